@@ -0,0 +1,133 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AccessPublic datasets may be shared with anyone. Datasets with no access
+	// level are public
+	AccessPublic = "public"
+	// AccessInternal datasets may only be shared within the publishing
+	// organization
+	AccessInternal = "internal"
+	// AccessRestricted datasets may only be shared with explicitly authorized
+	// parties
+	AccessRestricted = "restricted"
+)
+
+// accessLevels lists valid access levels from least to most restrictive
+var accessLevels = []string{AccessPublic, AccessInternal, AccessRestricted}
+
+const (
+	// SensitivityLow is data whose disclosure has limited adverse effect
+	SensitivityLow = "low"
+	// SensitivityModerate is data whose disclosure has serious adverse effect
+	SensitivityModerate = "moderate"
+	// SensitivityHigh is data whose disclosure has severe adverse effect, eg.
+	// personal data
+	SensitivityHigh = "high"
+)
+
+// sensitivities lists valid sensitivity classifications from least to most
+// sensitive
+var sensitivities = []string{SensitivityLow, SensitivityModerate, SensitivityHigh}
+
+// ParseAccessLevel normalizes an access level string, erroring if the level
+// is unknown. The empty string parses as AccessPublic
+func ParseAccessLevel(s string) (string, error) {
+	if s == "" {
+		return AccessPublic, nil
+	}
+	if levelRank(accessLevels, s) < 0 {
+		return "", fmt.Errorf("invalid access level '%s'. must be one of: %s", s, strings.Join(accessLevels, ", "))
+	}
+	return strings.ToLower(s), nil
+}
+
+// ParseSensitivity normalizes a sensitivity classification, erroring if the
+// classification is unknown. The empty string is unclassified, and parses as
+// the empty string
+func ParseSensitivity(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if levelRank(sensitivities, s) < 0 {
+		return "", fmt.Errorf("invalid sensitivity '%s'. must be one of: %s", s, strings.Join(sensitivities, ", "))
+	}
+	return strings.ToLower(s), nil
+}
+
+// levelRank gives the case-insensitive position of s in an ordered list, -1 if s
+// isn't present
+func levelRank(list []string, s string) int {
+	for i, v := range list {
+		if strings.EqualFold(v, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Access returns the normalized access level of metadata. Unknown access
+// levels are treated as AccessRestricted, so invalid documents fail closed
+func (md *Meta) Access() string {
+	if md == nil {
+		return AccessPublic
+	}
+	level, err := ParseAccessLevel(md.AccessLevel)
+	if err != nil {
+		return AccessRestricted
+	}
+	return level
+}
+
+// IsPublic checks if a dataset may be included in public bundles & exports.
+// datasets without metadata are public
+func (ds *Dataset) IsPublic() bool {
+	return ds.Meta.Access() == AccessPublic
+}
+
+// AccessAllowed checks if a dataset may be shared at a given access level,
+// eg. a dataset with an internal access level may be shared internally, but
+// not publicly
+func (ds *Dataset) AccessAllowed(level string) (bool, error) {
+	target, err := ParseAccessLevel(level)
+	if err != nil {
+		return false, err
+	}
+	return levelRank(accessLevels, ds.Meta.Access()) <= levelRank(accessLevels, target), nil
+}
+
+// CheckAccess returns an error wrapping ErrAccessDenied if a dataset may not
+// be shared at a given access level, see AccessAllowed. Exports & servers
+// check access before any data leaves the process
+func (ds *Dataset) CheckAccess(level string) error {
+	ok, err := ds.AccessAllowed(level)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		target, _ := ParseAccessLevel(level)
+		return fmt.Errorf("%w: %s dataset cannot be shared at the %s access level", ErrAccessDenied, ds.Meta.Access(), target)
+	}
+	return nil
+}
+
+// validateAccess checks access level & sensitivity fields. Highly sensitive
+// data can never be public
+func validateAccess(md *Meta) error {
+	level, err := ParseAccessLevel(md.AccessLevel)
+	if err != nil {
+		return err
+	}
+	sensitivity, err := ParseSensitivity(md.Sensitivity)
+	if err != nil {
+		return err
+	}
+	if sensitivity == SensitivityHigh && level == AccessPublic {
+		return fmt.Errorf("sensitivity '%s' requires an access level other than '%s'", SensitivityHigh, AccessPublic)
+	}
+	return nil
+}
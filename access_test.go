@@ -0,0 +1,91 @@
+package dataset
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAccessLevel(t *testing.T) {
+	cases := []struct {
+		in, expect, err string
+	}{
+		{"", AccessPublic, ""},
+		{"Internal", AccessInternal, ""},
+		{"restricted", AccessRestricted, ""},
+		{"private", "", "invalid access level 'private'. must be one of: public, internal, restricted"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseAccessLevel(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d result mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestDatasetAccessAllowed(t *testing.T) {
+	cases := []struct {
+		md       *Meta
+		level    string
+		public   bool
+		expected bool
+	}{
+		{nil, AccessPublic, true, true},
+		{&Meta{}, AccessPublic, true, true},
+		{&Meta{AccessLevel: "internal"}, AccessPublic, false, false},
+		{&Meta{AccessLevel: "internal"}, AccessInternal, false, true},
+		{&Meta{AccessLevel: "internal"}, AccessRestricted, false, true},
+		{&Meta{AccessLevel: "restricted"}, AccessInternal, false, false},
+		// unknown levels fail closed
+		{&Meta{AccessLevel: "secret"}, AccessInternal, false, false},
+	}
+
+	for i, c := range cases {
+		ds := &Dataset{Meta: c.md}
+		if ds.IsPublic() != c.public {
+			t.Errorf("case %d: expected IsPublic to be %t", i, c.public)
+		}
+		got, err := ds.AccessAllowed(c.level)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if got != c.expected {
+			t.Errorf("case %d: expected AccessAllowed(%s) to be %t", i, c.level, c.expected)
+		}
+	}
+
+	if _, err := (&Dataset{}).AccessAllowed("everyone"); err == nil {
+		t.Errorf("expected invalid access level to error")
+	}
+
+	restricted := &Dataset{Meta: &Meta{AccessLevel: AccessRestricted}}
+	err := restricted.CheckAccess(AccessPublic)
+	if !errors.Is(err, ErrAccessDenied) || err.Error() != "access denied: restricted dataset cannot be shared at the public access level" {
+		t.Errorf("expected an access denied error, got: %v", err)
+	}
+	if err := restricted.CheckAccess(AccessRestricted); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestMetaAccessJSON(t *testing.T) {
+	md := &Meta{}
+	if err := json.Unmarshal([]byte(`{"qri":"md:0","accessLevel":"internal","sensitivity":"moderate"}`), md); err != nil {
+		t.Fatal(err)
+	}
+	if md.AccessLevel != AccessInternal || md.Sensitivity != SensitivityModerate {
+		t.Errorf("field mismatch: %#v", md)
+	}
+	if len(md.Meta()) != 0 {
+		t.Errorf("access fields should not be hoisted into arbitrary metadata: %v", md.Meta())
+	}
+	if err := md.Set("accessLevel", "restricted"); err != nil || md.AccessLevel != AccessRestricted {
+		t.Errorf("set mismatch: %v, %s", err, md.AccessLevel)
+	}
+}
@@ -0,0 +1,135 @@
+package dataset
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lengths of calendar units when interpreting periodicities
+const (
+	periodDay   = time.Hour * 24
+	periodWeek  = periodDay * 7
+	periodMonth = periodDay * 30
+	periodYear  = periodDay * 365
+)
+
+// isoDuration matches ISO 8601 durations, optionally as a repeating interval
+// with no start date (eg. "R/P1D"). Fractional values are permitted
+var isoDuration = regexp.MustCompile(`^(?:R\d*/)?P(?:([\d.]+)Y)?(?:([\d.]+)M)?(?:([\d.]+)W)?(?:([\d.]+)D)?(?:T(?:([\d.]+)H)?(?:([\d.]+)M)?(?:([\d.]+)S)?)?$`)
+
+// isoDurationUnits are the lengths of each isoDuration capture group
+var isoDurationUnits = []time.Duration{periodYear, periodMonth, periodWeek, periodDay, time.Hour, time.Minute, time.Second}
+
+// frequencyVocabularies are URI prefixes for controlled vocabularies of update
+// frequencies: the EU Publications Office frequency authority table used by
+// DCAT-AP, and the Dublin Core collection description frequency vocabulary
+var frequencyVocabularies = []string{
+	"http://publications.europa.eu/resource/authority/frequency/",
+	"http://purl.org/cld/freq/",
+}
+
+// frequencies maps lower-cased vocabulary terms to durations. Terms that
+// don't describe a regular cadence map to zero
+var frequencies = map[string]time.Duration{
+	// terms shared by both vocabularies
+	"annual":    periodYear,
+	"biennial":  periodYear * 2,
+	"bimonthly": periodMonth * 2,
+	"biweekly":  periodWeek * 2,
+	"daily":     periodDay,
+	"monthly":   periodMonth,
+	"quarterly": periodMonth * 3,
+	"triennial": periodYear * 3,
+	"weekly":    periodWeek,
+
+	// EU frequency authority table
+	"annual_2":     periodYear / 2,
+	"annual_3":     periodYear / 3,
+	"bihourly":     time.Hour * 2,
+	"cont":         0,
+	"daily_2":      periodDay / 2,
+	"decennial":    periodYear * 10,
+	"hourly":       time.Hour,
+	"irreg":        0,
+	"monthly_2":    periodMonth / 2,
+	"monthly_3":    periodMonth / 3,
+	"never":        0,
+	"other":        0,
+	"quadrennial":  periodYear * 4,
+	"quinquennial": periodYear * 5,
+	"trihourly":    time.Hour * 3,
+	"unknown":      0,
+	"update_cont":  0,
+	"weekly_2":     periodWeek / 2,
+	"weekly_3":     periodWeek / 3,
+
+	// dublin core collection description frequency vocabulary
+	"continuous":       0,
+	"irregular":        0,
+	"semiannual":       periodYear / 2,
+	"semimonthly":      periodMonth / 2,
+	"semiweekly":       periodWeek / 2,
+	"threetimesamonth": periodMonth / 3,
+	"threetimesaweek":  periodWeek / 3,
+	"threetimesayear":  periodYear / 3,
+}
+
+// ParseAccrualPeriodicity interprets an accrual periodicity, returning the
+// expected interval between updates. Periodicities may be ISO 8601 durations
+// or repeating intervals ("P1D", "R/P1W"), or terms from the DCAT-AP or
+// Dublin Core frequency vocabularies, either as a full URI or a bare term
+// ("DAILY", "http://purl.org/cld/freq/weekly"). Years are counted as 365
+// days and months as 30. Terms without a regular cadence like "irregular"
+// return a zero duration and no error
+func ParseAccrualPeriodicity(p string) (time.Duration, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return 0, fmt.Errorf("accrual periodicity is required")
+	}
+
+	if m := isoDuration.FindStringSubmatch(p); m != nil && !strings.HasSuffix(p, "P") && !strings.HasSuffix(p, "T") {
+		var d time.Duration
+		for i, s := range m[1:] {
+			if s == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid accrual periodicity '%s': %s", p, err)
+			}
+			d += time.Duration(n * float64(isoDurationUnits[i]))
+		}
+		return d, nil
+	}
+
+	term := p
+	for _, prefix := range frequencyVocabularies {
+		if strings.HasPrefix(term, prefix) {
+			term = strings.TrimPrefix(term, prefix)
+			break
+		}
+	}
+	if d, ok := frequencies[strings.ToLower(term)]; ok {
+		return d, nil
+	}
+
+	return 0, fmt.Errorf("invalid accrual periodicity '%s': must be an ISO 8601 duration or frequency vocabulary term", p)
+}
+
+// Overdue checks if a dataset has gone longer than its declared accrual
+// periodicity without a new version, measuring from the commit timestamp to
+// now. Datasets without a periodicity, commit timestamp, or with a
+// periodicity that has no regular cadence are never overdue
+func (ds *Dataset) Overdue(now time.Time) (bool, error) {
+	if ds.Meta == nil || ds.Meta.AccrualPeriodicity == "" || ds.Commit == nil || ds.Commit.Timestamp.IsZero() {
+		return false, nil
+	}
+	d, err := ParseAccrualPeriodicity(ds.Meta.AccrualPeriodicity)
+	if err != nil || d == 0 {
+		return false, err
+	}
+	return now.Sub(ds.Commit.Timestamp) > d, nil
+}
@@ -0,0 +1,84 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAccrualPeriodicity(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect time.Duration
+		err    string
+	}{
+		{"R/P1D", time.Hour * 24, ""},
+		{"P1D", time.Hour * 24, ""},
+		{"R/P1W", time.Hour * 24 * 7, ""},
+		{"R/P3.5D", time.Hour * 84, ""},
+		{"P1Y2M", time.Hour * 24 * (365 + 60), ""},
+		{"PT15M", time.Minute * 15, ""},
+		{"P1DT12H", time.Hour * 36, ""},
+		{"R5/PT1H", time.Hour, ""},
+		{"DAILY", time.Hour * 24, ""},
+		{"http://publications.europa.eu/resource/authority/frequency/WEEKLY", time.Hour * 24 * 7, ""},
+		{"http://publications.europa.eu/resource/authority/frequency/ANNUAL_2", time.Hour * 24 * 365 / 2, ""},
+		{"http://purl.org/cld/freq/threeTimesAWeek", time.Hour * 24 * 7 / 3, ""},
+		{"http://purl.org/cld/freq/irregular", 0, ""},
+		{"IRREG", 0, ""},
+
+		{"", 0, "accrual periodicity is required"},
+		{"P", 0, "invalid accrual periodicity 'P': must be an ISO 8601 duration or frequency vocabulary term"},
+		{"P1DT", 0, "invalid accrual periodicity 'P1DT': must be an ISO 8601 duration or frequency vocabulary term"},
+		{"1W", 0, "invalid accrual periodicity '1W': must be an ISO 8601 duration or frequency vocabulary term"},
+		{"sometimes", 0, "invalid accrual periodicity 'sometimes': must be an ISO 8601 duration or frequency vocabulary term"},
+		{"http://example.com/frequency/DAILY", 0, "invalid accrual periodicity 'http://example.com/frequency/DAILY': must be an ISO 8601 duration or frequency vocabulary term"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseAccrualPeriodicity(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d '%s' error mismatch. expected: '%s', got: '%v'", i, c.in, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d '%s' duration mismatch. expected: %s, got: %s", i, c.in, c.expect, got)
+		}
+	}
+}
+
+func TestDatasetOverdue(t *testing.T) {
+	now := time.Date(2019, 6, 10, 0, 0, 0, 0, time.UTC)
+	ds := func(periodicity string, ts time.Time) *Dataset {
+		return &Dataset{
+			Meta:   &Meta{AccrualPeriodicity: periodicity},
+			Commit: &Commit{Timestamp: ts},
+		}
+	}
+
+	cases := []struct {
+		description string
+		ds          *Dataset
+		overdue     bool
+		err         string
+	}{
+		{"no meta", &Dataset{Commit: &Commit{Timestamp: now}}, false, ""},
+		{"no commit", &Dataset{Meta: &Meta{AccrualPeriodicity: "R/P1D"}}, false, ""},
+		{"no periodicity", ds("", now.AddDate(-1, 0, 0)), false, ""},
+		{"irregular", ds("IRREG", now.AddDate(-1, 0, 0)), false, ""},
+		{"daily, updated today", ds("R/P1D", now.Add(-time.Hour)), false, ""},
+		{"daily, updated last week", ds("R/P1D", now.AddDate(0, 0, -7)), true, ""},
+		{"weekly, updated last week", ds("WEEKLY", now.AddDate(0, 0, -6)), false, ""},
+		{"invalid", ds("fortnightly", now), false, "invalid accrual periodicity 'fortnightly': must be an ISO 8601 duration or frequency vocabulary term"},
+	}
+
+	for _, c := range cases {
+		got, err := c.ds.Overdue(now)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%s: error mismatch. expected: '%s', got: '%v'", c.description, c.err, err)
+			continue
+		}
+		if got != c.overdue {
+			t.Errorf("%s: expected overdue %t, got %t", c.description, c.overdue, got)
+		}
+	}
+}
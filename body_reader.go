@@ -0,0 +1,82 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/qri-io/qfs"
+)
+
+// MaxCachedBodySize is the largest body BodyReader keeps in memory, in bytes.
+// Larger bodies are fetched from their store each time they're read
+var MaxCachedBodySize = 16 << 20
+
+// BodyReader opens a fresh reader of the dataset body each time it's called,
+// so unlike BodyFile the body can be read any number of times. BodyBytes are
+// read in place, and bodies at BodyPath are fetched from resolver on demand.
+// Fetched bodies no larger than MaxCachedBodySize are cached on the dataset
+// once they're read in full, and later readers don't fetch them again. A
+// body file set with SetBodyFile isn't read. Callers must close the reader.
+// BodyReader isn't safe for concurrent use
+func (ds *Dataset) BodyReader(ctx context.Context, resolver qfs.PathResolver) (qfs.File, error) {
+	if ds.Body != nil {
+		return nil, ErrInlineBody
+	}
+	if ds.BodyBytes != nil {
+		return qfs.NewMemfileBytes(bodyFileName(ds), ds.BodyBytes), nil
+	}
+	if ds.BodyPath == "" {
+		return nil, ErrNoBody
+	}
+	if ds.bodyCache != nil && ds.bodyCachePath == ds.BodyPath {
+		return qfs.NewMemfileBytes(bodyFileName(ds), ds.bodyCache), nil
+	}
+	if resolver == nil {
+		return nil, ErrNoResolver
+	}
+	f, err := resolver.Get(ctx, ds.BodyPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening dataset.bodyPath '%s': %w", ds.BodyPath, err)
+	}
+	return &cachingBodyFile{File: f, ds: ds, path: ds.BodyPath}, nil
+}
+
+// bodyFileName names body files opened by BodyReader
+func bodyFileName(ds *Dataset) string {
+	name := "body"
+	if ds.Structure != nil && ds.Structure.DataFormat() != UnknownDataFormat {
+		name += "." + ds.Structure.DataFormat().String()
+	}
+	return name
+}
+
+// cachingBodyFile copies body content as it's read, caching it on the
+// dataset when the whole body is read without exceeding MaxCachedBodySize
+type cachingBodyFile struct {
+	qfs.File
+	ds   *Dataset
+	path string
+	buf  bytes.Buffer
+	stop bool
+}
+
+// Read implements the io.Reader interface
+func (f *cachingBodyFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if !f.stop {
+		if f.buf.Len()+n > MaxCachedBodySize {
+			f.stop = true
+			f.buf = bytes.Buffer{}
+		} else {
+			f.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !f.stop && f.ds.BodyPath == f.path {
+		f.ds.bodyCache = f.buf.Bytes()
+		f.ds.bodyCachePath = f.path
+		f.stop = true
+	}
+	return n, err
+}
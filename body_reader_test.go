@@ -0,0 +1,103 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// countingResolver counts calls to Get
+type countingResolver struct {
+	qfs.PathResolver
+	gets int
+}
+
+func (r *countingResolver) Get(ctx context.Context, path string) (qfs.File, error) {
+	r.gets++
+	return r.PathResolver.Get(ctx, path)
+}
+
+func TestBodyReader(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	path, err := store.Put(ctx, qfs.NewMemfileBytes("body.csv", []byte("a,b\n1,2\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := &countingResolver{PathResolver: store}
+	ds := &Dataset{BodyPath: path, Structure: &Structure{Format: "csv"}}
+
+	read := func() string {
+		f, err := ds.BodyReader(ctx, resolver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	for i := 0; i < 3; i++ {
+		if got := read(); got != "a,b\n1,2\n" {
+			t.Errorf("read %d: body mismatch. got: %q", i, got)
+		}
+	}
+	if resolver.gets != 1 {
+		t.Errorf("expected the body to be fetched once, got %d fetches", resolver.gets)
+	}
+
+	// a partially read body isn't cached
+	ds = &Dataset{BodyPath: path}
+	f, err := ds.BodyReader(ctx, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Read(make([]byte, 2))
+	f.Close()
+	read()
+	read()
+	if resolver.gets != 3 {
+		t.Errorf("expected a partial read not to be cached, got %d fetches", resolver.gets)
+	}
+
+	// bodies larger than the cache limit are fetched each time
+	defer func(size int) { MaxCachedBodySize = size }(MaxCachedBodySize)
+	MaxCachedBodySize = 4
+	ds = &Dataset{BodyPath: path}
+	read()
+	read()
+	if resolver.gets != 5 {
+		t.Errorf("expected large bodies not to be cached, got %d fetches", resolver.gets)
+	}
+
+	// body bytes don't need a resolver
+	ds = &Dataset{BodyBytes: []byte("[1]")}
+	if got := read(); got != "[1]" {
+		t.Errorf("body bytes mismatch. got: %q", got)
+	}
+}
+
+func TestBodyReaderErrors(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		ds       *Dataset
+		resolver qfs.PathResolver
+		err      error
+	}{
+		{&Dataset{Body: []interface{}{1}}, nil, ErrInlineBody},
+		{&Dataset{}, nil, ErrNoBody},
+		{&Dataset{BodyPath: "/map/QmBody"}, nil, ErrNoResolver},
+		{&Dataset{BodyPath: "/map/QmBody"}, cafs.NewMapstore(), cafs.ErrNotFound},
+	}
+	for i, c := range cases {
+		if _, err := c.ds.BodyReader(ctx, c.resolver); !errors.Is(err, c.err) {
+			t.Errorf("case %d: expected %v, got: %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package dataset
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrFinalized occurs when modifying a builder after Finalize has succeeded
+var ErrFinalized = errors.New("dataset builder is finalized")
+
+// Builder constructs a dataset from multiple goroutines. A common split is
+// one goroutine streaming the body, another computing structure values and
+// a third filling metadata. All methods are safe for concurrent use. Each
+// update holds the builder lock while it runs, so update functions should be
+// quick & must not call back into the builder
+type Builder struct {
+	lk        sync.Mutex
+	ds        *Dataset
+	finalized bool
+}
+
+// NewBuilder creates a builder, applying options in order. Unlike New,
+// options aren't validated until Finalize is called
+func NewBuilder(opts ...Option) *Builder {
+	b := &Builder{ds: &Dataset{Qri: KindDataset.String()}}
+	for _, opt := range opts {
+		opt(b.ds)
+	}
+	return b
+}
+
+// Apply sets dataset fields with options, in order
+func (b *Builder) Apply(opts ...Option) error {
+	return b.Update(func(ds *Dataset) {
+		for _, opt := range opts {
+			opt(ds)
+		}
+	})
+}
+
+// Update calls fn with the dataset under construction
+func (b *Builder) Update(fn func(ds *Dataset)) error {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	if b.finalized {
+		return ErrFinalized
+	}
+	fn(b.ds)
+	return nil
+}
+
+// UpdateCommit calls fn with the commit component, creating it if necessary
+func (b *Builder) UpdateCommit(fn func(cm *Commit)) error {
+	return b.Update(func(ds *Dataset) {
+		if ds.Commit == nil {
+			ds.Commit = &Commit{}
+		}
+		fn(ds.Commit)
+	})
+}
+
+// UpdateMeta calls fn with the meta component, creating it if necessary
+func (b *Builder) UpdateMeta(fn func(md *Meta)) error {
+	return b.Update(func(ds *Dataset) {
+		if ds.Meta == nil {
+			ds.Meta = &Meta{}
+		}
+		fn(ds.Meta)
+	})
+}
+
+// UpdateStructure calls fn with the structure component, creating it if
+// necessary
+func (b *Builder) UpdateStructure(fn func(st *Structure)) error {
+	return b.Update(func(ds *Dataset) {
+		if ds.Structure == nil {
+			ds.Structure = &Structure{}
+		}
+		fn(ds.Structure)
+	})
+}
+
+// Dataset returns a copy of the dataset as currently built
+func (b *Builder) Dataset() *Dataset {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	return b.ds.Clone()
+}
+
+// Finalize validates the dataset & freezes the builder, returning a copy of
+// the built dataset. Once finalized all updates fail with ErrFinalized &
+// calling Finalize again returns another copy of the same dataset. A dataset
+// that fails validation isn't frozen, and can be corrected before calling
+// Finalize again
+func (b *Builder) Finalize() (*Dataset, error) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	if b.finalized {
+		return b.ds.Clone(), nil
+	}
+	if err := b.ds.Validate(); err != nil {
+		return nil, err
+	}
+	b.finalized = true
+	return b.ds.Clone(), nil
+}
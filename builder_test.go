@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder(WithCommit(&Commit{Title: "initial commit"}))
+
+	if _, err := b.Finalize(); err == nil || err.Error() != "commit: timestamp is required" {
+		t.Fatalf("expected validation error, got: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		body := []interface{}{}
+		for i := 0; i < 100; i++ {
+			body = append(body, i)
+			if err := b.UpdateStructure(func(st *Structure) { st.Entries++ }); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := b.Apply(WithBody(body)); err != nil {
+			t.Error(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := b.UpdateStructure(func(st *Structure) {
+			st.Format = JSONDataFormat.String()
+			st.Schema = BaseSchemaArray
+		}); err != nil {
+			t.Error(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := b.UpdateMeta(func(md *Meta) { md.Title = "stops" }); err != nil {
+			t.Error(err)
+		}
+		if err := b.UpdateCommit(func(cm *Commit) {
+			cm.Timestamp = time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC)
+		}); err != nil {
+			t.Error(err)
+		}
+	}()
+	wg.Wait()
+
+	snapshot := b.Dataset()
+	ds, err := b.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot == ds {
+		t.Errorf("expected Dataset to return a copy")
+	}
+	if ds.Structure.Entries != 100 || ds.Meta.Title != "stops" || len(ds.Body.([]interface{})) != 100 {
+		t.Errorf("expected all updates to apply, got: %#v", ds)
+	}
+
+	if err := b.UpdateMeta(func(md *Meta) { md.Title = "changed" }); err != ErrFinalized {
+		t.Errorf("expected ErrFinalized, got: %v", err)
+	}
+	ds.Meta.Title = "modified"
+	again, err := b.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == ds {
+		t.Errorf("expected Finalize to return a copy")
+	}
+	if again.Meta.Title != "stops" || again.Structure.Entries != 100 {
+		t.Errorf("expected finalized dataset to be unchanged, got: %#v", again)
+	}
+}
@@ -0,0 +1,289 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Catalog groups related datasets into a single addressable unit, like the
+// tables of a GTFS feed or the datasets of a project. Catalogs correspond to
+// the DCAT dcat:Catalog class. Members are dataset references, so a catalog
+// of pinned references describes exact versions, while unpinned "handle/name"
+// members follow the latest version of each dataset
+type Catalog struct {
+	// Datasets are the members of the catalog, in display order
+	Datasets []*CatalogMember `json:"datasets,omitempty"`
+	// Description of the catalog, around a paragraph of human-readable text
+	Description string `json:"description,omitempty"`
+	// HomeURL is a path to a "home" resource for the catalog
+	HomeURL string `json:"homeURL,omitempty"`
+	// Keywords describing the catalog
+	Keywords []string `json:"keywords,omitempty"`
+	// License of the catalog as a whole. members have their own licenses
+	License *License `json:"license,omitempty"`
+	// Path is the location of the catalog, transient
+	Path string `json:"path,omitempty"`
+	// Publisher is the organization responsible for the catalog
+	Publisher *Publisher `json:"publisher,omitempty"`
+	// Qri should always be KindCatalog
+	Qri string `json:"qri,omitempty"`
+	// Title of the catalog
+	Title string `json:"title,omitempty"`
+}
+
+// CatalogMember is a dataset in a catalog
+type CatalogMember struct {
+	// Ref references the member dataset
+	Ref *DatasetRef `json:"ref"`
+	// Role describes what the member is in the catalog, eg. "stops" for the
+	// stops table of a GTFS feed. optional
+	Role string `json:"role,omitempty"`
+	// Title of the member, for display without loading the dataset. optional
+	Title string `json:"title,omitempty"`
+}
+
+// NewCatalogRef creates a catalog pointer with the internal path property
+// specified, and no other fields
+func NewCatalogRef(path string) *Catalog {
+	return &Catalog{Path: path}
+}
+
+// IsEmpty checks to see if a catalog has any fields other than the path
+func (c *Catalog) IsEmpty() bool {
+	return c.Datasets == nil &&
+		c.Description == "" &&
+		c.HomeURL == "" &&
+		c.Keywords == nil &&
+		c.License == nil &&
+		c.Publisher == nil &&
+		c.Title == ""
+}
+
+// DropTransientValues removes values that cannot be recorded when the
+// catalog is rendered immutable, usually by storing it in a cafs
+func (c *Catalog) DropTransientValues() {
+	c.Path = ""
+}
+
+// DropDerivedValues resets all set-on-save fields to their default values
+func (c *Catalog) DropDerivedValues() {
+	c.Path = ""
+	c.Qri = ""
+}
+
+// Add appends a dataset to the catalog, referenced by it's peername, name
+// & path
+func (c *Catalog) Add(ds *Dataset, role string) error {
+	if ds == nil {
+		return fmt.Errorf("dataset is required")
+	}
+	m := &CatalogMember{Ref: ds.Ref(), Role: role}
+	if ds.Meta != nil {
+		m.Title = ds.Meta.Title
+	}
+	if err := m.Ref.Validate(); err != nil {
+		return fmt.Errorf("invalid dataset ref: %s", err)
+	}
+	for _, member := range c.Datasets {
+		if member != nil && member.Ref.Equal(m.Ref) {
+			return fmt.Errorf("dataset '%s' is already a member", m.Ref)
+		}
+	}
+	c.Datasets = append(c.Datasets, m)
+	return nil
+}
+
+// Members lists the members with a role, in catalog order
+func (c *Catalog) Members(role string) []*CatalogMember {
+	var members []*CatalogMember
+	for _, m := range c.Datasets {
+		if m != nil && m.Role == role {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+// Pinned checks every member references an exact dataset version
+func (c *Catalog) Pinned() bool {
+	for _, m := range c.Datasets {
+		if m == nil || m.Ref == nil || !m.Ref.IsPinned() {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks a catalog is well formed
+func (c *Catalog) Validate() error {
+	if c.Path != "" && c.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(c.Qri, KindCatalog); err != nil {
+		return err
+	}
+	if c.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if err := validateURL("homeURL", c.HomeURL); err != nil {
+		return err
+	}
+	if c.License != nil {
+		if err := c.License.Validate(); err != nil {
+			return fmt.Errorf("license: %s", err)
+		}
+	}
+	for i, m := range c.Datasets {
+		if m == nil || m.Ref == nil {
+			return fmt.Errorf("dataset %d: ref is required", i)
+		}
+		if err := m.Ref.Validate(); err != nil {
+			return fmt.Errorf("dataset %d: %s", i, err)
+		}
+		// members are the same dataset when their refs are Equal
+		for _, prev := range c.Datasets[:i] {
+			if prev.Ref.Equal(m.Ref) {
+				return fmt.Errorf("dataset %d: '%s' is already a member", i, m.Ref)
+			}
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of a catalog
+func (c *Catalog) Clone() *Catalog {
+	if c == nil {
+		return nil
+	}
+	cp := &Catalog{
+		Description: c.Description,
+		HomeURL:     c.HomeURL,
+		Keywords:    cloneStrings(c.Keywords),
+		License:     c.License.Clone(),
+		Path:        c.Path,
+		Publisher:   c.Publisher.Clone(),
+		Qri:         c.Qri,
+		Title:       c.Title,
+	}
+	if c.Datasets != nil {
+		cp.Datasets = make([]*CatalogMember, len(c.Datasets))
+		for i, m := range c.Datasets {
+			if m != nil {
+				cp.Datasets[i] = &CatalogMember{Ref: m.Ref.Clone(), Role: m.Role, Title: m.Title}
+			}
+		}
+	}
+	return cp
+}
+
+// _catalog is a private struct for marshaling into & out of
+type _catalog Catalog
+
+// MarshalJSON satisfies the json.Marshaler interface. Empty catalogs with a
+// path marshal to the path string
+func (c Catalog) MarshalJSON() ([]byte, error) {
+	if c.Path != "" && c.IsEmpty() {
+		return json.Marshal(c.Path)
+	}
+	if c.Qri == "" {
+		c.Qri = KindCatalog.String()
+	}
+	return json.Marshal(_catalog(c))
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface, accepting either a
+// catalog object or a path string
+func (c *Catalog) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Catalog{Path: s}
+		return nil
+	}
+	_c := _catalog{}
+	if err := json.Unmarshal(data, &_c); err != nil {
+		return fmt.Errorf("unmarshaling catalog: %w", err)
+	}
+	if err := checkKind(_c.Qri); err != nil {
+		return err
+	}
+	*c = Catalog(_c)
+	return nil
+}
+
+// DCAT returns the catalog as a JSON-LD dcat:Catalog. Members are listed as
+// dcat:Dataset resources identified by their reference string, and members
+// with a role are also described by a dcat:qualifiedRelation
+func (c *Catalog) DCAT() map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"dcat": "http://www.w3.org/ns/dcat#",
+			"dct":  "http://purl.org/dc/terms/",
+			"foaf": "http://xmlns.com/foaf/0.1/",
+		},
+		"@type": "dcat:Catalog",
+	}
+	if c.Title != "" {
+		doc["dct:title"] = c.Title
+	}
+	if c.Description != "" {
+		doc["dct:description"] = c.Description
+	}
+	if c.HomeURL != "" {
+		doc["foaf:homepage"] = map[string]interface{}{"@id": c.HomeURL}
+	}
+	if len(c.Keywords) > 0 {
+		doc["dcat:keyword"] = cloneStrings(c.Keywords)
+	}
+	if c.License != nil {
+		if c.License.URL != "" {
+			doc["dct:license"] = map[string]interface{}{"@id": c.License.URL}
+		} else {
+			doc["dct:license"] = c.License.Type
+		}
+	}
+	if p := c.Publisher; p != nil {
+		agent := map[string]interface{}{"@type": "foaf:Agent"}
+		if p.Identifier != "" {
+			agent["@id"] = p.Identifier
+		}
+		if p.Name != "" {
+			agent["foaf:name"] = p.Name
+		}
+		if p.URL != "" {
+			agent["foaf:homepage"] = map[string]interface{}{"@id": p.URL}
+		}
+		if p.Email != "" {
+			agent["foaf:mbox"] = map[string]interface{}{"@id": "mailto:" + p.Email}
+		}
+		doc["dct:publisher"] = agent
+	}
+
+	var datasets, relations []interface{}
+	for _, m := range c.Datasets {
+		if m == nil || m.Ref == nil {
+			continue
+		}
+		ds := map[string]interface{}{
+			"@type":          "dcat:Dataset",
+			"dct:identifier": m.Ref.String(),
+		}
+		if m.Title != "" {
+			ds["dct:title"] = m.Title
+		}
+		datasets = append(datasets, ds)
+		if m.Role != "" {
+			relations = append(relations, map[string]interface{}{
+				"@type":        "dcat:Relationship",
+				"dct:relation": m.Ref.String(),
+				"dcat:hadRole": m.Role,
+			})
+		}
+	}
+	if datasets != nil {
+		doc["dcat:dataset"] = datasets
+	}
+	if relations != nil {
+		doc["dcat:qualifiedRelation"] = relations
+	}
+	return doc
+}
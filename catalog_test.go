@@ -0,0 +1,129 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		Title:     "VVS GTFS feed",
+		Keywords:  []string{"gtfs", "transit"},
+		License:   &License{Type: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"},
+		Publisher: &Publisher{Name: "MFDZ", URL: "https://mfdz.de", Email: "info@mfdz.de"},
+		Datasets: []*CatalogMember{
+			{Ref: &DatasetRef{Handle: "mfdz", Name: "vvs_stops", Path: "/ipfs/QmStops"}, Role: "stops", Title: "Stops"},
+			{Ref: &DatasetRef{Handle: "mfdz", Name: "vvs_routes", Path: "/ipfs/QmRoutes"}, Role: "routes"},
+		},
+	}
+}
+
+func TestCatalogAdd(t *testing.T) {
+	c := &Catalog{Title: "feed"}
+	ds := &Dataset{Peername: "mfdz", Name: "vvs_trips", Meta: &Meta{Title: "Trips"}}
+	if err := c.Add(ds, "trips"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(ds, "trips"); err == nil || err.Error() != "dataset 'mfdz/vvs_trips' is already a member" {
+		t.Errorf("expected a duplicate member error, got: %v", err)
+	}
+	// refs to the same version are duplicates, whatever they're named
+	if err := c.Add(&Dataset{Peername: "mfdz", Name: "vvs_shapes", Path: "/ipfs/QmShapes"}, "shapes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(&Dataset{Peername: "mfdz", Name: "shapes", Path: "/ipfs/QmShapes"}, "shapes"); err == nil || err.Error() != "dataset 'mfdz/shapes@/ipfs/QmShapes' is already a member" {
+		t.Errorf("expected a duplicate version error, got: %v", err)
+	}
+	if err := c.Add(&Dataset{}, "trips"); err == nil {
+		t.Errorf("expected datasets without a ref to error")
+	}
+	members := c.Members("trips")
+	if len(members) != 1 || members[0].Title != "Trips" || members[0].Ref.String() != "mfdz/vvs_trips" {
+		t.Errorf("unexpected members: %#v", members)
+	}
+	if c.Pinned() {
+		t.Errorf("expected unpinned members not to be pinned")
+	}
+	if !testCatalog().Pinned() {
+		t.Errorf("expected pinned members to be pinned")
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	cases := []struct {
+		change func(c *Catalog)
+		err    string
+	}{
+		{func(c *Catalog) {}, ""},
+		{func(c *Catalog) { *c = *NewCatalogRef("/ipfs/QmCatalog") }, ""},
+		{func(c *Catalog) { c.Title = "" }, "title is required"},
+		{func(c *Catalog) { c.Qri = "ds:0" }, "invalid kind: 'ds:0'. expected type 'ct'"},
+		{func(c *Catalog) { c.HomeURL = "mfdz.de" }, "homeURL: 'mfdz.de' must be an absolute url"},
+		{func(c *Catalog) { c.Datasets[1].Ref = nil }, "dataset 1: ref is required"},
+		{func(c *Catalog) { c.Datasets[1].Ref = &DatasetRef{Name: "routes"} }, "dataset 1: handle is required"},
+		{func(c *Catalog) { c.Datasets[1].Ref = c.Datasets[0].Ref.Clone() }, "dataset 1: 'mfdz/vvs_stops@/ipfs/QmStops' is already a member"},
+		{func(c *Catalog) {
+			c.Datasets[1].Ref = &DatasetRef{Handle: "mfdz", Name: "stops", Path: "/ipfs/QmStops"}
+		}, "dataset 1: 'mfdz/stops@/ipfs/QmStops' is already a member"},
+	}
+	for i, tc := range cases {
+		c := testCatalog()
+		tc.change(c)
+		err := c.Validate()
+		if !(err == nil && tc.err == "" || err != nil && err.Error() == tc.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, tc.err, err)
+		}
+	}
+}
+
+func TestCatalogJSON(t *testing.T) {
+	c := testCatalog()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Catalog{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	c.Qri = KindCatalog.String()
+	if !reflect.DeepEqual(c, got) {
+		t.Errorf("round trip mismatch.\nexpected: %#v\ngot:      %#v", c, got)
+	}
+	if !reflect.DeepEqual(got.Clone(), got) {
+		t.Errorf("expected clone to equal the catalog")
+	}
+
+	data, err = json.Marshal(NewCatalogRef("/ipfs/QmCatalog"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"/ipfs/QmCatalog"` {
+		t.Errorf("expected catalog references to marshal to a path, got: %s", data)
+	}
+	if err := json.Unmarshal(data, got); err != nil || got.Path != "/ipfs/QmCatalog" || !got.IsEmpty() {
+		t.Errorf("expected a catalog reference, got: %#v %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(`{"datasets":[{"ref":"mfdz/stops@/ipfs/QmStops"}]}`), got); err != nil || got.Datasets[0].Ref.Path != "/ipfs/QmStops" {
+		t.Errorf("expected member refs to decode from strings, got: %#v %v", got, err)
+	}
+}
+
+func TestCatalogDCAT(t *testing.T) {
+	doc := testCatalog().DCAT()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"@context":{"dcat":"http://www.w3.org/ns/dcat#","dct":"http://purl.org/dc/terms/","foaf":"http://xmlns.com/foaf/0.1/"},"@type":"dcat:Catalog",` +
+		`"dcat:dataset":[{"@type":"dcat:Dataset","dct:identifier":"mfdz/vvs_stops@/ipfs/QmStops","dct:title":"Stops"},{"@type":"dcat:Dataset","dct:identifier":"mfdz/vvs_routes@/ipfs/QmRoutes"}],` +
+		`"dcat:keyword":["gtfs","transit"],` +
+		`"dcat:qualifiedRelation":[{"@type":"dcat:Relationship","dcat:hadRole":"stops","dct:relation":"mfdz/vvs_stops@/ipfs/QmStops"},{"@type":"dcat:Relationship","dcat:hadRole":"routes","dct:relation":"mfdz/vvs_routes@/ipfs/QmRoutes"}],` +
+		`"dct:license":{"@id":"https://creativecommons.org/licenses/by/4.0/"},` +
+		`"dct:publisher":{"@type":"foaf:Agent","foaf:homepage":{"@id":"https://mfdz.de"},"foaf:mbox":{"@id":"mailto:info@mfdz.de"},"foaf:name":"MFDZ"},` +
+		`"dct:title":"VVS GTFS feed"}`
+	if string(data) != expect {
+		t.Errorf("dcat mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+}
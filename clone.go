@@ -0,0 +1,61 @@
+package dataset
+
+// cloneValue deep-copies values produced by json.Unmarshal-style decoding.
+// values of any other type are copied as-is
+func cloneValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return cloneMap(x)
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(x))
+		for key, val := range x {
+			m[key] = cloneValue(val)
+		}
+		return m
+	case []interface{}:
+		if x == nil {
+			return x
+		}
+		s := make([]interface{}, len(x))
+		for i, val := range x {
+			s[i] = cloneValue(val)
+		}
+		return s
+	case []string:
+		return cloneStrings(x)
+	case []byte:
+		return cloneBytes(x)
+	default:
+		return v
+	}
+}
+
+// cloneMap deep-copies a map of json.Unmarshal-style values
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		c[key] = cloneValue(val)
+	}
+	return c
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	c := make([]string, len(s))
+	copy(c, s)
+	return c
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
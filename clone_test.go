@@ -0,0 +1,129 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDatasetClone(t *testing.T) {
+	if (*Dataset)(nil).Clone() != nil {
+		t.Errorf("expected cloning a nil dataset to return nil")
+	}
+
+	ds := &Dataset{
+		Body:         []interface{}{"a", map[string]interface{}{"b": []interface{}{1.0}}},
+		BodyBytes:    []byte("body"),
+		BodyPath:     "/ipfs/QmBody",
+		Name:         "clone_me",
+		Path:         "/ipfs/QmDs",
+		Peername:     "peer",
+		PreviousPath: "/ipfs/QmPrev",
+		ProfileID:    "QmProfile",
+		NumVersions:  2,
+		Qri:          KindDataset.String(),
+		Commit: &Commit{
+			Author:    &User{ID: "author", Email: "author@example.com"},
+			Message:   "message",
+			Timestamp: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+			Title:     "title",
+		},
+		Meta: &Meta{
+			meta:         map[string]interface{}{"extra": map[string]interface{}{"nested": "value"}},
+			Title:        "title",
+			Keywords:     []string{"a", "b"},
+			Citations:    []*Citation{{Name: "citation"}},
+			Contributors: []*User{{ID: "contributor"}},
+			License:      &License{Type: "CC-BY-4.0"},
+		},
+		Readme: &Readme{Format: "md", ScriptBytes: []byte("# readme")},
+		Structure: &Structure{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			CRS:          "EPSG:4326",
+			Geometry:     &GeometryRules{X: "lon", Y: "lat", BBox: []float64{5.8, 47.2, 15.1, 55.1}},
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":  "array",
+					"items": []interface{}{map[string]interface{}{"title": "a", "type": "string"}},
+				},
+			},
+			Source: &SourceFormat{Format: "csv", FormatConfig: map[string]interface{}{"lazyQuotes": true}},
+		},
+		Transform: &Transform{
+			Anonymize:   &AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "a", Method: AnonymizeHash}}},
+			Config:      map[string]interface{}{"list": []interface{}{"a"}},
+			Resources:   map[string]*TransformResource{"a": {Path: "/ipfs/QmResource"}},
+			ScriptBytes: []byte("def transform(ds):\n  pass"),
+			Secrets:     map[string]string{"key": "value"},
+			Syntax:      "starlark",
+		},
+		Viz: &Viz{Format: "html", ScriptBytes: []byte("<html></html>")},
+	}
+
+	got := ds.Clone()
+	if diff := cmp.Diff(ds, got, cmp.AllowUnexported(Dataset{}, Meta{}, Transform{}, Viz{}, Readme{})); diff != "" {
+		t.Fatalf("clone mismatch (-want +got):\n%s", diff)
+	}
+
+	// modify the clone, confirm the original is unaffected
+	got.Body.([]interface{})[1].(map[string]interface{})["b"].([]interface{})[0] = 2.0
+	got.BodyBytes[0] = 'B'
+	got.Commit.Author.ID = "changed"
+	got.Meta.meta["extra"].(map[string]interface{})["nested"] = "changed"
+	got.Meta.Keywords[0] = "changed"
+	got.Meta.Citations[0].Name = "changed"
+	got.Meta.Contributors[0].ID = "changed"
+	got.Meta.License.Type = "changed"
+	got.Readme.ScriptBytes[0] = '!'
+	got.Structure.FormatConfig["headerRow"] = false
+	got.Structure.Geometry.BBox[0] = 0
+	got.Structure.Source.FormatConfig["lazyQuotes"] = false
+	got.Structure.Schema["items"].(map[string]interface{})["type"] = "object"
+	got.Transform.Config["list"].([]interface{})[0] = "changed"
+	got.Transform.Anonymize.Columns[0].Column = "changed"
+	got.Transform.Resources["a"].Path = "changed"
+	got.Transform.Secrets["key"] = "changed"
+	got.Transform.ScriptBytes[0] = '#'
+	got.Viz.ScriptBytes[0] = '!'
+
+	if ds.Body.([]interface{})[1].(map[string]interface{})["b"].([]interface{})[0] != 1.0 {
+		t.Errorf("body aliased")
+	}
+	if string(ds.BodyBytes) != "body" {
+		t.Errorf("bodyBytes aliased")
+	}
+	if ds.Commit.Author.ID != "author" {
+		t.Errorf("commit author aliased")
+	}
+	if ds.Meta.meta["extra"].(map[string]interface{})["nested"] != "value" {
+		t.Errorf("arbitrary metadata aliased")
+	}
+	if ds.Meta.Keywords[0] != "a" || ds.Meta.Citations[0].Name != "citation" || ds.Meta.Contributors[0].ID != "contributor" || ds.Meta.License.Type != "CC-BY-4.0" {
+		t.Errorf("meta aliased")
+	}
+	if string(ds.Readme.ScriptBytes) != "# readme" {
+		t.Errorf("readme aliased")
+	}
+	if ds.Structure.FormatConfig["headerRow"] != true || ds.Structure.Source.FormatConfig["lazyQuotes"] != true || ds.Structure.Schema["items"].(map[string]interface{})["type"] != "array" {
+		t.Errorf("structure aliased")
+	}
+	if ds.Transform.Config["list"].([]interface{})[0] != "a" || ds.Transform.Resources["a"].Path != "/ipfs/QmResource" || ds.Transform.Secrets["key"] != "value" || ds.Transform.ScriptBytes[0] != 'd' {
+		t.Errorf("transform aliased")
+	}
+	if string(ds.Viz.ScriptBytes) != "<html></html>" {
+		t.Errorf("viz aliased")
+	}
+}
+
+func TestCloneNilComponents(t *testing.T) {
+	got := (&Dataset{Name: "empty"}).Clone()
+	if got.Commit != nil || got.Meta != nil || got.Readme != nil || got.Structure != nil || got.Transform != nil || got.Viz != nil {
+		t.Errorf("expected nil components to clone as nil")
+	}
+	if (&Meta{}).Clone().Citations != nil {
+		t.Errorf("expected nil slices to clone as nil")
+	}
+}
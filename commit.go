@@ -14,6 +14,9 @@ import (
 type Commit struct {
 	// Author of this commit
 	Author *User `json:"author,omitempty"`
+	// BodyDelta records row-level changes to the body when this commit was
+	// created by appending to or patching the previous version's body
+	BodyDelta *BodyDelta `json:"bodyDelta,omitempty"`
 	// Message is an optional
 	Message string `json:"message,omitempty"`
 	// Path is the location of this commit, transient
@@ -52,6 +55,7 @@ func (cm *Commit) DropDerivedValues() {
 // IsEmpty checks to see if any fields are filled out other than Path and Qri
 func (cm *Commit) IsEmpty() bool {
 	return cm.Author == nil &&
+		cm.BodyDelta == nil &&
 		cm.Message == "" &&
 		cm.Signature == "" &&
 		cm.Timestamp.IsZero() &&
@@ -69,6 +73,9 @@ func (cm *Commit) Assign(msgs ...*Commit) {
 		if m.Author != nil {
 			cm.Author = m.Author
 		}
+		if m.BodyDelta != nil {
+			cm.BodyDelta = m.BodyDelta
+		}
 		if m.Message != "" {
 			cm.Message = m.Message
 		}
@@ -90,6 +97,23 @@ func (cm *Commit) Assign(msgs ...*Commit) {
 	}
 }
 
+// Clone returns a deep copy of a commit
+func (cm *Commit) Clone() *Commit {
+	if cm == nil {
+		return nil
+	}
+	return &Commit{
+		Author:    cm.Author.Clone(),
+		BodyDelta: cm.BodyDelta.Clone(),
+		Message:   cm.Message,
+		Path:      cm.Path,
+		Qri:       cm.Qri,
+		Signature: cm.Signature,
+		Timestamp: cm.Timestamp,
+		Title:     cm.Title,
+	}
+}
+
 // MarshalJSON implements the json.Marshaler interface for Commit
 // Empty Commit instances with a non-empty path marshal to their path value
 // otherwise, Commit marshals to an object
@@ -110,6 +134,7 @@ func (cm *Commit) MarshalJSONObject() ([]byte, error) {
 
 	m := &_commitMsg{
 		Author:    cm.Author,
+		BodyDelta: cm.BodyDelta,
 		Message:   cm.Message,
 		Path:      cm.Path,
 		Qri:       kind,
@@ -120,6 +145,26 @@ func (cm *Commit) MarshalJSONObject() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// BodyDelta counts the entries a new version changed in the previous
+// version's body
+type BodyDelta struct {
+	// Added is the number of entries inserted or appended
+	Added int `json:"added,omitempty"`
+	// Removed is the number of entries deleted
+	Removed int `json:"removed,omitempty"`
+	// Changed is the number of entries replaced with a new value
+	Changed int `json:"changed,omitempty"`
+}
+
+// Clone returns a copy of a body delta
+func (d *BodyDelta) Clone() *BodyDelta {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	return &c
+}
+
 // internal struct for json unmarshaling
 type _commitMsg Commit
 
@@ -136,6 +181,9 @@ func (cm *Commit) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &m); err != nil {
 		return fmt.Errorf("error unmarshling commit: %s", err.Error())
 	}
+	if err := checkKind(m.Qri); err != nil {
+		return err
+	}
 
 	*cm = Commit(m)
 	return nil
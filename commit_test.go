@@ -96,6 +96,7 @@ func TestCommitIsEmpty(t *testing.T) {
 	}{
 		{&Commit{Title: "a"}},
 		{&Commit{Author: &User{}}},
+		{&Commit{BodyDelta: &BodyDelta{}}},
 		{&Commit{Message: "a"}},
 		{&Commit{Signature: "a"}},
 		{&Commit{Timestamp: time.Now()}},
@@ -118,6 +119,7 @@ func TestCommitMarshalJSON(t *testing.T) {
 	}{
 		{&Commit{Title: "title", Timestamp: ts}, []byte(`{"qri":"cm:0","timestamp":"2001-01-01T01:01:01Z","title":"title"}`), nil},
 		{&Commit{Author: &User{ID: "foo"}, Timestamp: ts}, []byte(`{"author":{"id":"foo"},"qri":"cm:0","timestamp":"2001-01-01T01:01:01Z","title":""}`), nil},
+		{&Commit{BodyDelta: &BodyDelta{Added: 2, Removed: 1}, Timestamp: ts}, []byte(`{"bodyDelta":{"added":2,"removed":1},"qri":"cm:0","timestamp":"2001-01-01T01:01:01Z","title":""}`), nil},
 	}
 
 	for i, c := range cases {
@@ -153,6 +155,7 @@ func TestCommitMarshalJSONObject(t *testing.T) {
 	}{
 		{&Commit{Title: "title", Timestamp: ts}, []byte(`{"qri":"cm:0","timestamp":"2001-01-01T01:01:01Z","title":"title"}`), nil},
 		{&Commit{Author: &User{ID: "foo"}, Timestamp: ts}, []byte(`{"author":{"id":"foo"},"qri":"cm:0","timestamp":"2001-01-01T01:01:01Z","title":""}`), nil},
+		{&Commit{BodyDelta: &BodyDelta{Added: 2, Removed: 1}, Timestamp: ts}, []byte(`{"bodyDelta":{"added":2,"removed":1},"qri":"cm:0","timestamp":"2001-01-01T01:01:01Z","title":""}`), nil},
 	}
 
 	for i, c := range cases {
@@ -29,6 +29,9 @@ func CompareDatasets(a, b *Dataset) error {
 	if a.BodyPath != b.BodyPath {
 		return fmt.Errorf("BodyPath: %s != %s", a.BodyPath, b.BodyPath)
 	}
+	if !reflect.DeepEqual(a.BodyShards, b.BodyShards) {
+		return fmt.Errorf("BodyShards mismatch")
+	}
 	if err := CompareCommits(a.Commit, b.Commit); err != nil {
 		return fmt.Errorf("Commit: %s", err.Error())
 	}
@@ -116,6 +119,43 @@ func CompareMetas(a, b *Meta) error {
 		return fmt.Errorf("Theme: %s", err.Error())
 	}
 
+	if !reflect.DeepEqual(a.Translations, b.Translations) {
+		return fmt.Errorf("Translations mismatch")
+	}
+
+	if !reflect.DeepEqual(a.Spatial, b.Spatial) {
+		return fmt.Errorf("Spatial mismatch")
+	}
+
+	if !reflect.DeepEqual(a.Temporal, b.Temporal) {
+		return fmt.Errorf("Temporal mismatch")
+	}
+
+	if !reflect.DeepEqual(a.Publisher, b.Publisher) {
+		return fmt.Errorf("Publisher mismatch")
+	}
+
+	if !reflect.DeepEqual(a.ContactPoint, b.ContactPoint) {
+		return fmt.Errorf("ContactPoint mismatch")
+	}
+
+	if !reflect.DeepEqual(a.Identifiers, b.Identifiers) {
+		return fmt.Errorf("Identifiers mismatch")
+	}
+
+	if !reflect.DeepEqual(a.Themes, b.Themes) {
+		return fmt.Errorf("Themes mismatch")
+	}
+
+	if a.AccessLevel != b.AccessLevel {
+		return fmt.Errorf("AccessLevel: %s != %s", a.AccessLevel, b.AccessLevel)
+	}
+	if a.Sensitivity != b.Sensitivity {
+		return fmt.Errorf("Sensitivity: %s != %s", a.Sensitivity, b.Sensitivity)
+	}
+	if !reflect.DeepEqual(a.Deprecated, b.Deprecated) {
+		return fmt.Errorf("Deprecated mismatch")
+	}
 	// TODO - currently we're ignoring abitrary metadata differences
 	// if err := compare.MapStringInterface(a.Meta(), b.Meta()); err != nil {
 	// 	return fmt.Errorf("meta: %s", err.Error())
@@ -159,6 +199,9 @@ func CompareStructures(a, b *Structure) error {
 	if a.Compression != b.Compression {
 		return fmt.Errorf("Compression: %s != %s", a.Compression, b.Compression)
 	}
+	if a.CRS != b.CRS {
+		return fmt.Errorf("CRS: %s != %s", a.CRS, b.CRS)
+	}
 
 	if (a.FormatConfig != nil && b.FormatConfig == nil) || (a.FormatConfig == nil && b.FormatConfig != nil) {
 		return fmt.Errorf("FormatConfig nil mismatch")
@@ -166,6 +209,23 @@ func CompareStructures(a, b *Structure) error {
 		return fmt.Errorf("FormatConfig mismatch")
 	}
 
+	if (a.Geometry != nil && b.Geometry == nil) || (a.Geometry == nil && b.Geometry != nil) {
+		return fmt.Errorf("Geometry nil mismatch")
+	} else if a.Geometry != nil && b.Geometry != nil && !reflect.DeepEqual(a.Geometry, b.Geometry) {
+		return fmt.Errorf("Geometry mismatch")
+	}
+
+	if (a.Partition != nil && b.Partition == nil) || (a.Partition == nil && b.Partition != nil) {
+		return fmt.Errorf("Partition nil mismatch")
+	} else if a.Partition != nil && b.Partition != nil && *a.Partition != *b.Partition {
+		return fmt.Errorf("Partition mismatch")
+	}
+	if (a.Source != nil && b.Source == nil) || (a.Source == nil && b.Source != nil) {
+		return fmt.Errorf("Source nil mismatch")
+	} else if a.Source != nil && b.Source != nil && !reflect.DeepEqual(a.Source, b.Source) {
+		return fmt.Errorf("Source mismatch")
+	}
+
 	if err := CompareSchemas(a.Schema, b.Schema); err != nil {
 		return fmt.Errorf("Schema: %s", err.Error())
 	}
@@ -271,6 +331,9 @@ func CompareCommits(a, b *Commit) error {
 	if a.Message != b.Message {
 		return fmt.Errorf("Message: %s != %s", a.Message, b.Message)
 	}
+	if !reflect.DeepEqual(a.BodyDelta, b.BodyDelta) {
+		return fmt.Errorf("BodyDelta: %v != %v", a.BodyDelta, b.BodyDelta)
+	}
 
 	return nil
 }
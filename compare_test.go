@@ -83,6 +83,13 @@ func TestCompareStructures(t *testing.T) {
 		{&Structure{Format: "csv"}, &Structure{Format: ""}, "Format: csv != "},
 		{&Structure{Encoding: "a"}, &Structure{Encoding: "b"}, "Encoding: a != b"},
 		{&Structure{Compression: ""}, &Structure{Compression: compression.Tar.String()}, "Compression:  != tar"},
+		{&Structure{CRS: "EPSG:4326"}, &Structure{CRS: "EPSG:25832"}, "CRS: EPSG:4326 != EPSG:25832"},
+		{&Structure{Geometry: &GeometryRules{X: "lon", Y: "lat"}}, &Structure{}, "Geometry nil mismatch"},
+		{&Structure{Geometry: &GeometryRules{X: "lon", Y: "lat"}}, &Structure{Geometry: &GeometryRules{Geometry: "geom"}}, "Geometry mismatch"},
+		{&Structure{Partition: &Partition{Column: "date"}}, &Structure{}, "Partition nil mismatch"},
+		{&Structure{Partition: &Partition{Column: "date"}}, &Structure{Partition: &Partition{Column: "date", Granularity: PartitionDay}}, "Partition mismatch"},
+		{&Structure{Source: &SourceFormat{Format: "csv"}}, &Structure{}, "Source nil mismatch"},
+		{&Structure{Source: &SourceFormat{Format: "csv"}}, &Structure{Source: &SourceFormat{Format: "json"}}, "Source mismatch"},
 		{&Structure{}, &Structure{Schema: map[string]interface{}{}}, "Schema: nil: <nil> != <not nil>"},
 	}
 
@@ -143,6 +150,7 @@ func TestCompareCommits(t *testing.T) {
 		{&Commit{Message: "a"}, &Commit{Message: "b"}, "Message: a != b"},
 		{&Commit{Qri: "a"}, &Commit{Qri: "b"}, "Qri: a != b"},
 		{&Commit{Signature: "a"}, &Commit{Signature: "b"}, "Signature: a != b"},
+		{&Commit{BodyDelta: &BodyDelta{Added: 1}}, &Commit{BodyDelta: &BodyDelta{Added: 2}}, "BodyDelta: &{1 0 0} != &{2 0 0}"},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,208 @@
+package dataset
+
+import "fmt"
+
+// Option configures a dataset created with New
+type Option func(ds *Dataset)
+
+// New creates a dataset, applying options in order and validating the
+// result. Datasets require commit & structure components, so New must be
+// given at least WithCommit and WithStructure options
+func New(opts ...Option) (*Dataset, error) {
+	ds := &Dataset{Qri: KindDataset.String()}
+	for _, opt := range opts {
+		opt(ds)
+	}
+	if err := ds.Validate(); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// WithCommit sets the commit component of a dataset
+func WithCommit(cm *Commit) Option {
+	return func(ds *Dataset) {
+		ds.Commit = cm
+	}
+}
+
+// WithMeta sets the meta component of a dataset
+func WithMeta(md *Meta) Option {
+	return func(ds *Dataset) {
+		ds.Meta = md
+	}
+}
+
+// WithStructure sets the structure component of a dataset
+func WithStructure(st *Structure) Option {
+	return func(ds *Dataset) {
+		ds.Structure = st
+	}
+}
+
+// WithTransform sets the transform component of a dataset
+func WithTransform(q *Transform) Option {
+	return func(ds *Dataset) {
+		ds.Transform = q
+	}
+}
+
+// WithViz sets the viz component of a dataset
+func WithViz(v *Viz) Option {
+	return func(ds *Dataset) {
+		ds.Viz = v
+	}
+}
+
+// WithReadme sets the readme component of a dataset
+func WithReadme(r *Readme) Option {
+	return func(ds *Dataset) {
+		ds.Readme = r
+	}
+}
+
+// WithBody sets the body of a dataset, represented with native go types
+func WithBody(body interface{}) Option {
+	return func(ds *Dataset) {
+		ds.Body = body
+	}
+}
+
+// WithBodyPath sets the path of a dataset body
+func WithBodyPath(path string) Option {
+	return func(ds *Dataset) {
+		ds.BodyPath = path
+	}
+}
+
+// WithPreviousPath sets the path of the previous version of a dataset
+func WithPreviousPath(path string) Option {
+	return func(ds *Dataset) {
+		ds.PreviousPath = path
+	}
+}
+
+// StructureOption configures a structure created with NewStructure
+type StructureOption func(st *Structure)
+
+// NewStructure creates a structure for a data format, applying options in
+// order and validating the result, including any format configuration
+func NewStructure(format DataFormat, opts ...StructureOption) (*Structure, error) {
+	st := &Structure{
+		Qri:    KindStructure.String(),
+		Format: format.String(),
+	}
+	for _, opt := range opts {
+		opt(st)
+	}
+	if err := validateStructure(st); err != nil {
+		return nil, err
+	}
+	if st.FormatConfig != nil {
+		df, _ := ParseDataFormatString(st.Format)
+		if _, err := ParseFormatConfigMap(df, st.FormatConfig); err != nil {
+			return nil, fmt.Errorf("formatConfig: %s", err)
+		}
+	}
+	return st, nil
+}
+
+// WithSchema sets the schema of a structure
+func WithSchema(schema map[string]interface{}) StructureOption {
+	return func(st *Structure) {
+		st.Schema = schema
+	}
+}
+
+// WithFormatConfig sets the format configuration of a structure. A nil
+// config removes any format configuration
+func WithFormatConfig(cfg FormatConfig) StructureOption {
+	return func(st *Structure) {
+		if cfg == nil {
+			st.FormatConfig = nil
+			return
+		}
+		st.FormatConfig = cfg.Map()
+	}
+}
+
+// WithCompression sets the compression format of a structure
+func WithCompression(name string) StructureOption {
+	return func(st *Structure) {
+		st.Compression = name
+	}
+}
+
+// WithEncoding sets the character encoding of a structure
+func WithEncoding(encoding string) StructureOption {
+	return func(st *Structure) {
+		st.Encoding = encoding
+	}
+}
+
+// TransformOption configures a transform created with NewTransform
+type TransformOption func(q *Transform)
+
+// NewTransform creates a transform written in syntax, applying options in
+// order and validating the result
+func NewTransform(syntax string, opts ...TransformOption) (*Transform, error) {
+	q := &Transform{
+		Qri:    KindTransform.String(),
+		Syntax: syntax,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if err := validateTransform(q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// WithScript sets the script of a transform
+func WithScript(script []byte) TransformOption {
+	return func(q *Transform) {
+		q.ScriptBytes = script
+	}
+}
+
+// WithScriptPath sets the path of a transform's script
+func WithScriptPath(path string) TransformOption {
+	return func(q *Transform) {
+		q.ScriptPath = path
+	}
+}
+
+// WithSyntaxVersion sets the version of the transform syntax
+func WithSyntaxVersion(version string) TransformOption {
+	return func(q *Transform) {
+		q.SyntaxVersion = version
+	}
+}
+
+// WithConfigValue sets a single transform configuration value
+func WithConfigValue(key string, value interface{}) TransformOption {
+	return func(q *Transform) {
+		if q.Config == nil {
+			q.Config = map[string]interface{}{}
+		}
+		q.Config[key] = value
+	}
+}
+
+// WithResource adds a dataset or URL the transform reads, by name
+func WithResource(name, path string) TransformOption {
+	return func(q *Transform) {
+		if q.Resources == nil {
+			q.Resources = map[string]*TransformResource{}
+		}
+		q.Resources[name] = &TransformResource{Path: path}
+	}
+}
+
+// WithStep appends a step to a transform pipeline
+func WithStep(step *TransformStep) TransformOption {
+	return func(q *Transform) {
+		q.Steps = append(q.Steps, step)
+	}
+}
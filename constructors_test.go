@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	st, err := NewStructure(JSONDataFormat, WithSchema(BaseSchemaArray))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := &Commit{Title: "initial commit", Timestamp: time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC)}
+
+	ds, err := New(
+		WithCommit(cm),
+		WithStructure(st),
+		WithMeta(&Meta{Title: "title"}),
+		WithBody([]interface{}{"a"}),
+		WithPreviousPath("/ipfs/QmPrev"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Qri != KindDataset.String() {
+		t.Errorf("expected kind to be set, got: %q", ds.Qri)
+	}
+	if ds.Commit != cm || ds.Structure != st || ds.Meta.Title != "title" || ds.PreviousPath != "/ipfs/QmPrev" {
+		t.Errorf("expected options to apply, got: %#v", ds)
+	}
+
+	cases := []struct {
+		opts []Option
+		err  string
+	}{
+		{nil, "commit is required"},
+		{[]Option{WithCommit(cm)}, "structure is required"},
+		{[]Option{WithCommit(cm), WithStructure(st), WithTransform(&Transform{Resources: map[string]*TransformResource{"a": {}}})}, "transform: resource 'a': path is required"},
+	}
+	for i, c := range cases {
+		_, err := New(c.opts...)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestNewStructure(t *testing.T) {
+	csvOpts, err := NewCSVOptions(map[string]interface{}{"headerRow": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := NewStructure(CSVDataFormat, WithFormatConfig(csvOpts), WithCompression("gzip"), WithEncoding("utf-8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Format != "csv" || st.FormatConfig["headerRow"] != true || st.Compression != "gzip" || st.Encoding != "utf-8" {
+		t.Errorf("expected options to apply, got: %#v", st)
+	}
+	st, err = NewStructure(CSVDataFormat, WithFormatConfig(csvOpts), WithFormatConfig(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.FormatConfig != nil {
+		t.Errorf("expected a nil config to remove the format config, got: %v", st.FormatConfig)
+	}
+	if _, err = NewStructure(CSVDataFormat, WithFormatConfig((*CSVOptions)(nil))); err != nil {
+		t.Errorf("expected a nil csv config to be no config, got: %s", err)
+	}
+
+	cases := []struct {
+		format DataFormat
+		opts   []StructureOption
+		err    string
+	}{
+		{UnknownDataFormat, nil, "format is required"},
+		{CSVDataFormat, []StructureOption{WithCompression("lz5")}, `invalid compression type "lz5"`},
+		{CSVDataFormat, []StructureOption{func(st *Structure) { st.FormatConfig = map[string]interface{}{"headerRow": "yes"} }}, "formatConfig: invalid headerRow value: yes"},
+	}
+	for i, c := range cases {
+		_, err := NewStructure(c.format, c.opts...)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestNewTransform(t *testing.T) {
+	step := &TransformStep{Name: "load", Syntax: "starlark", ScriptBytes: []byte("load()")}
+	q, err := NewTransform("starlark",
+		WithScript([]byte("def transform(ds):\n  pass\n")),
+		WithSyntaxVersion("v0.1.0"),
+		WithConfigValue("limit", 10),
+		WithResource("a", "/ipfs/QmA"),
+		WithStep(step),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Qri != KindTransform.String() || q.Syntax != "starlark" || q.SyntaxVersion != "v0.1.0" {
+		t.Errorf("expected syntax & kind to be set, got: %#v", q)
+	}
+	if q.Config["limit"] != 10 || q.Resources["a"].Path != "/ipfs/QmA" || len(q.Steps) != 1 || q.Steps[0] != step {
+		t.Errorf("expected options to apply, got: %#v", q)
+	}
+	if _, err := NewTransform("starlark", WithScriptPath("/ipfs/QmScript")); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewTransform("starlark", WithResource("a", "")); err == nil || err.Error() != "resource 'a': path is required" {
+		t.Errorf("expected resource error, got: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package dataset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseCRS parses a coordinate reference system identifier of the form
+// "EPSG:<code>", giving the numeric EPSG code. The authority prefix is case
+// insensitive
+func ParseCRS(crs string) (int, error) {
+	parts := strings.SplitN(crs, ":", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "EPSG") {
+		return 0, fmt.Errorf("invalid CRS %q, must be of the form EPSG:<code>", crs)
+	}
+	code, err := strconv.Atoi(parts[1])
+	if err != nil || code <= 0 {
+		return 0, fmt.Errorf("invalid CRS %q, EPSG code must be a positive integer", crs)
+	}
+	return code, nil
+}
@@ -0,0 +1,29 @@
+package dataset
+
+import "testing"
+
+func TestParseCRS(t *testing.T) {
+	cases := []struct {
+		in   string
+		code int
+		err  string
+	}{
+		{"EPSG:4326", 4326, ""},
+		{"epsg:25832", 25832, ""},
+		{"", 0, `invalid CRS "", must be of the form EPSG:<code>`},
+		{"4326", 0, `invalid CRS "4326", must be of the form EPSG:<code>`},
+		{"ESRI:102100", 0, `invalid CRS "ESRI:102100", must be of the form EPSG:<code>`},
+		{"EPSG:utm", 0, `invalid CRS "EPSG:utm", EPSG code must be a positive integer`},
+		{"EPSG:-1", 0, `invalid CRS "EPSG:-1", EPSG code must be a positive integer`},
+	}
+	for _, c := range cases {
+		code, err := ParseCRS(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%q error mismatch. expected: %q, got: %v", c.in, c.err, err)
+			continue
+		}
+		if code != c.code {
+			t.Errorf("%q code mismatch. expected: %d, got: %d", c.in, c.code, code)
+		}
+	}
+}
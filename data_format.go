@@ -32,6 +32,9 @@ const (
 	XMLDataFormat
 	// XLSXDataFormat specifies microsoft excel formatted data
 	XLSXDataFormat
+	// PBFDataFormat specifies OpenStreetMap protocol buffer binary extracts.
+	// PBF data can be read but not written
+	PBFDataFormat
 )
 
 // SupportedDataFormats gives a slice of data formats that are
@@ -56,6 +59,7 @@ func (f DataFormat) String() string {
 		XMLDataFormat:     "xml",
 		XLSXDataFormat:    "xlsx",
 		CBORDataFormat:    "cbor",
+		PBFDataFormat:     "pbf",
 	}[f]
 
 	if !ok {
@@ -80,6 +84,8 @@ func ParseDataFormatString(s string) (df DataFormat, err error) {
 		"xlsx":  XLSXDataFormat,
 		"cbor":  CBORDataFormat,
 		".cbor": CBORDataFormat,
+		"pbf":   PBFDataFormat,
+		".pbf":  PBFDataFormat,
 	}[s]
 	if !ok {
 		err = fmt.Errorf("invalid data format: `%s`", s)
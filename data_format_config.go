@@ -2,6 +2,10 @@ package dataset
 
 import (
 	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset/vals"
 )
 
 // FormatConfig is the interface for data format configurations
@@ -22,6 +26,8 @@ func ParseFormatConfigMap(f DataFormat, opts map[string]interface{}) (FormatConf
 		return NewJSONOptions(opts)
 	case XLSXDataFormat:
 		return NewXLSXOptions(opts)
+	case PBFDataFormat:
+		return NewPBFOptions(opts)
 	default:
 		return nil, fmt.Errorf("cannot parse configuration for format: %s", f.String())
 	}
@@ -34,6 +40,22 @@ func NewCSVOptions(opts map[string]interface{}) (*CSVOptions, error) {
 		return o, nil
 	}
 
+	if opts["commentPrefix"] != nil {
+		if cp, ok := opts["commentPrefix"].(string); ok {
+			o.CommentPrefix = cp
+		} else {
+			return nil, fmt.Errorf("invalid commentPrefix value: %v", opts["commentPrefix"])
+		}
+	}
+
+	if opts["decimalNumbers"] != nil {
+		if dn, ok := opts["decimalNumbers"].(bool); ok {
+			o.DecimalNumbers = dn
+		} else {
+			return nil, fmt.Errorf("invalid decimalNumbers value: %v", opts["decimalNumbers"])
+		}
+	}
+
 	if opts["headerRow"] != nil {
 		if headerRow, ok := opts["headerRow"].(bool); ok {
 			o.HeaderRow = headerRow
@@ -50,6 +72,22 @@ func NewCSVOptions(opts map[string]interface{}) (*CSVOptions, error) {
 		}
 	}
 
+	if opts["nullValues"] != nil {
+		nv, err := stringsOption(opts, "nullValues")
+		if err != nil {
+			return nil, err
+		}
+		o.NullValues = nv
+	}
+
+	if opts["skipBlankLines"] != nil {
+		if sb, ok := opts["skipBlankLines"].(bool); ok {
+			o.SkipBlankLines = sb
+		} else {
+			return nil, fmt.Errorf("invalid skipBlankLines value: %v", opts["skipBlankLines"])
+		}
+	}
+
 	if opts["separator"] != nil {
 		if sep, ok := opts["separator"].(string); ok {
 			if len(sep) != 1 {
@@ -69,22 +107,172 @@ func NewCSVOptions(opts map[string]interface{}) (*CSVOptions, error) {
 		}
 	}
 
+	if err := o.CSVDialect.parse(opts); err != nil {
+		return nil, err
+	}
+
+	if opts["columns"] != nil {
+		cols, ok := opts["columns"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid columns value: %v", opts["columns"])
+		}
+		titles := make([]string, 0, len(cols))
+		for title := range cols {
+			titles = append(titles, title)
+		}
+		sort.Strings(titles)
+		o.Columns = map[string]*CSVDialect{}
+		for _, title := range titles {
+			v := cols[title]
+			copts, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid column %q value: %v", title, v)
+			}
+			d := &CSVDialect{}
+			if err := d.parse(copts); err != nil {
+				return nil, fmt.Errorf("column %q: %w", title, err)
+			}
+			if d.DecimalSeparator == 0 && d.GroupSeparator != 0 && d.GroupSeparator == o.DecimalSeparator {
+				// the column inherits the file-wide decimal separator
+				return nil, fmt.Errorf("column %q: groupSeparator must differ from the file-wide decimalSeparator", title)
+			}
+			o.Columns[title] = d
+		}
+	}
+
 	return o, nil
 }
 
+// stringsOption reads a list of strings option, which is []interface{} when
+// decoded from JSON
+func stringsOption(opts map[string]interface{}, key string) ([]string, error) {
+	switch v := opts[key].(type) {
+	case []string:
+		return append([]string(nil), v...), nil
+	case []interface{}:
+		strs := make([]string, len(v))
+		for i, x := range v {
+			s, ok := x.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s value: %v", key, opts[key])
+			}
+			strs[i] = s
+		}
+		return strs, nil
+	}
+	return nil, fmt.Errorf("invalid %s value: %v", key, opts[key])
+}
+
+// runeOption reads a single character option
+func runeOption(opts map[string]interface{}, key string) (rune, error) {
+	s, ok := opts[key].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid %s value: %v", key, opts[key])
+	}
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("%s must be a single character", key)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r, nil
+}
+
+// CSVDialect configures reading localized cells of csv files, either for a
+// whole file or a single column
+type CSVDialect struct {
+	// TrueValues & FalseValues are words read as booleans in addition to
+	// "true", "t", "1", "false", "f" & "0", eg. "yes" & "no" or "ja" &
+	// "nein". Matched case-insensitively
+	TrueValues  []string `json:"trueValues,omitempty"`
+	FalseValues []string `json:"falseValues,omitempty"`
+	// DecimalSeparator separates the whole & fractional parts of numbers.
+	// defaults to '.'
+	DecimalSeparator rune `json:"decimalSeparator,omitempty"`
+	// GroupSeparator separates groups of digits in numbers, eg. the ',' in
+	// "1,234.5". defaults to no grouping
+	GroupSeparator rune `json:"groupSeparator,omitempty"`
+}
+
+// parse reads dialect options from a configuration map
+func (d *CSVDialect) parse(opts map[string]interface{}) (err error) {
+	if opts["trueValues"] != nil {
+		if d.TrueValues, err = stringsOption(opts, "trueValues"); err != nil {
+			return err
+		}
+	}
+	if opts["falseValues"] != nil {
+		if d.FalseValues, err = stringsOption(opts, "falseValues"); err != nil {
+			return err
+		}
+	}
+	if opts["decimalSeparator"] != nil {
+		if d.DecimalSeparator, err = runeOption(opts, "decimalSeparator"); err != nil {
+			return err
+		}
+	}
+	if opts["groupSeparator"] != nil {
+		if d.GroupSeparator, err = runeOption(opts, "groupSeparator"); err != nil {
+			return err
+		}
+	}
+	if d.DecimalSeparator != 0 && d.DecimalSeparator == d.GroupSeparator {
+		return fmt.Errorf("decimalSeparator & groupSeparator must differ")
+	}
+	return nil
+}
+
+// IsEmpty checks if a dialect has no values
+func (d *CSVDialect) IsEmpty() bool {
+	return d == nil || len(d.TrueValues) == 0 && len(d.FalseValues) == 0 && d.DecimalSeparator == 0 && d.GroupSeparator == 0
+}
+
+// addTo writes dialect values to a configuration map
+func (d *CSVDialect) addTo(opt map[string]interface{}) {
+	if len(d.TrueValues) > 0 {
+		opt["trueValues"] = append([]string(nil), d.TrueValues...)
+	}
+	if len(d.FalseValues) > 0 {
+		opt["falseValues"] = append([]string(nil), d.FalseValues...)
+	}
+	if d.DecimalSeparator != 0 {
+		opt["decimalSeparator"] = string(d.DecimalSeparator)
+	}
+	if d.GroupSeparator != 0 {
+		opt["groupSeparator"] = string(d.GroupSeparator)
+	}
+}
+
 // CSVOptions specifies configuration details for csv files
 // This'll expand in the future to interoperate with okfn csv spec
 type CSVOptions struct {
+	// CSVDialect configures reading localized cells of all columns
+	CSVDialect
+	// Columns overrides the dialect of columns by title. Values set for a
+	// column replace the file-wide values
+	Columns map[string]*CSVDialect `json:"columns,omitempty"`
+	// CommentPrefix starts comment lines at the beginning of a file, which
+	// are skipped. Lines are comments if they start with the prefix after
+	// any indentation
+	CommentPrefix string `json:"commentPrefix,omitempty"`
+	// DecimalNumbers decodes number columns as arbitrary-precision decimals
+	// instead of float64, see vals.Decimal
+	DecimalNumbers bool `json:"decimalNumbers,omitempty"`
 	// HeaderRow specifies weather this csv file has a header row or not
 	HeaderRow bool `json:"headerRow"`
 	// If LazyQuotes is true, a quote may appear in an unquoted field and a
 	// non-doubled quote may appear in a quoted field.
 	LazyQuotes bool `json:"lazyQuotes"`
+	// NullValues lists cell values read as null, eg. "", "NA" or "-". Cells
+	// are matched exactly after trimming surrounding whitespace. When empty
+	// no cell is null, other than cells of null-typed columns
+	NullValues []string `json:"nullValues,omitempty"`
 	// Separator is the field delimiter.
 	// It is set to comma (',') by NewReader.
 	// Comma must be a valid rune and must not be \r, \n,
 	// or the Unicode replacement character (0xFFFD).
 	Separator rune `json:"separator,omitempty"`
+	// SkipBlankLines skips lines of only whitespace at the beginning of a
+	// file. Empty lines are always skipped
+	SkipBlankLines bool `json:"skipBlankLines,omitempty"`
 	// VariadicFields sets permits records to have a variable number of fields
 	// avoid using this
 	VariadicFields bool `json:"variadicFields"`
@@ -101,26 +289,108 @@ func (o *CSVOptions) Map() map[string]interface{} {
 		return nil
 	}
 	opt := map[string]interface{}{}
+	if o.CommentPrefix != "" {
+		opt["commentPrefix"] = o.CommentPrefix
+	}
+	if o.DecimalNumbers {
+		opt["decimalNumbers"] = o.DecimalNumbers
+	}
 	if o.HeaderRow {
 		opt["headerRow"] = o.HeaderRow
 	}
 	if o.LazyQuotes {
 		opt["lazyQuotes"] = o.LazyQuotes
 	}
+	if o.SkipBlankLines {
+		opt["skipBlankLines"] = o.SkipBlankLines
+	}
 	if o.VariadicFields {
 		opt["variadicFields"] = o.VariadicFields
 	}
+	if len(o.NullValues) > 0 {
+		opt["nullValues"] = append([]string(nil), o.NullValues...)
+	}
+	o.CSVDialect.addTo(opt)
+	if len(o.Columns) > 0 {
+		cols := map[string]interface{}{}
+		for title, d := range o.Columns {
+			copt := map[string]interface{}{}
+			if d != nil {
+				d.addTo(copt)
+			}
+			cols[title] = copt
+		}
+		opt["columns"] = cols
+	}
 	if o.Separator != rune(0) {
 		opt["separator"] = o.Separator
 	}
 	return opt
 }
 
+// Locale gives the locale cells of a column are read with, the file-wide
+// dialect with any values set for the column replacing file-wide values
+func (o *CSVOptions) Locale(column string) vals.Locale {
+	if o == nil {
+		return vals.Locale{}
+	}
+	d := o.CSVDialect
+	if cd := o.Columns[column]; cd != nil {
+		if cd.TrueValues != nil {
+			d.TrueValues = cd.TrueValues
+		}
+		if cd.FalseValues != nil {
+			d.FalseValues = cd.FalseValues
+		}
+		if cd.DecimalSeparator != 0 {
+			d.DecimalSeparator = cd.DecimalSeparator
+		}
+		if cd.GroupSeparator != 0 {
+			d.GroupSeparator = cd.GroupSeparator
+		} else if d.GroupSeparator == d.DecimalSeparator {
+			// a column decimal separator takes precedence over file-wide grouping
+			d.GroupSeparator = 0
+		}
+	}
+	return vals.Locale{
+		TrueValues:       d.TrueValues,
+		FalseValues:      d.FalseValues,
+		DecimalSeparator: d.DecimalSeparator,
+		GroupSeparator:   d.GroupSeparator,
+	}
+}
+
+// HasDialect checks if cells of any column are read with a localized dialect
+func (o *CSVOptions) HasDialect() bool {
+	if o == nil {
+		return false
+	}
+	if !o.CSVDialect.IsEmpty() {
+		return true
+	}
+	for _, d := range o.Columns {
+		if !d.IsEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
 // NewJSONOptions creates a JSONOptions pointer from a map
 func NewJSONOptions(opts map[string]interface{}) (*JSONOptions, error) {
 	if opts == nil {
 		opts = make(map[string]interface{})
 	}
+	if dn, ok := opts["decimalNumbers"]; ok {
+		if _, ok := dn.(bool); !ok {
+			return nil, fmt.Errorf("invalid decimalNumbers value: %v", dn)
+		}
+	}
+	if cp, ok := opts["commentPrefix"]; ok {
+		if _, ok := cp.(string); !ok {
+			return nil, fmt.Errorf("invalid commentPrefix value: %v", cp)
+		}
+	}
 	return &JSONOptions{Options: opts}, nil
 }
 
@@ -129,6 +399,26 @@ type JSONOptions struct {
 	Options map[string]interface{}
 }
 
+// DecimalNumbers reports if numbers should be decoded as arbitrary-precision
+// decimals instead of float64, set with the "decimalNumbers" option
+func (o *JSONOptions) DecimalNumbers() bool {
+	if o == nil {
+		return false
+	}
+	dn, _ := o.Options["decimalNumbers"].(bool)
+	return dn
+}
+
+// CommentPrefix gives the prefix of comment lines skipped at the beginning
+// of a file, set with the "commentPrefix" option, eg. "//"
+func (o *JSONOptions) CommentPrefix() string {
+	if o == nil {
+		return ""
+	}
+	cp, _ := o.Options["commentPrefix"].(string)
+	return cp
+}
+
 // Format announces the JSON Data Format for the FormatConfig interface
 func (*JSONOptions) Format() DataFormat {
 	return JSONDataFormat
@@ -182,3 +472,81 @@ func (o *XLSXOptions) Map() map[string]interface{} {
 
 	return opt
 }
+
+// PBFOptions specifies configuration details for OpenStreetMap PBF extracts.
+// Filters select the elements of an extract that are read as entries
+type PBFOptions struct {
+	// Types limits entries to the given element types, "node" or "way". All
+	// types are read when empty
+	Types []string `json:"types,omitempty"`
+	// Tags limits entries to elements with all of the given tags. A value of
+	// "*" matches any value of a tag
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// NewPBFOptions creates a PBFOptions pointer from a map
+func NewPBFOptions(opts map[string]interface{}) (*PBFOptions, error) {
+	o := &PBFOptions{}
+	if opts == nil {
+		return o, nil
+	}
+
+	if opts["types"] != nil {
+		types, ok := opts["types"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid types value: %v", opts["types"])
+		}
+		for _, t := range types {
+			if s, ok := t.(string); ok && (s == "node" || s == "way") {
+				o.Types = append(o.Types, s)
+			} else {
+				return nil, fmt.Errorf("invalid type %v, must be one of node or way", t)
+			}
+		}
+	}
+
+	if opts["tags"] != nil {
+		tags, ok := opts["tags"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid tags value: %v", opts["tags"])
+		}
+		o.Tags = map[string]string{}
+		for k, v := range tags {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid value for tag %q: %v", k, v)
+			}
+			o.Tags[k] = s
+		}
+	}
+
+	return o, nil
+}
+
+// Format announces the PBF data format for the FormatConfig interface
+func (*PBFOptions) Format() DataFormat {
+	return PBFDataFormat
+}
+
+// Map structures PBFOptions as a map of string keys to values
+func (o *PBFOptions) Map() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
+	opt := map[string]interface{}{}
+	if len(o.Types) > 0 {
+		types := make([]interface{}, len(o.Types))
+		for i, t := range o.Types {
+			types[i] = t
+		}
+		opt["types"] = types
+	}
+	if len(o.Tags) > 0 {
+		tags := map[string]interface{}{}
+		for k, v := range o.Tags {
+			tags[k] = v
+		}
+		opt["tags"] = tags
+	}
+	return opt
+}
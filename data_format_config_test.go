@@ -2,7 +2,10 @@ package dataset
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
+
+	"github.com/qri-io/dataset/vals"
 )
 
 func CompareFormatConfigs(a, b FormatConfig) error {
@@ -31,6 +34,7 @@ func TestParseFormatConfigMap(t *testing.T) {
 		{CSVDataFormat, map[string]interface{}{}, &CSVOptions{}, ""},
 		{JSONDataFormat, map[string]interface{}{}, &JSONOptions{}, ""},
 		{XLSXDataFormat, map[string]interface{}{}, &XLSXOptions{}, ""},
+		{PBFDataFormat, map[string]interface{}{}, &PBFOptions{}, ""},
 	}
 
 	for i, c := range cases {
@@ -54,6 +58,8 @@ func TestNewCSVOptions(t *testing.T) {
 	}{
 		{nil, &CSVOptions{}, ""},
 		{map[string]interface{}{}, &CSVOptions{}, ""},
+		{map[string]interface{}{"decimalNumbers": true}, &CSVOptions{DecimalNumbers: true}, ""},
+		{map[string]interface{}{"decimalNumbers": "foo"}, nil, "invalid decimalNumbers value: foo"},
 		{map[string]interface{}{"headerRow": true}, &CSVOptions{HeaderRow: true}, ""},
 		{map[string]interface{}{"headerRow": "foo"}, nil, "invalid headerRow value: foo"},
 		{map[string]interface{}{"lazyQuotes": true}, &CSVOptions{LazyQuotes: true}, ""},
@@ -63,6 +69,23 @@ func TestNewCSVOptions(t *testing.T) {
 		{map[string]interface{}{"separator": true}, nil, "invalid separator value: true"},
 		{map[string]interface{}{"variadicFields": true}, &CSVOptions{VariadicFields: true}, ""},
 		{map[string]interface{}{"variadicFields": "foo"}, nil, "invalid variadicFields value: foo"},
+		{map[string]interface{}{"nullValues": []interface{}{"", "NA"}}, &CSVOptions{NullValues: []string{"", "NA"}}, ""},
+		{map[string]interface{}{"nullValues": []string{"-"}}, &CSVOptions{NullValues: []string{"-"}}, ""},
+		{map[string]interface{}{"nullValues": []interface{}{"NA", 0}}, nil, "invalid nullValues value: [NA 0]"},
+		{map[string]interface{}{"nullValues": "NA"}, nil, "invalid nullValues value: NA"},
+		{map[string]interface{}{"commentPrefix": "#", "skipBlankLines": true}, &CSVOptions{CommentPrefix: "#", SkipBlankLines: true}, ""},
+		{map[string]interface{}{"commentPrefix": 1}, nil, "invalid commentPrefix value: 1"},
+		{map[string]interface{}{"skipBlankLines": "yes"}, nil, "invalid skipBlankLines value: yes"},
+		{map[string]interface{}{"trueValues": []interface{}{"ja"}, "falseValues": []interface{}{"nein"}}, &CSVOptions{CSVDialect: CSVDialect{TrueValues: []string{"ja"}, FalseValues: []string{"nein"}}}, ""},
+		{map[string]interface{}{"decimalSeparator": ",", "groupSeparator": "’"}, &CSVOptions{CSVDialect: CSVDialect{DecimalSeparator: ',', GroupSeparator: '’'}}, ""},
+		{map[string]interface{}{"columns": map[string]interface{}{"done": map[string]interface{}{"trueValues": []interface{}{"x"}}}}, &CSVOptions{Columns: map[string]*CSVDialect{"done": {TrueValues: []string{"x"}}}}, ""},
+		{map[string]interface{}{"trueValues": "ja"}, nil, "invalid trueValues value: ja"},
+		{map[string]interface{}{"decimalSeparator": ",,"}, nil, "decimalSeparator must be a single character"},
+		{map[string]interface{}{"decimalSeparator": ",", "groupSeparator": ","}, nil, "decimalSeparator & groupSeparator must differ"},
+		{map[string]interface{}{"decimalSeparator": ",", "columns": map[string]interface{}{"km": map[string]interface{}{"groupSeparator": ","}}}, nil, `column "km": groupSeparator must differ from the file-wide decimalSeparator`},
+		{map[string]interface{}{"decimalSeparator": ",", "columns": map[string]interface{}{"km": map[string]interface{}{"decimalSeparator": ".", "groupSeparator": ","}}}, &CSVOptions{CSVDialect: CSVDialect{DecimalSeparator: ','}, Columns: map[string]*CSVDialect{"km": {DecimalSeparator: '.', GroupSeparator: ','}}}, ""},
+		{map[string]interface{}{"columns": []interface{}{}}, nil, "invalid columns value: []"},
+		{map[string]interface{}{"columns": map[string]interface{}{"done": map[string]interface{}{"groupSeparator": 1}}}, nil, `column "done": invalid groupSeparator value: 1`},
 	}
 
 	for i, c := range cases {
@@ -76,6 +99,22 @@ func TestNewCSVOptions(t *testing.T) {
 				t.Errorf("case %d HeaderRow expected: %t, got: %t", i, got.HeaderRow, c.res.HeaderRow)
 				continue
 			}
+			if got.DecimalNumbers != c.res.DecimalNumbers {
+				t.Errorf("case %d DecimalNumbers expected: %t, got: %t", i, c.res.DecimalNumbers, got.DecimalNumbers)
+				continue
+			}
+			if !reflect.DeepEqual(got.NullValues, c.res.NullValues) {
+				t.Errorf("case %d NullValues expected: %v, got: %v", i, c.res.NullValues, got.NullValues)
+				continue
+			}
+			if got.CommentPrefix != c.res.CommentPrefix || got.SkipBlankLines != c.res.SkipBlankLines {
+				t.Errorf("case %d preamble options expected: %q %t, got: %q %t", i, c.res.CommentPrefix, c.res.SkipBlankLines, got.CommentPrefix, got.SkipBlankLines)
+				continue
+			}
+			if !reflect.DeepEqual(got.CSVDialect, c.res.CSVDialect) || !reflect.DeepEqual(got.Columns, c.res.Columns) {
+				t.Errorf("case %d dialect expected: %#v, got: %#v", i, c.res, got)
+				continue
+			}
 		}
 	}
 }
@@ -87,6 +126,7 @@ func TestCSVOptionsMap(t *testing.T) {
 	}{
 		{nil, nil},
 		{&CSVOptions{HeaderRow: true}, map[string]interface{}{"headerRow": true}},
+		{&CSVOptions{DecimalNumbers: true}, map[string]interface{}{"decimalNumbers": true}},
 	}
 
 	for i, c := range cases {
@@ -99,6 +139,47 @@ func TestCSVOptionsMap(t *testing.T) {
 	}
 }
 
+func TestCSVOptionsLocale(t *testing.T) {
+	opts, err := NewCSVOptions(map[string]interface{}{
+		"trueValues":       []interface{}{"ja"},
+		"falseValues":      []interface{}{"nein"},
+		"decimalSeparator": ",",
+		"groupSeparator":   ".",
+		"columns": map[string]interface{}{
+			"done":  map[string]interface{}{"trueValues": []interface{}{"x"}, "falseValues": []interface{}{""}},
+			"price": map[string]interface{}{"decimalSeparator": "."},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.HasDialect() || (&CSVOptions{}).HasDialect() {
+		t.Errorf("HasDialect mismatch")
+	}
+
+	cases := []struct {
+		column string
+		expect vals.Locale
+	}{
+		{"name", vals.Locale{TrueValues: []string{"ja"}, FalseValues: []string{"nein"}, DecimalSeparator: ',', GroupSeparator: '.'}},
+		{"done", vals.Locale{TrueValues: []string{"x"}, FalseValues: []string{""}, DecimalSeparator: ',', GroupSeparator: '.'}},
+		{"price", vals.Locale{TrueValues: []string{"ja"}, FalseValues: []string{"nein"}, DecimalSeparator: '.'}},
+	}
+	for _, c := range cases {
+		if got := opts.Locale(c.column); !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("column %s locale mismatch. expected: %#v, got: %#v", c.column, c.expect, got)
+		}
+	}
+
+	again, err := NewCSVOptions(opts.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opts, again) {
+		t.Errorf("expected options to round-trip through Map. expected: %#v, got: %#v", opts, again)
+	}
+}
+
 func TestNewJSONOptions(t *testing.T) {
 	cases := []struct {
 		opts map[string]interface{}
@@ -107,14 +188,24 @@ func TestNewJSONOptions(t *testing.T) {
 	}{
 		{nil, &JSONOptions{}, ""},
 		{map[string]interface{}{}, &JSONOptions{}, ""},
+		{map[string]interface{}{"decimalNumbers": true}, &JSONOptions{Options: map[string]interface{}{"decimalNumbers": true}}, ""},
+		{map[string]interface{}{"decimalNumbers": "foo"}, nil, "invalid decimalNumbers value: foo"},
+		{map[string]interface{}{"commentPrefix": "//"}, &JSONOptions{Options: map[string]interface{}{"commentPrefix": "//"}}, ""},
+		{map[string]interface{}{"commentPrefix": true}, nil, "invalid commentPrefix value: true"},
 	}
 
 	for i, c := range cases {
-		_, err := NewJSONOptions(c.opts)
+		got, err := NewJSONOptions(c.opts)
 		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
-			t.Errorf("case %d error expected: '%s', got: '%s'", i, c.err, err.Error())
+			t.Errorf("case %d error expected: '%s', got: '%s'", i, c.err, err)
 			continue
 		}
+		if c.err == "" && got.DecimalNumbers() != c.res.DecimalNumbers() {
+			t.Errorf("case %d DecimalNumbers expected: %t, got: %t", i, c.res.DecimalNumbers(), got.DecimalNumbers())
+		}
+		if c.err == "" && got.CommentPrefix() != c.res.CommentPrefix() {
+			t.Errorf("case %d CommentPrefix expected: %q, got: %q", i, c.res.CommentPrefix(), got.CommentPrefix())
+		}
 	}
 }
 
@@ -189,3 +280,47 @@ func TestXLSXOptionsMap(t *testing.T) {
 		}
 	}
 }
+
+func TestNewPBFOptions(t *testing.T) {
+	cases := []struct {
+		opts map[string]interface{}
+		res  *PBFOptions
+		err  string
+	}{
+		{nil, &PBFOptions{}, ""},
+		{map[string]interface{}{"types": []interface{}{"node"}}, &PBFOptions{Types: []string{"node"}}, ""},
+		{map[string]interface{}{"types": "node"}, nil, "invalid types value: node"},
+		{map[string]interface{}{"types": []interface{}{"relation"}}, nil, "invalid type relation, must be one of node or way"},
+		{map[string]interface{}{"tags": map[string]interface{}{"amenity": "bicycle_parking", "covered": "*"}},
+			&PBFOptions{Tags: map[string]string{"amenity": "bicycle_parking", "covered": "*"}}, ""},
+		{map[string]interface{}{"tags": []interface{}{"amenity"}}, nil, "invalid tags value: [amenity]"},
+		{map[string]interface{}{"tags": map[string]interface{}{"capacity": 5}}, nil, `invalid value for tag "capacity": 5`},
+	}
+
+	for i, c := range cases {
+		got, err := NewPBFOptions(c.opts)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && !reflect.DeepEqual(c.res, got) {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.res, got)
+		}
+	}
+}
+
+func TestPBFOptionsMap(t *testing.T) {
+	var o *PBFOptions
+	if o.Map() != nil {
+		t.Error("expected nil options to give a nil map")
+	}
+
+	o = &PBFOptions{Types: []string{"way"}, Tags: map[string]string{"amenity": "*"}}
+	parsed, err := NewPBFOptions(o.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o, parsed) {
+		t.Errorf("round trip mismatch. expected: %v, got: %v", o, parsed)
+	}
+}
@@ -31,6 +31,7 @@ func TestDataFormatString(t *testing.T) {
 		{XMLDataFormat, "xml"},
 		{XLSXDataFormat, "xlsx"},
 		{CBORDataFormat, "cbor"},
+		{PBFDataFormat, "pbf"},
 	}
 
 	for i, c := range cases {
@@ -58,6 +59,8 @@ func TestParseDataFormatString(t *testing.T) {
 		{"xlsx", XLSXDataFormat, ""},
 		{"cbor", CBORDataFormat, ""},
 		{".cbor", CBORDataFormat, ""},
+		{"pbf", PBFDataFormat, ""},
+		{".pbf", PBFDataFormat, ""},
 	}
 
 	for i, c := range cases {
@@ -45,6 +45,10 @@ import (
 type Dataset struct {
 	// body file reader, doesn't serialize
 	bodyFile qfs.File
+	// bodyCache holds body content fetched by BodyReader from bodyCachePath,
+	// doesn't serialize
+	bodyCache     []byte
+	bodyCachePath string
 	// Body represents dataset data with native go types.
 	// Datasets have at most one body. Body, BodyBytes, and BodyPath
 	// work together, often with only one field used at a time
@@ -53,6 +57,9 @@ type Dataset struct {
 	BodyBytes []byte `json:"bodyBytes,omitempty"`
 	// BodyPath is the path to the hash of raw data as it resolves on the network
 	BodyPath string `json:"bodyPath,omitempty"`
+	// BodyShards are the stored parts of a body partitioned by the structure
+	// partition, in partition key order
+	BodyShards []*BodyShard `json:"bodyShards,omitempty"`
 
 	// Commit contains author & change message information that describes this
 	// version of a dataset
@@ -67,9 +74,14 @@ type Dataset struct {
 	Path string `json:"path,omitempty"`
 	// Peername of dataset owner, transient
 	Peername string `json:"peername,omitempty"`
+	// Preview is a small sample of the body for display without reading
+	// the full body
+	Preview *Preview `json:"preview,omitempty"`
 	// PreviousPath connects datasets to form a historical merkle-DAG of snapshots
 	// of this document, creating a version history
 	PreviousPath string `json:"previousPath,omitempty"`
+	// Provenance records the activity that produced this version
+	Provenance *Provenance `json:"provenance,omitempty"`
 	// ProfileID of dataset owner, transient
 	ProfileID string `json:"profileID,omitempty"`
 	// Readme is a path to the readme file for this dataset
@@ -79,6 +91,8 @@ type Dataset struct {
 	// Qri is a key for both identifying this document type, and versioning the
 	// dataset document definition itself. derived
 	Qri string `json:"qri"`
+	// Stats summarizes the values of the body
+	Stats *Stats `json:"stats,omitempty"`
 	// Structure of this dataset
 	Structure *Structure `json:"structure,omitempty"`
 	// Transform is a path to the transformation that generated this resource
@@ -93,12 +107,16 @@ func (ds *Dataset) IsEmpty() bool {
 	return ds.Body == nil &&
 		ds.BodyBytes == nil &&
 		ds.BodyPath == "" &&
+		ds.BodyShards == nil &&
 		ds.Commit == nil &&
 		ds.Meta == nil &&
 		ds.Name == "" &&
 		ds.Peername == "" &&
+		ds.Preview == nil &&
 		ds.PreviousPath == "" &&
 		ds.ProfileID == "" &&
+		ds.Provenance == nil &&
+		ds.Stats == nil &&
 		ds.Structure == nil &&
 		ds.Transform == nil &&
 		ds.Readme == nil &&
@@ -161,6 +179,15 @@ func (ds *Dataset) DropDerivedValues() {
 	if ds.Readme != nil {
 		ds.Readme.DropDerivedValues()
 	}
+	if ds.Provenance != nil {
+		ds.Provenance.DropDerivedValues()
+	}
+	if ds.Preview != nil {
+		ds.Preview.DropDerivedValues()
+	}
+	if ds.Stats != nil {
+		ds.Stats.DropDerivedValues()
+	}
 	if ds.Viz != nil {
 		ds.Viz.DropDerivedValues()
 	}
@@ -197,29 +224,14 @@ func (ds *Dataset) OpenBodyFile(ctx context.Context, resolver qfs.PathResolver)
 		return ErrInlineBody
 	}
 
-	if ds.BodyBytes != nil {
-		bodyPath := ds.BodyPath
-		if bodyPath == "" {
-			bodyPath = "body"
-		}
-		ds.bodyFile = qfs.NewMemfileBytes(bodyPath, ds.BodyBytes)
-		return nil
+	src, err := ds.BodySource(resolver)
+	if err != nil || src == nil {
+		return err
 	}
-
-	if ds.BodyPath == "" {
-		// nothing to resolve
-		return nil
-	}
-
-	if resolver == nil {
-		return ErrNoResolver
-	}
-
-	ds.bodyFile, err = resolver.Get(ctx, ds.BodyPath)
-	if err != nil {
+	if ds.bodyFile, err = src.Open(ctx); err != nil && err != ErrNoResolver {
 		return fmt.Errorf("opening dataset.bodyPath '%s': %s", ds.BodyPath, err)
 	}
-	return
+	return err
 }
 
 // SetBodyFile assigns the bodyFile.
@@ -254,6 +266,9 @@ func (ds *Dataset) Assign(datasets ...*Dataset) {
 		if d.BodyPath != "" {
 			ds.BodyPath = d.BodyPath
 		}
+		if d.BodyShards != nil {
+			ds.BodyShards = d.BodyShards
+		}
 
 		if ds.Commit == nil && d.Commit != nil {
 			ds.Commit = d.Commit
@@ -301,12 +316,59 @@ func (ds *Dataset) Assign(datasets ...*Dataset) {
 		} else if ds.Readme != nil {
 			ds.Readme.Assign(d.Readme)
 		}
+		if ds.Provenance == nil && d.Provenance != nil {
+			ds.Provenance = d.Provenance
+		} else if ds.Provenance != nil {
+			ds.Provenance.Assign(d.Provenance)
+		}
+		if ds.Preview == nil && d.Preview != nil {
+			ds.Preview = d.Preview
+		} else if ds.Preview != nil {
+			ds.Preview.Assign(d.Preview)
+		}
+		if ds.Stats == nil && d.Stats != nil {
+			ds.Stats = d.Stats
+		} else if ds.Stats != nil {
+			ds.Stats.Assign(d.Stats)
+		}
 
 		// TODO - wut dis?
 		ds.Commit.Assign(d.Commit)
 	}
 }
 
+// Clone returns a deep copy of a dataset and all of it's components. Open
+// files (body, scripts) are not copied, as they can only be read once. The
+// body is copied with the same rules as arbitrary metadata: types created by
+// json.Unmarshal are deep copies, all other types are copied by assignment
+func (ds *Dataset) Clone() *Dataset {
+	if ds == nil {
+		return nil
+	}
+	return &Dataset{
+		Body:         cloneValue(ds.Body),
+		BodyBytes:    cloneBytes(ds.BodyBytes),
+		BodyPath:     ds.BodyPath,
+		BodyShards:   cloneShards(ds.BodyShards),
+		Commit:       ds.Commit.Clone(),
+		Meta:         ds.Meta.Clone(),
+		Name:         ds.Name,
+		Path:         ds.Path,
+		Peername:     ds.Peername,
+		Preview:      ds.Preview.Clone(),
+		PreviousPath: ds.PreviousPath,
+		ProfileID:    ds.ProfileID,
+		Provenance:   ds.Provenance.Clone(),
+		Readme:       ds.Readme.Clone(),
+		NumVersions:  ds.NumVersions,
+		Qri:          ds.Qri,
+		Stats:        ds.Stats.Clone(),
+		Structure:    ds.Structure.Clone(),
+		Transform:    ds.Transform.Clone(),
+		Viz:          ds.Viz.Clone(),
+	}
+}
+
 // MarshalJSON uses a map to combine meta & standard fields.
 // Marshalling a map[string]interface{} automatically alpha-sorts the keys.
 func (ds *Dataset) MarshalJSON() ([]byte, error) {
@@ -336,10 +398,13 @@ func (ds *Dataset) UnmarshalJSON(data []byte) error {
 
 	d := _dataset{}
 	if err := json.Unmarshal(data, &d); err != nil {
-		return fmt.Errorf("unmarshaling dataset: %s", err.Error())
+		return fmt.Errorf("unmarshaling dataset: %w", err)
+	}
+	if err := checkKind(d.Qri); err != nil {
+		return err
 	}
 	*ds = Dataset(d)
-	return nil
+	return upgradeLegacyQuery(ds, data)
 }
 
 // UnmarshalDataset tries to extract a dataset type from an empty
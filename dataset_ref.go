@@ -0,0 +1,211 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	refHandlePattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+	refNamePattern   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+)
+
+// DatasetRef is a reference to a dataset that pairs a human-readable
+// handle & name with the immutable path of a version. The handle & name of a
+// dataset can change, the path of a version can't: a ref with both stays
+// readable after a rename & still resolves to the exact version it was
+// made for
+//
+// The string form of a ref is "handle/name@path", where either part may be
+// left out: "handle/name" is a mutable reference to the latest version of a
+// dataset, "@path" or a plain "/ipfs/QmHash" path an anonymous reference to
+// a version
+type DatasetRef struct {
+	// Handle of the dataset owner, eg. a peername
+	Handle string `json:"handle,omitempty"`
+	// Name of the dataset
+	Name string `json:"name,omitempty"`
+	// Path of the referenced version, eg. "/ipfs/QmHash"
+	Path string `json:"path,omitempty"`
+}
+
+// ParseDatasetRef reads a ref from it's string form, checking it's valid
+func ParseDatasetRef(s string) (*DatasetRef, error) {
+	s = strings.TrimSpace(s)
+	ref := &DatasetRef{}
+	if strings.HasPrefix(s, "/") {
+		ref.Path = s
+	} else {
+		alias := s
+		if i := strings.IndexByte(s, '@'); i >= 0 {
+			alias, ref.Path = s[:i], s[i+1:]
+		}
+		if alias != "" {
+			parts := strings.Split(alias, "/")
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid dataset ref '%s': expected handle/name", s)
+			}
+			ref.Handle, ref.Name = parts[0], parts[1]
+		}
+	}
+	if err := ref.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dataset ref '%s': %s", s, err)
+	}
+	return ref, nil
+}
+
+// String gives the string form of a ref, read by ParseDatasetRef
+func (r *DatasetRef) String() string {
+	s := ""
+	if r.Handle != "" || r.Name != "" {
+		s = r.Handle + "/" + r.Name
+	}
+	if r.Path != "" {
+		if s == "" {
+			return r.Path
+		}
+		s += "@" + r.Path
+	}
+	return s
+}
+
+// Alias gives the human-readable "handle/name" part of a ref, the empty
+// string for anonymous refs
+func (r *DatasetRef) Alias() string {
+	if r.Handle == "" && r.Name == "" {
+		return ""
+	}
+	return r.Handle + "/" + r.Name
+}
+
+// IsEmpty checks if a ref has no values
+func (r *DatasetRef) IsEmpty() bool {
+	return r.Handle == "" && r.Name == "" && r.Path == ""
+}
+
+// IsPinned checks if a ref resolves to an exact version
+func (r *DatasetRef) IsPinned() bool {
+	return r.Path != ""
+}
+
+// Validate checks a ref has a well-formed alias, path, or both
+func (r *DatasetRef) Validate() error {
+	if r.IsEmpty() {
+		return fmt.Errorf("handle & name or path is required")
+	}
+	if r.Handle != "" || r.Name != "" {
+		if r.Handle == "" {
+			return fmt.Errorf("handle is required")
+		}
+		if r.Name == "" {
+			return fmt.Errorf("name is required")
+		}
+		if !refHandlePattern.MatchString(r.Handle) {
+			return fmt.Errorf("invalid handle '%s'", r.Handle)
+		}
+		if !refNamePattern.MatchString(r.Name) {
+			return fmt.Errorf("invalid name '%s': names must start with a letter and contain only letters, numbers, '_' & '-'", r.Name)
+		}
+	}
+	if r.Path != "" {
+		// paths have a store prefix & a hash, eg. /ipfs/QmHash
+		parts := strings.Split(r.Path, "/")
+		if len(parts) < 3 || parts[0] != "" || parts[1] == "" || parts[2] == "" || strings.ContainsAny(r.Path, " \t\n@") {
+			return fmt.Errorf("invalid path '%s'", r.Path)
+		}
+	}
+	return nil
+}
+
+// Clone returns a copy of a ref
+func (r *DatasetRef) Clone() *DatasetRef {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
+
+// Equal checks if two refs are the same. Pinned refs are equal if their
+// paths are, regardless of names: the same version may be known by an old
+// & new name
+func (r *DatasetRef) Equal(b *DatasetRef) bool {
+	if r == nil || b == nil {
+		return r == b
+	}
+	if r.Path != "" || b.Path != "" {
+		return r.Path == b.Path
+	}
+	return r.Handle == b.Handle && r.Name == b.Name
+}
+
+// private version for marshalling purposes only
+type datasetRef DatasetRef
+
+// MarshalJSON implements the json.Marshaler interface, always encoding an
+// object
+func (r DatasetRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(datasetRef(r))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting either a
+// ref object or it's string form. Both forms must be valid refs
+func (r *DatasetRef) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		ref, err := ParseDatasetRef(s)
+		if err != nil {
+			return err
+		}
+		*r = *ref
+		return nil
+	}
+
+	_r := datasetRef{}
+	if err := json.Unmarshal(data, &_r); err != nil {
+		return fmt.Errorf("unmarshaling dataset ref: %w", err)
+	}
+	ref := DatasetRef(_r)
+	if err := ref.Validate(); err != nil {
+		return fmt.Errorf("invalid dataset ref: %s", err)
+	}
+	*r = ref
+	return nil
+}
+
+// Ref gives a reference to this version of a dataset, pairing the dataset
+// peername & name with it's path
+func (ds *Dataset) Ref() *DatasetRef {
+	return &DatasetRef{Handle: ds.Peername, Name: ds.Name, Path: ds.Path}
+}
+
+// PreviousRef gives a reference to the previous version of a dataset, which
+// shares the dataset peername & name. Returns nil for a first version
+func (ds *Dataset) PreviousRef() *DatasetRef {
+	if ds.PreviousPath == "" {
+		return nil
+	}
+	return &DatasetRef{Handle: ds.Peername, Name: ds.Name, Path: ds.PreviousPath}
+}
+
+// DatasetRef reads a dataset resource as a ref. A "handle/name" resource
+// path pinned to a version gives a ref with both the alias & the pinned
+// path. URL resources aren't dataset refs & error
+func (r *TransformResource) DatasetRef() (*DatasetRef, error) {
+	if r.IsURL() {
+		return nil, fmt.Errorf("resource '%s' is a url, not a dataset ref", r.Path)
+	}
+	ref, err := ParseDatasetRef(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	if r.Version != "" {
+		ref.Path = r.Version
+		if err := ref.Validate(); err != nil {
+			return nil, fmt.Errorf("resource '%s': invalid version: %s", r.Path, err)
+		}
+	}
+	return ref, nil
+}
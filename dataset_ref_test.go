@@ -0,0 +1,138 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDatasetRef(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect DatasetRef
+		str    string
+		err    string
+	}{
+		{"b5/movies", DatasetRef{Handle: "b5", Name: "movies"}, "b5/movies", ""},
+		{" b5/movies@/ipfs/QmHash ", DatasetRef{Handle: "b5", Name: "movies", Path: "/ipfs/QmHash"}, "b5/movies@/ipfs/QmHash", ""},
+		{"@/ipfs/QmHash", DatasetRef{Path: "/ipfs/QmHash"}, "/ipfs/QmHash", ""},
+		{"/map/QmHash", DatasetRef{Path: "/map/QmHash"}, "/map/QmHash", ""},
+		{"peer.name/world_bank-population", DatasetRef{Handle: "peer.name", Name: "world_bank-population"}, "peer.name/world_bank-population", ""},
+
+		{"", DatasetRef{}, "", "invalid dataset ref '': handle & name or path is required"},
+		{"movies", DatasetRef{}, "", "invalid dataset ref 'movies': expected handle/name"},
+		{"b5/movies/2019", DatasetRef{}, "", "invalid dataset ref 'b5/movies/2019': expected handle/name"},
+		{"/movies", DatasetRef{}, "", "invalid dataset ref '/movies': invalid path '/movies'"},
+		{"b5/movies@QmHash", DatasetRef{}, "", "invalid dataset ref 'b5/movies@QmHash': invalid path 'QmHash'"},
+		{"b5/", DatasetRef{}, "", "invalid dataset ref 'b5/': name is required"},
+		{"/movies@/ipfs/QmHash", DatasetRef{}, "", "invalid dataset ref '/movies@/ipfs/QmHash': invalid path '/movies@/ipfs/QmHash'"},
+		{"b5/2019", DatasetRef{}, "", "invalid dataset ref 'b5/2019': invalid name '2019': names must start with a letter and contain only letters, numbers, '_' & '-'"},
+		{"b 5/movies", DatasetRef{}, "", "invalid dataset ref 'b 5/movies': invalid handle 'b 5'"},
+	}
+
+	for _, c := range cases {
+		got, err := ParseDatasetRef(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case '%s' error mismatch. expected: '%s', got: '%s'", c.in, c.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if *got != c.expect {
+			t.Errorf("case '%s' result mismatch. expected: %#v, got: %#v", c.in, c.expect, got)
+		}
+		if got.String() != c.str {
+			t.Errorf("case '%s' string mismatch. expected: '%s', got: '%s'", c.in, c.str, got.String())
+		}
+	}
+}
+
+func TestDatasetRefJSON(t *testing.T) {
+	ref := &DatasetRef{Handle: "b5", Name: "movies", Path: "/ipfs/QmHash"}
+	data, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"handle":"b5","name":"movies","path":"/ipfs/QmHash"}` {
+		t.Errorf("unexpected encoding: %s", data)
+	}
+
+	cases := []struct {
+		in  string
+		err string
+	}{
+		{`{"handle":"b5","name":"movies","path":"/ipfs/QmHash"}`, ""},
+		{`"b5/movies@/ipfs/QmHash"`, ""},
+		{`"movies"`, "invalid dataset ref 'movies': expected handle/name"},
+		{`{"handle":"b5","path":"/ipfs/QmHash"}`, "invalid dataset ref: name is required"},
+		{`{"handle":"b5","name":"movies","path":"QmHash"}`, "invalid dataset ref: invalid path 'QmHash'"},
+		{`{}`, "invalid dataset ref: handle & name or path is required"},
+		{`5`, "unmarshaling dataset ref: json: cannot unmarshal number into Go value of type dataset.datasetRef"},
+	}
+	for _, c := range cases {
+		got := &DatasetRef{}
+		err := json.Unmarshal([]byte(c.in), got)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %s error mismatch. expected: '%s', got: '%s'", c.in, c.err, err)
+			continue
+		}
+		if err == nil && *got != *ref {
+			t.Errorf("case %s result mismatch. expected: %#v, got: %#v", c.in, ref, got)
+		}
+	}
+}
+
+func TestDatasetRefEqual(t *testing.T) {
+	cases := []struct {
+		a, b   *DatasetRef
+		expect bool
+	}{
+		{nil, nil, true},
+		{&DatasetRef{Handle: "b5", Name: "movies"}, nil, false},
+		{&DatasetRef{Handle: "b5", Name: "movies"}, &DatasetRef{Handle: "b5", Name: "movies"}, true},
+		{&DatasetRef{Handle: "b5", Name: "movies"}, &DatasetRef{Handle: "b5", Name: "films"}, false},
+		{&DatasetRef{Handle: "b5", Name: "movies", Path: "/ipfs/QmA"}, &DatasetRef{Handle: "b5", Name: "films", Path: "/ipfs/QmA"}, true},
+		{&DatasetRef{Handle: "b5", Name: "movies", Path: "/ipfs/QmA"}, &DatasetRef{Handle: "b5", Name: "movies", Path: "/ipfs/QmB"}, false},
+		{&DatasetRef{Handle: "b5", Name: "movies", Path: "/ipfs/QmA"}, &DatasetRef{Handle: "b5", Name: "movies"}, false},
+	}
+	for i, c := range cases {
+		if got := c.a.Equal(c.b); got != c.expect {
+			t.Errorf("case %d: expected %t, got %t", i, c.expect, got)
+		}
+	}
+}
+
+func TestDatasetRefs(t *testing.T) {
+	ds := &Dataset{Peername: "b5", Name: "movies", Path: "/ipfs/QmB", PreviousPath: "/ipfs/QmA"}
+	if got := ds.Ref().String(); got != "b5/movies@/ipfs/QmB" {
+		t.Errorf("ref mismatch, got: %s", got)
+	}
+	if got := ds.PreviousRef().String(); got != "b5/movies@/ipfs/QmA" {
+		t.Errorf("previous ref mismatch, got: %s", got)
+	}
+	if (&Dataset{}).PreviousRef() != nil {
+		t.Errorf("expected a first version to have no previous ref")
+	}
+
+	cases := []struct {
+		r      *TransformResource
+		expect string
+		err    string
+	}{
+		{&TransformResource{Path: "b5/movies"}, "b5/movies", ""},
+		{&TransformResource{Path: "b5/movies", Version: "/ipfs/QmA"}, "b5/movies@/ipfs/QmA", ""},
+		{&TransformResource{Path: "/ipfs/QmA"}, "/ipfs/QmA", ""},
+		{&TransformResource{Path: "b5/movies", Version: "QmA"}, "", "resource 'b5/movies': invalid version: invalid path 'QmA'"},
+		{&TransformResource{Path: "https://example.com/movies.csv"}, "", "resource 'https://example.com/movies.csv' is a url, not a dataset ref"},
+	}
+	for _, c := range cases {
+		ref, err := c.r.DatasetRef()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("resource %#v error mismatch. expected: '%s', got: '%s'", c.r, c.err, err)
+			continue
+		}
+		if err == nil && ref.String() != c.expect {
+			t.Errorf("resource %#v ref mismatch. expected: '%s', got: '%s'", c.r, c.expect, ref)
+		}
+	}
+}
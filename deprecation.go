@@ -0,0 +1,147 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ErrAllDeprecated is returned when resolving a dataset history that has no
+// non-deprecated versions
+var ErrAllDeprecated = fmt.Errorf("all versions are deprecated")
+
+// Deprecation marks a dataset as no longer maintained, acting as a
+// machine-readable tombstone. The JSON value true decodes to an empty
+// Deprecation
+type Deprecation struct {
+	// Date the dataset was deprecated
+	Date time.Time `json:"date,omitempty"`
+	// Reason is a human-readable explanation, eg. "feed discontinued"
+	Reason string `json:"reason,omitempty"`
+	// SupersededBy is the path to a dataset that replaces this one
+	SupersededBy string `json:"supersededBy,omitempty"`
+}
+
+type _deprecation Deprecation
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface
+func (d *Deprecation) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		if !b {
+			return fmt.Errorf("deprecated: false is not a valid value, omit the field instead")
+		}
+		*d = Deprecation{}
+		return nil
+	}
+
+	_d := _deprecation{}
+	if err := json.Unmarshal(data, &_d); err != nil {
+		return fmt.Errorf("unmarshaling deprecation: %s", err)
+	}
+	*d = Deprecation(_d)
+	return nil
+}
+
+// Decode reads json.Umarshal-style data into a Deprecation
+func (d *Deprecation) Decode(val interface{}) (err error) {
+	if b, ok := val.(bool); ok {
+		if !b {
+			return fmt.Errorf("false is not a valid value")
+		}
+		*d = Deprecation{}
+		return nil
+	}
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected bool or map[string]interface{}")
+	}
+	if d.Reason, err = strVal(msi["reason"]); err != nil {
+		return
+	}
+	if d.SupersededBy, err = strVal(msi["supersededBy"]); err != nil {
+		return
+	}
+	var date string
+	if date, err = strVal(msi["date"]); err != nil {
+		return
+	}
+	if date != "" {
+		if d.Date, err = time.Parse(time.RFC3339, date); err != nil {
+			return fmt.Errorf("date: %s", err)
+		}
+	}
+	return
+}
+
+// Clone returns a copy of a deprecation
+func (d *Deprecation) Clone() *Deprecation {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	return &c
+}
+
+// IsDeprecated checks if a dataset version is marked deprecated
+func (ds *Dataset) IsDeprecated() bool {
+	return ds.Meta != nil && ds.Meta.Deprecated != nil
+}
+
+// SupersededBy returns the path of the dataset that replaces this one, if
+// any
+func (ds *Dataset) SupersededBy() string {
+	if !ds.IsDeprecated() {
+		return ""
+	}
+	return ds.Meta.Deprecated.SupersededBy
+}
+
+// DatasetLoader fetches the dataset stored at a path
+type DatasetLoader func(ctx context.Context, path string) (*Dataset, error)
+
+// LatestNonDeprecated resolves the newest version that isn't deprecated,
+// starting from the head of a history. Supersession links are followed first,
+// so a deprecated dataset resolves to the head of it's replacement. Deprecated
+// versions without a successor are skipped by walking PreviousPath. Returns
+// ErrAllDeprecated once history is exhausted
+func LatestNonDeprecated(ctx context.Context, head *Dataset, load DatasetLoader) (*Dataset, error) {
+	if head == nil {
+		return nil, fmt.Errorf("dataset is required")
+	}
+	if load == nil {
+		return nil, ErrNoResolver
+	}
+
+	visited := map[string]bool{}
+	ds := head
+	for {
+		if !ds.IsDeprecated() {
+			return ds, nil
+		}
+		if ds.Path != "" {
+			visited[ds.Path] = true
+		}
+
+		next := ds.SupersededBy()
+		if next == "" {
+			next = ds.PreviousPath
+		}
+		if next == "" {
+			return nil, ErrAllDeprecated
+		}
+		if visited[next] {
+			return nil, fmt.Errorf("cycle detected resolving '%s'", next)
+		}
+
+		prev, err := load(ctx, next)
+		if err != nil {
+			return nil, fmt.Errorf("loading '%s': %s", next, err)
+		}
+		if prev.Path == "" {
+			prev.Path = next
+		}
+		ds = prev
+	}
+}
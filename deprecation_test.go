@@ -0,0 +1,96 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDeprecationJSON(t *testing.T) {
+	md := &Meta{}
+	if err := json.Unmarshal([]byte(`{"qri":"md:0","deprecated":true}`), md); err != nil {
+		t.Fatal(err)
+	}
+	if md.Deprecated == nil {
+		t.Errorf("expected deprecated: true to decode")
+	}
+	if err := json.Unmarshal([]byte(`{"deprecated":false}`), &Meta{}); err == nil {
+		t.Errorf("expected deprecated: false to error")
+	}
+
+	md = &Meta{}
+	data := []byte(`{"qri":"md:0","deprecated":{"date":"2019-06-01T00:00:00Z","reason":"feed discontinued","supersededBy":"/ipfs/QmNext"}}`)
+	if err := json.Unmarshal(data, md); err != nil {
+		t.Fatal(err)
+	}
+	expect := &Deprecation{Date: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), Reason: "feed discontinued", SupersededBy: "/ipfs/QmNext"}
+	if *md.Deprecated != *expect {
+		t.Errorf("deprecation mismatch. expected: %#v, got: %#v", expect, md.Deprecated)
+	}
+	if md.Meta()["deprecated"] != nil {
+		t.Errorf("deprecated should not be hoisted into arbitrary metadata")
+	}
+
+	if err := md.Set("deprecated", map[string]interface{}{"date": "yesterday"}); err == nil {
+		t.Errorf("expected invalid date to error")
+	}
+	if err := md.Set("deprecated", map[string]interface{}{"reason": "gone", "date": "2019-06-01T00:00:00Z"}); err != nil {
+		t.Error(err)
+	} else if md.Deprecated.Reason != "gone" || md.Deprecated.Date.IsZero() {
+		t.Errorf("set mismatch: %#v", md.Deprecated)
+	}
+}
+
+func TestLatestNonDeprecated(t *testing.T) {
+	deprecated := func(path, prev, next string) *Dataset {
+		return &Dataset{Path: path, PreviousPath: prev, Meta: &Meta{Deprecated: &Deprecation{SupersededBy: next}}}
+	}
+	store := map[string]*Dataset{
+		"/a/1": {Path: "/a/1", Meta: &Meta{Title: "a"}},
+		"/a/2": deprecated("/a/2", "/a/1", ""),
+		"/a/3": deprecated("/a/3", "/a/2", ""),
+		"/b/1": {Path: "/b/1", Meta: &Meta{Title: "b"}},
+		"/c/1": deprecated("/c/1", "", "/b/1"),
+		"/d/1": deprecated("/d/1", "", ""),
+		"/e/1": deprecated("/e/1", "", "/f/1"),
+		"/f/1": deprecated("/f/1", "", "/e/1"),
+	}
+	load := func(ctx context.Context, path string) (*Dataset, error) {
+		if ds, ok := store[path]; ok {
+			return ds, nil
+		}
+		return nil, fmt.Errorf("not found")
+	}
+
+	cases := []struct {
+		head   string
+		expect string
+		err    string
+	}{
+		{"/a/1", "/a/1", ""},
+		{"/a/3", "/a/1", ""},
+		{"/c/1", "/b/1", ""},
+		{"/d/1", "", "all versions are deprecated"},
+		{"/e/1", "", "cycle detected resolving '/e/1'"},
+	}
+
+	for i, c := range cases {
+		got, err := LatestNonDeprecated(context.Background(), store[c.head], load)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if err == nil && got.Path != c.expect {
+			t.Errorf("case %d path mismatch. expected: '%s', got: '%s'", i, c.expect, got.Path)
+		}
+	}
+
+	if _, err := LatestNonDeprecated(context.Background(), deprecated("", "/missing", ""), load); err == nil {
+		t.Errorf("expected load error")
+	}
+	if _, err := LatestNonDeprecated(context.Background(), store["/a/1"], nil); err != ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
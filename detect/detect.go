@@ -37,8 +37,12 @@ func FromFile(path string) (st *dataset.Structure, err error) {
 }
 
 // FromReader detects a dataset structure from a reader and data format, returning a detected dataset
-// structure, the number of bytes read from the reader, and any error
+// structure, the number of bytes read from the reader, and any error. Data
+// recognized by a registered hook is given the hook's structure
 func FromReader(format dataset.DataFormat, data io.Reader) (st *dataset.Structure, n int, err error) {
+	if st, n, data = fromHooks(format, data); st != nil {
+		return st, n, nil
+	}
 	st = &dataset.Structure{
 		Format: format.String(),
 	}
@@ -61,6 +65,8 @@ func ExtensionDataFormat(path string) (format dataset.DataFormat, err error) {
 		return dataset.XMLDataFormat, nil
 	case ".xlsx":
 		return dataset.XLSXDataFormat, nil
+	case ".pbf":
+		return dataset.PBFDataFormat, nil
 	case "":
 		return dataset.UnknownDataFormat, errors.New("no file extension provided")
 	default:
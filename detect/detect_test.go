@@ -89,6 +89,7 @@ func TestExtensionDataFormat(t *testing.T) {
 		{"foo/bar/baz.xml", dataset.XMLDataFormat, ""},
 		{"foo/bar/baz.xlsx", dataset.XLSXDataFormat, ""},
 		{"foo/bar/baz.cbor", dataset.CBORDataFormat, ""},
+		{"foo/bar/baz.osm.pbf", dataset.PBFDataFormat, ""},
 		{"foo/bar/baz", dataset.UnknownDataFormat, "no file extension provided"},
 		{"foo/bar/baz.jpg", dataset.UnknownDataFormat, "unsupported file type: '.jpg'"},
 	}
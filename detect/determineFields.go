@@ -1,6 +1,7 @@
 package detect
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -37,6 +38,9 @@ func Schema(r *dataset.Structure, data io.Reader) (schema map[string]interface{}
 		return CSVSchema(r, data)
 	case dataset.XLSXDataFormat:
 		return XLSXSchema(r, data)
+	case dataset.PBFDataFormat:
+		// PBF extracts are always read with the same schema
+		return dsio.PBFSchema(), 0, nil
 	default:
 		err = fmt.Errorf("'%s' is not supported for field detection", r.Format)
 		return
@@ -51,7 +55,10 @@ type field struct {
 // CSVSchema determines the field names and types of an io.Reader of CSV-formatted data, returning a json schema
 func CSVSchema(resource *dataset.Structure, data io.Reader) (schema map[string]interface{}, n int, err error) {
 	tr := dsio.NewTrackedReader(data)
-	r := csv.NewReader(replacecr.Reader(tr))
+	// a byte order mark would otherwise end up in the first title
+	br := bufio.NewReader(tr)
+	dsio.SkipPreamble(resource, br)
+	r := csv.NewReader(replacecr.Reader(br))
 	r.FieldsPerRecord = -1
 	r.TrimLeadingSpace = true
 	r.LazyQuotes = true
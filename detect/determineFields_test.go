@@ -154,3 +154,15 @@ func runTestCase(t *testing.T, description string, input []byte, expect map[stri
 		t.Errorf("mismatch for \"%s\" (-want +got):\n%s\n", description, diff)
 	}
 }
+
+func TestCSVSchemaByteOrderMark(t *testing.T) {
+	data := []byte("\xEF\xBB\xBFname,count\nalien,1\n")
+	schema, _, err := CSVSchema(&dataset.Structure{Format: "csv"}, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := schema["items"].(map[string]interface{})["items"].([]interface{})
+	if title := items[0].(map[string]interface{})["title"]; title != "name" {
+		t.Errorf("expected the byte order mark to be skipped, got title: %q", title)
+	}
+}
@@ -0,0 +1,50 @@
+package detect
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"github.com/qri-io/dataset"
+)
+
+// HookPeekSize is the number of leading bytes of data given to hooks
+const HookPeekSize = 4096
+
+// Hook recognizes data of a known shape from a prefix of its bytes, returning
+// a structure for recognized data and nil otherwise. prefix may end in the
+// middle of a value
+type Hook func(format dataset.DataFormat, prefix []byte) *dataset.Structure
+
+var (
+	hooksLk sync.RWMutex
+	hooks   []Hook
+)
+
+// RegisterHook adds a hook to the hooks FromReader checks before falling
+// back to generic detection. Hooks are checked in order of registration
+func RegisterHook(h Hook) {
+	hooksLk.Lock()
+	defer hooksLk.Unlock()
+	hooks = append(hooks, h)
+}
+
+// fromHooks checks registered hooks against a prefix of data. the returned
+// reader reads data from the start, including the prefix
+func fromHooks(format dataset.DataFormat, data io.Reader) (*dataset.Structure, int, io.Reader) {
+	hooksLk.RLock()
+	hs := hooks
+	hooksLk.RUnlock()
+	if len(hs) == 0 {
+		return nil, 0, data
+	}
+
+	br := bufio.NewReaderSize(data, HookPeekSize)
+	prefix, _ := br.Peek(HookPeekSize)
+	for _, h := range hs {
+		if st := h(format, prefix); st != nil {
+			return st, len(prefix), br
+		}
+	}
+	return nil, 0, br
+}
@@ -0,0 +1,42 @@
+package detect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestRegisterHook(t *testing.T) {
+	prev := hooks
+	defer func() { hooks = prev }()
+
+	preset := &dataset.Structure{Format: "json", Schema: map[string]interface{}{"type": "object", "title": "preset"}}
+	RegisterHook(func(format dataset.DataFormat, prefix []byte) *dataset.Structure {
+		if format == dataset.JSONDataFormat && bytes.Contains(prefix, []byte(`"preset"`)) {
+			return preset
+		}
+		return nil
+	})
+
+	st, n, err := FromReader(dataset.JSONDataFormat, bytes.NewBufferString(`{"preset":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st != preset {
+		t.Errorf("expected hook structure, got: %v", st)
+	}
+	if n != 15 {
+		t.Errorf("expected 15 bytes read, got %d", n)
+	}
+
+	// unrecognized data falls back to generic detection, reading from the
+	// start of the data
+	st, _, err = FromReader(dataset.JSONDataFormat, bytes.NewBufferString(`  [{"a":1}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dataset.CompareStructures(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}, st); err != nil {
+		t.Errorf("fallback structure mismatch: %s", err)
+	}
+}
@@ -125,13 +125,16 @@ func DiffData(a, b *dataset.Dataset) (*SubDiff, error) {
 func DiffTransform(a, b *dataset.Transform) (*SubDiff, error) {
 	var emptyDiff = &SubDiff{kind: "transform"}
 
-	// Make copies of the input structs, since we might modify them.
+	// Make copies of the input structs, since we might modify them. Secrets
+	// are redacted before diffing
 	var left, right dataset.Transform
 	if a != nil {
-		left = *a
+		left = *a.Clone()
+		left.RedactSecrets()
 	}
 	if b != nil {
-		right = *b
+		right = *b.Clone()
+		right.RedactSecrets()
 	}
 	// The scriptPath is "incidental", and should not contribute to "signficant" differences.
 	// As long as it is non-empty, copy it from one struct to the other.
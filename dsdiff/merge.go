@@ -0,0 +1,279 @@
+package dsdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// Conflict describes a value that was changed differently by both sides of a
+// three-way merge. Merges resolve conflicts by keeping our value, so a merged
+// result is always usable, but conflicts should be reviewed. Missing values
+// are nil
+type Conflict struct {
+	// Path is a JSON pointer to the conflicting value. Row conflicts are
+	// relative to the body root, and address rows by their index in the base
+	// body. Rows added by both sides address rows by key
+	Path   string      `json:"path"`
+	Base   interface{} `json:"base,omitempty"`
+	Ours   interface{} `json:"ours,omitempty"`
+	Theirs interface{} `json:"theirs,omitempty"`
+}
+
+// mergeSkipPaths are document fields that differ between any two versions, and
+// are left out of merges. Merged datasets need a new commit, body and derived
+// structure values once the body merge is written
+var mergeSkipPaths = map[string]bool{
+	"/bodyPath":           true,
+	"/commit":             true,
+	"/path":               true,
+	"/previousPath":       true,
+	"/numVersions":        true,
+	"/structure/checksum": true,
+	"/structure/depth":    true,
+	"/structure/entries":  true,
+	"/structure/errCount": true,
+	"/structure/length":   true,
+	"/structure/path":     true,
+}
+
+// Merge combines the changes ours and theirs made to a common ancestor base,
+// comparing dataset documents field by field. Body data isn't merged, use
+// MergeRows to combine bodies. The merged dataset has no commit, and its
+// previous path is the path of ours
+func Merge(base, ours, theirs *dataset.Dataset) (*dataset.Dataset, []*Conflict, error) {
+	bv, err := documentValue(base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding base: %w", err)
+	}
+	ov, err := documentValue(ours)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding ours: %w", err)
+	}
+	tv, err := documentValue(theirs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding theirs: %w", err)
+	}
+
+	// skipped fields are dropped before merging, so no merge shortcut can
+	// carry them over
+	for _, v := range []interface{}{bv, ov, tv} {
+		dropSkipPaths(v, "")
+	}
+	var conflicts []*Conflict
+	merged, _ := mergeValues(&conflicts, "", mergeValue{bv, true}, mergeValue{ov, true}, mergeValue{tv, true})
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(data, ds); err != nil {
+		return nil, nil, fmt.Errorf("decoding merged dataset: %w", err)
+	}
+	if ours != nil {
+		ds.PreviousPath = ours.Path
+	}
+	return ds, conflicts, nil
+}
+
+// mergeValue is a json.Unmarshal-style value that may be missing
+type mergeValue struct {
+	v  interface{}
+	ok bool
+}
+
+func (a mergeValue) equal(b mergeValue) bool {
+	return a.ok == b.ok && reflect.DeepEqual(a.v, b.v)
+}
+
+// dropSkipPaths removes the mergeSkipPaths fields of a document value at
+// path in place
+func dropSkipPaths(v interface{}, path string) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, val := range m {
+		p := path + "/" + EscapePointerToken(key)
+		if mergeSkipPaths[p] {
+			delete(m, key)
+			continue
+		}
+		dropSkipPaths(val, p)
+	}
+}
+
+func mergeValues(conflicts *[]*Conflict, path string, base, ours, theirs mergeValue) (interface{}, bool) {
+	if ours.equal(theirs) || base.equal(theirs) {
+		return ours.v, ours.ok
+	}
+	if base.equal(ours) {
+		return theirs.v, theirs.ok
+	}
+
+	bm, bIsMap := base.v.(map[string]interface{})
+	om, oIsMap := ours.v.(map[string]interface{})
+	tm, tIsMap := theirs.v.(map[string]interface{})
+	if oIsMap && tIsMap && (bIsMap || !base.ok) {
+		merged := map[string]interface{}{}
+		for _, key := range unionKeys(bm, om, tm) {
+			b, bok := bm[key]
+			o, ook := om[key]
+			t, tok := tm[key]
+			if v, ok := mergeValues(conflicts, path+"/"+EscapePointerToken(key), mergeValue{b, bok}, mergeValue{o, ook}, mergeValue{t, tok}); ok {
+				merged[key] = v
+			}
+		}
+		return merged, true
+	}
+
+	*conflicts = append(*conflicts, &Conflict{Path: path, Base: base.v, Ours: ours.v, Theirs: theirs.v})
+	return ours.v, ours.ok
+}
+
+func unionKeys(maps ...map[string]interface{}) []string {
+	set := map[string]bool{}
+	for _, m := range maps {
+		for key := range m {
+			set[key] = true
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MergeRows combines the rows ours and theirs changed in a common ancestor
+// body, matching rows with key as DiffRows does. The base body is buffered in
+// memory. The returned reader streams the merged body. Rows both sides
+// changed differently are conflicts, resolved by keeping our row. Rows added
+// by both sides are only kept once
+func MergeRows(base, ours, theirs dsio.EntryReader, key string) (dsio.EntryReader, []*Conflict, error) {
+	st := base.Structure()
+	var rows []dsio.Entry
+	err := dsio.EachEntry(base, func(_ int, ent dsio.Entry, _ error) error {
+		rows = append(rows, ent)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading base: %w", err)
+	}
+
+	po, err := DiffRows(&entrySliceReader{st: st, entries: rows}, ours, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ours: %w", err)
+	}
+	pt, err := DiffRows(&entrySliceReader{st: st, entries: rows}, theirs, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("theirs: %w", err)
+	}
+
+	merged, conflicts := mergeRowPatches(po, pt, rowKeyFunc(st, key))
+	r, err := dsio.NewPatchReader(&entrySliceReader{st: st, entries: rows}, merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, conflicts, nil
+}
+
+// mergeRowPatches combines two patches of the same base body. keyOf
+// identifies added rows, so adds of the same row by both sides collide
+func mergeRowPatches(ours, theirs dsio.RowPatch, keyOf func(dsio.Entry) (string, error)) (dsio.RowPatch, []*Conflict) {
+	var (
+		merged    dsio.RowPatch
+		conflicts []*Conflict
+		i, j      int
+	)
+	for i < len(ours) || j < len(theirs) {
+		index := -1
+		if i < len(ours) {
+			index = ours[i].Index
+		}
+		if j < len(theirs) && (index < 0 || theirs[j].Index < index) {
+			index = theirs[j].Index
+		}
+
+		oAdds, oMod := rowChangesAt(ours, &i, index)
+		tAdds, tMod := rowChangesAt(theirs, &j, index)
+
+		merged = append(merged, oAdds...)
+		for _, t := range tAdds {
+			dup := false
+			for _, o := range oAdds {
+				if reflect.DeepEqual(o.Value, t.Value) && o.Key == t.Key {
+					dup = true
+					break
+				}
+				oKey, oErr := keyOf(dsio.Entry{Key: o.Key, Value: o.Value})
+				tKey, tErr := keyOf(dsio.Entry{Key: t.Key, Value: t.Value})
+				if oErr == nil && tErr == nil && oKey != "" && oKey == tKey {
+					conflicts = append(conflicts, &Conflict{Path: "/" + EscapePointerToken(oKey), Ours: o.Value, Theirs: t.Value})
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				merged = append(merged, t)
+			}
+		}
+
+		switch {
+		case oMod == nil && tMod == nil:
+		case tMod == nil:
+			merged = append(merged, *oMod)
+		case oMod == nil:
+			merged = append(merged, *tMod)
+		default:
+			if oMod.Op != tMod.Op || !reflect.DeepEqual(oMod.Value, tMod.Value) {
+				conflicts = append(conflicts, &Conflict{Path: "/" + strconv.Itoa(index), Base: oMod.Previous, Ours: oMod.Value, Theirs: tMod.Value})
+			}
+			merged = append(merged, *oMod)
+		}
+	}
+	return merged, conflicts
+}
+
+// rowChangesAt consumes the changes in p at index starting from *i, splitting
+// them into adds & the remove or replace of the row at index
+func rowChangesAt(p dsio.RowPatch, i *int, index int) (adds dsio.RowPatch, mod *dsio.RowChange) {
+	for *i < len(p) && p[*i].Index == index {
+		c := p[*i]
+		*i++
+		if c.Op == dsio.RowOpAdd {
+			adds = append(adds, c)
+		} else {
+			mod = &c
+		}
+	}
+	return adds, mod
+}
+
+// entrySliceReader reads entries from a slice
+type entrySliceReader struct {
+	st      *dataset.Structure
+	entries []dsio.Entry
+	i       int
+}
+
+func (r *entrySliceReader) Structure() *dataset.Structure { return r.st }
+
+func (r *entrySliceReader) ReadEntry() (dsio.Entry, error) {
+	if r.i >= len(r.entries) {
+		return dsio.Entry{}, io.EOF
+	}
+	ent := r.entries[r.i]
+	r.i++
+	return ent, nil
+}
+
+func (r *entrySliceReader) Close() error { return nil }
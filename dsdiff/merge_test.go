@@ -0,0 +1,176 @@
+package dsdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestMerge(t *testing.T) {
+	base := &dataset.Dataset{
+		Path:   "/mem/QmBase",
+		Commit: &dataset.Commit{Title: "base"},
+		Meta:   &dataset.Meta{Title: "feed", Description: "daily feed", Keywords: []string{"a"}},
+		Structure: &dataset.Structure{
+			Format:  "json",
+			Schema:  dataset.BaseSchemaArray,
+			Entries: 2,
+		},
+	}
+	ours := base.Clone()
+	ours.Path = "/mem/QmOurs"
+	ours.Commit.Title = "ours"
+	ours.Meta.Title = "Feed"
+	ours.Meta.Keywords = []string{"a", "b"}
+	ours.Structure.Entries = 3
+
+	theirs := base.Clone()
+	theirs.Path = "/mem/QmTheirs"
+	theirs.Commit.Title = "theirs"
+	theirs.Meta.Description = "a daily feed"
+	theirs.Meta.Keywords = []string{"c"}
+	theirs.Structure.Entries = 4
+
+	got, conflicts, err := Merge(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Meta.Title != "Feed" {
+		t.Errorf("expected our title. got: %q", got.Meta.Title)
+	}
+	if got.Meta.Description != "a daily feed" {
+		t.Errorf("expected their description. got: %q", got.Meta.Description)
+	}
+	if expect := []string{"a", "b"}; !reflect.DeepEqual(expect, got.Meta.Keywords) {
+		t.Errorf("expected conflicting keywords to resolve to ours. got: %v", got.Meta.Keywords)
+	}
+	if got.Commit != nil {
+		t.Errorf("expected merged dataset to have no commit")
+	}
+	if got.PreviousPath != "/mem/QmOurs" {
+		t.Errorf("previousPath mismatch. got: %q", got.PreviousPath)
+	}
+	if got.Structure.Entries != 0 {
+		t.Errorf("expected derived structure values to be dropped. got entries: %d", got.Structure.Entries)
+	}
+
+	data, err := json.Marshal(conflicts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[{"path":"/meta/keywords","base":["a"],"ours":["a","b"],"theirs":["c"]}]`
+	if expect != string(data) {
+		t.Errorf("conflicts mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+}
+
+func TestMergeUnchangedTheirs(t *testing.T) {
+	base := &dataset.Dataset{
+		Path:      "/mem/QmBase",
+		Commit:    &dataset.Commit{Title: "base"},
+		Meta:      &dataset.Meta{Title: "feed"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray, Entries: 2, Checksum: "QmBaseSum"},
+	}
+	ours := base.Clone()
+	ours.Path = "/mem/QmOurs"
+	ours.Commit.Title = "ours"
+	ours.Meta.Title = "Feed"
+	ours.Structure.Entries = 3
+	ours.Structure.Checksum = "QmOursSum"
+
+	for _, theirs := range []*dataset.Dataset{base, ours} {
+		got, conflicts, err := Merge(base, ours, theirs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("expected no conflicts, got: %v", conflicts)
+		}
+		if got.Meta.Title != "Feed" {
+			t.Errorf("expected our title. got: %q", got.Meta.Title)
+		}
+		if got.Commit != nil || got.Path != "" || got.Structure.Entries != 0 || got.Structure.Checksum != "" {
+			t.Errorf("expected skipped fields to be dropped, got: %#v %#v", got, got.Structure)
+		}
+	}
+}
+
+func TestMergeRows(t *testing.T) {
+	tableSt := &dataset.Structure{Format: "json", Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "id", "type": "integer"},
+				map[string]interface{}{"title": "name", "type": "string"},
+			},
+		},
+	}}
+	arraySt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+
+	cases := []struct {
+		description         string
+		st                  *dataset.Structure
+		key                 string
+		base, ours, theirs  string
+		expect, expectConfl string
+	}{
+		{"independent changes", tableSt, "id",
+			`[[1,"a"],[2,"b"],[3,"c"]]`,
+			`[[1,"A"],[2,"b"],[3,"c"],[4,"d"]]`,
+			`[[1,"a"],[3,"C"],[5,"e"]]`,
+			`[[1,"A"],[3,"C"],[4,"d"],[5,"e"]]`,
+			`null`,
+		},
+		{"conflicting changes", tableSt, "id",
+			`[[1,"a"],[2,"b"]]`,
+			`[[1,"x"],[2,"b"],[3,"c"]]`,
+			`[[1,"y"],[3,"z"]]`,
+			`[[1,"x"],[3,"c"]]`,
+			`[{"path":"/0","base":[1,"a"],"ours":[1,"x"],"theirs":[1,"y"]},{"path":"/3","ours":[3,"c"],"theirs":[3,"z"]}]`,
+		},
+		{"same change on both sides", arraySt, "",
+			`[1,2]`,
+			`[1,3,4]`,
+			`[1,3,4]`,
+			`[1,3,4]`,
+			`null`,
+		},
+		{"remove and replace", arraySt, "",
+			`[1,2,3]`,
+			`[1,2]`,
+			`[1,2,5]`,
+			`[1,2]`,
+			`[{"path":"/2","base":3,"theirs":5}]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r, conflicts, err := MergeRows(jsonReader(t, c.st, c.base), jsonReader(t, c.st, c.ours), jsonReader(t, c.st, c.theirs), c.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := readBody(r, "array")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.expect != string(data) {
+				t.Errorf("body mismatch.\nwant: %s\ngot:  %s", c.expect, string(data))
+			}
+			if data, err = json.Marshal(conflicts); err != nil {
+				t.Fatal(err)
+			}
+			if c.expectConfl != string(data) {
+				t.Errorf("conflicts mismatch.\nwant: %s\ngot:  %s", c.expectConfl, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package dsdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+const (
+	// OpAdd is the JSON-patch operation for adding a value
+	OpAdd = "add"
+	// OpRemove is the JSON-patch operation for removing a value
+	OpRemove = "remove"
+	// OpReplace is the JSON-patch operation for changing a value
+	OpReplace = "replace"
+)
+
+// Operation is a single field-level change between two documents, modeled on
+// RFC 6902 JSON Patch operations. Path is a JSON Pointer (RFC 6901)
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+	// Previous holds the value being removed or replaced. It's not part of the
+	// JSON Patch spec, and isn't serialized
+	Previous interface{} `json:"-"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. Add & replace
+// operations always include a value, even if it's null or empty, remove
+// operations never do
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type _operation Operation
+	return json.Marshal(_operation(o))
+}
+
+// Patch is an ordered list of operations that transforms one document into
+// another when applied in order
+type Patch []*Operation
+
+// Modified returns true if a patch has any operations
+func (p Patch) Modified() bool {
+	return len(p) > 0
+}
+
+// StructuredDiff produces a field-level diff between two dataset documents as
+// a patch. Body data is not considered, use DiffBodies to compare bodies
+// Components are compared in their encoded form, so differences in paths and
+// derived values will show up as changes
+func StructuredDiff(a, b *dataset.Dataset) (Patch, error) {
+	av, err := documentValue(a)
+	if err != nil {
+		return nil, fmt.Errorf("encoding dataset a: %s", err)
+	}
+	bv, err := documentValue(b)
+	if err != nil {
+		return nil, fmt.Errorf("encoding dataset b: %s", err)
+	}
+	return DiffValues(av, bv), nil
+}
+
+// documentValue converts a dataset to it's json.Unmarshal-style value, without
+// body data. Transform secrets are redacted before the dataset is encoded, so
+// secret values never reach a patch or merge conflict
+func documentValue(ds *dataset.Dataset) (interface{}, error) {
+	if ds == nil {
+		return map[string]interface{}{}, nil
+	}
+	ds = ds.Clone()
+	ds.Body = nil
+	ds.BodyBytes = nil
+	if ds.Transform != nil {
+		ds.Transform.RedactSecrets()
+	}
+
+	// marshal as an object, even if the dataset is a path reference
+	type _dataset dataset.Dataset
+	data, err := json.Marshal((*_dataset)(ds))
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// DiffValues compares two json.Unmarshal-style values producing a patch that
+// transforms a into b
+func DiffValues(a, b interface{}) Patch {
+	p := Patch{}
+	diffValues(&p, "", a, b)
+	return p
+}
+
+func diffValues(p *Patch, path string, a, b interface{}) {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		if bt, ok := b.(map[string]interface{}); ok {
+			diffObjects(p, path, at, bt)
+			return
+		}
+	case []interface{}:
+		if bt, ok := b.([]interface{}); ok {
+			diffArrays(p, path, at, bt)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*p = append(*p, &Operation{Op: OpReplace, Path: path, Value: b, Previous: a})
+	}
+}
+
+func diffObjects(p *Patch, path string, a, b map[string]interface{}) {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "/" + EscapePointerToken(key)
+		av, inA := a[key]
+		bv, inB := b[key]
+		switch {
+		case inA && !inB:
+			*p = append(*p, &Operation{Op: OpRemove, Path: keyPath, Previous: av})
+		case !inA && inB:
+			*p = append(*p, &Operation{Op: OpAdd, Path: keyPath, Value: bv})
+		default:
+			diffValues(p, keyPath, av, bv)
+		}
+	}
+}
+
+func diffArrays(p *Patch, path string, a, b []interface{}) {
+	i := 0
+	for ; i < len(a) && i < len(b); i++ {
+		diffValues(p, path+"/"+strconv.Itoa(i), a[i], b[i])
+	}
+	for j := i; j < len(b); j++ {
+		*p = append(*p, &Operation{Op: OpAdd, Path: path + "/" + strconv.Itoa(j), Value: b[j]})
+	}
+	// remove from the end so indexes remain valid when applied in order
+	for j := len(a) - 1; j >= i; j-- {
+		*p = append(*p, &Operation{Op: OpRemove, Path: path + "/" + strconv.Itoa(j), Previous: a[j]})
+	}
+}
+
+// DiffBodies compares the entries of two body readers, producing a patch with
+// paths relative to the body root. Array bodies are compared positionally
+// while streaming, only holding one entry from each reader in memory. Object
+// bodies are keyed, and are buffered in full before comparison
+func DiffBodies(a, b dsio.EntryReader) (Patch, error) {
+	tltA, err := dsio.GetTopLevelType(a.Structure())
+	if err != nil {
+		return nil, fmt.Errorf("body a: %s", err)
+	}
+	tltB, err := dsio.GetTopLevelType(b.Structure())
+	if err != nil {
+		return nil, fmt.Errorf("body b: %s", err)
+	}
+
+	if tltA != tltB || tltA == "object" {
+		av, err := readBody(a, tltA)
+		if err != nil {
+			return nil, fmt.Errorf("reading body a: %s", err)
+		}
+		bv, err := readBody(b, tltB)
+		if err != nil {
+			return nil, fmt.Errorf("reading body b: %s", err)
+		}
+		return DiffValues(av, bv), nil
+	}
+
+	p := Patch{}
+	var removes Patch
+	aDone, bDone := false, false
+	for i := 0; !aDone || !bDone; i++ {
+		var ae, be dsio.Entry
+		if !aDone {
+			if ae, err = a.ReadEntry(); err == io.EOF {
+				aDone = true
+			} else if err != nil {
+				return nil, fmt.Errorf("reading body a entry %d: %s", i, err)
+			}
+		}
+		if !bDone {
+			if be, err = b.ReadEntry(); err == io.EOF {
+				bDone = true
+			} else if err != nil {
+				return nil, fmt.Errorf("reading body b entry %d: %s", i, err)
+			}
+		}
+
+		path := "/" + strconv.Itoa(i)
+		switch {
+		case !aDone && !bDone:
+			diffValues(&p, path, normalizeValue(ae.Value), normalizeValue(be.Value))
+		case !aDone:
+			removes = append(Patch{&Operation{Op: OpRemove, Path: path, Previous: normalizeValue(ae.Value)}}, removes...)
+		case !bDone:
+			p = append(p, &Operation{Op: OpAdd, Path: path, Value: normalizeValue(be.Value)})
+		}
+	}
+
+	return append(p, removes...), nil
+}
+
+func readBody(r dsio.EntryReader, tlt string) (interface{}, error) {
+	if tlt == "object" {
+		obj := map[string]interface{}{}
+		err := dsio.EachEntry(r, func(_ int, ent dsio.Entry, _ error) error {
+			obj[ent.Key] = normalizeValue(ent.Value)
+			return nil
+		})
+		return obj, err
+	}
+
+	arr := []interface{}{}
+	err := dsio.EachEntry(r, func(_ int, ent dsio.Entry, _ error) error {
+		arr = append(arr, normalizeValue(ent.Value))
+		return nil
+	})
+	return arr, err
+}
+
+// normalizeValue round-trips entry values through JSON so values decoded from
+// different formats (eg. CBOR integers & JSON floats) compare equal
+func normalizeValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var n interface{}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return v
+	}
+	return n
+}
+
+// EscapePointerToken escapes a single JSON pointer reference token according
+// to RFC 6901
+func EscapePointerToken(tok string) string {
+	return strings.Replace(strings.Replace(tok, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,173 @@
+package dsdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestStructuredDiff(t *testing.T) {
+	a := &dataset.Dataset{
+		Meta: &dataset.Meta{Title: "old title", Keywords: []string{"a", "b", "c"}},
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: dataset.BaseSchemaArray,
+		},
+	}
+	b := &dataset.Dataset{
+		Meta: &dataset.Meta{Title: "new title", Description: "added", Keywords: []string{"a"}},
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: dataset.BaseSchemaArray,
+		},
+		Body: []interface{}{"ignored"},
+	}
+
+	got, err := StructuredDiff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `[{"op":"add","path":"/meta/description","value":"added"},{"op":"remove","path":"/meta/keywords/2"},{"op":"remove","path":"/meta/keywords/1"},{"op":"replace","path":"/meta/title","value":"new title"}]`
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect != string(data) {
+		t.Errorf("patch mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+	if got[3].Previous != "old title" {
+		t.Errorf("expected replace operation to record previous value. got: %v", got[3].Previous)
+	}
+
+	if p, err := StructuredDiff(a, a); err != nil {
+		t.Fatal(err)
+	} else if p.Modified() {
+		t.Errorf("expected diffing a dataset with itself to produce no changes")
+	}
+}
+
+func TestStructuredDiffSecrets(t *testing.T) {
+	a := &dataset.Dataset{Transform: &dataset.Transform{Syntax: "sql", Secrets: map[string]string{"token": "hunter2"}}}
+	b := &dataset.Dataset{Transform: &dataset.Transform{Syntax: "sql", Secrets: map[string]string{"token": "s3cret", "key": "pa55"}}}
+
+	got, err := StructuredDiff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[{"op":"add","path":"/transform/secrets/key","value":"[redacted]"}]`
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect != string(data) {
+		t.Errorf("patch mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+	if a.Transform.Secrets["token"] != "hunter2" {
+		t.Errorf("expected diffing not to redact the given transform")
+	}
+
+	sub, err := DiffTransform(a.Transform, b.Transform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range [][]byte{sub.a, sub.b} {
+		if bytes.Contains(doc, []byte("hunter2")) || bytes.Contains(doc, []byte("s3cret")) {
+			t.Errorf("expected transform diffs to redact secrets, got: %s", doc)
+		}
+	}
+
+	_, conflicts, err := Merge(&dataset.Dataset{}, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range conflicts {
+		if data, _ := json.Marshal([]interface{}{c.Base, c.Ours, c.Theirs}); bytes.Contains(data, []byte("hunter2")) {
+			t.Errorf("expected merge conflicts to redact secrets, got: %s", data)
+		}
+	}
+}
+
+func TestOperationMarshalJSON(t *testing.T) {
+	p := Patch{
+		{Op: OpAdd, Path: "/a", Value: nil},
+		{Op: OpReplace, Path: "/b", Value: ""},
+		{Op: OpReplace, Path: "/c", Value: 0.0, Previous: 1.0},
+		{Op: OpRemove, Path: "/d", Previous: "d"},
+	}
+	expect := `[{"op":"add","path":"/a","value":null},{"op":"replace","path":"/b","value":""},{"op":"replace","path":"/c","value":0},{"op":"remove","path":"/d"}]`
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect != string(data) {
+		t.Errorf("patch mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	cases := []struct {
+		a, b   interface{}
+		expect Patch
+	}{
+		{"a", "a", Patch{}},
+		{"a", "b", Patch{{Op: OpReplace, Path: "", Value: "b", Previous: "a"}}},
+		{map[string]interface{}{"a/b": 1.0}, map[string]interface{}{"a/b": 2.0}, Patch{{Op: OpReplace, Path: "/a~1b", Value: 2.0, Previous: 1.0}}},
+		{map[string]interface{}{"~": 1.0}, map[string]interface{}{}, Patch{{Op: OpRemove, Path: "/~0", Previous: 1.0}}},
+		{[]interface{}{1.0}, []interface{}{1.0, 2.0}, Patch{{Op: OpAdd, Path: "/1", Value: 2.0}}},
+		{[]interface{}{1.0}, map[string]interface{}{}, Patch{{Op: OpReplace, Path: "", Value: map[string]interface{}{}, Previous: []interface{}{1.0}}}},
+	}
+
+	for i, c := range cases {
+		got := DiffValues(c.a, c.b)
+		if diff := cmp.Diff(c.expect, got); diff != "" {
+			t.Errorf("case %d mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestDiffBodies(t *testing.T) {
+	arraySt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	objectSt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}
+
+	cases := []struct {
+		description string
+		aSt, bSt    *dataset.Structure
+		a, b        string
+		expect      string
+	}{
+		{"equal arrays", arraySt, arraySt, `[1,2,3]`, `[1,2,3]`, `[]`},
+		{"changed array entry", arraySt, arraySt, `[1,{"a":2},3]`, `[1,{"a":3},3]`, `[{"op":"replace","path":"/1/a","value":3}]`},
+		{"appended entries", arraySt, arraySt, `[1]`, `[1,2,3]`, `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`},
+		{"removed entries", arraySt, arraySt, `[1,2,3]`, `[1]`, `[{"op":"remove","path":"/2"},{"op":"remove","path":"/1"}]`},
+		{"objects", objectSt, objectSt, `{"a":1,"b":2}`, `{"b":3,"c":4}`, `[{"op":"remove","path":"/a"},{"op":"replace","path":"/b","value":3},{"op":"add","path":"/c","value":4}]`},
+		{"mixed top level types", arraySt, objectSt, `[1]`, `{"a":1}`, `[{"op":"replace","path":"","value":{"a":1}}]`},
+	}
+
+	for _, c := range cases {
+		ra, err := dsio.NewJSONReader(c.aSt, bytes.NewBufferString(c.a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rb, err := dsio.NewJSONReader(c.bSt, bytes.NewBufferString(c.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DiffBodies(ra, rb)
+		if err != nil {
+			t.Errorf("case '%s' unexpected error: %s", c.description, err)
+			continue
+		}
+		data, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.expect != string(data) {
+			t.Errorf("case '%s' patch mismatch.\nwant: %s\ngot:  %s", c.description, c.expect, string(data))
+		}
+	}
+}
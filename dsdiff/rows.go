@@ -0,0 +1,190 @@
+package dsdiff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// DiffRows compares the rows of two bodies, producing a row patch that turns
+// body a into body b when applied with dsio.NewPatchReader. Delta on the
+// returned patch summarizes added, removed & changed rows.
+//
+// When key is empty, array bodies are compared positionally while streaming,
+// holding one entry from each reader in memory, and object bodies are keyed
+// by entry key. When key is set, rows are matched by the value of the key
+// column, named by schema title for array rows or by field for object rows.
+// Keyed comparison buffers body a in full. Rows that only exist in b are
+// appended to the end of the body, so body order is only preserved when new
+// rows come after existing ones
+func DiffRows(a, b dsio.EntryReader, key string) (dsio.RowPatch, error) {
+	tltA, err := dsio.GetTopLevelType(a.Structure())
+	if err != nil {
+		return nil, fmt.Errorf("body a: %w", err)
+	}
+	tltB, err := dsio.GetTopLevelType(b.Structure())
+	if err != nil {
+		return nil, fmt.Errorf("body b: %w", err)
+	}
+	if tltA != tltB {
+		return nil, fmt.Errorf("cannot compare rows of %s body to %s body", tltA, tltB)
+	}
+
+	if key == "" && tltA == "array" {
+		return diffRowsPositional(a, b)
+	}
+
+	return diffRowsKeyed(a, b, rowKeyFunc(a.Structure(), key), rowKeyFunc(b.Structure(), key))
+}
+
+func diffRowsPositional(a, b dsio.EntryReader) (dsio.RowPatch, error) {
+	p := dsio.RowPatch{}
+	aDone, bDone := false, false
+	n := 0
+	for i := 0; !aDone || !bDone; i++ {
+		var ae, be dsio.Entry
+		var err error
+		if !aDone {
+			if ae, err = a.ReadEntry(); err == io.EOF {
+				aDone = true
+				n = i
+			} else if err != nil {
+				return nil, fmt.Errorf("reading body a entry %d: %w", i, err)
+			}
+		}
+		if !bDone {
+			if be, err = b.ReadEntry(); err == io.EOF {
+				bDone = true
+			} else if err != nil {
+				return nil, fmt.Errorf("reading body b entry %d: %w", i, err)
+			}
+		}
+
+		switch {
+		case !aDone && !bDone:
+			av, bv := normalizeValue(ae.Value), normalizeValue(be.Value)
+			if !reflect.DeepEqual(av, bv) {
+				p = append(p, dsio.RowChange{Op: dsio.RowOpReplace, Index: i, Value: bv, Previous: av})
+			}
+		case !aDone:
+			p = append(p, dsio.RowChange{Op: dsio.RowOpRemove, Index: i, Previous: normalizeValue(ae.Value)})
+		case !bDone:
+			p = append(p, dsio.RowChange{Op: dsio.RowOpAdd, Index: n, Value: normalizeValue(be.Value)})
+		}
+	}
+	return p, nil
+}
+
+// keyedRow is a row of body a, indexed by key
+type keyedRow struct {
+	index int
+	key   string
+	value interface{}
+	seen  bool
+}
+
+func diffRowsKeyed(a, b dsio.EntryReader, keyA, keyB func(dsio.Entry) (string, error)) (dsio.RowPatch, error) {
+	rows := map[string]*keyedRow{}
+	n := 0
+	err := dsio.EachEntry(a, func(i int, ent dsio.Entry, _ error) error {
+		k, err := keyA(ent)
+		if err != nil {
+			return fmt.Errorf("body a entry %d: %w", i, err)
+		}
+		if rows[k] != nil {
+			return fmt.Errorf("body a entry %d: duplicate key %q", i, k)
+		}
+		rows[k] = &keyedRow{index: i, key: ent.Key, value: normalizeValue(ent.Value)}
+		n = i + 1
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var changes, adds dsio.RowPatch
+	err = dsio.EachEntry(b, func(i int, ent dsio.Entry, _ error) error {
+		k, err := keyB(ent)
+		if err != nil {
+			return fmt.Errorf("body b entry %d: %w", i, err)
+		}
+		v := normalizeValue(ent.Value)
+		row := rows[k]
+		if row == nil {
+			adds = append(adds, dsio.RowChange{Op: dsio.RowOpAdd, Index: n, Key: ent.Key, Value: v})
+			return nil
+		}
+		if row.seen {
+			return fmt.Errorf("body b entry %d: duplicate key %q", i, k)
+		}
+		row.seen = true
+		if !reflect.DeepEqual(row.value, v) {
+			changes = append(changes, dsio.RowChange{Op: dsio.RowOpReplace, Index: row.index, Key: ent.Key, Value: v, Previous: row.value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if !row.seen {
+			changes = append(changes, dsio.RowChange{Op: dsio.RowOpRemove, Index: row.index, Key: row.key, Previous: row.value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Index < changes[j].Index })
+	return append(changes, adds...), nil
+}
+
+// rowKeyFunc creates a function that extracts the key of a row. an empty key
+// uses the entry key of object bodies
+func rowKeyFunc(st *dataset.Structure, key string) func(dsio.Entry) (string, error) {
+	if key == "" {
+		return func(ent dsio.Entry) (string, error) {
+			return ent.Key, nil
+		}
+	}
+
+	col := -1
+	if st != nil {
+		if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+			for i, c := range cols {
+				if c.Title == key {
+					col = i
+				}
+			}
+		}
+	}
+
+	return func(ent dsio.Entry) (string, error) {
+		switch row := normalizeValue(ent.Value).(type) {
+		case map[string]interface{}:
+			v, ok := row[key]
+			if !ok {
+				return "", fmt.Errorf("missing key field %q", key)
+			}
+			return keyString(v), nil
+		case []interface{}:
+			if col < 0 {
+				return "", fmt.Errorf("key column %q not found in schema", key)
+			}
+			if col >= len(row) {
+				return "", fmt.Errorf("missing key column %q", key)
+			}
+			return keyString(row[col]), nil
+		default:
+			return "", fmt.Errorf("rows must be arrays or objects to use a key")
+		}
+	}
+}
+
+// keyString formats a key value, normalizing numbers decoded from different
+// formats so they match
+func keyString(v interface{}) string {
+	return fmt.Sprintf("%v", normalizeValue(v))
+}
@@ -0,0 +1,140 @@
+package dsdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestDiffRows(t *testing.T) {
+	arraySt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	objectSt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}
+	tableSt := &dataset.Structure{Format: "json", Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "id", "type": "integer"},
+				map[string]interface{}{"title": "name", "type": "string"},
+			},
+		},
+	}}
+
+	cases := []struct {
+		description string
+		aSt, bSt    *dataset.Structure
+		key         string
+		a, b        string
+		expect      string
+		delta       dataset.BodyDelta
+	}{
+		{"equal arrays", arraySt, arraySt, "", `[1,2,3]`, `[1,2,3]`, `[]`, dataset.BodyDelta{}},
+		{"positional changes", arraySt, arraySt, "", `[1,{"a":2},3]`, `[1,{"a":3},3,4]`, `[{"op":"replace","index":1,"value":{"a":3}},{"op":"add","index":3,"value":4}]`, dataset.BodyDelta{Added: 1, Changed: 1}},
+		{"positional removes", arraySt, arraySt, "", `[1,2,3]`, `[1]`, `[{"op":"remove","index":1},{"op":"remove","index":2}]`, dataset.BodyDelta{Removed: 2}},
+		{"object entries", objectSt, objectSt, "", `{"a":1,"b":2}`, `{"b":3,"c":4}`, `[{"op":"remove","index":0,"key":"a"},{"op":"replace","index":1,"key":"b","value":3},{"op":"add","index":2,"key":"c","value":4}]`, dataset.BodyDelta{Added: 1, Removed: 1, Changed: 1}},
+		{"keyed table rows", tableSt, tableSt, "id", `[[1,"a"],[2,"b"],[3,"c"]]`, `[[1,"a"],[3,"z"],[4,"d"]]`, `[{"op":"remove","index":1},{"op":"replace","index":2,"value":[3,"z"]},{"op":"add","index":3,"value":[4,"d"]}]`, dataset.BodyDelta{Added: 1, Removed: 1, Changed: 1}},
+		{"keyed object rows", arraySt, arraySt, "id", `[{"id":"x","v":1},{"id":"y","v":2}]`, `[{"id":"y","v":2},{"id":"x","v":5}]`, `[{"op":"replace","index":0,"value":{"id":"x","v":5}}]`, dataset.BodyDelta{Changed: 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got, err := DiffRows(jsonReader(t, c.aSt, c.a), jsonReader(t, c.bSt, c.b), c.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.expect != string(data) {
+				t.Errorf("patch mismatch.\nwant: %s\ngot:  %s", c.expect, string(data))
+			}
+			if !reflect.DeepEqual(&c.delta, got.Delta()) {
+				t.Errorf("delta mismatch. expected: %v, got: %v", c.delta, got.Delta())
+			}
+		})
+	}
+}
+
+func TestDiffRowsApply(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	cases := []struct {
+		key  string
+		a, b string
+	}{
+		{"", `[1,2,3,4]`, `[1,5,3]`},
+		{"", `[]`, `[{"a":1},{"b":2}]`},
+		{"id", `[{"id":1},{"id":2,"v":true},{"id":3}]`, `[{"id":1},{"id":2,"v":false},{"id":4}]`},
+	}
+
+	for i, c := range cases {
+		p, err := DiffRows(jsonReader(t, st, c.a), jsonReader(t, st, c.b), c.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// patches must survive serialization
+		data, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := dsio.RowPatch{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		r, err := dsio.NewPatchReader(jsonReader(t, st, c.a), decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := readBody(r, "array")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expect interface{}
+		if err := json.Unmarshal([]byte(c.b), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("case %d applied patch mismatch. expected: %v, got: %v", i, expect, got)
+		}
+	}
+}
+
+func TestDiffRowsErrors(t *testing.T) {
+	arraySt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	objectSt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}
+
+	cases := []struct {
+		description string
+		aSt, bSt    *dataset.Structure
+		key         string
+		a, b        string
+		err         string
+	}{
+		{"mismatched types", arraySt, objectSt, "", `[]`, `{}`, "cannot compare rows of array body to object body"},
+		{"duplicate key", arraySt, arraySt, "id", `[{"id":1},{"id":1}]`, `[]`, `body a entry 1: duplicate key "1"`},
+		{"missing field", arraySt, arraySt, "id", `[{"id":1}]`, `[{"name":1}]`, `body b entry 0: missing key field "id"`},
+		{"unknown column", arraySt, arraySt, "id", `[[1]]`, `[]`, `body a entry 0: key column "id" not found in schema`},
+		{"scalar rows", arraySt, arraySt, "id", `[1]`, `[]`, `body a entry 0: rows must be arrays or objects to use a key`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := DiffRows(jsonReader(t, c.aSt, c.a), jsonReader(t, c.bSt, c.b), c.key)
+			if err == nil || err.Error() != c.err {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func jsonReader(t *testing.T, st *dataset.Structure, data string) dsio.EntryReader {
+	r, err := dsio.NewJSONReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
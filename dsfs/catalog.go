@@ -0,0 +1,64 @@
+package dsfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// WriteCatalog stores a catalog as a single file, returning the path of the
+// stored file. Catalogs are validated before they're written & stored
+// without transient values. Member datasets aren't written, they must be
+// stored separately. c itself isn't modified
+func WriteCatalog(ctx context.Context, store cafs.Filestore, c *dataset.Catalog) (string, error) {
+	if store == nil {
+		return "", fmt.Errorf("dsfs: store is required")
+	}
+	if c == nil {
+		return "", fmt.Errorf("dsfs: catalog is required")
+	}
+	if err := c.Validate(); err != nil {
+		return "", fmt.Errorf("dsfs: invalid catalog: %w", err)
+	}
+	c = c.Clone()
+	c.DropTransientValues()
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("dsfs: encoding %s: %w", PackageFileCatalog, err)
+	}
+	path, err := putFile(ctx, store, PackageFileCatalog, data)
+	if err != nil {
+		return "", fmt.Errorf("dsfs: writing %s: %w", PackageFileCatalog, err)
+	}
+	return path, nil
+}
+
+// LoadCatalog reads a stored catalog, setting it's path
+func LoadCatalog(ctx context.Context, resolver qfs.PathResolver, path string) (*dataset.Catalog, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("dsfs: %w", dataset.ErrNoResolver)
+	}
+	f, err := getFile(ctx, resolver, path)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: reading %s: %w", path, err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: reading %s: %w", path, err)
+	}
+	c := &dataset.Catalog{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("dsfs: decoding %s: %w", path, err)
+	}
+	if dataset.Kind(c.Qri).Type() != dataset.KindCatalog.Type() {
+		return nil, fmt.Errorf("dsfs: %s is not a catalog", path)
+	}
+	c.Path = path
+	return c, nil
+}
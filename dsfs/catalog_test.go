@@ -0,0 +1,64 @@
+package dsfs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+func TestLoadCatalogNotFound(t *testing.T) {
+	ctx := context.Background()
+	_, err := LoadCatalog(ctx, cafs.NewMapstore(), "/map/QmMissing")
+	if !errors.Is(err, dataset.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	_, err = LoadDataset(ctx, NewMapStore(), "/map/QmMissing")
+	if !errors.Is(err, dataset.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestWriteCatalog(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	c := &dataset.Catalog{
+		Path:  "/map/old",
+		Title: "VVS GTFS feed",
+		Datasets: []*dataset.CatalogMember{
+			{Ref: &dataset.DatasetRef{Handle: "mfdz", Name: "vvs_stops", Path: "/ipfs/QmStops"}, Role: "stops"},
+		},
+	}
+	path, err := WriteCatalog(ctx, store, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Path != "/map/old" || c.Qri != "" {
+		t.Errorf("expected WriteCatalog not to modify the catalog")
+	}
+	got, err := LoadCatalog(ctx, store, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != path || got.Qri != dataset.KindCatalog.String() || got.Title != c.Title {
+		t.Errorf("unexpected loaded catalog: %#v", got)
+	}
+	if !reflect.DeepEqual(c.Datasets, got.Datasets) {
+		t.Errorf("members mismatch. expected: %#v, got: %#v", c.Datasets, got.Datasets)
+	}
+
+	if _, err := WriteCatalog(ctx, store, &dataset.Catalog{}); err == nil || err.Error() != "dsfs: invalid catalog: title is required" {
+		t.Errorf("expected an invalid catalog error, got: %v", err)
+	}
+	dsPath, err := store.Put(ctx, qfs.NewMemfileBytes(PackageFileDataset, []byte(`{"qri":"ds:0"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCatalog(ctx, store, dsPath); err == nil || err.Error() != "dsfs: "+dsPath+" is not a catalog" {
+		t.Errorf("expected a wrong kind error, got: %v", err)
+	}
+}
@@ -0,0 +1,621 @@
+// Package dsfs stores dataset documents on content-addressed filestores. A
+// stored dataset is a tree of files: each component is written to a file of
+// it's own, and a root dataset file references components by path. The path
+// of the root file is the path of the dataset
+package dsfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// File names of stored dataset components
+const (
+	// PackageFileDataset is the root file of a stored dataset
+	PackageFileDataset = "dataset.json"
+	// PackageFileCatalog is the file of a stored catalog
+	PackageFileCatalog = "catalog.json"
+	// PackageFileBody is the body file of a stored dataset, which has the
+	// structure file format as it's extension when the format is known.
+	// Shards of partitioned bodies are named by position, eg. "body.0.csv"
+	PackageFileBody = "body"
+	// PackageFileCommit is the commit file of a stored dataset
+	PackageFileCommit = "commit.json"
+	// PackageFileMeta is the meta file of a stored dataset
+	PackageFileMeta = "meta.json"
+	// PackageFilePreview is the preview file of a stored dataset
+	PackageFilePreview = "preview.json"
+	// PackageFileProvenance is the provenance file of a stored dataset
+	PackageFileProvenance = "provenance.json"
+	// PackageFileReadme is the readme file of a stored dataset
+	PackageFileReadme = "readme.json"
+	// PackageFileRenderedReadme is the rendered readme file of a stored
+	// dataset, which has the readme format as it's extension, eg. "readme.md"
+	PackageFileRenderedReadme = "readme"
+	// PackageFileStats is the stats file of a stored dataset
+	PackageFileStats = "stats.json"
+	// PackageFileStructure is the structure file of a stored dataset
+	PackageFileStructure = "structure.json"
+	// PackageFileTemplate is the file of a stored template
+	PackageFileTemplate = "template.json"
+	// PackageFileTransform is the transform file of a stored dataset
+	PackageFileTransform = "transform.json"
+	// PackageFileViz is the viz file of a stored dataset
+	PackageFileViz = "viz.json"
+	// PackageFileRenderedViz is the rendered viz file of a stored dataset,
+	// which has the viz format as it's extension, eg. "viz.html"
+	PackageFileRenderedViz = "viz"
+)
+
+// WriteDataset stores ds, returning the path of the stored root file.
+// Writes are staged: every file is encoded to a temp directory before
+// anything is written to store, and the root file is only written once every
+// component write has succeeded, so a dataset path is never published for a
+// partially written dataset. If a write fails, files the write added to a
+// PathStore are deleted before the error is returned. Files that were already
+// stored are kept, as they may be shared with other stored versions. Other
+// stores choose paths themselves, so added files can't be told apart from
+// shared ones and nothing is deleted. A crash mid-write can still leave
+// component files behind, but they're unreferenced & never reachable from a
+// dataset path
+//
+// Components are stored without their transient values, see each
+// component's DropTransientValues method. Scripts held as open files or
+// inline bytes, of the transform, it's steps & files, the viz and the readme,
+// are stored as files of their own and referenced by path, as is a rendered
+// viz file. LoadDataset reads scripts back. Components that are only a
+// reference to a stored component are kept as references and not written
+// again. An inline body can't be stored, bodies must be given as a body
+// file, body bytes, or a body path. ds itself isn't modified
+//
+// Stored bodies are checksummed into the structure checksum with the
+// configured hash function, recorded in the checksum multihash prefix. Files
+// stored in a PathStore are addressed by the multihash of their content under
+// the same hash function, see PathStore.
+// Bodies of structures with a partition are stored as a shard file for each
+// partition key, listed as the dataset BodyShards. The checksum of a
+// partitioned body is of the body as it was given, while shards hold rows in
+// key order, so the checksum can't be verified by reading shards back. A
+// readme rendered file, as set by dsviz.RenderReadme, is stored as the readme
+// RenderedPath. Writes configured WithPreview also store a preview of the
+// body, see WithPreview
+//
+// Once the root file is written, registered & configured Hooks are notified
+func WriteDataset(ctx context.Context, store cafs.Filestore, ds *dataset.Dataset, opts ...func(*WriteConfig)) (string, error) {
+	cfg := &WriteConfig{HashFunc: dataset.DefaultHashFunc}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if store == nil {
+		return "", fmt.Errorf("dsfs: store is required")
+	}
+	if ds == nil {
+		return "", fmt.Errorf("dsfs: dataset is required")
+	}
+	stg, err := stageDataset(ctx, ds, cfg)
+	if err != nil {
+		return "", err
+	}
+	defer stg.cleanup()
+	path, err := stg.publish(ctx, store)
+	if err != nil {
+		log.Debugf("writing dataset: %s", err)
+		return "", err
+	}
+	log.Debugf("wrote dataset %s, %d files", path, len(stg.files)+1)
+	notifyWritten(ctx, cfg, stg, path)
+	return path, nil
+}
+
+// WriteConfig configures dataset writes
+type WriteConfig struct {
+	// HashFunc is the multihash name of the function bodies are checksummed
+	// with, see dataset.HashConfig
+	HashFunc string
+	// Hooks are notified once the dataset is written, after any hooks added
+	// with RegisterHooks
+	Hooks []Hooks
+	// StorageFormat is the format bodies are stored in, see WithStorageFormat.
+	// UnknownDataFormat stores bodies as they're given
+	StorageFormat dataset.DataFormat
+	// TempDir is the directory files are staged in before they're written,
+	// defaults to the system temp directory
+	TempDir string
+	// Preview stores a preview component of PreviewHead leading entries &
+	// PreviewSample sampled entries of the body, see WithPreview
+	Preview       bool
+	PreviewHead   int
+	PreviewSample int
+}
+
+// WithHashFunc sets the hash function bodies are checksummed with
+func WithHashFunc(name string) func(*WriteConfig) {
+	return func(c *WriteConfig) {
+		c.HashFunc = name
+	}
+}
+
+// WithTempDir sets the directory files are staged in
+func WithTempDir(dir string) func(*WriteConfig) {
+	return func(c *WriteConfig) {
+		c.TempDir = dir
+	}
+}
+
+// WithPreview stores a preview component of the body at commit time, holding
+// the first head entries & a random sample of sample other entries, see
+// dsio.NewPreview. Datasets that already have a preview keep it. Previews are
+// sampled with a fixed seed, so writing the same body gives the same preview
+func WithPreview(head, sample int) func(*WriteConfig) {
+	return func(c *WriteConfig) {
+		c.Preview = true
+		c.PreviewHead = head
+		c.PreviewSample = sample
+	}
+}
+
+// WithStorageFormat converts bodies to a canonical format like CBOR before
+// they're stored, trading write time for consistent reads. The structure of a
+// converted body describes the stored body, with the format it was given in
+// recorded as the structure Source, see dsio.ConvertToSource. Bodies already
+// in the storage format are stored as they are
+func WithStorageFormat(f dataset.DataFormat) func(*WriteConfig) {
+	return func(c *WriteConfig) {
+		c.StorageFormat = f
+	}
+}
+
+// component is the interface shared by stored dataset components
+type component interface {
+	IsEmpty() bool
+	DropTransientValues()
+}
+
+// components lists the stored components of a dataset in file name order,
+// with funcs to get each one & replace it with a reference
+var components = []struct {
+	name string
+	get  func(ds *dataset.Dataset) component
+	ref  func(ds *dataset.Dataset, path string)
+}{
+	{PackageFileCommit, func(ds *dataset.Dataset) component {
+		if ds.Commit == nil {
+			return nil
+		}
+		return ds.Commit
+	}, func(ds *dataset.Dataset, path string) { ds.Commit = dataset.NewCommitRef(path) }},
+	{PackageFileMeta, func(ds *dataset.Dataset) component {
+		if ds.Meta == nil {
+			return nil
+		}
+		return ds.Meta
+	}, func(ds *dataset.Dataset, path string) { ds.Meta = dataset.NewMetaRef(path) }},
+	{PackageFilePreview, func(ds *dataset.Dataset) component {
+		if ds.Preview == nil {
+			return nil
+		}
+		return ds.Preview
+	}, func(ds *dataset.Dataset, path string) { ds.Preview = dataset.NewPreviewRef(path) }},
+	{PackageFileProvenance, func(ds *dataset.Dataset) component {
+		if ds.Provenance == nil {
+			return nil
+		}
+		return ds.Provenance
+	}, func(ds *dataset.Dataset, path string) { ds.Provenance = dataset.NewProvenanceRef(path) }},
+	{PackageFileReadme, func(ds *dataset.Dataset) component {
+		if ds.Readme == nil {
+			return nil
+		}
+		return ds.Readme
+	}, func(ds *dataset.Dataset, path string) { ds.Readme = dataset.NewReadmeRef(path) }},
+	{PackageFileStats, func(ds *dataset.Dataset) component {
+		if ds.Stats == nil {
+			return nil
+		}
+		return ds.Stats
+	}, func(ds *dataset.Dataset, path string) { ds.Stats = dataset.NewStatsRef(path) }},
+	{PackageFileStructure, func(ds *dataset.Dataset) component {
+		if ds.Structure == nil {
+			return nil
+		}
+		return ds.Structure
+	}, func(ds *dataset.Dataset, path string) { ds.Structure = dataset.NewStructureRef(path) }},
+	{PackageFileTransform, func(ds *dataset.Dataset) component {
+		if ds.Transform == nil {
+			return nil
+		}
+		return ds.Transform
+	}, func(ds *dataset.Dataset, path string) { ds.Transform = dataset.NewTransformRef(path) }},
+	{PackageFileViz, func(ds *dataset.Dataset) component {
+		if ds.Viz == nil {
+			return nil
+		}
+		return ds.Viz
+	}, func(ds *dataset.Dataset, path string) { ds.Viz = dataset.NewVizRef(path) }},
+}
+
+// staging holds every encoded file of a dataset in a temp directory before
+// any of them are written, so encoding errors never leave files in a store
+type staging struct {
+	root     *dataset.Dataset
+	body     []byte
+	dir      string
+	hashFunc string
+	files    []stagedFile
+}
+
+// stagedFile is a file waiting to be written. ref replaces the staged
+// content on the root with a reference to the written path, which is set
+// once the file is written. Files that reference other staged files are
+// encoded again with encode once the files before them are written, the
+// staged data only checks they encode
+type stagedFile struct {
+	name   string
+	tmp    string
+	ref    func(path string)
+	encode func() ([]byte, error)
+	path   string
+}
+
+// stage writes file data to the temp directory
+func (stg *staging) stage(name string, data []byte, ref func(path string)) error {
+	tmp := filepath.Join(stg.dir, fmt.Sprintf("%d-%s", len(stg.files), name))
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("dsfs: staging %s: %w", name, err)
+	}
+	stg.files = append(stg.files, stagedFile{name: name, tmp: tmp, ref: ref})
+	return nil
+}
+
+// cleanup removes the temp directory
+func (stg *staging) cleanup() {
+	os.RemoveAll(stg.dir)
+}
+
+// stageDataset encodes the body & components of ds
+func stageDataset(ctx context.Context, ds *dataset.Dataset, cfg *WriteConfig) (*staging, error) {
+	if _, err := dataset.NewHashConfig(dataset.WithHashFunc(cfg.HashFunc)); err != nil {
+		return nil, fmt.Errorf("dsfs: %w", err)
+	}
+	dir, err := ioutil.TempDir(cfg.TempDir, "dsfs-stage-")
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: creating staging directory: %w", err)
+	}
+	root := ds.Clone()
+	stg := &staging{root: root, dir: dir, hashFunc: cfg.HashFunc}
+	if err := stg.stageComponents(ctx, ds, cfg); err != nil {
+		stg.cleanup()
+		return nil, err
+	}
+	return stg, nil
+}
+
+// stageComponents stages the body, scripts & components of ds. Scripts are
+// staged before the components that reference them
+func (stg *staging) stageComponents(ctx context.Context, ds *dataset.Dataset, cfg *WriteConfig) error {
+	root := stg.root
+	if err := stg.stageBody(ds, cfg); err != nil {
+		return err
+	}
+	if stg.body != nil && root.Structure != nil && !root.Structure.IsEmpty() {
+		sum, err := dataset.HashBytes(stg.body, dataset.WithHashFunc(cfg.HashFunc))
+		if err != nil {
+			return fmt.Errorf("dsfs: checksumming body: %w", err)
+		}
+		root.Structure.Checksum = sum
+	}
+	if cfg.Preview {
+		if err := stg.previewBody(cfg); err != nil {
+			return err
+		}
+	}
+
+	refs, err := stg.stageScripts(ctx, ds)
+	if err != nil {
+		return err
+	}
+	rendered, err := stg.stageRenderedReadme(ds)
+	if err != nil {
+		return err
+	}
+	if rendered {
+		refs[PackageFileReadme] = true
+	}
+
+	for _, c := range components {
+		cmp := c.get(root)
+		// references are kept as they are
+		if cmp == nil || cmp.IsEmpty() {
+			continue
+		}
+		cmp.DropTransientValues()
+		data, err := json.Marshal(cmp)
+		if err != nil {
+			return fmt.Errorf("dsfs: encoding %s: %w", c.name, err)
+		}
+		ref := c.ref
+		if err := stg.stage(c.name, data, func(path string) { ref(root, path) }); err != nil {
+			return err
+		}
+		if refs[c.name] {
+			stg.files[len(stg.files)-1].encode = func() ([]byte, error) { return json.Marshal(cmp) }
+		}
+	}
+
+	root.DropTransientValues()
+	return nil
+}
+
+// previewBody sets the root preview to a preview of the staged body. Bodies
+// that can't be read with the root structure are an error, datasets without
+// a body or with a preview are left as they are
+func (stg *staging) previewBody(cfg *WriteConfig) error {
+	root := stg.root
+	if stg.body == nil || root.Preview != nil || root.Structure == nil || root.Structure.IsEmpty() {
+		return nil
+	}
+	r, err := dsio.NewEntryReader(root.Structure, bytes.NewReader(stg.body))
+	if err != nil {
+		return fmt.Errorf("dsfs: previewing body: %w", err)
+	}
+	defer r.Close()
+	p, err := dsio.NewPreview(r, cfg.PreviewHead, cfg.PreviewSample, 0)
+	if err != nil {
+		return fmt.Errorf("dsfs: previewing body: %w", err)
+	}
+	root.Preview = p
+	return nil
+}
+
+// stageRenderedReadme stages the rendered file of the ds readme, reporting
+// whether there was one. The rendered file is consumed
+func (stg *staging) stageRenderedReadme(ds *dataset.Dataset) (bool, error) {
+	rm := stg.root.Readme
+	if ds.Readme == nil || ds.Readme.RenderedFile() == nil || rm == nil || rm.IsEmpty() {
+		return false, nil
+	}
+	f := ds.Readme.RenderedFile()
+	data, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return false, fmt.Errorf("dsfs: reading rendered readme: %w", err)
+	}
+	name := PackageFileRenderedReadme
+	if rm.Format != "" {
+		name += "." + rm.Format
+	}
+	err = stg.stage(name, data, func(path string) {
+		rm.RenderedPath = path
+	})
+	return err == nil, err
+}
+
+// stageBody stages the body file or bytes of ds, converting it to the
+// configured storage format. Bodies with a structure partition are stored as
+// shards instead of a single body file, see stageShards
+func (stg *staging) stageBody(ds *dataset.Dataset, cfg *WriteConfig) error {
+	var data []byte
+	if f := ds.BodyFile(); f != nil {
+		var err error
+		data, err = ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("dsfs: reading body file: %w", err)
+		}
+	} else if ds.Body != nil {
+		return fmt.Errorf("dsfs: %w", dataset.ErrInlineBody)
+	} else if ds.BodyBytes != nil {
+		data = ds.BodyBytes
+	} else {
+		return nil
+	}
+
+	if cfg.StorageFormat != dataset.UnknownDataFormat {
+		var err error
+		if data, err = stg.convertBody(data, cfg.StorageFormat); err != nil {
+			return err
+		}
+	}
+
+	ext := ""
+	if st := stg.root.Structure; st != nil && st.DataFormat() != dataset.UnknownDataFormat {
+		ext = "." + st.DataFormat().String()
+	}
+	stg.body = data
+	if st := stg.root.Structure; st != nil && st.Partition != nil {
+		return stg.stageShards(data, ext)
+	}
+	return stg.stage(PackageFileBody+ext, data, func(path string) {
+		stg.root.BodyPath = path
+	})
+}
+
+// stageShards splits body data into a shard file for each partition key,
+// recording the shards on the root in key order. Partitioned datasets have
+// shards in place of a body path. An empty body is stored as a single empty
+// shard with the empty key, so it reads back as an empty body. The structure
+// checksum still covers the whole body as it was given
+func (stg *staging) stageShards(data []byte, ext string) error {
+	st := stg.root.Structure
+	r, err := dsio.NewEntryReader(st, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("dsfs: partitioning body: %w", err)
+	}
+	defer r.Close()
+	bufs := map[string]*bytes.Buffer{}
+	w, err := dsio.NewPartitionWriter(st, func(key string) (io.Writer, error) {
+		bufs[key] = &bytes.Buffer{}
+		return bufs[key], nil
+	})
+	if err != nil {
+		return fmt.Errorf("dsfs: partitioning body: %w", err)
+	}
+	if err := dsio.Copy(r, w); err != nil {
+		return fmt.Errorf("dsfs: partitioning body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("dsfs: partitioning body: %w", err)
+	}
+
+	shards := w.Shards()
+	if len(shards) == 0 {
+		buf := &bytes.Buffer{}
+		ew, err := dsio.NewEntryWriter(st, buf)
+		if err != nil {
+			return fmt.Errorf("dsfs: partitioning body: %w", err)
+		}
+		if err := ew.Close(); err != nil {
+			return fmt.Errorf("dsfs: partitioning body: %w", err)
+		}
+		bufs[""] = buf
+		shards = []*dataset.BodyShard{{Length: buf.Len()}}
+	}
+	for i, s := range shards {
+		shard := s
+		name := fmt.Sprintf("%s.%d%s", PackageFileBody, i, ext)
+		if err := stg.stage(name, bufs[s.Key].Bytes(), func(path string) {
+			shard.Path = path
+		}); err != nil {
+			return err
+		}
+	}
+	stg.root.BodyPath = ""
+	stg.root.BodyShards = shards
+	return nil
+}
+
+// convertBody converts body data to the storage format, replacing the root
+// structure with the structure of the converted body. Bodies without a
+// structure format can't be read, and are kept as they are
+func (stg *staging) convertBody(data []byte, target dataset.DataFormat) ([]byte, error) {
+	st := stg.root.Structure
+	if st == nil || st.DataFormat() == dataset.UnknownDataFormat || st.DataFormat() == target {
+		return data, nil
+	}
+	out, r, err := dsio.ConvertTo(st, target, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: converting body to %s: %w", target, err)
+	}
+	defer r.Close()
+	converted, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: converting body to %s: %w", target, err)
+	}
+
+	out.ErrCount = st.ErrCount
+	out.Length = len(converted)
+	// bodies converted more than once keep the format they were first given in
+	out.Source = st.Source.Clone()
+	if out.Source == nil {
+		src := st.Clone()
+		out.Source = &dataset.SourceFormat{
+			Encoding:     src.Encoding,
+			Format:       src.Format,
+			FormatConfig: src.FormatConfig,
+		}
+	}
+	stg.root.Structure = out
+	return converted, nil
+}
+
+// publish writes staged files to store, then writes the root file
+// referencing them
+func (stg *staging) publish(ctx context.Context, store cafs.Filestore) (string, error) {
+	var added []string
+	for i, f := range stg.files {
+		var (
+			data []byte
+			err  error
+		)
+		if f.encode != nil {
+			data, err = f.encode()
+		} else {
+			data, err = ioutil.ReadFile(f.tmp)
+		}
+		if err != nil {
+			return "", rollback(ctx, store, added, fmt.Errorf("dsfs: reading staged %s: %w", f.name, err))
+		}
+		path, isNew, err := stg.put(ctx, store, f.name, data)
+		if err != nil {
+			return "", rollback(ctx, store, added, fmt.Errorf("dsfs: writing %s: %w", f.name, err))
+		}
+		if isNew {
+			added = append(added, path)
+		}
+		stg.files[i].path = path
+		f.ref(path)
+	}
+
+	data, err := json.Marshal(stg.root)
+	if err != nil {
+		return "", rollback(ctx, store, added, fmt.Errorf("dsfs: encoding %s: %w", PackageFileDataset, err))
+	}
+	path, _, err := stg.put(ctx, store, PackageFileDataset, data)
+	if err != nil {
+		return "", rollback(ctx, store, added, fmt.Errorf("dsfs: writing %s: %w", PackageFileDataset, err))
+	}
+	return path, nil
+}
+
+// put writes a file to store, reporting whether the write added it. Files
+// of a PathStore are checked before they're written, files that are already
+// stored aren't written again. The paths of other stores aren't known until
+// files are written, and writes to them are never reported as added
+func (stg *staging) put(ctx context.Context, store cafs.Filestore, name string, data []byte) (path string, added bool, err error) {
+	ps, ok := store.(PathStore)
+	if !ok {
+		path, err = putFile(ctx, store, name, data)
+		return path, false, err
+	}
+	hash, err := dataset.HashBytes(data, dataset.WithHashFunc(stg.hashFunc))
+	if err != nil {
+		return "", false, err
+	}
+	path = contentPath(ps, hash)
+	start := time.Now()
+	has, err := ps.Has(ctx, path)
+	observe(StoreOpHas, path, 0, start, err)
+	if err != nil {
+		return "", false, err
+	} else if has {
+		return path, false, nil
+	}
+	start = time.Now()
+	err = ps.PutPath(ctx, path, qfs.NewMemfileBytes(name, data))
+	observe(StoreOpPut, path, len(data), start, err)
+	if err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
+
+// rollback deletes the files a failed write added, returning err. A failure
+// to delete is reported alongside err, which is kept as the wrapped error
+func rollback(ctx context.Context, store cafs.Filestore, added []string, err error) error {
+	var failed []string
+	for _, path := range added {
+		start := time.Now()
+		derr := store.Delete(ctx, path)
+		observe(StoreOpDelete, path, 0, start, derr)
+		if derr != nil && derr != cafs.ErrNotFound {
+			log.Errorf("deleting partially written file %s: %s", path, derr)
+			failed = append(failed, path)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w (deleting partially written files %v also failed)", err, failed)
+	}
+	return err
+}
@@ -0,0 +1,676 @@
+package dsfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+func testDataset() *dataset.Dataset {
+	return &dataset.Dataset{
+		Name:      "movies",
+		BodyBytes: []byte("title,year\nalien,1979\n"),
+		Commit:    &dataset.Commit{Title: "initial commit"},
+		Meta:      &dataset.Meta{Title: "movies"},
+		Structure: &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray},
+		Viz:       dataset.NewVizRef("/map/viz"),
+	}
+}
+
+// failingStore fails the n-th write, counting from zero
+type failingStore struct {
+	*MapStore
+	n, puts int
+}
+
+func (s *failingStore) PutPath(ctx context.Context, path string, file qfs.File) error {
+	defer func() { s.puts++ }()
+	if s.puts == s.n {
+		return fmt.Errorf("disk full")
+	}
+	return s.MapStore.PutPath(ctx, path, file)
+}
+
+// failingPlainStore fails the n-th write to a store that chooses paths
+type failingPlainStore struct {
+	*cafs.MapStore
+	n, puts int
+}
+
+func (s *failingPlainStore) Put(ctx context.Context, file qfs.File) (string, error) {
+	defer func() { s.puts++ }()
+	if s.puts == s.n {
+		return "", fmt.Errorf("disk full")
+	}
+	return s.MapStore.Put(ctx, file)
+}
+
+func TestWriteDataset(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	ds := testDataset()
+
+	path, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.BodyBytes == nil || ds.Commit.Title != "initial commit" || ds.Name != "movies" {
+		t.Errorf("expected WriteDataset not to modify the dataset")
+	}
+	// body, commit, meta & structure, plus the root
+	if len(store.Files) != 5 {
+		t.Errorf("expected 5 stored files, got: %d", len(store.Files))
+	}
+
+	root := map[string]interface{}{}
+	readJSONFile(t, store, path, &root)
+	for _, key := range []string{"bodyPath", "commit", "meta", "structure", "viz"} {
+		if _, ok := root[key].(string); !ok {
+			t.Errorf("expected root %s to be a path, got: %#v", key, root[key])
+		}
+	}
+	if root["viz"] != "/map/viz" {
+		t.Errorf("expected reference components to be kept, got: %#v", root["viz"])
+	}
+	if _, ok := root["name"]; ok {
+		t.Errorf("expected transient values to be dropped")
+	}
+
+	st := &dataset.Structure{}
+	readJSONFile(t, store, root["structure"].(string), st)
+	if st.Format != "csv" || st.Path != "" {
+		t.Errorf("expected stored structure to match, got: %#v", st)
+	}
+	f, err := store.Get(ctx, root["bodyPath"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(f); string(data) != "title,year\nalien,1979\n" {
+		t.Errorf("expected stored body to match, got: %q", data)
+	}
+
+	again, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != path {
+		t.Errorf("expected writing the same dataset to give the same path. %s != %s", path, again)
+	}
+}
+
+func TestWriteDatasetBodyFile(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	ds := &dataset.Dataset{Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte("[1,2,3]")))
+
+	path, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &dataset.Dataset{}
+	readJSONFile(t, store, path, root)
+	f, err := store.Get(ctx, root.BodyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(f); string(data) != "[1,2,3]" {
+		t.Errorf("expected stored body to match, got: %q", data)
+	}
+}
+
+func TestWriteDatasetRenderedReadme(t *testing.T) {
+	ctx := context.Background()
+	store := NewMapStore()
+	ds := testDataset()
+	ds.Readme = &dataset.Readme{Format: "md", ScriptBytes: []byte("# {{ meta.title }}")}
+	ds.Readme.SetRenderedFile(qfs.NewMemfileBytes("readme.md", []byte("# movies")))
+
+	path, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := map[string]interface{}{}
+	readJSONFile(t, store, path, &root)
+	rm := &dataset.Readme{}
+	readJSONFile(t, store, root["readme"].(string), rm)
+	if rm.RenderedPath == "" {
+		t.Fatalf("expected the stored readme to have a rendered path")
+	}
+	f, err := store.Get(ctx, rm.RenderedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(f); string(data) != "# movies" {
+		t.Errorf("expected stored rendered readme to match, got: %q", data)
+	}
+}
+
+func TestWriteDatasetScripts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMapStore()
+	ds := testDataset()
+	ds.Transform = &dataset.Transform{
+		Syntax:      "starlark",
+		ScriptBytes: []byte(`load("lib.star", "clean")`),
+		Steps: []*dataset.TransformStep{
+			{Name: "clean", Syntax: "starlark", ScriptBytes: []byte("def clean(): pass")},
+			{Name: "aggregate", Syntax: "sql"},
+		},
+		Files: map[string]*dataset.TransformFile{
+			"lib.star":      {ScriptBytes: []byte("def clean(): pass")},
+			"lib/gtfs.star": {},
+		},
+	}
+	ds.Transform.Steps[1].SetScriptFile(qfs.NewMemfileBytes("aggregate.sql", []byte("select count(*) from a")))
+	ds.Transform.SetFile("lib/gtfs.star", qfs.NewMemfileBytes("gtfs.star", []byte("stops = []")))
+	ds.Viz = &dataset.Viz{Format: "html", ScriptBytes: []byte("<h1>{{ .Meta.Title }}</h1>")}
+	ds.Viz.SetRenderedFile(qfs.NewMemfileBytes("viz.html", []byte("<h1>movies</h1>")))
+	ds.Readme = &dataset.Readme{Format: "md", ScriptBytes: []byte("# movies")}
+
+	path, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := map[string]interface{}{}
+	readJSONFile(t, store, path, &root)
+	tf := map[string]interface{}{}
+	readJSONFile(t, store, root["transform"].(string), &tf)
+	if _, ok := tf["scriptPath"].(string); !ok || tf["scriptBytes"] != nil {
+		t.Errorf("expected the stored transform to reference its script by path, got: %v", tf)
+	}
+
+	got, err := LoadDataset(ctx, store, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != path || got.Transform.Path != root["transform"] {
+		t.Errorf("expected loaded paths to be set, got: %q, %q", got.Path, got.Transform.Path)
+	}
+	scripts := map[string][]byte{
+		"transform":          got.Transform.ScriptBytes,
+		"step clean":         got.Transform.Steps[0].ScriptBytes,
+		"step aggregate":     got.Transform.Steps[1].ScriptBytes,
+		"file lib.star":      got.Transform.Files["lib.star"].ScriptBytes,
+		"file lib/gtfs.star": got.Transform.Files["lib/gtfs.star"].ScriptBytes,
+		"viz":                got.Viz.ScriptBytes,
+		"readme":             got.Readme.ScriptBytes,
+	}
+	expect := map[string]string{
+		"transform":          `load("lib.star", "clean")`,
+		"step clean":         "def clean(): pass",
+		"step aggregate":     "select count(*) from a",
+		"file lib.star":      "def clean(): pass",
+		"file lib/gtfs.star": "stops = []",
+		"viz":                "<h1>{{ .Meta.Title }}</h1>",
+		"readme":             "# movies",
+	}
+	for name, data := range scripts {
+		if string(data) != expect[name] {
+			t.Errorf("%s script mismatch. expected: %q, got: %q", name, expect[name], data)
+		}
+	}
+	if got.Transform.ScriptPath == "" || got.Transform.Steps[1].ScriptPath == "" || got.Transform.Files["lib.star"].Path == "" {
+		t.Errorf("expected loaded scripts to keep their paths")
+	}
+	if got.Meta.Title != "movies" || got.Commit.Title != "initial commit" || got.Structure.Format != "csv" {
+		t.Errorf("expected loaded components to match, got: %v %v %v", got.Meta, got.Commit, got.Structure)
+	}
+	f, err := store.Get(ctx, got.Viz.RenderedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(f); string(data) != "<h1>movies</h1>" {
+		t.Errorf("expected stored rendered viz to match, got: %q", data)
+	}
+	if ds.Transform.ScriptBytes == nil || ds.Viz.ScriptPath != "" {
+		t.Errorf("expected WriteDataset not to modify the dataset")
+	}
+
+	if _, err := LoadDataset(ctx, store, got.Transform.ScriptPath); err == nil {
+		t.Errorf("expected loading a script as a dataset to fail")
+	}
+	if _, err := LoadDataset(ctx, nil, path); !errors.Is(err, dataset.ErrNoResolver) {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
+
+func TestWriteDatasetPreview(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	ds := testDataset()
+	ds.BodyBytes = []byte("alien,1979\naliens,1986\nalien 3,1992\n")
+	ds.Structure.Schema = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "title", "type": "string"},
+				map[string]interface{}{"title": "year", "type": "integer"},
+			},
+		},
+	}
+
+	path, err := WriteDataset(ctx, store, ds, WithPreview(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Preview != nil {
+		t.Errorf("expected WriteDataset not to modify the dataset")
+	}
+	root := map[string]interface{}{}
+	readJSONFile(t, store, path, &root)
+	p := &dataset.Preview{}
+	readJSONFile(t, store, root["preview"].(string), p)
+	body, ok := p.Body.([]interface{})
+	if !ok || len(body) != 2 || p.Head != 1 || p.Total != 3 {
+		t.Fatalf("unexpected stored preview: %#v", p)
+	}
+	if first := body[0].([]interface{}); first[0] != "alien" {
+		t.Errorf("expected the preview to start with the first entry, got: %v", first)
+	}
+
+	again, err := WriteDataset(ctx, store, ds, WithPreview(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != path {
+		t.Errorf("expected previews to be reproducible. %s != %s", path, again)
+	}
+
+	ds.Preview = &dataset.Preview{Head: 1, Body: []interface{}{[]interface{}{"given"}}}
+	path, err = WriteDataset(ctx, store, ds, WithPreview(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = map[string]interface{}{}
+	readJSONFile(t, store, path, &root)
+	p = &dataset.Preview{}
+	readJSONFile(t, store, root["preview"].(string), p)
+	if !reflect.DeepEqual(p.Body, ds.Preview.Body) {
+		t.Errorf("expected a given preview to be kept, got: %#v", p.Body)
+	}
+}
+
+func TestWriteDatasetHashFunc(t *testing.T) {
+	ctx := context.Background()
+	for _, fn := range []string{dataset.HashFuncSHA2256, dataset.HashFuncBlake2b256} {
+		store := cafs.NewMapstore()
+		path, err := WriteDataset(ctx, store, testDataset(), WithHashFunc(fn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := &dataset.Dataset{}
+		readJSONFile(t, store, path, root)
+		st := &dataset.Structure{}
+		readJSONFile(t, store, root.Structure.Path, st)
+
+		expect, _ := dataset.HashBytes(testDataset().BodyBytes, dataset.WithHashFunc(fn))
+		if st.Checksum != expect {
+			t.Errorf("%s: expected body checksum %s, got: %s", fn, expect, st.Checksum)
+		}
+		if got, _ := dataset.HashFunc(st.Checksum); got != fn {
+			t.Errorf("%s: expected checksum to record the hash function, got: %s", fn, got)
+		}
+	}
+}
+
+func TestWriteDatasetErrors(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		description string
+		store       cafs.Filestore
+		ds          *dataset.Dataset
+		opts        []func(*WriteConfig)
+		err         string
+	}{
+		{"no store", nil, testDataset(), nil, "dsfs: store is required"},
+		{"no dataset", cafs.NewMapstore(), nil, nil, "dsfs: dataset is required"},
+		{"inline body", cafs.NewMapstore(), &dataset.Dataset{Body: []interface{}{1}}, nil, "dsfs: dataset body is inlined"},
+		{"unsupported hash func", cafs.NewMapstore(), testDataset(), []func(*WriteConfig){WithHashFunc("md5")}, "dsfs: unsupported hash function \"md5\""},
+	}
+
+	for _, c := range cases {
+		_, err := WriteDataset(ctx, c.store, c.ds, c.opts...)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case '%s' error mismatch. expected: '%s', got: '%s'", c.description, c.err, err)
+		}
+	}
+}
+
+func TestWriteDatasetRollback(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		n   int
+		err string
+	}{
+		{0, "dsfs: writing body.csv: disk full"},
+		{2, "dsfs: writing meta.json: disk full"},
+		{4, "dsfs: writing dataset.json: disk full"},
+	}
+
+	for _, c := range cases {
+		store := &failingStore{MapStore: NewMapStore(), n: c.n}
+		// files stored before a failed write are kept
+		prior, err := store.MapStore.Put(ctx, qfs.NewMemfileBytes("other.json", []byte(`{}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = WriteDataset(ctx, store, testDataset())
+		if err == nil || err.Error() != c.err {
+			t.Errorf("failing put %d: error mismatch. expected: '%s', got: '%s'", c.n, c.err, err)
+			continue
+		}
+		if len(store.Files) != 1 {
+			t.Errorf("failing put %d: expected partially written files to be deleted, %d files remain", c.n, len(store.Files))
+		}
+		if has, _ := store.Has(ctx, prior); !has {
+			t.Errorf("failing put %d: expected prior content to be kept", c.n)
+		}
+	}
+}
+
+func TestWriteDatasetRollbackSharedContent(t *testing.T) {
+	ctx := context.Background()
+	store := &failingStore{MapStore: NewMapStore(), n: -1}
+	v1, err := WriteDataset(ctx, store, testDataset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := len(store.Files)
+
+	// v2 shares the body, meta & structure of v1, only the commit & root are
+	// new. fail writing the root
+	v2 := testDataset()
+	v2.Commit = &dataset.Commit{Title: "second commit"}
+	store.n, store.puts = 1, 0
+	if _, err := WriteDataset(ctx, store, v2); err == nil || err.Error() != "dsfs: writing dataset.json: disk full" {
+		t.Fatalf("expected the root write to fail, got: %v", err)
+	}
+	if len(store.Files) != stored {
+		t.Errorf("expected the failed write to only delete files it added, %d files before, %d after", stored, len(store.Files))
+	}
+	root := &dataset.Dataset{}
+	readJSONFile(t, store, v1, root)
+	for _, path := range []string{root.BodyPath, root.Commit.Path, root.Meta.Path, root.Structure.Path} {
+		if has, _ := store.Has(ctx, path); !has {
+			t.Errorf("expected v1 file %s to be kept", path)
+		}
+	}
+
+	// stores that choose paths keep every file of a failed write
+	plain := &failingPlainStore{MapStore: cafs.NewMapstore(), n: -1}
+	if _, err := WriteDataset(ctx, plain, testDataset()); err != nil {
+		t.Fatal(err)
+	}
+	stored = len(plain.Files)
+	// the new commit is written before writing meta fails
+	plain.n, plain.puts = 2, 0
+	if _, err := WriteDataset(ctx, plain, v2); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if len(plain.Files) != stored+1 {
+		t.Errorf("expected files of a failed write to be kept, %d files before, %d after", stored, len(plain.Files))
+	}
+}
+
+func TestWriteDatasetHashFuncPaths(t *testing.T) {
+	ctx := context.Background()
+	store := NewMapStore()
+	sha, err := WriteDataset(ctx, store, testDataset(), WithHashFunc(dataset.HashFuncSHA2256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blake, err := WriteDataset(ctx, store, testDataset(), WithHashFunc(dataset.HashFuncBlake2b256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha == blake {
+		t.Fatalf("expected hash functions to give different paths, both gave: %s", sha)
+	}
+	for fn, path := range map[string]string{dataset.HashFuncSHA2256: sha, dataset.HashFuncBlake2b256: blake} {
+		root := &dataset.Dataset{}
+		readJSONFile(t, store, path, root)
+		for _, p := range []string{path, root.BodyPath, root.Meta.Path} {
+			if got, err := dataset.HashFunc(strings.TrimPrefix(p, "/map/")); err != nil || got != fn {
+				t.Errorf("expected %s to be addressed with %s, got: %s %v", p, fn, got, err)
+			}
+		}
+	}
+}
+
+func TestWriteDatasetTempDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsfs-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := WriteDataset(context.Background(), NewMapStore(), testDataset(), WithTempDir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := ioutil.ReadDir(dir); len(left) != 0 {
+		t.Errorf("expected staged files to be removed, %d remain", len(left))
+	}
+	if _, err := WriteDataset(context.Background(), NewMapStore(), testDataset(), WithTempDir(filepath.Join(dir, "missing"))); err == nil {
+		t.Errorf("expected a missing temp dir to fail")
+	}
+}
+
+func readJSONFile(t *testing.T, store cafs.Filestore, path string, v interface{}) {
+	t.Helper()
+	f, err := store.Get(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteDatasetStorageFormat(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	ds := testDataset()
+	ds.Structure.Encoding = "UTF-8"
+	ds.Structure.FormatConfig = map[string]interface{}{"headerRow": true}
+	ds.Structure.Schema = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "title", "type": "string"},
+				map[string]interface{}{"title": "year", "type": "integer"},
+			},
+		},
+	}
+
+	path, err := WriteDataset(ctx, store, ds, WithStorageFormat(dataset.CBORDataFormat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Structure.Format != "csv" || ds.Structure.Source != nil {
+		t.Errorf("expected WriteDataset not to modify the dataset")
+	}
+	root := &dataset.Dataset{}
+	readJSONFile(t, store, path, root)
+	st := &dataset.Structure{}
+	readJSONFile(t, store, root.Structure.Path, st)
+	if st.Format != "cbor" || st.Encoding != "" || st.FormatConfig != nil {
+		t.Errorf("expected a cbor structure, got: %#v", st)
+	}
+	expectSource := &dataset.SourceFormat{Encoding: "UTF-8", Format: "csv", FormatConfig: map[string]interface{}{"headerRow": true}}
+	if !reflect.DeepEqual(expectSource, st.Source) {
+		t.Errorf("source mismatch. expected: %#v, got: %#v", expectSource, st.Source)
+	}
+
+	f, err := store.Get(ctx, root.BodyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FileName() != "body.cbor" {
+		t.Errorf("expected a cbor body file, got: %s", f.FileName())
+	}
+	data, _ := ioutil.ReadAll(f)
+	if st.Length != len(data) {
+		t.Errorf("expected length %d, got: %d", len(data), st.Length)
+	}
+	if sum, _ := dataset.HashBytes(data); st.Checksum != sum {
+		t.Errorf("expected the checksum of the stored body")
+	}
+
+	_, r, err := dsio.ConvertToSource(st, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported, _ := ioutil.ReadAll(r); string(exported) != string(testDataset().BodyBytes) {
+		t.Errorf("export mismatch. got: %q", exported)
+	}
+
+	// bodies in the storage format are stored as given
+	ds = &dataset.Dataset{BodyBytes: []byte("[1,2]"), Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}}
+	path, err = WriteDataset(ctx, store, ds, WithStorageFormat(dataset.JSONDataFormat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = &dataset.Dataset{}
+	readJSONFile(t, store, path, root)
+	readJSONFile(t, store, root.Structure.Path, st)
+	if st.Source != nil {
+		t.Errorf("expected no source format, got: %#v", st.Source)
+	}
+
+	ds = &dataset.Dataset{BodyBytes: []byte("[1,2]"), Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}}
+	if _, err := WriteDataset(ctx, store, ds, WithStorageFormat(dataset.PBFDataFormat)); err == nil || err.Error() != `dsfs: converting body to pbf: convert: cannot convert to "pbf"` {
+		t.Errorf("expected an unsupported storage format error, got: %v", err)
+	}
+}
+
+func TestWriteDatasetPartitioned(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	body := "date,pm10\n2020-02-03,41\n2020-01-01,58\n2020-01-31,35\n"
+	ds := &dataset.Dataset{
+		BodyBytes: []byte(body),
+		Structure: &dataset.Structure{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Partition:    &dataset.Partition{Column: "date", Granularity: dataset.PartitionMonth},
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "date", "type": "string"},
+						map[string]interface{}{"title": "pm10", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+
+	path, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &dataset.Dataset{}
+	readJSONFile(t, store, path, root)
+	if root.BodyPath != "" || len(root.BodyShards) != 2 {
+		t.Fatalf("expected 2 shards in place of a body path, got: %q %v", root.BodyPath, root.BodyShards)
+	}
+	shard := root.BodyShards[0]
+	if shard.Key != "2020-01" || shard.Entries != 2 || shard.Path == "" {
+		t.Errorf("expected the first shard to hold january, got: %#v", shard)
+	}
+	f, err := store.Get(ctx, shard.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(f)
+	if f.FileName() != "body.0.csv" || string(data) != "date,pm10\n2020-01-01,58\n2020-01-31,35\n" {
+		t.Errorf("shard mismatch. got %s: %q", f.FileName(), data)
+	}
+	if shard.Length != len(data) {
+		t.Errorf("expected shard length %d, got: %d", len(data), shard.Length)
+	}
+
+	readJSONFile(t, store, root.Structure.Path, root.Structure)
+	if sum, _ := dataset.HashBytes([]byte(body)); root.Structure.Checksum != sum {
+		t.Errorf("expected the checksum of the whole body")
+	}
+	r, err := dsio.NewDatasetReader(ctx, root, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err = dsio.Pipeline(r).FilterPartitions(dsio.KeyRange("2020-02", "")).Reader(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []interface{}{"2020-02-03", int64(41)}; !reflect.DeepEqual(expect, ent.Value) {
+		t.Errorf("entry mismatch. expected: %v, got: %v", expect, ent.Value)
+	}
+}
+
+func TestWriteDatasetPartitionedEmpty(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	ds := &dataset.Dataset{
+		BodyBytes: []byte("date,pm10\n"),
+		Structure: &dataset.Structure{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Partition:    &dataset.Partition{Column: "date", Granularity: dataset.PartitionMonth},
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "date", "type": "string"},
+						map[string]interface{}{"title": "pm10", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+
+	path, err := WriteDataset(ctx, store, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &dataset.Dataset{}
+	readJSONFile(t, store, path, root)
+	if len(root.BodyShards) != 1 || root.BodyShards[0].Key != "" || root.BodyShards[0].Entries != 0 || root.BodyShards[0].Path == "" {
+		t.Fatalf("expected a single empty shard, got: %v", root.BodyShards)
+	}
+	readJSONFile(t, store, root.Structure.Path, root.Structure)
+	r, err := dsio.NewDatasetReader(ctx, root, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := r.ReadEntry(); err != io.EOF {
+		t.Errorf("expected an empty body, got: %v", err)
+	}
+}
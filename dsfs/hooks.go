@@ -0,0 +1,106 @@
+package dsfs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/qri-io/dataset"
+)
+
+// Hooks are notified when WriteDataset stores a dataset, for work like
+// triggering webhooks, invalidating caches or syncing a portal when a new
+// version lands. Hooks are only called once a dataset is published, never
+// for a write that fails, and are called synchronously before WriteDataset
+// returns, so long-running work should be handed off
+type Hooks interface {
+	// OnComponentWritten is called for each file written for the dataset at
+	// datasetPath, in write order, before OnDatasetWritten. name is the
+	// package file name, eg. PackageFileMeta or "body.csv". Components kept
+	// as references to stored files aren't written, and aren't reported
+	OnComponentWritten(ctx context.Context, datasetPath, name, path string)
+	// OnDatasetWritten is called with the path & stored root document of a
+	// written dataset. Components of the root are references to their files
+	OnDatasetWritten(ctx context.Context, path string, ds *dataset.Dataset)
+}
+
+// HookFuncs adapts funcs to the Hooks interface. Nil funcs are skipped
+type HookFuncs struct {
+	ComponentWritten func(ctx context.Context, datasetPath, name, path string)
+	DatasetWritten   func(ctx context.Context, path string, ds *dataset.Dataset)
+}
+
+var _ Hooks = HookFuncs{}
+
+// OnComponentWritten calls ComponentWritten
+func (h HookFuncs) OnComponentWritten(ctx context.Context, datasetPath, name, path string) {
+	if h.ComponentWritten != nil {
+		h.ComponentWritten(ctx, datasetPath, name, path)
+	}
+}
+
+// OnDatasetWritten calls DatasetWritten
+func (h HookFuncs) OnDatasetWritten(ctx context.Context, path string, ds *dataset.Dataset) {
+	if h.DatasetWritten != nil {
+		h.DatasetWritten(ctx, path, ds)
+	}
+}
+
+var (
+	hooksLk sync.Mutex
+	hooks   []*registeredHooks
+)
+
+// registeredHooks gives each registration a distinct pointer to remove
+type registeredHooks struct{ Hooks }
+
+// RegisterHooks adds hooks called by every dataset write, returning a func
+// that removes them. Registered hooks are called before hooks set with
+// WithHooks, in registration order
+func RegisterHooks(h Hooks) (unregister func()) {
+	reg := &registeredHooks{h}
+	hooksLk.Lock()
+	hooks = append(hooks, reg)
+	hooksLk.Unlock()
+	return func() {
+		hooksLk.Lock()
+		defer hooksLk.Unlock()
+		for i, x := range hooks {
+			if x == reg {
+				hooks = append(hooks[:i:i], hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// WithHooks adds hooks called by a single write
+func WithHooks(h ...Hooks) func(*WriteConfig) {
+	return func(c *WriteConfig) {
+		c.Hooks = append(c.Hooks, h...)
+	}
+}
+
+// notifyWritten calls registered & configured hooks for a published
+// dataset. Each hook gets a copy of the root, so hooks can't change what
+// later hooks see
+func notifyWritten(ctx context.Context, cfg *WriteConfig, stg *staging, path string) {
+	hooksLk.Lock()
+	all := make([]Hooks, 0, len(hooks)+len(cfg.Hooks))
+	for _, reg := range hooks {
+		all = append(all, reg.Hooks)
+	}
+	hooksLk.Unlock()
+	all = append(all, cfg.Hooks...)
+
+	for _, h := range all {
+		if h == nil {
+			continue
+		}
+		for _, f := range stg.files {
+			h.OnComponentWritten(ctx, path, f.name, f.path)
+		}
+		ds := stg.root.Clone()
+		ds.Path = path
+		h.OnDatasetWritten(ctx, path, ds)
+	}
+}
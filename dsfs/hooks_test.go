@@ -0,0 +1,88 @@
+package dsfs
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// recordingHooks records the order hooks are called in
+type recordingHooks struct {
+	calls []string
+	roots []*dataset.Dataset
+}
+
+func (h *recordingHooks) OnComponentWritten(ctx context.Context, datasetPath, name, path string) {
+	h.calls = append(h.calls, name)
+}
+
+func (h *recordingHooks) OnDatasetWritten(ctx context.Context, path string, ds *dataset.Dataset) {
+	h.calls = append(h.calls, PackageFileDataset)
+	h.roots = append(h.roots, ds)
+}
+
+func TestWriteDatasetHooks(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+
+	var order []string
+	unregister := RegisterHooks(HookFuncs{DatasetWritten: func(ctx context.Context, path string, ds *dataset.Dataset) {
+		order = append(order, "registered")
+	}})
+	defer unregister()
+	configured := HookFuncs{DatasetWritten: func(ctx context.Context, path string, ds *dataset.Dataset) {
+		order = append(order, "configured")
+	}}
+
+	rec := &recordingHooks{}
+	var componentPaths []string
+	paths := HookFuncs{ComponentWritten: func(ctx context.Context, datasetPath, name, path string) {
+		componentPaths = append(componentPaths, datasetPath)
+	}}
+	path, err := WriteDataset(ctx, store, testDataset(), WithHooks(configured, rec, paths))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := []string{"registered", "configured"}; !reflect.DeepEqual(expect, order) {
+		t.Errorf("hook order mismatch. expected: %v, got: %v", expect, order)
+	}
+	// the viz reference isn't written
+	expect := []string{"body.csv", PackageFileCommit, PackageFileMeta, PackageFileStructure, PackageFileDataset}
+	if !reflect.DeepEqual(expect, rec.calls) {
+		t.Errorf("call mismatch. expected: %v, got: %v", expect, rec.calls)
+	}
+	for _, p := range componentPaths {
+		if p != path {
+			t.Errorf("expected components to report the dataset path %s, got: %s", path, p)
+		}
+	}
+	root := rec.roots[0]
+	if root.Path != path || root.Meta == nil || root.Meta.Path == "" || !root.Meta.IsEmpty() {
+		t.Errorf("expected the stored root with component references, got: %#v", root)
+	}
+
+	unregister()
+	order = nil
+	if _, err := WriteDataset(ctx, store, testDataset()); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 0 {
+		t.Errorf("expected unregistered hooks not to be called, got: %v", order)
+	}
+}
+
+func TestWriteDatasetHooksFailedWrite(t *testing.T) {
+	ctx := context.Background()
+	rec := &recordingHooks{}
+	store := &failingStore{MapStore: NewMapStore(), n: 4}
+	if _, err := WriteDataset(ctx, store, testDataset(), WithHooks(rec)); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if len(rec.calls) != 0 {
+		t.Errorf("expected hooks not to be called for a failed write, got: %v", rec.calls)
+	}
+}
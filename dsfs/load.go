@@ -0,0 +1,96 @@
+package dsfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// LoadDataset reads a stored dataset, setting it's path. Component
+// references are replaced with the stored components, and stored scripts of
+// the transform, it's steps & files, the viz and the readme are read into
+// their ScriptBytes, keeping script paths. Bodies & rendered files aren't
+// read, see dsio.NewDatasetReader
+func LoadDataset(ctx context.Context, resolver qfs.PathResolver, path string) (*dataset.Dataset, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("dsfs: %w", dataset.ErrNoResolver)
+	}
+	data, err := readFile(ctx, resolver, path)
+	if err != nil {
+		return nil, err
+	}
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(data, ds); err != nil {
+		return nil, fmt.Errorf("dsfs: decoding %s: %w", path, err)
+	}
+	if dataset.Kind(ds.Qri).Type() != dataset.KindDataset.Type() {
+		return nil, fmt.Errorf("dsfs: %s is not a dataset", path)
+	}
+	for _, c := range components {
+		cmp := c.get(ds)
+		if cmp == nil || !cmp.IsEmpty() {
+			continue
+		}
+		ref := componentPath(cmp)
+		if ref == nil || *ref == "" {
+			continue
+		}
+		cmpPath := *ref
+		data, err := readFile(ctx, resolver, cmpPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, cmp); err != nil {
+			return nil, fmt.Errorf("dsfs: decoding %s %s: %w", c.name, cmpPath, err)
+		}
+		*componentPath(cmp) = cmpPath
+	}
+	if err := loadScripts(ctx, resolver, ds); err != nil {
+		return nil, err
+	}
+	ds.Path = path
+	return ds, nil
+}
+
+// readFile reads the full content of a stored file
+func readFile(ctx context.Context, resolver qfs.PathResolver, path string) ([]byte, error) {
+	f, err := getFile(ctx, resolver, path)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: reading %s: %w", path, err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: reading %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// componentPath gives the path field of a component
+func componentPath(c component) *string {
+	switch x := c.(type) {
+	case *dataset.Commit:
+		return &x.Path
+	case *dataset.Meta:
+		return &x.Path
+	case *dataset.Preview:
+		return &x.Path
+	case *dataset.Provenance:
+		return &x.Path
+	case *dataset.Readme:
+		return &x.Path
+	case *dataset.Stats:
+		return &x.Path
+	case *dataset.Structure:
+		return &x.Path
+	case *dataset.Transform:
+		return &x.Path
+	case *dataset.Viz:
+		return &x.Path
+	}
+	return nil
+}
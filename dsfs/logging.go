@@ -0,0 +1,47 @@
+package dsfs
+
+import (
+	"sync/atomic"
+
+	logger "github.com/ipfs/go-log"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// defaultLogger writes to the "dsfs" go-log subsystem
+var defaultLogger dsio.Logger = logger.Logger("dsfs")
+
+// log is the package logger, forwarding to the logger set with SetLogger
+var log = &switchLogger{}
+
+// SetLogger replaces the logger dsfs writes to, see dsio.Logger. A nil
+// logger restores the default. SetLogger is safe to call while writes are in
+// progress
+func SetLogger(l dsio.Logger) {
+	if l == nil {
+		l = defaultLogger
+	}
+	log.v.Store(loggerBox{l})
+}
+
+// loggerBox gives atomic.Value a consistent concrete type to store
+type loggerBox struct{ dsio.Logger }
+
+// switchLogger forwards logs to a replaceable logger
+type switchLogger struct {
+	v atomic.Value
+}
+
+func (s *switchLogger) logger() dsio.Logger {
+	if b, ok := s.v.Load().(loggerBox); ok {
+		return b.Logger
+	}
+	return defaultLogger
+}
+
+func (s *switchLogger) Debugf(format string, args ...interface{}) {
+	s.logger().Debugf(format, args...)
+}
+
+func (s *switchLogger) Errorf(format string, args ...interface{}) {
+	s.logger().Errorf(format, args...)
+}
@@ -0,0 +1,56 @@
+package dsfs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Debug(args ...interface{}) {
+	l.lines = append(l.lines, "debug: "+fmt.Sprint(args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.lines = append(l.lines, "debug: "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Error(args ...interface{}) {
+	l.lines = append(l.lines, "error: "+fmt.Sprint(args...))
+}
+
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.lines = append(l.lines, "error: "+fmt.Sprintf(format, args...))
+}
+
+func TestSetLogger(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+
+	ctx := context.Background()
+	path, err := WriteDataset(ctx, NewMapStore(), testDataset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := fmt.Sprintf("debug: wrote dataset %s, 5 files", path)
+	if len(rec.lines) != 1 || rec.lines[0] != expect {
+		t.Errorf("log mismatch. expected: %v, got: %v", []string{expect}, rec.lines)
+	}
+
+	if _, err := WriteDataset(ctx, &failingStore{MapStore: NewMapStore()}, testDataset()); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if len(rec.lines) != 2 || !strings.HasPrefix(rec.lines[1], "debug: writing dataset: dsfs: writing body.csv: disk full") {
+		t.Errorf("expected the failed write to be logged, got: %v", rec.lines)
+	}
+
+	SetLogger(nil)
+	if log.logger() != defaultLogger {
+		t.Errorf("expected nil logger to restore the default")
+	}
+}
@@ -0,0 +1,88 @@
+package dsfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// Store operations measured by metrics
+const (
+	// StoreOpGet reads a file
+	StoreOpGet = "get"
+	// StoreOpHas checks a file is stored
+	StoreOpHas = "has"
+	// StoreOpPut writes a file
+	StoreOpPut = "put"
+	// StoreOpDelete deletes a file
+	StoreOpDelete = "delete"
+)
+
+// StoreStats measures one store operation
+type StoreStats struct {
+	// Op is the operation, one of the StoreOp constants
+	Op string
+	// Path is the path of the file. Paths of failed writes to stores that
+	// choose paths are empty
+	Path string
+	// Bytes is the size of written files, 0 for other operations
+	Bytes int
+	// Elapsed is the time the operation took
+	Elapsed time.Duration
+	// Err is the error the operation failed with
+	Err error
+}
+
+// Metrics are optional callbacks for measuring dsfs reads & writes. nil
+// callbacks are skipped
+type Metrics struct {
+	// Store is called after every store operation
+	Store func(StoreStats)
+}
+
+// metrics holds a metricsBox
+var metrics atomic.Value
+
+// metricsBox gives atomic.Value a consistent concrete type to store
+type metricsBox struct{ *Metrics }
+
+// SetMetrics sets the callbacks dsfs reports measurements to, nil disables
+// metrics
+func SetMetrics(m *Metrics) {
+	metrics.Store(metricsBox{m})
+}
+
+// observe reports a store operation that started at start
+func observe(op, path string, bytes int, start time.Time, err error) {
+	b, _ := metrics.Load().(metricsBox)
+	if b.Metrics == nil || b.Store == nil {
+		return
+	}
+	b.Store(StoreStats{Op: op, Path: path, Bytes: bytes, Elapsed: time.Since(start), Err: err})
+}
+
+// putFile writes a file to a store that chooses paths, measuring the write
+func putFile(ctx context.Context, store cafs.Filestore, name string, data []byte) (string, error) {
+	start := time.Now()
+	path, err := store.Put(ctx, qfs.NewMemfileBytes(name, data))
+	observe(StoreOpPut, path, len(data), start, err)
+	return path, err
+}
+
+// getFile reads a file, measuring the read. Missing files of content
+// addressed stores are reported as dataset.ErrNotFound
+func getFile(ctx context.Context, resolver qfs.PathResolver, path string) (qfs.File, error) {
+	start := time.Now()
+	f, err := resolver.Get(ctx, path)
+	if errors.Is(err, cafs.ErrNotFound) {
+		err = fmt.Errorf("%w: %s", dataset.ErrNotFound, path)
+	}
+	observe(StoreOpGet, path, 0, start, err)
+	return f, err
+}
@@ -0,0 +1,47 @@
+package dsfs
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	var ops []StoreStats
+	SetMetrics(&Metrics{Store: func(s StoreStats) { ops = append(ops, s) }})
+	defer SetMetrics(nil)
+
+	ctx := context.Background()
+	store := &failingStore{MapStore: NewMapStore(), n: 2}
+	if _, err := WriteDataset(ctx, store, testDataset()); err == nil {
+		t.Fatal("expected the third write to fail")
+	}
+
+	// has & put for the two written files, has & the failed put, then the
+	// rollback deletes of the written files
+	expect := []string{StoreOpHas, StoreOpPut, StoreOpHas, StoreOpPut, StoreOpHas, StoreOpPut, StoreOpDelete, StoreOpDelete}
+	if len(ops) != len(expect) {
+		t.Fatalf("expected %d measured operations, got %d: %v", len(expect), len(ops), ops)
+	}
+	for i, op := range ops {
+		if op.Op != expect[i] {
+			t.Errorf("operation %d: expected %s, got %s", i, expect[i], op.Op)
+		}
+		if op.Path == "" {
+			t.Errorf("operation %d: expected a path", i)
+		}
+	}
+	if ops[1].Bytes == 0 || ops[1].Err != nil {
+		t.Errorf("expected the first put to measure written bytes, got: %#v", ops[1])
+	}
+	if ops[5].Err == nil {
+		t.Errorf("expected the failed put to report it's error")
+	}
+
+	SetMetrics(nil)
+	if _, err := LoadCatalog(ctx, store, "/map/missing"); err == nil {
+		t.Fatal("expected loading a missing catalog to fail")
+	}
+	if len(ops) != len(expect) {
+		t.Errorf("expected measurements to stop once metrics are unset")
+	}
+}
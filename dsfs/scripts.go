@@ -0,0 +1,168 @@
+package dsfs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// stageScripts stages the script files of the ds transform, transform steps,
+// transform files, viz & readme, and the rendered viz, setting their paths
+// on the root components once they're written. Scripts are read from the
+// open file a component of ds holds, or from inline bytes. Scripts that are
+// only a path are kept as references. It reports the names of components
+// that reference staged files, which are encoded once those files are
+// written
+func (stg *staging) stageScripts(ctx context.Context, ds *dataset.Dataset) (map[string]bool, error) {
+	refs := map[string]bool{}
+	stage := func(component, name string, file qfs.File, src dataset.FileSource, ref func(path string)) error {
+		staged, err := stg.stageScript(ctx, name, file, src, ref)
+		if staged {
+			refs[component] = true
+		}
+		return err
+	}
+	root := stg.root
+
+	if q := root.Transform; q != nil && ds.Transform != nil && !q.IsEmpty() {
+		if err := stage(PackageFileTransform, "transform.star", ds.Transform.ScriptFile(), inlineSource(q.ScriptBytes, q.ScriptSource(nil)), func(path string) {
+			q.ScriptPath = path
+		}); err != nil {
+			return nil, err
+		}
+		for i, s := range q.Steps {
+			if s == nil || i >= len(ds.Transform.Steps) || ds.Transform.Steps[i] == nil {
+				continue
+			}
+			s := s
+			if err := stage(PackageFileTransform, "step."+path.Base(s.Name), ds.Transform.Steps[i].ScriptFile(), inlineSource(s.ScriptBytes, s.ScriptSource(nil)), func(path string) {
+				s.ScriptPath = path
+			}); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range q.FileNames() {
+			f := q.Files[name]
+			if f == nil {
+				continue
+			}
+			if err := stage(PackageFileTransform, path.Base(name), ds.Transform.File(name), inlineSource(f.ScriptBytes, dataset.NewBytesSource(name, f.ScriptBytes)), func(path string) {
+				f.Path = path
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if v := root.Viz; v != nil && ds.Viz != nil && !v.IsEmpty() {
+		if err := stage(PackageFileViz, "template.html", ds.Viz.ScriptFile(), inlineSource(v.ScriptBytes, v.ScriptSource(nil)), func(path string) {
+			v.ScriptPath = path
+		}); err != nil {
+			return nil, err
+		}
+		name := PackageFileRenderedViz
+		if v.Format != "" {
+			name += "." + v.Format
+		}
+		if err := stage(PackageFileViz, name, ds.Viz.RenderedFile(), nil, func(path string) {
+			v.RenderedPath = path
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if rm := root.Readme; rm != nil && ds.Readme != nil && !rm.IsEmpty() {
+		if err := stage(PackageFileReadme, "readme.md", ds.Readme.ScriptFile(), inlineSource(rm.ScriptBytes, rm.ScriptSource(nil)), func(path string) {
+			rm.ScriptPath = path
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// inlineSource gives src when a component holds inline script bytes, so
+// scripts that are only a path aren't read
+func inlineSource(data []byte, src dataset.FileSource) dataset.FileSource {
+	if data == nil {
+		return nil
+	}
+	return src
+}
+
+// stageScript stages a script, reporting whether there was one. An open
+// file takes precedence over src & is consumed
+func (stg *staging) stageScript(ctx context.Context, name string, file qfs.File, src dataset.FileSource, ref func(path string)) (bool, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case file != nil:
+		data, err = ioutil.ReadAll(file)
+		file.Close()
+	case src != nil:
+		data, err = dataset.ReadSource(ctx, src)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("dsfs: reading %s: %w", name, err)
+	}
+	return true, stg.stage(name, data, ref)
+}
+
+// loadScripts reads the scripts of stored transforms, transform steps,
+// transform files, vizes & readmes into their ScriptBytes, keeping script
+// paths. Rendered files are left as paths
+func loadScripts(ctx context.Context, resolver qfs.PathResolver, ds *dataset.Dataset) error {
+	load := func(name string, src dataset.FileSource, set func(data []byte)) error {
+		if src == nil {
+			return nil
+		}
+		data, err := dataset.ReadSource(ctx, src)
+		if err != nil {
+			return fmt.Errorf("dsfs: reading %s: %w", name, err)
+		}
+		set(data)
+		return nil
+	}
+	if q := ds.Transform; q != nil {
+		if err := load("transform script", q.ScriptSource(resolver), func(data []byte) { q.ScriptBytes = data }); err != nil {
+			return err
+		}
+		for _, s := range q.Steps {
+			if s == nil {
+				continue
+			}
+			s := s
+			if err := load(fmt.Sprintf("step '%s' script", s.Name), s.ScriptSource(resolver), func(data []byte) { s.ScriptBytes = data }); err != nil {
+				return err
+			}
+		}
+		for _, name := range q.FileNames() {
+			f := q.Files[name]
+			if f == nil || f.ScriptBytes != nil || f.Path == "" {
+				continue
+			}
+			if err := load(fmt.Sprintf("transform file '%s'", name), dataset.NewPathSource(resolver, f.Path), func(data []byte) { f.ScriptBytes = data }); err != nil {
+				return err
+			}
+		}
+	}
+	if v := ds.Viz; v != nil {
+		if err := load("viz script", v.ScriptSource(resolver), func(data []byte) { v.ScriptBytes = data }); err != nil {
+			return err
+		}
+	}
+	if rm := ds.Readme; rm != nil {
+		if err := load("readme script", rm.ScriptSource(resolver), func(data []byte) { rm.ScriptBytes = data }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
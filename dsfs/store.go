@@ -0,0 +1,85 @@
+package dsfs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// PathStore is implemented by filestores that store files at paths the
+// writer chooses. WriteDataset stores the files of a dataset in a PathStore
+// at the multihash of their content under the configured hash function,
+// prefixed with the store PathPrefix, eg. "/map/<multihash>". Stores that
+// don't implement PathStore choose paths themselves, and the hash function
+// only affects the body checksum
+type PathStore interface {
+	cafs.Filestore
+	// PutPath stores a file at path
+	PutPath(ctx context.Context, path string, file qfs.File) error
+}
+
+// Lister is implemented by filestores that can list the paths of every
+// stored file, for walking a store without knowing what's in it
+type Lister interface {
+	List(ctx context.Context) ([]string, error)
+}
+
+// contentPath gives the path a file with content hash is stored at in a
+// PathStore
+func contentPath(store PathStore, hash string) string {
+	return fmt.Sprintf("/%s/%s", store.PathPrefix(), hash)
+}
+
+// MapStore is an in-memory PathStore, a cafs.MapStore that stores files at
+// paths chosen by WriteDataset
+type MapStore struct {
+	*cafs.MapStore
+}
+
+var (
+	_ PathStore = (*MapStore)(nil)
+	_ Lister    = (*MapStore)(nil)
+)
+
+// NewMapStore allocates an empty in-memory PathStore
+func NewMapStore() *MapStore {
+	return &MapStore{MapStore: cafs.NewMapstore()}
+}
+
+// PutPath stores a file at path. Directories can't be stored at a path
+func (m *MapStore) PutPath(ctx context.Context, path string, file qfs.File) error {
+	if file.IsDirectory() {
+		return fmt.Errorf("cannot store a directory at %s", path)
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file.FileName(), err)
+	}
+	m.Files[path] = memFile{path: file.FullPath(), data: data}
+	return nil
+}
+
+// List gives the paths of stored files in sorted order
+func (m *MapStore) List(ctx context.Context) ([]string, error) {
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// memFile is a file stored in a MapStore
+type memFile struct {
+	path string
+	data []byte
+}
+
+// File opens the stored file
+func (f memFile) File() qfs.File {
+	return qfs.NewMemfileBytes(f.path, f.data)
+}
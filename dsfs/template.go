@@ -0,0 +1,67 @@
+package dsfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// WriteTemplate stores a template as a single file, returning the path of
+// the stored file. Templates are validated before they're written & stored
+// without transient values, so transform secrets are always redacted. t
+// itself isn't modified
+func WriteTemplate(ctx context.Context, store cafs.Filestore, t *dataset.Template) (string, error) {
+	if store == nil {
+		return "", fmt.Errorf("dsfs: store is required")
+	}
+	if t == nil {
+		return "", fmt.Errorf("dsfs: template is required")
+	}
+	if t.Path != "" && t.IsEmpty() {
+		return "", fmt.Errorf("dsfs: template %s is a reference", t.Path)
+	}
+	if err := t.Validate(); err != nil {
+		return "", fmt.Errorf("dsfs: invalid template: %w", err)
+	}
+	t = t.Clone()
+	t.DropTransientValues()
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", fmt.Errorf("dsfs: encoding %s: %w", PackageFileTemplate, err)
+	}
+	path, err := putFile(ctx, store, PackageFileTemplate, data)
+	if err != nil {
+		return "", fmt.Errorf("dsfs: writing %s: %w", PackageFileTemplate, err)
+	}
+	return path, nil
+}
+
+// LoadTemplate reads a stored template, setting it's path
+func LoadTemplate(ctx context.Context, resolver qfs.PathResolver, path string) (*dataset.Template, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("dsfs: %w", dataset.ErrNoResolver)
+	}
+	f, err := getFile(ctx, resolver, path)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: reading %s: %w", path, err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("dsfs: reading %s: %w", path, err)
+	}
+	t := &dataset.Template{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("dsfs: decoding %s: %w", path, err)
+	}
+	if dataset.Kind(t.Qri).Type() != dataset.KindTemplate.Type() {
+		return nil, fmt.Errorf("dsfs: %s is not a template", path)
+	}
+	t.Path = path
+	return t, nil
+}
@@ -0,0 +1,67 @@
+package dsfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	tmpl := &dataset.Template{
+		Path:        "/map/old",
+		NamePattern: "vvs_gtfs_{yyyy}_{mm}",
+		Meta:        &dataset.Meta{Title: "VVS timetable {yyyy}-{mm}"},
+		Structure:   &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray},
+		Transform:   &dataset.Transform{Syntax: "sql", Secrets: map[string]string{"token": "hunter2"}},
+	}
+	path, err := WriteTemplate(ctx, store, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Path != "/map/old" || tmpl.Transform.Secrets["token"] != "hunter2" {
+		t.Errorf("expected WriteTemplate not to modify the template")
+	}
+	got, err := LoadTemplate(ctx, store, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != path || got.Qri != dataset.KindTemplate.String() || got.NamePattern != tmpl.NamePattern {
+		t.Errorf("unexpected loaded template: %#v", got)
+	}
+	if got.Transform.Secrets["token"] != dataset.SecretRedacted {
+		t.Errorf("expected stored secrets to be redacted, got: %#v", got.Transform.Secrets)
+	}
+	ds, err := got.Instantiate(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Name != "vvs_gtfs_2026_10" || ds.Structure.Format != "csv" {
+		t.Errorf("unexpected instance of a loaded template: %#v", ds)
+	}
+
+	cases := []struct {
+		tmpl *dataset.Template
+		err  string
+	}{
+		{&dataset.Template{}, "dsfs: invalid template: namePattern is required"},
+		{dataset.NewTemplateRef("/map/ref"), "dsfs: template /map/ref is a reference"},
+	}
+	for i, c := range cases {
+		if _, err := WriteTemplate(ctx, store, c.tmpl); err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+	dsPath, err := store.Put(ctx, qfs.NewMemfileBytes(PackageFileDataset, []byte(`{"qri":"ds:0"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplate(ctx, store, dsPath); err == nil || err.Error() != "dsfs: "+dsPath+" is not a template" {
+		t.Errorf("expected a wrong kind error, got: %v", err)
+	}
+}
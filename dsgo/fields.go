@@ -0,0 +1,181 @@
+// Package dsgo maps dataset bodies to Go types. Generate emits Go struct
+// definitions from a structure's JSON schema, and StructReader decodes body
+// entries into structs, so known datasets can be consumed with static types
+// instead of map & slice assertions. In the other direction StructureFor
+// derives a structure from a struct type, and StructWriter writes structs
+// as body entries
+package dsgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// Field describes a single struct field derived from a schema
+type Field struct {
+	// Name is the exported Go field name
+	Name string
+	// Title is the column title or property name the field decodes from, used
+	// as the field's json tag
+	Title string
+	// Type is the Go type expression for the field, eg. "*int64"
+	Type string
+	// Description is the schema description of the field, if any
+	Description string
+}
+
+// Fields derives struct fields from the row schema of a structure. Array
+// rows must describe columns with a tabular schema, object rows must list
+// properties. Fields of array rows are in column order, object row fields
+// are sorted by property name
+func Fields(st *dataset.Structure) ([]Field, error) {
+	if st == nil || st.Schema == nil {
+		return nil, fmt.Errorf("%w: schema is required", dataset.ErrInvalidSchema)
+	}
+	items, _ := st.Schema["items"].(map[string]interface{})
+	if st.Schema["type"] != "array" || items == nil {
+		return nil, fmt.Errorf("%w: schema must describe an array of rows", dataset.ErrInvalidSchema)
+	}
+
+	var fields []Field
+	switch items["type"] {
+	case "array":
+		cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range cols {
+			var types []string
+			if col.Type != nil {
+				types = *col.Type
+			}
+			fields = append(fields, Field{Title: col.Title, Type: goType(types), Description: col.Description})
+		}
+	case "object":
+		props, _ := items["properties"].(map[string]interface{})
+		if len(props) == 0 {
+			return nil, fmt.Errorf("%w: object rows must list properties", dataset.ErrInvalidSchema)
+		}
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, _ := props[name].(map[string]interface{})
+			desc, _ := prop["description"].(string)
+			fields = append(fields, Field{Title: name, Type: goType(schemaTypes(prop["type"])), Description: desc})
+		}
+	default:
+		return nil, fmt.Errorf("%w: rows must be arrays or objects", dataset.ErrInvalidSchema)
+	}
+
+	used := map[string]bool{}
+	for i := range fields {
+		name := fieldName(fields[i].Title, i)
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s%d", fieldName(fields[i].Title, i), n)
+		}
+		used[name] = true
+		fields[i].Name = name
+	}
+	return fields, nil
+}
+
+func schemaTypes(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// goType maps JSON schema types to a Go type. nullable scalars become
+// pointers, fields that accept more than one type use interface{}
+func goType(types []string) string {
+	nullable := false
+	var nonNull []string
+	for _, t := range types {
+		if t == "null" {
+			nullable = true
+			continue
+		}
+		nonNull = append(nonNull, t)
+	}
+	if len(nonNull) != 1 {
+		return "interface{}"
+	}
+
+	var t string
+	switch nonNull[0] {
+	case "string":
+		t = "string"
+	case "integer":
+		t = "int64"
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]interface{}"
+	case "object":
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+	if nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// initialisms are title words that are written in upper case in Go names
+var initialisms = map[string]bool{
+	"id":   true,
+	"url":  true,
+	"uri":  true,
+	"uuid": true,
+	"json": true,
+	"html": true,
+	"http": true,
+	"api":  true,
+}
+
+// fieldName converts a column title to an exported Go identifier, eg.
+// "latitude_deg" becomes "LatitudeDeg"
+func fieldName(title string, i int) string {
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	b := &strings.Builder{}
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		b.WriteRune(unicode.ToUpper(rs[0]))
+		b.WriteString(string(rs[1:]))
+	}
+	name := b.String()
+	if name == "" {
+		return fmt.Sprintf("Col%d", i)
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		return "F" + name
+	}
+	return name
+}
@@ -0,0 +1,58 @@
+package dsgo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestFields(t *testing.T) {
+	objectRows := &dataset.Structure{Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"stop_id":   map[string]interface{}{"type": "string", "description": "unique stop id"},
+				"stop_url":  map[string]interface{}{"type": []interface{}{"string", "null"}},
+				"2nd-level": map[string]interface{}{"type": []interface{}{"integer", "string"}},
+				"tags":      map[string]interface{}{"type": "array"},
+			},
+		},
+	}}
+	got, err := Fields(objectRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Field{
+		{Name: "F2ndLevel", Title: "2nd-level", Type: "interface{}"},
+		{Name: "StopID", Title: "stop_id", Type: "string", Description: "unique stop id"},
+		{Name: "StopURL", Title: "stop_url", Type: "*string"},
+		{Name: "Tags", Title: "tags", Type: "[]interface{}"},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("fields mismatch.\nwant: %v\ngot:  %v", expect, got)
+	}
+}
+
+func TestFieldsDuplicateNames(t *testing.T) {
+	st := &dataset.Structure{Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "name", "type": "string"},
+				map[string]interface{}{"title": "Name", "type": "boolean"},
+				map[string]interface{}{"title": "", "type": "number"},
+			},
+		},
+	}}
+	got, err := Fields(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{got[0].Name, got[1].Name, got[2].Name}
+	if expect := []string{"Name", "Name2", "Col2"}; !reflect.DeepEqual(expect, names) {
+		t.Errorf("names mismatch. expected: %v, got: %v", expect, names)
+	}
+}
@@ -0,0 +1,113 @@
+package dsgo
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/qri-io/dataset"
+)
+
+var structTmpl = template.Must(template.New("struct").Parse(`// Code generated by dsgo. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"github.com/qri-io/dataset/dsgo"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// {{ .Name }} is a single entry of a dataset body
+type {{ .Name }} struct {
+{{- range .Fields }}
+{{- if .Description }}
+	// {{ .Description }}
+{{- end }}
+	{{ .Name }} {{ .Type }} ` + "`" + `json:"{{ .Title }}"` + "`" + `
+{{- end }}
+}
+
+// {{ .Name }}Reader reads {{ .Name }} entries from a dataset body
+type {{ .Name }}Reader struct {
+	r *dsgo.StructReader
+}
+
+// New{{ .Name }}Reader wraps an entry reader, decoding entries into {{ .Name }}
+func New{{ .Name }}Reader(r dsio.EntryReader) *{{ .Name }}Reader {
+	return &{{ .Name }}Reader{r: dsgo.NewStructReader(r)}
+}
+
+// Read decodes the next entry, returning io.EOF at the end of the body
+func (r *{{ .Name }}Reader) Read() (*{{ .Name }}, error) {
+	v := &{{ .Name }}{}
+	if err := r.r.Read(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Close closes the wrapped entry reader
+func (r *{{ .Name }}Reader) Close() error {
+	return r.r.Close()
+}
+`))
+
+// Generate emits gofmt'd Go source declaring a struct named name for the
+// entries of a structure's body, and a typed reader that decodes entries
+// into the struct. The source is part of package pkg. Titles are used as
+// json tags, so titles encoding/json doesn't accept as a tag, and titles
+// that only differ by case are errors
+func Generate(pkg, name string, st *dataset.Structure) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return nil, fmt.Errorf("invalid struct name %q, must be an exported identifier", name)
+	}
+	fields, err := Fields(st)
+	if err != nil {
+		return nil, err
+	}
+	titles := map[string]string{}
+	for i := range fields {
+		title := fields[i].Title
+		if !validTag(title) {
+			return nil, fmt.Errorf("title %q can't be used as a json tag", title)
+		}
+		if prev, ok := titles[strings.ToLower(title)]; ok {
+			return nil, fmt.Errorf("titles %q and %q only differ by case", prev, title)
+		}
+		titles[strings.ToLower(title)] = title
+		// descriptions are emitted as single line comments
+		fields[i].Description = strings.Join(strings.Fields(fields[i].Description), " ")
+	}
+
+	buf := &bytes.Buffer{}
+	err = structTmpl.Execute(buf, map[string]interface{}{
+		"Package": pkg,
+		"Name":    name,
+		"Fields":  fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// validTag checks s is a json tag name encoding/json accepts. Tags it
+// rejects are ignored, leaving the field to decode by it's Go name
+func validTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r) {
+			return false
+		}
+	}
+	return true
+}
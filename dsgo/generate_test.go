@@ -0,0 +1,68 @@
+package dsgo
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dstest"
+)
+
+func TestGenerate(t *testing.T) {
+	got, err := Generate("cities", "City", dstest.Cities().Structure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := ioutil.ReadFile("testdata/city.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expect) != string(got) {
+		t.Errorf("generated source mismatch.\nwant:\n%s\ngot:\n%s", expect, got)
+	}
+}
+
+// titledStructure is a tabular structure with a string column per title
+func titledStructure(titles ...string) *dataset.Structure {
+	cols := []interface{}{}
+	for _, title := range titles {
+		cols = append(cols, map[string]interface{}{"title": title, "type": "string"})
+	}
+	return &dataset.Structure{Format: "json", Schema: map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "array", "items": cols},
+	}}
+}
+
+func TestGenerateTitles(t *testing.T) {
+	if _, err := Generate("stops", "Stop", titledStructure("stop id", "lat:deg", "name (de)")); err != nil {
+		t.Errorf("expected titles with spaces & punctuation to be valid tags. got: %s", err)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	st := dstest.Cities().Structure
+	cases := []struct {
+		pkg, name string
+		st        *dataset.Structure
+		err       string
+	}{
+		{"1cities", "City", st, `invalid package name "1cities"`},
+		{"cities", "city", st, `invalid struct name "city", must be an exported identifier`},
+		{"cities", "City", nil, "invalid schema: schema is required"},
+		{"cities", "City", &dataset.Structure{Schema: dataset.BaseSchemaObject}, "invalid schema: schema must describe an array of rows"},
+		{"cities", "City", titledStructure("a,b"), `title "a,b" can't be used as a json tag`},
+		{"cities", "City", titledStructure("a`b"), "title \"a`b\" can't be used as a json tag"},
+		{"cities", "City", titledStructure(`a"b`), `title "a\"b" can't be used as a json tag`},
+		{"cities", "City", titledStructure(`a\b`), `title "a\\b" can't be used as a json tag`},
+		{"cities", "City", titledStructure("it's"), `title "it's" can't be used as a json tag`},
+		{"cities", "City", titledStructure(""), `title "" can't be used as a json tag`},
+		{"cities", "City", titledStructure("name", "Name"), `titles "name" and "Name" only differ by case`},
+	}
+	for i, c := range cases {
+		_, err := Generate(c.pkg, c.name, c.st)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package dsgo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// StructReader decodes the entries of a body into structs. Array rows are
+// matched to fields by column title, object rows by property name, both
+// using json struct tags. Values are converted as encoding/json does
+type StructReader struct {
+	r      dsio.EntryReader
+	titles []string
+}
+
+// NewStructReader wraps an entry reader. Array rows can only be decoded if
+// the reader's structure has a tabular schema with column titles
+func NewStructReader(r dsio.EntryReader) *StructReader {
+	sr := &StructReader{r: r}
+	if st := r.Structure(); st != nil {
+		if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+			sr.titles = cols.Titles()
+		}
+	}
+	return sr
+}
+
+// Read decodes the next entry into dst, which must be a pointer. Read returns
+// io.EOF at the end of the body
+func (r *StructReader) Read(dst interface{}) error {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return err
+	}
+
+	v := ent.Value
+	if row, ok := v.([]interface{}); ok {
+		if r.titles == nil {
+			return fmt.Errorf("decoding entry %d: array rows require a tabular schema with column titles", ent.Index)
+		}
+		obj := make(map[string]interface{}, len(row))
+		for i, val := range row {
+			if i < len(r.titles) {
+				obj[r.titles[i]] = val
+			}
+		}
+		v = obj
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("decoding entry %d: %w", ent.Index, err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("decoding entry %d: %w", ent.Index, err)
+	}
+	return nil
+}
+
+// Close closes the wrapped entry reader
+func (r *StructReader) Close() error {
+	return r.r.Close()
+}
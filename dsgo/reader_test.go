@@ -0,0 +1,68 @@
+package dsgo
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/dstest"
+)
+
+type city struct {
+	City   string  `json:"city"`
+	Pop    int64   `json:"pop"`
+	AvgAge float64 `json:"avg_age"`
+	InUsa  bool    `json:"in_usa"`
+}
+
+func TestStructReader(t *testing.T) {
+	ds := dstest.Cities()
+	r, err := dsio.NewEntryReader(ds.Structure, bytes.NewReader(ds.BodyBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := NewStructReader(r)
+	defer sr.Close()
+
+	var got []city
+	for {
+		c := city{}
+		if err := sr.Read(&c); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected 5 cities, got %d", len(got))
+	}
+	if expect := (city{City: "toronto", Pop: 40000000, AvgAge: 55.5, InUsa: false}); !reflect.DeepEqual(expect, got[0]) {
+		t.Errorf("first city mismatch. expected: %v, got: %v", expect, got[0])
+	}
+}
+
+func TestStructReaderObjects(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	r, err := dsio.NewJSONReader(st, bytes.NewBufferString(`[{"city":"a","pop":2},[1]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := NewStructReader(r)
+
+	c := city{}
+	if err := sr.Read(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.City != "a" || c.Pop != 2 {
+		t.Errorf("decode mismatch. got: %v", c)
+	}
+
+	expect := "decoding entry 1: array rows require a tabular schema with column titles"
+	if err := sr.Read(&c); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+}
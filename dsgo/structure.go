@@ -0,0 +1,140 @@
+package dsgo
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/qri-io/dataset"
+)
+
+// StructureFor derives a structure from a Go struct type, using tabular rows
+// with one column per exported field. v may be a struct, a pointer to a
+// struct, or a slice of either. Column titles come from json struct tags,
+// falling back to field names, and fields tagged "-" are skipped. Embedded
+// structs without a tag are flattened into their parent's columns
+func StructureFor(v interface{}, format dataset.DataFormat) (*dataset.Structure, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("deriving a structure requires a struct type, got %T", v)
+	}
+
+	cols := []interface{}{}
+	for _, f := range structColumns(t) {
+		col := map[string]interface{}{"title": f.title}
+		if typ := schemaType(f.typ); typ != nil {
+			col["type"] = typ
+		}
+		cols = append(cols, col)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("struct %s has no exported fields", t)
+	}
+
+	st := &dataset.Structure{
+		Qri:    dataset.KindStructure.String(),
+		Format: format.String(),
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": cols,
+			},
+		},
+	}
+	if format == dataset.CSVDataFormat {
+		st.FormatConfig = map[string]interface{}{"headerRow": true}
+	}
+	return st, nil
+}
+
+// structColumn is an exported field of a struct
+type structColumn struct {
+	title string
+	typ   reflect.Type
+}
+
+func structColumns(t reflect.Type) []structColumn {
+	var cols []structColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			cols = append(cols, structColumns(ft)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, structColumn{title: name, typ: f.Type})
+	}
+	return cols
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaType gives the JSON schema type of values of a Go type as encoded by
+// encoding/json. Returns nil for types that could encode as any value
+func schemaType(t reflect.Type) interface{} {
+	if t.Kind() == reflect.Ptr {
+		if inner, ok := schemaType(t.Elem()).(string); ok {
+			return []interface{}{inner, "null"}
+		}
+		return schemaType(t.Elem())
+	}
+	if implements(t, textMarshalerType) {
+		// text marshalers like time.Time always encode as strings
+		return "string"
+	}
+	if implements(t, jsonMarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes byte slices as base64 strings
+			return "string"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return nil
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
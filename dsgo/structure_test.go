@@ -0,0 +1,78 @@
+package dsgo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type record struct {
+	base
+	Name    string    `json:"name,omitempty"`
+	Count   *int      `json:"count"`
+	Ratio   float32   `json:"ratio"`
+	Created time.Time `json:"created"`
+	Raw     []byte    `json:"raw"`
+	Tags    []string
+	Meta    map[string]interface{} `json:"meta"`
+	Any     interface{}            `json:"any"`
+	Skip    string                 `json:"-"`
+	hidden  bool
+}
+
+func TestStructureFor(t *testing.T) {
+	st, err := StructureFor([]*record{}, dataset.CSVDataFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Format != "csv" || st.Qri != dataset.KindStructure.String() {
+		t.Errorf("format/kind mismatch. got: %q %q", st.Format, st.Qri)
+	}
+	if !reflect.DeepEqual(st.FormatConfig, map[string]interface{}{"headerRow": true}) {
+		t.Errorf("formatConfig mismatch. got: %v", st.FormatConfig)
+	}
+
+	expect := []interface{}{
+		map[string]interface{}{"title": "id", "type": "string"},
+		map[string]interface{}{"title": "name", "type": "string"},
+		map[string]interface{}{"title": "count", "type": []interface{}{"integer", "null"}},
+		map[string]interface{}{"title": "ratio", "type": "number"},
+		map[string]interface{}{"title": "created", "type": "string"},
+		map[string]interface{}{"title": "raw", "type": "string"},
+		map[string]interface{}{"title": "Tags", "type": "array"},
+		map[string]interface{}{"title": "meta", "type": "object"},
+		map[string]interface{}{"title": "any"},
+	}
+	got := st.Schema["items"].(map[string]interface{})["items"]
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("columns mismatch.\nexpected: %v\ngot:      %v", expect, got)
+	}
+
+	if _, err := Fields(st); err != nil {
+		t.Errorf("derived structure should generate fields: %s", err)
+	}
+}
+
+func TestStructureForErrors(t *testing.T) {
+	cases := []struct {
+		v   interface{}
+		err string
+	}{
+		{nil, "deriving a structure requires a struct type, got <nil>"},
+		{"foo", "deriving a structure requires a struct type, got string"},
+		{[]int{}, "deriving a structure requires a struct type, got []int"},
+		{struct{ a int }{}, "struct struct { a int } has no exported fields"},
+	}
+	for i, c := range cases {
+		_, err := StructureFor(c.v, dataset.JSONDataFormat)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
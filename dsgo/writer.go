@@ -0,0 +1,93 @@
+package dsgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// StructWriter encodes structs as body entries. For writers with a tabular
+// structure, structs are written as array rows in column order, matching
+// columns to fields by json struct tag. Otherwise structs are written as
+// objects
+type StructWriter struct {
+	w      dsio.EntryWriter
+	titles []string
+	i      int
+}
+
+// NewStructWriter wraps an entry writer. Use StructureFor to create a
+// structure that matches a struct type
+func NewStructWriter(w dsio.EntryWriter) *StructWriter {
+	sw := &StructWriter{w: w}
+	if st := w.Structure(); st != nil {
+		if items, ok := st.Schema["items"].(map[string]interface{}); ok && items["type"] == "array" {
+			if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+				sw.titles = cols.Titles()
+			}
+		}
+	}
+	return sw
+}
+
+// Write encodes v as the next entry of the body. v is converted as
+// encoding/json would marshal it, with integers kept as int64 values
+func (w *StructWriter) Write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding entry %d: %w", w.i, err)
+	}
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return fmt.Errorf("encoding entry %d: values must encode as objects", w.i)
+	}
+	numbers(obj)
+
+	var val interface{} = obj
+	if w.titles != nil {
+		row := make([]interface{}, len(w.titles))
+		for i, title := range w.titles {
+			row[i] = obj[title]
+		}
+		val = row
+	}
+
+	if err := w.w.WriteEntry(dsio.Entry{Index: w.i, Value: val}); err != nil {
+		return err
+	}
+	w.i++
+	return nil
+}
+
+// numbers replaces the json.Number values of a decoded value in place with
+// int64 values for integers, float64 values otherwise. Decoding straight to
+// float64 would lose the precision of integers above 2^53
+func numbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		return f
+	case map[string]interface{}:
+		for key, val := range x {
+			x[key] = numbers(val)
+		}
+	case []interface{}:
+		for i, val := range x {
+			x[i] = numbers(val)
+		}
+	}
+	return v
+}
+
+// Close closes the wrapped entry writer
+func (w *StructWriter) Close() error {
+	return w.w.Close()
+}
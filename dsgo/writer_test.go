@@ -0,0 +1,105 @@
+package dsgo
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestStructWriter(t *testing.T) {
+	cities := []city{
+		{City: "toronto", Pop: 40000000, AvgAge: 55.5, InUsa: false},
+		{City: "new york", Pop: 8500000, AvgAge: 44.4, InUsa: true},
+	}
+
+	for _, format := range []dataset.DataFormat{dataset.CSVDataFormat, dataset.JSONDataFormat} {
+		st, err := StructureFor(cities, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		w, err := dsio.NewEntryWriter(st, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sw := NewStructWriter(w)
+		for _, c := range cities {
+			if err := sw.Write(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := sw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := dsio.NewEntryReader(st, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sr := NewStructReader(r)
+		var got []city
+		for {
+			c := city{}
+			if err := sr.Read(&c); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %s", format, err)
+			}
+			got = append(got, c)
+		}
+		if !reflect.DeepEqual(cities, got) {
+			t.Errorf("%s round trip mismatch. expected: %v, got: %v", format, cities, got)
+		}
+	}
+}
+
+func TestStructWriterObjects(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	buf := &bytes.Buffer{}
+	w, err := dsio.NewJSONWriter(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := NewStructWriter(w)
+	if err := sw.Write(city{City: "a", Pop: 2}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "encoding entry 1: values must encode as objects"
+	if err := sw.Write(5); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[{"avg_age":0,"city":"a","in_usa":false,"pop":2}]`; buf.String() != expect {
+		t.Errorf("output mismatch. expected: %s, got: %s", expect, buf.String())
+	}
+}
+
+func TestStructWriterIntegers(t *testing.T) {
+	type reading struct {
+		ID     int64   `json:"id"`
+		Counts []int64 `json:"counts"`
+		Value  float64 `json:"value"`
+	}
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	buf := &bytes.Buffer{}
+	w, err := dsio.NewJSONWriter(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := NewStructWriter(w)
+	if err := sw.Write(reading{ID: 1<<53 + 1, Counts: []int64{1<<62 + 1}, Value: 2.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[{"counts":[4611686018427387905],"id":9007199254740993,"value":2.5}]`; buf.String() != expect {
+		t.Errorf("output mismatch. expected: %s, got: %s", expect, buf.String())
+	}
+}
@@ -1,6 +1,11 @@
 // Package dsgraph is a placeholder package for linking
 // queries, resources, and metadata until proper
-// packaging & architectural decisions can be made
+// packaging & architectural decisions can be made.
+// Index records relationships between datasets: previous versions,
+// transform resources & shared structures, for impact analysis.
+// SearchIndex is an inverted index of dataset metadata for finding datasets
+// in a store without loading every version, built from a list of paths or
+// by walking a store with WalkSearchIndex
 package dsgraph
 
 import (
@@ -0,0 +1,311 @@
+package dsgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/qri-io/dataset"
+)
+
+// NtURL is a URL a transform reads from
+var NtURL = NodeType("url")
+
+// EdgeType is the kind of relationship an index edge records
+type EdgeType string
+
+var (
+	// EtPrevious links a dataset version to the version before it
+	EtPrevious = EdgeType("previous")
+	// EtResource links a dataset to a dataset or URL its transform reads
+	EtResource = EdgeType("resource")
+	// EtStructure links a dataset to its structure. Datasets that link to
+	// the same structure share it
+	EtStructure = EdgeType("structure")
+)
+
+// Edge is a directional relationship between two indexed nodes
+type Edge struct {
+	Type EdgeType `json:"type"`
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	// Name is the transform's name for a resource edge
+	Name string `json:"name,omitempty"`
+}
+
+// Index is a graph of datasets & their relationships, keyed by path.
+// Structures are keyed by path when they have one, and by hash otherwise.
+// Index isn't safe for concurrent use
+type Index struct {
+	nodes map[string]NodeType
+	edges []Edge
+	out   map[string][]Edge
+	in    map[string][]Edge
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		nodes: map[string]NodeType{},
+		out:   map[string][]Edge{},
+		in:    map[string][]Edge{},
+	}
+}
+
+// BuildIndex creates an index of the datasets at paths, loading each with
+// load. Previous versions & dataset resources are followed and indexed as
+// well. Datasets that load reports as dataset.ErrNotFound are kept as nodes
+// without any edges of their own
+func BuildIndex(ctx context.Context, load dataset.DatasetLoader, paths ...string) (*Index, error) {
+	if load == nil {
+		return nil, dataset.ErrNoResolver
+	}
+	idx := NewIndex()
+	visited := map[string]bool{}
+	queue := append([]string{}, paths...)
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if path == "" || visited[path] {
+			continue
+		}
+		visited[path] = true
+
+		ds, err := load(ctx, path)
+		if errors.Is(err, dataset.ErrNotFound) {
+			idx.addNode(path, NtDataset)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", path, err)
+		}
+		if ds == nil {
+			idx.addNode(path, NtDataset)
+			continue
+		}
+		if ds.Path == "" {
+			ds = ds.Clone()
+			ds.Path = path
+		}
+		if err := idx.AddDataset(ds); err != nil {
+			return nil, err
+		}
+		for _, e := range idx.out[ds.Path] {
+			if idx.nodes[e.To] == NtDataset {
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return idx, nil
+}
+
+// AddDataset adds a dataset and its relationships to the index. The dataset
+// must have a path
+func (idx *Index) AddDataset(ds *dataset.Dataset) error {
+	if ds == nil || ds.Path == "" {
+		return fmt.Errorf("indexing a dataset requires a path")
+	}
+	idx.addNode(ds.Path, NtDataset)
+
+	if ds.PreviousPath != "" {
+		idx.addNode(ds.PreviousPath, NtDataset)
+		idx.addEdge(Edge{Type: EtPrevious, From: ds.Path, To: ds.PreviousPath})
+	}
+
+	if ds.Structure != nil {
+		id := ds.Structure.Path
+		if id == "" {
+			st := ds.Structure.Clone()
+			st.DropTransientValues()
+			hash, err := st.Hash()
+			if err != nil {
+				return fmt.Errorf("hashing structure of %s: %w", ds.Path, err)
+			}
+			id = hash
+		}
+		idx.addNode(id, NtStructure)
+		idx.addEdge(Edge{Type: EtStructure, From: ds.Path, To: id})
+	}
+
+	if ds.Transform != nil {
+		names := make([]string, 0, len(ds.Transform.Resources))
+		for name := range ds.Transform.Resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r := ds.Transform.Resources[name]
+			if r == nil || r.ResolvedPath() == "" {
+				continue
+			}
+			nt := NtDataset
+			if r.IsURL() {
+				nt = NtURL
+			}
+			idx.addNode(r.ResolvedPath(), nt)
+			idx.addEdge(Edge{Type: EtResource, From: ds.Path, To: r.ResolvedPath(), Name: name})
+		}
+	}
+	return nil
+}
+
+func (idx *Index) addNode(id string, nt NodeType) {
+	if _, ok := idx.nodes[id]; !ok {
+		idx.nodes[id] = nt
+	}
+}
+
+func (idx *Index) addEdge(e Edge) {
+	for _, x := range idx.out[e.From] {
+		if x == e {
+			return
+		}
+	}
+	idx.edges = append(idx.edges, e)
+	idx.out[e.From] = append(idx.out[e.From], e)
+	idx.in[e.To] = append(idx.in[e.To], e)
+}
+
+// NodeType gives the type of an indexed node, and false if the node isn't in
+// the index
+func (idx *Index) NodeType(id string) (NodeType, bool) {
+	nt, ok := idx.nodes[id]
+	return nt, ok
+}
+
+// Nodes lists the ids of all indexed nodes in sorted order
+func (idx *Index) Nodes() []string {
+	ids := make([]string, 0, len(idx.nodes))
+	for id := range idx.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Edges lists all edges in the order they were added
+func (idx *Index) Edges() []Edge {
+	return append([]Edge{}, idx.edges...)
+}
+
+// Dependencies lists the datasets & URLs the transform of path reads
+func (idx *Index) Dependencies(path string) []string {
+	return edgeEnds(idx.out[path], EtResource, func(e Edge) string { return e.To })
+}
+
+// Dependents lists the datasets whose transforms read id directly
+func (idx *Index) Dependents(id string) []string {
+	return edgeEnds(idx.in[id], EtResource, func(e Edge) string { return e.From })
+}
+
+// Impacted lists every dataset that depends on id or an earlier version of
+// id, directly or through other datasets, in sorted order. A new version of
+// id may change the output of any impacted dataset's transform
+func (idx *Index) Impacted(id string) []string {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	var impacted []string
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dep := range idx.Dependents(cur) {
+			if !seen[dep] {
+				seen[dep] = true
+				impacted = append(impacted, dep)
+				queue = append(queue, dep)
+			}
+		}
+		// datasets that read an earlier version are also out of date
+		for _, prev := range edgeEnds(idx.out[cur], EtPrevious, func(e Edge) string { return e.To }) {
+			if !seen[prev] {
+				seen[prev] = true
+				queue = append(queue, prev)
+			}
+		}
+	}
+	sort.Strings(impacted)
+	return impacted
+}
+
+// History lists the previous versions of path, most recent first. History
+// stops at the first version that isn't indexed with a previous version
+func (idx *Index) History(path string) []string {
+	var hist []string
+	seen := map[string]bool{path: true}
+	for {
+		prev := edgeEnds(idx.out[path], EtPrevious, func(e Edge) string { return e.To })
+		if len(prev) == 0 || seen[prev[0]] {
+			return hist
+		}
+		path = prev[0]
+		seen[path] = true
+		hist = append(hist, path)
+	}
+}
+
+// SharedStructure lists other datasets with the same structure as path
+func (idx *Index) SharedStructure(path string) []string {
+	var shared []string
+	for _, st := range edgeEnds(idx.out[path], EtStructure, func(e Edge) string { return e.To }) {
+		for _, ds := range edgeEnds(idx.in[st], EtStructure, func(e Edge) string { return e.From }) {
+			if ds != path {
+				shared = append(shared, ds)
+			}
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+func edgeEnds(edges []Edge, et EdgeType, end func(Edge) string) []string {
+	var ids []string
+	for _, e := range edges {
+		if e.Type == et {
+			ids = append(ids, end(e))
+		}
+	}
+	return ids
+}
+
+// indexNode is the JSON encoding of an index node
+type indexNode struct {
+	ID   string   `json:"id"`
+	Type NodeType `json:"type"`
+}
+
+// MarshalJSON encodes the index as lists of nodes & edges
+func (idx *Index) MarshalJSON() ([]byte, error) {
+	nodes := []indexNode{}
+	for _, id := range idx.Nodes() {
+		nodes = append(nodes, indexNode{ID: id, Type: idx.nodes[id]})
+	}
+	return json.Marshal(struct {
+		Nodes []indexNode `json:"nodes"`
+		Edges []Edge      `json:"edges"`
+	}{nodes, idx.Edges()})
+}
+
+// WriteDOT writes the index as a graphviz DOT digraph
+func (idx *Index) WriteDOT(w io.Writer) error {
+	if _, err := io.WriteString(w, "digraph datasets {\n"); err != nil {
+		return err
+	}
+	for _, id := range idx.Nodes() {
+		if _, err := fmt.Fprintf(w, "  %q [type=%q];\n", id, idx.nodes[id]); err != nil {
+			return err
+		}
+	}
+	for _, e := range idx.edges {
+		label := string(e.Type)
+		if e.Name != "" {
+			label += ":" + e.Name
+		}
+		if _, err := fmt.Fprintf(w, "  %q -> %q [label=%q];\n", e.From, e.To, label); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
@@ -0,0 +1,138 @@
+package dsgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func testLoader(dss ...*dataset.Dataset) dataset.DatasetLoader {
+	store := map[string]*dataset.Dataset{}
+	for _, ds := range dss {
+		store[ds.Path] = ds
+	}
+	return func(ctx context.Context, path string) (*dataset.Dataset, error) {
+		ds, ok := store[path]
+		if !ok {
+			return nil, dataset.ErrNotFound
+		}
+		return ds, nil
+	}
+}
+
+func testIndex(t *testing.T) *Index {
+	st := &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray}
+	feedV1 := &dataset.Dataset{Path: "/mem/feed1", Structure: st}
+	feedV2 := &dataset.Dataset{Path: "/mem/feed2", PreviousPath: "/mem/feed1", Structure: st}
+	stops := &dataset.Dataset{
+		Path:      "/mem/stops",
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject},
+		Transform: &dataset.Transform{Resources: map[string]*dataset.TransformResource{
+			"feed":    {Path: "me/feed", Version: "/mem/feed1"},
+			"weather": {Path: "https://example.com/weather.csv"},
+		}},
+	}
+	report := &dataset.Dataset{
+		Path: "/mem/report",
+		Transform: &dataset.Transform{Resources: map[string]*dataset.TransformResource{
+			"stops": {Path: "/mem/stops"},
+		}},
+	}
+
+	idx, err := BuildIndex(context.Background(), testLoader(feedV1, feedV2, stops, report), "/mem/feed2", "/mem/report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestBuildIndex(t *testing.T) {
+	idx := testIndex(t)
+
+	expect := []string{"/mem/feed1", "/mem/feed2", "/mem/report", "/mem/stops", "https://example.com/weather.csv"}
+	var got []string
+	for _, id := range idx.Nodes() {
+		if nt, _ := idx.NodeType(id); nt != NtStructure {
+			got = append(got, id)
+		}
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("nodes mismatch. expected: %v, got: %v", expect, got)
+	}
+	if nt, _ := idx.NodeType("https://example.com/weather.csv"); nt != NtURL {
+		t.Errorf("expected url node type. got: %q", nt)
+	}
+
+	if _, err := BuildIndex(context.Background(), nil); err != dataset.ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+	missing, err := BuildIndex(context.Background(), testLoader(), "/mem/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := missing.NodeType("/mem/missing"); !ok {
+		t.Errorf("expected missing datasets to be indexed")
+	}
+}
+
+func TestIndexQueries(t *testing.T) {
+	idx := testIndex(t)
+
+	cases := []struct {
+		description string
+		got, expect []string
+	}{
+		{"dependencies", idx.Dependencies("/mem/stops"), []string{"/mem/feed1", "https://example.com/weather.csv"}},
+		{"dependents", idx.Dependents("/mem/feed1"), []string{"/mem/stops"}},
+		{"impacted by earlier version", idx.Impacted("/mem/feed2"), []string{"/mem/report", "/mem/stops"}},
+		{"impacted by url", idx.Impacted("https://example.com/weather.csv"), []string{"/mem/report", "/mem/stops"}},
+		{"not impacted", idx.Impacted("/mem/report"), nil},
+		{"history", idx.History("/mem/feed2"), []string{"/mem/feed1"}},
+		{"shared structure", idx.SharedStructure("/mem/feed2"), []string{"/mem/feed1"}},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.expect, c.got) {
+			t.Errorf("%s mismatch. expected: %v, got: %v", c.description, c.expect, c.got)
+		}
+	}
+}
+
+func TestIndexExport(t *testing.T) {
+	idx := NewIndex()
+	if err := idx.AddDataset(&dataset.Dataset{Path: "/mem/b", PreviousPath: "/mem/a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDataset(&dataset.Dataset{}); err == nil {
+		t.Errorf("expected datasets without a path to error")
+	}
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"nodes":[{"id":"/mem/a","type":"dataset"},{"id":"/mem/b","type":"dataset"}],"edges":[{"type":"previous","from":"/mem/b","to":"/mem/a"}]}`
+	if expect != string(data) {
+		t.Errorf("json mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := idx.WriteDOT(buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := strings.Join([]string{
+		`digraph datasets {`,
+		`  "/mem/a" [type="dataset"];`,
+		`  "/mem/b" [type="dataset"];`,
+		`  "/mem/b" -> "/mem/a" [label="previous"];`,
+		`}`,
+		``,
+	}, "\n")
+	if dot != buf.String() {
+		t.Errorf("dot mismatch.\nwant: %s\ngot:  %s", dot, buf.String())
+	}
+}
@@ -0,0 +1,370 @@
+package dsgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qfs/cafs"
+)
+
+// SearchField is a dataset metadata field the search index records
+type SearchField string
+
+const (
+	// SfTitle is the meta title
+	SfTitle SearchField = "title"
+	// SfDescription is the meta description
+	SfDescription SearchField = "description"
+	// SfKeyword is a meta keyword
+	SfKeyword SearchField = "keyword"
+	// SfTheme is a free-text meta theme, or the label, title or code of a
+	// vocabulary theme
+	SfTheme SearchField = "theme"
+	// SfColumn is the title of a tabular schema column
+	SfColumn SearchField = "column"
+)
+
+// searchFields lists the indexed fields, with the weight a term match in each
+// field adds to a result score
+var searchFields = map[SearchField]int{
+	SfTitle:       3,
+	SfKeyword:     2,
+	SfTheme:       2,
+	SfColumn:      1,
+	SfDescription: 1,
+}
+
+// SearchQuery selects datasets from a search index. Text & field values are
+// split into terms the same way indexed fields are, and a dataset matches
+// when every text term is in any field & every field term is in that field
+type SearchQuery struct {
+	// Text is free text matched against all fields
+	Text string
+	// Fields restricts terms to a single field
+	Fields map[SearchField]string
+	// Limit caps the number of results, zero returns all results
+	Limit int
+}
+
+// ParseSearchQuery reads a query string where "field:term" words filter on a
+// field, eg. "stops theme:transport column:stop_id". Other words are text
+func ParseSearchQuery(s string) (SearchQuery, error) {
+	q := SearchQuery{}
+	var text []string
+	for _, word := range strings.Fields(s) {
+		i := strings.IndexByte(word, ':')
+		if i <= 0 {
+			text = append(text, word)
+			continue
+		}
+		f := SearchField(strings.ToLower(word[:i]))
+		if _, ok := searchFields[f]; !ok {
+			return q, fmt.Errorf("unknown search field '%s'", word[:i])
+		}
+		if q.Fields == nil {
+			q.Fields = map[SearchField]string{}
+		}
+		q.Fields[f] = strings.TrimSpace(q.Fields[f] + " " + word[i+1:])
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+// SearchResult is a dataset that matches a search query
+type SearchResult struct {
+	Path  string `json:"path"`
+	Title string `json:"title,omitempty"`
+	// Score ranks results, higher scores are better matches
+	Score int `json:"score"`
+}
+
+// SearchIndex is an inverted index of dataset metadata, keyed by path.
+// Only dataset documents are read, bodies are never loaded. SearchIndex isn't
+// safe for concurrent use
+type SearchIndex struct {
+	// postings maps fields to terms to the number of times the term appears
+	// in the field of each dataset
+	postings map[SearchField]map[string]map[string]int
+	// terms records the indexed terms of each dataset, for removal
+	terms  map[string]map[SearchField][]string
+	titles map[string]string
+}
+
+// NewSearchIndex creates an empty search index
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		postings: map[SearchField]map[string]map[string]int{},
+		terms:    map[string]map[SearchField][]string{},
+		titles:   map[string]string{},
+	}
+}
+
+// BuildSearchIndex creates a search index of the datasets at paths, loading
+// each with load. Unlike BuildIndex, previous versions & resources aren't
+// followed, so paths are usually the latest version of every dataset in a
+// store. Paths that load reports as dataset.ErrNotFound are skipped
+func BuildSearchIndex(ctx context.Context, load dataset.DatasetLoader, paths ...string) (*SearchIndex, error) {
+	if load == nil {
+		return nil, dataset.ErrNoResolver
+	}
+	idx := NewSearchIndex()
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		ds, err := load(ctx, p)
+		if errors.Is(err, dataset.ErrNotFound) || err == nil && ds == nil {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", p, err)
+		}
+		if ds.Path == "" {
+			ds = ds.Clone()
+			ds.Path = p
+		}
+		if err := idx.AddDataset(ds); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+// WalkSearchIndex creates a search index of the latest version of every
+// dataset in a store. The store must implement dsfs.Lister: every listed file
+// is read, and files that are dataset documents are datasets. Datasets that
+// another dataset lists as it's previous version aren't indexed, the rest are
+// loaded with load & indexed as BuildSearchIndex does
+func WalkSearchIndex(ctx context.Context, store cafs.Filestore, load dataset.DatasetLoader) (*SearchIndex, error) {
+	if store == nil {
+		return nil, dataset.ErrNoResolver
+	}
+	lister, ok := store.(dsfs.Lister)
+	if !ok {
+		return nil, fmt.Errorf("walking store: %s store can't list files", store.PathPrefix())
+	}
+	paths, err := lister.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("walking store: %w", err)
+	}
+	var datasets []string
+	previous := map[string]bool{}
+	for _, p := range paths {
+		doc, err := readDatasetDoc(ctx, store, p)
+		if err != nil {
+			return nil, fmt.Errorf("walking store: %w", err)
+		}
+		if doc == nil {
+			continue
+		}
+		datasets = append(datasets, p)
+		if doc.PreviousPath != "" {
+			previous[doc.PreviousPath] = true
+		}
+	}
+	heads := datasets[:0]
+	for _, p := range datasets {
+		if !previous[p] {
+			heads = append(heads, p)
+		}
+	}
+	return BuildSearchIndex(ctx, load, heads...)
+}
+
+// datasetDoc holds the fields of a stored dataset document WalkSearchIndex
+// reads
+type datasetDoc struct {
+	Qri          string `json:"qri"`
+	PreviousPath string `json:"previousPath"`
+}
+
+// readDatasetDoc reads a stored file, returning nil if it isn't a dataset
+// document
+func readDatasetDoc(ctx context.Context, store cafs.Filestore, path string) (*datasetDoc, error) {
+	f, err := store.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+	if f.IsDirectory() {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, nil
+	}
+	doc := &datasetDoc{}
+	if err := json.Unmarshal(data, doc); err != nil || dataset.Kind(doc.Qri).Type() != dataset.KindDataset.Type() {
+		return nil, nil
+	}
+	return doc, nil
+}
+
+// AddDataset indexes the metadata of a dataset, replacing any earlier entry
+// for the same path. The dataset must have a path
+func (idx *SearchIndex) AddDataset(ds *dataset.Dataset) error {
+	if ds == nil || ds.Path == "" {
+		return fmt.Errorf("indexing a dataset requires a path")
+	}
+	idx.Remove(ds.Path)
+
+	fields := map[SearchField][]string{}
+	if md := ds.Meta; md != nil {
+		idx.titles[ds.Path] = md.Title
+		fields[SfTitle] = searchTerms(md.Title)
+		fields[SfDescription] = searchTerms(md.Description)
+		for _, kw := range md.Keywords {
+			fields[SfKeyword] = append(fields[SfKeyword], searchTerms(kw)...)
+		}
+		for _, th := range md.Theme {
+			fields[SfTheme] = append(fields[SfTheme], searchTerms(th)...)
+		}
+		for _, th := range md.Themes {
+			if th == nil {
+				continue
+			}
+			for _, s := range []string{th.Label, th.Title, th.DisplayName, th.Name} {
+				fields[SfTheme] = append(fields[SfTheme], searchTerms(s)...)
+			}
+			if th.URI != "" {
+				fields[SfTheme] = append(fields[SfTheme], searchTerms(path.Base(th.URI))...)
+			}
+		}
+	}
+	if ds.Structure != nil {
+		if cols, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema); err == nil {
+			for _, title := range cols.Titles() {
+				fields[SfColumn] = append(fields[SfColumn], searchTerms(title)...)
+			}
+		}
+	}
+
+	indexed := map[SearchField][]string{}
+	for f, terms := range fields {
+		if len(terms) == 0 {
+			continue
+		}
+		indexed[f] = terms
+		if idx.postings[f] == nil {
+			idx.postings[f] = map[string]map[string]int{}
+		}
+		for _, term := range terms {
+			if idx.postings[f][term] == nil {
+				idx.postings[f][term] = map[string]int{}
+			}
+			idx.postings[f][term][ds.Path]++
+		}
+	}
+	idx.terms[ds.Path] = indexed
+	return nil
+}
+
+// Remove drops a dataset from the index
+func (idx *SearchIndex) Remove(path string) {
+	for f, terms := range idx.terms[path] {
+		for _, term := range terms {
+			delete(idx.postings[f][term], path)
+			if len(idx.postings[f][term]) == 0 {
+				delete(idx.postings[f], term)
+			}
+		}
+	}
+	delete(idx.terms, path)
+	delete(idx.titles, path)
+}
+
+// Paths lists the paths of all indexed datasets in sorted order
+func (idx *SearchIndex) Paths() []string {
+	paths := make([]string, 0, len(idx.terms))
+	for p := range idx.terms {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Search lists the datasets that match a query, best match first. Results
+// with equal scores are sorted by path. A query without any terms matches
+// nothing
+func (idx *SearchIndex) Search(q SearchQuery) ([]SearchResult, error) {
+	type clause struct {
+		fields []SearchField
+		term   string
+	}
+	var clauses []clause
+	all := make([]SearchField, 0, len(searchFields))
+	for f := range searchFields {
+		all = append(all, f)
+	}
+	for _, term := range searchTerms(q.Text) {
+		clauses = append(clauses, clause{all, term})
+	}
+	for f, text := range q.Fields {
+		if _, ok := searchFields[f]; !ok {
+			return nil, fmt.Errorf("unknown search field '%s'", f)
+		}
+		for _, term := range searchTerms(text) {
+			clauses = append(clauses, clause{[]SearchField{f}, term})
+		}
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+
+	var scores map[string]int
+	for _, c := range clauses {
+		matched := map[string]int{}
+		for _, f := range c.fields {
+			for p, n := range idx.postings[f][c.term] {
+				matched[p] += n * searchFields[f]
+			}
+		}
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for p := range scores {
+			if n, ok := matched[p]; ok {
+				scores[p] += n
+			} else {
+				delete(scores, p)
+			}
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for p, score := range scores {
+		results = append(results, SearchResult{Path: p, Title: idx.titles[p], Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+// searchTerms splits text into lower case runs of letters & digits, so
+// "Stop_ID" & "stop id" have the same terms
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,188 @@
+package dsgraph
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+func testSearchIndex(t *testing.T) *SearchIndex {
+	stops := &dataset.Dataset{
+		Path: "/mem/stops",
+		Meta: &dataset.Meta{
+			Title:       "VVS Stops",
+			Description: "Stops of the Stuttgart public transport network",
+			Keywords:    []string{"gtfs", "public transport"},
+			Themes:      []*dataset.Theme{{URI: "http://publications.europa.eu/resource/authority/data-theme/TRAN", Label: "Transport"}},
+		},
+		Structure: &dataset.Structure{Format: "csv", Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "stop_id", "type": "string"},
+					map[string]interface{}{"title": "stop_name", "type": "string"},
+				},
+			},
+		}},
+	}
+	weather := &dataset.Dataset{
+		Path: "/mem/weather",
+		Meta: &dataset.Meta{
+			Title:       "Weather",
+			Description: "Hourly weather at transport stops",
+			Theme:       []string{"environment"},
+		},
+	}
+	parking := &dataset.Dataset{
+		Path: "/mem/parking",
+		Meta: &dataset.Meta{Title: "Park & ride", Keywords: []string{"transport"}},
+	}
+
+	idx, err := BuildSearchIndex(context.Background(), testLoader(stops, weather, parking), "/mem/stops", "/mem/weather", "/mem/parking", "/mem/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestBuildSearchIndex(t *testing.T) {
+	idx := testSearchIndex(t)
+	expect := []string{"/mem/parking", "/mem/stops", "/mem/weather"}
+	if got := idx.Paths(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("paths mismatch. expected: %v, got: %v", expect, got)
+	}
+
+	if _, err := BuildSearchIndex(context.Background(), nil); err != dataset.ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
+
+func TestWalkSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	store := dsfs.NewMapStore()
+	stored := map[string]*dataset.Dataset{}
+	write := func(ds *dataset.Dataset) string {
+		ds.BodyBytes = []byte("stop_id\n1\n")
+		ds.Structure = &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray}
+		path, err := dsfs.WriteDataset(ctx, store, ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored[path] = ds
+		return path
+	}
+	v1 := write(&dataset.Dataset{Meta: &dataset.Meta{Title: "VVS Stops draft"}})
+	v2 := write(&dataset.Dataset{PreviousPath: v1, Meta: &dataset.Meta{Title: "VVS Stops"}})
+	parking := write(&dataset.Dataset{Meta: &dataset.Meta{Title: "Park & ride"}})
+	load := func(ctx context.Context, path string) (*dataset.Dataset, error) {
+		if ds, ok := stored[path]; ok {
+			return ds, nil
+		}
+		return nil, dataset.ErrNotFound
+	}
+
+	idx, err := WalkSearchIndex(ctx, store, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{v2, parking}
+	sort.Strings(expect)
+	if got := idx.Paths(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected the latest versions %v, got: %v", expect, got)
+	}
+
+	if _, err := WalkSearchIndex(ctx, cafs.NewMapstore(), load); err == nil || err.Error() != "walking store: map store can't list files" {
+		t.Errorf("expected an unlistable store error, got: %v", err)
+	}
+}
+
+func TestSearchIndexSearch(t *testing.T) {
+	idx := testSearchIndex(t)
+
+	cases := []struct {
+		query  string
+		expect []string
+	}{
+		{"", nil},
+		{"transport", []string{"/mem/stops", "/mem/parking", "/mem/weather"}},
+		{"Transport STOPS", []string{"/mem/stops", "/mem/weather"}},
+		{"keyword:transport", []string{"/mem/parking", "/mem/stops"}},
+		{"theme:tran", []string{"/mem/stops"}},
+		{"theme:environment", []string{"/mem/weather"}},
+		{"column:stop_id", []string{"/mem/stops"}},
+		{"weather column:stop_id", []string{}},
+		{"bicycles", []string{}},
+	}
+
+	for i, c := range cases {
+		q, err := ParseSearchQuery(c.query)
+		if err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		res, err := idx.Search(q)
+		if err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		var got []string
+		if res != nil {
+			got = []string{}
+		}
+		for _, r := range res {
+			got = append(got, r.Path)
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d %q: expected: %v, got: %v", i, c.query, c.expect, got)
+		}
+	}
+
+	res, err := idx.Search(SearchQuery{Text: "transport", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Title != "VVS Stops" {
+		t.Errorf("expected the top result titled 'VVS Stops', got: %v", res)
+	}
+
+	if _, err := idx.Search(SearchQuery{Fields: map[SearchField]string{"publisher": "vvs"}}); err == nil || err.Error() != "unknown search field 'publisher'" {
+		t.Errorf("expected an unknown field error, got: %v", err)
+	}
+	if _, err := ParseSearchQuery("license:odbl"); err == nil || err.Error() != "unknown search field 'license'" {
+		t.Errorf("expected an unknown field error, got: %v", err)
+	}
+}
+
+func TestSearchIndexUpdate(t *testing.T) {
+	idx := testSearchIndex(t)
+
+	if err := idx.AddDataset(&dataset.Dataset{}); err == nil {
+		t.Error("expected datasets without a path to error")
+	}
+
+	// re-adding a path replaces the earlier entry
+	if err := idx.AddDataset(&dataset.Dataset{Path: "/mem/weather", Meta: &dataset.Meta{Title: "Rainfall"}}); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := idx.Search(SearchQuery{Text: "weather"})
+	if len(res) != 0 {
+		t.Errorf("expected replaced terms to be dropped, got: %v", res)
+	}
+	res, _ = idx.Search(SearchQuery{Text: "rainfall"})
+	if len(res) != 1 || res[0].Path != "/mem/weather" {
+		t.Errorf("expected a match for the new title, got: %v", res)
+	}
+
+	idx.Remove("/mem/stops")
+	res, _ = idx.Search(SearchQuery{Fields: map[SearchField]string{SfColumn: "stop"}})
+	if len(res) != 0 {
+		t.Errorf("expected removed datasets not to match, got: %v", res)
+	}
+	if len(idx.postings[SfColumn]) != 0 {
+		t.Errorf("expected empty postings to be dropped, got: %v", idx.postings[SfColumn])
+	}
+}
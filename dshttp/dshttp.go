@@ -0,0 +1,448 @@
+// Package dshttp defines http.Handler constructors that serve dataset
+// documents, bodies & stats from a store. Handlers load datasets by path
+// with a dataset.DatasetLoader and read bodies with a qfs.PathResolver, so
+// they work with any store that can provide both
+package dshttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/ipfs/go-log"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qfs"
+)
+
+var log = logger.Logger("dshttp")
+
+// PathFunc gives the path of the dataset a request is for, returning the
+// empty string if the request doesn't name a dataset
+type PathFunc func(r *http.Request) string
+
+// QueryPath reads dataset paths from a URL query parameter
+func QueryPath(param string) PathFunc {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(param)
+	}
+}
+
+// PrefixPath reads dataset paths from the URL path following prefix, so
+// a handler mounted at "/datasets" serves "/datasets/ipfs/QmHash" as the
+// dataset at "/ipfs/QmHash"
+func PrefixPath(prefix string) PathFunc {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(r *http.Request) string {
+		if !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			return ""
+		}
+		return strings.TrimPrefix(r.URL.Path, prefix)
+	}
+}
+
+// Source configures where handlers read datasets from
+type Source struct {
+	// Load gets a dataset document by path. required
+	Load dataset.DatasetLoader
+	// Resolver opens body files by path. required to serve bodies stored
+	// as files
+	Resolver qfs.PathResolver
+	// Path gives the dataset path of a request. required
+	Path PathFunc
+	// AccessLevel is the level handlers share datasets at, defaults to
+	// dataset.AccessPublic. Datasets with a more restrictive access level
+	// are refused with 403 Forbidden
+	AccessLevel string
+}
+
+// load gets the dataset a request is for, writing an error response &
+// returning nil if it can't
+func (s Source) load(w http.ResponseWriter, r *http.Request) *dataset.Dataset {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return nil
+	}
+	path := s.Path(r)
+	if path == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("dataset path is required"))
+		return nil
+	}
+	ds, err := s.Load(r.Context(), path)
+	if err == nil && ds == nil {
+		err = fmt.Errorf("%w: %s", dataset.ErrNotFound, path)
+	}
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return nil
+	}
+	level := s.AccessLevel
+	if level == "" {
+		level = dataset.AccessPublic
+	}
+	if err := ds.CheckAccess(level); err != nil {
+		writeError(w, statusFor(err), err)
+		return nil
+	}
+	return ds
+}
+
+// DatasetHandler serves the JSON document of a dataset without its body.
+// Dataset paths are content addresses, so the path doubles as an ETag
+func DatasetHandler(s Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ds := s.load(w, r)
+		if ds == nil {
+			return
+		}
+		doc := ds.Clone()
+		doc.Body = nil
+		doc.BodyBytes = nil
+		if notModified(w, r, ds.Path) {
+			return
+		}
+		writeJSON(w, r, doc)
+	})
+}
+
+// StatsFunc gives the stats of a dataset, encoded as JSON
+type StatsFunc func(ctx context.Context, ds *dataset.Dataset) (interface{}, error)
+
+// StatsHandler serves dataset stats provided by stats as JSON
+func StatsHandler(s Source, stats StatsFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ds := s.load(w, r)
+		if ds == nil {
+			return
+		}
+		sts, err := stats(r.Context(), ds)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		if notModified(w, r, ds.Path) {
+			return
+		}
+		writeJSON(w, r, sts)
+	})
+}
+
+// BodyHandler serves dataset bodies. The response format is negotiated
+// from the Accept header or set with a "format" query parameter, one of
+// json, csv, cbor or xlsx. csv & xlsx require a tabular schema.
+// "offset" & "limit" query parameters page through entries. Requests for
+// the stored format without paging are served as raw bytes, with support
+// for Range requests
+func BodyHandler(s Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ds := s.load(w, r)
+		if ds == nil {
+			return
+		}
+		st := ds.Structure
+		if st == nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("dataset has no structure"))
+			return
+		}
+
+		offset, err := intParam(r, "offset", 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		limit, err := intParam(r, "limit", -1)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		format, err := negotiateFormat(r, st)
+		if err != nil {
+			writeError(w, http.StatusNotAcceptable, err)
+			return
+		}
+		w.Header().Set("Content-Type", mediaTypes[format])
+		w.Header().Add("Vary", "Accept")
+
+		if ds.Body != nil {
+			serveEntries(w, r, ds, inlineReader, format, offset, limit)
+			return
+		}
+		src, err := ds.BodySource(s.Resolver)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		if src == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("dataset has no body"))
+			return
+		}
+		f, err := src.Open(r.Context())
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		defer f.Close()
+
+		if format == st.DataFormat() && offset == 0 && limit < 0 && st.Compression == "" {
+			serveRaw(w, r, ds, f)
+			return
+		}
+		serveEntries(w, r, ds, func(ds *dataset.Dataset) (dsio.EntryReader, error) {
+			return dsio.NewEntryReader(ds.Structure, f)
+		}, format, offset, limit)
+	})
+}
+
+// serveRaw serves body bytes as stored. http.ServeContent handles Range &
+// conditional requests, which need a seekable body
+func serveRaw(w http.ResponseWriter, r *http.Request, ds *dataset.Dataset, f qfs.File) {
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		rs = bytes.NewReader(data)
+	}
+	if ds.Path != "" {
+		w.Header().Set("ETag", strconv.Quote(ds.Path))
+	}
+	var modTime time.Time
+	if ds.Commit != nil {
+		modTime = ds.Commit.Timestamp
+	}
+	http.ServeContent(w, r, f.FileName(), modTime, rs)
+}
+
+// serveEntries streams body entries in format. Entries are written to the
+// response as they're read, so responses have no Content-Length, and errors
+// once the body has started can only end the response early. HEAD requests
+// don't read the body
+func serveEntries(w http.ResponseWriter, r *http.Request, ds *dataset.Dataset, open func(*dataset.Dataset) (dsio.EntryReader, error), format dataset.DataFormat, offset, limit int) {
+	if notModified(w, r, ds.Path) {
+		return
+	}
+	out, err := dsio.ConvertStructure(ds.Structure, format)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	rdr, err := open(ds)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer rdr.Close()
+
+	rw := &responseWriter{w: w}
+	wr, err := dsio.NewEntryWriter(out, rw)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	paged := &dsio.PagedReader{Reader: rdr, Offset: offset, Limit: limit}
+	if err = dsio.Copy(paged, wr); err == nil {
+		err = wr.Close()
+	}
+	if err != nil {
+		if !rw.started {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		log.Debugf("writing body response: %s", err)
+	}
+}
+
+// responseWriter records whether a response body has been started
+type responseWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	rw.started = true
+	return rw.w.Write(p)
+}
+
+// inlineReader reads a body held in the dataset as native go types
+func inlineReader(ds *dataset.Dataset) (dsio.EntryReader, error) {
+	data, err := json.Marshal(ds.Body)
+	if err != nil {
+		return nil, err
+	}
+	st := &dataset.Structure{Format: dataset.JSONDataFormat.String(), Schema: ds.Structure.Schema}
+	return dsio.NewJSONReader(st, bytes.NewReader(data))
+}
+
+// mediaTypes maps the formats bodies can be served in to their media type
+var mediaTypes = map[dataset.DataFormat]string{
+	dataset.JSONDataFormat: "application/json",
+	dataset.CSVDataFormat:  "text/csv",
+	dataset.CBORDataFormat: "application/cbor",
+	dataset.XLSXDataFormat: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// negotiateFormat picks the format to serve a body in. An explicit format
+// query parameter wins. Otherwise the most preferred acceptable media type
+// is used, favouring the stored format for wildcards
+func negotiateFormat(r *http.Request, st *dataset.Structure) (dataset.DataFormat, error) {
+	_, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	isTabular := err == nil
+	servable := func(f dataset.DataFormat) bool {
+		if _, ok := mediaTypes[f]; !ok {
+			return false
+		}
+		return isTabular || (f != dataset.CSVDataFormat && f != dataset.XLSXDataFormat)
+	}
+
+	if name := r.URL.Query().Get("format"); name != "" {
+		f, err := dataset.ParseDataFormatString(name)
+		if err != nil || !servable(f) {
+			return dataset.UnknownDataFormat, fmt.Errorf("cannot serve body as %q", name)
+		}
+		return f, nil
+	}
+
+	preferred := st.DataFormat()
+	if !servable(preferred) {
+		preferred = dataset.JSONDataFormat
+	}
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return preferred, nil
+	}
+	formats := []dataset.DataFormat{preferred, dataset.JSONDataFormat, dataset.CSVDataFormat, dataset.CBORDataFormat, dataset.XLSXDataFormat}
+	for _, mt := range parseAccept(accept) {
+		for _, f := range formats {
+			if servable(f) && matchMediaType(mt, mediaTypes[f]) {
+				return f, nil
+			}
+		}
+	}
+	return dataset.UnknownDataFormat, fmt.Errorf("no acceptable body format. available formats are json, cbor, csv & xlsx")
+}
+
+// matchMediaType checks a media type against an accepted media type, which
+// may be a wildcard like "text/*"
+func matchMediaType(accepted, mediaType string) bool {
+	if accepted == "*/*" || accepted == mediaType {
+		return true
+	}
+	return strings.HasSuffix(accepted, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(accepted, "*"))
+}
+
+// parseAccept gives the media types of an Accept header ordered from most
+// to least preferred, dropping types with a quality of 0
+func parseAccept(header string) []string {
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+	var types []accepted
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			types = append(types, accepted{mt, q})
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return types[i].q > types[j].q })
+	mts := make([]string, len(types))
+	for i, t := range types {
+		mts[i] = t.mediaType
+	}
+	return mts
+}
+
+// intParam reads a non-negative integer query parameter
+func intParam(r *http.Request, name string, def int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid %s %q, must be a non-negative integer", name, s)
+	}
+	return i, nil
+}
+
+// notModified sets an ETag for path, responding 304 Not Modified if the
+// request already has a matching version
+func notModified(w http.ResponseWriter, r *http.Request, path string) bool {
+	if path == "" {
+		return false
+	}
+	etag := strconv.Quote(path)
+	w.Header().Set("ETag", etag)
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if tag = strings.TrimSpace(tag); tag == etag || tag == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(data); err != nil {
+		log.Debugf("writing response: %s", err)
+	}
+}
+
+// writeError responds with a JSON error message
+func writeError(w http.ResponseWriter, status int, err error) {
+	log.Debug(err.Error())
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	h := w.Header()
+	h.Del("ETag")
+	h.Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// statusFor maps errors to response codes
+func statusFor(err error) int {
+	if errors.Is(err, dataset.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, dataset.ErrAccessDenied) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,213 @@
+package dshttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+type memResolver map[string]string
+
+func (m memResolver) Get(ctx context.Context, path string) (qfs.File, error) {
+	data, ok := m[path]
+	if !ok {
+		return nil, dataset.ErrNotFound
+	}
+	return qfs.NewMemfileBytes(path, []byte(data)), nil
+}
+
+func testSource() Source {
+	schema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "stop_id", "type": "string"},
+				map[string]interface{}{"title": "capacity", "type": "integer"},
+			},
+		},
+	}
+	datasets := map[string]*dataset.Dataset{
+		"/mem/stops": {
+			Path:      "/mem/stops",
+			Commit:    &dataset.Commit{Title: "initial commit", Timestamp: time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC)},
+			Meta:      &dataset.Meta{Title: "stops"},
+			BodyPath:  "/mem/body.csv",
+			Structure: &dataset.Structure{Format: "csv", FormatConfig: map[string]interface{}{"headerRow": true}, Schema: schema},
+		},
+		"/mem/restricted": {
+			Path:      "/mem/restricted",
+			Meta:      &dataset.Meta{Title: "restricted", AccessLevel: dataset.AccessRestricted},
+			BodyPath:  "/mem/body.csv",
+			Structure: &dataset.Structure{Format: "csv", FormatConfig: map[string]interface{}{"headerRow": true}, Schema: schema},
+		},
+		"/mem/inline": {
+			Body:      []interface{}{map[string]interface{}{"a": 1}},
+			Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		},
+	}
+	return Source{
+		Load: func(ctx context.Context, path string) (*dataset.Dataset, error) {
+			return datasets[path], nil
+		},
+		Resolver: memResolver{"/mem/body.csv": "stop_id,capacity\na,1\nb,2\nc,3\n"},
+		Path:     QueryPath("path"),
+	}
+}
+
+func serve(h http.Handler, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestDatasetHandler(t *testing.T) {
+	h := DatasetHandler(testSource())
+
+	res := serve(h, "GET", "/?path=/mem/stops", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", res.Code, res.Body.String())
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(res.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["meta"].(map[string]interface{})["title"] != "stops" {
+		t.Errorf("expected dataset document, got: %s", res.Body.String())
+	}
+	etag := res.Header().Get("ETag")
+	if etag != `"/mem/stops"` {
+		t.Errorf("expected path ETag, got: %q", etag)
+	}
+
+	cases := []struct {
+		method, target string
+		header         map[string]string
+		status         int
+	}{
+		{"GET", "/?path=/mem/stops", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"HEAD", "/?path=/mem/stops", nil, http.StatusOK},
+		{"POST", "/?path=/mem/stops", nil, http.StatusMethodNotAllowed},
+		{"GET", "/?path=/mem/missing", nil, http.StatusNotFound},
+		{"GET", "/", nil, http.StatusNotFound},
+		{"GET", "/?path=/mem/restricted", nil, http.StatusForbidden},
+	}
+	for i, c := range cases {
+		if res := serve(h, c.method, c.target, c.header); res.Code != c.status {
+			t.Errorf("case %d: expected status %d, got %d: %s", i, c.status, res.Code, res.Body.String())
+		}
+	}
+}
+
+func TestSourceAccessLevel(t *testing.T) {
+	src := testSource()
+	src.AccessLevel = dataset.AccessRestricted
+	if res := serve(BodyHandler(src), "GET", "/?path=/mem/restricted", nil); res.Code != http.StatusOK {
+		t.Errorf("expected restricted sources to serve restricted datasets, got %d: %s", res.Code, res.Body.String())
+	}
+	src.AccessLevel = dataset.AccessInternal
+	if res := serve(BodyHandler(src), "GET", "/?path=/mem/restricted", nil); res.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d: %s", res.Code, res.Body.String())
+	}
+}
+
+func TestBodyHandler(t *testing.T) {
+	h := BodyHandler(testSource())
+
+	cases := []struct {
+		target      string
+		header      map[string]string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/?path=/mem/stops", nil, 200, "text/csv", "stop_id,capacity\na,1\nb,2\nc,3\n"},
+		{"/?path=/mem/stops", map[string]string{"Accept": "application/json"}, 200, "application/json", `[["a",1],["b",2],["c",3]]`},
+		{"/?path=/mem/stops&format=json&offset=1&limit=1", nil, 200, "application/json", `[["b",2]]`},
+		{"/?path=/mem/stops&limit=2", nil, 200, "text/csv", "stop_id,capacity\na,1\nb,2\n"},
+		{"/?path=/mem/stops", map[string]string{"Accept": "text/html, application/json;q=0.5, text/*;q=0.9"}, 200, "text/csv", "stop_id,capacity\na,1\nb,2\nc,3\n"},
+		{"/?path=/mem/stops", map[string]string{"Range": "bytes=0-6"}, 206, "text/csv", "stop_id"},
+		{"/?path=/mem/inline", map[string]string{"Accept": "*/*"}, 200, "application/json", `[{"a":1}]`},
+		{"/?path=/mem/inline&format=csv", nil, 406, "", ""},
+		{"/?path=/mem/stops", map[string]string{"Accept": "image/png"}, 406, "", ""},
+		{"/?path=/mem/stops&limit=a", nil, 400, "", ""},
+	}
+	for i, c := range cases {
+		res := serve(h, "GET", c.target, c.header)
+		if res.Code != c.status {
+			t.Errorf("case %d: expected status %d, got %d: %s", i, c.status, res.Code, res.Body.String())
+			continue
+		}
+		if c.contentType == "" {
+			continue
+		}
+		if got := res.Header().Get("Content-Type"); got != c.contentType {
+			t.Errorf("case %d: expected content type %q, got %q", i, c.contentType, got)
+		}
+		if got := res.Body.String(); got != c.body {
+			t.Errorf("case %d: body mismatch.\nexpected: %q\ngot:      %q", i, c.body, got)
+		}
+	}
+}
+
+func TestBodyHandlerStreamError(t *testing.T) {
+	src := testSource()
+	src.Load = func(ctx context.Context, path string) (*dataset.Dataset, error) {
+		return &dataset.Dataset{
+			BodyPath:  "/mem/broken.json",
+			Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		}, nil
+	}
+	src.Resolver = memResolver{"/mem/broken.json": `[["a",1],["b",`}
+
+	res := serve(BodyHandler(src), "GET", "/?path=/mem/broken&limit=10", nil)
+	if res.Code != http.StatusOK {
+		t.Errorf("expected the streamed status to stand, got %d", res.Code)
+	}
+	if got := res.Body.String(); !strings.HasPrefix(got, `[["a",1]`) || strings.HasSuffix(got, "]]") {
+		t.Errorf("expected a body ended early, got: %q", got)
+	}
+	if got := res.Header().Get("Content-Length"); got != "" {
+		t.Errorf("expected streamed bodies to have no content length, got: %q", got)
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	h := StatsHandler(testSource(), func(ctx context.Context, ds *dataset.Dataset) (interface{}, error) {
+		if ds.Meta == nil {
+			return nil, fmt.Errorf("%w: no stats", dataset.ErrNotFound)
+		}
+		return map[string]interface{}{"entries": 3}, nil
+	})
+	if res := serve(h, "GET", "/?path=/mem/stops", nil); res.Code != 200 || strings.TrimSpace(res.Body.String()) != `{"entries":3}` {
+		t.Errorf("stats mismatch. got %d: %s", res.Code, res.Body.String())
+	}
+	if res := serve(h, "GET", "/?path=/mem/inline", nil); res.Code != 404 {
+		t.Errorf("expected stats errors to map to status codes, got %d", res.Code)
+	}
+}
+
+func TestPrefixPath(t *testing.T) {
+	path := PrefixPath("/datasets/")
+	for target, expect := range map[string]string{
+		"/datasets/ipfs/QmHash": "/ipfs/QmHash",
+		"/datasets":             "",
+		"/other/ipfs/QmHash":    "",
+	} {
+		if got := path(httptest.NewRequest("GET", target, nil)); got != expect {
+			t.Errorf("%s: expected %q, got %q", target, expect, got)
+		}
+	}
+}
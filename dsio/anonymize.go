@@ -0,0 +1,373 @@
+package dsio
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+// NewAnonymizeReader wraps r, applying the column rules of an anonymize spec
+// to each entry as it's read. When the spec sets K, k-anonymity of the
+// anonymized quasi identifiers is checked once r is exhausted: instead of
+// io.EOF the final read errors if any combination of values occurs fewer
+// than K times. secrets provides the salts of keyed hashes, usually
+// Transform.Secrets. Array rows require a tabular schema to locate columns
+func NewAnonymizeReader(r EntryReader, spec *dataset.AnonymizeSpec, secrets map[string]string) (EntryReader, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("anonymize: spec is required")
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("anonymize: %w", err)
+	}
+
+	st := r.Structure().Clone()
+	if st == nil {
+		st = &dataset.Structure{}
+	}
+	ar := &anonymizeReader{r: r, spec: spec, st: st}
+	for _, c := range spec.Columns {
+		var salt []byte
+		if c.Salt != "" {
+			s, ok := secrets[c.Salt]
+			if !ok || s == dataset.SecretRedacted {
+				return nil, fmt.Errorf("anonymize: column %q: salt secret %q is not set", c.Column, c.Salt)
+			}
+			salt = []byte(s)
+		}
+		ar.salts = append(ar.salts, salt)
+	}
+	if spec.K > 0 {
+		ar.groups = newGroupCounter()
+	}
+
+	if cols, _, err := tabular.ColumnsFromJSONSchema(ar.st.Schema); err == nil {
+		ar.index = map[string]int{}
+		for i, title := range cols.Titles() {
+			ar.index[title] = i
+		}
+		names := append([]string{}, spec.QuasiIdentifiers...)
+		for _, c := range spec.Columns {
+			names = append(names, c.Column)
+		}
+		for _, name := range names {
+			if _, ok := ar.index[name]; !ok {
+				return nil, fmt.Errorf("anonymize: column %q not found", name)
+			}
+		}
+		ar.stringColumns()
+	}
+	return ar, nil
+}
+
+// anonymizeReader applies an anonymize spec to entries
+type anonymizeReader struct {
+	r      EntryReader
+	spec   *dataset.AnonymizeSpec
+	st     *dataset.Structure
+	salts  [][]byte
+	index  map[string]int
+	groups *groupCounter
+	read   int
+}
+
+var _ EntryReader = (*anonymizeReader)(nil)
+
+// stringColumns sets the schema type of hashed & masked columns to string
+func (r *anonymizeReader) stringColumns() {
+	items, ok := r.st.Schema["items"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	cols, ok := items["items"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, c := range r.spec.Columns {
+		if c.Method == dataset.AnonymizeTruncate {
+			continue
+		}
+		col, ok := cols[r.index[c.Column]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if types, ok := col["type"].([]interface{}); ok && containsNull(types) {
+			col["type"] = []interface{}{"string", "null"}
+		} else {
+			col["type"] = "string"
+		}
+	}
+}
+
+func containsNull(types []interface{}) bool {
+	for _, t := range types {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// Structure gives the structure of anonymized entries
+func (r *anonymizeReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads & anonymizes the next entry
+func (r *anonymizeReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err == io.EOF && r.groups != nil {
+		if err := r.groups.check(r.spec.K); err != nil {
+			return ent, fmt.Errorf("anonymize: %w", err)
+		}
+	}
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	val, get, set, err := r.accessors(ent.Value)
+	if err != nil {
+		return ent, fmt.Errorf("anonymize: entry %d: %w", i, err)
+	}
+	ent.Value = val
+	for j, c := range r.spec.Columns {
+		v, err := anonymizeValue(c, r.salts[j], get(c.Column))
+		if err != nil {
+			return ent, fmt.Errorf("anonymize: entry %d: column %q: %w", i, c.Column, err)
+		}
+		set(c.Column, v)
+	}
+	if r.groups != nil {
+		vals := make([]interface{}, len(r.spec.QuasiIdentifiers))
+		for j, name := range r.spec.QuasiIdentifiers {
+			vals[j] = get(name)
+		}
+		r.groups.add(vals)
+	}
+	return ent, nil
+}
+
+// accessors copies an entry value, giving functions to read & write columns
+// of the copy
+func (r *anonymizeReader) accessors(v interface{}) (val interface{}, get func(string) interface{}, set func(string, interface{}), err error) {
+	return rowAccessors(r.index, v)
+}
+
+// rowAccessors copies an entry value, giving functions to read & write
+// columns of the copy by name. index locates the columns of array rows, &
+// is nil when the schema isn't tabular
+func rowAccessors(index map[string]int, v interface{}) (val interface{}, get func(string) interface{}, set func(string, interface{}), err error) {
+	switch row := v.(type) {
+	case []interface{}:
+		if index == nil {
+			return nil, nil, nil, fmt.Errorf("array rows require a tabular schema")
+		}
+		cp := make([]interface{}, len(row))
+		copy(cp, row)
+		get = func(name string) interface{} {
+			if i := index[name]; i < len(cp) {
+				return cp[i]
+			}
+			return nil
+		}
+		set = func(name string, val interface{}) {
+			if i := index[name]; i < len(cp) {
+				cp[i] = val
+			}
+		}
+		return cp, get, set, nil
+	case map[string]interface{}:
+		cp := make(map[string]interface{}, len(row))
+		for key, val := range row {
+			cp[key] = val
+		}
+		get = func(name string) interface{} { return cp[name] }
+		set = func(name string, val interface{}) {
+			if _, ok := cp[name]; ok {
+				cp[name] = val
+			}
+		}
+		return cp, get, set, nil
+	}
+	return nil, nil, nil, fmt.Errorf("expected an array or object, got %T", v)
+}
+
+// Close closes the underlying reader
+func (r *anonymizeReader) Close() error {
+	return r.r.Close()
+}
+
+// anonymizeValue applies a single column rule. null values are left as-is
+func anonymizeValue(c *dataset.AnonymizeColumn, salt []byte, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch c.Method {
+	case dataset.AnonymizeHash:
+		var h hash.Hash
+		if salt != nil {
+			h = hmac.New(sha256.New, salt)
+		} else {
+			h = sha256.New()
+		}
+		h.Write([]byte(valueString(v)))
+		return hex.EncodeToString(h.Sum(nil)), nil
+	case dataset.AnonymizeMask:
+		s := valueString(v)
+		n := utf8.RuneCountInString(s)
+		if n <= c.Keep {
+			return s, nil
+		}
+		runes := []rune(s)
+		return strings.Repeat("*", n-c.Keep) + string(runes[n-c.Keep:]), nil
+	case dataset.AnonymizeTruncate:
+		switch t := v.(type) {
+		case float64:
+			// cut the decimal text, multiplying by powers of ten rounds
+			s := truncateDigits(strconv.FormatFloat(t, 'f', -1, 64), c.Keep)
+			return strconv.ParseFloat(s, 64)
+		case vals.Decimal:
+			return vals.ParseDecimal(truncateDigits(t.String(), c.Keep))
+		case int, int64, int32, uint64:
+			return t, nil
+		case string:
+			if runes := []rune(t); len(runes) > c.Keep {
+				return string(runes[:c.Keep]), nil
+			}
+			return t, nil
+		}
+		return nil, fmt.Errorf("cannot truncate %T", v)
+	}
+	return nil, fmt.Errorf("unknown method %q", c.Method)
+}
+
+// truncateDigits cuts decimal text to keep digits after the decimal point
+func truncateDigits(s string, keep int) string {
+	i := strings.IndexByte(s, '.')
+	if i < 0 || len(s)-i-1 <= keep {
+		return s
+	}
+	if keep == 0 {
+		return s[:i]
+	}
+	return s[:i+1+keep]
+}
+
+// valueString gives the text hashed or masked for a value: strings as-is,
+// other values JSON-encoded
+func valueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// groupCounter counts occurrences of value combinations
+type groupCounter struct {
+	counts map[string]int
+}
+
+func newGroupCounter() *groupCounter {
+	return &groupCounter{counts: map[string]int{}}
+}
+
+func (g *groupCounter) add(vals []interface{}) {
+	key, err := json.Marshal(vals)
+	if err != nil {
+		key = []byte(fmt.Sprint(vals))
+	}
+	g.counts[string(key)]++
+}
+
+// min gives the size of the smallest group, zero if there are no groups
+func (g *groupCounter) min() int {
+	min := 0
+	for _, n := range g.counts {
+		if min == 0 || n < min {
+			min = n
+		}
+	}
+	return min
+}
+
+// check errors if any group has fewer than k members
+func (g *groupCounter) check(k int) error {
+	entries, groups := 0, 0
+	for _, n := range g.counts {
+		if n < k {
+			entries += n
+			groups++
+		}
+	}
+	if groups > 0 {
+		return fmt.Errorf("k-anonymity: %d entries in %d groups occur fewer than %d times", entries, groups, k)
+	}
+	return nil
+}
+
+// KAnonymity consumes a reader, giving the size of the smallest group of
+// entries sharing values for columns: the k for which the body is
+// k-anonymous. An empty body gives zero
+func KAnonymity(r EntryReader, columns []string) (int, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("k-anonymity: columns are required")
+	}
+	var index map[string]int
+	if cols, _, err := tabular.ColumnsFromJSONSchema(r.Structure().Schema); err == nil {
+		index = map[string]int{}
+		for i, title := range cols.Titles() {
+			index[title] = i
+		}
+		for _, name := range columns {
+			if _, ok := index[name]; !ok {
+				return 0, fmt.Errorf("k-anonymity: column %q not found", name)
+			}
+		}
+	}
+
+	groups := newGroupCounter()
+	err := EachEntry(r, func(i int, ent Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		vals := make([]interface{}, len(columns))
+		switch row := ent.Value.(type) {
+		case []interface{}:
+			if index == nil {
+				return fmt.Errorf("k-anonymity: entry %d: array rows require a tabular schema", i)
+			}
+			for j, name := range columns {
+				if k := index[name]; k < len(row) {
+					vals[j] = row[k]
+				}
+			}
+		case map[string]interface{}:
+			for j, name := range columns {
+				vals[j] = row[name]
+			}
+		default:
+			return fmt.Errorf("k-anonymity: entry %d: expected an array or object, got %T", i, ent.Value)
+		}
+		groups.add(vals)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return groups.min(), nil
+}
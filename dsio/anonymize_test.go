@@ -0,0 +1,220 @@
+package dsio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+// readRows reads all entry values of r. Unlike readValues entry indexes
+// aren't checked, CSV readers don't set them
+func readRows(r EntryReader) ([]interface{}, error) {
+	rows := []interface{}{}
+	err := EachEntry(r, func(i int, ent Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		rows = append(rows, ent.Value)
+		return nil
+	})
+	return rows, err
+}
+
+func TestAnonymizeReader(t *testing.T) {
+	spec := &dataset.AnonymizeSpec{
+		Columns: []*dataset.AnonymizeColumn{
+			{Column: "email", Method: dataset.AnonymizeHash},
+			{Column: "phone", Method: dataset.AnonymizeMask, Keep: 2},
+			{Column: "lat", Method: dataset.AnonymizeTruncate, Keep: 3},
+			{Column: "name", Method: dataset.AnonymizeTruncate, Keep: 1},
+		},
+	}
+	r, err := NewAnonymizeReader(jsonArrayReader(t, `[
+		{"email":"a@mfdz.de","phone":"0711 123456","lat":48.78291,"name":"Ada"},
+		{"email":null,"phone":12345,"lat":-9.9999,"name":"B"},
+		{"lat":7},
+		{"lat":0.1239}
+	]`), spec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		map[string]interface{}{
+			"email": "ca34f04e302315fa27f4f96ae46caeee3a44c498879d6a3e13352b81764bd532",
+			"phone": "*********56",
+			"lat":   48.782,
+			"name":  "A",
+		},
+		map[string]interface{}{"email": nil, "phone": "***45", "lat": -9.999, "name": "B"},
+		map[string]interface{}{"lat": int64(7)},
+		map[string]interface{}{"lat": 0.123},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("result mismatch.\nexpected: %#v\ngot:      %#v", expect, got)
+	}
+}
+
+func TestAnonymizeReaderSalt(t *testing.T) {
+	spec := &dataset.AnonymizeSpec{Columns: []*dataset.AnonymizeColumn{{Column: "email", Method: dataset.AnonymizeHash, Salt: "salt"}}}
+
+	if _, err := NewAnonymizeReader(jsonArrayReader(t, `[]`), spec, map[string]string{"salt": dataset.SecretRedacted}); err == nil || err.Error() != `anonymize: column "email": salt secret "salt" is not set` {
+		t.Errorf("expected redacted salt error, got: %v", err)
+	}
+
+	r, err := NewAnonymizeReader(jsonArrayReader(t, `[{"email":"a@mfdz.de"}]`), spec, map[string]string{"salt": "pepper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HMAC-SHA256 of "a@mfdz.de" keyed with "pepper"
+	expect := "e4b1e9d743a2a7a093f39a1e5afa89c2781ab3f101f961eb87c7940c2669ac4c"
+	if h := got[0].(map[string]interface{})["email"]; h != expect {
+		t.Errorf("hash mismatch. expected: %s, got: %v", expect, h)
+	}
+}
+
+func TestAnonymizeReaderTabular(t *testing.T) {
+	spec := &dataset.AnonymizeSpec{
+		Columns:          []*dataset.AnonymizeColumn{{Column: "zip", Method: dataset.AnonymizeTruncate, Keep: 3}},
+		K:                2,
+		QuasiIdentifiers: []string{"zip", "age"},
+	}
+
+	r, err := NewAnonymizeReader(stringCSVReader(t, "zip,age\n70173,30\n70178,30\n10115,40\n", "zip", "age"), spec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRows(r)
+	expectErr := "error reading row 3: anonymize: k-anonymity: 1 entries in 1 groups occur fewer than 2 times"
+	if err == nil || err.Error() != expectErr {
+		t.Errorf("error mismatch. expected: %q, got: %v", expectErr, err)
+	}
+	expect := []interface{}{[]interface{}{"701", "30"}, []interface{}{"701", "30"}, []interface{}{"101", "40"}}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("result mismatch.\nexpected: %#v\ngot:      %#v", expect, got)
+	}
+
+	r, err = NewAnonymizeReader(stringCSVReader(t, "zip,age\n70173,30\n70178,30\n", "zip", "age"), spec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRows(r); err != nil {
+		t.Errorf("expected k-anonymous body to read without error, got: %v", err)
+	}
+
+	spec = &dataset.AnonymizeSpec{Columns: []*dataset.AnonymizeColumn{{Column: "city", Method: dataset.AnonymizeMask}}}
+	if _, err := NewAnonymizeReader(stringCSVReader(t, "zip,age\n", "zip", "age"), spec, nil); err == nil || err.Error() != `anonymize: column "city" not found` {
+		t.Errorf("expected missing column error, got: %v", err)
+	}
+}
+
+func TestAnonymizeReaderSchema(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "id", "type": "integer"},
+					map[string]interface{}{"title": "phone", "type": []interface{}{"integer", "null"}},
+					map[string]interface{}{"title": "lat", "type": "number"},
+				},
+			},
+		},
+	}
+	src, err := NewJSONReader(st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := &dataset.AnonymizeSpec{Columns: []*dataset.AnonymizeColumn{
+		{Column: "id", Method: dataset.AnonymizeHash},
+		{Column: "phone", Method: dataset.AnonymizeMask},
+		{Column: "lat", Method: dataset.AnonymizeTruncate, Keep: 2},
+	}}
+	r, err := NewAnonymizeReader(src, spec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols := r.Structure().Schema["items"].(map[string]interface{})["items"].([]interface{})
+	types := []interface{}{}
+	for _, c := range cols {
+		types = append(types, c.(map[string]interface{})["type"])
+	}
+	expect := []interface{}{"string", []interface{}{"string", "null"}, "number"}
+	if !reflect.DeepEqual(expect, types) {
+		t.Errorf("column types mismatch. expected: %v, got: %v", expect, types)
+	}
+	if st.Schema["items"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["type"] != "integer" {
+		t.Error("expected source schema to be unmodified")
+	}
+}
+
+func TestKAnonymity(t *testing.T) {
+	cases := []struct {
+		data    string
+		columns []string
+		k       int
+		err     string
+	}{
+		{"zip,age\n701,30\n701,30\n101,40\n", []string{"zip"}, 1, ""},
+		{"zip,age\n701,30\n701,30\n101,40\n101,41\n", []string{"zip"}, 2, ""},
+		{"zip,age\n701,30\n701,30\n101,40\n101,41\n", []string{"zip", "age"}, 1, ""},
+		{"zip,age\n", []string{"zip"}, 0, ""},
+		{"zip,age\n", []string{"city"}, 0, `k-anonymity: column "city" not found`},
+		{"zip,age\n", nil, 0, "k-anonymity: columns are required"},
+	}
+	for i, c := range cases {
+		k, err := KAnonymity(stringCSVReader(t, c.data, "zip", "age"), c.columns)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+			continue
+		}
+		if k != c.k {
+			t.Errorf("case %d k mismatch. expected: %d, got: %d", i, c.k, k)
+		}
+	}
+}
+
+func TestAnonymizeTruncate(t *testing.T) {
+	dec, _ := vals.ParseDecimal("12.3456")
+	cases := []struct {
+		keep   int
+		v      interface{}
+		expect interface{}
+	}{
+		{2, 0.29, 0.29},
+		{1, 0.29, 0.2},
+		{0, -9.99, -9.0},
+		{3, 1e-7, 0.0},
+		{2, 48.0, 48.0},
+		{2, int64(7), int64(7)},
+		{2, "70173", "70"},
+	}
+	for i, c := range cases {
+		got, err := anonymizeValue(&dataset.AnonymizeColumn{Method: dataset.AnonymizeTruncate, Keep: c.keep}, nil, c.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.expect {
+			t.Errorf("case %d: truncating %v to %d digits expected: %v, got: %v", i, c.v, c.keep, c.expect, got)
+		}
+	}
+
+	got, err := anonymizeValue(&dataset.AnonymizeColumn{Method: dataset.AnonymizeTruncate, Keep: 2}, nil, dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.(vals.Decimal).String() != "12.34" {
+		t.Errorf("expected decimal to truncate to 12.34, got: %v", got)
+	}
+}
@@ -3,12 +3,18 @@ package dsio
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
 	"github.com/ugorji/go/codec"
 )
 
@@ -19,6 +25,9 @@ type CBORReader struct {
 	st       *dataset.Structure
 	topLevel byte
 	length   int
+	lend     bool
+	// lent is a pooled row for the next top-level array
+	lent []interface{}
 }
 
 var _ EntryReader = (*CBORReader)(nil)
@@ -87,7 +96,11 @@ func (r *CBORReader) ReadEntry() (ent Entry, err error) {
 		ent.Index = r.rowsRead
 	}
 
+	if r.lend && r.topLevel == cborBaseArray {
+		r.lent = borrowRow(0)
+	}
 	ent.Value, err = r.readValue()
+	r.lent = nil
 	if err != nil {
 		return
 	}
@@ -96,6 +109,12 @@ func (r *CBORReader) ReadEntry() (ent Entry, err error) {
 	return
 }
 
+// lendEntries decodes top-level array rows into pooled memory. see
+// BorrowEntries
+func (r *CBORReader) lendEntries() {
+	r.lend = true
+}
+
 // Close finalizes the reader
 func (r *CBORReader) Close() error {
 	// TODO (b5): check if underlying reader is an io.ReadCloser, call close here if so
@@ -212,39 +231,19 @@ func (r *CBORReader) readValue() (interface{}, error) {
 	case cborBdFloat64:
 		return r.readFloatBytes(8)
 	case cborBdIndefiniteBytes:
-		concat := bytes.Buffer{}
-		for {
-			if r.readIndefiniteSequenceBreak() {
-				break
-			}
-			b, err := r.rdr.ReadByte()
-			if err != nil {
-				return nil, err
-			}
-			buff, err := r.readLengthPrefixedBytes(b)
-			if err != nil {
-				return nil, err
-			}
-			concat.Write(buff)
+		concat := getBuffer()
+		defer putBuffer(concat)
+		if err := r.readIndefiniteChunks(concat); err != nil {
+			return nil, err
 		}
-		return concat.Bytes(), nil
+		return append([]byte(nil), concat.Bytes()...), nil
 	case cborBdIndefiniteString:
-		concat := bytes.Buffer{}
-		for {
-			if r.readIndefiniteSequenceBreak() {
-				break
-			}
-			b, err := r.rdr.ReadByte()
-			if err != nil {
-				return nil, err
-			}
-			buff, err := r.readLengthPrefixedBytes(b)
-			if err != nil {
-				return nil, err
-			}
-			concat.Write(buff)
+		concat := getBuffer()
+		defer putBuffer(concat)
+		if err := r.readIndefiniteChunks(concat); err != nil {
+			return nil, err
 		}
-		return string(concat.Bytes()), nil
+		return concat.String(), nil
 	case cborBdIndefiniteArray:
 		array, err := r.readArray(indefiniteLength)
 		if err != nil {
@@ -292,9 +291,14 @@ func (r *CBORReader) readValue() (interface{}, error) {
 				return nil, err
 			}
 			return assoc, nil
+		case cborBaseNegInt:
+			n, err := r.getVarLenInt(b)
+			if err != nil {
+				return nil, err
+			}
+			return -1 - n, nil
 		case cborBaseTag:
-			// TODO: Implement me
-			return nil, nil
+			return r.readTagged(b)
 		case cborBaseSimple:
 			// TODO: Implement me
 			return nil, nil
@@ -304,6 +308,110 @@ func (r *CBORReader) readValue() (interface{}, error) {
 	}
 }
 
+// standard CBOR tags, as registered with IANA
+const (
+	cborTagDateTimeString  uint64 = 0
+	cborTagEpochDateTime   uint64 = 1
+	cborTagPosBignum       uint64 = 2
+	cborTagNegBignum       uint64 = 3
+	cborTagDecimalFraction uint64 = 4
+	cborTagBase64URL       uint64 = 33
+	cborTagBase64          uint64 = 34
+	cborTagEpochDate       uint64 = 100
+	cborTagDateString      uint64 = 1004
+	cborTagSelfDescribe    uint64 = 55799
+)
+
+// readTagged reads a tagged value, mapping standard tags to the vals types
+// they describe: datetimes to vals.DateTime, dates to vals.Date and bignums
+// & decimal fractions to vals.Decimal. base64 encoded text is decoded to
+// bytes. the content of unrecognized tags is returned untagged
+func (r *CBORReader) readTagged(b byte) (interface{}, error) {
+	n, err := r.getVarLenInt(b)
+	if err != nil {
+		return nil, err
+	}
+	tag := uint64(n)
+	content, err := r.readValue()
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := func() (interface{}, error) {
+		return nil, fmt.Errorf("invalid content for cbor tag %d: %#v", tag, content)
+	}
+
+	switch tag {
+	case cborTagDateTimeString:
+		s, ok := content.(string)
+		if !ok {
+			return invalid()
+		}
+		return vals.ParseDateTime(s)
+	case cborTagEpochDateTime:
+		switch t := content.(type) {
+		case int64:
+			return vals.NewDateTime(time.Unix(t, 0).UTC()), nil
+		case float64:
+			sec, frac := math.Modf(t)
+			return vals.NewDateTime(time.Unix(int64(sec), int64(frac*1e9)).UTC().Round(time.Microsecond)), nil
+		}
+		return invalid()
+	case cborTagPosBignum, cborTagNegBignum:
+		data, ok := content.([]byte)
+		if !ok {
+			return invalid()
+		}
+		i := new(big.Int).SetBytes(data)
+		if tag == cborTagNegBignum {
+			i.Neg(i).Sub(i, big.NewInt(1))
+		}
+		return vals.ParseDecimal(i.String())
+	case cborTagDecimalFraction:
+		parts, ok := content.([]interface{})
+		if !ok || len(parts) != 2 {
+			return invalid()
+		}
+		exp, ok := parts[0].(int64)
+		if !ok {
+			return invalid()
+		}
+		var mantissa string
+		switch m := parts[1].(type) {
+		case int64:
+			mantissa = strconv.FormatInt(m, 10)
+		case vals.Decimal:
+			mantissa = m.String()
+		default:
+			return invalid()
+		}
+		return vals.ParseDecimal(fmt.Sprintf("%se%d", mantissa, exp))
+	case cborTagBase64URL, cborTagBase64:
+		s, ok := content.(string)
+		if !ok {
+			return invalid()
+		}
+		enc := base64.RawStdEncoding
+		if tag == cborTagBase64URL {
+			enc = base64.RawURLEncoding
+		}
+		return enc.DecodeString(strings.TrimRight(s, "="))
+	case cborTagEpochDate:
+		days, ok := content.(int64)
+		if !ok {
+			return invalid()
+		}
+		return vals.NewDate(1970, time.January, 1+int(days)), nil
+	case cborTagDateString:
+		s, ok := content.(string)
+		if !ok {
+			return invalid()
+		}
+		return vals.ParseDate(s)
+	}
+	return content, nil
+}
+
 // readIndefiniteSequenceBreak returns true if the next byte is a sequence break
 func (r *CBORReader) readIndefiniteSequenceBreak() bool {
 	bytes, err := r.rdr.Peek(1)
@@ -318,6 +426,25 @@ func (r *CBORReader) readIndefiniteSequenceBreak() bool {
 }
 
 // readLengthPrefixedBytes returns a number of bytes prefixed by the number of bytes to read
+// readIndefiniteChunks reads the chunks of an indefinite length byte or text
+// string into buf
+func (r *CBORReader) readIndefiniteChunks(buf *bytes.Buffer) error {
+	for {
+		if r.readIndefiniteSequenceBreak() {
+			return nil
+		}
+		b, err := r.rdr.ReadByte()
+		if err != nil {
+			return err
+		}
+		buff, err := r.readLengthPrefixedBytes(b)
+		if err != nil {
+			return err
+		}
+		buf.Write(buff)
+	}
+}
+
 func (r *CBORReader) readLengthPrefixedBytes(b byte) ([]byte, error) {
 	length, err := r.getVarLenInt(b)
 	if err != nil {
@@ -333,7 +460,10 @@ func (r *CBORReader) readLengthPrefixedBytes(b byte) ([]byte, error) {
 // readArray reads an array of the given length
 func (r *CBORReader) readArray(length int) ([]interface{}, error) {
 	var array []interface{}
-	if length > 0 {
+	if r.lent != nil {
+		// the first array read for an entry is the top-level row
+		array, r.lent = r.lent, nil
+	} else if length > 0 {
 		array = make([]interface{}, 0, length)
 	} else {
 		array = make([]interface{}, 0)
@@ -439,12 +569,15 @@ type CBORWriter struct {
 	tlt         string
 	st          *dataset.Structure
 	wr          io.Writer
+	cfg         *WriterConfig
 	arr         []interface{}
 	obj         map[string]interface{}
+	keys        []string
 }
 
-// NewCBORWriter creates a Writer from a structure and write destination
-func NewCBORWriter(st *dataset.Structure, w io.Writer) (*CBORWriter, error) {
+// NewCBORWriter creates a Writer from a structure and write destination.
+// WithSchemaKeyOrder is the only writer option CBOR writers read
+func NewCBORWriter(st *dataset.Structure, w io.Writer, opts ...func(*WriterConfig)) (*CBORWriter, error) {
 	if st.Schema == nil {
 		return nil, fmt.Errorf("schema required for CBOR writer")
 	}
@@ -457,6 +590,7 @@ func NewCBORWriter(st *dataset.Structure, w io.Writer) (*CBORWriter, error) {
 		st:  st,
 		wr:  w,
 		tlt: tlt,
+		cfg: newWriterConfig(opts...),
 	}
 
 	if cw.tlt == "object" {
@@ -479,6 +613,10 @@ func (w *CBORWriter) WriteEntry(ent Entry) error {
 		w.rowsWritten++
 	}()
 
+	val := cborValue(ent.Value)
+	if w.cfg.SchemaKeyOrder {
+		val = keyOrdered(val, entrySchema(w.st.Schema, ent))
+	}
 	if w.tlt == "object" {
 		if ent.Key == "" {
 			return fmt.Errorf("Key cannot be empty")
@@ -487,14 +625,72 @@ func (w *CBORWriter) WriteEntry(ent Entry) error {
 		if _, ok := w.obj[ent.Key]; ok {
 			return fmt.Errorf(`key already written: '%s'`, ent.Key)
 		}
-		w.obj[ent.Key] = ent.Value
+		w.obj[ent.Key] = val
+		w.keys = append(w.keys, ent.Key)
 		return nil
 	}
 
-	w.arr = append(w.arr, ent.Value)
+	w.arr = append(w.arr, val)
 	return nil
 }
 
+// cborValue converts value types the CBOR encoder doesn't know how to encode
+// into types it does. datetimes & dates are written as tagged strings and
+// decimals as tagged decimal fractions. CBOR has no standard tag for times of
+// day, which are written as untagged ISO 8601 strings
+func cborValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case vals.DateTime:
+		return &codec.RawExt{Tag: cborTagDateTimeString, Value: t.String()}
+	case vals.Date:
+		return &codec.RawExt{Tag: cborTagDateString, Value: t.String()}
+	case vals.Time:
+		return t.String()
+	case vals.Decimal:
+		return cborDecimal(t)
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, el := range t {
+			arr[i] = cborValue(el)
+		}
+		return arr
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(t))
+		for key, el := range t {
+			obj[key] = cborValue(el)
+		}
+		return obj
+	}
+	return v
+}
+
+// cborDecimal writes a decimal as a decimal fraction [exponent, mantissa],
+// using a bignum mantissa when it overflows an int64
+func cborDecimal(d vals.Decimal) interface{} {
+	digits := d.String()
+	exp := 0
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		exp = -(len(digits) - i - 1)
+		digits = digits[:i] + digits[i+1:]
+	}
+	m, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return d.Number()
+	}
+
+	var mantissa interface{}
+	switch {
+	case m.IsInt64():
+		mantissa = m.Int64()
+	case m.Sign() > 0:
+		mantissa = &codec.RawExt{Tag: cborTagPosBignum, Value: m.Bytes()}
+	default:
+		n := new(big.Int).Neg(m)
+		mantissa = &codec.RawExt{Tag: cborTagNegBignum, Value: n.Sub(n, big.NewInt(1)).Bytes()}
+	}
+	return &codec.RawExt{Tag: cborTagDecimalFraction, Value: []interface{}{exp, mantissa}}
+}
+
 // Close finalizes the writer, indicating no more records
 // will be written
 func (w *CBORWriter) Close() error {
@@ -503,6 +699,13 @@ func (w *CBORWriter) Close() error {
 	enc := codec.NewEncoder(w.wr, h)
 
 	if w.tlt == "object" {
+		if w.cfg.SchemaKeyOrder {
+			obj := make(orderedObject, 0, len(w.keys)*2)
+			for _, key := range w.keys {
+				obj = append(obj, key, w.obj[key])
+			}
+			return enc.Encode(obj)
+		}
 		return enc.Encode(w.obj)
 	}
 
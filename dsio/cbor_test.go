@@ -7,9 +7,11 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dstest"
+	"github.com/qri-io/dataset/vals"
 )
 
 var (
@@ -33,7 +35,6 @@ var (
 	}}
 )
 
-// TODO(dustmop): Tag support.
 // TODO(dustmop): Test illegal chunks.
 // TODO(dustmop): Move indefinite streams to their own test, test that 0xff correctly returns EOF.
 
@@ -265,6 +266,113 @@ func TestCBORReaderFile(t *testing.T) {
 	}
 }
 
+func TestCBORReaderTags(t *testing.T) {
+	mustDecimal := func(s string) vals.Decimal {
+		d, err := vals.ParseDecimal(s)
+		if err != nil {
+			panic(err)
+		}
+		return d
+	}
+	at := time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)
+
+	// examples from RFC 8949 appendix A, each wrapped in a single-element array
+	cases := []struct {
+		cbor   string
+		expect interface{}
+		err    string
+	}{
+		{"81c074323031332d30332d32315432303a30343a30305a", vals.NewDateTime(at), ""},
+		{"81c11a514b67b0", vals.NewDateTime(at), ""},
+		{"81c1fb41d452d9ec200000", vals.NewDateTime(at.Add(500 * time.Millisecond)), ""},
+		{"81c249010000000000000000", mustDecimal("18446744073709551616"), ""},
+		{"81c349010000000000000000", mustDecimal("-18446744073709551617"), ""},
+		{"81c48221196ab3", mustDecimal("273.15"), ""},
+		{"81d903ec6a323031392d30332d3331", vals.NewDate(2019, 3, 31), ""},
+		{"81d86400", vals.NewDate(1970, 1, 1), ""},
+		{"81d82268614756736247383d", []byte("hello"), ""},
+		{"81d8216761475673624738", []byte("hello"), ""},
+		{"81d82076687474703a2f2f7777772e6578616d706c652e636f6d", "http://www.example.com", ""},
+		{"81d9d9f7183f", int64(63), ""},
+		{"813903e7", int64(-1000), ""},
+
+		{"81c001", nil, "invalid content for cbor tag 0: 1"},
+		{"81c06474657374", nil, "invalid datetime 'test'"},
+	}
+
+	for i, c := range cases {
+		data, err := hex.DecodeString(c.cbor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewCBORReader(&dataset.Structure{Format: "cbor", Schema: dataset.BaseSchemaArray}, bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ent, err := r.ReadEntry()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if expect, ok := c.expect.(vals.Value); ok {
+			got, ok := ent.Value.(vals.Value)
+			if !ok || !vals.Equal(expect, got) {
+				t.Errorf("case %d mismatch. expected: %v, got: %#v", i, expect, ent.Value)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(c.expect, ent.Value) {
+			t.Errorf("case %d mismatch. expected: %#v, got: %#v", i, c.expect, ent.Value)
+		}
+	}
+}
+
+func TestCBORTaggedRoundTrip(t *testing.T) {
+	dec, _ := vals.ParseDecimal("-12.50")
+	bigDec, _ := vals.ParseDecimal("123456789012345678901234567890.1")
+	row := []interface{}{
+		vals.NewDate(2019, 3, 31),
+		vals.NewDateTime(time.Date(2019, 3, 31, 13, 45, 0, 0, time.FixedZone("CEST", 2*60*60))),
+		dec,
+		bigDec,
+	}
+
+	st := &dataset.Structure{Format: "cbor", Schema: dataset.BaseSchemaArray}
+	buf := &bytes.Buffer{}
+	w, err := NewCBORWriter(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteEntry(Entry{Value: row}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewCBORReader(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := ent.Value.([]interface{})
+	if !ok || len(got) != len(row) {
+		t.Fatalf("unexpected entry: %#v", ent.Value)
+	}
+	for i, v := range row {
+		g, ok := got[i].(vals.Value)
+		if !ok || reflect.TypeOf(g) != reflect.TypeOf(v) || g.String() != v.(vals.Value).String() {
+			t.Errorf("index %d mismatch. expected: %v, got: %#v", i, v, got[i])
+		}
+	}
+}
+
 func TestCBORWriter(t *testing.T) {
 	objst := &dataset.Structure{Schema: dataset.BaseSchemaObject}
 	arrst := &dataset.Structure{Schema: dataset.BaseSchemaArray}
@@ -443,7 +551,7 @@ func BenchmarkCBORReader(b *testing.B) {
 	st := &dataset.Structure{Format: "cbor", Schema: dataset.BaseSchemaArray}
 
 	for n := 0; n < b.N; n++ {
-		file, err := os.Open(testdataFile("../dsio/testdata/movies/body.cbor"))
+		file, err := os.Open("testdata/movies/body.cbor")
 		if err != nil {
 			b.Errorf("unexpected error: %s", err.Error())
 		}
@@ -0,0 +1,421 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// Vector is one column of a Table. Typed vectors store values unboxed, use
+// the concrete vector types to read values without allocating. Each typed
+// vector holds a single kind of go value: integer vectors only hold go
+// integers & number vectors only floats, numbers of the other kind aren't
+// converted
+type Vector interface {
+	// Title gives the column title
+	Title() string
+	// Type gives the type of values in the vector: "integer", "number",
+	// "boolean" or "string" for typed vectors, "any" for AnyVector
+	Type() string
+	// Len gives the number of values in the vector
+	Len() int
+	// IsNull reports whether the value at index i is null
+	IsNull(i int) bool
+	// Value gives the value at index i as a native go type, nil for null
+	Value(i int) interface{}
+
+	// push appends v, returning false if v is a value the vector can't hold
+	push(v interface{}) bool
+}
+
+// nulls records the indexes of null values in a vector, allocating only once
+// a null value is pushed
+type nulls []bool
+
+func (n nulls) IsNull(i int) bool {
+	return i < len(n) && n[i]
+}
+
+func (n *nulls) set(i int) {
+	for len(*n) <= i {
+		*n = append(*n, false)
+	}
+	(*n)[i] = true
+}
+
+// IntVector is a column of integers
+type IntVector struct {
+	title string
+	nulls
+	// Values holds every value in the column, 0 for nulls
+	Values []int64
+}
+
+// Title gives the column title
+func (v *IntVector) Title() string { return v.title }
+
+// Type gives "integer"
+func (v *IntVector) Type() string { return "integer" }
+
+// Len gives the number of values in the vector
+func (v *IntVector) Len() int { return len(v.Values) }
+
+// Value gives the value at index i as an int64, nil for null
+func (v *IntVector) Value(i int) interface{} {
+	if v.IsNull(i) {
+		return nil
+	}
+	return v.Values[i]
+}
+
+func (v *IntVector) push(val interface{}) bool {
+	if val == nil {
+		v.set(len(v.Values))
+		v.Values = append(v.Values, 0)
+		return true
+	}
+	var n int64
+	switch x := val.(type) {
+	case int:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint64:
+		if x > math.MaxInt64 {
+			return false
+		}
+		n = int64(x)
+	default:
+		return false
+	}
+	v.Values = append(v.Values, n)
+	return true
+}
+
+// FloatVector is a column of numbers
+type FloatVector struct {
+	title string
+	nulls
+	// Values holds every value in the column, 0 for nulls
+	Values []float64
+}
+
+// Title gives the column title
+func (v *FloatVector) Title() string { return v.title }
+
+// Type gives "number"
+func (v *FloatVector) Type() string { return "number" }
+
+// Len gives the number of values in the vector
+func (v *FloatVector) Len() int { return len(v.Values) }
+
+// Value gives the value at index i as a float64, nil for null
+func (v *FloatVector) Value(i int) interface{} {
+	if v.IsNull(i) {
+		return nil
+	}
+	return v.Values[i]
+}
+
+func (v *FloatVector) push(val interface{}) bool {
+	if val == nil {
+		v.set(len(v.Values))
+		v.Values = append(v.Values, 0)
+		return true
+	}
+	var f float64
+	switch x := val.(type) {
+	case float64:
+		f = x
+	case float32:
+		f = float64(x)
+	default:
+		return false
+	}
+	v.Values = append(v.Values, f)
+	return true
+}
+
+// BoolVector is a column of booleans
+type BoolVector struct {
+	title string
+	nulls
+	// Values holds every value in the column, false for nulls
+	Values []bool
+}
+
+// Title gives the column title
+func (v *BoolVector) Title() string { return v.title }
+
+// Type gives "boolean"
+func (v *BoolVector) Type() string { return "boolean" }
+
+// Len gives the number of values in the vector
+func (v *BoolVector) Len() int { return len(v.Values) }
+
+// Value gives the value at index i as a bool, nil for null
+func (v *BoolVector) Value(i int) interface{} {
+	if v.IsNull(i) {
+		return nil
+	}
+	return v.Values[i]
+}
+
+func (v *BoolVector) push(val interface{}) bool {
+	if val == nil {
+		v.set(len(v.Values))
+		v.Values = append(v.Values, false)
+		return true
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false
+	}
+	v.Values = append(v.Values, b)
+	return true
+}
+
+// StringVector is a column of strings
+type StringVector struct {
+	title string
+	nulls
+	// Values holds every value in the column, "" for nulls
+	Values []string
+}
+
+// Title gives the column title
+func (v *StringVector) Title() string { return v.title }
+
+// Type gives "string"
+func (v *StringVector) Type() string { return "string" }
+
+// Len gives the number of values in the vector
+func (v *StringVector) Len() int { return len(v.Values) }
+
+// Value gives the value at index i as a string, nil for null
+func (v *StringVector) Value(i int) interface{} {
+	if v.IsNull(i) {
+		return nil
+	}
+	return v.Values[i]
+}
+
+func (v *StringVector) push(val interface{}) bool {
+	if val == nil {
+		v.set(len(v.Values))
+		v.Values = append(v.Values, "")
+		return true
+	}
+	s, ok := val.(string)
+	if !ok {
+		return false
+	}
+	v.Values = append(v.Values, s)
+	return true
+}
+
+// AnyVector is a column of boxed values, used for columns that allow more
+// than one type & columns with values that don't match their schema type
+type AnyVector struct {
+	title string
+	// Values holds every value in the column
+	Values []interface{}
+}
+
+// Title gives the column title
+func (v *AnyVector) Title() string { return v.title }
+
+// Type gives "any"
+func (v *AnyVector) Type() string { return "any" }
+
+// Len gives the number of values in the vector
+func (v *AnyVector) Len() int { return len(v.Values) }
+
+// IsNull reports whether the value at index i is null
+func (v *AnyVector) IsNull(i int) bool { return v.Values[i] == nil }
+
+// Value gives the value at index i
+func (v *AnyVector) Value(i int) interface{} { return v.Values[i] }
+
+func (v *AnyVector) push(val interface{}) bool {
+	v.Values = append(v.Values, val)
+	return true
+}
+
+// newVector creates an empty vector for a tabular column
+func newVector(col tabular.Column) Vector {
+	var types []string
+	if col.Type != nil {
+		for _, t := range *col.Type {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+	}
+	if len(types) == 1 {
+		switch types[0] {
+		case "integer":
+			return &IntVector{title: col.Title}
+		case "number":
+			return &FloatVector{title: col.Title}
+		case "boolean":
+			return &BoolVector{title: col.Title}
+		case "string":
+			return &StringVector{title: col.Title}
+		}
+	}
+	return &AnyVector{title: col.Title}
+}
+
+// boxVector copies the values of v into an AnyVector
+func boxVector(v Vector) *AnyVector {
+	boxed := &AnyVector{title: v.Title(), Values: make([]interface{}, v.Len())}
+	for i := range boxed.Values {
+		boxed.Values[i] = v.Value(i)
+	}
+	return boxed
+}
+
+// Table is a tabular body held in memory as one typed vector per column.
+// Repeated passes over a table avoid decoding rows & boxing values. Table
+// is an EntryWriter, use Copy to fill a table from an EntryReader
+type Table struct {
+	st      *dataset.Structure
+	Columns []Vector
+	rows    int
+}
+
+var _ EntryWriter = (*Table)(nil)
+
+// NewTable creates an empty table with a vector for each column of a
+// structure's tabular schema
+func NewTable(st *dataset.Structure) (*Table, error) {
+	if st == nil {
+		return nil, fmt.Errorf("columnar: structure is required")
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("columnar: %w", err)
+	}
+	t := &Table{st: st, Columns: make([]Vector, len(cols))}
+	for i, col := range cols {
+		t.Columns[i] = newVector(col)
+	}
+	return t, nil
+}
+
+// ReadTable reads all entries of r into a table, closing r
+func ReadTable(r EntryReader) (*Table, error) {
+	t, err := NewTable(r.Structure())
+	if err != nil {
+		return nil, err
+	}
+	if err := Copy(r, t); err != nil {
+		return nil, err
+	}
+	return t, r.Close()
+}
+
+// Structure gives the table structure
+func (t *Table) Structure() *dataset.Structure {
+	return t.st
+}
+
+// Len gives the number of rows in the table
+func (t *Table) Len() int {
+	return t.rows
+}
+
+// Column gets a column vector by title
+func (t *Table) Column(title string) (Vector, bool) {
+	for _, v := range t.Columns {
+		if v.Title() == title {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Append adds a row to the table. Rows shorter than the table are padded
+// with null values. A value that doesn't fit a typed vector, like a string
+// left in place of an invalid number or an integer in a number column,
+// converts the column to an AnyVector that keeps values as they are
+func (t *Table) Append(row []interface{}) error {
+	if len(row) > len(t.Columns) {
+		return fmt.Errorf("columnar: row %d has %d values, table has %d columns", t.rows, len(row), len(t.Columns))
+	}
+	for i, v := range t.Columns {
+		var val interface{}
+		if i < len(row) {
+			val = row[i]
+		}
+		if !v.push(val) {
+			boxed := boxVector(v)
+			boxed.push(val)
+			t.Columns[i] = boxed
+		}
+	}
+	t.rows++
+	return nil
+}
+
+// WriteEntry appends an array row entry to the table
+func (t *Table) WriteEntry(ent Entry) error {
+	row, ok := ent.Value.([]interface{})
+	if !ok {
+		return fmt.Errorf("columnar: entry %d is not an array row", t.rows)
+	}
+	return t.Append(row)
+}
+
+// Close implements the EntryWriter interface. Tables don't need closing
+func (t *Table) Close() error {
+	return nil
+}
+
+// Row gives the values of row i
+func (t *Table) Row(i int) []interface{} {
+	row := make([]interface{}, len(t.Columns))
+	for j, v := range t.Columns {
+		row[j] = v.Value(i)
+	}
+	return row
+}
+
+// Reader reads the rows of the table as entries
+func (t *Table) Reader() EntryReader {
+	return &tableReader{t: t}
+}
+
+// tableReader reads table rows
+type tableReader struct {
+	t *Table
+	i int
+}
+
+var _ EntryReader = (*tableReader)(nil)
+
+// Structure gives the table structure
+func (r *tableReader) Structure() *dataset.Structure {
+	return r.t.st
+}
+
+// ReadEntry reads the next table row
+func (r *tableReader) ReadEntry() (Entry, error) {
+	if r.i >= r.t.rows {
+		return Entry{}, io.EOF
+	}
+	ent := Entry{Index: r.i, Value: r.t.Row(r.i)}
+	r.i++
+	return ent, nil
+}
+
+// Close implements the EntryReader interface
+func (r *tableReader) Close() error {
+	return nil
+}
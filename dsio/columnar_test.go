@@ -0,0 +1,106 @@
+package dsio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestTable(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "stop_id", "type": "string"},
+					map[string]interface{}{"title": "capacity", "type": []interface{}{"integer", "null"}},
+					map[string]interface{}{"title": "lat", "type": "number"},
+					map[string]interface{}{"title": "sheltered", "type": "boolean"},
+					map[string]interface{}{"title": "zone", "type": "integer"},
+					map[string]interface{}{"title": "extra", "type": []interface{}{"string", "integer"}},
+					map[string]interface{}{"title": "depth", "type": "number"},
+					map[string]interface{}{"title": "count", "type": "integer"},
+				},
+			},
+		},
+	}
+	src, err := NewJSONReader(st, strings.NewReader(`[
+		["a", 12, 48.7, true, 1, "x", 1.5, 3],
+		["b", null, 9.25, false, "A", 2, 2, 4.5],
+		["c"]
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl, err := ReadTable(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl.Len() != 3 {
+		t.Fatalf("expected 3 rows, got: %d", tbl.Len())
+	}
+
+	types := make([]string, len(tbl.Columns))
+	for i, v := range tbl.Columns {
+		types[i] = v.Type()
+	}
+	if expect := []string{"string", "integer", "number", "boolean", "any", "any", "any", "any"}; !reflect.DeepEqual(expect, types) {
+		t.Errorf("column types mismatch. expected: %v, got: %v", expect, types)
+	}
+
+	capacity, ok := tbl.Column("capacity")
+	if !ok {
+		t.Fatal("expected capacity column")
+	}
+	if iv := capacity.(*IntVector); !reflect.DeepEqual(iv.Values, []int64{12, 0, 0}) || iv.IsNull(0) || !iv.IsNull(1) || !iv.IsNull(2) {
+		t.Errorf("capacity vector mismatch: %v", iv)
+	}
+	lat, _ := tbl.Column("lat")
+	if fv := lat.(*FloatVector); !reflect.DeepEqual(fv.Values, []float64{48.7, 9.25, 0}) {
+		t.Errorf("lat vector mismatch: %v", fv.Values)
+	}
+	if _, ok := tbl.Column("missing"); ok {
+		t.Errorf("expected missing column to not be found")
+	}
+
+	expect := []interface{}{
+		[]interface{}{"a", int64(12), 48.7, true, int64(1), "x", 1.5, int64(3)},
+		[]interface{}{"b", nil, 9.25, false, "A", int64(2), int64(2), 4.5},
+		[]interface{}{"c", nil, nil, nil, nil, nil, nil, nil},
+	}
+	got, err := readValues(tbl.Reader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("rows mismatch.\nexpected: %v\ngot:      %v", expect, got)
+	}
+	if tbl.Reader().Structure() != st {
+		t.Errorf("expected table reader to use the table structure")
+	}
+}
+
+func TestTableErrors(t *testing.T) {
+	if _, err := NewTable(nil); err == nil || err.Error() != "columnar: structure is required" {
+		t.Errorf("expected missing structure error, got: %v", err)
+	}
+	if _, err := NewTable(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}); err == nil {
+		t.Errorf("expected non-tabular schema error")
+	}
+
+	tbl, err := NewTable(stringCSVReader(t, "", "a", "b").Structure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Append([]interface{}{"1", "2", "3"}); err == nil || err.Error() != "columnar: row 0 has 3 values, table has 2 columns" {
+		t.Errorf("expected row length error, got: %v", err)
+	}
+	if err := tbl.WriteEntry(Entry{Value: "a"}); err == nil || err.Error() != "columnar: entry 0 is not an array row" {
+		t.Errorf("expected array row error, got: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// ConvertConfig configures format conversion
+type ConvertConfig struct {
+	// FormatConfig sets format configuration of the converted body, like the
+	// separator of a CSV body. Values are merged over the defaults
+	FormatConfig map[string]interface{}
+}
+
+// WithConvertFormatConfig sets format configuration of a converted body
+func WithConvertFormatConfig(cfg map[string]interface{}) func(*ConvertConfig) {
+	return func(c *ConvertConfig) {
+		c.FormatConfig = cfg
+	}
+}
+
+// convertFormats are the formats bodies can be converted to
+var convertFormats = map[dataset.DataFormat]bool{
+	dataset.CBORDataFormat: true,
+	dataset.CSVDataFormat:  true,
+	dataset.JSONDataFormat: true,
+	dataset.XLSXDataFormat: true,
+}
+
+// ConvertStructure gives the structure of a body described by st converted
+// to target. The schema is kept, so converting to CSV or XLSX requires a
+// tabular schema. Format configuration is rewritten for the target format,
+// writing CSV header rows & keeping decimal number decoding between CSV &
+// JSON. Values that depend on body bytes like checksum & length are dropped
+func ConvertStructure(st *dataset.Structure, target dataset.DataFormat, opts ...func(*ConvertConfig)) (*dataset.Structure, error) {
+	if st == nil {
+		return nil, fmt.Errorf("convert: structure is required")
+	}
+	if !convertFormats[target] {
+		return nil, fmt.Errorf("convert: cannot convert to %q", target.String())
+	}
+	if st.Compression != "" {
+		return nil, fmt.Errorf("convert: cannot convert %s compressed bodies", st.Compression)
+	}
+	cfg := &ConvertConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if target == dataset.CSVDataFormat || target == dataset.XLSXDataFormat {
+		if _, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err != nil {
+			return nil, fmt.Errorf("convert: %s requires a tabular schema: %w", target, err)
+		}
+	}
+
+	src := st.Clone()
+	out := &dataset.Structure{
+		CRS:      src.CRS,
+		Depth:    src.Depth,
+		Entries:  src.Entries,
+		Format:   target.String(),
+		Geometry: src.Geometry,
+		Qri:      dataset.KindStructure.String(),
+		Schema:   src.Schema,
+		Strict:   src.Strict,
+	}
+	if target != dataset.CBORDataFormat {
+		out.Encoding = src.Encoding
+	}
+
+	fc := map[string]interface{}{}
+	if st.DataFormat() == target {
+		for k, v := range src.FormatConfig {
+			fc[k] = v
+		}
+	} else {
+		if target == dataset.CSVDataFormat {
+			fc["headerRow"] = true
+		}
+		if target == dataset.CSVDataFormat || target == dataset.JSONDataFormat {
+			if dn, ok := src.FormatConfig["decimalNumbers"]; ok {
+				fc["decimalNumbers"] = dn
+			}
+		}
+	}
+	for k, v := range cfg.FormatConfig {
+		fc[k] = v
+	}
+	if len(fc) > 0 {
+		if _, err := dataset.ParseFormatConfigMap(target, fc); err != nil {
+			return nil, fmt.Errorf("convert: formatConfig: %w", err)
+		}
+		out.FormatConfig = fc
+	}
+	return out, nil
+}
+
+// ConvertTo converts a body described by st to target as it's read,
+// returning the converted body's structure & a reader of the converted
+// bytes. Closing the reader stops conversion & closes body if it's an
+// io.Closer. Bodies that are already in the target format with no format
+// configuration changes are passed through unchanged
+func ConvertTo(st *dataset.Structure, target dataset.DataFormat, body io.Reader, opts ...func(*ConvertConfig)) (*dataset.Structure, io.ReadCloser, error) {
+	out, err := ConvertStructure(st, target, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := &ConvertConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if st.DataFormat() == target && cfg.FormatConfig == nil {
+		if rc, ok := body.(io.ReadCloser); ok {
+			return out, rc, nil
+		}
+		return out, ioutil.NopCloser(body), nil
+	}
+
+	r, err := NewEntryReader(st, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert: %w", err)
+	}
+	pr, pw := io.Pipe()
+	w, err := NewEntryWriter(out, pw)
+	if err != nil {
+		r.Close()
+		return nil, nil, fmt.Errorf("convert: %w", err)
+	}
+	go func() {
+		err := Copy(r, w)
+		if err == nil {
+			err = w.Close()
+		}
+		r.Close()
+		pw.CloseWithError(err)
+	}()
+	return out, &convertReader{PipeReader: pr, body: body}, nil
+}
+
+// convertReader reads converted bytes, closing the source body on close
+type convertReader struct {
+	*io.PipeReader
+	body io.Reader
+}
+
+// Close stops conversion & closes the source body
+func (r *convertReader) Close() error {
+	err := r.PipeReader.Close()
+	if c, ok := r.body.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// ConvertToSource converts a stored body back to the format it was given in,
+// recorded by the structure's Source. Bodies without a source format are
+// passed through unchanged. The returned structure describes the converted
+// body, and has no source format
+func ConvertToSource(st *dataset.Structure, body io.Reader) (*dataset.Structure, io.ReadCloser, error) {
+	if st == nil {
+		return nil, nil, fmt.Errorf("convert: structure is required")
+	}
+	src := st.Source
+	if src == nil {
+		out := st.Clone()
+		if rc, ok := body.(io.ReadCloser); ok {
+			return out, rc, nil
+		}
+		return out, ioutil.NopCloser(body), nil
+	}
+	var opts []func(*ConvertConfig)
+	if src.FormatConfig != nil {
+		opts = append(opts, WithConvertFormatConfig(src.FormatConfig))
+	}
+	out, rc, err := ConvertTo(st, src.DataFormat(), body, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	out.Encoding = src.Encoding
+	return out, rc, nil
+}
@@ -0,0 +1,159 @@
+package dsio
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestConvertTo(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "stop_id", "type": "string"},
+				map[string]interface{}{"title": "capacity", "type": "integer"},
+			},
+		},
+	}
+	csvSt := &dataset.Structure{
+		Checksum:     "QmChecksum",
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true, "lazyQuotes": true, "decimalNumbers": true},
+		Length:       31,
+		Schema:       schema,
+	}
+	csvBody := "stop_id,capacity\na,1\nb,2\n"
+
+	st, r, err := ConvertTo(csvSt, dataset.JSONDataFormat, strings.NewReader(csvBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if string(data) != `[["a",1],["b",2]]` {
+		t.Errorf("json body mismatch. got: %s", data)
+	}
+	stData, _ := json.Marshal(st)
+	if expect := `{"format":"json","formatConfig":{"decimalNumbers":true},"qri":"st:0","schema":{"items":{"items":[{"title":"stop_id","type":"string"},{"title":"capacity","type":"integer"}],"type":"array"},"type":"array"}}`; string(stData) != expect {
+		t.Errorf("structure mismatch.\nexpected: %s\ngot:      %s", expect, stData)
+	}
+
+	// round trip through cbor
+	cborSt, r, err := ConvertTo(st, dataset.CBORDataFormat, strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backSt, back, err := ConvertTo(cborSt, dataset.CSVDataFormat, r, WithConvertFormatConfig(map[string]interface{}{"separator": ";"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadAll(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back.Close()
+	if string(data) != "stop_id;capacity\na;1\nb;2\n" {
+		t.Errorf("csv round trip mismatch. got: %q", data)
+	}
+	if backSt.FormatConfig["headerRow"] != true {
+		t.Errorf("expected csv conversion to write a header row, got: %v", backSt.FormatConfig)
+	}
+
+	// same format passes through
+	_, r, err = ConvertTo(csvSt, dataset.CSVDataFormat, strings.NewReader(csvBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(r); string(data) != csvBody {
+		t.Errorf("expected unchanged body, got: %q", data)
+	}
+
+	// closing early stops conversion
+	_, r, err = ConvertTo(csvSt, dataset.JSONDataFormat, strings.NewReader(csvBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// source read errors surface from the converted reader
+	_, r, err = ConvertTo(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}, dataset.CBORDataFormat, strings.NewReader(`[1,`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); err == nil {
+		t.Errorf("expected error converting an invalid body")
+	}
+}
+
+func TestConvertStructureErrors(t *testing.T) {
+	cases := []struct {
+		st     *dataset.Structure
+		target dataset.DataFormat
+		opts   []func(*ConvertConfig)
+		err    string
+	}{
+		{nil, dataset.JSONDataFormat, nil, "convert: structure is required"},
+		{&dataset.Structure{Format: "json"}, dataset.PBFDataFormat, nil, `convert: cannot convert to "pbf"`},
+		{&dataset.Structure{Format: "json", Compression: "gzip"}, dataset.CBORDataFormat, nil, "convert: cannot convert gzip compressed bodies"},
+		{&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}, dataset.CSVDataFormat, nil, "convert: csv requires a tabular schema: unfinished"},
+		{stringCSVReader(t, "", "a").Structure(), dataset.XLSXDataFormat, []func(*ConvertConfig){WithConvertFormatConfig(map[string]interface{}{"sheetName": 1})}, "convert: formatConfig: invalid sheetName value: 1"},
+	}
+	for i, c := range cases {
+		_, err := ConvertStructure(c.st, c.target, c.opts...)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestConvertToSource(t *testing.T) {
+	stored := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": []interface{}{map[string]interface{}{"title": "stop_id", "type": "string"}},
+			},
+		},
+		Source: &dataset.SourceFormat{Encoding: "UTF-8", Format: "csv", FormatConfig: map[string]interface{}{"headerRow": false}},
+	}
+	st, r, err := ConvertToSource(stored, strings.NewReader(`[["a"],["b"]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if string(data) != "a\nb\n" {
+		t.Errorf("csv body mismatch. got: %q", data)
+	}
+	if st.Format != "csv" || st.Encoding != "UTF-8" || st.Source != nil {
+		t.Errorf("expected the structure of the source format, got: %#v", st)
+	}
+
+	// structures without a source pass through
+	st, r, err = ConvertToSource(&dataset.Structure{Format: "json"}, strings.NewReader(`[1]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(r); string(data) != `[1]` || st.Format != "json" {
+		t.Errorf("expected an unchanged body, got: %q", data)
+	}
+
+	if _, _, err := ConvertToSource(nil, nil); err == nil {
+		t.Error("expected a nil structure to error")
+	}
+}
@@ -1,11 +1,13 @@
 package dsio
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsio/replacecr"
@@ -17,45 +19,79 @@ import (
 type CSVReader struct {
 	st         *dataset.Structure
 	readHeader bool
-	r          *csv.Reader
-
-	// TODO (b5) - this will create problems if users define schemas that support
-	// mutiple types per column. Should replace with a tabular.Columns field
-	types []string
+	r          csvRecordReader
+	cells      *CSVCellDecoder
+	lend       bool
+	// rows is the number of rows read, not counting the header. rows are
+	// counted separately from lines: quoted cells may span lines
+	rows int
+	// preamble is skipped before the first read
+	preamble       preambleReader
+	skipBlankLines bool
+	commentPrefix  string
+	skipped        bool
+	norm           Normalization
 }
 
-var _ EntryReader = (*CSVReader)(nil)
+var (
+	_ EntryReader = (*CSVReader)(nil)
+	_ Normalizer  = (*CSVReader)(nil)
+)
 
-// NewCSVReader creates a reader from a structure and read source
+// NewCSVReader creates a reader from a structure and read source. Given a
+// MappedFile, the reader parses the unread contents of the file in place,
+// slicing string values out of the file's memory instead of copying them.
+// A leading byte order mark is skipped, as are leading blank & comment lines
+// if the format config asks for it, see Normalization
 func NewCSVReader(st *dataset.Structure, r io.Reader) (*CSVReader, error) {
-	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
-	if err != nil {
+	if _, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err != nil {
 		return nil, err
 	}
 
-	types := make([]string, len(cols))
-	for i, c := range cols {
-		types[i] = []string(*c.Type)[0]
-	}
-
-	csvr := csv.NewReader(replacecr.Reader(r))
-
+	var (
+		lazy, variadic bool
+		comma          = ','
+	)
 	if fopts, err := dataset.ParseFormatConfigMap(dataset.CSVDataFormat, st.FormatConfig); err == nil {
 		if opts, ok := fopts.(*dataset.CSVOptions); ok {
-			csvr.LazyQuotes = opts.LazyQuotes
-			if opts.VariadicFields == true {
-				csvr.FieldsPerRecord = -1
-			}
+			lazy = opts.LazyQuotes
+			variadic = opts.VariadicFields
 			if opts.Separator != rune(0) {
-				csvr.Comma = opts.Separator
+				comma = opts.Separator
 			}
 		}
 	}
 
+	var (
+		records  csvRecordReader
+		preamble preambleReader
+	)
+	if mf, ok := r.(*MappedFile); ok {
+		mr := newMappedCSVReader(mf.unread(), comma, lazy, variadic)
+		records, preamble = mr, mr
+	} else {
+		br := bufio.NewReader(r)
+		preamble = br
+		csvr := csv.NewReader(replacecr.Reader(br))
+		// records are always decoded into new rows, so the record slice can be
+		// reused between reads
+		csvr.ReuseRecord = true
+		csvr.LazyQuotes = lazy
+		if variadic {
+			csvr.FieldsPerRecord = -1
+		}
+		csvr.Comma = comma
+		records = csvr
+	}
+
+	blankLines, comment := preambleOptions(st)
 	return &CSVReader{
-		st:    st,
-		r:     csvr,
-		types: types,
+		st:             st,
+		r:              records,
+		cells:          NewCSVCellDecoder(st),
+		preamble:       preamble,
+		skipBlankLines: blankLines,
+		commentPrefix:  comment,
 	}, nil
 }
 
@@ -64,15 +100,25 @@ func (r *CSVReader) Structure() *dataset.Structure {
 	return r.st
 }
 
+// Normalization gives the changes made to the start of the body, known once
+// the first entry is read
+func (r *CSVReader) Normalization() Normalization {
+	return r.norm
+}
+
 // ReadEntry reads one CSV record from the reader
 func (r *CSVReader) ReadEntry() (Entry, error) {
+	if !r.skipped {
+		r.norm = skipPreamble(r.preamble, r.skipBlankLines, r.commentPrefix)
+		r.skipped = true
+	}
 	if !r.readHeader {
 		if HasHeaderRow(r.st) {
 			if _, err := r.r.Read(); err != nil {
 				if err.Error() != "EOF" {
 					log.Debug(err.Error())
 				}
-				return Entry{}, err
+				return Entry{}, offsetParseError(err, r.norm.Lines())
 			}
 		}
 		r.readHeader = true
@@ -80,6 +126,11 @@ func (r *CSVReader) ReadEntry() (Entry, error) {
 
 	data, err := r.r.Read()
 	if err != nil {
+		if err == io.EOF {
+			return Entry{}, err
+		}
+		// parse errors give the line a row starts on, add the row
+		err = &RowError{Index: r.rows, Err: offsetParseError(err, r.norm.Lines())}
 		log.Debug(err.Error())
 		return Entry{}, err
 	}
@@ -90,7 +141,14 @@ func (r *CSVReader) ReadEntry() (Entry, error) {
 		return Entry{}, err
 	}
 
-	return Entry{Value: value}, nil
+	ent := Entry{Index: r.rows, Value: value}
+	r.rows++
+	return ent, nil
+}
+
+// lendEntries decodes rows into pooled memory. see BorrowEntries
+func (r *CSVReader) lendEntries() {
+	r.lend = true
 }
 
 // Close finalizes the reader
@@ -102,50 +160,166 @@ func (r *CSVReader) Close() error {
 
 // decode uses specified types from structure's schema to cast csv string values to their
 // intended types. If casting fails because the data is invalid, it's left as a string instead
-// of causing an error.
+// of causing an error. Cells matching a configured null value are read as nil in any column
 func (r *CSVReader) decode(strings []string) ([]interface{}, error) {
-	vs := make([]interface{}, len(strings))
-	types := r.types
-	if len(types) < len(strings) {
-		// TODO - fix. for now is types fails to parse we just assume all types
-		// are strings
-		types = make([]string, len(strings))
-		for i := range types {
-			types[i] = "string"
-		}
+	var vs []interface{}
+	if r.lend {
+		vs = borrowRow(len(strings))
+	} else {
+		vs = make([]interface{}, len(strings))
 	}
+	// TODO - fix. for now is types fails to parse we just assume all types
+	// are strings
+	typed := r.cells.Typed(len(strings))
 	for i, str := range strings {
-		vs[i] = str
+		switch {
+		case typed:
+			vs[i] = r.cells.Decode(i, str)
+		case r.cells.IsNull(str):
+			vs[i] = nil
+		default:
+			vs[i] = str
+		}
+	}
+
+	return vs, nil
+}
 
-		switch types[i] {
-		case "number":
-			if num, err := vals.ParseNumber([]byte(str)); err == nil {
-				vs[i] = num
+// CSVCellDecoder decodes CSV cells into the values a CSVReader reads, with
+// the column types & string formats of a structure's tabular schema and the
+// null values, decimal numbers & dialect of it's format config. Packages
+// that read CSV records without a CSVReader decode cells with one to get the
+// same values
+type CSVCellDecoder struct {
+	decimals bool
+	// nulls configures the cell values read as null
+	nulls []func(*vals.CoerceConfig)
+	// locales of localized columns by index. nil without a dialect
+	locales []vals.Locale
+	coerce  [][]func(*vals.CoerceConfig)
+
+	// TODO (b5) - this will create problems if users define schemas that support
+	// mutiple types per column. Should replace with a tabular.Columns field
+	types []string
+	// string formats of each column, used to decode temporal values
+	formats []string
+}
+
+// NewCSVCellDecoder creates a cell decoder for a CSV structure. Structures
+// without a tabular schema have no column types, all their cells are read
+// as strings. Invalid format configs are ignored, as CSVReader ignores them
+func NewCSVCellDecoder(st *dataset.Structure) *CSVCellDecoder {
+	d := &CSVCellDecoder{}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err == nil {
+		d.types = make([]string, len(cols))
+		d.formats = make([]string, len(cols))
+		for i, c := range cols {
+			if c.Type != nil && len(*c.Type) > 0 {
+				d.types[i] = []string(*c.Type)[0]
 			}
-		case "integer":
-			if num, err := vals.ParseInteger([]byte(str)); err == nil {
-				vs[i] = num
+			d.formats[i], _ = c.Validation["format"].(string)
+		}
+	}
+	if fopts, err := dataset.ParseFormatConfigMap(dataset.CSVDataFormat, st.FormatConfig); err == nil {
+		if opts, ok := fopts.(*dataset.CSVOptions); ok {
+			d.decimals = opts.DecimalNumbers
+			if len(opts.NullValues) > 0 {
+				d.nulls = append(d.nulls, vals.WithNullValues(opts.NullValues...))
 			}
-		case "boolean":
-			if b, err := vals.ParseBoolean([]byte(str)); err == nil {
-				vs[i] = b
+			if opts.HasDialect() {
+				d.locales = make([]vals.Locale, len(cols))
+				d.coerce = make([][]func(*vals.CoerceConfig), len(cols))
+				for i, c := range cols {
+					d.locales[i] = opts.Locale(c.Title)
+					d.coerce[i] = []func(*vals.CoerceConfig){vals.WithLocale(d.locales[i])}
+				}
 			}
-		case "object":
-			v := map[string]interface{}{}
-			if err := json.Unmarshal([]byte(str), &v); err == nil {
-				vs[i] = v
+		}
+	}
+	return d
+}
+
+// Typed reports whether cells of a record of width cells are decoded by
+// column type. Records wider than the schema are read as strings
+func (d *CSVCellDecoder) Typed(width int) bool {
+	return width <= len(d.types)
+}
+
+// IsNull checks if a cell matches a configured null value
+func (d *CSVCellDecoder) IsNull(cell string) bool {
+	return d.nulls != nil && vals.IsNull(cell, d.nulls...)
+}
+
+// Decode decodes cell i of a record. Cells that fail to decode for the type of
+// their column are left as strings, cells matching a configured null value
+// are nil in any column
+func (d *CSVCellDecoder) Decode(i int, cell string) interface{} {
+	if d.IsNull(cell) {
+		return nil
+	}
+	if i >= len(d.types) {
+		return cell
+	}
+	var coerce []func(*vals.CoerceConfig)
+	if i < len(d.coerce) {
+		coerce = d.coerce[i]
+	}
+
+	switch d.types[i] {
+	case "string":
+		switch d.formats[i] {
+		case "date", "time", "date-time":
+			if t, err := vals.ParseFormat(d.formats[i], cell); err == nil {
+				return t
 			}
-		case "array":
-			v := []interface{}{}
-			if err := json.Unmarshal([]byte(str), &v); err == nil {
-				vs[i] = v
+		}
+	case "number":
+		if d.decimals {
+			if dec, err := vals.ParseDecimal(d.number(i, cell)); err == nil {
+				return dec
 			}
-		case "null":
-			vs[i] = nil
+		} else if f, err := vals.ToFloat(cell, coerce...); err == nil {
+			return f
+		}
+	case "integer":
+		if n, err := vals.ToInt(cell, coerce...); err == nil {
+			return n
+		} else if d.decimals {
+			// integers too large for int64 are kept exactly as decimals
+			if num := d.number(i, cell); vals.IsInteger([]byte(num)) {
+				if dec, err := vals.ParseDecimal(num); err == nil {
+					return dec
+				}
+			}
+		}
+	case "boolean":
+		if b, err := vals.ToBool(cell, coerce...); err == nil {
+			return b
+		}
+	case "object":
+		v := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(cell), &v); err == nil {
+			return v
 		}
+	case "array":
+		v := []interface{}{}
+		if err := json.Unmarshal([]byte(cell), &v); err == nil {
+			return v
+		}
+	case "null":
+		return nil
 	}
+	return cell
+}
 
-	return vs, nil
+// number gives cell i as number text, without the localized separators of
+// it's column
+func (d *CSVCellDecoder) number(i int, cell string) string {
+	if i < len(d.locales) {
+		return vals.NormalizeNumber(cell, d.locales[i])
+	}
+	return cell
 }
 
 // HasHeaderRow checks Structure for the presence of the HeaderRow flag
@@ -164,14 +338,16 @@ type CSVWriter struct {
 	rowsWritten int
 	w           *csv.Writer
 	st          *dataset.Structure
+	cfg         *WriterConfig
 
 	// TODO (b5) - this will create problems if users define schemas that support
 	// mutiple types per column. Should replace with a tabular.Columns field
 	types []string
 }
 
-// NewCSVWriter creates a Writer from a structure and write destination
-func NewCSVWriter(st *dataset.Structure, w io.Writer) (*CSVWriter, error) {
+// NewCSVWriter creates a Writer from a structure and write destination.
+// Writer options configure how floats & datetimes are formatted
+func NewCSVWriter(st *dataset.Structure, w io.Writer, opts ...func(*WriterConfig)) (*CSVWriter, error) {
 	// TODO - capture error
 	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
 	if err != nil {
@@ -184,21 +360,22 @@ func NewCSVWriter(st *dataset.Structure, w io.Writer) (*CSVWriter, error) {
 	}
 
 	writer := csv.NewWriter(w)
-	opts, err := dataset.NewCSVOptions(st.FormatConfig)
-	if opts != nil && err == nil {
-		if opts.Separator != rune(0) {
-			writer.Comma = opts.Separator
+	csvOpts, err := dataset.NewCSVOptions(st.FormatConfig)
+	if csvOpts != nil && err == nil {
+		if csvOpts.Separator != rune(0) {
+			writer.Comma = csvOpts.Separator
 		}
 	}
 
 	wr := &CSVWriter{
 		st:    st,
 		w:     writer,
+		cfg:   newWriterConfig(opts...),
 		types: types,
 	}
 
-	if opts != nil {
-		if opts.HeaderRow {
+	if csvOpts != nil {
+		if csvOpts.HeaderRow {
 			writer.Write(cols.Titles())
 		}
 	}
@@ -214,18 +391,18 @@ func (w *CSVWriter) Structure() *dataset.Structure {
 // WriteEntry writes one CSV record to the writer
 func (w *CSVWriter) WriteEntry(ent Entry) error {
 	if arr, ok := ent.Value.([]interface{}); ok {
-		strs, err := encode(arr)
+		strs, err := encode(arr, w.cfg)
 		if err != nil {
 			log.Debug(err.Error())
-			return fmt.Errorf("error encoding entry: %s", err.Error())
+			return fmt.Errorf("error encoding entry: %w", err)
 		}
 		return w.w.Write(strs)
 	}
-	return fmt.Errorf("expected array value to write csv row. got: %v", ent)
+	return fmt.Errorf("%w: expected array value to write csv row. got: %v", dataset.ErrSchemaMismatch, ent)
 }
 
 // encode uses specified types from structure's schema to go values to strings
-func encode(vs []interface{}) ([]string, error) {
+func encode(vs []interface{}, cfg *WriterConfig) ([]string, error) {
 	strings := make([]string, len(vs))
 
 	for i, v := range vs {
@@ -238,13 +415,23 @@ func encode(vs []interface{}) ([]string, error) {
 		case int64:
 			strings[i] = strconv.Itoa(int(t))
 		case float64:
-			strings[i] = strconv.FormatFloat(t, 'f', -1, 64)
+			strings[i] = cfg.formatFloat(t)
+		case vals.Decimal:
+			strings[i] = t.String()
+		case vals.Date:
+			strings[i] = t.String()
+		case vals.Time:
+			strings[i] = t.String()
+		case vals.DateTime:
+			strings[i] = cfg.formatTime(t.Time())
+		case time.Time:
+			strings[i] = cfg.formatTime(t)
 		case []interface{}:
-			if data, err := json.Marshal(t); err == nil {
+			if data, err := json.Marshal(cfg.jsonValue(t)); err == nil {
 				strings[i] = string(data)
 			}
 		case map[string]interface{}:
-			if data, err := json.Marshal(t); err == nil {
+			if data, err := json.Marshal(cfg.jsonValue(t)); err == nil {
 				strings[i] = string(data)
 			}
 		case bool:
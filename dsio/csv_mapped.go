@@ -0,0 +1,207 @@
+package dsio
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvRecordReader reads CSV records. both csv.Reader & mappedCSVReader
+// satisfy csvRecordReader
+type csvRecordReader interface {
+	Read() ([]string, error)
+}
+
+// mappedCSVReader parses CSV records from an in-memory string, slicing
+// fields from the source instead of copying them. Only quoted fields that
+// contain escaped quotes or carriage returns are copied. Parsing follows
+// encoding/csv, with solo carriage returns read as line breaks the way
+// replacecr rewrites them for CSVReader
+type mappedCSVReader struct {
+	s     string
+	pos   int
+	comma string
+	lazy  bool
+	// fields follows the semantics of csv.Reader.FieldsPerRecord
+	fields int
+
+	line      int
+	lineStart int
+}
+
+func newMappedCSVReader(s string, comma rune, lazy, variadic bool) *mappedCSVReader {
+	r := &mappedCSVReader{s: s, comma: string(comma), lazy: lazy}
+	if variadic {
+		r.fields = -1
+	}
+	return r
+}
+
+// Read reads one record, skipping empty lines
+func (r *mappedCSVReader) Read() ([]string, error) {
+	for {
+		if r.pos >= len(r.s) {
+			return nil, io.EOF
+		}
+		n := r.lineBreak(r.pos)
+		if n == 0 {
+			break
+		}
+		r.newLine(r.pos + n)
+	}
+
+	start := r.line + 1
+	var record []string
+	for {
+		field, err := r.readField(start)
+		if err != nil {
+			return nil, err
+		}
+		record = append(record, field)
+		if r.isComma(r.pos) {
+			r.pos += len(r.comma)
+			continue
+		}
+		r.newLine(r.pos + r.lineBreak(r.pos))
+		break
+	}
+
+	if r.fields == 0 {
+		r.fields = len(record)
+	} else if r.fields > 0 && len(record) != r.fields {
+		return nil, &csv.ParseError{StartLine: start, Line: start, Column: 1, Err: csv.ErrFieldCount}
+	}
+	return record, nil
+}
+
+func (r *mappedCSVReader) readField(start int) (string, error) {
+	if r.pos < len(r.s) && r.s[r.pos] == '"' {
+		return r.readQuotedField(start)
+	}
+	i := r.pos
+	for i < len(r.s) && r.s[i] != '\n' && r.s[i] != '\r' && !r.isComma(i) {
+		if r.s[i] == '"' && !r.lazy {
+			return "", r.errorAt(start, i, csv.ErrBareQuote)
+		}
+		i++
+	}
+	field := r.s[r.pos:i]
+	r.pos = i
+	return field, nil
+}
+
+func (r *mappedCSVReader) readQuotedField(start int) (string, error) {
+	s := r.s
+	i := r.pos + 1
+	seg := i
+	// buf holds the field once it differs from the source
+	var buf []byte
+	copied := false
+	field := func(end int) string {
+		if !copied {
+			return s[seg:end]
+		}
+		return string(append(buf, s[seg:end]...))
+	}
+
+	for {
+		j := strings.IndexAny(s[i:], "\"\r\n")
+		if j < 0 {
+			if !r.lazy {
+				return "", r.errorAt(start, len(s), csv.ErrQuote)
+			}
+			f := field(len(s))
+			r.pos = len(s)
+			return f, nil
+		}
+		i += j
+
+		switch s[i] {
+		case '\n':
+			i++
+			r.newLine(i)
+			continue
+		case '\r':
+			// quoted line breaks are read as a single newline
+			buf = append(buf, s[seg:i]...)
+			copied = true
+			i++
+			if i < len(s) && s[i] == '\n' {
+				i++
+			}
+			buf = append(buf, '\n')
+			seg = i
+			r.newLine(i)
+			continue
+		}
+
+		switch {
+		case i+1 < len(s) && s[i+1] == '"':
+			// escaped quote
+			buf = append(buf, s[seg:i+1]...)
+			copied = true
+			i += 2
+			seg = i
+		case i+1 == len(s) || s[i+1] == '\n' || s[i+1] == '\r' || r.isComma(i+1):
+			f := field(i)
+			r.pos = i + 1
+			return f, nil
+		case r.lazy:
+			// bare quotes in lazy mode are kept as part of the field
+			i++
+		default:
+			return "", r.errorAt(start, i+1, csv.ErrQuote)
+		}
+	}
+}
+
+// Peek gives the next n unparsed bytes, like bufio.Reader.Peek
+func (r *mappedCSVReader) Peek(n int) ([]byte, error) {
+	if r.pos+n > len(r.s) {
+		return []byte(r.s[r.pos:]), io.EOF
+	}
+	return []byte(r.s[r.pos : r.pos+n]), nil
+}
+
+// Discard skips the next n unparsed bytes, like bufio.Reader.Discard
+func (r *mappedCSVReader) Discard(n int) (int, error) {
+	if r.pos+n > len(r.s) {
+		n = len(r.s) - r.pos
+		r.pos = len(r.s)
+		return n, io.EOF
+	}
+	r.pos += n
+	return n, nil
+}
+
+// lineBreak gives the length of a line break at i, 0 if there isn't one
+func (r *mappedCSVReader) lineBreak(i int) int {
+	if i >= len(r.s) {
+		return 0
+	}
+	switch r.s[i] {
+	case '\n':
+		return 1
+	case '\r':
+		if i+1 < len(r.s) && r.s[i+1] == '\n' {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// newLine records the start of a new line at i
+func (r *mappedCSVReader) newLine(i int) {
+	r.pos = i
+	r.line++
+	r.lineStart = i
+}
+
+func (r *mappedCSVReader) isComma(i int) bool {
+	return strings.HasPrefix(r.s[i:], r.comma)
+}
+
+func (r *mappedCSVReader) errorAt(start, i int, err error) error {
+	return &csv.ParseError{StartLine: start, Line: r.line + 1, Column: i - r.lineStart + 1, Err: err}
+}
@@ -0,0 +1,172 @@
+package dsio
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio/replacecr"
+)
+
+func TestMappedCSVReaderMatchesEncodingCSV(t *testing.T) {
+	cases := []struct {
+		description string
+		data        string
+		comma       rune
+		lazy        bool
+		variadic    bool
+	}{
+		{"simple", "a,b,c\n1,2,3\n", ',', false, false},
+		{"no trailing newline", "a,b\n1,2", ',', false, false},
+		{"empty fields", ",,\na,,\n", ',', false, false},
+		{"empty lines", "\n\na,b\n\n\r\n1,2\n\n", ',', false, false},
+		{"crlf", "a,b\r\n1,2\r\n", ',', false, false},
+		{"solo carriage returns", "a,b\r1,2\r", ',', false, false},
+		{"quoted", `"a","b,c"` + "\n" + `"",""` + "\n", ',', false, false},
+		{"escaped quotes", `"say ""hi""",b` + "\n", ',', false, false},
+		{"quoted line breaks", "\"line\nbreak\",\"crlf\r\nbreak\"\nx,y\n", ',', false, false},
+		{"separator", "a;b;\"c;d\"\n1;2;3\n", ';', false, false},
+		{"multibyte separator", "a→b→c\n1→2→3\n", '→', false, false},
+		{"lazy quotes", "a\"b,\"c\"d\",e\n", ',', true, false},
+		{"lazy unterminated", "a,\"b,c\n", ',', true, false},
+		{"variadic", "a,b,c\n1\n1,2\n", ',', false, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			csvr := csv.NewReader(replacecr.Reader(strings.NewReader(c.data)))
+			csvr.Comma = c.comma
+			csvr.LazyQuotes = c.lazy
+			if c.variadic {
+				csvr.FieldsPerRecord = -1
+			}
+			expect, err := csvr.ReadAll()
+			if err != nil {
+				t.Fatalf("encoding/csv: %s", err)
+			}
+
+			r := newMappedCSVReader(c.data, c.comma, c.lazy, c.variadic)
+			var got [][]string
+			for {
+				rec, err := r.Read()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, rec)
+			}
+			if !reflect.DeepEqual(expect, got) {
+				t.Errorf("records mismatch.\nexpected: %q\ngot:      %q", expect, got)
+			}
+		})
+	}
+}
+
+func TestMappedCSVReaderErrors(t *testing.T) {
+	cases := []struct {
+		data   string
+		err    error
+		line   int
+		column int
+	}{
+		{"a,b\n1,2,3\n", csv.ErrFieldCount, 2, 1},
+		{"a,b\"c\n", csv.ErrBareQuote, 1, 4},
+		{"a,\"b\"c\n", csv.ErrQuote, 1, 6},
+		{"a\n\"b\nc", csv.ErrQuote, 3, 2},
+	}
+	for i, c := range cases {
+		r := newMappedCSVReader(c.data, ',', false, false)
+		var err error
+		for err == nil {
+			_, err = r.Read()
+		}
+		perr := &csv.ParseError{}
+		if !errors.As(err, &perr) || !errors.Is(err, c.err) {
+			t.Errorf("case %d: expected %s parse error, got: %v", i, c.err, err)
+			continue
+		}
+		if perr.Line != c.line || perr.Column != c.column {
+			t.Errorf("case %d: position mismatch. expected line %d column %d, got line %d column %d", i, c.line, c.column, perr.Line, perr.Column)
+		}
+	}
+}
+
+func TestCSVReaderMultilineRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsio_csv_multiline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := "title,notes\r\"alien\",\"first line\rsecond line\"\r\n\r\nsolaris,\"a\n\nb\"\nstalker,x,extra\n"
+	path := filepath.Join(dir, "body.csv")
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "title", "type": "string"},
+					map[string]interface{}{"title": "notes", "type": "string"},
+				},
+			},
+		},
+	}
+	expect := []Entry{
+		{Index: 0, Value: []interface{}{"alien", "first line\nsecond line"}},
+		{Index: 1, Value: []interface{}{"solaris", "a\n\nb"}},
+	}
+
+	sources := map[string]func() io.Reader{
+		"stream": func() io.Reader { return iotest.OneByteReader(strings.NewReader(data)) },
+		"mapped file": func() io.Reader {
+			mf, err := OpenMappedFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return mf
+		},
+	}
+	for name, src := range sources {
+		r, err := NewCSVReader(st, src())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []Entry
+		err = EachEntry(r, func(_ int, ent Entry, _ error) error {
+			got = append(got, ent)
+			return nil
+		})
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("%s: entries mismatch.\nexpected: %#v\ngot:      %#v", name, expect, got)
+		}
+
+		// the failing row is the third entry, starting on line 8
+		rerr := &RowError{}
+		perr := &csv.ParseError{}
+		if !errors.As(err, &rerr) || !errors.As(err, &perr) {
+			t.Errorf("%s: expected a row parse error, got: %v", name, err)
+			continue
+		}
+		if rerr.Index != 2 || perr.StartLine != 8 {
+			t.Errorf("%s: error position mismatch. expected row 2 on line 8, got row %d on line %d", name, rerr.Index, perr.StartLine)
+		}
+		if err.Error() != "error reading row 2: record on line 8: wrong number of fields" {
+			t.Errorf("%s: error message mismatch, got: %s", name, err)
+		}
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"errors"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
 )
 
 const csvData = `col_a,col_b,col_c,col_d,col_3,col_f,col_g
@@ -153,6 +155,216 @@ func TestCSVReaderLazyQuotes(t *testing.T) {
 	}
 }
 
+func TestCSVReaderDecimalNumbers(t *testing.T) {
+	data := `price,count
+19.990000000000000001,92233720368547758070`
+
+	st := &dataset.Structure{
+		Format: "csv",
+		FormatConfig: map[string]interface{}{
+			"headerRow":      true,
+			"decimalNumbers": true,
+		},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "price", "type": "number"},
+					map[string]interface{}{"title": "count", "type": "integer"},
+				},
+			},
+		},
+	}
+
+	rdr, err := NewEntryReader(st, bytes.NewBuffer([]byte(data)))
+	if err != nil {
+		t.Fatalf("error allocating EntryReader: %s", err.Error())
+	}
+	ent, err := rdr.ReadEntry()
+	if err != nil {
+		t.Fatalf("expected no error: %s", err.Error())
+	}
+
+	row := ent.Value.([]interface{})
+	for i, expect := range []string{"19.990000000000000001", "92233720368547758070"} {
+		dec, ok := row[i].(vals.Decimal)
+		if !ok {
+			t.Errorf("index %d expected vals.Decimal, got: %T", i, row[i])
+			continue
+		}
+		if dec.String() != expect {
+			t.Errorf("index %d expected: %s, got: %s", i, expect, dec.String())
+		}
+	}
+
+	strs, err := encode(row, newWriterConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strs[0] != "19.990000000000000001" {
+		t.Errorf("expected decimal to encode exactly, got: %s", strs[0])
+	}
+}
+
+func TestCSVReaderNullValues(t *testing.T) {
+	data := `name,count,flag
+NA,1,true
+alien, - ,
+NULL,,false`
+
+	st := &dataset.Structure{
+		Format: "csv",
+		FormatConfig: map[string]interface{}{
+			"headerRow":  true,
+			"nullValues": []interface{}{"", "NA", "-"},
+		},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "count", "type": "integer"},
+					map[string]interface{}{"title": "flag", "type": "boolean"},
+				},
+			},
+		},
+	}
+
+	rdr, err := NewEntryReader(st, bytes.NewBuffer([]byte(data)))
+	if err != nil {
+		t.Fatalf("error allocating EntryReader: %s", err.Error())
+	}
+	expect := [][]interface{}{
+		{nil, int64(1), true},
+		{"alien", nil, nil},
+		{"NULL", nil, false},
+	}
+	for i, row := range expect {
+		ent, err := rdr.ReadEntry()
+		if err != nil {
+			t.Fatalf("row %d: expected no error: %s", i, err.Error())
+		}
+		if !reflect.DeepEqual(row, ent.Value) {
+			t.Errorf("row %d mismatch. expected: %#v, got: %#v", i, row, ent.Value)
+		}
+	}
+}
+
+func TestCSVReaderDialect(t *testing.T) {
+	data := `name;price;count;active;done
+Brezel;1.234,5;1.000;ja;x
+Laugenstange;0,99;12;nein;`
+
+	st := &dataset.Structure{
+		Format: "csv",
+		FormatConfig: map[string]interface{}{
+			"headerRow":        true,
+			"separator":        ";",
+			"trueValues":       []interface{}{"ja"},
+			"falseValues":      []interface{}{"nein"},
+			"decimalSeparator": ",",
+			"groupSeparator":   ".",
+			"columns": map[string]interface{}{
+				"done": map[string]interface{}{"trueValues": []interface{}{"x"}, "falseValues": []interface{}{""}},
+			},
+		},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "price", "type": "number"},
+					map[string]interface{}{"title": "count", "type": "integer"},
+					map[string]interface{}{"title": "active", "type": "boolean"},
+					map[string]interface{}{"title": "done", "type": "boolean"},
+				},
+			},
+		},
+	}
+
+	rdr, err := NewEntryReader(st, bytes.NewBuffer([]byte(data)))
+	if err != nil {
+		t.Fatalf("error allocating EntryReader: %s", err.Error())
+	}
+	expect := [][]interface{}{
+		{"Brezel", 1234.5, int64(1000), true, true},
+		{"Laugenstange", 0.99, int64(12), false, false},
+	}
+	for i, row := range expect {
+		ent, err := rdr.ReadEntry()
+		if err != nil {
+			t.Fatalf("row %d: expected no error: %s", i, err.Error())
+		}
+		if !reflect.DeepEqual(row, ent.Value) {
+			t.Errorf("row %d mismatch. expected: %#v, got: %#v", i, row, ent.Value)
+		}
+	}
+
+	st.FormatConfig["decimalNumbers"] = true
+	rdr, err = NewEntryReader(st, bytes.NewBuffer([]byte(data)))
+	if err != nil {
+		t.Fatalf("error allocating EntryReader: %s", err.Error())
+	}
+	ent, err := rdr.ReadEntry()
+	if err != nil {
+		t.Fatalf("expected no error: %s", err.Error())
+	}
+	if dec, ok := ent.Value.([]interface{})[1].(vals.Decimal); !ok || dec.String() != "1234.5" {
+		t.Errorf("expected localized decimal, got: %#v", ent.Value.([]interface{})[1])
+	}
+}
+
+func TestCSVCellDecoder(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "csv",
+		FormatConfig: map[string]interface{}{
+			"nullValues":       []interface{}{"NA"},
+			"decimalSeparator": ",",
+			"trueValues":       []interface{}{"ja"},
+		},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "price", "type": "number"},
+					map[string]interface{}{"title": "active", "type": "boolean"},
+				},
+			},
+		},
+	}
+	d := NewCSVCellDecoder(st)
+	cases := []struct {
+		i      int
+		cell   string
+		expect interface{}
+	}{
+		{0, "Brezel", "Brezel"},
+		{0, "NA", nil},
+		{1, "0,99", 0.99},
+		{1, "teuer", "teuer"},
+		{2, "ja", true},
+		{3, "1", "1"},
+	}
+	for _, c := range cases {
+		if got := d.Decode(c.i, c.cell); !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("decoding cell %d %q mismatch. expected: %#v, got: %#v", c.i, c.cell, c.expect, got)
+		}
+	}
+	if !d.Typed(3) || d.Typed(4) {
+		t.Errorf("expected only records as wide as the schema to be typed")
+	}
+
+	untyped := NewCSVCellDecoder(&dataset.Structure{Format: "csv"})
+	if got := untyped.Decode(0, "1"); got != "1" {
+		t.Errorf("expected cells without a schema to decode as strings, got: %#v", got)
+	}
+}
+
 func TestTSVReader(t *testing.T) {
 	// data separated with tabs, has variadic fields per record, and odd quoting
 	// bascially, a trash TSV file that can still parse with lots of CSVOption relaxing
@@ -300,7 +512,7 @@ func BenchmarkCSVReader(b *testing.B) {
 	st := &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray}
 
 	for n := 0; n < b.N; n++ {
-		file, err := os.Open(testdataFile("../dsio/testdata/movies/body.csv"))
+		file, err := os.Open("testdata/movies/body.csv")
 		if err != nil {
 			b.Errorf("unexpected error: %s", err.Error())
 		}
@@ -0,0 +1,101 @@
+package dsio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// NewDatasetReader reads the body entries of a dataset, opening the body
+// with Dataset.BodyReader so stored bodies are fetched from resolver on
+// demand. A structure that's only a reference is loaded from resolver &
+// assigned to the dataset, so later readers don't load it again. Inline
+// bodies are read as JSON, and bodies stored as shards are read with a
+// PartitionReader. Closing the reader closes the body
+func NewDatasetReader(ctx context.Context, ds *dataset.Dataset, resolver qfs.PathResolver) (EntryReader, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("dataset is required")
+	}
+	if ds.Structure == nil {
+		return nil, fmt.Errorf("dataset has no structure")
+	}
+	if ds.Structure.IsEmpty() && ds.Structure.Path != "" {
+		st, err := loadStructure(ctx, resolver, ds.Structure.Path)
+		if err != nil {
+			return nil, err
+		}
+		ds.Structure = st
+	}
+
+	if ds.Body != nil {
+		data, err := json.Marshal(ds.Body)
+		if err != nil {
+			return nil, err
+		}
+		st := &dataset.Structure{Format: dataset.JSONDataFormat.String(), Schema: ds.Structure.Schema}
+		return NewJSONReader(st, bytes.NewReader(data))
+	}
+
+	if ds.BodyPath == "" && ds.BodyBytes == nil && ds.BodyFile() == nil && len(ds.BodyShards) > 0 {
+		if resolver == nil {
+			return nil, dataset.ErrNoResolver
+		}
+		return NewPartitionReader(ds.Structure, ds.BodyShards, func(s *dataset.BodyShard) (io.Reader, error) {
+			return resolver.Get(ctx, s.Path)
+		})
+	}
+
+	f, err := ds.BodyReader(ctx, resolver)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewEntryReader(ds.Structure, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &closingReader{EntryReader: r, body: f}, nil
+}
+
+// loadStructure reads a stored structure, setting it's path
+func loadStructure(ctx context.Context, resolver qfs.PathResolver, path string) (*dataset.Structure, error) {
+	if resolver == nil {
+		return nil, dataset.ErrNoResolver
+	}
+	f, err := resolver.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("loading structure '%s': %w", path, err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("loading structure '%s': %w", path, err)
+	}
+	st := &dataset.Structure{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("loading structure '%s': %w", path, err)
+	}
+	st.Path = path
+	return st, nil
+}
+
+// closingReader closes a body file along with the entry reader reading it
+type closingReader struct {
+	EntryReader
+	body io.Closer
+}
+
+// Close closes the entry reader & body
+func (r *closingReader) Close() error {
+	err := r.EntryReader.Close()
+	if cerr := r.body.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package dsio
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+)
+
+func TestNewDatasetReader(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	body, err := store.Put(ctx, qfs.NewMemfileBytes("body.json", []byte(`[["a",1],["b",2]]`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stData, _ := json.Marshal(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray})
+	stPath, err := store.Put(ctx, qfs.NewMemfileBytes("structure.json", stData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := &dataset.Dataset{BodyPath: body, Structure: dataset.NewStructureRef(stPath)}
+	r, err := NewDatasetReader(ctx, ds, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{[]interface{}{"a", int64(1)}, []interface{}{"b", int64(2)}}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("entries mismatch. expected: %v, got: %v", expect, got)
+	}
+	if ds.Structure.Format != "json" || ds.Structure.Path != stPath {
+		t.Errorf("expected the structure reference to be loaded, got: %#v", ds.Structure)
+	}
+
+	// inline bodies are read as json
+	ds = &dataset.Dataset{Body: []interface{}{"x"}, Structure: &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray}}
+	if r, err = NewDatasetReader(ctx, ds, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = readValues(r); err != nil || !reflect.DeepEqual([]interface{}{"x"}, got) {
+		t.Errorf("inline entries mismatch. got: %v %v", got, err)
+	}
+}
+
+func TestNewDatasetReaderErrors(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		ds  *dataset.Dataset
+		err string
+	}{
+		{nil, "dataset is required"},
+		{&dataset.Dataset{}, "dataset has no structure"},
+		{&dataset.Dataset{Structure: dataset.NewStructureRef("/map/QmSt")}, "no resolver available to fetch path"},
+		{&dataset.Dataset{Structure: &dataset.Structure{Format: "json"}}, "dataset has no body"},
+	}
+	for i, c := range cases {
+		_, err := NewDatasetReader(ctx, c.ds, nil)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
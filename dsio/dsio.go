@@ -5,12 +5,9 @@ import (
 	"fmt"
 	"io"
 
-	logger "github.com/ipfs/go-log"
 	"github.com/qri-io/dataset"
 )
 
-var log = logger.Logger("dsio")
-
 // EntryWriter is a generalized interface for writing structured data
 type EntryWriter interface {
 	// Structure gives the structure being written
@@ -49,6 +46,15 @@ type EntryReadWriter interface {
 
 // NewEntryReader allocates a EntryReader based on a given structure
 func NewEntryReader(st *dataset.Structure, r io.Reader) (EntryReader, error) {
+	if m := currentMetrics(); m != nil && m.Read != nil {
+		return meterReader(m, st, r, func(r io.Reader) (EntryReader, error) {
+			return newEntryReader(st, r)
+		})
+	}
+	return newEntryReader(st, r)
+}
+
+func newEntryReader(st *dataset.Structure, r io.Reader) (EntryReader, error) {
 	switch st.DataFormat() {
 	case dataset.CBORDataFormat:
 		return NewCBORReader(st, r)
@@ -58,34 +64,46 @@ func NewEntryReader(st *dataset.Structure, r io.Reader) (EntryReader, error) {
 		return NewCSVReader(st, r)
 	case dataset.XLSXDataFormat:
 		return NewXLSXReader(st, r)
+	case dataset.PBFDataFormat:
+		return NewPBFReader(st, r)
 	case dataset.UnknownDataFormat:
-		err := fmt.Errorf("structure must have a data format")
+		err := &FormatError{Op: "reader"}
 		log.Debug(err.Error())
 		return nil, err
 	default:
-		err := fmt.Errorf("invalid format to create reader: %s", st.Format)
+		err := &FormatError{Op: "reader", Format: st.Format}
 		log.Debug(err.Error())
 		return nil, err
 	}
 }
 
-// NewEntryWriter allocates a EntryWriter based on a given structure
-func NewEntryWriter(st *dataset.Structure, w io.Writer) (EntryWriter, error) {
+// NewEntryWriter allocates a EntryWriter based on a given structure. Writer
+// options configure value formatting, see WriterConfig
+func NewEntryWriter(st *dataset.Structure, w io.Writer, opts ...func(*WriterConfig)) (EntryWriter, error) {
+	if m := currentMetrics(); m != nil && m.Write != nil {
+		return meterWriter(m, st, w, func(w io.Writer) (EntryWriter, error) {
+			return newEntryWriter(st, w, opts...)
+		})
+	}
+	return newEntryWriter(st, w, opts...)
+}
+
+func newEntryWriter(st *dataset.Structure, w io.Writer, opts ...func(*WriterConfig)) (EntryWriter, error) {
 	switch st.DataFormat() {
 	case dataset.CBORDataFormat:
-		return NewCBORWriter(st, w)
+		return NewCBORWriter(st, w, opts...)
 	case dataset.JSONDataFormat:
-		return NewJSONWriter(st, w)
+		return NewJSONWriter(st, w, opts...)
 	case dataset.CSVDataFormat:
-		return NewCSVWriter(st, w)
+		return NewCSVWriter(st, w, opts...)
 	case dataset.XLSXDataFormat:
 		return NewXLSXWriter(st, w)
 	case dataset.UnknownDataFormat:
-		err := fmt.Errorf("structure must have a data format")
+		err := &FormatError{Op: "writer"}
 		log.Debug(err.Error())
 		return nil, err
 	default:
-		err := fmt.Errorf("invalid format to create writer: %s", st.Format)
+		err := &FormatError{Op: "writer", Format: st.Format}
 		log.Debug(err.Error())
 		return nil, err
 	}
@@ -96,14 +114,14 @@ func NewEntryWriter(st *dataset.Structure, w io.Writer) (EntryWriter, error) {
 func GetTopLevelType(st *dataset.Structure) (string, error) {
 	// tlt := st.Schema.TopLevelType()
 	if st.Schema == nil {
-		return "", fmt.Errorf("a schema object is required")
+		return "", fmt.Errorf("%w: a schema object is required", dataset.ErrInvalidSchema)
 	}
 	tlt, ok := st.Schema["type"].(string)
 	if !ok {
-		return "", fmt.Errorf("schema top level 'type' value must be either 'array' or 'object'")
+		return "", fmt.Errorf("%w: schema top level 'type' value must be either 'array' or 'object'", dataset.ErrInvalidSchema)
 	}
 	if tlt != "array" && tlt != "object" {
-		return "", fmt.Errorf("invalid schema. root must be either an array or object type")
+		return "", fmt.Errorf("%w. root must be either an array or object type", dataset.ErrInvalidSchema)
 	}
 	return tlt, nil
 }
@@ -2,9 +2,12 @@ package dsio
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
 )
 
 var basicTableSchema = map[string]interface{}{
@@ -58,3 +61,61 @@ func TestNewEntryWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestTemporalRoundTrip(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "date", "type": "string", "format": "date"},
+				map[string]interface{}{"title": "time", "type": "string", "format": "time"},
+				map[string]interface{}{"title": "at", "type": "string", "format": "date-time"},
+			},
+		},
+	}
+	row := []interface{}{
+		vals.NewDate(2019, 3, 31),
+		vals.NewTime(13, 45, 0, 0),
+		vals.NewDateTime(time.Date(2019, 3, 31, 13, 45, 0, 0, time.FixedZone("CEST", 2*60*60))),
+	}
+	expect := []string{"2019-03-31", "13:45:00", "2019-03-31T13:45:00+02:00"}
+
+	for _, format := range []string{"cbor", "csv", "json"} {
+		st := &dataset.Structure{Format: format, Schema: schema}
+		buf := &bytes.Buffer{}
+		w, err := NewEntryWriter(st, buf)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if err := w.WriteEntry(Entry{Value: row}); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+
+		r, err := NewEntryReader(st, buf)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		ent, err := r.ReadEntry()
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		got, ok := ent.Value.([]interface{})
+		if !ok || len(got) != len(expect) {
+			t.Fatalf("%s: unexpected entry: %#v", format, ent.Value)
+		}
+		for i, e := range expect {
+			if s := fmt.Sprintf("%s", got[i]); s != e {
+				t.Errorf("%s index %d mismatch. expected: %s, got: %s", format, i, e, s)
+			}
+		}
+		if format == "csv" || format == "cbor" {
+			if _, ok := got[2].(vals.DateTime); !ok {
+				t.Errorf("%s: expected datetime to decode as vals.DateTime, got: %T", format, got[2])
+			}
+		}
+	}
+}
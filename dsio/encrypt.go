@@ -0,0 +1,308 @@
+package dsio
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+const (
+	// EncryptedKeyword is the schema keyword marking an encrypted column. The
+	// keyword value is the schema of the column before encryption, which
+	// decryption restores
+	EncryptedKeyword = "encrypted"
+	// EncryptionAESGCM is the schema format of encrypted columns: AES in
+	// Galois counter mode, with a 16, 24 or 32 byte key selecting AES-128,
+	// AES-192 or AES-256
+	EncryptionAESGCM = "aes-gcm"
+)
+
+// NewEncryptReader wraps r, encrypting the columns named in keys with their
+// keys as entries are read. Other columns are read as they are. Encrypted
+// cells hold the base64 encoding of a random nonce followed by the sealed
+// JSON encoding of the cell, so decryption restores the cell value & type.
+// The column title is authenticated with each cell, a cell copied to another
+// column won't decrypt. Null cells are left as null
+//
+// The schema of the read structure types encrypted columns as strings with
+// the EncryptionAESGCM format, moving the rest of the column schema under the
+// EncryptedKeyword. Array rows require a tabular schema, object rows a schema
+// that describes row properties
+func NewEncryptReader(r EntryReader, keys map[string][]byte) (EntryReader, error) {
+	cr, err := newCryptReader(r, keys, false)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	return cr, nil
+}
+
+// NewDecryptReader wraps r, decrypting the encrypted columns named in keys
+// as entries are read. Encrypted columns without a key are left encrypted,
+// so readers can be given access to only some of the sensitive columns of
+// a dataset. Decrypted columns get back their type before encryption
+func NewDecryptReader(r EntryReader, keys map[string][]byte) (EntryReader, error) {
+	cr, err := newCryptReader(r, keys, true)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return cr, nil
+}
+
+// EncryptedColumns lists the titles of columns a structure marks as
+// encrypted, in sorted order
+func EncryptedColumns(st *dataset.Structure) []string {
+	var titles []string
+	for title, col := range columnSchemas(st) {
+		if _, ok := col[EncryptedKeyword]; ok {
+			titles = append(titles, title)
+		}
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+// cryptReader encrypts or decrypts columns of entries
+type cryptReader struct {
+	r       EntryReader
+	st      *dataset.Structure
+	decrypt bool
+	// columns are sorted so errors are deterministic
+	columns []string
+	aeads   map[string]cipher.AEAD
+	// floats is true for decrypted columns typed as numbers, which read
+	// back as float64 even when a value is integral
+	floats map[string]bool
+	index  map[string]int
+	read   int
+}
+
+var _ EntryReader = (*cryptReader)(nil)
+
+func newCryptReader(r EntryReader, keys map[string][]byte, decrypt bool) (*cryptReader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("reader is required")
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one column key is required")
+	}
+	st := &dataset.Structure{}
+	if src := r.Structure(); src != nil {
+		// the checksum, path & length describe the source encoding. entries
+		// are read one to one
+		st = derivedStructure(src)
+		st.Entries = src.Entries
+	}
+	cr := &cryptReader{r: r, st: st, decrypt: decrypt, aeads: map[string]cipher.AEAD{}, floats: map[string]bool{}}
+	if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+		cr.index = map[string]int{}
+		for i, title := range cols.Titles() {
+			cr.index[title] = i
+		}
+	}
+
+	schemas := columnSchemas(st)
+	for title := range keys {
+		cr.columns = append(cr.columns, title)
+	}
+	sort.Strings(cr.columns)
+	for _, title := range cr.columns {
+		col, ok := schemas[title]
+		if !ok {
+			return nil, fmt.Errorf("column %q not found", title)
+		}
+		block, err := aes.NewCipher(keys[title])
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", title, err)
+		}
+		if cr.aeads[title], err = cipher.NewGCM(block); err != nil {
+			return nil, fmt.Errorf("column %q: %w", title, err)
+		}
+		if decrypt {
+			err = cr.markDecrypted(title, col)
+		} else {
+			err = markEncrypted(title, col)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cr, nil
+}
+
+// columnDisplayKeywords are kept on the schema of an encrypted column,
+// everything else describes the value before encryption
+var columnDisplayKeywords = map[string]bool{"title": true, "description": true}
+
+// markEncrypted records the encryption of a column in it's schema
+func markEncrypted(title string, col map[string]interface{}) error {
+	if _, ok := col[EncryptedKeyword]; ok {
+		return fmt.Errorf("column %q is already encrypted", title)
+	}
+	plain := map[string]interface{}{}
+	for key, val := range col {
+		if !columnDisplayKeywords[key] {
+			plain[key] = val
+			delete(col, key)
+		}
+	}
+	col[EncryptedKeyword] = plain
+	col["format"] = EncryptionAESGCM
+	if types, ok := plain["type"].([]interface{}); ok && containsNull(types) {
+		col["type"] = []interface{}{"string", "null"}
+	} else {
+		col["type"] = "string"
+	}
+	return nil
+}
+
+// markDecrypted restores the schema of an encrypted column
+func (r *cryptReader) markDecrypted(title string, col map[string]interface{}) error {
+	plain, ok := col[EncryptedKeyword].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("column %q is not encrypted", title)
+	}
+	if col["format"] != EncryptionAESGCM {
+		return fmt.Errorf("column %q: unsupported encryption format %v", title, col["format"])
+	}
+	for key := range col {
+		if !columnDisplayKeywords[key] {
+			delete(col, key)
+		}
+	}
+	for key, val := range plain {
+		col[key] = val
+	}
+	r.floats[title] = plain["type"] == "number"
+	return nil
+}
+
+// columnSchemas gives the schemas of the columns of a structure by title,
+// either tabular schema items or the properties of object rows
+func columnSchemas(st *dataset.Structure) map[string]map[string]interface{} {
+	schemas := map[string]map[string]interface{}{}
+	if st == nil || st.Schema == nil {
+		return schemas
+	}
+	rows, ok := st.Schema["items"].(map[string]interface{})
+	if !ok {
+		return schemas
+	}
+	if items, ok := rows["items"].([]interface{}); ok {
+		for _, item := range items {
+			if col, ok := item.(map[string]interface{}); ok {
+				if title, ok := col["title"].(string); ok {
+					schemas[title] = col
+				}
+			}
+		}
+	}
+	if props, ok := rows["properties"].(map[string]interface{}); ok {
+		for title, prop := range props {
+			if col, ok := prop.(map[string]interface{}); ok {
+				schemas[title] = col
+			}
+		}
+	}
+	return schemas
+}
+
+// Structure gives the structure of read entries
+func (r *cryptReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next entry, encrypting or decrypting columns
+func (r *cryptReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	op := "encrypt"
+	if r.decrypt {
+		op = "decrypt"
+	}
+	val, get, set, err := rowAccessors(r.index, ent.Value)
+	if err != nil {
+		return ent, fmt.Errorf("%s: entry %d: %w", op, i, err)
+	}
+	ent.Value = val
+	for _, title := range r.columns {
+		v := get(title)
+		if v == nil {
+			continue
+		}
+		if r.decrypt {
+			v, err = r.open(title, v)
+		} else {
+			v, err = r.seal(title, v)
+		}
+		if err != nil {
+			return ent, fmt.Errorf("%s: entry %d: column %q: %w", op, i, title, err)
+		}
+		set(title, v)
+	}
+	return ent, nil
+}
+
+// seal encrypts a cell
+func (r *cryptReader) seal(title string, v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	aead := r.aeads[title]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, []byte(title))), nil
+}
+
+// open decrypts a cell
+func (r *cryptReader) open(title string, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected an encrypted string, got %T", v)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	aead := r.aeads[title]
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted value: too short")
+	}
+	data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(title))
+	if err != nil {
+		return nil, err
+	}
+	// integers are read back as int64, like the JSON reader does
+	if !r.floats[title] && vals.IsInteger(data) {
+		if i, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	var cell interface{}
+	if err := json.Unmarshal(data, &cell); err != nil {
+		return nil, err
+	}
+	return cell, nil
+}
+
+// Close closes the underlying reader
+func (r *cryptReader) Close() error {
+	return r.r.Close()
+}
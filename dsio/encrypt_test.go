@@ -0,0 +1,247 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+var (
+	ssnKey    = bytes.Repeat([]byte{1}, 32)
+	salaryKey = bytes.Repeat([]byte{2}, 16)
+)
+
+const encryptBody = `[
+	[1,"078-05-1120",52000.0,"ok"],
+	[2,null,61000.5,"ok"]
+]`
+
+// sliceReader reads entries from a slice of values
+type sliceReader struct {
+	st   *dataset.Structure
+	rows []interface{}
+	i    int
+}
+
+func (r *sliceReader) Structure() *dataset.Structure { return r.st }
+
+func (r *sliceReader) ReadEntry() (Entry, error) {
+	if r.i == len(r.rows) {
+		return Entry{}, io.EOF
+	}
+	r.i++
+	return Entry{Index: r.i - 1, Value: r.rows[r.i-1]}, nil
+}
+
+func (r *sliceReader) Close() error { return nil }
+
+func encryptTableReader(t *testing.T, data string) EntryReader {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "id", "type": "integer"},
+					map[string]interface{}{"title": "ssn", "type": []interface{}{"string", "null"}},
+					map[string]interface{}{"title": "salary", "type": "number"},
+					map[string]interface{}{"title": "note", "type": "string"},
+				},
+			},
+		},
+	}
+	r, err := NewJSONReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestEncryptReader(t *testing.T) {
+	enc, err := NewEncryptReader(encryptTableReader(t, encryptBody), map[string][]byte{"ssn": ssnKey, "salary": salaryKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := EncryptedColumns(enc.Structure()); !reflect.DeepEqual(got, []string{"salary", "ssn"}) {
+		t.Errorf("expected encrypted columns to be marked, got: %v", got)
+	}
+	if _, err := enc.Structure().JSONSchema(); err != nil {
+		t.Errorf("expected marked schema to be valid, got: %s", err)
+	}
+
+	src := encryptTableReader(t, encryptBody)
+	src.Structure().Path = "/mem/QmPlain"
+	src.Structure().Checksum = "QmPlainSum"
+	src.Structure().Length = len(encryptBody)
+	src.Structure().Entries = 2
+	derived, err := NewEncryptReader(src, map[string][]byte{"ssn": ssnKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := derived.Structure(); st.Path != "" || st.Checksum != "" || st.Length != 0 || st.Entries != 2 {
+		t.Errorf("expected values describing the source encoding to be cleared, got: %#v", st)
+	}
+	rows, err := readRows(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := rows[0].([]interface{})
+	if first[0] != int64(1) || first[3] != "ok" {
+		t.Errorf("expected other columns to be left as-is, got: %#v", first)
+	}
+	if s, ok := first[1].(string); !ok || s == "078-05-1120" {
+		t.Errorf("expected ssn to be encrypted, got: %#v", first[1])
+	}
+	if rows[1].([]interface{})[1] != nil {
+		t.Errorf("expected null cells to stay null")
+	}
+
+	cols := columnSchemas(enc.Structure())
+	expectSalary := map[string]interface{}{
+		"title":          "salary",
+		"type":           "string",
+		"format":         EncryptionAESGCM,
+		EncryptedKeyword: map[string]interface{}{"type": "number"},
+	}
+	if !reflect.DeepEqual(cols["salary"], expectSalary) {
+		t.Errorf("encrypted column schema mismatch. expected: %v, got: %v", expectSalary, cols["salary"])
+	}
+	if !reflect.DeepEqual(cols["ssn"]["type"], []interface{}{"string", "null"}) {
+		t.Errorf("expected nullable encrypted columns to stay nullable, got: %v", cols["ssn"]["type"])
+	}
+
+	// decrypting all columns restores the body & schema
+	dec, err := NewDecryptReader(&sliceReader{st: enc.Structure(), rows: rows}, map[string][]byte{"ssn": ssnKey, "salary": salaryKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRows(dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := readRows(encryptTableReader(t, encryptBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("decrypted body mismatch.\nexpected: %#v\ngot:      %#v", expect, got)
+	}
+	if !reflect.DeepEqual(dec.Structure().Schema, encryptTableReader(t, "[]").Structure().Schema) {
+		t.Errorf("expected decrypted schema to match the original, got: %#v", dec.Structure().Schema)
+	}
+
+	// decrypting some columns leaves the rest encrypted
+	partial, err := NewDecryptReader(&sliceReader{st: enc.Structure(), rows: rows}, map[string][]byte{"ssn": ssnKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = readRows(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row := got[0].([]interface{}); row[1] != "078-05-1120" || row[2] != first[2] {
+		t.Errorf("expected only ssn to be decrypted, got: %#v", row)
+	}
+	if cols := EncryptedColumns(partial.Structure()); !reflect.DeepEqual(cols, []string{"salary"}) {
+		t.Errorf("expected salary to stay marked, got: %v", cols)
+	}
+
+	// cells are bound to their column
+	swapped := []interface{}{[]interface{}{int64(1), first[2], first[2], "ok"}}
+	dec, err = NewDecryptReader(&sliceReader{st: enc.Structure(), rows: swapped}, map[string][]byte{"ssn": salaryKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.ReadEntry(); err == nil || err.Error() != `decrypt: entry 0: column "ssn": cipher: message authentication failed` {
+		t.Errorf("expected cells copied to another column not to decrypt, got: %v", err)
+	}
+}
+
+func TestEncryptReaderObjects(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":  map[string]interface{}{"type": "string"},
+					"score": map[string]interface{}{"type": "integer"},
+				},
+			},
+		},
+	}
+	src, err := NewJSONReader(st, bytes.NewBufferString(`[{"name":"ada","score":3},{"name":"bo"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string][]byte{"score": ssnKey}
+	enc, err := NewEncryptReader(src, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readRows(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rows[0].(map[string]interface{})["score"].(string); !ok {
+		t.Errorf("expected score to be encrypted, got: %#v", rows[0])
+	}
+	dec, err := NewDecryptReader(&sliceReader{st: enc.Structure(), rows: rows}, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRows(dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		map[string]interface{}{"name": "ada", "score": int64(3)},
+		map[string]interface{}{"name": "bo"},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("decrypted body mismatch.\nexpected: %#v\ngot:      %#v", expect, got)
+	}
+}
+
+func TestEncryptReaderErrors(t *testing.T) {
+	enc, err := NewEncryptReader(encryptTableReader(t, encryptBody), map[string][]byte{"ssn": ssnKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := enc.Structure()
+
+	cases := []struct {
+		description string
+		decrypt     bool
+		st          *dataset.Structure
+		keys        map[string][]byte
+		err         string
+	}{
+		{"no keys", false, nil, nil, "encrypt: at least one column key is required"},
+		{"missing column", false, nil, map[string][]byte{"email": ssnKey}, `encrypt: column "email" not found`},
+		{"bad key", false, nil, map[string][]byte{"ssn": []byte("short")}, `encrypt: column "ssn": crypto/aes: invalid key size 5`},
+		{"encrypted twice", false, encrypted, map[string][]byte{"ssn": ssnKey}, `encrypt: column "ssn" is already encrypted`},
+		{"not encrypted", true, nil, map[string][]byte{"note": ssnKey}, `decrypt: column "note" is not encrypted`},
+	}
+
+	for _, c := range cases {
+		var r EntryReader = encryptTableReader(t, encryptBody)
+		if c.st != nil {
+			r = &sliceReader{st: c.st}
+		}
+		if c.decrypt {
+			_, err = NewDecryptReader(r, c.keys)
+		} else {
+			_, err = NewEncryptReader(r, c.keys)
+		}
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case '%s' error mismatch. expected: '%s', got: '%s'", c.description, c.err, err)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package dsio
 
 import (
-	"fmt"
+	"errors"
 	"io"
 )
 
@@ -30,7 +30,11 @@ func EachEntry(rr EntryReader, fn DataIteratorFunc) error {
 			if err.Error() == io.EOF.Error() {
 				return nil
 			}
-			err := fmt.Errorf("error reading row %d: %s", num, err.Error())
+			// readers may already scope errors to a row
+			var re *RowError
+			if !errors.As(err, &re) {
+				err = &RowError{Index: num, Err: err}
+			}
 			log.Debug(err.Error())
 			return err
 		}
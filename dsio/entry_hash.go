@@ -0,0 +1,286 @@
+package dsio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+// CanonicalValue encodes a value as canonical JSON, so values read from
+// different formats encode the same: object keys are sorted, numbers are
+// written in positional notation without trailing zeros whether they're
+// integers, floats or decimals, and datetimes, dates & times of day are
+// written as the ISO 8601 strings they're read from
+func CanonicalValue(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EntryHash gives the multihash of the canonical encoding of an entry value,
+// see CanonicalValue. Entries of object bodies hash as an object of their key
+// & value. Entry indexes aren't hashed, so equal rows have equal hashes
+// wherever they are in a body
+func EntryHash(ent Entry, opts ...func(*dataset.HashConfig)) (string, error) {
+	v := ent.Value
+	if ent.Key != "" {
+		v = map[string]interface{}{ent.Key: ent.Value}
+	}
+	data, err := CanonicalValue(v)
+	if err != nil {
+		return "", err
+	}
+	return dataset.HashBytes(data, opts...)
+}
+
+// writeCanonical writes the canonical JSON encoding of a value
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case string:
+		writeCanonicalString(buf, x)
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(x, 10))
+	case float32:
+		return writeCanonical(buf, float64(x))
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return fmt.Errorf("cannot encode %v as canonical JSON", x)
+		}
+		if x == 0 {
+			// drop the sign of negative zero
+			x = 0
+		}
+		buf.WriteString(strconv.FormatFloat(x, 'f', -1, 64))
+	case vals.Decimal:
+		buf.WriteString(canonicalDecimal(x.String()))
+	case json.Number:
+		d, err := vals.ParseDecimal(x.String())
+		if err != nil {
+			return err
+		}
+		buf.WriteString(canonicalDecimal(d.String()))
+	case vals.DateTime:
+		writeCanonicalString(buf, x.String())
+	case vals.Date:
+		writeCanonicalString(buf, x.String())
+	case vals.Time:
+		writeCanonicalString(buf, x.String())
+	case time.Time:
+		writeCanonicalString(buf, x.Format(vals.DateTimeLayout))
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, el := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, el); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, x[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		// decode other values to generic JSON values first
+		data, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Errorf("cannot encode %T as canonical JSON: %w", v, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return writeCanonical(buf, generic)
+	}
+	return nil
+}
+
+// writeCanonicalString writes a JSON string without escaping html
+// characters. Encoding a string can't fail
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// drop the newline Encode adds
+	buf.Truncate(buf.Len() - 1)
+}
+
+// canonicalDecimal drops trailing fractional zeros from decimal text
+// without an exponent, eg. "-1.500" becomes "-1.5" and "0.00" becomes "0"
+func canonicalDecimal(s string) string {
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// NewRowHashReader adds a column of row hashes to the rows of r, giving rows
+// an identity that's stable across versions without a declared primary key.
+// title names the hash column, which is appended to tabular rows and set as
+// a key of object rows. Tabular rows hash as objects keyed by column title,
+// so reordering columns doesn't change row hashes, and array & object rows
+// with the same cells hash the same
+func NewRowHashReader(r EntryReader, title string, opts ...func(*dataset.HashConfig)) (EntryReader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("row hash: reader is required")
+	}
+	if title == "" {
+		return nil, fmt.Errorf("row hash: column title is required")
+	}
+	if _, err := dataset.NewHashConfig(opts...); err != nil {
+		return nil, fmt.Errorf("row hash: %w", err)
+	}
+
+	base := r.Structure().Clone()
+	hr := &rowHashReader{r: r, title: title, opts: opts}
+	hashCol := map[string]interface{}{"title": title, "type": "string"}
+	if cols, items, err := schemaColumns(base); err == nil {
+		hr.titles = cols.Titles()
+		for _, t := range hr.titles {
+			if t == title {
+				return nil, fmt.Errorf("row hash: column %q already exists", title)
+			}
+		}
+		hr.st = reshapedStructure(base, append(items, hashCol), base.Entries)
+		return hr, nil
+	}
+
+	hr.st = derivedStructure(base)
+	hr.st.Entries = base.Entries
+	if rows, ok := hr.st.Schema["items"].(map[string]interface{}); ok {
+		if props, ok := rows["properties"].(map[string]interface{}); ok {
+			if _, exists := props[title]; exists {
+				return nil, fmt.Errorf("row hash: column %q already exists", title)
+			}
+			props[title] = hashCol
+		}
+	}
+	return hr, nil
+}
+
+// RowHash adds a column of row hashes, see NewRowHashReader
+func (p *PipelineBuilder) RowHash(title string, opts ...func(*dataset.HashConfig)) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		return NewRowHashReader(r, title, opts...)
+	})
+}
+
+// rowHashReader appends row hashes to rows
+type rowHashReader struct {
+	r      EntryReader
+	st     *dataset.Structure
+	title  string
+	titles []string
+	opts   []func(*dataset.HashConfig)
+	read   int
+}
+
+var _ EntryReader = (*rowHashReader)(nil)
+
+// Structure gives the structure of rows with a hash column
+func (r *rowHashReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next row, adding it's hash
+func (r *rowHashReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	var hashed, out interface{}
+	switch row := ent.Value.(type) {
+	case []interface{}:
+		if r.titles == nil {
+			return ent, fmt.Errorf("row hash: entry %d: array rows require a tabular schema", i)
+		}
+		obj := make(map[string]interface{}, len(r.titles))
+		for j, t := range r.titles {
+			if j < len(row) {
+				obj[t] = row[j]
+			} else {
+				obj[t] = nil
+			}
+		}
+		hashed = obj
+		cp := make([]interface{}, len(r.titles), len(r.titles)+1)
+		copy(cp, row)
+		out = cp
+	case map[string]interface{}:
+		if _, exists := row[r.title]; exists {
+			return ent, fmt.Errorf("row hash: entry %d already has a %q key", i, r.title)
+		}
+		hashed = row
+		cp := make(map[string]interface{}, len(row)+1)
+		for key, val := range row {
+			cp[key] = val
+		}
+		out = cp
+	default:
+		return ent, fmt.Errorf("row hash: entry %d is not an array or object row", i)
+	}
+
+	hash, err := EntryHash(Entry{Key: ent.Key, Value: hashed}, r.opts...)
+	if err != nil {
+		return ent, fmt.Errorf("row hash: entry %d: %w", i, err)
+	}
+	switch x := out.(type) {
+	case []interface{}:
+		out = append(x, hash)
+	case map[string]interface{}:
+		x[r.title] = hash
+	}
+	ent.Value = out
+	return ent, nil
+}
+
+// Close closes the source reader
+func (r *rowHashReader) Close() error {
+	return r.r.Close()
+}
@@ -0,0 +1,183 @@
+package dsio
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestCanonicalValue(t *testing.T) {
+	dec, _ := vals.ParseDecimal("-12.500")
+	date, _ := vals.ParseDate("2020-03-31")
+	ts := time.Date(2020, 3, 31, 13, 45, 0, 0, time.UTC)
+
+	cases := []struct {
+		value  interface{}
+		expect string
+		err    string
+	}{
+		{nil, `null`, ""},
+		{true, `true`, ""},
+		{"a<b", `"a<b"`, ""},
+		{1, `1`, ""},
+		{int64(-7), `-7`, ""},
+		{uint64(7), `7`, ""},
+		{1.0, `1`, ""},
+		{1e21, `1000000000000000000000`, ""},
+		{2.5e-7, `0.00000025`, ""},
+		{-0.0, `0`, ""},
+		{dec, `-12.5`, ""},
+		{json.Number("1.50e2"), `150`, ""},
+		{date, `"2020-03-31"`, ""},
+		{vals.NewDateTime(ts), `"2020-03-31T13:45:00Z"`, ""},
+		{ts, `"2020-03-31T13:45:00Z"`, ""},
+		{map[string]interface{}{"b": []interface{}{1.0, "x"}, "a": nil}, `{"a":null,"b":[1,"x"]}`, ""},
+		{struct {
+			B int     `json:"b"`
+			A float64 `json:"a"`
+		}{2, 0.10}, `{"a":0.1,"b":2}`, ""},
+		{[]interface{}{0.0 / zero()}, "", "cannot encode NaN as canonical JSON"},
+	}
+
+	for i, c := range cases {
+		got, err := CanonicalValue(c.value)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+			continue
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func zero() float64 { return 0 }
+
+func TestEntryHashAcrossFormats(t *testing.T) {
+	st := tabularStructure(
+		map[string]interface{}{"title": "id", "type": "integer"},
+		map[string]interface{}{"title": "price", "type": "number"},
+		map[string]interface{}{"title": "name", "type": "string"},
+	)
+	jsonSt := st.Clone()
+	csvSt := st.Clone()
+	csvSt.Format = "csv"
+	cborSt := st.Clone()
+	cborSt.Format = "cbor"
+
+	cbor := &bytes.Buffer{}
+	w, err := NewCBORWriter(cborSt, cbor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteEntry(Entry{Value: []interface{}{int64(1), 1.5, "Feuersee"}})
+	w.Close()
+
+	bodies := []struct {
+		st   *dataset.Structure
+		data []byte
+	}{
+		{jsonSt, []byte(`[[1,1.50,"Feuersee"]]`)},
+		{csvSt, []byte("1,1.5,Feuersee\n")},
+		{cborSt, cbor.Bytes()},
+	}
+
+	var expect string
+	for _, b := range bodies {
+		r, err := NewEntryReader(b.st, bytes.NewReader(b.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ent, err := r.ReadEntry()
+		if err != nil {
+			t.Fatalf("%s: %s", b.st.Format, err)
+		}
+		hash, err := EntryHash(ent)
+		if err != nil {
+			t.Fatalf("%s: %s", b.st.Format, err)
+		}
+		if expect == "" {
+			expect = hash
+		} else if hash != expect {
+			t.Errorf("%s entry %v hash mismatch. expected: %s, got: %s", b.st.Format, ent.Value, expect, hash)
+		}
+	}
+
+	keyed, err := EntryHash(Entry{Key: "a", Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unkeyed, _ := EntryHash(Entry{Value: 1})
+	if keyed == unkeyed {
+		t.Errorf("expected entry keys to change hashes")
+	}
+	if _, err := EntryHash(Entry{Value: 1}, dataset.WithHashFunc("md5")); err == nil {
+		t.Errorf("expected unsupported hash function error")
+	}
+}
+
+func TestRowHashReader(t *testing.T) {
+	src := stringCSVReader(t, "id,name\n1,Hauptbahnhof\n2,Feuersee\n", "id", "name")
+	reordered := stringCSVReader(t, "name,id\nFeuersee,2\n", "name", "id")
+
+	r, err := Pipeline(src).RowHash("row_hash").Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"id", "name", "row_hash"}; strings.Join(readTitles(t, r.Structure()), ",") != strings.Join(expect, ",") {
+		t.Errorf("titles mismatch. expected: %v, got: %v", expect, readTitles(t, r.Structure()))
+	}
+	rows := hashedRows(t, r)
+	if len(rows) != 2 || len(rows[0]) != 3 {
+		t.Fatalf("expected 2 rows of 3 cells, got: %v", rows)
+	}
+	if rows[0][2] == rows[1][2] {
+		t.Errorf("expected different rows to have different hashes")
+	}
+
+	rr, err := NewRowHashReader(reordered, "row_hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hashedRows(t, rr); got[0][2] != rows[1][2] {
+		t.Errorf("expected reordered columns to keep the row hash %v, got: %v", rows[1][2], got[0][2])
+	}
+
+	objects := &sliceReader{
+		st:   &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		rows: []interface{}{map[string]interface{}{"id": "2", "name": "Feuersee"}},
+	}
+	or, err := NewRowHashReader(objects, "row_hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := or.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ent.Value.(map[string]interface{})["row_hash"]; got != rows[1][2] {
+		t.Errorf("expected object row to hash like an array row %v, got: %v", rows[1][2], got)
+	}
+
+	if _, err := NewRowHashReader(stringCSVReader(t, "id\n1\n", "id"), "id"); err == nil || err.Error() != `row hash: column "id" already exists` {
+		t.Errorf("expected existing column error, got: %v", err)
+	}
+}
+
+func hashedRows(t *testing.T, r EntryReader) [][]interface{} {
+	t.Helper()
+	vs, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([][]interface{}, len(vs))
+	for i, v := range vs {
+		rows[i] = v.([]interface{})
+	}
+	return rows
+}
@@ -1,8 +1,6 @@
 package dsio
 
 import (
-	"os"
-	"path/filepath"
 	"testing"
 
 	"github.com/qri-io/dataset"
@@ -49,7 +47,3 @@ func TestEachEntry(t *testing.T) {
 		return
 	}
 }
-
-func testdataFile(base string) string {
-	return filepath.Join(os.Getenv("GOPATH"), "/src/github.com/qri-io/dataset/testdata/", base)
-}
@@ -0,0 +1,49 @@
+package dsio
+
+import (
+	"fmt"
+
+	"github.com/qri-io/dataset"
+)
+
+// FormatError occurs when an entry reader or writer can't be created for a
+// structure's data format. FormatErrors match dataset.ErrFormatRequired when
+// the structure has no format, and dataset.ErrUnknownDataFormat otherwise
+type FormatError struct {
+	// Op is the kind of value being created, "reader" or "writer"
+	Op string
+	// Format is the unsupported format, empty if no format was given
+	Format string
+}
+
+// Error implements the error interface
+func (e *FormatError) Error() string {
+	if e.Format == "" {
+		return "structure must have a data format"
+	}
+	return fmt.Sprintf("invalid format to create %s: %s", e.Op, e.Format)
+}
+
+// Is reports whether a FormatError matches a dataset package error
+func (e *FormatError) Is(target error) bool {
+	if e.Format == "" {
+		return target == dataset.ErrFormatRequired
+	}
+	return target == dataset.ErrUnknownDataFormat
+}
+
+// RowError is an error scoped to a single entry of a dataset body
+type RowError struct {
+	// Index of the entry that failed
+	Index int
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface
+func (e *RowError) Error() string {
+	return fmt.Sprintf("error reading row %d: %s", e.Index, e.Err)
+}
+
+// Unwrap gives the underlying error
+func (e *RowError) Unwrap() error { return e.Err }
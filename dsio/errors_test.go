@@ -0,0 +1,68 @@
+package dsio
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestFormatError(t *testing.T) {
+	_, err := NewEntryReader(&dataset.Structure{}, &bytes.Buffer{})
+	if !errors.Is(err, dataset.ErrFormatRequired) {
+		t.Errorf("expected missing format to match ErrFormatRequired, got: %v", err)
+	}
+	fe := &FormatError{}
+	if !errors.As(err, &fe) || fe.Op != "reader" {
+		t.Errorf("expected a reader FormatError, got: %#v", err)
+	}
+
+	err = &FormatError{Op: "writer", Format: "ndjson"}
+	if err.Error() != "invalid format to create writer: ndjson" {
+		t.Errorf("message mismatch. got: %s", err)
+	}
+	if !errors.Is(err, dataset.ErrUnknownDataFormat) || errors.Is(err, dataset.ErrFormatRequired) {
+		t.Errorf("expected unsupported format to only match ErrUnknownDataFormat")
+	}
+}
+
+func TestRowError(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	r, err := NewJSONReader(st, bytes.NewBufferString(`[1,2,nope]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = EachEntry(r, func(int, Entry, error) error { return nil })
+	re := &RowError{}
+	if !errors.As(err, &re) {
+		t.Fatalf("expected a RowError, got: %#v", err)
+	}
+	if re.Index != 2 {
+		t.Errorf("expected error on row 2, got: %d", re.Index)
+	}
+	if re.Unwrap() == nil || err.Error() != "error reading row 2: "+re.Err.Error() {
+		t.Errorf("unexpected message: %s", err)
+	}
+}
+
+func TestSchemaErrors(t *testing.T) {
+	if _, err := GetTopLevelType(&dataset.Structure{}); !errors.Is(err, dataset.ErrInvalidSchema) {
+		t.Errorf("expected ErrInvalidSchema, got: %v", err)
+	}
+
+	schema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": []interface{}{map[string]interface{}{"title": "a", "type": "string"}},
+		},
+	}
+	w, err := NewCSVWriter(&dataset.Structure{Format: "csv", Schema: schema}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteEntry(Entry{Value: "not a row"}); !errors.Is(err, dataset.ErrSchemaMismatch) {
+		t.Errorf("expected ErrSchemaMismatch, got: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package dsio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/qri-io/dataset"
+)
+
+// EstimateSampleSize is the default number of body bytes EstimateEntries
+// reads to extrapolate an entry count
+const EstimateSampleSize = 64 << 10
+
+// EstimateConfidence describes how an entry count estimate was made
+type EstimateConfidence string
+
+const (
+	// EstimateUnknown means no count could be made, Entries is zero
+	EstimateUnknown = EstimateConfidence("unknown")
+	// EstimateAtLeast means Entries complete entries were read from a body
+	// of unknown size, the body has at least Entries entries
+	EstimateAtLeast = EstimateConfidence("atLeast")
+	// EstimateSampled means Entries is extrapolated from the average size of
+	// the entries in a sample from the start of the body
+	EstimateSampled = EstimateConfidence("sampled")
+	// EstimateExact means Entries is the number of entries in the body, read
+	// from the structure, a length prefix or a complete scan of a small body
+	EstimateExact = EstimateConfidence("exact")
+)
+
+// EntryEstimate is an estimated number of body entries
+type EntryEstimate struct {
+	Entries    int                `json:"entries"`
+	Confidence EstimateConfidence `json:"confidence"`
+	// Sampled is the number of entries a sampled estimate was made from
+	Sampled int `json:"sampled,omitempty"`
+}
+
+// EstimateConfig configures entry count estimation
+type EstimateConfig struct {
+	// SampleSize is the number of body bytes read to estimate entry counts
+	// of formats without a length prefix
+	SampleSize int
+}
+
+// WithSampleSize sets the number of body bytes an estimate reads
+func WithSampleSize(n int) func(*EstimateConfig) {
+	return func(c *EstimateConfig) {
+		c.SampleSize = n
+	}
+}
+
+// EstimateEntries gives the number of entries of a body without reading it
+// all, for progress bars and page counts. Structures with entries record an
+// exact count & don't read the body. CBOR bodies with a definite length
+// header, which CBORWriter always writes, give an exact count from the
+// first bytes. JSON & CSV bodies count the complete entries of a sample from
+// the start of the body, after the preamble readers skip, see SkipPreamble,
+// and extrapolate with the body size in bytes. size
+// falls back to st.Length when it's zero or less. Compressed bodies can't
+// be estimated
+func EstimateEntries(st *dataset.Structure, body io.Reader, size int64, opts ...func(*EstimateConfig)) (EntryEstimate, error) {
+	cfg := &EstimateConfig{SampleSize: EstimateSampleSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	unknown := EntryEstimate{Confidence: EstimateUnknown}
+	if st == nil {
+		return unknown, fmt.Errorf("structure is required")
+	}
+	if st.Entries > 0 {
+		return EntryEstimate{Entries: st.Entries, Confidence: EstimateExact}, nil
+	}
+	if st.Compression != "" {
+		return unknown, fmt.Errorf("cannot estimate entries of a %s compressed body", st.Compression)
+	}
+	if body == nil {
+		return unknown, fmt.Errorf("body is required")
+	}
+	if size <= 0 {
+		size = int64(st.Length)
+	}
+
+	switch st.DataFormat() {
+	case dataset.CBORDataFormat:
+		return estimateCBOREntries(body)
+	case dataset.JSONDataFormat, dataset.CSVDataFormat:
+	default:
+		return unknown, fmt.Errorf("cannot estimate entries of %s bodies", st.Format)
+	}
+
+	if cfg.SampleSize <= 0 {
+		return unknown, fmt.Errorf("sample size must be greater than zero")
+	}
+	// read one byte past the sample to tell whether the sample is the body
+	sample, err := ioutil.ReadAll(io.LimitReader(body, int64(cfg.SampleSize)+1))
+	if err != nil {
+		return unknown, err
+	}
+	complete := len(sample) <= cfg.SampleSize
+	if !complete {
+		sample = sample[:cfg.SampleSize]
+	}
+
+	// skip the preamble readers skip, counting entries after it
+	br := bufio.NewReader(bytes.NewReader(sample))
+	SkipPreamble(st, br)
+	rest, _ := ioutil.ReadAll(br)
+	pre := len(sample) - len(rest)
+
+	var sc sampleCount
+	if st.DataFormat() == dataset.JSONDataFormat {
+		if sc, err = countJSONSample(rest, complete); err != nil {
+			return unknown, err
+		}
+	} else {
+		sc = countCSVSample(rest, complete, HasHeaderRow(st))
+	}
+	sc.start += pre
+	sc.end += pre
+
+	switch {
+	case complete:
+		return EntryEstimate{Entries: sc.entries, Confidence: EstimateExact}, nil
+	case sc.entries == 0:
+		return unknown, nil
+	case size <= int64(sc.end):
+		return EntryEstimate{Entries: sc.entries, Confidence: EstimateAtLeast}, nil
+	}
+	avg := float64(sc.end-sc.start) / float64(sc.entries)
+	n := int(math.Round(float64(size-int64(sc.start)) / avg))
+	if n < sc.entries {
+		n = sc.entries
+	}
+	return EntryEstimate{Entries: n, Confidence: EstimateSampled, Sampled: sc.entries}, nil
+}
+
+// sampleCount is the number of complete entries in a body sample, which
+// span the sample bytes from start to end
+type sampleCount struct {
+	entries    int
+	start, end int
+}
+
+// estimateCBOREntries reads the entry count from the length header of a
+// top level CBOR array or map
+func estimateCBOREntries(body io.Reader) (EntryEstimate, error) {
+	unknown := EntryEstimate{Confidence: EstimateUnknown}
+	head := make([]byte, 1)
+	if _, err := io.ReadFull(body, head); err != nil {
+		return unknown, fmt.Errorf("reading cbor header: %w", err)
+	}
+	base, info := head[0]&0xe0, head[0]&0x1f
+	if base != cborBaseArray && base != cborBaseMap {
+		return unknown, fmt.Errorf("cbor body must be an array or map")
+	}
+	if info == 0x1f {
+		// indefinite length, entries aren't counted up front
+		return unknown, nil
+	}
+	if info < 0x18 {
+		return EntryEstimate{Entries: int(info), Confidence: EstimateExact}, nil
+	}
+	if info > 0x1b {
+		return unknown, fmt.Errorf("invalid cbor length header")
+	}
+	buf := make([]byte, 1<<(info-0x18))
+	if _, err := io.ReadFull(body, buf); err != nil {
+		return unknown, fmt.Errorf("reading cbor header: %w", err)
+	}
+	var n uint64
+	switch len(buf) {
+	case 1:
+		n = uint64(buf[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(buf))
+	case 4:
+		n = uint64(binary.BigEndian.Uint32(buf))
+	case 8:
+		n = binary.BigEndian.Uint64(buf)
+	}
+	return EntryEstimate{Entries: int(n), Confidence: EstimateExact}, nil
+}
+
+// countJSONSample counts the complete elements of a top level JSON array or
+// object in a body sample, without decoding values
+func countJSONSample(sample []byte, complete bool) (sampleCount, error) {
+	sc := sampleCount{}
+	var (
+		depth            int
+		inString, escape bool
+		started          bool
+	)
+	for i, b := range sample {
+		if inString {
+			switch {
+			case escape:
+				escape = false
+			case b == '\\':
+				escape = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[', '{':
+			depth++
+			if depth == 1 {
+				sc.start, sc.end = i+1, i+1
+				continue
+			}
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				if started {
+					sc.entries++
+				}
+				sc.end = i + 1
+				return sc, nil
+			}
+		case ',':
+			if depth == 1 {
+				sc.entries++
+				sc.end = i + 1
+				started = false
+				continue
+			}
+		case '"':
+			inString = true
+		}
+		if depth == 0 {
+			return sc, fmt.Errorf("json body must be an array or object")
+		}
+		started = true
+	}
+	if complete {
+		return sc, fmt.Errorf("unexpected end of json body")
+	}
+	return sc, nil
+}
+
+// countCSVSample counts the non-empty rows of a CSV body sample, excluding
+// the header row. Line breaks in quoted cells don't end rows
+func countCSVSample(sample []byte, complete, header bool) sampleCount {
+	sc := sampleCount{}
+	var (
+		rows      int
+		inQuote   bool
+		hasValues bool
+	)
+	endRow := func(end int) {
+		if !hasValues {
+			return
+		}
+		hasValues = false
+		rows++
+		if rows == 1 && header {
+			sc.start, sc.end = end, end
+			return
+		}
+		sc.entries++
+		sc.end = end
+	}
+	for i, b := range sample {
+		switch {
+		case b == '"':
+			inQuote = !inQuote
+			hasValues = true
+		case b == '\n' && !inQuote:
+			endRow(i + 1)
+		case b != '\r':
+			hasValues = true
+		}
+	}
+	if complete && len(bytes.TrimSpace(sample[sc.end:])) > 0 {
+		// the last row needn't end with a line break
+		hasValues = true
+		endRow(len(sample))
+	}
+	return sc
+}
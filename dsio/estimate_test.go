@@ -0,0 +1,115 @@
+package dsio
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestEstimateEntries(t *testing.T) {
+	csvHeader := &dataset.Structure{Format: "csv", FormatConfig: map[string]interface{}{"headerRow": true}}
+	csvRows := "id,name\n1,a\n2,b\n3,\"c\nd\"\n\n4,e"
+	jsonArr := `[{"a":[1,2]},"x,]",3]`
+	csvComments := &dataset.Structure{Format: "csv", FormatConfig: map[string]interface{}{"headerRow": true, "commentPrefix": "#"}}
+	jsonComments := &dataset.Structure{Format: "json", FormatConfig: map[string]interface{}{"commentPrefix": "//"}}
+
+	cases := []struct {
+		description string
+		st          *dataset.Structure
+		body        string
+		size        int64
+		sample      int
+		expect      EntryEstimate
+		err         string
+	}{
+		{"recorded entries", &dataset.Structure{Format: "json", Entries: 12}, "", 0, 0, EntryEstimate{Entries: 12, Confidence: EstimateExact}, ""},
+		{"compressed", &dataset.Structure{Format: "csv", Compression: "gzip"}, "", 0, 0, EntryEstimate{Confidence: EstimateUnknown}, "cannot estimate entries of a gzip compressed body"},
+		{"xlsx", &dataset.Structure{Format: "xlsx"}, "", 0, 0, EntryEstimate{Confidence: EstimateUnknown}, "cannot estimate entries of xlsx bodies"},
+
+		{"csv complete", csvHeader, csvRows, 0, 0, EntryEstimate{Entries: 4, Confidence: EstimateExact}, ""},
+		{"csv no header", &dataset.Structure{Format: "csv"}, "1,a\n2,b\n", 0, 0, EntryEstimate{Entries: 2, Confidence: EstimateExact}, ""},
+		{"csv header only", csvHeader, "id,name\n", 0, 0, EntryEstimate{Entries: 0, Confidence: EstimateExact}, ""},
+		{"csv sampled", csvHeader, "id,name\n1,a\n2,b\n3,c\n", 8 + 4*1000, 14, EntryEstimate{Entries: 1000, Confidence: EstimateSampled, Sampled: 1}, ""},
+		{"csv unknown size", csvHeader, "id,name\n1,a\n2,b\n3,c\n", 0, 14, EntryEstimate{Entries: 1, Confidence: EstimateAtLeast}, ""},
+		{"csv no complete row", csvHeader, "id,name\n1,a\n2,b\n3,c\n", 1000, 4, EntryEstimate{Confidence: EstimateUnknown}, ""},
+
+		{"csv preamble", csvComments, "\xEF\xBB\xBF# export\nid,name\n1,a\n2,b\n", 0, 0, EntryEstimate{Entries: 2, Confidence: EstimateExact}, ""},
+		{"csv preamble sampled", csvComments, "# x\nid,name\n1,a\n2,b\n3,c\n", 12 + 4*1000, 18, EntryEstimate{Entries: 1000, Confidence: EstimateSampled, Sampled: 1}, ""},
+
+		{"json array", &dataset.Structure{Format: "json"}, jsonArr, 0, 0, EntryEstimate{Entries: 3, Confidence: EstimateExact}, ""},
+		{"json object", &dataset.Structure{Format: "json"}, `{"a": {"b": 1}, "c": "}"}`, 0, 0, EntryEstimate{Entries: 2, Confidence: EstimateExact}, ""},
+		{"json empty", &dataset.Structure{Format: "json"}, ` [ ] `, 0, 0, EntryEstimate{Entries: 0, Confidence: EstimateExact}, ""},
+		{"json sampled", &dataset.Structure{Format: "json"}, `[1,2,3,4,5]`, 1 + 2*500, 6, EntryEstimate{Entries: 500, Confidence: EstimateSampled, Sampled: 2}, ""},
+		{"json preamble", jsonComments, "\xEF\xBB\xBF// rows\n[1,2]", 0, 0, EntryEstimate{Entries: 2, Confidence: EstimateExact}, ""},
+		{"json bom", &dataset.Structure{Format: "json"}, "\xEF\xBB\xBF[1,2,3]", 0, 0, EntryEstimate{Entries: 3, Confidence: EstimateExact}, ""},
+		{"json scalar", &dataset.Structure{Format: "json"}, `12`, 0, 0, EntryEstimate{Confidence: EstimateUnknown}, "json body must be an array or object"},
+		{"json truncated", &dataset.Structure{Format: "json"}, `[1,2`, 0, 0, EntryEstimate{Confidence: EstimateUnknown}, "unexpected end of json body"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var opts []func(*EstimateConfig)
+			if c.sample > 0 {
+				opts = append(opts, WithSampleSize(c.sample))
+			}
+			got, err := EstimateEntries(c.st, strings.NewReader(c.body), c.size, opts...)
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Fatalf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+			if got != c.expect {
+				t.Errorf("estimate mismatch. expected: %+v, got: %+v", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestEstimateEntriesStructureLength(t *testing.T) {
+	st := &dataset.Structure{Format: "csv", Length: 4 * 250}
+	got, err := EstimateEntries(st, strings.NewReader("1,a\n2,b\n"), 0, WithSampleSize(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (EntryEstimate{Entries: 250, Confidence: EstimateSampled, Sampled: 1}); got != expect {
+		t.Errorf("estimate mismatch. expected: %+v, got: %+v", expect, got)
+	}
+}
+
+func TestEstimateEntriesCBOR(t *testing.T) {
+	for _, n := range []int{0, 3, 200, 300, 70000} {
+		t.Run(fmt.Sprintf("%d entries", n), func(t *testing.T) {
+			st := &dataset.Structure{Format: "cbor", Schema: dataset.BaseSchemaArray}
+			buf := &bytes.Buffer{}
+			w, err := NewCBORWriter(st, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < n; i++ {
+				if err := w.WriteEntry(Entry{Index: i, Value: i}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := EstimateEntries(st, buf, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expect := (EntryEstimate{Entries: n, Confidence: EstimateExact}); got != expect {
+				t.Errorf("estimate mismatch. expected: %+v, got: %+v", expect, got)
+			}
+		})
+	}
+
+	st := &dataset.Structure{Format: "cbor"}
+	if got, err := EstimateEntries(st, bytes.NewReader([]byte{0x9f, 0x01, 0xff}), 0); err != nil || got.Confidence != EstimateUnknown {
+		t.Errorf("indefinite array: expected unknown estimate, got: %+v, %v", got, err)
+	}
+	if _, err := EstimateEntries(st, bytes.NewReader([]byte{0x01}), 0); err == nil || err.Error() != "cbor body must be an array or map" {
+		t.Errorf("scalar: expected error, got: %v", err)
+	}
+}
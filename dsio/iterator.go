@@ -0,0 +1,105 @@
+package dsio
+
+import (
+	"io"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+// ValsIterator adapts an EntryReader to the vals.Iterator interface, reading
+// one entry per call to Next
+type ValsIterator struct {
+	r   EntryReader
+	ent Entry
+	val vals.Value
+	err error
+}
+
+var _ vals.Iterator = (*ValsIterator)(nil)
+
+// NewValsIterator wraps an EntryReader as a vals.Iterator
+func NewValsIterator(r EntryReader) *ValsIterator {
+	return &ValsIterator{r: r}
+}
+
+// Next reads the next entry, returning false at the end of the reader or on
+// error
+func (it *ValsIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	ent, err := it.r.ReadEntry()
+	if err != nil {
+		if err.Error() != io.EOF.Error() {
+			it.err = err
+		}
+		it.ent, it.val = Entry{}, nil
+		return false
+	}
+	val, err := entryValue(ent.Value)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.ent, it.val = ent, val
+	return true
+}
+
+// entryValue converts an entry value to a vals.Value
+func entryValue(v interface{}) (vals.Value, error) {
+	if val, ok := v.(vals.Value); ok {
+		return val, nil
+	}
+	return vals.ConvertDecoded(v)
+}
+
+// Value gives the value of the current entry
+func (it *ValsIterator) Value() vals.Value { return it.val }
+
+// Key gives the key of the current entry
+func (it *ValsIterator) Key() string { return it.ent.Key }
+
+// Entry gives the current entry as read
+func (it *ValsIterator) Entry() Entry { return it.ent }
+
+// Err gives the error that stopped iteration, if any
+func (it *ValsIterator) Err() error { return it.err }
+
+// Close closes the underlying reader
+func (it *ValsIterator) Close() error { return it.r.Close() }
+
+// IteratorReader adapts a vals.Iterator to the EntryReader interface
+type IteratorReader struct {
+	st *dataset.Structure
+	it vals.Iterator
+	i  int
+}
+
+var _ EntryReader = (*IteratorReader)(nil)
+
+// NewIteratorReader wraps a vals.Iterator as an EntryReader of the given
+// structure
+func NewIteratorReader(st *dataset.Structure, it vals.Iterator) *IteratorReader {
+	return &IteratorReader{st: st, it: it}
+}
+
+// Structure gives the structure being read
+func (r *IteratorReader) Structure() *dataset.Structure { return r.st }
+
+// ReadEntry reads the next value from the iterator as an entry, returning
+// io.EOF once the iterator is exhausted
+func (r *IteratorReader) ReadEntry() (Entry, error) {
+	if !r.it.Next() {
+		if err := r.it.Err(); err != nil {
+			return Entry{}, err
+		}
+		return Entry{}, io.EOF
+	}
+	ent := Entry{Index: r.i, Key: r.it.Key(), Value: vals.ToDecoded(r.it.Value())}
+	r.i++
+	return ent, nil
+}
+
+// Close closes the underlying iterator
+func (r *IteratorReader) Close() error { return r.it.Close() }
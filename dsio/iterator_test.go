@@ -0,0 +1,86 @@
+package dsio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestValsIterator(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}
+	r, err := NewJSONReader(st, bytes.NewBufferString(`{"a":1,"b":[true,"x"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := vals.Collect(NewValsIterator(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := vals.Object{
+		"a": vals.Integer(1),
+		"b": &vals.Array{vals.Boolean(true), vals.String("x")},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("result mismatch. expected: %#v, got: %#v", expect, got)
+	}
+
+	r, err = NewJSONReader(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}, bytes.NewBufferString(`[1,`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := NewValsIterator(r)
+	for it.Next() {
+	}
+	if it.Err() == nil {
+		t.Errorf("expected invalid body to stop iteration with an error")
+	}
+}
+
+func TestIteratorReader(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	r := NewIteratorReader(st, vals.NewArrayIterator(vals.Array{
+		vals.Integer(1),
+		vals.Array{vals.String("a"), vals.Null(true)},
+	}))
+	if r.Structure() != st {
+		t.Errorf("structure mismatch")
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewJSONWriter(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Copy(r, w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `[1,["a",null]]` {
+		t.Errorf("output mismatch. got: %s", buf.String())
+	}
+
+	if _, err := r.ReadEntry(); err != io.EOF {
+		t.Errorf("expected io.EOF, got: %v", err)
+	}
+}
+
+// errIterator yields no values, failing immediately
+type errIterator struct{ vals.Iterator }
+
+func (errIterator) Next() bool   { return false }
+func (errIterator) Err() error   { return fmt.Errorf("boom") }
+func (errIterator) Close() error { return nil }
+
+func TestIteratorReaderError(t *testing.T) {
+	r := NewIteratorReader(&dataset.Structure{Format: "json"}, errIterator{})
+	if _, err := r.ReadEntry(); err == nil || err.Error() != "boom" {
+		t.Errorf("expected iterator error, got: %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
 )
 
 // JSONReader implements the RowReader interface for the JSON data format
@@ -21,14 +22,28 @@ type JSONReader struct {
 	objKey      string
 	reader      *bufio.Reader
 	prevSize    int // when buffer is extended, remember how much of the old buffer to discard
+	decimals    bool
+	// pooled is true when reader is a pooled buffer, returned on close
+	pooled bool
+	lend   bool
+	// lent is a pooled row for the next top-level array
+	lent []interface{}
+	// commentPrefix starts comment lines skipped before the body
+	commentPrefix string
+	skipped       bool
+	norm          Normalization
 }
 
-var _ EntryReader = (*JSONReader)(nil)
+var (
+	_ EntryReader = (*JSONReader)(nil)
+	_ Normalizer  = (*JSONReader)(nil)
+)
 
-// NewJSONReader creates a reader from a structure and read source
+// NewJSONReader creates a reader from a structure and read source. A leading
+// byte order mark is skipped, as are leading comment lines if the format
+// config sets a "commentPrefix", see Normalization
 func NewJSONReader(st *dataset.Structure, r io.Reader) (*JSONReader, error) {
-	// Huge buffer (a quarter of a MB) to speed up string reads.
-	return NewJSONReaderSize(st, r, 256*1024)
+	return NewJSONReaderSize(st, r, jsonReaderBufferSize)
 }
 
 // NewJSONReaderSize creates a reader from a structure, read source, and buffer size
@@ -39,15 +54,23 @@ func NewJSONReaderSize(st *dataset.Structure, r io.Reader, size int) (*JSONReade
 		return nil, err
 	}
 
-	reader := bufio.NewReaderSize(r, size)
 	tlt, err := GetTopLevelType(st)
 	if err != nil {
 		return nil, err
 	}
 	jr := &JSONReader{
-		st:     st,
-		reader: reader,
-		tlt:    tlt,
+		st:  st,
+		tlt: tlt,
+	}
+	if size == jsonReaderBufferSize {
+		jr.reader = getJSONReaderBuffer(r)
+		jr.pooled = true
+	} else {
+		jr.reader = bufio.NewReaderSize(r, size)
+	}
+	if opts, err := dataset.NewJSONOptions(st.FormatConfig); err == nil {
+		jr.decimals = opts.DecimalNumbers()
+		jr.commentPrefix = opts.CommentPrefix()
 	}
 	return jr, nil
 }
@@ -57,11 +80,25 @@ func (r *JSONReader) Structure() *dataset.Structure {
 	return r.st
 }
 
+// Normalization gives the changes made to the start of the body, known once
+// the first entry is read
+func (r *JSONReader) Normalization() Normalization {
+	return r.norm
+}
+
 const blockSize = 4096
 
 // ReadEntry reads one JSON record from the reader
 func (r *JSONReader) ReadEntry() (Entry, error) {
 	ent := Entry{}
+	if r.reader == nil {
+		return ent, fmt.Errorf("json reader is closed")
+	}
+
+	if !r.skipped {
+		r.norm = skipPreamble(r.reader, r.commentPrefix != "", r.commentPrefix)
+		r.skipped = true
+	}
 
 	// Fill up buffer.
 	_, _ = r.reader.Peek(blockSize)
@@ -107,7 +144,11 @@ func (r *JSONReader) ReadEntry() (Entry, error) {
 			return ent, err
 		}
 	} else {
+		if r.lend {
+			r.lent = borrowRow(0)
+		}
 		val, err := r.readValue()
+		r.lent = nil
 		ent.Index = r.entriesRead
 		ent.Value = val
 		if err != nil {
@@ -118,10 +159,20 @@ func (r *JSONReader) ReadEntry() (Entry, error) {
 	return ent, nil
 }
 
+// lendEntries decodes top-level array rows into pooled memory. see
+// BorrowEntries
+func (r *JSONReader) lendEntries() {
+	r.lend = true
+}
+
 // Close finalizes the reader
 func (r *JSONReader) Close() error {
 	// TODO (b5): we should retain a reference to the underlying reader &
 	// check if it's an io.ReadCloser, calling close here if so
+	if r.pooled && r.reader != nil {
+		putJSONReaderBuffer(r.reader)
+	}
+	r.reader = nil
 	return nil
 }
 
@@ -311,11 +362,19 @@ func (r *JSONReader) readNumber() (interface{}, error) {
 		}
 	}
 	if i > 0 {
+		str := r.extractFromBuffer(buff, i)
 		if isFloat {
-			return strconv.ParseFloat(r.extractFromBuffer(buff, i), 64)
+			if r.decimals {
+				return vals.ParseDecimal(str)
+			}
+			return strconv.ParseFloat(str, 64)
 		}
-		num, err := strconv.Atoi(r.extractFromBuffer(buff, i))
+		num, err := strconv.Atoi(str)
 		if err != nil {
+			if r.decimals {
+				// integers too large for int64 are kept exactly as decimals
+				return vals.ParseDecimal(str)
+			}
 			return nil, err
 		}
 		return int64(num), nil
@@ -358,6 +417,10 @@ func (r *JSONReader) readArray() ([]interface{}, error) {
 		return nil, fmt.Errorf("Expected: opening '[' for array")
 	}
 	array := make([]interface{}, 0)
+	if r.lent != nil {
+		// the first array read for an entry is the top-level row
+		array, r.lent = r.lent, nil
+	}
 	if r.readTokenChar(']') {
 		return array, nil
 	}
@@ -408,11 +471,13 @@ type JSONWriter struct {
 	indent      string
 	st          *dataset.Structure
 	wr          io.Writer
+	cfg         *WriterConfig
 	keysWritten map[string]bool
 }
 
-// NewJSONWriter creates a Writer from a structure and write destination
-func NewJSONWriter(st *dataset.Structure, w io.Writer) (*JSONWriter, error) {
+// NewJSONWriter creates a Writer from a structure and write destination.
+// Writer options configure how floats & datetimes are formatted
+func NewJSONWriter(st *dataset.Structure, w io.Writer, opts ...func(*WriterConfig)) (*JSONWriter, error) {
 	if st.Schema == nil {
 		err := fmt.Errorf("schema required for JSON writer")
 		log.Debug(err.Error())
@@ -427,6 +492,7 @@ func NewJSONWriter(st *dataset.Structure, w io.Writer) (*JSONWriter, error) {
 		st:  st,
 		wr:  w,
 		tlt: tlt,
+		cfg: newWriterConfig(opts...),
 	}
 
 	if jw.tlt == "object" {
@@ -436,8 +502,8 @@ func NewJSONWriter(st *dataset.Structure, w io.Writer) (*JSONWriter, error) {
 }
 
 // NewJSONPrettyWriter creates a Writer that writes pretty indented JSON
-func NewJSONPrettyWriter(st *dataset.Structure, w io.Writer, indent string) (*JSONWriter, error) {
-	jw, err := NewJSONWriter(st, w)
+func NewJSONPrettyWriter(st *dataset.Structure, w io.Writer, indent string, opts ...func(*WriterConfig)) (*JSONWriter, error) {
+	jw, err := NewJSONWriter(st, w, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -486,6 +552,12 @@ func (w *JSONWriter) WriteEntry(ent Entry) error {
 }
 
 func (w *JSONWriter) valBytes(ent Entry) (data []byte, err error) {
+	if w.cfg.formatsJSON() {
+		ent.Value = w.cfg.jsonValue(ent.Value)
+	}
+	if w.cfg.SchemaKeyOrder {
+		ent.Value = keyOrdered(ent.Value, entrySchema(w.st.Schema, ent))
+	}
 	if w.tlt == "array" {
 		// TODO - add test that checks this is recording values & not entries
 		if w.indent != "" {
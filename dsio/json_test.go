@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dstest"
+	"github.com/qri-io/dataset/vals"
 )
 
 func TestJSONReader(t *testing.T) {
@@ -124,6 +125,41 @@ func TestJSONReaderBasicParsing(t *testing.T) {
 	}
 }
 
+func TestJSONReaderDecimalNumbers(t *testing.T) {
+	st := &dataset.Structure{
+		Format:       "json",
+		FormatConfig: map[string]interface{}{"decimalNumbers": true},
+		Schema:       dataset.BaseSchemaArray,
+	}
+	data := `[12.50, 0.1000000000000000000001, 92233720368547758070, 42]`
+	r, err := NewJSONReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"12.50", "0.1000000000000000000001", "92233720368547758070"}
+	for i, e := range expect {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			t.Fatalf("entry %d: %s", i, err)
+		}
+		dec, ok := ent.Value.(vals.Decimal)
+		if !ok {
+			t.Fatalf("entry %d expected vals.Decimal, got: %T", i, ent.Value)
+		}
+		if dec.String() != e {
+			t.Errorf("entry %d expected: %s, got: %s", i, e, dec.String())
+		}
+	}
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ent.Value != int64(42) {
+		t.Errorf("expected integers that fit in int64 to decode as int64, got: %#v", ent.Value)
+	}
+}
+
 func TestJSONReaderSmallerBufferForHugeToken(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -478,7 +514,7 @@ func BenchmarkJSONReader(b *testing.B) {
 	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
 
 	for n := 0; n < b.N; n++ {
-		file, err := os.Open(testdataFile("../dsio/testdata/movies/body.json"))
+		file, err := os.Open("testdata/movies/body.json")
 		if err != nil {
 			b.Errorf("unexpected error: %s", err.Error())
 		}
@@ -0,0 +1,126 @@
+package dsio
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// orderedObject is an object with keys in a fixed order, elements alternate
+// between keys & values. orderedObjects encode as JSON objects & CBOR maps
+type orderedObject []interface{}
+
+// MapBySlice marks orderedObject as a map for the CBOR encoder
+func (orderedObject) MapBySlice() {}
+
+// MarshalJSON writes a JSON object with keys in order
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i := 0; i+1 < len(o); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(o[i])
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(o[i+1])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// entrySchema gives the schema of an entry value of a body schema: the items
+// schema of array bodies, and the property schema of object bodies
+func entrySchema(schema map[string]interface{}, ent Entry) map[string]interface{} {
+	if schema["type"] != "object" {
+		return itemSchema(schema, ent.Index)
+	}
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		if sub, ok := props[ent.Key].(map[string]interface{}); ok {
+			return sub
+		}
+	}
+	sub, _ := schema["additionalProperties"].(map[string]interface{})
+	return sub
+}
+
+// itemSchema gives the schema of element i of an array schema, supporting
+// both single item schemas & tuple item lists
+func itemSchema(schema map[string]interface{}, i int) map[string]interface{} {
+	switch items := schema["items"].(type) {
+	case map[string]interface{}:
+		return items
+	case []interface{}:
+		if i < len(items) {
+			sub, _ := items[i].(map[string]interface{})
+			return sub
+		}
+	}
+	return nil
+}
+
+// keyOrdered copies a value, replacing objects with orderedObjects that list
+// keys in schema order, see WithSchemaKeyOrder
+func keyOrdered(v interface{}, schema map[string]interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		obj := make(orderedObject, 0, len(x)*2)
+		for _, key := range schemaKeys(x, schema) {
+			sub, _ := props[key].(map[string]interface{})
+			obj = append(obj, key, keyOrdered(x[key], sub))
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(x))
+		for i, el := range x {
+			arr[i] = keyOrdered(el, itemSchema(schema, i))
+		}
+		return arr
+	}
+	return v
+}
+
+// schemaKeys orders the keys of an object: keys listed by the "propertyOrder"
+// of the object schema first, then keys listed as "required", then any other
+// keys in sorted order. "properties" is a map, so it has no order to follow
+func schemaKeys(obj map[string]interface{}, schema map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	listed := map[string]bool{}
+	for _, list := range []string{"propertyOrder", "required"} {
+		var names []string
+		switch x := schema[list].(type) {
+		case []string:
+			names = x
+		case []interface{}:
+			for _, name := range x {
+				if key, ok := name.(string); ok {
+					names = append(names, key)
+				}
+			}
+		}
+		for _, key := range names {
+			if _, present := obj[key]; !present || listed[key] {
+				continue
+			}
+			listed[key] = true
+			keys = append(keys, key)
+		}
+	}
+	rest := make([]string, 0, len(obj)-len(keys))
+	for key := range obj {
+		if !listed[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
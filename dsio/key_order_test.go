@@ -0,0 +1,176 @@
+package dsio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func keyOrderStructure(format string) *dataset.Structure {
+	return &dataset.Structure{
+		Format: format,
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":          "object",
+				"propertyOrder": []interface{}{"zone", "stop_id"},
+				"required":      []interface{}{"stop_id", "name"},
+				"properties": map[string]interface{}{
+					"stop_id": map[string]interface{}{"type": "string"},
+					"name":    map[string]interface{}{"type": "string"},
+					"zone":    map[string]interface{}{"type": "integer"},
+					"location": map[string]interface{}{
+						"type":          "object",
+						"propertyOrder": []string{"lon", "lat"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func keyOrderRows() []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"stop_id":  "a",
+			"name":     "Hauptbahnhof",
+			"zone":     1,
+			"location": map[string]interface{}{"lat": 48.78, "lon": 9.18},
+			"extra":    true,
+			"alias":    []interface{}{map[string]interface{}{"b": 1, "a": 2}},
+		},
+	}
+}
+
+func TestJSONWriterSchemaKeyOrder(t *testing.T) {
+	cases := []struct {
+		description string
+		opts        []func(*WriterConfig)
+		expect      string
+	}{
+		{"sorted", nil,
+			`[{"alias":[{"a":2,"b":1}],"extra":true,"location":{"lat":48.78,"lon":9.18},"name":"Hauptbahnhof","stop_id":"a","zone":1}]`},
+		{"schema order", []func(*WriterConfig){WithSchemaKeyOrder()},
+			`[{"zone":1,"stop_id":"a","name":"Hauptbahnhof","alias":[{"a":2,"b":1}],"extra":true,"location":{"lon":9.18,"lat":48.78}}]`},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := NewJSONWriter(keyOrderStructure("json"), buf, c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, row := range keyOrderRows() {
+				if err := w.WriteEntry(Entry{Index: i, Value: row}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != c.expect {
+				t.Errorf("body mismatch.\nexpected: %s\ngot:      %s", c.expect, got)
+			}
+		})
+	}
+
+	// keys only declared in properties are sorted
+	st := &dataset.Structure{Format: "json", Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"zone":    map[string]interface{}{"type": "integer"},
+				"stop_id": map[string]interface{}{"type": "string"},
+			},
+		},
+	}}
+	buf := &bytes.Buffer{}
+	w, err := NewJSONWriter(st, buf, WithSchemaKeyOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteEntry(Entry{Value: map[string]interface{}{"zone": 2, "stop_id": "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if expect := `[{"stop_id":"b","zone":2}]`; buf.String() != expect {
+		t.Errorf("properties body mismatch.\nexpected: %s\ngot:      %s", expect, buf.String())
+	}
+
+	buf = &bytes.Buffer{}
+	w, err = NewJSONPrettyWriter(keyOrderStructure("json"), buf, "  ", WithSchemaKeyOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteEntry(Entry{Value: map[string]interface{}{"name": "x", "zone": 2}}); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	expect := "[\n  {\n    \"zone\": 2,\n    \"name\": \"x\"\n  }\n]"
+	if got := buf.String(); got != expect {
+		t.Errorf("pretty body mismatch.\nexpected: %q\ngot:      %q", expect, got)
+	}
+}
+
+func TestCBORWriterSchemaKeyOrder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewEntryWriter(keyOrderStructure("cbor"), buf, WithSchemaKeyOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range keyOrderRows() {
+		if err := w.WriteEntry(Entry{Index: i, Value: row}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectOrder(t, buf.Bytes(), "zone", "stop_id", "name", "alias", "extra", "location", "lon", "lat")
+
+	r, err := NewEntryReader(keyOrderStructure("cbor"), bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row, ok := ent.Value.(map[string]interface{}); !ok || row["name"] != "Hauptbahnhof" || len(row) != 6 {
+		t.Errorf("expected ordered row to read back as an object, got: %#v", ent.Value)
+	}
+
+	st := &dataset.Structure{Format: "cbor", Schema: dataset.BaseSchemaObject}
+	buf.Reset()
+	w, err = NewEntryWriter(st, buf, WithSchemaKeyOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"c", "a", "b"} {
+		if err := w.WriteEntry(Entry{Key: key, Value: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []byte{0xa3, 0x61, 'c', 0x61, 'c', 0x61, 'a', 0x61, 'a', 0x61, 'b', 0x61, 'b'}; !bytes.Equal(expect, buf.Bytes()) {
+		t.Errorf("object body mismatch. expected: %x, got: %x", expect, buf.Bytes())
+	}
+}
+
+// expectOrder checks keys first appear in data in order
+func expectOrder(t *testing.T, data []byte, keys ...string) {
+	t.Helper()
+	last := -1
+	for _, key := range keys {
+		i := bytes.Index(data, []byte(key))
+		if i <= last {
+			t.Errorf("expected key %q after position %d, found at %d", key, last, i)
+			return
+		}
+		last = i
+	}
+}
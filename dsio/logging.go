@@ -0,0 +1,63 @@
+package dsio
+
+import (
+	"sync/atomic"
+
+	logger "github.com/ipfs/go-log"
+)
+
+// Logger is the interface dsio writes logs to. The methods match the ipfs
+// go-log logger dsio uses by default, and are satisfied by common leveled
+// loggers like zap's SugaredLogger
+type Logger interface {
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// defaultLogger writes to the "dsio" go-log subsystem
+var defaultLogger Logger = logger.Logger("dsio")
+
+// log is the package logger, forwarding to the logger set with SetLogger
+var log = &switchLogger{}
+
+// SetLogger replaces the logger dsio writes to. A nil logger restores the
+// default. SetLogger is safe to call while readers & writers are in use
+func SetLogger(l Logger) {
+	if l == nil {
+		l = defaultLogger
+	}
+	log.v.Store(loggerBox{l})
+}
+
+// loggerBox gives atomic.Value a consistent concrete type to store
+type loggerBox struct{ Logger }
+
+// switchLogger forwards logs to a replaceable logger
+type switchLogger struct {
+	v atomic.Value
+}
+
+func (s *switchLogger) logger() Logger {
+	if b, ok := s.v.Load().(loggerBox); ok {
+		return b.Logger
+	}
+	return defaultLogger
+}
+
+func (s *switchLogger) Debug(args ...interface{}) {
+	s.logger().Debug(args...)
+}
+
+func (s *switchLogger) Debugf(format string, args ...interface{}) {
+	s.logger().Debugf(format, args...)
+}
+
+func (s *switchLogger) Error(args ...interface{}) {
+	s.logger().Error(args...)
+}
+
+func (s *switchLogger) Errorf(format string, args ...interface{}) {
+	s.logger().Errorf(format, args...)
+}
@@ -0,0 +1,52 @@
+package dsio
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Debug(args ...interface{}) {
+	l.lines = append(l.lines, "debug: "+fmt.Sprint(args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.lines = append(l.lines, "debug: "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Error(args ...interface{}) {
+	l.lines = append(l.lines, "error: "+fmt.Sprint(args...))
+}
+
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.lines = append(l.lines, "error: "+fmt.Sprintf(format, args...))
+}
+
+func TestSetLogger(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+
+	if _, err := NewEntryReader(&dataset.Structure{Format: "xml"}, strings.NewReader("")); err == nil {
+		t.Fatal("expected error creating a reader for an unsupported format")
+	}
+	expect := []string{"debug: invalid format to create reader: xml"}
+	if len(rec.lines) != 1 || rec.lines[0] != expect[0] {
+		t.Errorf("log mismatch. expected: %v, got: %v", expect, rec.lines)
+	}
+
+	SetLogger(nil)
+	if log.logger() != defaultLogger {
+		t.Errorf("expected nil logger to restore the default")
+	}
+	NewEntryReader(&dataset.Structure{Format: "xml"}, strings.NewReader(""))
+	if len(rec.lines) != 1 {
+		t.Errorf("expected logs to stop after the logger is replaced, got: %v", rec.lines)
+	}
+}
@@ -0,0 +1,279 @@
+package dsio
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+// NewMappingReader wraps r, computing the columns of a mapping from each
+// entry as it's read. Entries are read as array rows with a tabular schema
+// of the mapping columns. Source entries can be array rows, which require a
+// tabular schema to locate columns, or object rows. Null source cells map to
+// null, as do splits & extracts with no part to keep
+func NewMappingReader(r EntryReader, m *dataset.Mapping) (EntryReader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("mapping: reader is required")
+	}
+	if m == nil {
+		return nil, fmt.Errorf("mapping: mapping is required")
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("mapping: %w", err)
+	}
+
+	mr := &mappingReader{r: r, m: m}
+	st := r.Structure()
+	if st == nil {
+		st = &dataset.Structure{}
+	}
+	if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+		mr.index = map[string]int{}
+		for i, title := range cols.Titles() {
+			mr.index[title] = i
+		}
+		for _, name := range m.SourceColumns() {
+			if _, ok := mr.index[name]; !ok {
+				return nil, fmt.Errorf("mapping: column %q not found", name)
+			}
+		}
+	}
+
+	sources := columnSchemas(st)
+	cols := make([]interface{}, len(m.Columns))
+	mr.patterns = make([]*regexp.Regexp, len(m.Columns))
+	mr.constants = make([]interface{}, len(m.Columns))
+	for i, c := range m.Columns {
+		col := map[string]interface{}{"title": c.Title}
+		var typ interface{} = c.Type
+		switch c.Op {
+		case dataset.MapRename:
+			for key, val := range sources[c.From] {
+				col[key] = val
+			}
+			col["title"] = c.Title
+			typ = col["type"]
+			if c.Type != "" {
+				typ = castType(col["type"], c.Type)
+			}
+		case dataset.MapCast:
+			typ = castType(sourceType(sources[c.From]), c.Type)
+		case dataset.MapSplit, dataset.MapExtract:
+			if c.Type == "" {
+				typ = "string"
+			}
+			typ = nullable(typ)
+			if c.Op == dataset.MapExtract {
+				mr.patterns[i] = regexp.MustCompile(c.Pattern)
+			}
+		case dataset.MapConcat:
+			if c.Type == "" {
+				typ = "string"
+			}
+		case dataset.MapConstant:
+			v, err := mappingConstant(c)
+			if err != nil {
+				return nil, fmt.Errorf("mapping: column %q: %w", c.Title, err)
+			}
+			mr.constants[i] = v
+			typ = valueType(v)
+		}
+		if typ == nil || typ == "" {
+			delete(col, "type")
+		} else {
+			col["type"] = typ
+		}
+		cols[i] = col
+	}
+	mr.st = reshapedStructure(st, cols, 0)
+	return mr, nil
+}
+
+// castType gives the JSON schema type of a column of type src cast to t.
+// casting keeps nulls, so columns that may hold nulls stay nullable
+func castType(src interface{}, t string) interface{} {
+	switch x := src.(type) {
+	case string:
+		if x == "null" {
+			return nullable(t)
+		}
+		return t
+	case []interface{}:
+		if containsNull(x) {
+			return nullable(t)
+		}
+		return t
+	}
+	return nullable(t)
+}
+
+// sourceType gives the type of a source column schema, nil if unknown
+func sourceType(col map[string]interface{}) interface{} {
+	if col == nil {
+		return nil
+	}
+	return col["type"]
+}
+
+// mappingConstant gives the value of a constant column. decoded scripts read
+// integral numbers as float64, which are converted to int64 like readers
+// give integers
+func mappingConstant(c *dataset.MappingColumn) (interface{}, error) {
+	if c.Value == nil {
+		return nil, nil
+	}
+	if c.Type != "" {
+		return castValue(c.Value, c.Type)
+	}
+	if f, ok := c.Value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f), nil
+	}
+	return c.Value, nil
+}
+
+// valueType gives the JSON schema type of a constant value
+func valueType(v interface{}) interface{} {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return nil
+}
+
+// castValue converts a non-null value to a mapping type
+func castValue(v interface{}, t string) (interface{}, error) {
+	switch t {
+	case "integer":
+		return vals.ToInt(v)
+	case "number":
+		return vals.ToFloat(v)
+	case "boolean":
+		return vals.ToBool(v)
+	case "string":
+		return vals.ToString(v)
+	}
+	return v, nil
+}
+
+// mappingReader computes mapping columns from entries
+type mappingReader struct {
+	r         EntryReader
+	m         *dataset.Mapping
+	st        *dataset.Structure
+	index     map[string]int
+	patterns  []*regexp.Regexp
+	constants []interface{}
+	read      int
+}
+
+var _ EntryReader = (*mappingReader)(nil)
+
+// Structure gives the structure of mapped entries
+func (r *mappingReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next entry, mapped to a row of mapping columns
+func (r *mappingReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	_, get, _, err := rowAccessors(r.index, ent.Value)
+	if err != nil {
+		return ent, fmt.Errorf("mapping: entry %d: %w", i, err)
+	}
+	row := make([]interface{}, len(r.m.Columns))
+	for j, c := range r.m.Columns {
+		if row[j], err = r.column(j, c, get); err != nil {
+			return ent, fmt.Errorf("mapping: entry %d column %q: %w", i, c.Title, err)
+		}
+	}
+	return Entry{Index: i, Value: row}, nil
+}
+
+// column computes the value of the j-th column
+func (r *mappingReader) column(j int, c *dataset.MappingColumn, get func(string) interface{}) (v interface{}, err error) {
+	switch c.Op {
+	case dataset.MapConstant:
+		return r.constants[j], nil
+	case dataset.MapConcat:
+		parts := make([]string, 0, len(c.Sources))
+		for _, src := range c.Sources {
+			cell := get(src)
+			if cell == nil {
+				continue
+			}
+			s, err := vals.ToString(cell)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, s)
+		}
+		v = strings.Join(parts, c.Separator)
+	default:
+		if v = get(c.From); v == nil {
+			return nil, nil
+		}
+		switch c.Op {
+		case dataset.MapSplit:
+			s, err := vals.ToString(v)
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.Split(s, c.Separator)
+			idx := c.Index
+			if idx < 0 {
+				idx += len(parts)
+			}
+			if idx < 0 || idx >= len(parts) {
+				return nil, nil
+			}
+			v = parts[idx]
+		case dataset.MapExtract:
+			s, err := vals.ToString(v)
+			if err != nil {
+				return nil, err
+			}
+			re := r.patterns[j]
+			match := re.FindStringSubmatch(s)
+			if match == nil {
+				return nil, nil
+			}
+			group := c.Group
+			if group == 0 && re.NumSubexp() > 0 {
+				group = 1
+			}
+			v = match[group]
+		}
+	}
+	if c.Type == "" {
+		return v, nil
+	}
+	return castValue(v, c.Type)
+}
+
+// Close closes the underlying reader
+func (r *mappingReader) Close() error {
+	return r.r.Close()
+}
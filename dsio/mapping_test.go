@@ -0,0 +1,116 @@
+package dsio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+const mappingBody = `[
+	["de:08111:6115", "Stuttgart Hbf", "48.784", "Daimler, Gottlieb"],
+	["x", "Esslingen", null, "Bosch, Robert"]
+]`
+
+func mappingTableReader(t *testing.T) EntryReader {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "stop_id", "type": "string"},
+					map[string]interface{}{"title": "stop_name", "type": "string", "description": "name of the stop"},
+					map[string]interface{}{"title": "stop_lat", "type": []interface{}{"string", "null"}},
+					map[string]interface{}{"title": "founder", "type": "string"},
+				},
+			},
+		},
+	}
+	r, err := NewJSONReader(st, bytes.NewBufferString(mappingBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMappingReader(t *testing.T) {
+	m := &dataset.Mapping{Columns: []*dataset.MappingColumn{
+		{Title: "name", Op: dataset.MapRename, From: "stop_name"},
+		{Title: "lat", Op: dataset.MapCast, From: "stop_lat", Type: "number"},
+		{Title: "municipality", Op: dataset.MapExtract, From: "stop_id", Pattern: `^de:(\d+)`, Type: "integer"},
+		{Title: "last_part", Op: dataset.MapSplit, From: "stop_id", Separator: ":", Index: -1},
+		{Title: "surname", Op: dataset.MapSplit, From: "founder", Separator: ", "},
+		{Title: "label", Op: dataset.MapConcat, Sources: []string{"stop_name", "stop_lat"}, Separator: " @ "},
+		{Title: "feed", Op: dataset.MapConstant, Value: float64(3)},
+	}}
+	r, err := Pipeline(mappingTableReader(t)).Map(m).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		[]interface{}{"Stuttgart Hbf", 48.784, int64(8111), "6115", "Daimler", "Stuttgart Hbf @ 48.784", int64(3)},
+		[]interface{}{"Esslingen", nil, nil, "x", "Bosch", "Esslingen", int64(3)},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("mapped body mismatch.\nexpected: %#v\ngot:      %#v", expect, got)
+	}
+
+	expectCols := []interface{}{
+		map[string]interface{}{"title": "name", "type": "string", "description": "name of the stop"},
+		map[string]interface{}{"title": "lat", "type": []interface{}{"number", "null"}},
+		map[string]interface{}{"title": "municipality", "type": []interface{}{"integer", "null"}},
+		map[string]interface{}{"title": "last_part", "type": []interface{}{"string", "null"}},
+		map[string]interface{}{"title": "surname", "type": []interface{}{"string", "null"}},
+		map[string]interface{}{"title": "label", "type": "string"},
+		map[string]interface{}{"title": "feed", "type": "integer"},
+	}
+	cols := r.Structure().Schema["items"].(map[string]interface{})["items"]
+	if !reflect.DeepEqual(expectCols, cols) {
+		t.Errorf("mapped schema mismatch.\nexpected: %#v\ngot:      %#v", expectCols, cols)
+	}
+}
+
+func TestMappingReaderObjects(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	src, err := NewJSONReader(st, bytes.NewBufferString(`[{"n": "7"}, {}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &dataset.Mapping{Columns: []*dataset.MappingColumn{{Title: "n", Op: dataset.MapCast, From: "n", Type: "integer"}}}
+	r, err := NewMappingReader(src, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []interface{}{[]interface{}{int64(7)}, []interface{}{nil}}; !reflect.DeepEqual(expect, got) {
+		t.Errorf("mapped body mismatch. expected: %#v, got: %#v", expect, got)
+	}
+}
+
+func TestMappingReaderErrors(t *testing.T) {
+	if _, err := NewMappingReader(mappingTableReader(t), nil); err == nil || err.Error() != "mapping: mapping is required" {
+		t.Errorf("expected a missing mapping error, got: %v", err)
+	}
+	m := &dataset.Mapping{Columns: []*dataset.MappingColumn{{Title: "a", Op: dataset.MapRename, From: "stop_code"}}}
+	if _, err := NewMappingReader(mappingTableReader(t), m); err == nil || err.Error() != `mapping: column "stop_code" not found` {
+		t.Errorf("expected a missing column error, got: %v", err)
+	}
+	m = &dataset.Mapping{Columns: []*dataset.MappingColumn{{Title: "a", Op: dataset.MapCast, From: "stop_name", Type: "integer"}}}
+	r, err := NewMappingReader(mappingTableReader(t), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadEntry(); err == nil {
+		t.Errorf("expected a cast error")
+	}
+}
@@ -0,0 +1,215 @@
+package dsio
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+// StreamStats measures one pass of an entry reader or writer over a body
+type StreamStats struct {
+	// Format is the data format of the body
+	Format string
+	// Entries is the number of entries read or written
+	Entries int
+	// Bytes is the number of body bytes consumed or produced. Readers buffer
+	// ahead, so a reader that stops early can consume more bytes than the
+	// entries it read
+	Bytes int64
+	// Elapsed is the time from the first entry to the end of the stream
+	Elapsed time.Duration
+	// Err is the error that ended the stream, nil if the stream finished or
+	// was closed
+	Err error
+}
+
+// EntriesPerSecond gives the rate entries were read or written, 0 if no
+// time has elapsed
+func (s StreamStats) EntriesPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Entries) / s.Elapsed.Seconds()
+}
+
+// Metrics are optional callbacks for measuring the readers & writers
+// created by NewEntryReader & NewEntryWriter. nil callbacks are skipped
+type Metrics struct {
+	// Read is called once per reader, when the reader reaches the end of the
+	// body, fails, or is closed
+	Read func(StreamStats)
+	// Write is called once per writer, when the writer fails or is closed
+	Write func(StreamStats)
+}
+
+// metrics holds a metricsBox
+var metrics atomic.Value
+
+// metricsBox gives atomic.Value a consistent concrete type to store
+type metricsBox struct{ *Metrics }
+
+// SetMetrics sets the callbacks dsio reports measurements to, nil disables
+// metrics. Readers & writers created while metrics are set are wrapped to
+// take measurements, so they can't be type-asserted to their format-specific
+// types. Reader & writer constructors for specific formats are never measured
+func SetMetrics(m *Metrics) {
+	metrics.Store(metricsBox{m})
+}
+
+func currentMetrics() *Metrics {
+	b, _ := metrics.Load().(metricsBox)
+	return b.Metrics
+}
+
+// meterReader wraps the reader returned by open to report to m.Read
+func meterReader(m *Metrics, st *dataset.Structure, r io.Reader, open func(io.Reader) (EntryReader, error)) (EntryReader, error) {
+	mr := &meteredReader{report: m.Read, stats: StreamStats{Format: st.Format}}
+	if mf, ok := r.(*MappedFile); ok {
+		// mapped file readers may parse the file in place. count the whole
+		// file as consumed
+		n := int64(len(mf.unread()))
+		mr.bytes = func() int64 { return n }
+	} else {
+		tr := NewTrackedReader(r)
+		mr.bytes = func() int64 { return int64(tr.BytesRead()) }
+		r = tr
+	}
+	er, err := open(r)
+	if err != nil {
+		return nil, err
+	}
+	mr.r = er
+	return mr, nil
+}
+
+// meteredReader measures reads from an entry reader
+type meteredReader struct {
+	r        EntryReader
+	report   func(StreamStats)
+	bytes    func() int64
+	stats    StreamStats
+	start    time.Time
+	reported bool
+}
+
+var _ EntryReader = (*meteredReader)(nil)
+
+// Structure gives the structure of the underlying reader
+func (r *meteredReader) Structure() *dataset.Structure {
+	return r.r.Structure()
+}
+
+// ReadEntry reads an entry from the underlying reader
+func (r *meteredReader) ReadEntry() (Entry, error) {
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	ent, err := r.r.ReadEntry()
+	if err == io.EOF {
+		r.finish(nil)
+	} else if err != nil {
+		r.finish(err)
+	} else {
+		r.stats.Entries++
+	}
+	return ent, err
+}
+
+// Close closes the underlying reader
+func (r *meteredReader) Close() error {
+	r.finish(nil)
+	return r.r.Close()
+}
+
+func (r *meteredReader) lendEntries() {
+	if l, ok := r.r.(lender); ok {
+		l.lendEntries()
+	}
+}
+
+func (r *meteredReader) finish(err error) {
+	if r.reported {
+		return
+	}
+	r.reported = true
+	r.stats.Bytes = r.bytes()
+	if !r.start.IsZero() {
+		r.stats.Elapsed = time.Since(r.start)
+	}
+	r.stats.Err = err
+	r.report(r.stats)
+}
+
+// meterWriter wraps the writer returned by open to report to m.Write
+func meterWriter(m *Metrics, st *dataset.Structure, w io.Writer, open func(io.Writer) (EntryWriter, error)) (EntryWriter, error) {
+	cw := &countingWriter{w: w}
+	ew, err := open(cw)
+	if err != nil {
+		return nil, err
+	}
+	return &meteredWriter{w: ew, cw: cw, report: m.Write, stats: StreamStats{Format: st.Format}}, nil
+}
+
+// countingWriter counts bytes written to a writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// meteredWriter measures writes to an entry writer
+type meteredWriter struct {
+	w        EntryWriter
+	cw       *countingWriter
+	report   func(StreamStats)
+	stats    StreamStats
+	start    time.Time
+	reported bool
+}
+
+var _ EntryWriter = (*meteredWriter)(nil)
+
+// Structure gives the structure of the underlying writer
+func (w *meteredWriter) Structure() *dataset.Structure {
+	return w.w.Structure()
+}
+
+// WriteEntry writes an entry to the underlying writer
+func (w *meteredWriter) WriteEntry(ent Entry) error {
+	if w.start.IsZero() {
+		w.start = time.Now()
+	}
+	if err := w.w.WriteEntry(ent); err != nil {
+		w.finish(err)
+		return err
+	}
+	w.stats.Entries++
+	return nil
+}
+
+// Close closes the underlying writer, which may write remaining bytes
+func (w *meteredWriter) Close() error {
+	err := w.w.Close()
+	w.finish(err)
+	return err
+}
+
+func (w *meteredWriter) finish(err error) {
+	if w.reported {
+		return
+	}
+	w.reported = true
+	w.stats.Bytes = w.cw.n
+	if !w.start.IsZero() {
+		w.stats.Elapsed = time.Since(w.start)
+	}
+	w.stats.Err = err
+	w.report(w.stats)
+}
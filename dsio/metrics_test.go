@@ -0,0 +1,85 @@
+package dsio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestMetrics(t *testing.T) {
+	var reads, writes []StreamStats
+	SetMetrics(&Metrics{
+		Read:  func(s StreamStats) { reads = append(reads, s) },
+		Write: func(s StreamStats) { writes = append(writes, s) },
+	})
+	defer SetMetrics(nil)
+
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	body := `[1,2,3]`
+	r, err := NewEntryReader(st, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !BorrowEntries(r) {
+		t.Errorf("expected metered readers to support borrowed entries")
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewEntryWriter(st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Copy(r, w); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reads) != 1 {
+		t.Fatalf("expected one read report, got: %v", reads)
+	}
+	if got := reads[0]; got.Format != "json" || got.Entries != 3 || got.Bytes != int64(len(body)) || got.Err != nil {
+		t.Errorf("read stats mismatch. got: %#v", got)
+	}
+	if len(writes) != 1 {
+		t.Fatalf("expected one write report, got: %v", writes)
+	}
+	if got := writes[0]; got.Entries != 3 || got.Bytes != int64(buf.Len()) || got.Err != nil {
+		t.Errorf("write stats mismatch. got: %#v", got)
+	}
+
+	r, err = NewEntryReader(st, strings.NewReader(`[1,`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, err = r.ReadEntry()
+	}
+	r.Close()
+	if len(reads) != 2 || reads[1].Err == nil {
+		t.Errorf("expected a failed read to report its error once, got: %v", reads)
+	}
+
+	SetMetrics(nil)
+	if r, err := NewEntryReader(st, strings.NewReader(body)); err != nil {
+		t.Fatal(err)
+	} else if _, ok := r.(*JSONReader); !ok {
+		t.Errorf("expected readers to be unwrapped with metrics disabled, got: %T", r)
+	}
+}
+
+func TestStreamStatsEntriesPerSecond(t *testing.T) {
+	if got := (StreamStats{Entries: 10, Elapsed: 2 * time.Second}).EntriesPerSecond(); got != 5 {
+		t.Errorf("expected 5 entries per second, got: %v", got)
+	}
+	if got := (StreamStats{Entries: 10}).EntriesPerSecond(); got != 0 {
+		t.Errorf("expected 0 without elapsed time, got: %v", got)
+	}
+}
@@ -0,0 +1,220 @@
+package dsio
+
+import (
+	"fmt"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// MigrateConfig configures a body migration
+type MigrateConfig struct {
+	// Renames maps the titles of new columns to the old columns they were
+	// renamed from. Columns with the same title in both structures don't need
+	// a rename
+	Renames map[string]string
+	// Defaults fill new columns that have no old column. New columns without
+	// a default are filled with null, which their schema type must allow
+	Defaults map[string]interface{}
+}
+
+// WithRenames sets the old column each renamed column is read from
+func WithRenames(renames map[string]string) func(*MigrateConfig) {
+	return func(c *MigrateConfig) {
+		c.Renames = renames
+	}
+}
+
+// WithDefaults sets fill values of new columns
+func WithDefaults(defaults map[string]interface{}) func(*MigrateConfig) {
+	return func(c *MigrateConfig) {
+		c.Defaults = defaults
+	}
+}
+
+// MigrationColumn describes how a column of a migrated body is filled
+type MigrationColumn struct {
+	// Title of the new column
+	Title string
+	// From is the title of the old column values are read from, empty for
+	// new columns
+	From string
+	// Default fills new columns
+	Default interface{}
+}
+
+// Migration adapts bodies of an old structure to a new one
+type Migration struct {
+	// Columns are the new columns, in new column order
+	Columns []MigrationColumn
+	// Dropped lists old columns the new structure doesn't read, in old
+	// column order. Dropped is empty when old rows are objects
+	Dropped []string
+
+	from, to *dataset.Structure
+	index    map[string]int
+}
+
+// PlanMigration matches the columns of a new tabular structure to the columns
+// of an old structure, by title or by rename. Old bodies can have array rows
+// with a tabular schema, or object rows. Values are kept as they are, chain
+// PipelineBuilder.Coerce to convert migrated values to new column types
+func PlanMigration(from, to *dataset.Structure, opts ...func(*MigrateConfig)) (*Migration, error) {
+	cfg := &MigrateConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if from == nil {
+		return nil, fmt.Errorf("migrate: old structure is required")
+	}
+	newCols, _, err := schemaColumns(to)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: new structure: %w", err)
+	}
+
+	m := &Migration{from: from, to: to}
+	var oldTitles []string
+	if oldCols, _, err := tabular.ColumnsFromJSONSchema(from.Schema); err == nil {
+		oldTitles = oldCols.Titles()
+		m.index = map[string]int{}
+		for i, title := range oldTitles {
+			m.index[title] = i
+		}
+	}
+	hasOld := func(title string) bool {
+		if m.index == nil {
+			// object rows can have any key
+			return true
+		}
+		_, ok := m.index[title]
+		return ok
+	}
+
+	titles := map[string]bool{}
+	for _, title := range newCols.Titles() {
+		titles[title] = true
+	}
+	for title, old := range cfg.Renames {
+		if !titles[title] {
+			return nil, fmt.Errorf("migrate: renamed column %q is not a new column", title)
+		}
+		if !hasOld(old) {
+			return nil, fmt.Errorf("migrate: column %q is renamed from %q, which isn't an old column", title, old)
+		}
+	}
+
+	read := map[string]bool{}
+	for _, col := range newCols {
+		mc := MigrationColumn{Title: col.Title}
+		if old, ok := cfg.Renames[col.Title]; ok {
+			mc.From = old
+		} else if m.index != nil && hasOld(col.Title) {
+			mc.From = col.Title
+		} else if m.index == nil && cfg.Defaults[col.Title] == nil {
+			// object rows are read by title unless a default says the
+			// column is new
+			mc.From = col.Title
+		}
+		if mc.From == "" {
+			mc.Default = cfg.Defaults[col.Title]
+			if mc.Default == nil && !allowsNull(colType(col.Type)) {
+				return nil, fmt.Errorf("migrate: new column %q requires a default, it's type doesn't allow null", col.Title)
+			}
+		}
+		read[mc.From] = true
+		m.Columns = append(m.Columns, mc)
+	}
+	for _, title := range oldTitles {
+		if !read[title] {
+			m.Dropped = append(m.Dropped, title)
+		}
+	}
+	return m, nil
+}
+
+// allowsNull reports whether a JSON schema type value accepts null. Columns
+// without a type accept any value
+func allowsNull(t interface{}) bool {
+	switch x := t.(type) {
+	case nil:
+		return true
+	case string:
+		return x == "null"
+	case []interface{}:
+		for _, s := range x {
+			if s == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewMigrateReader adapts entries of r, an old body, to the new structure to,
+// reading rows in new column order. See PlanMigration for how columns are
+// matched. The new structure describes migrated entries, without values
+// derived from a stored body
+func NewMigrateReader(r EntryReader, to *dataset.Structure, opts ...func(*MigrateConfig)) (EntryReader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("migrate: reader is required")
+	}
+	m, err := PlanMigration(r.Structure(), to, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Reader(r)
+}
+
+// Reader wraps an old body reader, reading migrated entries
+func (m *Migration) Reader(r EntryReader) (EntryReader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("migrate: reader is required")
+	}
+	st := derivedStructure(m.to)
+	st.Entries = m.from.Entries
+	return &migrateReader{r: r, m: m, st: st}, nil
+}
+
+// migrateReader reads old rows as new rows
+type migrateReader struct {
+	r    EntryReader
+	m    *Migration
+	st   *dataset.Structure
+	read int
+}
+
+var _ EntryReader = (*migrateReader)(nil)
+
+// Structure gives the new structure
+func (r *migrateReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next old row, arranged in new column order
+func (r *migrateReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	_, get, _, err := rowAccessors(r.m.index, ent.Value)
+	if err != nil {
+		return ent, fmt.Errorf("migrate: entry %d: %w", i, err)
+	}
+	row := make([]interface{}, len(r.m.Columns))
+	for j, c := range r.m.Columns {
+		if c.From == "" {
+			row[j] = c.Default
+			continue
+		}
+		row[j] = get(c.From)
+	}
+	return Entry{Index: i, Value: row}, nil
+}
+
+// Close closes the old body reader
+func (r *migrateReader) Close() error {
+	return r.r.Close()
+}
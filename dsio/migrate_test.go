@@ -0,0 +1,109 @@
+package dsio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func tabularStructure(cols ...interface{}) *dataset.Structure {
+	return &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": cols},
+		},
+	}
+}
+
+func TestMigrateReader(t *testing.T) {
+	data := "stop_id,name,zone\na,Hauptbahnhof,1\nb,Feuersee,2\n"
+	to := tabularStructure(
+		map[string]interface{}{"title": "stop_name", "type": "string"},
+		map[string]interface{}{"title": "stop_id", "type": "string"},
+		map[string]interface{}{"title": "wheelchair", "type": "integer"},
+		map[string]interface{}{"title": "platform", "type": []interface{}{"string", "null"}},
+	)
+
+	src := stringCSVReader(t, data, "stop_id", "name", "zone")
+	m, err := PlanMigration(src.Structure(), to, WithRenames(map[string]string{"stop_name": "name"}), WithDefaults(map[string]interface{}{"wheelchair": 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCols := []MigrationColumn{{Title: "stop_name", From: "name"}, {Title: "stop_id", From: "stop_id"}, {Title: "wheelchair", Default: 0}, {Title: "platform"}}
+	if !reflect.DeepEqual(expectCols, m.Columns) {
+		t.Errorf("columns mismatch. expected: %v, got: %v", expectCols, m.Columns)
+	}
+	if expect := []string{"zone"}; !reflect.DeepEqual(expect, m.Dropped) {
+		t.Errorf("dropped mismatch. expected: %v, got: %v", expect, m.Dropped)
+	}
+
+	r, err := m.Reader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readTitles(t, r.Structure()); !reflect.DeepEqual([]string{"stop_name", "stop_id", "wheelchair", "platform"}, got) {
+		t.Errorf("structure titles mismatch. got: %v", got)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		[]interface{}{"Hauptbahnhof", "a", 0, nil},
+		[]interface{}{"Feuersee", "b", 0, nil},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("rows mismatch. expected: %v, got: %v", expect, got)
+	}
+}
+
+func TestMigrateReaderObjectRows(t *testing.T) {
+	from := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	src := &sliceReader{st: from, rows: []interface{}{
+		map[string]interface{}{"id": "a", "label": "x"},
+		map[string]interface{}{"id": "b"},
+	}}
+	to := tabularStructure(
+		map[string]interface{}{"title": "id", "type": "string"},
+		map[string]interface{}{"title": "name"},
+		map[string]interface{}{"title": "source", "type": "string"},
+	)
+	r, err := Pipeline(src).Migrate(to, WithRenames(map[string]string{"name": "label"}), WithDefaults(map[string]interface{}{"source": "vvs"})).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		[]interface{}{"a", "x", "vvs"},
+		[]interface{}{"b", nil, "vvs"},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("rows mismatch. expected: %v, got: %v", expect, got)
+	}
+}
+
+func TestPlanMigrationErrors(t *testing.T) {
+	from := tabularStructure(map[string]interface{}{"title": "a", "type": "string"})
+	cases := []struct {
+		from, to *dataset.Structure
+		opts     []func(*MigrateConfig)
+		err      string
+	}{
+		{nil, from, nil, "migrate: old structure is required"},
+		{from, &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}, nil, "migrate: new structure: unfinished"},
+		{from, tabularStructure(map[string]interface{}{"title": "b", "type": "string"}), nil, `migrate: new column "b" requires a default, it's type doesn't allow null`},
+		{from, from, []func(*MigrateConfig){WithRenames(map[string]string{"b": "a"})}, `migrate: renamed column "b" is not a new column`},
+		{from, tabularStructure(map[string]interface{}{"title": "b"}), []func(*MigrateConfig){WithRenames(map[string]string{"b": "c"})}, `migrate: column "b" is renamed from "c", which isn't an old column`},
+	}
+	for i, c := range cases {
+		_, err := PlanMigration(c.from, c.to, c.opts...)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
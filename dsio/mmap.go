@@ -0,0 +1,101 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"unsafe"
+)
+
+// MappedFile is a file-backed body held in memory. On platforms that support
+// it the file is memory-mapped, otherwise it's read into memory when opened.
+// Readers given a MappedFile can avoid copying the body: CSVReader slices
+// fields directly out of the mapped bytes. Values read from a mapped file
+// reference its memory & must not be used after the file is closed
+type MappedFile struct {
+	data   []byte
+	str    string
+	r      *bytes.Reader
+	mapped bool
+}
+
+var _ io.ReadCloser = (*MappedFile)(nil)
+
+// OpenMappedFile maps the file at path into memory for reading, falling back
+// to reading the whole file if it can't be mapped
+func OpenMappedFile(path string) (*MappedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	mf := &MappedFile{}
+	if size := fi.Size(); size > 0 && fi.Mode().IsRegular() && int64(int(size)) == size {
+		if data, err := mmap(f, int(size)); err == nil {
+			mf.data = data
+			mf.mapped = true
+		} else {
+			log.Debugf("mapping %s: %s. reading into memory instead", path, err)
+		}
+	}
+	if !mf.mapped {
+		if mf.data, err = ioutil.ReadAll(f); err != nil {
+			return nil, err
+		}
+	}
+	mf.str = bytesToString(mf.data)
+	mf.r = bytes.NewReader(mf.data)
+	return mf, nil
+}
+
+// Read implements the io.Reader interface
+func (f *MappedFile) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+// Bytes gives the full contents of the file. The returned slice must not be
+// modified
+func (f *MappedFile) Bytes() []byte {
+	return f.data
+}
+
+// Mapped reports whether the file is memory-mapped, false if the file was
+// read into memory instead
+func (f *MappedFile) Mapped() bool {
+	return f.mapped
+}
+
+// Close releases the file's memory. Closing a file more than once is a no-op
+func (f *MappedFile) Close() error {
+	var err error
+	if f.mapped {
+		err = munmap(f.data)
+		f.mapped = false
+	}
+	f.data = nil
+	f.str = ""
+	f.r = bytes.NewReader(nil)
+	return err
+}
+
+// unread gives the contents of the file that haven't been consumed by Read
+// as a string that shares memory with the file
+func (f *MappedFile) unread() string {
+	return f.str[len(f.str)-f.r.Len():]
+}
+
+// bytesToString converts b to a string without copying. b must never be
+// modified afterward
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
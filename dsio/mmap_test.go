@@ -0,0 +1,138 @@
+package dsio
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestMappedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsio_mmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "body.csv")
+	data := "stop_id,name,capacity\n1,\"Hbf, Gleis 1\",12\n2,Nord,\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mf, err := OpenMappedFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS == "linux" && !mf.Mapped() {
+		t.Errorf("expected file to be memory-mapped")
+	}
+	if string(mf.Bytes()) != data {
+		t.Errorf("bytes mismatch. got: %q", mf.Bytes())
+	}
+
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "stop_id", "type": "string"},
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "capacity", "type": "integer"},
+				},
+			},
+		},
+	}
+	r, err := NewEntryReader(st, mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.(*CSVReader).r.(*mappedCSVReader); !ok {
+		t.Errorf("expected csv reader to parse the mapped file in place")
+	}
+	got, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		[]interface{}{"1", "Hbf, Gleis 1", int64(12)},
+		[]interface{}{"2", "Nord", ""},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("rows mismatch.\nexpected: %v\ngot:      %v", expect, got)
+	}
+
+	if err := mf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mf.Close(); err != nil {
+		t.Errorf("expected closing twice to be a no-op, got: %s", err)
+	}
+	if mf.Mapped() || mf.Bytes() != nil {
+		t.Errorf("expected close to release file memory")
+	}
+}
+
+func TestMappedFileEmpty(t *testing.T) {
+	f, err := ioutil.TempFile("", "dsio_mmap_empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	mf, err := OpenMappedFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mf.Close()
+	if mf.Mapped() {
+		t.Errorf("expected empty files to be read, not mapped")
+	}
+	r := newMappedCSVReader(mf.unread(), ',', false, false)
+	if _, err := r.Read(); err == nil {
+		t.Errorf("expected EOF reading an empty file")
+	}
+
+	if _, err := OpenMappedFile(filepath.Join(os.TempDir(), "dsio_mmap_missing")); err == nil {
+		t.Errorf("expected error opening a missing file")
+	}
+}
+
+func BenchmarkCSVReaderMapped(b *testing.B) {
+	st := &dataset.Structure{
+		Format: "csv",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "movie_title", "type": "string"},
+					map[string]interface{}{"title": "duration", "type": "integer"},
+				},
+			},
+		},
+	}
+
+	for n := 0; n < b.N; n++ {
+		file, err := OpenMappedFile("testdata/movies/body.csv")
+		if err != nil {
+			b.Errorf("unexpected error: %s", err.Error())
+		}
+		r, _ := NewCSVReader(st, file)
+		for {
+			_, err = r.ReadEntry()
+			if err != nil {
+				break
+			}
+		}
+		file.Close()
+	}
+}
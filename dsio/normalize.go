@@ -0,0 +1,156 @@
+package dsio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+
+	"github.com/qri-io/dataset"
+)
+
+// utf8BOM is the UTF-8 encoding of the byte order mark spreadsheet software
+// likes to start text files with
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Normalization describes the changes a reader made to the start of a body
+// before parsing it. Bodies exported by spreadsheet software often start
+// with a byte order mark, blank lines or comments that strict parsers reject
+type Normalization struct {
+	// BOM is true if a leading UTF-8 byte order mark was removed
+	BOM bool `json:"bom,omitempty"`
+	// BlankLines is the number of leading lines of only whitespace skipped
+	BlankLines int `json:"blankLines,omitempty"`
+	// Comments is the number of leading comment lines skipped
+	Comments int `json:"comments,omitempty"`
+}
+
+// IsEmpty checks if no normalization was applied
+func (n Normalization) IsEmpty() bool {
+	return !n.BOM && n.BlankLines == 0 && n.Comments == 0
+}
+
+// Lines gives the number of lines skipped
+func (n Normalization) Lines() int {
+	return n.BlankLines + n.Comments
+}
+
+// Normalizer is implemented by readers that normalize the start of a body.
+// Normalization is known once the first entry has been read
+type Normalizer interface {
+	Normalization() Normalization
+}
+
+// preambleReader is the part of bufio.Reader skipping a preamble needs
+type preambleReader interface {
+	Peek(n int) ([]byte, error)
+	Discard(n int) (int, error)
+}
+
+// SkipPreamble discards the start of a body that readers for st skip: a
+// UTF-8 byte order mark, and as configured by the format config of st,
+// leading blank & comment lines
+func SkipPreamble(st *dataset.Structure, r *bufio.Reader) Normalization {
+	blankLines, comment := preambleOptions(st)
+	return skipPreamble(r, blankLines, comment)
+}
+
+// preambleOptions reads the preamble configuration of a structure
+func preambleOptions(st *dataset.Structure) (blankLines bool, comment string) {
+	switch st.DataFormat() {
+	case dataset.CSVDataFormat:
+		if opts, err := dataset.NewCSVOptions(st.FormatConfig); err == nil {
+			return opts.SkipBlankLines, opts.CommentPrefix
+		}
+	case dataset.JSONDataFormat:
+		if opts, err := dataset.NewJSONOptions(st.FormatConfig); err == nil {
+			// whitespace is valid JSON, blank lines only need skipping to get
+			// to comments
+			return opts.CommentPrefix() != "", opts.CommentPrefix()
+		}
+	}
+	return false, ""
+}
+
+func skipPreamble(r preambleReader, blankLines bool, comment string) Normalization {
+	n := Normalization{}
+	if p, _ := r.Peek(len(utf8BOM)); bytes.Equal(p, utf8BOM) {
+		_, _ = r.Discard(len(utf8BOM))
+		n.BOM = true
+	}
+	if !blankLines && comment == "" {
+		return n
+	}
+
+	for {
+		// indentation before a line break or comment
+		indent := 0
+		for {
+			p, _ := r.Peek(indent + 1)
+			if len(p) <= indent || (p[indent] != ' ' && p[indent] != '\t') {
+				break
+			}
+			indent++
+		}
+
+		if blankLines {
+			if brk := lineBreakLen(r, indent); brk > 0 {
+				_, _ = r.Discard(indent + brk)
+				n.BlankLines++
+				continue
+			}
+		}
+		if comment != "" && hasPrefixAt(r, indent, comment) {
+			_, _ = r.Discard(indent + len(comment))
+			for {
+				if brk := lineBreakLen(r, 0); brk > 0 {
+					_, _ = r.Discard(brk)
+					break
+				}
+				if d, _ := r.Discard(1); d == 0 {
+					break
+				}
+			}
+			n.Comments++
+			continue
+		}
+		return n
+	}
+}
+
+// hasPrefixAt checks if the bytes i bytes into r start with prefix
+func hasPrefixAt(r preambleReader, i int, prefix string) bool {
+	p, _ := r.Peek(i + len(prefix))
+	return len(p) == i+len(prefix) && string(p[i:]) == prefix
+}
+
+// lineBreakLen gives the length of a line break i bytes into r, 0 if there
+// isn't one. solo carriage returns are line breaks, like replacecr reads them
+func lineBreakLen(r preambleReader, i int) int {
+	p, _ := r.Peek(i + 2)
+	if len(p) <= i {
+		return 0
+	}
+	switch p[i] {
+	case '\n':
+		return 1
+	case '\r':
+		if len(p) > i+1 && p[i+1] == '\n' {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// offsetParseError moves the lines of a csv parse error past lines skipped
+// before parsing, so errors give positions in the body as it was read
+func offsetParseError(err error, lines int) error {
+	perr, ok := err.(*csv.ParseError)
+	if !ok || lines == 0 {
+		return err
+	}
+	moved := *perr
+	moved.StartLine += lines
+	moved.Line += lines
+	return &moved
+}
@@ -0,0 +1,127 @@
+package dsio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestSkipPreamble(t *testing.T) {
+	cases := []struct {
+		description string
+		data        string
+		blankLines  bool
+		comment     string
+		expect      Normalization
+		rest        string
+	}{
+		{"nothing to skip", "a,b\n", true, "#", Normalization{}, "a,b\n"},
+		{"bom", "\xEF\xBB\xBFa,b\n", false, "", Normalization{BOM: true}, "a,b\n"},
+		{"blank lines", "  \r\n\t\r\n\na,b\n", true, "", Normalization{BlankLines: 3}, "a,b\n"},
+		{"blank lines not skipped", "  \na,b\n", false, "", Normalization{}, "  \na,b\n"},
+		{"comments", "# exported 2019-03-31\r  # by: excel\ra,b\n", false, "#", Normalization{Comments: 2}, "a,b\n"},
+		{"comments & blank lines", "\xEF\xBB\xBF// data\n\n  \n// more\n[1]", true, "//", Normalization{BOM: true, BlankLines: 2, Comments: 2}, "[1]"},
+		{"comment at end", "# no body", false, "#", Normalization{Comments: 1}, ""},
+		{"indented data", "  a,b\n", true, "#", Normalization{}, "  a,b\n"},
+	}
+
+	for _, c := range cases {
+		br := bufio.NewReader(strings.NewReader(c.data))
+		got := skipPreamble(br, c.blankLines, c.comment)
+		rest, _ := ioutil.ReadAll(br)
+		if got != c.expect || string(rest) != c.rest {
+			t.Errorf("case '%s' mismatch. expected: %#v %q, got: %#v %q", c.description, c.expect, c.rest, got, rest)
+		}
+
+		mr := newMappedCSVReader(c.data, ',', false, false)
+		if got := skipPreamble(mr, c.blankLines, c.comment); got != c.expect || mr.s[mr.pos:] != c.rest {
+			t.Errorf("case '%s' mapped mismatch. expected: %#v %q, got: %#v %q", c.description, c.expect, c.rest, got, mr.s[mr.pos:])
+		}
+	}
+}
+
+func TestCSVReaderNormalization(t *testing.T) {
+	data := "\xEF\xBB\xBF# exported from excel\r\n \r\ntitle,year\r\nalien,1979\r\nsolaris,1972,tarkovsky\r\n"
+	st := &dataset.Structure{
+		Format: "csv",
+		FormatConfig: map[string]interface{}{
+			"headerRow":      true,
+			"commentPrefix":  "#",
+			"skipBlankLines": true,
+		},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "title", "type": "string"},
+					map[string]interface{}{"title": "year", "type": "integer"},
+				},
+			},
+		},
+	}
+
+	r, err := NewCSVReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row := ent.Value.([]interface{}); row[0] != "alien" || row[1] != int64(1979) {
+		t.Errorf("unexpected first row: %#v", row)
+	}
+	if expect := (Normalization{BOM: true, BlankLines: 1, Comments: 1}); r.Normalization() != expect {
+		t.Errorf("normalization mismatch. expected: %#v, got: %#v", expect, r.Normalization())
+	}
+
+	// error lines count skipped lines
+	_, err = r.ReadEntry()
+	perr := &csv.ParseError{}
+	if !errors.As(err, &perr) || perr.StartLine != 5 {
+		t.Errorf("expected a parse error on line 5, got: %v", err)
+	}
+}
+
+func TestJSONReaderNormalization(t *testing.T) {
+	data := "\xEF\xBB\xBF// generated file\n\n// do not edit\n[1, 2]"
+	st := &dataset.Structure{
+		Format:       "json",
+		FormatConfig: map[string]interface{}{"commentPrefix": "//"},
+		Schema:       dataset.BaseSchemaArray,
+	}
+	r, err := NewJSONReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 entries, got: %#v", got)
+	}
+	if expect := (Normalization{BOM: true, BlankLines: 1, Comments: 2}); r.Normalization() != expect {
+		t.Errorf("normalization mismatch. expected: %#v, got: %#v", expect, r.Normalization())
+	}
+
+	// byte order marks are always skipped
+	st.FormatConfig = nil
+	r, err = NewJSONReader(st, bytes.NewBufferString("\xEF\xBB\xBF\n[1]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadEntry(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := (Normalization{BOM: true}); r.Normalization() != expect {
+		t.Errorf("normalization mismatch. expected: %#v, got: %#v", expect, r.Normalization())
+	}
+}
@@ -0,0 +1,405 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset"
+)
+
+// PartitionFilter reports whether the rows of a partition key should be read
+type PartitionFilter func(key string) bool
+
+// KeyRange keeps partition keys from & to inclusive, an empty bound leaves
+// that end of the range open. KeyRange is only meant for the period keys of
+// partitions with a granularity: keys are compared as text, which orders
+// period keys in time but not the keys of other values, eg. the number key
+// "10" sorts before "9". Keys & bounds of different precision are compared at
+// the shorter length, so a period is kept when it overlaps the range, eg. the
+// month "2020-03" is in the range from "2020-03-15". The empty key of null
+// values is only kept by an unbounded range
+func KeyRange(from, to string) PartitionFilter {
+	return func(key string) bool {
+		if key == "" {
+			return from == "" && to == ""
+		}
+		if from != "" && comparePrefix(key, from) < 0 {
+			return false
+		}
+		if to != "" && comparePrefix(key, to) > 0 {
+			return false
+		}
+		return true
+	}
+}
+
+// comparePrefix compares a & b at the length of the shorter string
+func comparePrefix(a, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	return strings.Compare(a[:n], b[:n])
+}
+
+// ShardPruner is implemented by readers of partitioned bodies that can skip
+// whole shards. Shards must be pruned before the first entry is read
+type ShardPruner interface {
+	PruneShards(keep PartitionFilter) error
+}
+
+// EntrySkipper is implemented by readers that can skip entries without
+// decoding them. SkipEntries skips up to n entries, returning the number
+// skipped, and io.EOF when the body ends first
+type EntrySkipper interface {
+	SkipEntries(n int) (int, error)
+}
+
+// FilterPartitions keeps the rows of partition keys keep reports true for.
+// Readers of partitioned bodies that implement ShardPruner skip the shards of
+// other keys without opening them, other readers check the partition key of
+// every row. The structure must declare a partition
+func (p *PipelineBuilder) FilterPartitions(keep PartitionFilter) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		if keep == nil {
+			return nil, fmt.Errorf("filter partitions: func is required")
+		}
+		st := r.Structure()
+		if st.Partition == nil {
+			return nil, fmt.Errorf("filter partitions: structure has no partition")
+		}
+		if pr, ok := r.(ShardPruner); ok {
+			if err := pr.PruneShards(keep); err != nil {
+				return nil, fmt.Errorf("filter partitions: %w", err)
+			}
+			return r, nil
+		}
+		key, err := partitionKeyFunc(st)
+		if err != nil {
+			return nil, fmt.Errorf("filter partitions: %w", err)
+		}
+		f := func(ent Entry) (bool, error) {
+			k, err := key(ent.Value)
+			if err != nil {
+				return false, err
+			}
+			return keep(k), nil
+		}
+		return &filterReader{r: r, st: derivedStructure(st), f: f}, nil
+	})
+}
+
+// partitionKeyFunc gives a func that returns the partition key of a row.
+// Columns of tabular structures must be in the schema
+func partitionKeyFunc(st *dataset.Structure) (func(row interface{}) (string, error), error) {
+	p := st.Partition
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("partition: %w", err)
+	}
+	col := -1
+	if cols, _, err := schemaColumns(st); err == nil {
+		for i, title := range cols.Titles() {
+			if title == p.Column {
+				col = i
+			}
+		}
+		if col < 0 {
+			return nil, fmt.Errorf("partition column %q is not in the schema", p.Column)
+		}
+	}
+	return func(row interface{}) (string, error) {
+		switch x := row.(type) {
+		case []interface{}:
+			if col < 0 {
+				return "", fmt.Errorf("array rows require a tabular schema")
+			}
+			if col < len(x) {
+				return p.Key(x[col])
+			}
+			return p.Key(nil)
+		case map[string]interface{}:
+			return p.Key(x[p.Column])
+		}
+		return "", fmt.Errorf("entry is not an array or object row")
+	}, nil
+}
+
+// PartitionWriter splits entries into shards by the structure partition,
+// writing each shard with an entry writer of the structure format. Shards are
+// created the first time their key is written, so rows don't need to be
+// sorted by key, at the cost of keeping a writer open for every key
+type PartitionWriter struct {
+	st     *dataset.Structure
+	key    func(row interface{}) (string, error)
+	create func(key string) (io.Writer, error)
+	opts   []func(*WriterConfig)
+	shards map[string]*shardWriter
+	n      int
+}
+
+var _ EntryWriter = (*PartitionWriter)(nil)
+
+// shardWriter writes the entries of one shard
+type shardWriter struct {
+	w       EntryWriter
+	dst     io.Writer
+	cw      *countingWriter
+	entries int
+}
+
+// NewPartitionWriter writes entries to shards, calling create once for each
+// partition key to get the writer of it's shard. Shard writers that are
+// io.Closers are closed when the partition writer is closed
+func NewPartitionWriter(st *dataset.Structure, create func(key string) (io.Writer, error), opts ...func(*WriterConfig)) (*PartitionWriter, error) {
+	if st == nil || st.Partition == nil {
+		return nil, fmt.Errorf("partition writer: structure has no partition")
+	}
+	if create == nil {
+		return nil, fmt.Errorf("partition writer: create func is required")
+	}
+	key, err := partitionKeyFunc(st)
+	if err != nil {
+		return nil, fmt.Errorf("partition writer: %w", err)
+	}
+	return &PartitionWriter{
+		st:     st,
+		key:    key,
+		create: create,
+		opts:   opts,
+		shards: map[string]*shardWriter{},
+	}, nil
+}
+
+// Structure gives the structure being written
+func (w *PartitionWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteEntry writes an entry to the shard of it's partition key
+func (w *PartitionWriter) WriteEntry(ent Entry) error {
+	i := w.n
+	w.n++
+	key, err := w.key(ent.Value)
+	if err != nil {
+		return fmt.Errorf("partition writer: entry %d: %w", i, err)
+	}
+	sw, ok := w.shards[key]
+	if !ok {
+		dst, err := w.create(key)
+		if err != nil {
+			return fmt.Errorf("partition writer: creating shard %q: %w", key, err)
+		}
+		cw := &countingWriter{w: dst}
+		ew, err := NewEntryWriter(w.st, cw, w.opts...)
+		if err != nil {
+			return fmt.Errorf("partition writer: %w", err)
+		}
+		sw = &shardWriter{w: ew, dst: dst, cw: cw}
+		w.shards[key] = sw
+	}
+	if err := sw.w.WriteEntry(ent); err != nil {
+		return fmt.Errorf("partition writer: entry %d: %w", i, err)
+	}
+	sw.entries++
+	return nil
+}
+
+// Close closes every shard writer, returning the first error
+func (w *PartitionWriter) Close() (err error) {
+	for _, key := range w.keys() {
+		sw := w.shards[key]
+		if cerr := sw.w.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("partition writer: closing shard %q: %w", key, cerr)
+		}
+		if c, ok := sw.dst.(io.Closer); ok {
+			if cerr := c.Close(); cerr != nil && err == nil {
+				err = fmt.Errorf("partition writer: closing shard %q: %w", key, cerr)
+			}
+		}
+	}
+	return err
+}
+
+// Shards describes the written shards in key order. Lengths are only
+// complete once the writer is closed, and shard paths are left for callers
+// to set once shards are stored
+func (w *PartitionWriter) Shards() []*dataset.BodyShard {
+	keys := w.keys()
+	shards := make([]*dataset.BodyShard, len(keys))
+	for i, key := range keys {
+		sw := w.shards[key]
+		shards[i] = &dataset.BodyShard{Key: key, Entries: sw.entries, Length: int(sw.cw.n)}
+	}
+	return shards
+}
+
+// keys gives shard keys in sorted order
+func (w *PartitionWriter) keys() []string {
+	keys := make([]string, 0, len(w.shards))
+	for key := range w.shards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PartitionReader reads the shards of a partitioned body in order as one
+// body. Shards are opened as they're reached, and shards that are pruned or
+// skipped are never opened. Entry indexes count across shards
+type PartitionReader struct {
+	st     *dataset.Structure
+	shards []*dataset.BodyShard
+	open   func(*dataset.BodyShard) (io.Reader, error)
+	r      EntryReader
+	src    io.Reader
+	read   int
+	done   bool
+}
+
+var (
+	_ EntryReader  = (*PartitionReader)(nil)
+	_ ShardPruner  = (*PartitionReader)(nil)
+	_ EntrySkipper = (*PartitionReader)(nil)
+)
+
+// NewPartitionReader reads shards of a body with structure st, calling open
+// to get the body of each shard. Shard bodies that are io.Closers are closed
+// once they're read
+func NewPartitionReader(st *dataset.Structure, shards []*dataset.BodyShard, open func(*dataset.BodyShard) (io.Reader, error)) (*PartitionReader, error) {
+	if st == nil {
+		return nil, fmt.Errorf("partition reader: structure is required")
+	}
+	if open == nil {
+		return nil, fmt.Errorf("partition reader: open func is required")
+	}
+	cp := make([]*dataset.BodyShard, 0, len(shards))
+	for _, s := range shards {
+		if s != nil {
+			cp = append(cp, s)
+		}
+	}
+	return &PartitionReader{st: st, shards: cp, open: open}, nil
+}
+
+// Structure gives the structure being read
+func (r *PartitionReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// PruneShards drops the shards of keys keep reports false for. The structure
+// of a pruned reader no longer describes the whole body: derived values are
+// dropped, and entries are counted from the remaining shards
+func (r *PartitionReader) PruneShards(keep PartitionFilter) error {
+	if r.read > 0 || r.r != nil || r.done {
+		return fmt.Errorf("cannot prune shards once reading has started")
+	}
+	kept := r.shards[:0]
+	entries := 0
+	for _, s := range r.shards {
+		if keep(s.Key) {
+			kept = append(kept, s)
+			entries += s.Entries
+		}
+	}
+	r.shards = kept
+	r.st = derivedStructure(r.st)
+	r.st.Entries = entries
+	return nil
+}
+
+// ReadEntry reads the next entry, moving on to the next shard when a shard
+// runs out
+func (r *PartitionReader) ReadEntry() (Entry, error) {
+	for {
+		if r.r == nil {
+			if err := r.next(); err != nil {
+				return Entry{}, err
+			}
+		}
+		ent, err := r.r.ReadEntry()
+		if err == io.EOF {
+			if err := r.closeShard(); err != nil {
+				return Entry{}, err
+			}
+			continue
+		} else if err != nil {
+			return ent, err
+		}
+		ent.Index = r.read
+		r.read++
+		return ent, nil
+	}
+}
+
+// SkipEntries skips n entries. Whole shards with a recorded entry count that
+// fit in n are skipped without opening them
+func (r *PartitionReader) SkipEntries(n int) (int, error) {
+	skipped := 0
+	for skipped < n {
+		if r.r == nil {
+			if len(r.shards) == 0 {
+				return skipped, io.EOF
+			}
+			if s := r.shards[0]; s.Entries > 0 && s.Entries <= n-skipped {
+				r.shards = r.shards[1:]
+				skipped += s.Entries
+				r.read += s.Entries
+				continue
+			}
+		}
+		if _, err := r.ReadEntry(); err != nil {
+			return skipped, err
+		}
+		skipped++
+	}
+	return skipped, nil
+}
+
+// next opens the next shard
+func (r *PartitionReader) next() error {
+	if len(r.shards) == 0 {
+		r.done = true
+		return io.EOF
+	}
+	s := r.shards[0]
+	r.shards = r.shards[1:]
+	src, err := r.open(s)
+	if err != nil {
+		return fmt.Errorf("partition reader: opening shard %q: %w", s.Key, err)
+	}
+	er, err := NewEntryReader(r.st, src)
+	if err != nil {
+		if c, ok := src.(io.Closer); ok {
+			c.Close()
+		}
+		return fmt.Errorf("partition reader: shard %q: %w", s.Key, err)
+	}
+	r.r = er
+	r.src = src
+	return nil
+}
+
+// closeShard closes the open shard
+func (r *PartitionReader) closeShard() error {
+	if r.r == nil {
+		return nil
+	}
+	err := r.r.Close()
+	if c, ok := r.src.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	r.r = nil
+	r.src = nil
+	return err
+}
+
+// Close closes the open shard, shards that weren't reached are never opened
+func (r *PartitionReader) Close() error {
+	r.done = true
+	r.shards = nil
+	return r.closeShard()
+}
@@ -0,0 +1,218 @@
+package dsio
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func partitionedStructure() *dataset.Structure {
+	st := tabularStructure(
+		map[string]interface{}{"title": "date", "type": "string"},
+		map[string]interface{}{"title": "station", "type": "string"},
+		map[string]interface{}{"title": "pm10", "type": "integer"},
+	)
+	st.Partition = &dataset.Partition{Column: "date", Granularity: dataset.PartitionMonth}
+	return st
+}
+
+// writeShards partitions rows into in-memory shards
+func writeShards(t *testing.T, st *dataset.Structure, rows ...[]interface{}) ([]*dataset.BodyShard, map[string]*bytes.Buffer) {
+	t.Helper()
+	bufs := map[string]*bytes.Buffer{}
+	w, err := NewPartitionWriter(st, func(key string) (io.Writer, error) {
+		bufs[key] = &bytes.Buffer{}
+		return bufs[key], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteEntry(Entry{Value: row}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	shards := w.Shards()
+	for _, s := range shards {
+		s.Path = s.Key
+	}
+	return shards, bufs
+}
+
+// openShards opens in-memory shards, recording the keys of opened shards
+func openShards(bufs map[string]*bytes.Buffer, opened *[]string) func(*dataset.BodyShard) (io.Reader, error) {
+	return func(s *dataset.BodyShard) (io.Reader, error) {
+		*opened = append(*opened, s.Key)
+		return bytes.NewReader(bufs[s.Path].Bytes()), nil
+	}
+}
+
+func TestPartitionWriterReader(t *testing.T) {
+	st := partitionedStructure()
+	shards, bufs := writeShards(t, st,
+		[]interface{}{"2020-02-03", "Neckartor", 41},
+		[]interface{}{"2020-01-01", "Neckartor", 58},
+		[]interface{}{"2020-03-30T08:00:00Z", "Am Neckartor", 22},
+		[]interface{}{"2020-01-31", "Hohenheimer Str.", 35},
+	)
+
+	expectShards := []*dataset.BodyShard{
+		{Key: "2020-01", Path: "2020-01", Entries: 2, Length: bufs["2020-01"].Len()},
+		{Key: "2020-02", Path: "2020-02", Entries: 1, Length: bufs["2020-02"].Len()},
+		{Key: "2020-03", Path: "2020-03", Entries: 1, Length: bufs["2020-03"].Len()},
+	}
+	if !reflect.DeepEqual(expectShards, shards) {
+		t.Errorf("shards mismatch. expected: %v, got: %v", expectShards, shards)
+	}
+	if got := bufs["2020-01"].String(); got != `[["2020-01-01","Neckartor",58],["2020-01-31","Hohenheimer Str.",35]]` {
+		t.Errorf("shard body mismatch. got: %s", got)
+	}
+
+	var opened []string
+	r, err := NewPartitionReader(st, shards, openShards(bufs, &opened))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexes []int
+	var dates []string
+	for {
+		ent, err := r.ReadEntry()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		indexes = append(indexes, ent.Index)
+		dates = append(dates, ent.Value.([]interface{})[0].(string))
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []int{0, 1, 2, 3}; !reflect.DeepEqual(expect, indexes) {
+		t.Errorf("expected indexes to count across shards %v, got: %v", expect, indexes)
+	}
+	if expect := "2020-01-01,2020-01-31,2020-02-03,2020-03-30T08:00:00Z"; strings.Join(dates, ",") != expect {
+		t.Errorf("entries mismatch. expected: %s, got: %s", expect, strings.Join(dates, ","))
+	}
+}
+
+func TestPartitionPruning(t *testing.T) {
+	st := partitionedStructure()
+	shards, bufs := writeShards(t, st,
+		[]interface{}{"2020-01-01", "Neckartor", 58},
+		[]interface{}{"2020-01-31", "Neckartor", 35},
+		[]interface{}{"2020-02-03", "Neckartor", 41},
+		[]interface{}{"2020-03-30", "Neckartor", 22},
+	)
+
+	var opened []string
+	pr, err := NewPartitionReader(st, shards, openShards(bufs, &opened))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Pipeline(pr).FilterPartitions(KeyRange("2020-02-15", "")).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Structure().Entries != 2 {
+		t.Errorf("expected pruned structure to count 2 entries, got: %d", r.Structure().Entries)
+	}
+	rows, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].([]interface{})[0] != "2020-02-03" {
+		t.Errorf("expected rows of kept shards, got: %v", rows)
+	}
+	if expect := []string{"2020-02", "2020-03"}; !reflect.DeepEqual(expect, opened) {
+		t.Errorf("expected only kept shards to be opened %v, got: %v", expect, opened)
+	}
+	if err := pr.PruneShards(KeyRange("", "")); err == nil {
+		t.Errorf("expected pruning after reading to fail")
+	}
+
+	opened = nil
+	pr, _ = NewPartitionReader(st, shards, openShards(bufs, &opened))
+	r, err = Pipeline(pr).Offset(3).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ent.Index != 3 || ent.Value.([]interface{})[0] != "2020-03-30" {
+		t.Errorf("expected entry 3 after the offset, got: %v", ent)
+	}
+	if expect := []string{"2020-03"}; !reflect.DeepEqual(expect, opened) {
+		t.Errorf("expected offset to skip shards unopened %v, got: %v", expect, opened)
+	}
+}
+
+func TestFilterPartitionsRows(t *testing.T) {
+	st := partitionedStructure()
+	src := &sliceReader{st: st, rows: []interface{}{
+		[]interface{}{"2020-01-31", "Neckartor", 35},
+		[]interface{}{"2020-02-03", "Neckartor", 41},
+		[]interface{}{nil, "Neckartor", 12},
+	}}
+	r, err := Pipeline(src).FilterPartitions(KeyRange("2020-02", "2020-12")).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].([]interface{})[0] != "2020-02-03" {
+		t.Errorf("expected rows in the key range, got: %v", rows)
+	}
+
+	unpartitioned := tabularStructure(map[string]interface{}{"title": "date", "type": "string"})
+	missing := partitionedStructure()
+	missing.Partition.Column = "day"
+	cases := []struct {
+		st  *dataset.Structure
+		err string
+	}{
+		{unpartitioned, "pipeline: filter partitions: structure has no partition"},
+		{missing, `pipeline: filter partitions: partition column "day" is not in the schema`},
+	}
+	for i, c := range cases {
+		_, err := Pipeline(&sliceReader{st: c.st}).FilterPartitions(KeyRange("", "")).Reader()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestKeyRange(t *testing.T) {
+	cases := []struct {
+		from, to, key string
+		expect        bool
+	}{
+		{"", "", "2020-01", true},
+		{"2020-02", "", "2020-01", false},
+		{"2020-02", "2020-03", "2020-03", true},
+		{"2020-02", "2020-03", "2020-04", false},
+		{"2020-03-15", "2020-04-01", "2020-03", true},
+		{"2020-03-15", "2020-04-01", "2020-03-14", false},
+		{"2020-03-15", "2020-04-01", "2020", true},
+		{"", "2020-04-01", "2020-04-01T23", true},
+		{"2020-03-15", "", "2020-03-14T23", false},
+		{"", "", "", true},
+		{"2020", "", "", false},
+	}
+	for i, c := range cases {
+		if got := KeyRange(c.from, c.to)(c.key); got != c.expect {
+			t.Errorf("case %d: %q in [%q, %q] expected: %t, got: %t", i, c.key, c.from, c.to, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package dsio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio/pbwire"
+)
+
+// PBFSchema gives the schema of entries read from OpenStreetMap PBF
+// extracts. Each entry is a node or way: [id, type, lat, lon, tags, refs].
+// Nodes have coordinates in WGS84 degrees, ways list the ids of their nodes as
+// refs. Every call returns a new schema callers are free to modify
+func PBFSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "id", "type": "integer"},
+				map[string]interface{}{"title": "type", "type": "string"},
+				map[string]interface{}{"title": "lat", "type": []interface{}{"number", "null"}},
+				map[string]interface{}{"title": "lon", "type": []interface{}{"number", "null"}},
+				map[string]interface{}{"title": "tags", "type": "object"},
+				map[string]interface{}{"title": "refs", "type": []interface{}{"array", "null"}},
+			},
+		},
+	}
+}
+
+const (
+	// pbfMaxHeaderSize & pbfMaxBlobSize are the size limits of the PBF spec
+	pbfMaxHeaderSize = 64 * 1024
+	pbfMaxBlobSize   = 32 * 1024 * 1024
+)
+
+// pbfFeatures are the required features of extracts PBFReader can read
+var pbfFeatures = map[string]bool{
+	"OsmSchema-V0.6": true,
+	"DenseNodes":     true,
+}
+
+// PBFReader reads the nodes & ways of an OpenStreetMap PBF extract. Extracts
+// are decoded one block of up to 8000 elements at a time, filtered by the
+// PBFOptions of the structure's format config
+type PBFReader struct {
+	st    *dataset.Structure
+	r     io.Reader
+	types map[string]bool
+	tags  map[string]string
+	rows  [][]interface{}
+	i     int
+}
+
+var _ EntryReader = (*PBFReader)(nil)
+
+// NewPBFReader creates a reader from a structure and read source
+func NewPBFReader(st *dataset.Structure, r io.Reader) (*PBFReader, error) {
+	opts, err := dataset.NewPBFOptions(st.FormatConfig)
+	if err != nil {
+		return nil, err
+	}
+	rdr := &PBFReader{st: st, r: r, tags: opts.Tags}
+	if len(opts.Types) > 0 {
+		rdr.types = map[string]bool{}
+		for _, t := range opts.Types {
+			rdr.types[t] = true
+		}
+	}
+	return rdr, nil
+}
+
+// Structure gives this reader's structure
+func (r *PBFReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next node or way of the extract
+func (r *PBFReader) ReadEntry() (Entry, error) {
+	for len(r.rows) == 0 {
+		typ, blob, err := r.readBlob()
+		if err != nil {
+			return Entry{}, err
+		}
+		switch typ {
+		case "OSMHeader":
+			err = checkPBFHeader(blob)
+		case "OSMData":
+			r.rows, err = r.primitiveBlock(blob)
+		}
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	ent := Entry{Index: r.i, Value: r.rows[0]}
+	r.rows = r.rows[1:]
+	r.i++
+	return ent, nil
+}
+
+// Close finalizes the reader
+func (r *PBFReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// readBlob reads the next blob of the file, giving the blob type & its
+// uncompressed contents
+func (r *PBFReader) readBlob() (string, []byte, error) {
+	var size uint32
+	if err := binary.Read(r.r, binary.BigEndian, &size); err != nil {
+		if err == io.EOF {
+			return "", nil, io.EOF
+		}
+		return "", nil, fmt.Errorf("pbf: reading blob header size: %w", err)
+	}
+	if size > pbfMaxHeaderSize {
+		return "", nil, fmt.Errorf("pbf: blob header size %d exceeds maximum", size)
+	}
+	header := make([]byte, size)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		return "", nil, fmt.Errorf("pbf: reading blob header: %w", err)
+	}
+
+	var (
+		typ      string
+		dataSize int64
+	)
+	err := pbwire.Decode(header, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			typ = f.String()
+		case 3:
+			dataSize = f.Int()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("pbf: decoding blob header: %w", err)
+	}
+	if dataSize < 0 || dataSize > pbfMaxBlobSize {
+		return "", nil, fmt.Errorf("pbf: blob size %d exceeds maximum", dataSize)
+	}
+	blob := make([]byte, dataSize)
+	if _, err := io.ReadFull(r.r, blob); err != nil {
+		return "", nil, fmt.Errorf("pbf: reading blob: %w", err)
+	}
+
+	var data []byte
+	err = pbwire.Decode(blob, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			data = f.Bytes
+		case 3:
+			zr, err := zlib.NewReader(bytes.NewReader(f.Bytes))
+			if err != nil {
+				return err
+			}
+			defer zr.Close()
+			data, err = ioutil.ReadAll(io.LimitReader(zr, pbfMaxBlobSize))
+			return err
+		case 4, 5, 6, 7:
+			return fmt.Errorf("unsupported blob compression")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("pbf: decoding %s blob: %w", typ, err)
+	}
+	return typ, data, nil
+}
+
+// checkPBFHeader errors if a HeaderBlock requires unsupported features
+func checkPBFHeader(data []byte) error {
+	return pbwire.Decode(data, func(f pbwire.Field) error {
+		if f.Num == 4 && !pbfFeatures[f.String()] {
+			return fmt.Errorf("pbf: unsupported required feature %q", f.String())
+		}
+		return nil
+	})
+}
+
+// pbfBlock holds the parameters of a PrimitiveBlock for decoding elements
+type pbfBlock struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (b *pbfBlock) coord(offset, v int64) float64 {
+	return float64(offset+b.granularity*v) / 1e9
+}
+
+func (b *pbfBlock) str(i uint64) (string, error) {
+	if i >= uint64(len(b.strings)) {
+		return "", fmt.Errorf("string index %d out of range", i)
+	}
+	return b.strings[i], nil
+}
+
+// primitiveBlock decodes the elements of a PrimitiveBlock that pass the
+// reader's filters
+func (r *PBFReader) primitiveBlock(data []byte) ([][]interface{}, error) {
+	b := &pbfBlock{granularity: 100}
+	var groups [][]byte
+	err := pbwire.Decode(data, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			return pbwire.Decode(f.Bytes, func(s pbwire.Field) error {
+				if s.Num == 1 {
+					b.strings = append(b.strings, s.String())
+				}
+				return nil
+			})
+		case 2:
+			groups = append(groups, f.Bytes)
+		case 17:
+			b.granularity = f.Int()
+		case 19:
+			b.latOffset = f.Int()
+		case 20:
+			b.lonOffset = f.Int()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pbf: decoding block: %w", err)
+	}
+
+	var rows [][]interface{}
+	add := func(id int64, typ string, lat, lon interface{}, tags map[string]interface{}, refs interface{}) {
+		if r.match(typ, tags) {
+			rows = append(rows, []interface{}{id, typ, lat, lon, tags, refs})
+		}
+	}
+
+	for _, g := range groups {
+		err := pbwire.Decode(g, func(f pbwire.Field) error {
+			switch f.Num {
+			case 1:
+				return b.node(f.Bytes, add)
+			case 2:
+				return b.denseNodes(f.Bytes, add)
+			case 3:
+				return b.way(f.Bytes, add)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("pbf: decoding block: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+// match reports whether an element passes the reader's filters
+func (r *PBFReader) match(typ string, tags map[string]interface{}) bool {
+	if r.types != nil && !r.types[typ] {
+		return false
+	}
+	for k, want := range r.tags {
+		v, ok := tags[k]
+		if !ok || (want != "*" && v != want) {
+			return false
+		}
+	}
+	return true
+}
+
+type pbfAddFunc func(id int64, typ string, lat, lon interface{}, tags map[string]interface{}, refs interface{})
+
+// tags zips key & value string indexes into a tag map
+func (b *pbfBlock) tags(keys, vals []uint64) (map[string]interface{}, error) {
+	if len(keys) != len(vals) {
+		return nil, fmt.Errorf("mismatched tag keys & values")
+	}
+	tags := make(map[string]interface{}, len(keys))
+	for i := range keys {
+		k, err := b.str(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		v, err := b.str(vals[i])
+		if err != nil {
+			return nil, err
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func (b *pbfBlock) node(data []byte, add pbfAddFunc) error {
+	var id, lat, lon int64
+	var keys, vals []uint64
+	err := pbwire.Decode(data, func(f pbwire.Field) (err error) {
+		switch f.Num {
+		case 1:
+			id = f.Sint()
+		case 2:
+			keys, err = appendVarints(keys, f)
+		case 3:
+			vals, err = appendVarints(vals, f)
+		case 8:
+			lat = f.Sint()
+		case 9:
+			lon = f.Sint()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := b.tags(keys, vals)
+	if err != nil {
+		return fmt.Errorf("node %d: %w", id, err)
+	}
+	add(id, "node", b.coord(b.latOffset, lat), b.coord(b.lonOffset, lon), tags, nil)
+	return nil
+}
+
+// denseNodes decodes delta coded DenseNodes, tags are stored as a single
+// list of key & value pairs, with a 0 marking the end of each node's tags
+func (b *pbfBlock) denseNodes(data []byte, add pbfAddFunc) error {
+	var ids, lats, lons, keyVals []uint64
+	err := pbwire.Decode(data, func(f pbwire.Field) (err error) {
+		switch f.Num {
+		case 1:
+			ids, err = appendVarints(ids, f)
+		case 8:
+			lats, err = appendVarints(lats, f)
+		case 9:
+			lons, err = appendVarints(lons, f)
+		case 10:
+			keyVals, err = appendVarints(keyVals, f)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return fmt.Errorf("dense nodes: mismatched ids & coordinates")
+	}
+
+	var id, lat, lon int64
+	kv := 0
+	for i := range ids {
+		id += pbwire.Zigzag(ids[i])
+		lat += pbwire.Zigzag(lats[i])
+		lon += pbwire.Zigzag(lons[i])
+
+		tags := map[string]interface{}{}
+		for kv < len(keyVals) && keyVals[kv] != 0 {
+			if kv+1 >= len(keyVals) {
+				return fmt.Errorf("node %d: mismatched tag keys & values", id)
+			}
+			k, err := b.str(keyVals[kv])
+			if err != nil {
+				return fmt.Errorf("node %d: %w", id, err)
+			}
+			v, err := b.str(keyVals[kv+1])
+			if err != nil {
+				return fmt.Errorf("node %d: %w", id, err)
+			}
+			tags[k] = v
+			kv += 2
+		}
+		// skip the end of tags marker
+		kv++
+
+		add(id, "node", b.coord(b.latOffset, lat), b.coord(b.lonOffset, lon), tags, nil)
+	}
+	return nil
+}
+
+func (b *pbfBlock) way(data []byte, add pbfAddFunc) error {
+	var id int64
+	var keys, vals, deltas []uint64
+	err := pbwire.Decode(data, func(f pbwire.Field) (err error) {
+		switch f.Num {
+		case 1:
+			id = f.Int()
+		case 2:
+			keys, err = appendVarints(keys, f)
+		case 3:
+			vals, err = appendVarints(vals, f)
+		case 8:
+			deltas, err = appendVarints(deltas, f)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := b.tags(keys, vals)
+	if err != nil {
+		return fmt.Errorf("way %d: %w", id, err)
+	}
+
+	refs := make([]interface{}, len(deltas))
+	var ref int64
+	for i, d := range deltas {
+		ref += pbwire.Zigzag(d)
+		refs[i] = ref
+	}
+	add(id, "way", nil, nil, tags, refs)
+	return nil
+}
+
+func appendVarints(vals []uint64, f pbwire.Field) ([]uint64, error) {
+	v, err := f.Varints()
+	if err != nil {
+		return nil, err
+	}
+	return append(vals, v...), nil
+}
@@ -0,0 +1,169 @@
+package dsio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio/pbwire"
+)
+
+func packed(vals ...uint64) []byte {
+	var b []byte
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, v := range vals {
+		n := binary.PutUvarint(buf, v)
+		b = append(b, buf[:n]...)
+	}
+	return b
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func pbfBlob(t *testing.T, typ string, data []byte, compress bool) []byte {
+	var blob []byte
+	if compress {
+		buf := &bytes.Buffer{}
+		zw := zlib.NewWriter(buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		blob = pbwire.AppendVarint(nil, 2, uint64(len(data)))
+		blob = pbwire.AppendBytes(blob, 3, buf.Bytes())
+	} else {
+		blob = pbwire.AppendBytes(nil, 1, data)
+	}
+
+	header := pbwire.AppendBytes(nil, 1, []byte(typ))
+	header = pbwire.AppendVarint(header, 3, uint64(len(blob)))
+	out := make([]byte, 4)
+	binary.BigEndian.PutUint32(out, uint32(len(header)))
+	out = append(out, header...)
+	return append(out, blob...)
+}
+
+// testPBF builds an extract with two dense nodes, a plain node & a way
+func testPBF(t *testing.T, features ...string) []byte {
+	var header []byte
+	for _, f := range append([]string{"OsmSchema-V0.6", "DenseNodes"}, features...) {
+		header = pbwire.AppendBytes(header, 4, []byte(f))
+	}
+
+	var st []byte
+	for _, s := range []string{"", "amenity", "bicycle_parking", "capacity", "10", "highway", "cycleway", "bench"} {
+		st = pbwire.AppendBytes(st, 1, []byte(s))
+	}
+
+	// nanodegree coordinates divided by the default granularity of 100
+	dense := pbwire.AppendBytes(nil, 1, packed(zigzag(1), zigzag(1)))
+	dense = pbwire.AppendBytes(dense, 8, packed(zigzag(487840810), zigzag(-100)))
+	dense = pbwire.AppendBytes(dense, 9, packed(zigzag(91816350), zigzag(200)))
+	dense = pbwire.AppendBytes(dense, 10, packed(1, 2, 3, 4, 0, 0))
+
+	node := pbwire.AppendVarint(nil, 1, zigzag(10))
+	node = pbwire.AppendBytes(node, 2, packed(1))
+	node = pbwire.AppendBytes(node, 3, packed(7))
+	node = pbwire.AppendVarint(node, 8, zigzag(-338688000))
+	node = pbwire.AppendVarint(node, 9, zigzag(1512093000))
+
+	way := pbwire.AppendVarint(nil, 1, 100)
+	way = pbwire.AppendBytes(way, 2, packed(5))
+	way = pbwire.AppendBytes(way, 3, packed(6))
+	way = pbwire.AppendBytes(way, 8, packed(zigzag(1), zigzag(1), zigzag(8)))
+
+	group1 := pbwire.AppendBytes(nil, 2, dense)
+	group1 = pbwire.AppendBytes(group1, 1, node)
+	group2 := pbwire.AppendBytes(nil, 3, way)
+
+	block := pbwire.AppendBytes(nil, 1, st)
+	block = pbwire.AppendBytes(block, 2, group1)
+	block = pbwire.AppendBytes(block, 2, group2)
+
+	data := pbfBlob(t, "OSMHeader", header, false)
+	return append(data, pbfBlob(t, "OSMData", block, true)...)
+}
+
+func TestPBFReader(t *testing.T) {
+	parking := map[string]interface{}{"amenity": "bicycle_parking", "capacity": "10"}
+	nodes := []interface{}{
+		[]interface{}{int64(1), "node", 48.784081, 9.181635, parking, nil},
+		[]interface{}{int64(2), "node", 48.784071, 9.181655, map[string]interface{}{}, nil},
+		[]interface{}{int64(10), "node", -33.8688, 151.2093, map[string]interface{}{"amenity": "bench"}, nil},
+	}
+	way := []interface{}{int64(100), "way", nil, nil, map[string]interface{}{"highway": "cycleway"}, []interface{}{int64(1), int64(2), int64(10)}}
+
+	cases := []struct {
+		description string
+		opts        map[string]interface{}
+		expect      []interface{}
+	}{
+		{"all", nil, append(append([]interface{}{}, nodes...), way)},
+		{"ways", map[string]interface{}{"types": []interface{}{"way"}}, []interface{}{way}},
+		{"tag value", map[string]interface{}{"tags": map[string]interface{}{"amenity": "bicycle_parking"}}, nodes[:1]},
+		{"any tag value", map[string]interface{}{"types": []interface{}{"node"}, "tags": map[string]interface{}{"amenity": "*"}}, []interface{}{nodes[0], nodes[2]}},
+		{"no match", map[string]interface{}{"tags": map[string]interface{}{"amenity": "bicycle_parking", "covered": "*"}}, []interface{}{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			st := &dataset.Structure{Format: "pbf", FormatConfig: c.opts, Schema: PBFSchema()}
+			r, err := NewEntryReader(st, bytes.NewReader(testPBF(t)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := readValues(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.expect, got) {
+				t.Errorf("entries mismatch.\nexpected: %v\ngot:      %v", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestPBFReaderErrors(t *testing.T) {
+	st := &dataset.Structure{Format: "pbf", Schema: PBFSchema()}
+	if _, err := NewPBFReader(&dataset.Structure{Format: "pbf", FormatConfig: map[string]interface{}{"types": "node"}}, nil); err == nil || err.Error() != "invalid types value: node" {
+		t.Errorf("unexpected format config error: %v", err)
+	}
+
+	r, err := NewPBFReader(st, bytes.NewReader(testPBF(t, "HistoricalInformation")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `pbf: unsupported required feature "HistoricalInformation"`
+	if _, err := r.ReadEntry(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+
+	data := testPBF(t)
+	r, err = NewPBFReader(st, bytes.NewReader(data[:len(data)-10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "pbf: reading blob: unexpected EOF"
+	if _, err := r.ReadEntry(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+
+	if _, err := NewEntryWriter(st, &bytes.Buffer{}); err == nil || err.Error() != "invalid format to create writer: pbf" {
+		t.Errorf("expected pbf to be read-only, got: %v", err)
+	}
+}
+
+func TestPBFSchemaCopies(t *testing.T) {
+	sch := PBFSchema()
+	sch["items"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["type"] = "string"
+	if got := PBFSchema()["items"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["type"]; got != "integer" {
+		t.Errorf("expected changes to a returned schema to not affect others, got id type: %v", got)
+	}
+}
@@ -0,0 +1,253 @@
+// Package pbwire decodes & encodes the protocol buffer wire format without
+// generated code. Readers of protobuf based feeds use it to walk messages
+// field by field, interpreting field numbers against a known .proto
+// definition
+package pbwire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WireType is the encoding of a field value
+type WireType int
+
+const (
+	// Varint is a base 128 varint, used for integers, bools & enums
+	Varint WireType = 0
+	// Fixed64 is a little endian 8 byte value, used for doubles & fixed64s
+	Fixed64 WireType = 1
+	// Bytes is a length-delimited value, used for strings, bytes, embedded
+	// messages & packed repeated fields
+	Bytes WireType = 2
+	// Fixed32 is a little endian 4 byte value, used for floats & fixed32s
+	Fixed32 WireType = 5
+)
+
+// MaxMessageSize is the largest length-delimited value Reader will read
+const MaxMessageSize = 64 << 20
+
+// ErrTruncated indicates a message ended in the middle of a field
+var ErrTruncated = errors.New("pbwire: truncated message")
+
+// Field is a single decoded field of a message
+type Field struct {
+	// Num is the field number
+	Num int
+	// Type is the wire type of the field
+	Type WireType
+	// Value holds the value of varint & fixed fields
+	Value uint64
+	// Bytes holds the payload of length-delimited fields
+	Bytes []byte
+}
+
+// Int gives the value of an int32, int64, uint32, uint64 or enum field
+func (f Field) Int() int64 { return int64(f.Value) }
+
+// Sint gives the value of a zigzag encoded sint32 or sint64 field
+func (f Field) Sint() int64 { return Zigzag(f.Value) }
+
+// Bool gives the value of a bool field
+func (f Field) Bool() bool { return f.Value != 0 }
+
+// Float gives the value of a float field
+func (f Field) Float() float32 { return math.Float32frombits(uint32(f.Value)) }
+
+// Double gives the value of a double field
+func (f Field) Double() float64 { return math.Float64frombits(f.Value) }
+
+// String gives the value of a string field
+func (f Field) String() string { return string(f.Bytes) }
+
+// Varints gives the values of a repeated varint field, decoding packed
+// values of length-delimited fields
+func (f Field) Varints() ([]uint64, error) {
+	if f.Type == Varint {
+		return []uint64{f.Value}, nil
+	}
+	if f.Type != Bytes {
+		return nil, fmt.Errorf("pbwire: field %d: wire type %d is not a varint", f.Num, f.Type)
+	}
+	var vals []uint64
+	for b := f.Bytes; len(b) > 0; {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, ErrTruncated
+		}
+		vals = append(vals, v)
+		b = b[n:]
+	}
+	return vals, nil
+}
+
+// Zigzag decodes a zigzag encoded sint32 or sint64 value
+func Zigzag(v uint64) int64 { return int64(v>>1) ^ -int64(v&1) }
+
+// Decode calls fn for each field of msg in order of appearance
+func Decode(msg []byte, fn func(Field) error) error {
+	for len(msg) > 0 {
+		f, n, err := decodeField(msg)
+		if err != nil {
+			return err
+		}
+		msg = msg[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeField(b []byte) (f Field, n int, err error) {
+	tag, n := binary.Uvarint(b)
+	if n <= 0 {
+		return f, 0, ErrTruncated
+	}
+	if f, err = newField(tag); err != nil {
+		return f, 0, err
+	}
+
+	switch f.Type {
+	case Varint:
+		v, m := binary.Uvarint(b[n:])
+		if m <= 0 {
+			return f, 0, ErrTruncated
+		}
+		f.Value = v
+		n += m
+	case Fixed64:
+		if len(b[n:]) < 8 {
+			return f, 0, ErrTruncated
+		}
+		f.Value = binary.LittleEndian.Uint64(b[n:])
+		n += 8
+	case Fixed32:
+		if len(b[n:]) < 4 {
+			return f, 0, ErrTruncated
+		}
+		f.Value = uint64(binary.LittleEndian.Uint32(b[n:]))
+		n += 4
+	case Bytes:
+		l, m := binary.Uvarint(b[n:])
+		if m <= 0 || l > uint64(len(b[n+m:])) {
+			return f, 0, ErrTruncated
+		}
+		n += m
+		f.Bytes = b[n : n+int(l)]
+		n += int(l)
+	}
+	return f, n, nil
+}
+
+func newField(tag uint64) (Field, error) {
+	f := Field{Num: int(tag >> 3), Type: WireType(tag & 7)}
+	if f.Num <= 0 {
+		return f, fmt.Errorf("pbwire: invalid field number %d", f.Num)
+	}
+	switch f.Type {
+	case Varint, Fixed64, Bytes, Fixed32:
+		return f, nil
+	}
+	return f, fmt.Errorf("pbwire: field %d: unsupported wire type %d", f.Num, f.Type)
+}
+
+// Reader reads the top-level fields of a message from a stream, so large
+// messages made of repeated fields can be consumed one field at a time
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader creates a field reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next reads the next field, returning io.EOF at the end of the message
+func (r *Reader) Next() (Field, error) {
+	tag, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return Field{}, io.EOF
+	} else if err != nil {
+		return Field{}, ErrTruncated
+	}
+	f, err := newField(tag)
+	if err != nil {
+		return f, err
+	}
+
+	switch f.Type {
+	case Varint:
+		if f.Value, err = binary.ReadUvarint(r.r); err != nil {
+			return f, ErrTruncated
+		}
+	case Fixed64:
+		buf := make([]byte, 8)
+		if _, err = io.ReadFull(r.r, buf); err != nil {
+			return f, ErrTruncated
+		}
+		f.Value = binary.LittleEndian.Uint64(buf)
+	case Fixed32:
+		buf := make([]byte, 4)
+		if _, err = io.ReadFull(r.r, buf); err != nil {
+			return f, ErrTruncated
+		}
+		f.Value = uint64(binary.LittleEndian.Uint32(buf))
+	case Bytes:
+		l, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return f, ErrTruncated
+		}
+		if l > MaxMessageSize {
+			return f, fmt.Errorf("pbwire: field %d: length %d exceeds maximum message size", f.Num, l)
+		}
+		f.Bytes = make([]byte, int(l))
+		if _, err = io.ReadFull(r.r, f.Bytes); err != nil {
+			return f, ErrTruncated
+		}
+	}
+	return f, nil
+}
+
+// AppendVarint appends a varint field to b
+func AppendVarint(b []byte, num int, v uint64) []byte {
+	b = appendTag(b, num, Varint)
+	return appendUvarint(b, v)
+}
+
+// AppendFixed32 appends a 4 byte field to b
+func AppendFixed32(b []byte, num int, v uint32) []byte {
+	b = appendTag(b, num, Fixed32)
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, v)
+	return append(b, buf...)
+}
+
+// AppendFixed64 appends an 8 byte field to b
+func AppendFixed64(b []byte, num int, v uint64) []byte {
+	b = appendTag(b, num, Fixed64)
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return append(b, buf...)
+}
+
+// AppendBytes appends a length-delimited field to b
+func AppendBytes(b []byte, num int, v []byte) []byte {
+	b = appendTag(b, num, Bytes)
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendTag(b []byte, num int, t WireType) []byte {
+	return appendUvarint(b, uint64(num)<<3|uint64(t))
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, v)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,138 @@
+package pbwire
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	neg := int64(-5)
+	msg := AppendVarint(nil, 1, 150)
+	msg = AppendVarint(msg, 2, uint64(neg))
+	msg = AppendVarint(msg, 3, 9) // zigzag -5
+	msg = AppendFixed32(msg, 4, math.Float32bits(1.5))
+	msg = AppendFixed64(msg, 5, math.Float64bits(-2.25))
+	msg = AppendBytes(msg, 6, []byte("hello"))
+	msg = AppendBytes(msg, 2048, AppendVarint(nil, 1, 1))
+
+	check := func(fields []Field) {
+		if len(fields) != 7 {
+			t.Fatalf("expected 7 fields, got %d", len(fields))
+		}
+		if fields[0].Num != 1 || fields[0].Int() != 150 {
+			t.Errorf("varint mismatch. got: %v", fields[0])
+		}
+		if fields[1].Int() != -5 {
+			t.Errorf("negative varint mismatch. got: %d", fields[1].Int())
+		}
+		if fields[2].Sint() != -5 {
+			t.Errorf("zigzag mismatch. got: %d", fields[2].Sint())
+		}
+		if fields[3].Type != Fixed32 || fields[3].Float() != 1.5 {
+			t.Errorf("float mismatch. got: %v", fields[3].Float())
+		}
+		if fields[4].Type != Fixed64 || fields[4].Double() != -2.25 {
+			t.Errorf("double mismatch. got: %v", fields[4].Double())
+		}
+		if fields[5].String() != "hello" {
+			t.Errorf("string mismatch. got: %q", fields[5].String())
+		}
+		if fields[6].Num != 2048 {
+			t.Errorf("field number mismatch. got: %d", fields[6].Num)
+		}
+		var inner []Field
+		if err := Decode(fields[6].Bytes, func(f Field) error {
+			inner = append(inner, f)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(inner, []Field{{Num: 1, Type: Varint, Value: 1}}) {
+			t.Errorf("embedded message mismatch. got: %v", inner)
+		}
+	}
+
+	var decoded []Field
+	if err := Decode(msg, func(f Field) error {
+		decoded = append(decoded, f)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	check(decoded)
+
+	var streamed []Field
+	r := NewReader(bytes.NewReader(msg))
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		streamed = append(streamed, f)
+	}
+	check(streamed)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		msg         []byte
+		err         string
+	}{
+		{"truncated varint", []byte{0x08, 0x80}, "pbwire: truncated message"},
+		{"truncated bytes", []byte{0x0a, 0x05, 'a'}, "pbwire: truncated message"},
+		{"truncated fixed32", []byte{0x0d, 0x01}, "pbwire: truncated message"},
+		{"zero field number", []byte{0x00, 0x01}, "pbwire: invalid field number 0"},
+		{"group", []byte{0x0b}, "pbwire: field 1: unsupported wire type 3"},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := Decode(c.msg, func(Field) error { return nil })
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("decode error mismatch. expected: %q, got: %v", c.err, err)
+			}
+			_, err = NewReader(bytes.NewReader(c.msg)).Next()
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("reader error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestVarints(t *testing.T) {
+	packed := appendUvarint(nil, 1)
+	packed = appendUvarint(packed, 300)
+	packed = appendUvarint(packed, 3) // zigzag -2
+	var fields []Field
+	msg := AppendBytes(nil, 1, packed)
+	msg = AppendVarint(msg, 1, 7)
+	msg = AppendFixed32(msg, 2, 1)
+	if err := Decode(msg, func(f Field) error {
+		fields = append(fields, f)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	vals, err := fields[0].Varints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, []uint64{1, 300, 3}) {
+		t.Errorf("packed mismatch. got: %v", vals)
+	}
+	if Zigzag(vals[2]) != -2 {
+		t.Errorf("zigzag mismatch. got: %d", Zigzag(vals[2]))
+	}
+	if vals, err = fields[1].Varints(); err != nil || !reflect.DeepEqual(vals, []uint64{7}) {
+		t.Errorf("unpacked mismatch. got: %v, %v", vals, err)
+	}
+	if _, err := fields[2].Varints(); err == nil || err.Error() != "pbwire: field 2: wire type 5 is not a varint" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,374 @@
+package dsio
+
+import (
+	"fmt"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+// PipelineBuilder composes entry reader wrappers into a single reader. Each
+// stage wraps the reader of the stage before it and rewrites its structure
+// to describe the entries the stage produces. Rewritten structures are
+// checked as stages are added, and the first failing stage stops the
+// pipeline: later stages are skipped and Reader returns the error
+//
+//	r, err := dsio.Pipeline(body).Filter(f).Select("id", "name").Limit(10).Reader()
+type PipelineBuilder struct {
+	r   EntryReader
+	err error
+}
+
+// Pipeline starts a pipeline that reads from r
+func Pipeline(r EntryReader) *PipelineBuilder {
+	p := &PipelineBuilder{r: r}
+	if r == nil {
+		p.err = fmt.Errorf("pipeline: reader is required")
+	} else {
+		p.err = checkStage("source", r.Structure())
+	}
+	return p
+}
+
+// FilterFunc reports whether an entry should be kept
+type FilterFunc func(Entry) (bool, error)
+
+// Filter keeps only the entries f reports true for
+func (p *PipelineBuilder) Filter(f FilterFunc) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		if f == nil {
+			return nil, fmt.Errorf("filter: func is required")
+		}
+		return &filterReader{r: r, st: derivedStructure(r.Structure()), f: f}, nil
+	})
+}
+
+// Select projects array rows onto the named columns, in the given order.
+// Columns are matched by title, and the source must have a tabular schema
+func (p *PipelineBuilder) Select(cols ...string) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		return newSelectReader(r, cols)
+	})
+}
+
+// Offset skips the first n entries
+func (p *PipelineBuilder) Offset(n int) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		if n < 0 {
+			return nil, fmt.Errorf("offset: %d cannot be negative", n)
+		}
+		return &stageReader{EntryReader: &PagedReader{Reader: r, Limit: -1, Offset: n}, st: derivedStructure(r.Structure())}, nil
+	})
+}
+
+// Limit stops the pipeline after n entries
+func (p *PipelineBuilder) Limit(n int) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		if n < 0 {
+			return nil, fmt.Errorf("limit: %d cannot be negative", n)
+		}
+		return &stageReader{EntryReader: &PagedReader{Reader: r, Limit: n}, st: derivedStructure(r.Structure())}, nil
+	})
+}
+
+// Coerce converts the cells of array rows to the types of their schema
+// columns with the vals package. Null cells and cells of columns that allow
+// more than one type are left as they are. A cell that can't be converted
+// is an error
+func (p *PipelineBuilder) Coerce(opts ...func(*vals.CoerceConfig)) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		return newCoerceReader(r, opts)
+	})
+}
+
+// Map computes the columns of a mapping from each entry, see
+// NewMappingReader
+func (p *PipelineBuilder) Map(m *dataset.Mapping) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		return NewMappingReader(r, m)
+	})
+}
+
+// Migrate adapts old rows to a new structure, see NewMigrateReader
+func (p *PipelineBuilder) Migrate(to *dataset.Structure, opts ...func(*MigrateConfig)) *PipelineBuilder {
+	return p.Then(func(r EntryReader) (EntryReader, error) {
+		return NewMigrateReader(r, to, opts...)
+	})
+}
+
+// Then adds a stage built by wrap, for wrappers like NewAnonymizeReader or
+// NewUnionReader that have no dedicated pipeline method
+func (p *PipelineBuilder) Then(wrap func(EntryReader) (EntryReader, error)) *PipelineBuilder {
+	if p.err != nil {
+		return p
+	}
+	r, err := wrap(p.r)
+	if err != nil {
+		p.err = fmt.Errorf("pipeline: %w", err)
+		return p
+	}
+	if r == nil {
+		p.err = fmt.Errorf("pipeline: stage returned no reader")
+		return p
+	}
+	if err := checkStage("stage", r.Structure()); err != nil {
+		p.err = err
+		return p
+	}
+	p.r = r
+	return p
+}
+
+// Reader gives the reader of the final stage, or the error of the first
+// failing stage
+func (p *PipelineBuilder) Reader() (EntryReader, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.r, nil
+}
+
+// checkStage checks the structure a stage produces
+func checkStage(stage string, st *dataset.Structure) error {
+	if st == nil {
+		return fmt.Errorf("pipeline: %s: structure is required", stage)
+	}
+	if st.Schema != nil {
+		if _, err := GetTopLevelType(st); err != nil {
+			return fmt.Errorf("pipeline: %s: %w", stage, err)
+		}
+	}
+	if st.RequiresTabularSchema() {
+		if _, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err != nil {
+			return fmt.Errorf("pipeline: %s: %s requires a tabular schema: %w", stage, st.Format, err)
+		}
+	}
+	return nil
+}
+
+// derivedStructure copies st for a stage that changes which entries are read,
+// dropping values derived from the source body
+func derivedStructure(st *dataset.Structure) *dataset.Structure {
+	st = st.Clone()
+	st.Path = ""
+	st.Checksum = ""
+	st.Length = 0
+	st.ErrCount = 0
+	st.Entries = 0
+	return st
+}
+
+// stageReader overrides the structure of a wrapped reader
+type stageReader struct {
+	EntryReader
+	st *dataset.Structure
+}
+
+// Structure gives the stage structure
+func (r *stageReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// filterReader skips entries a filter func rejects
+type filterReader struct {
+	r  EntryReader
+	st *dataset.Structure
+	f  FilterFunc
+}
+
+var _ EntryReader = (*filterReader)(nil)
+
+// Structure gives the filtered structure
+func (r *filterReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next entry the filter keeps
+func (r *filterReader) ReadEntry() (Entry, error) {
+	for {
+		ent, err := r.r.ReadEntry()
+		if err != nil {
+			return ent, err
+		}
+		keep, err := r.f(ent)
+		if err != nil {
+			return Entry{}, fmt.Errorf("filter: entry %d: %w", ent.Index, err)
+		}
+		if keep {
+			return ent, nil
+		}
+	}
+}
+
+// Close closes the source reader
+func (r *filterReader) Close() error {
+	return r.r.Close()
+}
+
+// schemaColumns gives the raw schema items of a tabular structure with their
+// parsed columns
+func schemaColumns(st *dataset.Structure) (tabular.Columns, []interface{}, error) {
+	if st == nil || st.Schema == nil {
+		return nil, nil, fmt.Errorf("a tabular schema is required")
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	// ColumnsFromJSONSchema only accepts array schemas with items.items
+	items := st.Schema["items"].(map[string]interface{})["items"].([]interface{})
+	return cols, items, nil
+}
+
+// selectReader projects array rows onto a subset of columns
+type selectReader struct {
+	r         EntryReader
+	st        *dataset.Structure
+	positions []int
+	read      int
+}
+
+var _ EntryReader = (*selectReader)(nil)
+
+func newSelectReader(r EntryReader, titles []string) (EntryReader, error) {
+	if len(titles) == 0 {
+		return nil, fmt.Errorf("select: at least one column is required")
+	}
+	// items are taken from a copy of the source structure, so the projected
+	// structure doesn't share schema values with the source
+	base := r.Structure().Clone()
+	cols, items, err := schemaColumns(base)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+	index := map[string]int{}
+	for i, title := range cols.Titles() {
+		index[title] = i
+	}
+
+	positions := make([]int, len(titles))
+	selected := make([]interface{}, len(titles))
+	seen := map[string]bool{}
+	for i, title := range titles {
+		pos, ok := index[title]
+		if !ok {
+			return nil, fmt.Errorf("select: column %q not found", title)
+		}
+		if seen[title] {
+			return nil, fmt.Errorf("select: column %q is selected more than once", title)
+		}
+		seen[title] = true
+		positions[i] = pos
+		selected[i] = items[pos]
+	}
+
+	st := reshapedStructure(base, selected, base.Entries)
+	return &selectReader{r: r, st: st, positions: positions}, nil
+}
+
+// Structure gives the projected structure
+func (r *selectReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next source row, keeping only selected columns
+func (r *selectReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	src, ok := ent.Value.([]interface{})
+	if !ok {
+		return ent, fmt.Errorf("select: entry %d is not an array row", i)
+	}
+	row := make([]interface{}, len(r.positions))
+	for j, pos := range r.positions {
+		if pos < len(src) {
+			row[j] = src[pos]
+		}
+	}
+	ent.Value = row
+	return ent, nil
+}
+
+// Close closes the source reader
+func (r *selectReader) Close() error {
+	return r.r.Close()
+}
+
+// coerceReader converts row cells to the types of their columns
+type coerceReader struct {
+	r      EntryReader
+	titles []string
+	types  []string
+	opts   []func(*vals.CoerceConfig)
+	read   int
+}
+
+var _ EntryReader = (*coerceReader)(nil)
+
+func newCoerceReader(r EntryReader, opts []func(*vals.CoerceConfig)) (EntryReader, error) {
+	cols, _, err := schemaColumns(r.Structure())
+	if err != nil {
+		return nil, fmt.Errorf("coerce: %w", err)
+	}
+	types := make([]string, len(cols))
+	for i, c := range cols {
+		if c.Type != nil && len(*c.Type) == 1 {
+			types[i] = (*c.Type)[0]
+		}
+	}
+	return &coerceReader{r: r, titles: cols.Titles(), types: types, opts: opts}, nil
+}
+
+// Structure gives the source structure, which coercion doesn't change
+func (r *coerceReader) Structure() *dataset.Structure {
+	return r.r.Structure()
+}
+
+// ReadEntry reads the next source row with coerced cells
+func (r *coerceReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	src, ok := ent.Value.([]interface{})
+	if !ok {
+		return ent, fmt.Errorf("coerce: entry %d is not an array row", i)
+	}
+	row := make([]interface{}, len(src))
+	for j, v := range src {
+		row[j] = v
+		if v == nil || j >= len(r.types) {
+			continue
+		}
+		var err error
+		switch r.types[j] {
+		case "integer":
+			row[j], err = vals.ToInt(v, r.opts...)
+		case "number":
+			row[j], err = vals.ToFloat(v, r.opts...)
+		case "boolean":
+			row[j], err = vals.ToBool(v, r.opts...)
+		case "string":
+			row[j], err = vals.ToString(v, r.opts...)
+		}
+		if err != nil {
+			return Entry{}, fmt.Errorf("coerce: entry %d column %q: %w", i, r.titles[j], err)
+		}
+	}
+	ent.Value = row
+	return ent, nil
+}
+
+// Close closes the source reader
+func (r *coerceReader) Close() error {
+	return r.r.Close()
+}
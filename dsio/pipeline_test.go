@@ -0,0 +1,191 @@
+package dsio
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+func pipelineSource(t *testing.T, data string) EntryReader {
+	st := &dataset.Structure{
+		Format:  "json",
+		Entries: 4,
+		Length:  len(data),
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "id", "type": "integer"},
+					map[string]interface{}{"title": "name", "type": "string", "description": "stop name"},
+					map[string]interface{}{"title": "lat", "type": "number"},
+				},
+			},
+		},
+	}
+	r, err := NewJSONReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+const pipelineData = `[[1,"Hauptbahnhof",48.78],[2,"Rotebühlplatz","48.77"],[3,"Schlossplatz",48.779],[4,"Berliner Platz",null]]`
+
+func TestPipeline(t *testing.T) {
+	evenIDs := func(ent Entry) (bool, error) {
+		return ent.Value.([]interface{})[0].(int64)%2 == 0, nil
+	}
+	r, err := Pipeline(pipelineSource(t, pipelineData)).
+		Filter(evenIDs).
+		Coerce().
+		Select("lat", "name").
+		Limit(1).
+		Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{[]interface{}{48.77, "Rotebühlplatz"}}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("rows mismatch. expected: %v, got: %v", expect, got)
+	}
+
+	st := r.Structure()
+	if st.Entries != 0 || st.Length != 0 {
+		t.Errorf("expected derived values to be dropped, got entries %d, length %d", st.Entries, st.Length)
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := cols.Titles(); !reflect.DeepEqual(titles, []string{"lat", "name"}) {
+		t.Errorf("expected projected columns, got: %v", titles)
+	}
+	if cols[1].Description != "stop name" {
+		t.Errorf("expected column schemas to be kept, got: %#v", cols[1])
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPipelineOffset(t *testing.T) {
+	r, err := Pipeline(pipelineSource(t, pipelineData)).Offset(1).Limit(2).Select("id").Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{[]interface{}{int64(2)}, []interface{}{int64(3)}}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("rows mismatch. expected: %v, got: %v", expect, got)
+	}
+}
+
+func TestPipelineThen(t *testing.T) {
+	calls := 0
+	keep := Pipeline(pipelineSource(t, pipelineData)).Then(func(r EntryReader) (EntryReader, error) {
+		calls++
+		return NewAppendReader(r, Entry{Value: []interface{}{5, "Charlottenplatz", 48.77}}), nil
+	})
+	r, err := keep.Select("name").Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || len(got) != 5 {
+		t.Errorf("expected appended row, got %d rows", len(got))
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	fail := fmt.Errorf("boom")
+	cases := []struct {
+		build func(p *PipelineBuilder) *PipelineBuilder
+		err   string
+	}{
+		{func(p *PipelineBuilder) *PipelineBuilder { return p.Select() }, "pipeline: select: at least one column is required"},
+		{func(p *PipelineBuilder) *PipelineBuilder { return p.Select("stop") }, `pipeline: select: column "stop" not found`},
+		{func(p *PipelineBuilder) *PipelineBuilder { return p.Select("id", "id") }, `pipeline: select: column "id" is selected more than once`},
+		{func(p *PipelineBuilder) *PipelineBuilder { return p.Filter(nil) }, "pipeline: filter: func is required"},
+		{func(p *PipelineBuilder) *PipelineBuilder { return p.Limit(-1) }, "pipeline: limit: -1 cannot be negative"},
+		{func(p *PipelineBuilder) *PipelineBuilder { return p.Offset(-2) }, "pipeline: offset: -2 cannot be negative"},
+		{func(p *PipelineBuilder) *PipelineBuilder {
+			return p.Then(func(EntryReader) (EntryReader, error) { return nil, fail }).Select("nope")
+		}, "pipeline: boom"},
+		{func(p *PipelineBuilder) *PipelineBuilder {
+			return p.Then(func(EntryReader) (EntryReader, error) { return nil, nil })
+		}, "pipeline: stage returned no reader"},
+		{func(p *PipelineBuilder) *PipelineBuilder {
+			return p.Then(func(r EntryReader) (EntryReader, error) {
+				return &stageReader{EntryReader: r, st: &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray}}, nil
+			})
+		}, "pipeline: stage: csv requires a tabular schema: invalid tabular schema: top level 'items' property must be an object"},
+	}
+	for i, c := range cases {
+		_, err := c.build(Pipeline(pipelineSource(t, pipelineData))).Reader()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+
+	if _, err := Pipeline(nil).Reader(); err == nil || err.Error() != "pipeline: reader is required" {
+		t.Errorf("expected nil reader error, got: %v", err)
+	}
+	untabular := jsonArrayReader(t, `[1,2]`)
+	if _, err := Pipeline(untabular).Select("a").Reader(); err == nil {
+		t.Errorf("expected select on a non-tabular body to fail")
+	}
+}
+
+func TestPipelineReadErrors(t *testing.T) {
+	r, err := Pipeline(pipelineSource(t, `[[1,"a",true]]`)).Coerce().Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadEntry(); err == nil {
+		t.Errorf("expected a failing coercion to error")
+	}
+
+	r, err = Pipeline(pipelineSource(t, `[[1,"a",1]]`)).Filter(func(Entry) (bool, error) { return false, fmt.Errorf("nope") }).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadEntry(); err == nil || err.Error() != "filter: entry 0: nope" {
+		t.Errorf("expected filter error, got: %v", err)
+	}
+
+	r, err = Pipeline(pipelineSource(t, `["a"]`)).Select("id").Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadEntry(); err == nil || err.Error() != "select: entry 0 is not an array row" {
+		t.Errorf("expected non-row error, got: %v", err)
+	}
+
+	r, err = Pipeline(pipelineSource(t, `[["1.500",1,"2"]]`)).Coerce(vals.WithLocale(vals.LocaleDE)).Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []interface{}{int64(1500), "1", 2.0}; !reflect.DeepEqual(expect, ent.Value) {
+		t.Errorf("coerced row mismatch. expected: %#v, got: %#v", expect, ent.Value)
+	}
+}
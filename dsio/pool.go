@@ -0,0 +1,105 @@
+package dsio
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Readers reuse memory through pools to reduce garbage collection when
+// reading many entries or many bodies. Buffers & decoder state are pooled
+// internally. Pooling entry rows requires opting in with BorrowEntries, as
+// callers must release each entry when they're done with it
+
+const (
+	// maxPooledRowLen caps the length of rows kept for reuse
+	maxPooledRowLen = 1024
+	// maxPooledBufferSize caps the capacity of byte buffers kept for reuse
+	maxPooledBufferSize = 64 * 1024
+	// jsonReaderBufferSize is the read buffer size of NewJSONReader. A huge
+	// buffer (a quarter of a MB) speeds up string reads
+	jsonReaderBufferSize = 256 * 1024
+)
+
+var (
+	rowPool        sync.Pool
+	bufferPool     = sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+	jsonReaderPool sync.Pool
+)
+
+// lender is implemented by readers that can decode array rows into pooled
+// memory
+type lender interface {
+	lendEntries()
+}
+
+// BorrowEntries opts r into borrowed entries, reporting false if r doesn't
+// support them. CSV, JSON & CBOR readers support borrowed entries.
+// A reader with borrowed entries takes the row slice of each array entry it
+// reads from a pool. Callers pass each entry to ReleaseEntry once they're
+// done with it, freeing the row to be reused by a later entry. A released
+// entry's row must not be used or retained. Values within the row, like
+// strings & nested arrays, aren't reused and remain valid. Entries that are
+// never released are garbage collected as usual
+func BorrowEntries(r EntryReader) bool {
+	l, ok := r.(lender)
+	if ok {
+		l.lendEntries()
+	}
+	return ok
+}
+
+// ReleaseEntry frees the array row of an entry for reuse. It's always safe
+// to release an entry that will no longer be used, whether or not it was
+// read with borrowed entries
+func ReleaseEntry(ent Entry) {
+	row, ok := ent.Value.([]interface{})
+	if !ok || cap(row) == 0 || cap(row) > maxPooledRowLen {
+		return
+	}
+	row = row[:cap(row)]
+	for i := range row {
+		row[i] = nil
+	}
+	rowPool.Put(row[:0])
+}
+
+// borrowRow gets a row of length n from the pool, allocating one if the pool
+// is empty or the pooled row is too small
+func borrowRow(n int) []interface{} {
+	if v := rowPool.Get(); v != nil {
+		if row := v.([]interface{}); cap(row) >= n {
+			return row[:n]
+		}
+	}
+	return make([]interface{}, n)
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// getJSONReaderBuffer gets a read buffer of jsonReaderBufferSize reading
+// from r
+func getJSONReaderBuffer(r io.Reader) *bufio.Reader {
+	if v := jsonReaderPool.Get(); v != nil {
+		br := v.(*bufio.Reader)
+		br.Reset(r)
+		return br
+	}
+	return bufio.NewReaderSize(r, jsonReaderBufferSize)
+}
+
+func putJSONReaderBuffer(br *bufio.Reader) {
+	br.Reset(nil)
+	jsonReaderPool.Put(br)
+}
@@ -0,0 +1,140 @@
+package dsio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestBorrowEntries(t *testing.T) {
+	rows := []interface{}{
+		[]interface{}{"a", int64(1), []interface{}{"nested"}},
+		[]interface{}{"b", int64(2), []interface{}{}},
+	}
+	jsonBody := `[["a",1,["nested"]],["b",2,[]]]`
+	jsonSt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+
+	cborSt := &dataset.Structure{Format: "cbor", Schema: dataset.BaseSchemaArray}
+	cborBody := &bytes.Buffer{}
+	w, err := NewCBORWriter(cborSt, cborBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteEntry(Entry{Value: row}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	readers := map[string]func() EntryReader{
+		"json": func() EntryReader {
+			r, err := NewJSONReader(jsonSt, strings.NewReader(jsonBody))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return r
+		},
+		"cbor": func() EntryReader {
+			r, err := NewCBORReader(cborSt, bytes.NewReader(cborBody.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return r
+		},
+	}
+
+	for name, newReader := range readers {
+		t.Run(name, func(t *testing.T) {
+			// read twice, so the second pass may reuse rows released by the first
+			for pass := 0; pass < 2; pass++ {
+				r := newReader()
+				if !BorrowEntries(r) {
+					t.Fatalf("expected %s reader to support borrowed entries", name)
+				}
+				for i, expect := range rows {
+					ent, err := r.ReadEntry()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(expect, ent.Value) {
+						t.Errorf("pass %d row %d mismatch. expected: %v, got: %v", pass, i, expect, ent.Value)
+					}
+					ReleaseEntry(ent)
+				}
+				if err := r.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		for pass := 0; pass < 2; pass++ {
+			r := stringCSVReader(t, "x,y\na,b\nc,d\n", "x", "y")
+			if !BorrowEntries(r) {
+				t.Fatal("expected csv reader to support borrowed entries")
+			}
+			for i, expect := range []interface{}{[]interface{}{"a", "b"}, []interface{}{"c", "d"}} {
+				ent, err := r.ReadEntry()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(expect, ent.Value) {
+					t.Errorf("pass %d row %d mismatch. expected: %v, got: %v", pass, i, expect, ent.Value)
+				}
+				ReleaseEntry(ent)
+			}
+		}
+	})
+
+	tbl, err := NewTable(stringCSVReader(t, "", "x").Structure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if BorrowEntries(tbl.Reader()) {
+		t.Errorf("expected table reader to not support borrowed entries")
+	}
+}
+
+func TestReleaseEntry(t *testing.T) {
+	row := []interface{}{"a", 1}
+	ReleaseEntry(Entry{Value: row})
+	if row[0] != nil || row[1] != nil {
+		t.Errorf("expected released rows to drop their values, got: %v", row)
+	}
+	// releasing non-row values is a no-op
+	ReleaseEntry(Entry{Value: "a"})
+	ReleaseEntry(Entry{})
+
+	if got := borrowRow(3); len(got) != 3 || got[0] != nil {
+		t.Errorf("expected an empty row of length 3, got: %v", got)
+	}
+}
+
+func TestJSONReaderClosed(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	for i := 0; i < 2; i++ {
+		r, err := NewJSONReader(st, strings.NewReader(`[1]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ent, err := r.ReadEntry(); err != nil || ent.Value != int64(1) {
+			t.Errorf("read %d mismatch. got: %v, %v", i, ent.Value, err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Close(); err != nil {
+			t.Errorf("expected closing twice to be a no-op, got: %s", err)
+		}
+		if _, err := r.ReadEntry(); err == nil || err.Error() != "json reader is closed" {
+			t.Errorf("expected closed reader error, got: %v", err)
+		}
+	}
+}
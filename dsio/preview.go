@@ -0,0 +1,90 @@
+package dsio
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/qri-io/dataset"
+)
+
+// DefaultPreviewHead & DefaultPreviewSample are the preview sizes used when
+// a caller doesn't have a preference
+const (
+	DefaultPreviewHead   = 20
+	DefaultPreviewSample = 80
+)
+
+// NewPreview reads r in a single pass, building a preview of the first head
+// entries & a uniform random sample of up to sample of the remaining entries.
+// Sampled entries are held in memory, so preview sizes should be small. The
+// same seed & body always give the same preview
+func NewPreview(r EntryReader, head, sample int, seed int64) (*dataset.Preview, error) {
+	if head < 0 || sample < 0 {
+		return nil, fmt.Errorf("preview: head & sample sizes cannot be negative")
+	}
+
+	type sampled struct {
+		i   int
+		ent Entry
+	}
+	var (
+		heads     []sampled
+		reservoir []sampled
+		rnd       = rand.New(rand.NewSource(seed))
+		objects   bool
+	)
+	if st := r.Structure(); st != nil {
+		objects = st.Schema["type"] == "object"
+	}
+
+	total := 0
+	err := EachEntry(r, func(i int, ent Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		total++
+		s := sampled{i: i, ent: ent}
+		if len(heads) < head {
+			heads = append(heads, s)
+			return nil
+		}
+		// reservoir sampling: the n-th entry past the head replaces a random
+		// sampled entry with probability sample/n
+		n := i - head + 1
+		if len(reservoir) < sample {
+			reservoir = append(reservoir, s)
+		} else if j := rnd.Intn(n); j < sample {
+			reservoir[j] = s
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("preview: %w", err)
+	}
+	sort.Slice(reservoir, func(a, b int) bool { return reservoir[a].i < reservoir[b].i })
+
+	p := &dataset.Preview{
+		Head:    len(heads),
+		Indexes: []int{},
+		Seed:    seed,
+		Total:   total,
+	}
+	entries := append(heads, reservoir...)
+	if objects {
+		body := map[string]interface{}{}
+		for _, s := range entries {
+			body[s.ent.Key] = s.ent.Value
+			p.Indexes = append(p.Indexes, s.i)
+		}
+		p.Body = body
+	} else {
+		body := make([]interface{}, 0, len(entries))
+		for _, s := range entries {
+			body = append(body, s.ent.Value)
+			p.Indexes = append(p.Indexes, s.i)
+		}
+		p.Body = body
+	}
+	return p, nil
+}
@@ -0,0 +1,87 @@
+package dsio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestNewPreview(t *testing.T) {
+	body := "["
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			body += ","
+		}
+		body += "[" + string(rune('0'+i%10)) + "]"
+	}
+	body += "]"
+
+	p, err := NewPreview(jsonArrayReader(t, body), 3, 5, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Head != 3 || p.Total != 100 || p.Seed != 7 {
+		t.Errorf("preview mismatch: head %d, total %d, seed %d", p.Head, p.Total, p.Seed)
+	}
+	if len(p.Indexes) != 8 || len(p.Body.([]interface{})) != 8 {
+		t.Fatalf("expected 8 sampled entries, got indexes: %v", p.Indexes)
+	}
+	for i, idx := range p.Indexes {
+		if i < 3 && idx != i {
+			t.Errorf("expected head index %d to be %d", i, idx)
+		}
+		if i > 0 && idx <= p.Indexes[i-1] {
+			t.Errorf("expected ascending indexes, got: %v", p.Indexes)
+		}
+		expect := []interface{}{int64(idx % 10)}
+		if got := p.Body.([]interface{})[i]; !reflect.DeepEqual(expect, got) {
+			t.Errorf("entry %d mismatch. expected: %v, got: %v", idx, expect, got)
+		}
+	}
+
+	again, err := NewPreview(jsonArrayReader(t, body), 3, 5, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.Indexes, again.Indexes) {
+		t.Errorf("expected equal seeds to give equal samples. %v != %v", p.Indexes, again.Indexes)
+	}
+}
+
+func TestNewPreviewSmallBody(t *testing.T) {
+	p, err := NewPreview(jsonArrayReader(t, `[1,2,3]`), 2, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &dataset.Preview{
+		Body:    []interface{}{int64(1), int64(2), int64(3)},
+		Head:    2,
+		Indexes: []int{0, 1, 2},
+		Total:   3,
+	}
+	if !reflect.DeepEqual(expect, p) {
+		t.Errorf("preview mismatch. expected: %#v, got: %#v", expect, p)
+	}
+
+	if _, err := NewPreview(jsonArrayReader(t, `[]`), -1, 0, 0); err == nil {
+		t.Error("expected negative head to error")
+	}
+}
+
+func TestNewPreviewObject(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}
+	r, err := NewJSONReader(st, bytes.NewBufferString(`{"a":1,"b":2,"c":3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPreview(r, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{"a": int64(1)}
+	if !reflect.DeepEqual(expect, p.Body) || p.Total != 3 {
+		t.Errorf("preview mismatch. expected body: %v, got: %v, total: %d", expect, p.Body, p.Total)
+	}
+}
@@ -17,7 +17,7 @@ import (
 // if read from directly. This can cause issues with checksums and byte counts.
 // Use with caution.
 func Reader(data io.Reader) io.Reader {
-	return crlfReplaceReader{
+	return &crlfReplaceReader{
 		rdr: bufio.NewReader(data),
 	}
 }
@@ -25,10 +25,13 @@ func Reader(data io.Reader) io.Reader {
 // crlfReplaceReader wraps a reader
 type crlfReplaceReader struct {
 	rdr *bufio.Reader
+	// lf is true when a lonely \r ended the last read, and the \n that
+	// follows it is still to be written
+	lf bool
 }
 
 // Read implements io.Reader for crlfReplaceReader
-func (c crlfReplaceReader) Read(p []byte) (n int, err error) {
+func (c *crlfReplaceReader) Read(p []byte) (n int, err error) {
 	lenP := len(p)
 	if lenP == 0 {
 		return
@@ -39,17 +42,24 @@ func (c crlfReplaceReader) Read(p []byte) (n int, err error) {
 			return
 		}
 
+		if c.lf {
+			p[n] = '\n'
+			c.lf = false
+			n++
+			continue
+		}
+
 		p[n], err = c.rdr.ReadByte()
 		if err != nil {
 			return
 		}
 
-		// any time we encounter \r & still have space, check to see if \n follows
-		// ff next char is not \n, add it in manually
-		if p[n] == '\r' && n < lenP-1 {
-			if pk, err := c.rdr.Peek(1); (err == nil && pk[0] != '\n') || (err != nil && err.Error() == "EOF") {
-				n++
-				p[n] = '\n'
+		// any time we encounter \r, check to see if \n follows. if the next
+		// char is not \n, add it in manually. the added \n is carried over to
+		// the next read when p is full
+		if p[n] == '\r' {
+			if pk, err := c.rdr.Peek(1); (err == nil && pk[0] != '\n') || err == io.EOF {
+				c.lf = true
 			}
 		}
 
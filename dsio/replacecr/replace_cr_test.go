@@ -2,7 +2,9 @@ package replacecr
 
 import (
 	"bytes"
+	"io/ioutil"
 	"testing"
+	"testing/iotest"
 )
 
 func TestReader(t *testing.T) {
@@ -21,3 +23,17 @@ func TestReader(t *testing.T) {
 		t.Errorf("byte mismatch. expected:\n%v\ngot:\n%v", expect, got)
 	}
 }
+
+func TestReaderSmallReads(t *testing.T) {
+	input := []byte("a,\"b\rc\"\r1,2\r\n3,4\r")
+	expect := []byte("a,\"b\r\nc\"\r\n1,2\r\n3,4\r\n")
+
+	// lonely carriage returns at the end of a read must still get a line feed
+	got, err := ioutil.ReadAll(iotest.OneByteReader(Reader(bytes.NewReader(input))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expect, got) {
+		t.Errorf("byte mismatch. expected:\n%q\ngot:\n%q", expect, got)
+	}
+}
@@ -0,0 +1,473 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// NewTransposeReader swaps the rows & columns of a body of array rows. When
+// labeled is true the first column of r holds row labels: labels become the
+// column titles of the transposed body, and the column titles of r become its
+// first column, which requires r to have a tabular schema. Transposing reads
+// the entire body of r before the first entry is returned
+func NewTransposeReader(r EntryReader, labeled bool) (EntryReader, error) {
+	var titles []string
+	var types []interface{}
+	if st := r.Structure(); st != nil && st.Schema != nil {
+		if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+			titles = cols.Titles()
+			for _, col := range cols {
+				types = append(types, colType(col.Type))
+			}
+		} else if labeled {
+			return nil, fmt.Errorf("transpose: %w", err)
+		}
+	} else if labeled {
+		return nil, fmt.Errorf("transpose: labeled rows require a tabular schema")
+	}
+
+	var rows [][]interface{}
+	width := 0
+	err := EachEntry(r, func(i int, ent Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		row, ok := ent.Value.([]interface{})
+		if !ok {
+			return fmt.Errorf("transpose: entry %d is not an array row", i)
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	first := 0
+	if labeled {
+		first = 1
+		if len(titles) > width {
+			width = len(titles)
+		}
+	}
+
+	// each source row becomes a column, typed by the source columns it spans
+	var cellType interface{}
+	if first < len(types) {
+		cellType = types[first]
+		for _, t := range types[first:] {
+			if !reflect.DeepEqual(cellType, t) {
+				cellType = nil
+				break
+			}
+		}
+	}
+
+	var cols []interface{}
+	if labeled {
+		cols = append(cols, map[string]interface{}{"title": titles[0], "type": "string"})
+	}
+	for _, row := range rows {
+		title := fmt.Sprintf("col_%d", len(cols))
+		if labeled && len(row) > 0 && row[0] != nil {
+			title = fmt.Sprint(row[0])
+		}
+		col := map[string]interface{}{"title": title}
+		if cellType != nil {
+			col["type"] = cellType
+		}
+		cols = append(cols, col)
+	}
+
+	out := make([][]interface{}, 0, width-first)
+	for j := first; j < width; j++ {
+		row := make([]interface{}, 0, len(cols))
+		if labeled {
+			title := fmt.Sprintf("col_%d", j)
+			if j < len(titles) {
+				title = titles[j]
+			}
+			row = append(row, title)
+		}
+		for _, src := range rows {
+			var v interface{}
+			if j < len(src) {
+				v = src[j]
+			}
+			row = append(row, v)
+		}
+		out = append(out, row)
+	}
+
+	return &rowsReader{st: reshapedStructure(r.Structure(), cols, len(out)), rows: out, closer: r}, nil
+}
+
+// Pivot configures a long to wide reshape of tabular rows. Each distinct
+// value of the Key column becomes a single row, each distinct value of the
+// Column column becomes a column, and cells are filled from the Value column.
+// Other columns are dropped
+type Pivot struct {
+	// Key is the title of the column that identifies output rows
+	Key string
+	// Column is the title of the column whose values become column titles
+	Column string
+	// Value is the title of the column holding cell values
+	Value string
+	// Columns lists expected values of Column, in output order. When empty
+	// columns are discovered from the body, in order of first appearance
+	Columns []string
+	// Sorted declares that rows sharing a key are adjacent in the body
+	Sorted bool
+}
+
+// NewPivotReader reshapes a body of array rows from long to wide form. When
+// both Columns and Sorted are set, entries are streamed holding only a
+// single output row in memory. Otherwise the body of r is read in full
+// before the first entry is returned
+func NewPivotReader(r EntryReader, p Pivot) (EntryReader, error) {
+	st := r.Structure()
+	if st == nil || st.Schema == nil {
+		return nil, fmt.Errorf("pivot: tabular schema is required")
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("pivot: %w", err)
+	}
+
+	pr := &pivotReader{r: r, p: p, columns: map[string]int{}, seen: map[string]bool{}}
+	if pr.key, err = columnIndex(cols, p.Key); err != nil {
+		return nil, err
+	}
+	if pr.col, err = columnIndex(cols, p.Column); err != nil {
+		return nil, err
+	}
+	if pr.val, err = columnIndex(cols, p.Value); err != nil {
+		return nil, err
+	}
+	for _, c := range p.Columns {
+		if _, ok := pr.columns[c]; ok {
+			return nil, fmt.Errorf("pivot: duplicate column %q", c)
+		}
+		pr.columns[c] = len(pr.titles)
+		pr.titles = append(pr.titles, c)
+	}
+
+	streaming := len(p.Columns) > 0 && p.Sorted
+	if !streaming {
+		// buffer all rows; columns and group order are fixed once r is read
+		if err := pr.buffer(); err != nil {
+			return nil, err
+		}
+	}
+
+	schemaCols := []interface{}{map[string]interface{}{"title": p.Key, "type": colType(cols[pr.key].Type)}}
+	valType := nullable(colType(cols[pr.val].Type))
+	for _, title := range pr.titles {
+		col := map[string]interface{}{"title": title}
+		if valType != nil {
+			col["type"] = valType
+		}
+		schemaCols = append(schemaCols, col)
+	}
+	entries := 0
+	if !streaming {
+		entries = len(pr.rows)
+	}
+	pr.st = reshapedStructure(st, schemaCols, entries)
+
+	if !streaming {
+		return &rowsReader{st: pr.st, rows: pr.rows, closer: r}, nil
+	}
+	return pr, nil
+}
+
+func columnIndex(cols tabular.Columns, title string) (int, error) {
+	for i, col := range cols {
+		if col.Title == title {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("pivot: column %q not found", title)
+}
+
+// pivotReader groups long rows into wide rows
+type pivotReader struct {
+	r  EntryReader
+	p  Pivot
+	st *dataset.Structure
+
+	key, col, val int
+	titles        []string
+	columns       map[string]int
+
+	seen    map[string]bool
+	group   string
+	current []interface{}
+	read    int
+	emitted int
+	done    bool
+
+	// rows populated when buffering
+	rows [][]interface{}
+}
+
+var _ EntryReader = (*pivotReader)(nil)
+
+// Structure gives the structure of the pivoted body
+func (r *pivotReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next wide row, streaming sorted input
+func (r *pivotReader) ReadEntry() (Entry, error) {
+	for !r.done {
+		ent, err := r.r.ReadEntry()
+		if err == io.EOF {
+			r.done = true
+			break
+		} else if err != nil {
+			return ent, err
+		}
+		key, keyVal, cell, err := r.split(r.read, ent)
+		if err != nil {
+			return Entry{}, err
+		}
+		i := r.read
+		r.read++
+
+		if r.current != nil && key == r.group {
+			if err := r.set(r.current, key, cell.column, cell.value); err != nil {
+				return Entry{}, err
+			}
+			continue
+		}
+		if r.seen[key] {
+			return Entry{}, fmt.Errorf("pivot: entry %d: rows with key %q are not adjacent", i, key)
+		}
+		r.seen[key] = true
+
+		prev := r.current
+		r.group = key
+		r.current = r.newRow(keyVal)
+		if err := r.set(r.current, key, cell.column, cell.value); err != nil {
+			return Entry{}, err
+		}
+		if prev != nil {
+			return r.entry(prev), nil
+		}
+	}
+
+	if r.current != nil {
+		row := r.current
+		r.current = nil
+		return r.entry(row), nil
+	}
+	return Entry{}, io.EOF
+}
+
+// buffer reads all rows of r, grouping by key in order of first appearance
+func (r *pivotReader) buffer() error {
+	var cells []pivotCell
+	groups := map[string]int{}
+	var keyVals []interface{}
+
+	err := EachEntry(r.r, func(i int, ent Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		key, keyVal, c, err := r.split(i, ent)
+		if err != nil {
+			return err
+		}
+		if r.p.Sorted && r.seen[key] && key != r.group {
+			return fmt.Errorf("pivot: entry %d: rows with key %q are not adjacent", i, key)
+		}
+		r.seen[key] = true
+		r.group = key
+
+		if _, ok := groups[key]; !ok {
+			groups[key] = len(keyVals)
+			keyVals = append(keyVals, keyVal)
+		}
+		if _, ok := r.columns[c.column]; !ok {
+			if len(r.p.Columns) > 0 {
+				return fmt.Errorf("pivot: entry %d: unexpected column %q", i, c.column)
+			}
+			r.columns[c.column] = len(r.titles)
+			r.titles = append(r.titles, c.column)
+		}
+		cells = append(cells, c)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.rows = make([][]interface{}, len(keyVals))
+	for i, kv := range keyVals {
+		r.rows[i] = r.newRow(kv)
+	}
+	for _, c := range cells {
+		if err := r.set(r.rows[groups[c.key]], c.key, c.column, c.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pivotCell is a single value of a long row
+type pivotCell struct {
+	key    string
+	column string
+	value  interface{}
+}
+
+// split extracts the key, column & value of long row i
+func (r *pivotReader) split(i int, ent Entry) (key string, keyVal interface{}, cell pivotCell, err error) {
+	row, ok := ent.Value.([]interface{})
+	if !ok {
+		return "", nil, cell, fmt.Errorf("pivot: entry %d is not an array row", i)
+	}
+	get := func(i int) interface{} {
+		if i < len(row) {
+			return row[i]
+		}
+		return nil
+	}
+	keyVal = get(r.key)
+	if keyVal == nil {
+		return "", nil, cell, fmt.Errorf("pivot: entry %d: key %q is null", i, r.p.Key)
+	}
+	column := get(r.col)
+	if column == nil {
+		return "", nil, cell, fmt.Errorf("pivot: entry %d: column %q is null", i, r.p.Column)
+	}
+	key = fmt.Sprint(keyVal)
+	return key, keyVal, pivotCell{key: key, column: fmt.Sprint(column), value: get(r.val)}, nil
+}
+
+func (r *pivotReader) newRow(keyVal interface{}) []interface{} {
+	row := make([]interface{}, len(r.titles)+1)
+	row[0] = keyVal
+	return row
+}
+
+// set fills a cell of a wide row. Each cell may only be given one non-null
+// value
+func (r *pivotReader) set(row []interface{}, key, column string, value interface{}) error {
+	i, ok := r.columns[column]
+	if !ok {
+		return fmt.Errorf("pivot: key %q: unexpected column %q", key, column)
+	}
+	if row[i+1] != nil {
+		return fmt.Errorf("pivot: key %q: duplicate value for column %q", key, column)
+	}
+	row[i+1] = value
+	return nil
+}
+
+func (r *pivotReader) entry(row []interface{}) Entry {
+	ent := Entry{Index: r.emitted, Value: row}
+	r.emitted++
+	return ent
+}
+
+// Close closes the wrapped reader
+func (r *pivotReader) Close() error {
+	return r.r.Close()
+}
+
+// rowsReader reads buffered rows of a reshaped body
+type rowsReader struct {
+	st     *dataset.Structure
+	rows   [][]interface{}
+	i      int
+	closer io.Closer
+}
+
+var _ EntryReader = (*rowsReader)(nil)
+
+// Structure gives the structure of the reshaped body
+func (r *rowsReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next buffered row
+func (r *rowsReader) ReadEntry() (Entry, error) {
+	if r.i == len(r.rows) {
+		return Entry{}, io.EOF
+	}
+	ent := Entry{Index: r.i, Value: r.rows[r.i]}
+	r.i++
+	return ent, nil
+}
+
+// Close closes the wrapped reader
+func (r *rowsReader) Close() error {
+	return r.closer.Close()
+}
+
+// reshapedStructure copies st, describing rows with the given columns.
+// entries should be 0 when the number of rows is not known in advance
+func reshapedStructure(st *dataset.Structure, cols []interface{}, entries int) *dataset.Structure {
+	if st == nil {
+		st = &dataset.Structure{}
+	}
+	st = st.Clone()
+	st.Path = ""
+	st.Checksum = ""
+	st.Length = 0
+	st.ErrCount = 0
+	st.Entries = entries
+	st.Depth = 2
+	st.Schema = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": cols,
+		},
+	}
+	return st
+}
+
+// colType converts a tabular column type to a JSON schema type value
+func colType(t *tabular.ColType) interface{} {
+	if t == nil || len(*t) == 0 {
+		return nil
+	}
+	if len(*t) == 1 {
+		return (*t)[0]
+	}
+	types := make([]interface{}, len(*t))
+	for i, s := range *t {
+		types[i] = s
+	}
+	return types
+}
+
+// nullable adds "null" to a JSON schema type value. Pivoted cells are null
+// where a key has no value for a column
+func nullable(t interface{}) interface{} {
+	switch x := t.(type) {
+	case string:
+		if x == "null" {
+			return x
+		}
+		return []interface{}{x, "null"}
+	case []interface{}:
+		for _, s := range x {
+			if s == "null" {
+				return x
+			}
+		}
+		return append(x, "null")
+	}
+	return nil
+}
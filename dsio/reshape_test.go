@@ -0,0 +1,195 @@
+package dsio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// stringCSVReader reads csv data with a header row, typing all columns as
+// strings
+func stringCSVReader(t *testing.T, data string, titles ...string) EntryReader {
+	cols := []interface{}{}
+	for _, title := range titles {
+		cols = append(cols, map[string]interface{}{"title": title, "type": "string"})
+	}
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": cols},
+		},
+	}
+	r, err := NewCSVReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func readTitles(t *testing.T, st *dataset.Structure) []string {
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cols.Titles()
+}
+
+func TestTransposeReader(t *testing.T) {
+	data := "region,2019,2020\nnorth,1,2\nsouth,3,4\n"
+
+	r, err := NewTransposeReader(stringCSVReader(t, data, "region", "2019", "2020"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := []string{"region", "north", "south"}, readTitles(t, r.Structure()); !reflect.DeepEqual(expect, got) {
+		t.Errorf("titles mismatch. expected: %v, got: %v", expect, got)
+	}
+	if r.Structure().Entries != 2 {
+		t.Errorf("entries mismatch. expected: 2, got: %d", r.Structure().Entries)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		[]interface{}{"2019", "1", "3"},
+		[]interface{}{"2020", "2", "4"},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("body mismatch.\nexpected: %v\ngot:      %v", expect, got)
+	}
+
+	r, err = NewTransposeReader(jsonArrayReader(t, `[["a","b","c"],["d"]]`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := []string{"col_0", "col_1"}, readTitles(t, r.Structure()); !reflect.DeepEqual(expect, got) {
+		t.Errorf("titles mismatch. expected: %v, got: %v", expect, got)
+	}
+	got, err = readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = []interface{}{
+		[]interface{}{"a", "d"},
+		[]interface{}{"b", nil},
+		[]interface{}{"c", nil},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("body mismatch.\nexpected: %v\ngot:      %v", expect, got)
+	}
+}
+
+func TestTransposeReaderErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		body        string
+		labeled     bool
+		err         string
+	}{
+		{"object rows", `[{"a":"b"}]`, false, "transpose: entry 0 is not an array row"},
+		{"labeled without titles", `[["a"]]`, true, "transpose: invalid tabular schema: top level 'items' property must be an object"},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := NewTransposeReader(jsonArrayReader(t, c.body), c.labeled)
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestPivotReader(t *testing.T) {
+	sorted := "region,year,value,note\nnorth,2019,1,x\nnorth,2020,2,\nsouth,2020,4,\n"
+	unsorted := "region,year,value,note\nnorth,2019,1,x\nsouth,2020,4,\nnorth,2020,2,\n"
+
+	cases := []struct {
+		description string
+		data        string
+		pivot       Pivot
+		titles      []string
+		entries     int
+		expect      []interface{}
+	}{
+		{"discovered columns", unsorted, Pivot{Key: "region", Column: "year", Value: "value"},
+			[]string{"region", "2019", "2020"}, 2,
+			[]interface{}{
+				[]interface{}{"north", "1", "2"},
+				[]interface{}{"south", nil, "4"},
+			}},
+		{"listed columns", unsorted, Pivot{Key: "region", Column: "year", Value: "value", Columns: []string{"2020", "2019"}},
+			[]string{"region", "2020", "2019"}, 2,
+			[]interface{}{
+				[]interface{}{"north", "2", "1"},
+				[]interface{}{"south", "4", nil},
+			}},
+		{"streaming", sorted, Pivot{Key: "region", Column: "year", Value: "value", Columns: []string{"2019", "2020"}, Sorted: true},
+			[]string{"region", "2019", "2020"}, 0,
+			[]interface{}{
+				[]interface{}{"north", "1", "2"},
+				[]interface{}{"south", nil, "4"},
+			}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r, err := NewPivotReader(stringCSVReader(t, c.data, "region", "year", "value", "note"), c.pivot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readTitles(t, r.Structure()); !reflect.DeepEqual(c.titles, got) {
+				t.Errorf("titles mismatch. expected: %v, got: %v", c.titles, got)
+			}
+			if r.Structure().Entries != c.entries {
+				t.Errorf("entries mismatch. expected: %d, got: %d", c.entries, r.Structure().Entries)
+			}
+			got, err := readValues(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.expect, got) {
+				t.Errorf("body mismatch.\nexpected: %v\ngot:      %v", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestPivotReaderErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		data        string
+		pivot       Pivot
+		err         string
+	}{
+		{"missing column", "region,year,value\n", Pivot{Key: "area", Column: "year", Value: "value"}, `pivot: column "area" not found`},
+		{"duplicate listed column", "region,year,value\n", Pivot{Key: "region", Column: "year", Value: "value", Columns: []string{"a", "a"}}, `pivot: duplicate column "a"`},
+		{"duplicate value", "region,year,value\nnorth,2019,1\nnorth,2019,2\n", Pivot{Key: "region", Column: "year", Value: "value"}, `pivot: key "north": duplicate value for column "2019"`},
+		{"unexpected column", "region,year,value\nnorth,2021,1\n", Pivot{Key: "region", Column: "year", Value: "value", Columns: []string{"2019"}}, `pivot: entry 0: unexpected column "2021"`},
+		{"not adjacent", "region,year,value\nnorth,2019,1\nsouth,2019,2\nnorth,2020,3\n", Pivot{Key: "region", Column: "year", Value: "value", Sorted: true}, `pivot: entry 2: rows with key "north" are not adjacent`},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := NewPivotReader(stringCSVReader(t, c.data, "region", "year", "value"), c.pivot)
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+
+	// streaming readers report errors as entries are read
+	p := Pivot{Key: "region", Column: "year", Value: "value", Columns: []string{"2019", "2020"}, Sorted: true}
+	r, err := NewPivotReader(stringCSVReader(t, "region,year,value\nnorth,2019,1\nsouth,2019,2\nnorth,2020,3\n", "region", "year", "value"), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `error reading row 1: pivot: entry 2: rows with key "north" are not adjacent`
+	if _, err := readValues(r); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+}
@@ -0,0 +1,183 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset"
+)
+
+const (
+	// RowOpAdd inserts an entry before the row at Index. An Index equal to
+	// the number of rows in the body appends
+	RowOpAdd = "add"
+	// RowOpRemove deletes the row at Index
+	RowOpRemove = "remove"
+	// RowOpReplace swaps the row at Index for a new value
+	RowOpReplace = "replace"
+)
+
+// RowChange is a single row-level change to a body. Index is always the
+// position of a row in the body being patched, not the patched result
+type RowChange struct {
+	Op    string      `json:"op"`
+	Index int         `json:"index"`
+	Key   string      `json:"key,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	// Previous holds the row being removed or replaced, for reviewing changes.
+	// Patches don't need it to apply, and it isn't serialized
+	Previous interface{} `json:"-"`
+}
+
+// RowPatch is a list of row-level changes to a body, ordered by index. At
+// any index adds come first, followed by at most one remove or replace
+type RowPatch []RowChange
+
+// Validate checks a patch is well-ordered
+func (p RowPatch) Validate() error {
+	prev, prevMod := -1, false
+	for i, c := range p {
+		if c.Index < 0 {
+			return fmt.Errorf("row patch change %d: index must not be negative", i)
+		}
+		if c.Index < prev {
+			return fmt.Errorf("row patch change %d: changes must be ordered by index", i)
+		}
+		if c.Index != prev {
+			prevMod = false
+		}
+		switch c.Op {
+		case RowOpAdd:
+			if prevMod {
+				return fmt.Errorf("row patch change %d: adds must come before other changes to row %d", i, c.Index)
+			}
+		case RowOpRemove, RowOpReplace:
+			if prevMod {
+				return fmt.Errorf("row patch change %d: row %d is already changed", i, c.Index)
+			}
+			prevMod = true
+		default:
+			return fmt.Errorf("row patch change %d: unknown op %q", i, c.Op)
+		}
+		prev = c.Index
+	}
+	return nil
+}
+
+// Delta counts the changes a patch makes
+func (p RowPatch) Delta() *dataset.BodyDelta {
+	d := &dataset.BodyDelta{}
+	for _, c := range p {
+		switch c.Op {
+		case RowOpAdd:
+			d.Added++
+		case RowOpRemove:
+			d.Removed++
+		case RowOpReplace:
+			d.Changed++
+		}
+	}
+	return d
+}
+
+// appendIndex is an add index no row will reach, adding after the last row
+// regardless of body length
+const appendIndex = int(^uint(0) >> 1)
+
+// patchReader applies a row patch to entries as they're read
+type patchReader struct {
+	r       EntryReader
+	patch   RowPatch
+	next    int // index of the next change to apply
+	row     int // index of the next row to read from r
+	emitted int
+	done    bool
+}
+
+var _ EntryReader = (*patchReader)(nil)
+
+// NewPatchReader wraps a reader, applying patch to entries as they're read.
+// Unchanged entries are passed through without buffering the body
+func NewPatchReader(r EntryReader, patch RowPatch) (EntryReader, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	return &patchReader{r: r, patch: patch}, nil
+}
+
+// NewAppendReader wraps a reader, emitting entries after the last entry of r
+func NewAppendReader(r EntryReader, entries ...Entry) EntryReader {
+	patch := make(RowPatch, 0, len(entries))
+	for _, ent := range entries {
+		patch = append(patch, RowChange{Op: RowOpAdd, Index: appendIndex, Key: ent.Key, Value: ent.Value})
+	}
+	return &patchReader{r: r, patch: patch}
+}
+
+// Structure gives the structure of the wrapped reader
+func (r *patchReader) Structure() *dataset.Structure {
+	return r.r.Structure()
+}
+
+// ReadEntry reads the next entry of the patched body
+func (r *patchReader) ReadEntry() (Entry, error) {
+	for {
+		if r.next < len(r.patch) && r.patch[r.next].Index == r.row && r.patch[r.next].Op == RowOpAdd {
+			c := r.patch[r.next]
+			r.next++
+			return r.entry(c.Key, c.Value), nil
+		}
+
+		if r.done {
+			return r.flush()
+		}
+		ent, err := r.r.ReadEntry()
+		if err == io.EOF {
+			r.done = true
+			return r.flush()
+		} else if err != nil {
+			return ent, err
+		}
+		row := r.row
+		r.row++
+
+		if r.next < len(r.patch) && r.patch[r.next].Index == row {
+			c := r.patch[r.next]
+			r.next++
+			if c.Op == RowOpRemove {
+				continue
+			}
+			key := ent.Key
+			if c.Key != "" {
+				key = c.Key
+			}
+			return r.entry(key, c.Value), nil
+		}
+		return r.entry(ent.Key, ent.Value), nil
+	}
+}
+
+// flush emits changes that remain after the wrapped reader is exhausted. only
+// adds are allowed past the end of the body
+func (r *patchReader) flush() (Entry, error) {
+	if r.next == len(r.patch) {
+		return Entry{}, io.EOF
+	}
+	c := r.patch[r.next]
+	if c.Op != RowOpAdd || (c.Index != r.row && c.Index != appendIndex) {
+		return Entry{}, fmt.Errorf("row patch: index %d out of range for body with %d rows", c.Index, r.row)
+	}
+	r.next++
+	return r.entry(c.Key, c.Value), nil
+}
+
+func (r *patchReader) entry(key string, value interface{}) Entry {
+	ent := Entry{Index: r.emitted, Key: key, Value: value}
+	r.emitted++
+	return ent
+}
+
+// Close closes the wrapped reader
+func (r *patchReader) Close() error {
+	return r.r.Close()
+}
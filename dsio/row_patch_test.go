@@ -0,0 +1,114 @@
+package dsio
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func jsonArrayReader(t *testing.T, data string) EntryReader {
+	r, err := NewJSONReader(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func readValues(r EntryReader) ([]interface{}, error) {
+	vals := []interface{}{}
+	err := EachEntry(r, func(i int, ent Entry, err error) error {
+		if ent.Index != i {
+			return fmt.Errorf("entry %d has index %d", i, ent.Index)
+		}
+		vals = append(vals, ent.Value)
+		return nil
+	})
+	return vals, err
+}
+
+func TestRowPatchValidate(t *testing.T) {
+	cases := []struct {
+		description string
+		patch       RowPatch
+		err         string
+	}{
+		{"empty", RowPatch{}, ""},
+		{"ordered", RowPatch{{Op: RowOpAdd, Index: 0}, {Op: RowOpRemove, Index: 0}, {Op: RowOpReplace, Index: 2}}, ""},
+		{"negative", RowPatch{{Op: RowOpAdd, Index: -1}}, "row patch change 0: index must not be negative"},
+		{"unordered", RowPatch{{Op: RowOpRemove, Index: 2}, {Op: RowOpRemove, Index: 1}}, "row patch change 1: changes must be ordered by index"},
+		{"add after remove", RowPatch{{Op: RowOpRemove, Index: 1}, {Op: RowOpAdd, Index: 1}}, "row patch change 1: adds must come before other changes to row 1"},
+		{"double change", RowPatch{{Op: RowOpReplace, Index: 1}, {Op: RowOpRemove, Index: 1}}, "row patch change 1: row 1 is already changed"},
+		{"unknown op", RowPatch{{Op: "move", Index: 0}}, `row patch change 0: unknown op "move"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.patch.Validate()
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestPatchReader(t *testing.T) {
+	cases := []struct {
+		description string
+		body        string
+		patch       RowPatch
+		expect      []interface{}
+		err         string
+	}{
+		{"no changes", `["a","b","c"]`, RowPatch{}, []interface{}{"a", "b", "c"}, ""},
+		{"insert first", `["a","b"]`, RowPatch{{Op: RowOpAdd, Index: 0, Value: "x"}}, []interface{}{"x", "a", "b"}, ""},
+		{"append", `["a","b"]`, RowPatch{{Op: RowOpAdd, Index: 2, Value: "c"}}, []interface{}{"a", "b", "c"}, ""},
+		{"remove & replace", `["a","b","c"]`, RowPatch{{Op: RowOpRemove, Index: 0}, {Op: RowOpReplace, Index: 2, Value: "z"}}, []interface{}{"b", "z"}, ""},
+		{"insert & replace same row", `["a","b"]`, RowPatch{{Op: RowOpAdd, Index: 1, Value: "x"}, {Op: RowOpReplace, Index: 1, Value: "y"}}, []interface{}{"a", "x", "y"}, ""},
+		{"empty body", `[]`, RowPatch{{Op: RowOpAdd, Index: 0, Value: "a"}}, []interface{}{"a"}, ""},
+		{"remove out of range", `["a"]`, RowPatch{{Op: RowOpRemove, Index: 3}}, nil, "error reading row 1: row patch: index 3 out of range for body with 1 rows"},
+		{"add out of range", `["a"]`, RowPatch{{Op: RowOpAdd, Index: 3}}, nil, "error reading row 1: row patch: index 3 out of range for body with 1 rows"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r, err := NewPatchReader(jsonArrayReader(t, c.body), c.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := readValues(r)
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Fatalf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+			if c.err == "" && !reflect.DeepEqual(c.expect, got) {
+				t.Errorf("result mismatch. expected: %v, got: %v", c.expect, got)
+			}
+		})
+	}
+
+	if _, err := NewPatchReader(jsonArrayReader(t, `[]`), RowPatch{{Op: "nope"}}); err == nil {
+		t.Errorf("expected invalid patch to error")
+	}
+}
+
+func TestAppendReader(t *testing.T) {
+	r := NewAppendReader(jsonArrayReader(t, `["a","b"]`), Entry{Value: "c"}, Entry{Value: "d"})
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{"a", "b", "c", "d"}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("result mismatch. expected: %v, got: %v", expect, got)
+	}
+}
+
+func TestRowPatchDelta(t *testing.T) {
+	p := RowPatch{{Op: RowOpAdd}, {Op: RowOpAdd}, {Op: RowOpRemove, Index: 1}, {Op: RowOpReplace, Index: 2}}
+	expect := &dataset.BodyDelta{Added: 2, Removed: 1, Changed: 1}
+	if got := p.Delta(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("delta mismatch. expected: %v, got: %v", expect, got)
+	}
+}
@@ -21,8 +21,16 @@ func (r *PagedReader) Structure() *dataset.Structure {
 	return r.Reader.Structure()
 }
 
-// ReadEntry returns an entry, taking offset and limit into account
+// ReadEntry returns an entry, taking offset and limit into account. Readers
+// that implement EntrySkipper skip the offset without decoding entries
 func (r *PagedReader) ReadEntry() (Entry, error) {
+	if s, ok := r.Reader.(EntrySkipper); ok && r.Offset > 0 {
+		n, err := s.SkipEntries(r.Offset)
+		r.Offset -= n
+		if err != nil {
+			return Entry{}, err
+		}
+	}
 	for r.Offset > 0 {
 		_, err := r.Reader.ReadEntry()
 		if err != nil {
@@ -51,10 +59,10 @@ func Copy(reader EntryReader, writer EntryWriter) error {
 			if err == io.EOF {
 				break
 			}
-			return fmt.Errorf("row iteration error: %s", err.Error())
+			return fmt.Errorf("row iteration error: %w", err)
 		}
 		if err := writer.WriteEntry(val); err != nil {
-			return fmt.Errorf("error writing value to buffer: %s", err.Error())
+			return fmt.Errorf("error writing value to buffer: %w", err)
 		}
 	}
 	return nil
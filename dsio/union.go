@@ -0,0 +1,89 @@
+package dsio
+
+import (
+	"fmt"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// NewUnionReader reads the array rows of r through union, a structure
+// computed with dataset.UnionStructures. Rows are rearranged into the column
+// order of union, with null values for columns r doesn't have. Every column
+// of r must be a union column
+func NewUnionReader(r EntryReader, union *dataset.Structure) (EntryReader, error) {
+	if union == nil {
+		return nil, fmt.Errorf("union: structure is required")
+	}
+	ucols, _, err := tabular.ColumnsFromJSONSchema(union.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("union: %w", err)
+	}
+	index := map[string]int{}
+	for i, title := range ucols.Titles() {
+		index[title] = i
+	}
+
+	st := r.Structure()
+	if st == nil {
+		return nil, fmt.Errorf("union: source structure is required")
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("union: source: %w", err)
+	}
+	positions := make([]int, len(cols))
+	for i, title := range cols.Titles() {
+		pos, ok := index[title]
+		if !ok {
+			return nil, fmt.Errorf("union: column %q is not in the union structure", title)
+		}
+		positions[i] = pos
+	}
+	return &unionReader{r: r, st: union, positions: positions, width: len(ucols)}, nil
+}
+
+// unionReader maps rows into the columns of a union structure
+type unionReader struct {
+	r  EntryReader
+	st *dataset.Structure
+	// positions maps source column indexes to union column indexes
+	positions []int
+	width     int
+	read      int
+}
+
+var _ EntryReader = (*unionReader)(nil)
+
+// Structure gives the union structure
+func (r *unionReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next source row as a union row
+func (r *unionReader) ReadEntry() (Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	src, ok := ent.Value.([]interface{})
+	if !ok {
+		return ent, fmt.Errorf("union: entry %d is not an array row", i)
+	}
+	row := make([]interface{}, r.width)
+	for j, v := range src {
+		if j < len(r.positions) {
+			row[r.positions[j]] = v
+		}
+	}
+	ent.Value = row
+	return ent, nil
+}
+
+// Close closes the source reader
+func (r *unionReader) Close() error {
+	return r.r.Close()
+}
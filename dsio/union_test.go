@@ -0,0 +1,51 @@
+package dsio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestUnionReader(t *testing.T) {
+	v1 := stringCSVReader(t, "stop_id,name\n1,Hbf\n2,Nord\n", "stop_id", "name")
+	v2 := stringCSVReader(t, "name,zone,stop_id\nSüd,B,3\n", "name", "zone", "stop_id")
+	union, err := dataset.UnionStructures(v1.Structure(), v2.Structure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readTitles(t, union); !reflect.DeepEqual(got, []string{"stop_id", "name", "zone"}) {
+		t.Fatalf("union titles mismatch. got: %v", got)
+	}
+
+	expect := [][]interface{}{
+		{[]interface{}{"1", "Hbf", nil}, []interface{}{"2", "Nord", nil}},
+		{[]interface{}{"3", "Süd", "B"}},
+	}
+	for i, src := range []EntryReader{v1, v2} {
+		r, err := NewUnionReader(src, union)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Structure() != union {
+			t.Errorf("expected union reader to use the union structure")
+		}
+		got, err := readRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, row := range expect[i] {
+			if j >= len(got) || !reflect.DeepEqual(row, got[j]) {
+				t.Errorf("version %d row %d mismatch. expected: %v, got: %v", i, j, row, got)
+			}
+		}
+	}
+
+	narrow, err := dataset.UnionStructures(stringCSVReader(t, "", "stop_id").Structure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewUnionReader(stringCSVReader(t, "stop_id,name\n", "stop_id", "name"), narrow); err == nil || err.Error() != `union: column "name" is not in the union structure` {
+		t.Errorf("expected missing column error, got: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package dsio
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset"
+)
+
+// AppendVersion creates the next version of prev, whose body is the body of
+// prev followed by entries. body must read the body of prev. The returned
+// reader streams the new body, passing existing entries through unchanged.
+// The commit of the new version records the number of appended entries, and
+// must be given a title & timestamp before the dataset is saved. The entry
+// count of the new structure is carried over from prev, and set exactly once
+// the returned reader is read to the end
+func AppendVersion(prev *dataset.Dataset, body EntryReader, entries ...Entry) (*dataset.Dataset, EntryReader, error) {
+	ds, err := nextVersion(prev, body, &dataset.BodyDelta{Added: len(entries)})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds, countVersion(ds, NewAppendReader(body, entries...)), nil
+}
+
+// PatchVersion creates the next version of prev by applying a row-level patch
+// to the body of prev, which body must read. The returned reader streams the
+// new body. The commit of the new version records the counts of added,
+// removed & changed entries, and must be given a title & timestamp before the
+// dataset is saved. Entries are counted like AppendVersion counts them
+func PatchVersion(prev *dataset.Dataset, body EntryReader, patch RowPatch) (*dataset.Dataset, EntryReader, error) {
+	r, err := NewPatchReader(body, patch)
+	if err != nil {
+		return nil, nil, err
+	}
+	ds, err := nextVersion(prev, body, patch.Delta())
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds, countVersion(ds, r), nil
+}
+
+// nextVersion copies prev as the basis of a new version with a changed body
+func nextVersion(prev *dataset.Dataset, body EntryReader, delta *dataset.BodyDelta) (*dataset.Dataset, error) {
+	if prev == nil {
+		return nil, fmt.Errorf("previous version is required")
+	}
+	if body == nil {
+		return nil, fmt.Errorf("previous version body is required")
+	}
+
+	ds := prev.Clone()
+	ds.DropTransientValues()
+	ds.PreviousPath = prev.Path
+	ds.BodyPath = ""
+	ds.Commit = &dataset.Commit{BodyDelta: delta}
+
+	st := body.Structure()
+	if ds.Structure != nil && !ds.Structure.IsEmpty() {
+		st = ds.Structure
+	}
+	if st != nil {
+		entries := st.Entries
+		if entries == 0 && body.Structure() != nil {
+			entries = body.Structure().Entries
+		}
+		st = st.Clone()
+		st.Path = ""
+		st.Checksum = ""
+		st.Length = 0
+		st.ErrCount = 0
+		st.Entries = 0
+		if entries > 0 {
+			st.Entries = entries + delta.Added - delta.Removed
+		}
+		ds.Structure = st
+	}
+	return ds, nil
+}
+
+// countVersion wraps the body reader of a new version to set the entry count
+// of the version structure once the body is read to the end
+func countVersion(ds *dataset.Dataset, r EntryReader) EntryReader {
+	if ds.Structure == nil {
+		return r
+	}
+	return &versionCounter{EntryReader: r, st: ds.Structure}
+}
+
+// versionCounter counts the entries of a new version body
+type versionCounter struct {
+	EntryReader
+	st *dataset.Structure
+	n  int
+}
+
+// ReadEntry reads the next entry, setting the structure entry count at the
+// end of the body
+func (r *versionCounter) ReadEntry() (Entry, error) {
+	ent, err := r.EntryReader.ReadEntry()
+	if err == io.EOF {
+		r.st.Entries = r.n
+	} else if err == nil {
+		r.n++
+	}
+	return ent, err
+}
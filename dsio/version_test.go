@@ -0,0 +1,96 @@
+package dsio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestAppendVersion(t *testing.T) {
+	prev := &dataset.Dataset{
+		Path:     "/mem/QmPrev",
+		BodyPath: "/mem/QmBody",
+		Commit:   &dataset.Commit{Title: "initial commit"},
+		Meta:     &dataset.Meta{Title: "daily feed"},
+		Structure: &dataset.Structure{
+			Format:   "json",
+			Schema:   dataset.BaseSchemaArray,
+			Entries:  2,
+			Length:   9,
+			Checksum: "QmChecksum",
+		},
+	}
+
+	ds, r, err := AppendVersion(prev, jsonArrayReader(t, `["a","b"]`), Entry{Value: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.PreviousPath != "/mem/QmPrev" {
+		t.Errorf("previousPath mismatch. got: %q", ds.PreviousPath)
+	}
+	if ds.Path != "" || ds.BodyPath != "" {
+		t.Errorf("expected path & bodyPath to be cleared. got: %q, %q", ds.Path, ds.BodyPath)
+	}
+	if expect := (&dataset.BodyDelta{Added: 1}); !reflect.DeepEqual(expect, ds.Commit.BodyDelta) {
+		t.Errorf("commit delta mismatch. expected: %v, got: %v", expect, ds.Commit.BodyDelta)
+	}
+	if ds.Commit.Title != "" {
+		t.Errorf("expected a fresh commit. got title: %q", ds.Commit.Title)
+	}
+	if ds.Meta.Title != "daily feed" {
+		t.Errorf("expected meta to carry over. got: %q", ds.Meta.Title)
+	}
+	if ds.Structure.Entries != 3 || ds.Structure.Length != 0 || ds.Structure.Checksum != "" {
+		t.Errorf("structure mismatch. got entries: %d, length: %d, checksum: %q", ds.Structure.Entries, ds.Structure.Length, ds.Structure.Checksum)
+	}
+	if prev.Structure.Entries != 2 {
+		t.Errorf("expected previous version to be unmodified")
+	}
+
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []interface{}{"a", "b", "c"}; !reflect.DeepEqual(expect, got) {
+		t.Errorf("body mismatch. expected: %v, got: %v", expect, got)
+	}
+	if ds.Structure.Entries != 3 {
+		t.Errorf("expected 3 entries once the body is read, got: %d", ds.Structure.Entries)
+	}
+}
+
+func TestPatchVersion(t *testing.T) {
+	prev := &dataset.Dataset{Path: "/mem/QmPrev"}
+	patch := RowPatch{{Op: RowOpRemove, Index: 0}, {Op: RowOpReplace, Index: 1, Value: "z"}}
+	ds, r, err := PatchVersion(prev, jsonArrayReader(t, `["a","b"]`), patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (&dataset.BodyDelta{Removed: 1, Changed: 1}); !reflect.DeepEqual(expect, ds.Commit.BodyDelta) {
+		t.Errorf("commit delta mismatch. expected: %v, got: %v", expect, ds.Commit.BodyDelta)
+	}
+	if ds.Structure == nil || ds.Structure.Format != "json" {
+		t.Errorf("expected structure to be taken from the body reader. got: %v", ds.Structure)
+	}
+	got, err := readValues(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []interface{}{"z"}; !reflect.DeepEqual(expect, got) {
+		t.Errorf("body mismatch. expected: %v, got: %v", expect, got)
+	}
+	if ds.Structure.Entries != 1 {
+		t.Errorf("expected entries to be counted once the body is read, got: %d", ds.Structure.Entries)
+	}
+
+	if _, _, err := PatchVersion(prev, jsonArrayReader(t, `[]`), RowPatch{{Op: "nope"}}); err == nil {
+		t.Errorf("expected invalid patch to error")
+	}
+	if _, _, err := AppendVersion(nil, jsonArrayReader(t, `[]`)); err == nil || err.Error() != "previous version is required" {
+		t.Errorf("expected missing previous version error. got: %v", err)
+	}
+	if _, _, err := AppendVersion(prev, nil); err == nil || err.Error() != "previous version body is required" {
+		t.Errorf("expected missing body error. got: %v", err)
+	}
+}
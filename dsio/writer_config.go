@@ -0,0 +1,135 @@
+package dsio
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+// WriterConfig configures how writers format values. JSON & CSV writers read
+// float & datetime options, JSON & CBOR writers read key order options.
+// Other formats encode values as they are
+type WriterConfig struct {
+	// FloatPrecision is the number of digits written after the decimal point
+	// of floats. Negative values write the fewest digits that read back as
+	// the same float, the default
+	FloatPrecision int
+	// NoExponent writes floats in positional notation, never in scientific
+	// notation like 1e+21. CSV writers never write exponents
+	NoExponent bool
+	// TimeFormat is the go time layout datetimes are written in. defaults to
+	// RFC 3339, vals.DateTimeLayout
+	TimeFormat string
+	// TimeLocation is the zone datetimes are written in. nil keeps the zone
+	// of each value
+	TimeLocation *time.Location
+	// SchemaKeyOrder writes object keys in schema order, see
+	// WithSchemaKeyOrder
+	SchemaKeyOrder bool
+}
+
+// newWriterConfig applies writer options to the default configuration
+func newWriterConfig(opts ...func(*WriterConfig)) *WriterConfig {
+	cfg := &WriterConfig{FloatPrecision: -1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithFloatPrecision writes floats with n digits after the decimal point,
+// rounding values with more digits
+func WithFloatPrecision(n int) func(*WriterConfig) {
+	return func(c *WriterConfig) {
+		c.FloatPrecision = n
+	}
+}
+
+// WithoutExponent writes floats in positional notation
+func WithoutExponent() func(*WriterConfig) {
+	return func(c *WriterConfig) {
+		c.NoExponent = true
+	}
+}
+
+// WithTimeFormat sets the go time layout datetimes are written in, eg.
+// "2006-01-02 15:04:05"
+func WithTimeFormat(layout string) func(*WriterConfig) {
+	return func(c *WriterConfig) {
+		c.TimeFormat = layout
+	}
+}
+
+// WithTimeLocation converts datetimes to a zone before they're written
+func WithTimeLocation(loc *time.Location) func(*WriterConfig) {
+	return func(c *WriterConfig) {
+		c.TimeLocation = loc
+	}
+}
+
+// WithSchemaKeyOrder writes the keys of objects in entries in the order their
+// schema declares, instead of the sorted order of JSON writers or the
+// canonical order of CBOR writers. Keys listed by an object schema's
+// "propertyOrder" come first, then keys listed as "required", then any other
+// keys sorted. The order keys are declared in "properties" isn't kept:
+// schemas are decoded into maps, which drop it, so keys only listed in
+// "properties" are sorted just as they are without this option. List them in
+// "propertyOrder" to keep a declared order. CBOR writers also write the
+// entries of object bodies in the order they're written, like JSON writers do
+func WithSchemaKeyOrder() func(*WriterConfig) {
+	return func(c *WriterConfig) {
+		c.SchemaKeyOrder = true
+	}
+}
+
+// formatsJSON reports whether values need formatting before JSON encoding
+func (c *WriterConfig) formatsJSON() bool {
+	return c.FloatPrecision >= 0 || c.NoExponent || c.TimeFormat != "" || c.TimeLocation != nil
+}
+
+// formatFloat writes a float in positional notation
+func (c *WriterConfig) formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', c.FloatPrecision, 64)
+}
+
+// formatTime writes a datetime with the configured layout & zone
+func (c *WriterConfig) formatTime(t time.Time) string {
+	if c.TimeLocation != nil {
+		t = t.In(c.TimeLocation)
+	}
+	layout := c.TimeFormat
+	if layout == "" {
+		layout = vals.DateTimeLayout
+	}
+	return t.Format(layout)
+}
+
+// jsonValue copies a value, replacing floats & datetimes with their
+// formatted JSON encoding
+func (c *WriterConfig) jsonValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case float64:
+		if c.FloatPrecision >= 0 || c.NoExponent {
+			return json.Number(c.formatFloat(x))
+		}
+	case time.Time:
+		return c.formatTime(x)
+	case vals.DateTime:
+		return c.formatTime(x.Time())
+	case []interface{}:
+		cp := make([]interface{}, len(x))
+		for i, val := range x {
+			cp[i] = c.jsonValue(val)
+		}
+		return cp
+	case map[string]interface{}:
+		cp := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			cp[k] = c.jsonValue(val)
+		}
+		return cp
+	}
+	return v
+}
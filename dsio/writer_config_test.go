@@ -0,0 +1,77 @@
+package dsio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestWriterFormatting(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	ts := time.Date(2020, 3, 31, 13, 45, 0, 0, time.UTC)
+	row := []interface{}{1.0 / 3, 1e21, vals.NewDateTime(ts), ts, []interface{}{2.5e-7}}
+
+	jsonSt := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	csvSt := tabularStructure(
+		map[string]interface{}{"title": "third", "type": "number"},
+		map[string]interface{}{"title": "big", "type": "number"},
+		map[string]interface{}{"title": "datetime", "type": "string"},
+		map[string]interface{}{"title": "time", "type": "string"},
+		map[string]interface{}{"title": "list", "type": "array"},
+	)
+	csvSt.Format = "csv"
+
+	cases := []struct {
+		description string
+		opts        []func(*WriterConfig)
+		json, csv   string
+	}{
+		{"defaults", nil,
+			`[[0.3333333333333333,1e+21,"2020-03-31T13:45:00Z","2020-03-31T13:45:00Z",[2.5e-7]]]`,
+			"0.3333333333333333,1000000000000000000000,2020-03-31T13:45:00Z,2020-03-31T13:45:00Z,[2.5e-7]\n"},
+		{"precision", []func(*WriterConfig){WithFloatPrecision(2)},
+			`[[0.33,1000000000000000000000.00,"2020-03-31T13:45:00Z","2020-03-31T13:45:00Z",[0.00]]]`,
+			"0.33,1000000000000000000000.00,2020-03-31T13:45:00Z,2020-03-31T13:45:00Z,[0.00]\n"},
+		{"no exponent", []func(*WriterConfig){WithoutExponent()},
+			`[[0.3333333333333333,1000000000000000000000,"2020-03-31T13:45:00Z","2020-03-31T13:45:00Z",[0.00000025]]]`,
+			"0.3333333333333333,1000000000000000000000,2020-03-31T13:45:00Z,2020-03-31T13:45:00Z,[0.00000025]\n"},
+		{"time format", []func(*WriterConfig){WithTimeFormat("02.01.2006 15:04"), WithTimeLocation(berlin)},
+			`[[0.3333333333333333,1e+21,"31.03.2020 15:45","31.03.2020 15:45",[2.5e-7]]]`,
+			"0.3333333333333333,1000000000000000000000,31.03.2020 15:45,31.03.2020 15:45,[2.5e-7]\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			for _, f := range []struct {
+				st     *dataset.Structure
+				expect string
+			}{{jsonSt, c.json}, {csvSt, c.csv}} {
+				buf := &bytes.Buffer{}
+				w, err := NewEntryWriter(f.st, buf, c.opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := w.WriteEntry(Entry{Value: row}); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if got := buf.String(); got != f.expect {
+					t.Errorf("%s mismatch.\nexpected: %s\ngot:      %s", f.st.Format, f.expect, got)
+				}
+			}
+		})
+	}
+
+	if expect := []interface{}{2.5e-7}; !reflect.DeepEqual(expect, row[4]) {
+		t.Errorf("writing modified the entry, got: %v", row[4])
+	}
+}
@@ -198,7 +198,7 @@ func (w *XLSXWriter) WriteEntry(ent Entry) error {
 		strs, err := encodeStrings(arr)
 		if err != nil {
 			log.Debug(err.Error())
-			return fmt.Errorf("error encoding entry: %s", err.Error())
+			return fmt.Errorf("error encoding entry: %w", err)
 		}
 		for i, str := range strs {
 			w.f.SetCellValue(w.sheetName, w.axis(i), str)
@@ -206,7 +206,7 @@ func (w *XLSXWriter) WriteEntry(ent Entry) error {
 		w.rowsWritten++
 		return nil
 	}
-	return fmt.Errorf("expected array value to write xlsx row. got: %v", ent)
+	return fmt.Errorf("%w: expected array value to write xlsx row. got: %v", dataset.ErrSchemaMismatch, ent)
 }
 
 func (w *XLSXWriter) axis(colIDx int) string {
@@ -287,12 +287,12 @@ func formatColumnName(colID []int) string {
 			// range 0-25, all other numbers are 1-26,
 			// hence we use a differente offset for the
 			// last part.
-			result += string(part + 65)
+			result += string(rune(part + 65))
 		} else {
 			// Don't output leading 0s, as there is no
 			// representation of 0 in this format.
 			if part > 0 {
-				result += string(part + 64)
+				result += string(rune(part + 64))
 			}
 		}
 	}
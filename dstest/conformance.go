@@ -0,0 +1,177 @@
+package dstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+)
+
+// ConformanceSuitePath is the location of the conformance suite shipped with
+// this package, relative to the dstest package directory
+const ConformanceSuitePath = "testdata/conformance.json"
+
+// ConformanceSuite is a set of golden test vectors that pin down how datasets
+// serialize. Each vector pairs an input document with the canonical encoding
+// and hash this package produces for it. The suite is plain JSON so other
+// implementations can check byte-for-byte compatibility without Go
+type ConformanceSuite struct {
+	// Version of the dataset spec the vectors were generated against
+	Version string `json:"version"`
+	// Vectors is the list of test vectors
+	Vectors []ConformanceVector `json:"vectors"`
+}
+
+// ConformanceVector is a single golden test case
+type ConformanceVector struct {
+	// Name uniquely identifies the vector within a suite
+	Name string `json:"name"`
+	// Description explains what the vector exercises
+	Description string `json:"description,omitempty"`
+	// Input is a dataset document, as it would be read from disk
+	Input json.RawMessage `json:"input"`
+	// Canonical is the encoding of Input after decoding it as a dataset
+	Canonical string `json:"canonical"`
+	// Hash is the dataset.HashBytes checksum of Canonical
+	Hash string `json:"hash"`
+	// Components maps component names to their individual encodings
+	Components map[string]ComponentEncoding `json:"components,omitempty"`
+}
+
+// ComponentEncoding is the canonical encoding & hash of a single component
+type ComponentEncoding struct {
+	Canonical string `json:"canonical"`
+	Hash      string `json:"hash"`
+}
+
+// LoadConformanceSuite reads a conformance suite from a JSON file
+func LoadConformanceSuite(path string) (*ConformanceSuite, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &ConformanceSuite{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decoding conformance suite: %w", err)
+	}
+	return s, nil
+}
+
+// EncodeVector builds a conformance vector by encoding input with this
+// package. It's used to check vectors, and to generate new ones
+func EncodeVector(name, description string, input []byte) (ConformanceVector, error) {
+	v := ConformanceVector{
+		Name:        name,
+		Description: description,
+		Input:       json.RawMessage(input),
+	}
+
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(input, ds); err != nil {
+		return v, err
+	}
+	enc, err := encodeComponent(ds)
+	if err != nil {
+		return v, err
+	}
+	v.Canonical, v.Hash = enc.Canonical, enc.Hash
+
+	components := map[string]json.Marshaler{}
+	if ds.Commit != nil {
+		components["commit"] = ds.Commit
+	}
+	if ds.Meta != nil {
+		components["meta"] = ds.Meta
+	}
+	if ds.Provenance != nil {
+		components["provenance"] = ds.Provenance
+	}
+	if ds.Readme != nil {
+		components["readme"] = ds.Readme
+	}
+	if ds.Structure != nil {
+		components["structure"] = ds.Structure
+	}
+	if ds.Transform != nil {
+		components["transform"] = ds.Transform
+	}
+	if ds.Viz != nil {
+		components["viz"] = ds.Viz
+	}
+	for name, c := range components {
+		enc, err := encodeComponent(c)
+		if err != nil {
+			return v, fmt.Errorf("%s: %w", name, err)
+		}
+		if v.Components == nil {
+			v.Components = map[string]ComponentEncoding{}
+		}
+		v.Components[name] = enc
+	}
+	return v, nil
+}
+
+func encodeComponent(m json.Marshaler) (ComponentEncoding, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return ComponentEncoding{}, err
+	}
+	hash, err := dataset.HashBytes(data)
+	if err != nil {
+		return ComponentEncoding{}, err
+	}
+	return ComponentEncoding{Canonical: string(data), Hash: hash}, nil
+}
+
+// Check re-encodes the vector input & compares the result with the expected
+// encodings, returning the first mismatch
+func (v ConformanceVector) Check() error {
+	got, err := EncodeVector(v.Name, v.Description, v.Input)
+	if err != nil {
+		return fmt.Errorf("vector %q: %w", v.Name, err)
+	}
+	if got.Canonical != v.Canonical {
+		return fmt.Errorf("vector %q: canonical encoding mismatch. expected: %s, got: %s", v.Name, v.Canonical, got.Canonical)
+	}
+	if got.Hash != v.Hash {
+		return fmt.Errorf("vector %q: hash mismatch. expected: %s, got: %s", v.Name, v.Hash, got.Hash)
+	}
+	if len(got.Components) != len(v.Components) {
+		return fmt.Errorf("vector %q: expected %d components, got %d", v.Name, len(v.Components), len(got.Components))
+	}
+	for name, expect := range v.Components {
+		c, ok := got.Components[name]
+		if !ok {
+			return fmt.Errorf("vector %q: missing component %s", v.Name, name)
+		}
+		if c.Canonical != expect.Canonical {
+			return fmt.Errorf("vector %q: %s encoding mismatch. expected: %s, got: %s", v.Name, name, expect.Canonical, c.Canonical)
+		}
+		if c.Hash != expect.Hash {
+			return fmt.Errorf("vector %q: %s hash mismatch. expected: %s, got: %s", v.Name, name, expect.Hash, c.Hash)
+		}
+	}
+	return nil
+}
+
+// Run checks every vector in the suite, returning one error per failing
+// vector. A nil result means this package conforms to the suite
+func (s *ConformanceSuite) Run() []error {
+	var errs []error
+	if s.Version != dataset.CurrentSpecVersion {
+		errs = append(errs, fmt.Errorf("suite is for spec version %q, current version is %q", s.Version, dataset.CurrentSpecVersion))
+	}
+	names := map[string]bool{}
+	for _, v := range s.Vectors {
+		if names[v.Name] {
+			errs = append(errs, fmt.Errorf("duplicate vector name %q", v.Name))
+			continue
+		}
+		names[v.Name] = true
+		if err := v.Check(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,78 @@
+package dstest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConformanceSuite(t *testing.T) {
+	s, err := LoadConformanceSuite(ConformanceSuitePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Vectors) == 0 {
+		t.Fatal("expected conformance suite to have vectors")
+	}
+	for _, err := range s.Run() {
+		t.Error(err)
+	}
+}
+
+func TestConformanceVectorCheck(t *testing.T) {
+	v, err := EncodeVector("meta", "", []byte(`{"meta":{"title":"a"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Check(); err != nil {
+		t.Errorf("expected freshly encoded vector to pass. got: %s", err)
+	}
+
+	cases := []struct {
+		description string
+		mutate      func(v *ConformanceVector)
+		err         string
+	}{
+		{"bad input", func(v *ConformanceVector) { v.Input = json.RawMessage(`[]`) }, `vector "meta": unmarshaling dataset: json: cannot unmarshal array into Go value of type dataset._dataset`},
+		{"canonical", func(v *ConformanceVector) { v.Canonical = "{}" }, `vector "meta": canonical encoding mismatch. expected: {}, got: {"meta":{"qri":"md:0","title":"a"},"qri":"ds:0"}`},
+		{"hash", func(v *ConformanceVector) { v.Hash = "Qm" }, `vector "meta": hash mismatch. expected: Qm, got: ` + v.Hash},
+		{"missing component", func(v *ConformanceVector) {
+			v.Components = map[string]ComponentEncoding{"commit": {}}
+		}, `vector "meta": missing component commit`},
+		{"component hash", func(v *ConformanceVector) {
+			v.Components = map[string]ComponentEncoding{"meta": {Canonical: v.Components["meta"].Canonical, Hash: "Qm"}}
+		}, `vector "meta": meta hash mismatch. expected: Qm, got: ` + v.Components["meta"].Hash},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got := v
+			got.Components = map[string]ComponentEncoding{}
+			for k, enc := range v.Components {
+				got.Components[k] = enc
+			}
+			c.mutate(&got)
+			err := got.Check()
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestConformanceSuiteRun(t *testing.T) {
+	v, err := EncodeVector("a", "", []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ConformanceSuite{Version: "99", Vectors: []ConformanceVector{v, v}}
+	errs := s.Run()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got: %v", errs)
+	}
+	if errs[0].Error() != `suite is for spec version "99", current version is "0"` {
+		t.Errorf("version error mismatch. got: %s", errs[0])
+	}
+	if errs[1].Error() != `duplicate vector name "a"` {
+		t.Errorf("duplicate error mismatch. got: %s", errs[1])
+	}
+}
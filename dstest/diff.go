@@ -0,0 +1,120 @@
+package dstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset"
+)
+
+// EqualDatasets compares the encoded form of two datasets, returning an error
+// listing each difference by field path if they don't match, one per line,
+// eg. `meta.title: expected "a", got "b"`. Fields are compared as they
+// serialize, so unexported & transient values that aren't encoded, like body
+// files, are ignored
+func EqualDatasets(expect, got *dataset.Dataset) error {
+	a, err := encodedValue(expect)
+	if err != nil {
+		return fmt.Errorf("encoding expected dataset: %s", err)
+	}
+	b, err := encodedValue(got)
+	if err != nil {
+		return fmt.Errorf("encoding dataset: %s", err)
+	}
+
+	diffs := diffValues("", a, b, nil)
+	if len(diffs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("datasets differ:\n  %s", strings.Join(diffs, "\n  "))
+}
+
+// encodedValue round-trips a dataset through JSON into generic go types
+func encodedValue(ds *dataset.Dataset) (interface{}, error) {
+	if ds == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(ds)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// diffValues appends a line for each difference between a & b
+func diffValues(path string, a, b interface{}, diffs []string) []string {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for key := range at {
+			keys[key] = true
+		}
+		for key := range bt {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			diffs = diffValues(joinPath(path, key), at[key], bt[key], diffs)
+		}
+		return diffs
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(at) || i < len(bt); i++ {
+			var ae, be interface{}
+			if i < len(at) {
+				ae = at[i]
+			}
+			if i < len(bt) {
+				be = bt[i]
+			}
+			diffs = diffValues(joinPath(path, fmt.Sprintf("%d", i)), ae, be, diffs)
+		}
+		return diffs
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		if path == "" {
+			path = "dataset"
+		}
+		diffs = append(diffs, fmt.Sprintf("%s: expected %s, got %s", path, describe(a), describe(b)))
+	}
+	return diffs
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// describe gives a short readable form of a value
+func describe(v interface{}) string {
+	if v == nil {
+		return "<nil>"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	if len(data) > 80 {
+		return string(data[:77]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,36 @@
+package dstest
+
+import (
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestEqualDatasets(t *testing.T) {
+	a := Cities()
+	b := Cities()
+	b.Meta.Title = "changed"
+	b.Meta.Keywords = []string{"cities"}
+	b.Structure.Entries = 6
+
+	cases := []struct {
+		a, b *dataset.Dataset
+		err  string
+	}{
+		{nil, nil, ""},
+		{Cities(), Cities(), ""},
+		{a, b, `datasets differ:
+  meta.keywords.1: expected "population", got <nil>
+  meta.title: expected "example city data", got "changed"
+  structure.entries: expected 5, got 6`},
+		{nil, dataset.NewDatasetRef("/mem/a"), `datasets differ:
+  dataset: expected <nil>, got "/mem/a"`},
+	}
+
+	for i, c := range cases {
+		err := EqualDatasets(c.a, c.b)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected:\n%s\ngot:\n%v", i, c.err, err)
+		}
+	}
+}
@@ -1,5 +1,13 @@
 // Package dstest defines an interface for reading test cases from static files
-// leveraging directories of test dataset input files & expected output files
+// leveraging directories of test dataset input files & expected output files.
+// For tests that don't need files on disk, dstest also provides in-memory
+// fixture datasets, a MemStore to resolve them by path, and EqualDatasets for
+// comparing datasets with readable diffs.
+//
+// testdata/conformance.json is a suite of golden vectors: dataset documents
+// with the canonical encodings & hashes this package produces for them.
+// LoadConformanceSuite and ConformanceSuite.Run check an implementation
+// against it; implementations in other languages can read the file directly
 package dstest
 
 import (
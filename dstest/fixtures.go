@@ -0,0 +1,94 @@
+package dstest
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+// FixtureTimestamp is the commit timestamp of all fixture datasets, fixed so
+// fixtures encode & hash consistently
+var FixtureTimestamp = time.Date(2019, time.March, 31, 12, 0, 0, 0, time.UTC)
+
+// citiesBody is the CSV body of the Cities fixture
+const citiesBody = `city,pop,avg_age,in_usa
+toronto,40000000,55.5,false
+new york,8500000,44.4,true
+chicago,300000,44.4,true
+chatham,35000,65.25,true
+raleigh,250000,50.65,true
+`
+
+// Cities gives a complete tabular dataset fixture, with commit, meta &
+// structure components and a CSV body held in memory. Each call returns a
+// new instance that's safe to modify
+func Cities() *dataset.Dataset {
+	return &dataset.Dataset{
+		Qri: dataset.KindDataset.String(),
+		Commit: &dataset.Commit{
+			Qri:       dataset.KindCommit.String(),
+			Timestamp: FixtureTimestamp,
+			Title:     "initial commit",
+		},
+		Meta: &dataset.Meta{
+			Qri:      dataset.KindMeta.String(),
+			Title:    "example city data",
+			Keywords: []string{"cities", "population"},
+		},
+		Structure: &dataset.Structure{
+			Qri:          dataset.KindStructure.String(),
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Entries:      5,
+			Length:       len(citiesBody),
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "city", "type": "string"},
+						map[string]interface{}{"title": "pop", "type": "integer"},
+						map[string]interface{}{"title": "avg_age", "type": "number"},
+						map[string]interface{}{"title": "in_usa", "type": "boolean"},
+					},
+				},
+			},
+		},
+		BodyBytes: []byte(citiesBody),
+	}
+}
+
+// NewDataset builds a dataset fixture with a JSON array body of rows and a
+// commit, passing the result through any configuration functions. rows must
+// be JSON-encodable
+func NewDataset(rows []interface{}, configs ...func(ds *dataset.Dataset)) *dataset.Dataset {
+	if rows == nil {
+		rows = []interface{}{}
+	}
+	body, err := json.Marshal(rows)
+	if err != nil {
+		panic(err)
+	}
+
+	ds := &dataset.Dataset{
+		Qri: dataset.KindDataset.String(),
+		Commit: &dataset.Commit{
+			Qri:       dataset.KindCommit.String(),
+			Timestamp: FixtureTimestamp,
+			Title:     "initial commit",
+		},
+		Structure: &dataset.Structure{
+			Qri:     dataset.KindStructure.String(),
+			Format:  "json",
+			Entries: len(rows),
+			Length:  len(body),
+			Schema:  map[string]interface{}{"type": "array"},
+		},
+		BodyBytes: body,
+	}
+	for _, config := range configs {
+		config(ds)
+	}
+	return ds
+}
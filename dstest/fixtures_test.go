@@ -0,0 +1,52 @@
+package dstest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestCities(t *testing.T) {
+	ds := Cities()
+	if err := ds.Validate(); err != nil {
+		t.Fatalf("expected fixture to be valid: %s", err)
+	}
+	if Cities() == ds {
+		t.Errorf("expected each call to return a new instance")
+	}
+
+	r, err := dsio.NewEntryReader(ds.Structure, bytes.NewReader(ds.BodyBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := 0
+	if err := dsio.EachEntry(r, func(int, dsio.Entry, error) error {
+		entries++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if entries != ds.Structure.Entries {
+		t.Errorf("entries mismatch. structure: %d, body: %d", ds.Structure.Entries, entries)
+	}
+}
+
+func TestNewDataset(t *testing.T) {
+	ds := NewDataset([]interface{}{"a", 1}, func(ds *dataset.Dataset) {
+		ds.Meta = &dataset.Meta{Title: "configured"}
+	})
+	if string(ds.BodyBytes) != `["a",1]` {
+		t.Errorf("body mismatch. got: %s", ds.BodyBytes)
+	}
+	if ds.Structure.Entries != 2 {
+		t.Errorf("expected 2 entries, got: %d", ds.Structure.Entries)
+	}
+	if ds.Meta == nil || ds.Meta.Title != "configured" {
+		t.Errorf("expected configuration to apply")
+	}
+	if string(NewDataset(nil).BodyBytes) != `[]` {
+		t.Errorf("expected nil rows to encode as an empty array")
+	}
+}
@@ -0,0 +1,79 @@
+package dstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+// MemStore is an in-memory dataset store for tests that need to resolve
+// dataset paths, such as following PreviousPath or loading transform
+// resources. Stored datasets are copied in & out, so callers can't modify
+// the contents of the store by accident
+type MemStore struct {
+	datasets map[string][]byte
+}
+
+// NewMemStore creates a store pre-populated with datasets. Datasets without a
+// path are assigned one, see Put
+func NewMemStore(dss ...*dataset.Dataset) (*MemStore, error) {
+	s := &MemStore{datasets: map[string][]byte{}}
+	for _, ds := range dss {
+		if _, err := s.Put(context.Background(), ds); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Put adds a dataset to the store, returning it's path. Datasets without a
+// path are stored at "/mem/" followed by the dataset checksum, and have their
+// Path field set
+func (s *MemStore) Put(ctx context.Context, ds *dataset.Dataset) (string, error) {
+	if ds == nil {
+		return "", fmt.Errorf("dataset is required")
+	}
+	if ds.Path == "" {
+		ds.Path = "/mem/" + DatasetChecksum(ds)
+	}
+	data, err := json.Marshal(ds)
+	if err != nil {
+		return "", err
+	}
+	s.datasets[ds.Path] = data
+	return ds.Path, nil
+}
+
+// Load fetches a copy of the dataset stored at path. Load satisfies the
+// dataset.DatasetLoader function signature
+func (s *MemStore) Load(ctx context.Context, path string) (*dataset.Dataset, error) {
+	data, ok := s.datasets[path]
+	if !ok {
+		return nil, dataset.ErrNotFound
+	}
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(data, ds); err != nil {
+		return nil, err
+	}
+	ds.Path = path
+	return ds, nil
+}
+
+// Paths lists the paths of all stored datasets in sorted order
+func (s *MemStore) Paths() []string {
+	paths := make([]string, 0, len(s.datasets))
+	for path := range s.datasets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Resolver gives a link resolver backed by the store
+func (s *MemStore) Resolver() vals.Resolver {
+	return dataset.LinkResolver(s.Load)
+}
@@ -0,0 +1,61 @@
+package dstest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestMemStore(t *testing.T) {
+	ctx := context.Background()
+	named := NewDataset(nil)
+	named.Path = "/mem/named"
+
+	s, err := NewMemStore(Cities(), named)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := s.Paths()
+	if len(paths) != 2 || paths[1] != "/mem/named" || !strings.HasPrefix(paths[0], "/mem/") {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+
+	got, err := s.Load(ctx, paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := Cities()
+	expect.Path = paths[0]
+	if err := EqualDatasets(expect, got); err != nil {
+		t.Error(err)
+	}
+
+	got.Meta.Title = "changed"
+	again, _ := s.Load(ctx, paths[0])
+	if again.Meta.Title == "changed" {
+		t.Errorf("expected loaded datasets to be copies")
+	}
+
+	if _, err := s.Load(ctx, "/mem/missing"); err == nil || err.Error() != "path not found" {
+		t.Errorf("expected path not found error, got: %v", err)
+	}
+	if _, err := s.Put(ctx, nil); err == nil {
+		t.Errorf("expected error putting nil dataset")
+	}
+
+	var load dataset.DatasetLoader = s.Load
+	body := []interface{}{vals.NewLink(paths[0] + "#/meta/title")}
+	title, err := vals.GetLinked(ctx, body, "/0", s.Resolver())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "example city data" {
+		t.Errorf("link resolution mismatch. got: %v", title)
+	}
+	if _, err := load(ctx, "/mem/named"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,105 @@
+package dsutil
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/multiformats/go-multihash"
+)
+
+// ManifestFileName is the name of the manifest in a bundle
+const ManifestFileName = "manifest.json"
+
+// Manifest lists the files of a dataset bundle with their sizes & hashes
+// so bundles can be checked for completeness & corruption after transfer
+type Manifest struct {
+	Files []*ManifestFile `json:"files"`
+}
+
+// ManifestFile describes one file in a bundle
+type ManifestFile struct {
+	// Component is the dataset component stored in the file, eg. "dataset"
+	// or "body"
+	Component string `json:"component"`
+	// Hash is the base58-encoded SHA-256 multihash of the file, computed the
+	// same way as dataset.HashBytes
+	Hash string `json:"hash"`
+	// Name is the path of the file within the bundle
+	Name string `json:"name"`
+	// Size of the file in bytes
+	Size int64 `json:"size"`
+}
+
+// Add hashes the contents of r, recording it as a file in the manifest
+func (m *Manifest) Add(component, name string, r io.Reader) error {
+	hash, size, err := hashReader(r)
+	if err != nil {
+		return fmt.Errorf("hashing %s: %w", name, err)
+	}
+	m.Files = append(m.Files, &ManifestFile{Component: component, Hash: hash, Name: name, Size: size})
+	return nil
+}
+
+// File gets a manifest file by name, nil if the manifest has no such file
+func (m *Manifest) File(name string) *ManifestFile {
+	for _, f := range m.Files {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Verify checks that every file in the manifest can be opened & matches
+// its recorded size & hash, returning the first mismatch
+func (m *Manifest) Verify(open func(name string) (io.ReadCloser, error)) error {
+	if len(m.Files) == 0 {
+		return fmt.Errorf("manifest lists no files")
+	}
+	for _, f := range m.Files {
+		rc, err := open(f.Name)
+		if err != nil {
+			return fmt.Errorf("file %q: %w", f.Name, err)
+		}
+		hash, size, err := hashReader(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("file %q: %w", f.Name, err)
+		}
+		if size != f.Size {
+			return fmt.Errorf("file %q: size mismatch. expected %d bytes, got %d", f.Name, f.Size, size)
+		}
+		if hash != f.Hash {
+			return fmt.Errorf("file %q: hash mismatch. expected %s, got %s", f.Name, f.Hash, hash)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes a manifest with files sorted by name, so the same set
+// of files always produces the same manifest
+func (m *Manifest) MarshalJSON() ([]byte, error) {
+	files := append([]*ManifestFile{}, m.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return json.Marshal(struct {
+		Files []*ManifestFile `json:"files"`
+	}{files})
+}
+
+// hashReader gives the size & multihash of the contents of r
+func hashReader(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	mhBuf, err := multihash.Encode(h.Sum(nil), multihash.SHA2_256)
+	if err != nil {
+		return "", 0, fmt.Errorf("error allocating multihash buffer: %s", err.Error())
+	}
+	return base58.Encode(mhBuf), size, nil
+}
@@ -0,0 +1,67 @@
+package dsutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestManifest(t *testing.T) {
+	mf := &Manifest{}
+	if err := mf.Add("body", "body.csv", strings.NewReader("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mf.Add("dataset", "dataset.json", strings.NewReader("{}")); err != nil {
+		t.Fatal(err)
+	}
+	expectHash, _ := dataset.HashBytes([]byte("a,b\n"))
+	if f := mf.File("body.csv"); f == nil || f.Hash != expectHash || f.Size != 4 {
+		t.Errorf("expected manifest hashes to match dataset.HashBytes, got: %#v", f)
+	}
+
+	data, err := json.Marshal(mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mf.Files = []*ManifestFile{mf.Files[1], mf.Files[0]}
+	if again, _ := json.Marshal(mf); !bytes.Equal(data, again) {
+		t.Errorf("expected manifest encoding to be independent of file order")
+	}
+
+	files := map[string]string{"body.csv": "a,b\n", "dataset.json": "{}"}
+	open := func(name string) (io.ReadCloser, error) {
+		s, ok := files[name]
+		if !ok {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+	if err := mf.Verify(open); err != nil {
+		t.Errorf("unexpected verification error: %s", err)
+	}
+
+	cases := []struct {
+		files map[string]string
+		err   string
+	}{
+		{map[string]string{"body.csv": "a,b\n"}, `file "dataset.json": unexpected EOF`},
+		{map[string]string{"body.csv": "a,b,c\n", "dataset.json": "{}"}, `file "body.csv": size mismatch. expected 4 bytes, got 6`},
+		{map[string]string{"body.csv": "a;b\n", "dataset.json": "{}"}, `file "body.csv": hash mismatch. expected ` + expectHash + `, got `},
+	}
+	for i, c := range cases {
+		files = c.files
+		err := mf.Verify(open)
+		if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+
+	if err := (&Manifest{}).Verify(open); err == nil || err.Error() != "manifest lists no files" {
+		t.Errorf("expected empty manifest error, got: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package dsutil includes dataset util funcs, placed here to avoid dataset
+// package bloat
+package dsutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+)
+
+// ZipConfig configures zip exports
+type ZipConfig struct {
+	// AccessLevel is the level archives are shared at, defaults to
+	// dataset.AccessPublic. Datasets with a more restrictive access level
+	// aren't written
+	AccessLevel string
+}
+
+// WithAccessLevel sets the access level archives are shared at, eg.
+// dataset.AccessInternal for exports that stay within an organization
+func WithAccessLevel(level string) func(*ZipConfig) {
+	return func(c *ZipConfig) {
+		c.AccessLevel = level
+	}
+}
+
+// WriteZip writes a dataset to a zip archive as a dataset.json document, a
+// body file & a manifest of both. The body is read from BodyBytes or an
+// open body file. Inline bodies stay in the dataset document. Datasets that
+// may not be shared at the configured access level are an error wrapping
+// dataset.ErrAccessDenied, and nothing is written
+func WriteZip(ds *dataset.Dataset, w io.Writer, opts ...func(*ZipConfig)) error {
+	cfg := &ZipConfig{AccessLevel: dataset.AccessPublic}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if ds == nil {
+		return fmt.Errorf("dataset is required")
+	}
+	if err := ds.CheckAccess(cfg.AccessLevel); err != nil {
+		return err
+	}
+	doc := ds.Clone()
+	doc.BodyBytes = nil
+
+	var body []byte
+	if ds.BodyBytes != nil {
+		body = ds.BodyBytes
+	} else if f := ds.BodyFile(); f != nil {
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("reading body: %w", err)
+		}
+		body = data
+	}
+
+	zw := zip.NewWriter(w)
+	mf := &Manifest{}
+	write := func(component, name string, data []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+		return mf.Add(component, name, bytes.NewReader(data))
+	}
+
+	if body != nil {
+		name := "body"
+		if doc.Structure != nil && doc.Structure.Format != "" {
+			name += "." + doc.Structure.Format
+		}
+		doc.BodyPath = name
+		if err := write("body", name, body); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := write("dataset", "dataset.json", data); err != nil {
+		return err
+	}
+
+	fw, err := zw.Create(ManifestFileName)
+	if err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(mf, "", "  "); err != nil {
+		return err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// VerifyZip checks every file of a zip archive written by WriteZip against
+// the archive manifest, returning the manifest. Archives with files missing
+// from the manifest or more than one file of the same name fail verification
+func VerifyZip(r io.ReaderAt, size int64) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files, err := zipFiles(zr)
+	if err != nil {
+		return nil, err
+	}
+	return verifyZip(files)
+}
+
+// zipFiles indexes the files of an archive by name. Archives can hold more
+// than one file of a name, which would let a file other than the verified
+// one be read, so duplicate names are an error
+func zipFiles(zr *zip.Reader) (map[string]*zip.File, error) {
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		if _, ok := files[f.Name]; ok {
+			return nil, fmt.Errorf("archive has more than one file named %q", f.Name)
+		}
+		files[f.Name] = f
+	}
+	return files, nil
+}
+
+func verifyZip(files map[string]*zip.File) (*Manifest, error) {
+	mzf, ok := files[ManifestFileName]
+	if !ok {
+		return nil, fmt.Errorf("archive has no %s", ManifestFileName)
+	}
+	rc, err := mzf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	mf := &Manifest{}
+	if err := json.NewDecoder(rc).Decode(mf); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ManifestFileName, err)
+	}
+
+	for name := range files {
+		if name != ManifestFileName && mf.File(name) == nil {
+			return nil, fmt.Errorf("file %q is not in the manifest", name)
+		}
+	}
+	err = mf.Verify(func(name string) (io.ReadCloser, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("file is missing")
+		}
+		return f.Open()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
+
+// ReadZip verifies & reads a zip archive written by WriteZip. The body is
+// loaded into BodyBytes
+func ReadZip(r io.ReaderAt, size int64) (*dataset.Dataset, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files, err := zipFiles(zr)
+	if err != nil {
+		return nil, err
+	}
+	mf, err := verifyZip(files)
+	if err != nil {
+		return nil, err
+	}
+
+	read := func(name string) ([]byte, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("file %q is missing", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+
+	var doc *ManifestFile
+	for _, f := range mf.Files {
+		if f.Component == "dataset" {
+			doc = f
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("manifest lists no dataset document")
+	}
+	data, err := read(doc.Name)
+	if err != nil {
+		return nil, err
+	}
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(data, ds); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", doc.Name, err)
+	}
+	if ds.BodyPath != "" {
+		if mf.File(ds.BodyPath) == nil {
+			return nil, fmt.Errorf("body %q is not in the manifest", ds.BodyPath)
+		}
+		if ds.BodyBytes, err = read(ds.BodyPath); err != nil {
+			return nil, err
+		}
+		ds.BodyPath = ""
+	}
+	return ds, nil
+}
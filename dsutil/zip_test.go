@@ -0,0 +1,144 @@
+package dsutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+func testDataset() *dataset.Dataset {
+	return &dataset.Dataset{
+		Commit:    &dataset.Commit{Title: "initial commit", Timestamp: time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC)},
+		Meta:      &dataset.Meta{Title: "stops"},
+		Structure: &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray},
+		BodyBytes: []byte("a,b\n1,2\n"),
+	}
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteZip(testDataset(), buf); err != nil {
+		t.Fatal(err)
+	}
+
+	mf, err := VerifyZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mf.Files) != 2 || mf.File("body.csv") == nil || mf.File("dataset.json") == nil {
+		t.Errorf("expected manifest to list body & dataset files, got: %v", mf.Files)
+	}
+
+	ds, err := ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ds.BodyBytes) != "a,b\n1,2\n" || ds.Meta.Title != "stops" || ds.BodyPath != "" {
+		t.Errorf("read dataset mismatch: %#v", ds)
+	}
+}
+
+// rewriteZip copies a zip archive, replacing file contents with edits and
+// adding files that aren't in the source
+func rewriteZip(t *testing.T, src []byte, edits map[string]string) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, f := range zr.File {
+		data, ok := edits[f.Name]
+		if !ok {
+			rc, _ := f.Open()
+			b := &bytes.Buffer{}
+			b.ReadFrom(rc)
+			rc.Close()
+			data = b.String()
+		}
+		delete(edits, f.Name)
+		if data == "<delete>" {
+			continue
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write([]byte(data))
+	}
+	for name, data := range edits {
+		w, _ := zw.Create(name)
+		w.Write([]byte(data))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestVerifyZipErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteZip(testDataset(), buf); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		edits map[string]string
+		err   string
+	}{
+		{map[string]string{"body.csv": "a,b\n1,3\n"}, `file "body.csv": hash mismatch`},
+		{map[string]string{"body.csv": "<delete>"}, `file "body.csv": file is missing`},
+		{map[string]string{"extra.txt": "hi"}, `file "extra.txt" is not in the manifest`},
+		{map[string]string{ManifestFileName: "<delete>"}, "archive has no manifest.json"},
+		{map[string]string{ManifestFileName: "{"}, "reading manifest.json: unexpected EOF"},
+	}
+	for i, c := range cases {
+		data := rewriteZip(t, buf.Bytes(), c.edits)
+		_, err := ReadZip(bytes.NewReader(data), int64(len(data)))
+		if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+
+	// a tampered body placed before the verified one
+	src, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	dup := &bytes.Buffer{}
+	zw := zip.NewWriter(dup)
+	w, _ := zw.Create("body.csv")
+	w.Write([]byte("a,b\n6,6\n"))
+	for _, f := range src.File {
+		rc, _ := f.Open()
+		w, _ := zw.Create(f.Name)
+		b := &bytes.Buffer{}
+		b.ReadFrom(rc)
+		rc.Close()
+		w.Write(b.Bytes())
+	}
+	zw.Close()
+	expect := `archive has more than one file named "body.csv"`
+	if _, err := VerifyZip(bytes.NewReader(dup.Bytes()), int64(dup.Len())); err == nil || err.Error() != expect {
+		t.Errorf("verify duplicate error mismatch. expected: %q, got: %v", expect, err)
+	}
+	if _, err := ReadZip(bytes.NewReader(dup.Bytes()), int64(dup.Len())); err == nil || err.Error() != expect {
+		t.Errorf("read duplicate error mismatch. expected: %q, got: %v", expect, err)
+	}
+
+	if err := WriteZip(nil, buf); err == nil {
+		t.Errorf("expected error writing a nil dataset")
+	}
+}
+
+func TestWriteZipAccess(t *testing.T) {
+	ds := testDataset()
+	ds.Meta.AccessLevel = dataset.AccessInternal
+	buf := &bytes.Buffer{}
+	if err := WriteZip(ds, buf); !errors.Is(err, dataset.ErrAccessDenied) {
+		t.Errorf("expected an access denied error, got: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %d bytes", buf.Len())
+	}
+	if err := WriteZip(ds, buf, WithAccessLevel(dataset.AccessInternal)); err != nil {
+		t.Errorf("expected internal exports to be allowed, got: %v", err)
+	}
+}
@@ -31,5 +31,12 @@ UpperCamelCase naming conventions
 			{{ isType $val "type" }}
 				return true or false if the type of $val matches the given type string
 				possible type values are "string", "object", "array", "boolean", "number"
+
+Readme rendering executes markdown & html readme scripts with placeholders
+for dataset values like {{ meta.title }} or {{ stats.columns.x.max }}, see
+RenderReadme
+
+BodyTable previews the first entries of a body as an aligned text table or an
+html table, for command line output & reports
 */
 package dsviz
@@ -0,0 +1,294 @@
+package dsviz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"math"
+	"text/template"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/stats"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qfs"
+)
+
+// RenderReadme executes the readme script of a dataset as a template,
+// returning the rendered readme. "md" readmes render to markdown with go's
+// text/template package, "html" readmes to html with html/template. Readme
+// templates reach dataset values through template functions named after
+// dataset components:
+//
+//	{{ meta.title }}
+//	{{ commit.title }}
+//	{{ structure.entries }}
+//	{{ stats.entries }}
+//	{{ stats.columns.population.max }}
+//	{{ previewTable 10 }}
+//
+// stats.columns holds the column stats of the stats component keyed by
+// column title, with numeric summary values like min, max & mean lifted to
+// the column. previewTable renders a table of the first rows of the preview
+// component, or of the body when the dataset has no preview. Referencing a
+// value the dataset doesn't have is an error, so a readme never publishes a
+// placeholder that didn't render. Like Render, readme script & body files
+// are replaced once they're consumed. The rendered readme is also set as the
+// readme rendered file, which dsfs.WriteDataset stores as the RenderedPath
+func RenderReadme(ds *dataset.Dataset) (qfs.File, error) {
+	if ds.Readme == nil {
+		return nil, fmt.Errorf("no readme component")
+	}
+	var name string
+	switch ds.Readme.Format {
+	case "md":
+		name = "readme.md"
+	case "html":
+		name = "readme.html"
+	default:
+		return nil, fmt.Errorf("readme format must be 'md' or 'html'")
+	}
+
+	script, err := readmeScript(ds.Readme)
+	if err != nil {
+		return nil, err
+	}
+	funcs, err := readmeFuncs(ds)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if ds.Readme.Format == "html" {
+		tmpl, err := htmltemplate.New(name).Option("missingkey=error").Funcs(funcs).Parse(script)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %s", err.Error())
+		}
+		if err := tmpl.Execute(buf, ds); err != nil {
+			return nil, err
+		}
+	} else {
+		tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(script)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %s", err.Error())
+		}
+		if err := tmpl.Execute(buf, ds); err != nil {
+			return nil, err
+		}
+	}
+	ds.Readme.SetRenderedFile(qfs.NewMemfileBytes(name, buf.Bytes()))
+	return qfs.NewMemfileBytes(name, buf.Bytes()), nil
+}
+
+// readmeScript reads the readme script, restoring a consumed script file
+func readmeScript(rm *dataset.Readme) (string, error) {
+	f := rm.ScriptFile()
+	if f == nil {
+		if rm.ScriptBytes == nil {
+			return "", fmt.Errorf("readme has no script")
+		}
+		return string(rm.ScriptBytes), nil
+	}
+	buf := &bytes.Buffer{}
+	data, err := ioutil.ReadAll(io.TeeReader(f, buf))
+	rm.SetScriptFile(qfs.NewMemfileReader(f.FileName(), buf))
+	if err != nil {
+		return "", fmt.Errorf("reading template data: %s", err.Error())
+	}
+	return string(data), nil
+}
+
+// readmeFuncs gives the template functions of a readme
+func readmeFuncs(ds *dataset.Dataset) (map[string]interface{}, error) {
+	doc, err := vizDataset(ds)
+	if err != nil {
+		return nil, err
+	}
+	integralNumbers(doc)
+	component := func(key string) func() map[string]interface{} {
+		return func() map[string]interface{} {
+			if m, ok := doc[key].(map[string]interface{}); ok {
+				return m
+			}
+			return map[string]interface{}{}
+		}
+	}
+	st, err := readmeStats(ds.Stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"ds":        func() map[string]interface{} { return doc },
+		"commit":    component("commit"),
+		"meta":      component("meta"),
+		"structure": component("structure"),
+		"transform": component("transform"),
+		"stats":     func() map[string]interface{} { return st },
+		"previewTable": func(limit int) (interface{}, error) {
+			return previewTable(ds, limit)
+		},
+		"filesize": func(n float64) string {
+			return printByteInfo(int(n))
+		},
+		"round": round,
+		"title": func() string {
+			if ds.Meta != nil && ds.Meta.Title != "" {
+				return ds.Meta.Title
+			}
+			return fmt.Sprintf("%s/%s", ds.Peername, ds.Name)
+		},
+	}, nil
+}
+
+// readmeStats gives the stats view of a readme template
+func readmeStats(sa *dataset.Stats) (map[string]interface{}, error) {
+	view := map[string]interface{}{}
+	if sa == nil {
+		return view, nil
+	}
+	cols, err := stats.Columns(sa)
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]interface{}{}
+	for _, c := range cols {
+		if c == nil {
+			continue
+		}
+		col, err := jsonMap(c)
+		if err != nil {
+			return nil, err
+		}
+		if c.Numeric != nil {
+			num, err := jsonMap(c.Numeric)
+			if err != nil {
+				return nil, err
+			}
+			delete(num, "count")
+			for k, v := range num {
+				col[k] = v
+			}
+			col["stddev"] = c.Numeric.StdDev()
+		}
+		integralNumbers(col)
+		columns[c.Title] = col
+	}
+	view["columns"] = columns
+	view["entries"] = sa.Entries
+	return view, nil
+}
+
+// jsonMap converts a value to a generic map with a JSON round trip
+func jsonMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// integralNumbers replaces integral float64 values of decoded JSON with
+// int64 values in place, so templates print 6300000000 instead of 6.3e+09
+func integralNumbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return int64(x)
+		}
+	case map[string]interface{}:
+		for k, val := range x {
+			x[k] = integralNumbers(val)
+		}
+	case []interface{}:
+		for i, val := range x {
+			x[i] = integralNumbers(val)
+		}
+	}
+	return v
+}
+
+// round rounds a number to a number of decimal places
+func round(places int, v interface{}) (float64, error) {
+	var f float64
+	switch x := v.(type) {
+	case float64:
+		f = x
+	case int:
+		f = float64(x)
+	case int64:
+		f = float64(x)
+	default:
+		return 0, fmt.Errorf("round: expected a number, got %T", v)
+	}
+	p := math.Pow(10, float64(places))
+	return math.Round(f*p) / p, nil
+}
+
+// previewTable renders up to limit rows of a tabular dataset as a table,
+// markdown for md readmes & html for html readmes. Tables are rendered as a
+// BodyTable without cutting off cells
+func previewTable(ds *dataset.Dataset, limit int) (interface{}, error) {
+	if ds.Structure == nil {
+		return nil, fmt.Errorf("previewTable: dataset has no structure component")
+	}
+	if _, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema); err != nil {
+		return nil, fmt.Errorf("previewTable: %s", err)
+	}
+
+	var rows interface{}
+	if ds.Preview != nil && ds.Preview.Body != nil {
+		rows = ds.Preview.Body
+	} else if ds.BodyFile() != nil {
+		// read one more row than the table shows, so the table knows the
+		// body has more
+		n := limit
+		if n >= 0 {
+			n++
+		}
+		var err error
+		if rows, err = bodyEntries(ds, 0, n); err != nil {
+			return nil, fmt.Errorf("previewTable: %s", err)
+		}
+	} else {
+		return nil, fmt.Errorf("previewTable: dataset has no preview or body")
+	}
+
+	list, _ := rows.([]interface{})
+	t, err := NewBodyTable(&rowsReader{st: ds.Structure, rows: list}, WithLimit(limit), WithMaxWidth(0))
+	if err != nil {
+		return nil, fmt.Errorf("previewTable: %s", err)
+	}
+
+	if ds.Readme.Format == "html" {
+		return t.HTML(), nil
+	}
+	return t.Markdown(), nil
+}
+
+// rowsReader reads entries from a list of rows
+type rowsReader struct {
+	st   *dataset.Structure
+	rows []interface{}
+	i    int
+}
+
+var _ dsio.EntryReader = (*rowsReader)(nil)
+
+func (r *rowsReader) Structure() *dataset.Structure { return r.st }
+
+func (r *rowsReader) ReadEntry() (dsio.Entry, error) {
+	if r.i >= len(r.rows) {
+		return dsio.Entry{}, io.EOF
+	}
+	ent := dsio.Entry{Index: r.i, Value: r.rows[r.i]}
+	r.i++
+	return ent, nil
+}
+
+func (r *rowsReader) Close() error { return nil }
@@ -0,0 +1,115 @@
+package dsviz
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/stats"
+	"github.com/qri-io/qfs"
+)
+
+func readmeDataset(format, script string) *dataset.Dataset {
+	ds := &dataset.Dataset{
+		Meta:   &dataset.Meta{Title: "World Population"},
+		Readme: &dataset.Readme{Format: format},
+		Stats: &dataset.Stats{
+			Entries: 3,
+			Stats: []*stats.ColumnStats{
+				{Title: "year", Count: 3, Numeric: &stats.NumericStats{Count: 3, Min: 2000, Max: 2002, Mean: 2001, Variance: 0.6666666666666666}},
+				{Title: "population", Count: 3, Numeric: &stats.NumericStats{Count: 3, Min: 6.1e9, Max: 6.3e9, Mean: 6.2e9}},
+			},
+		},
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "year", "type": "integer"},
+						map[string]interface{}{"title": "population", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+	ds.Readme.SetScriptFile(qfs.NewMemfileBytes("readme.md", []byte(script)))
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[[2000,6100000000],[2001,6200000000],[2002,6300000000]]`)))
+	return ds
+}
+
+func TestRenderReadme(t *testing.T) {
+	cases := []struct {
+		format, script, expect string
+	}{
+		{"md", "# {{ meta.title }}\n", "# World Population\n"},
+		{"md", "{{ stats.entries }} years, peaking at {{ stats.columns.population.max }}", "3 years, peaking at 6300000000"},
+		{"md", "{{ stats.columns.year.stddev | round 2 }}", "0.82"},
+		{"md", "{{ previewTable 2 }}", "| year | population |\n| ---: | ---: |\n| 2000 | 6100000000 |\n| 2001 | 6200000000 |\n\n… more entries\n"},
+		{"html", "<h1>{{ meta.title }}</h1>", "<h1>World Population</h1>"},
+		{"html", "{{ previewTable 1 }}", "<table>\n<thead>\n<tr><th style=\"text-align:right\">year</th><th style=\"text-align:right\">population</th></tr>\n</thead>\n<tbody>\n<tr><td style=\"text-align:right\">2000</td><td style=\"text-align:right\">6100000000</td></tr>\n</tbody>\n<tfoot>\n<tr><td colspan=\"2\">… more entries</td></tr>\n</tfoot>\n</table>"},
+	}
+
+	for i, c := range cases {
+		ds := readmeDataset(c.format, c.script)
+		f, err := RenderReadme(ds)
+		if err != nil {
+			t.Errorf("case %d: %s", i, err)
+			continue
+		}
+		got, _ := ioutil.ReadAll(f)
+		if string(got) != c.expect {
+			t.Errorf("case %d result mismatch. expected:\n%q\ngot:\n%q", i, c.expect, got)
+		}
+		if ds.Readme.RenderedFile() == nil {
+			t.Errorf("case %d: expected the rendered file to be set", i)
+		} else if rendered, _ := ioutil.ReadAll(ds.Readme.RenderedFile()); string(rendered) != c.expect {
+			t.Errorf("case %d: expected the rendered file to match, got: %q", i, rendered)
+		}
+		if ds.Readme.ScriptFile() == nil {
+			t.Errorf("case %d: expected the script file to be restored", i)
+		} else if script, _ := ioutil.ReadAll(ds.Readme.ScriptFile()); string(script) != c.script {
+			t.Errorf("case %d: expected the restored script to match, got: %q", i, script)
+		}
+	}
+}
+
+func TestRenderReadmePreview(t *testing.T) {
+	ds := readmeDataset("md", "{{ previewTable -1 }}")
+	ds.SetBodyFile(nil)
+	ds.Preview = &dataset.Preview{Body: []interface{}{[]interface{}{2000.0, "a|b"}, map[string]interface{}{"year": 2001.0}}}
+	f, err := RenderReadme(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "| year | population |\n| ---: | --- |\n| 2000 | a\\|b |\n| 2001 |  |\n"
+	if got, _ := ioutil.ReadAll(f); string(got) != expect {
+		t.Errorf("result mismatch. expected:\n%q\ngot:\n%q", expect, got)
+	}
+}
+
+func TestRenderReadmeErrors(t *testing.T) {
+	cases := []struct {
+		ds  *dataset.Dataset
+		err string
+	}{
+		{&dataset.Dataset{}, "no readme component"},
+		{&dataset.Dataset{Readme: &dataset.Readme{Format: "rst"}}, "readme format must be 'md' or 'html'"},
+		{&dataset.Dataset{Readme: &dataset.Readme{Format: "md"}}, "readme has no script"},
+		{readmeDataset("md", "{{ meta.title"), "parsing template: template: readme.md:1: unclosed action"},
+	}
+	for i, c := range cases {
+		_, err := RenderReadme(c.ds)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+
+	// missing values fail the render instead of rendering a placeholder
+	_, err := RenderReadme(readmeDataset("md", "{{ meta.license }}"))
+	if err == nil || !strings.Contains(err.Error(), `map has no entry for key "license"`) {
+		t.Errorf("expected a missing key error, got: %v", err)
+	}
+}
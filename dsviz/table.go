@@ -0,0 +1,366 @@
+package dsviz
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+// TableConfig configures body table previews
+type TableConfig struct {
+	// Limit is the number of entries a table shows, defaults to 10. Negative
+	// limits show every entry
+	Limit int
+	// MaxWidth is the maximum number of characters in a cell, longer cells
+	// are cut off & end with "…". defaults to 24, zero or less never cuts off
+	// cells
+	MaxWidth int
+}
+
+// WithLimit sets the number of entries a table shows
+func WithLimit(n int) func(*TableConfig) {
+	return func(c *TableConfig) {
+		c.Limit = n
+	}
+}
+
+// WithMaxWidth sets the maximum number of characters in a cell
+func WithMaxWidth(n int) func(*TableConfig) {
+	return func(c *TableConfig) {
+		c.MaxWidth = n
+	}
+}
+
+// BodyTable is a preview of the first entries of a body as a table of cells,
+// for printing "head" style summaries of a dataset
+type BodyTable struct {
+	// Titles are column headers. Tabular bodies use schema column titles,
+	// other bodies use row object keys in sorted order, or a single "value"
+	// column for scalar entries. Object bodies show entry keys in a first
+	// "key" column
+	Titles []string
+	// Rows are formatted cells in column order. Rows keep full cell values,
+	// Text, Markdown & HTML cut off cells at the maximum width
+	Rows [][]string
+	// Numeric is true for columns of numbers & nulls with at least one
+	// number, which are aligned to the right
+	Numeric []bool
+	// More is true when the body has entries that aren't in the table
+	More bool
+	// Remaining is the number of entries that aren't in the table, when the
+	// body structure records an entry count
+	Remaining int
+
+	maxWidth int
+}
+
+// NewBodyTable reads up to a limit of entries from r into a table. r isn't
+// closed
+func NewBodyTable(r dsio.EntryReader, opts ...func(*TableConfig)) (*BodyTable, error) {
+	cfg := &TableConfig{Limit: 10, MaxWidth: 24}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if r == nil {
+		return nil, fmt.Errorf("reader is required")
+	}
+	st := r.Structure()
+
+	var ents []dsio.Entry
+	t := &BodyTable{maxWidth: cfg.MaxWidth}
+	for cfg.Limit < 0 || len(ents) <= cfg.Limit {
+		ent, err := r.ReadEntry()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading entry %d: %w", len(ents), err)
+		}
+		if cfg.Limit >= 0 && len(ents) == cfg.Limit {
+			t.More = true
+			break
+		}
+		ents = append(ents, ent)
+	}
+	if t.More && st != nil && st.Entries > len(ents) {
+		t.Remaining = st.Entries - len(ents)
+	}
+
+	keyed := false
+	titles := entryTitles(ents)
+	if st != nil {
+		tlt, _ := dsio.GetTopLevelType(st)
+		keyed = tlt == "object"
+		if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+			titles = cols.Titles()
+		}
+	}
+	index := make(map[string]int, len(titles))
+	for i, title := range titles {
+		index[title] = i
+	}
+
+	numeric := make([]bool, len(titles))
+	other := make([]bool, len(titles))
+	for _, ent := range ents {
+		vs := make([]interface{}, len(titles))
+		switch x := ent.Value.(type) {
+		case []interface{}:
+			copy(vs, x)
+		case map[string]interface{}:
+			for key, v := range x {
+				if i, ok := index[key]; ok {
+					vs[i] = v
+				}
+			}
+		default:
+			if len(vs) > 0 {
+				vs[0] = x
+			}
+		}
+		row := make([]string, len(vs))
+		for i, v := range vs {
+			row[i] = cellString(v)
+			switch v.(type) {
+			case nil:
+			case int, int64, float64, vals.Decimal:
+				numeric[i] = true
+			default:
+				other[i] = true
+			}
+		}
+		if keyed {
+			row = append([]string{ent.Key}, row...)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	for i := range numeric {
+		numeric[i] = numeric[i] && !other[i]
+	}
+	if keyed {
+		titles = append([]string{"key"}, titles...)
+		numeric = append([]bool{false}, numeric...)
+	}
+	t.Titles = titles
+	t.Numeric = numeric
+	return t, nil
+}
+
+// entryTitles gives the column titles of entries without a tabular schema
+func entryTitles(ents []dsio.Entry) []string {
+	keys := map[string]bool{}
+	width := 0
+	scalar := false
+	for _, ent := range ents {
+		switch x := ent.Value.(type) {
+		case map[string]interface{}:
+			for key := range x {
+				keys[key] = true
+			}
+		case []interface{}:
+			if len(x) > width {
+				width = len(x)
+			}
+		default:
+			scalar = true
+		}
+	}
+	switch {
+	case len(keys) > 0:
+		titles := make([]string, 0, len(keys))
+		for key := range keys {
+			titles = append(titles, key)
+		}
+		sort.Strings(titles)
+		return titles
+	case width > 0:
+		titles := make([]string, width)
+		for i := range titles {
+			titles[i] = fmt.Sprintf("%d", i)
+		}
+		return titles
+	case scalar:
+		return []string{"value"}
+	}
+	return nil
+}
+
+// cellString formats a table cell. Integral numbers are written without an
+// exponent
+func cellString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []interface{}, map[string]interface{}:
+		data, _ := json.Marshal(x)
+		return string(data)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// cut shortens a cell to the maximum width, in characters
+func (t *BodyTable) cut(s string) string {
+	if t.maxWidth <= 0 || utf8.RuneCountInString(s) <= t.maxWidth {
+		return s
+	}
+	if t.maxWidth == 1 {
+		return "…"
+	}
+	return string([]rune(s)[:t.maxWidth-1]) + "…"
+}
+
+// numeric reports whether column i is aligned to the right
+func (t *BodyTable) numeric(i int) bool {
+	return i < len(t.Numeric) && t.Numeric[i]
+}
+
+// footer describes entries that aren't in the table
+func (t *BodyTable) footer() string {
+	switch {
+	case t.Remaining == 1:
+		return "… 1 more entry"
+	case t.Remaining > 0:
+		return fmt.Sprintf("… %d more entries", t.Remaining)
+	case t.More:
+		return "… more entries"
+	}
+	return ""
+}
+
+// Text gives the table as plain text with space-aligned columns & a header
+// underline, for terminal output. Line breaks & tabs in cells are replaced
+// with spaces
+func (t *BodyTable) Text() string {
+	flat := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+	header := make([]string, len(t.Titles))
+	widths := make([]int, len(t.Titles))
+	for i, title := range t.Titles {
+		header[i] = t.cut(flat.Replace(title))
+		widths[i] = utf8.RuneCountInString(header[i])
+	}
+	rows := make([][]string, len(t.Rows))
+	for r, row := range t.Rows {
+		rows[r] = make([]string, len(row))
+		for i, cell := range row {
+			rows[r][i] = t.cut(flat.Replace(cell))
+			if n := utf8.RuneCountInString(rows[r][i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	sb := &strings.Builder{}
+	line := func(cells []string) {
+		ln := &strings.Builder{}
+		for i, cell := range cells {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i > 0 {
+				ln.WriteString("  ")
+			}
+			if t.numeric(i) {
+				ln.WriteString(pad + cell)
+			} else {
+				ln.WriteString(cell + pad)
+			}
+		}
+		sb.WriteString(strings.TrimRight(ln.String(), " ") + "\n")
+	}
+	line(header)
+	rules := make([]string, len(widths))
+	for i, w := range widths {
+		rules[i] = strings.Repeat("-", w)
+	}
+	line(rules)
+	for _, row := range rows {
+		line(row)
+	}
+	if f := t.footer(); f != "" {
+		sb.WriteString(f + "\n")
+	}
+	return sb.String()
+}
+
+// Markdown gives the table as a markdown pipe table, with numeric columns
+// aligned to the right. Pipes in cells are escaped & line breaks replaced
+// with spaces
+func (t *BodyTable) Markdown() string {
+	escape := strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+	sb := &strings.Builder{}
+	line := func(cells []string) {
+		sb.WriteString("|")
+		for _, cell := range cells {
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+	header := make([]string, len(t.Titles))
+	rules := make([]string, len(t.Titles))
+	for i, title := range t.Titles {
+		header[i] = escape.Replace(t.cut(title))
+		rules[i] = "---"
+		if t.numeric(i) {
+			rules[i] = "---:"
+		}
+	}
+	line(header)
+	line(rules)
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = escape.Replace(t.cut(cell))
+		}
+		line(cells)
+	}
+	if f := t.footer(); f != "" {
+		sb.WriteString("\n" + f + "\n")
+	}
+	return sb.String()
+}
+
+// HTML gives the table as an html table with inline styles, for embedding in
+// web pages & emails. Cut off cells carry their full value as a title
+func (t *BodyTable) HTML() htmltemplate.HTML {
+	esc := htmltemplate.HTMLEscapeString
+	align := func(i int) string {
+		if t.numeric(i) {
+			return ` style="text-align:right"`
+		}
+		return ""
+	}
+	sb := &strings.Builder{}
+	sb.WriteString("<table>\n<thead>\n<tr>")
+	for i, title := range t.Titles {
+		sb.WriteString("<th" + align(i) + ">" + esc(title) + "</th>")
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range t.Rows {
+		sb.WriteString("<tr>")
+		for i, cell := range row {
+			attrs := align(i)
+			short := t.cut(cell)
+			if short != cell {
+				attrs += ` title="` + esc(cell) + `"`
+			}
+			sb.WriteString("<td" + attrs + ">" + esc(short) + "</td>")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</tbody>\n")
+	if f := t.footer(); f != "" {
+		sb.WriteString(fmt.Sprintf("<tfoot>\n<tr><td colspan=\"%d\">%s</td></tr>\n</tfoot>\n", len(t.Titles), esc(f)))
+	}
+	sb.WriteString("</table>")
+	return htmltemplate.HTML(sb.String())
+}
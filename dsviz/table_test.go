@@ -0,0 +1,114 @@
+package dsviz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func bodyTable(t *testing.T, st *dataset.Structure, body string, opts ...func(*TableConfig)) *BodyTable {
+	t.Helper()
+	r, err := dsio.NewJSONReader(st, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	tbl, err := NewBodyTable(r, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tbl
+}
+
+func TestBodyTableText(t *testing.T) {
+	tabular := &dataset.Structure{
+		Format:  "json",
+		Entries: 4,
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "stop_id", "type": "string"},
+					map[string]interface{}{"title": "stop_name", "type": "string"},
+					map[string]interface{}{"title": "zone", "type": "integer"},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		description string
+		st          *dataset.Structure
+		body        string
+		opts        []func(*TableConfig)
+		expect      string
+	}{
+		{"tabular", tabular,
+			`[["de:08111:6118","Stuttgart Hauptbahnhof (oben)",1],["de:08111:6056","Feuersee",10],["x","y",null],["z","w",2]]`,
+			[]func(*TableConfig){WithLimit(3), WithMaxWidth(16)},
+			"stop_id        stop_name         zone\n" +
+				"-------------  ----------------  ----\n" +
+				"de:08111:6118  Stuttgart Haupt…     1\n" +
+				"de:08111:6056  Feuersee            10\n" +
+				"x              y\n" +
+				"… 1 more entry\n"},
+		{"object rows", &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+			`[{"name":"a\nb","tags":["x"]},{"count":2.5}]`, nil,
+			"count  name  tags\n" +
+				"-----  ----  -----\n" +
+				"       a b   [\"x\"]\n" +
+				"  2.5\n"},
+		{"scalar entries", &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+			`[1,2,3]`, []func(*TableConfig){WithLimit(2)},
+			"value\n" +
+				"-----\n" +
+				"    1\n" +
+				"    2\n" +
+				"… more entries\n"},
+		{"object body", &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject},
+			`{"population":6100000000,"title":"world"}`, nil,
+			"key         value\n" +
+				"----------  ----------\n" +
+				"population  6100000000\n" +
+				"title       world\n"},
+		{"empty", &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+			`[]`, nil,
+			"\n\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got := bodyTable(t, c.st, c.body, c.opts...).Text()
+			if got != c.expect {
+				t.Errorf("text mismatch.\nexpected:\n%s\ngot:\n%s", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestBodyTableHTML(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray, Entries: 3}
+	tbl := bodyTable(t, st, `[{"name":"<b>Feuersee</b>","zone":1},{"name":"x","zone":2},{"name":"y","zone":3}]`, WithLimit(2), WithMaxWidth(8))
+	expect := "<table>\n<thead>\n<tr><th>name</th><th style=\"text-align:right\">zone</th></tr>\n</thead>\n<tbody>\n" +
+		"<tr><td title=\"&lt;b&gt;Feuersee&lt;/b&gt;\">&lt;b&gt;Feue…</td><td style=\"text-align:right\">1</td></tr>\n" +
+		"<tr><td>x</td><td style=\"text-align:right\">2</td></tr>\n" +
+		"</tbody>\n<tfoot>\n<tr><td colspan=\"2\">… 1 more entry</td></tr>\n</tfoot>\n</table>"
+	if got := string(tbl.HTML()); got != expect {
+		t.Errorf("html mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+	if tbl.Rows[0][0] != "<b>Feuersee</b>" {
+		t.Errorf("expected rows to keep full cells, got: %q", tbl.Rows[0][0])
+	}
+}
+
+func TestBodyTableMarkdown(t *testing.T) {
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray, Entries: 3}
+	tbl := bodyTable(t, st, `[{"name":"a|b","zone":1},{"name":"x\ny","zone":2},{"name":"z","zone":3}]`, WithLimit(2))
+	expect := "| name | zone |\n| --- | ---: |\n| a\\|b | 1 |\n| x y | 2 |\n\n… 1 more entry\n"
+	if got := tbl.Markdown(); got != expect {
+		t.Errorf("markdown mismatch.\nexpected:\n%q\ngot:\n%q", expect, got)
+	}
+}
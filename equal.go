@@ -0,0 +1,316 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// objectMarshaler is implemented by components that can always be encoded as
+// a JSON object, even when they're a path reference
+type objectMarshaler interface {
+	MarshalJSONObject() ([]byte, error)
+}
+
+// Equal checks if two datasets have the same semantic content: paths are
+// ignored, maps are compared regardless of ordering, and zero values of
+// component fields are considered equal to unset values, so a nil component
+// equals an empty one. Unlike CompareDatasets, Equal examines every encoded
+// field including body data & arbitrary metadata. User data like bodies,
+// schemas, format configs & arbitrary metadata is compared exactly. Datasets
+// that can't be encoded are never equal
+func (ds *Dataset) Equal(b *Dataset) bool {
+	return equalObjects(ds.equalForm(), b.equalForm())
+}
+
+// datasetObject encodes a dataset as a JSON object
+type datasetObject Dataset
+
+func (ds *datasetObject) MarshalJSONObject() ([]byte, error) {
+	return json.Marshal((*_dataset)(ds))
+}
+
+func (ds *Dataset) equalForm() objectMarshaler {
+	if ds == nil {
+		return nil
+	}
+	c := ds.Clone()
+	c.Path = ""
+	c.Qri = KindDataset.String()
+	if c.Commit != nil {
+		c.Commit.Path = ""
+	}
+	if c.Meta != nil {
+		c.Meta.Path = ""
+	}
+	if c.Structure != nil {
+		c.Structure.Path = ""
+	}
+	if c.Transform != nil {
+		c.Transform.Path = ""
+	}
+	if c.Viz != nil {
+		c.Viz.Path = ""
+	}
+	if c.Readme != nil {
+		c.Readme.Path = ""
+	}
+	if c.Provenance != nil {
+		c.Provenance.Path = ""
+	}
+	if c.Preview != nil {
+		c.Preview.Path = ""
+	}
+	if c.Stats != nil {
+		c.Stats.Path = ""
+	}
+	return (*datasetObject)(c)
+}
+
+// Equal checks if two commits have the same semantic content, see Dataset.Equal
+func (cm *Commit) Equal(b *Commit) bool {
+	return equalObjects(cm.equalForm(), b.equalForm())
+}
+
+func (cm *Commit) equalForm() objectMarshaler {
+	if cm == nil {
+		return nil
+	}
+	c := cm.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two metadata structs have the same semantic content, see
+// Dataset.Equal
+func (md *Meta) Equal(b *Meta) bool {
+	return equalObjects(md.equalForm(), b.equalForm())
+}
+
+func (md *Meta) equalForm() objectMarshaler {
+	if md == nil {
+		return nil
+	}
+	c := md.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two structures have the same semantic content, see
+// Dataset.Equal
+func (s *Structure) Equal(b *Structure) bool {
+	return equalObjects(s.equalForm(), b.equalForm())
+}
+
+func (s *Structure) equalForm() objectMarshaler {
+	if s == nil {
+		return nil
+	}
+	c := s.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two transforms have the same semantic content, see
+// Dataset.Equal
+func (q *Transform) Equal(b *Transform) bool {
+	return equalObjects(q.equalForm(), b.equalForm())
+}
+
+func (q *Transform) equalForm() objectMarshaler {
+	if q == nil {
+		return nil
+	}
+	c := q.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two viz components have the same semantic content, see
+// Dataset.Equal
+func (v *Viz) Equal(b *Viz) bool {
+	return equalObjects(v.equalForm(), b.equalForm())
+}
+
+func (v *Viz) equalForm() objectMarshaler {
+	if v == nil {
+		return nil
+	}
+	c := v.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two readme components have the same semantic content, see
+// Dataset.Equal
+func (r *Readme) Equal(b *Readme) bool {
+	return equalObjects(r.equalForm(), b.equalForm())
+}
+
+func (r *Readme) equalForm() objectMarshaler {
+	if r == nil {
+		return nil
+	}
+	c := r.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two provenance components have the same semantic content,
+// see Dataset.Equal
+func (p *Provenance) Equal(b *Provenance) bool {
+	return equalObjects(p.equalForm(), b.equalForm())
+}
+
+func (p *Provenance) equalForm() objectMarshaler {
+	if p == nil {
+		return nil
+	}
+	c := p.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two previews have the same semantic content, see
+// Dataset.Equal
+func (p *Preview) Equal(b *Preview) bool {
+	return equalObjects(p.equalForm(), b.equalForm())
+}
+
+func (p *Preview) equalForm() objectMarshaler {
+	if p == nil {
+		return nil
+	}
+	c := p.Clone()
+	c.Path = ""
+	return c
+}
+
+// Equal checks if two stats have the same semantic content, see Dataset.Equal
+func (sa *Stats) Equal(b *Stats) bool {
+	return equalObjects(sa.equalForm(), b.equalForm())
+}
+
+func (sa *Stats) equalForm() objectMarshaler {
+	if sa == nil {
+		return nil
+	}
+	c := sa.Clone()
+	c.Path = ""
+	return c
+}
+
+// equalObjects compares the pruned encoded forms of two objects
+func equalObjects(a, b objectMarshaler) bool {
+	av, err := semanticValue(a)
+	if err != nil {
+		return false
+	}
+	bv, err := semanticValue(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// semanticValue encodes an object to it's json.Unmarshal-style form with zero
+// values of struct fields removed. nil objects have a nil semantic value
+func semanticValue(o objectMarshaler) (interface{}, error) {
+	if o == nil {
+		return nil, nil
+	}
+	data, err := o.MarshalJSONObject()
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return pruneZeroValues(v, reflect.TypeOf(o)), nil
+}
+
+// pruneZeroValues removes zero values of struct fields from the encoded form
+// v of a value of type t, recursively. Structs that only contain zero values
+// are themselves zero. User data held in interface & generic map fields like
+// the body, schemas, format configs & arbitrary metadata is kept exactly as
+// it is, so a 0 in a body never equals a null. Array elements are pruned but
+// never removed, as position is significant
+func pruneZeroValues(v interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, val := range x {
+				ft, ok := fields[key]
+				if !ok {
+					// keys that aren't fields are user data, like arbitrary meta
+					continue
+				}
+				if p := pruneZeroValues(val, ft); p == nil {
+					delete(x, key)
+				} else {
+					x[key] = p
+				}
+			}
+			// "qri" is always set on encoding, objects with only a kind are empty
+			if len(x) == 0 || len(x) == 1 && x["qri"] != nil {
+				return nil
+			}
+		case reflect.Map:
+			if len(x) == 0 {
+				return nil
+			}
+			if isStruct(t.Elem()) {
+				for key, val := range x {
+					x[key] = pruneZeroValues(val, t.Elem())
+				}
+			}
+		}
+		return x
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return x
+		}
+		if len(x) == 0 {
+			return nil
+		}
+		if isStruct(t.Elem()) {
+			for i, val := range x {
+				x[i] = pruneZeroValues(val, t.Elem())
+			}
+		}
+		return x
+	}
+	if t.Kind() == reflect.Interface {
+		return v
+	}
+	switch x := v.(type) {
+	case string:
+		if x == "" {
+			return nil
+		}
+	case float64:
+		if x == 0 {
+			return nil
+		}
+	case bool:
+		if !x {
+			return nil
+		}
+	}
+	return v
+}
+
+// isStruct reports whether t is a struct or a pointer to one
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
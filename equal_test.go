@@ -0,0 +1,93 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatasetEqual(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := func() *Dataset {
+		return &Dataset{
+			Path:      "/ipfs/QmA",
+			Commit:    &Commit{Path: "/ipfs/QmB", Title: "initial", Timestamp: ts},
+			Meta:      &Meta{Title: "stops", Keywords: []string{"transit"}},
+			Structure: &Structure{Format: "csv", Schema: map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "array"}}},
+			Body:      []interface{}{[]interface{}{"a", 1}},
+		}
+	}
+
+	cases := []struct {
+		description string
+		a, b        *Dataset
+		equal       bool
+	}{
+		{"nil", nil, nil, true},
+		{"nil & empty", nil, &Dataset{}, true},
+		{"nil & empty with kind", nil, &Dataset{Qri: "ds:0"}, true},
+		{"identical", base(), base(), true},
+		{"ignores paths", base(), func() *Dataset { ds := base(); ds.Path = "/ipfs/QmZ"; ds.Commit.Path = ""; return ds }(), true},
+		{"empty component", &Dataset{Name: "a"}, &Dataset{Name: "a", Meta: &Meta{}, Viz: &Viz{}}, true},
+		{"nil & empty slices", &Dataset{Meta: &Meta{Title: "a"}}, &Dataset{Meta: &Meta{Title: "a", Citations: []*Citation{}}}, true},
+		{"changed meta", base(), func() *Dataset { ds := base(); ds.Meta.Title = "changed"; return ds }(), false},
+		{"changed body", base(), func() *Dataset { ds := base(); ds.Body = []interface{}{[]interface{}{"a", 2}}; return ds }(), false},
+		{"changed schema", base(), func() *Dataset { ds := base(); ds.Structure.Schema["type"] = "object"; return ds }(), false},
+		{"arbitrary meta", base(), func() *Dataset { ds := base(); ds.Meta.SetArbitrary("mfdz:score", 3); return ds }(), false},
+		{"zero body value", &Dataset{Body: []interface{}{0.0, 1.0}}, &Dataset{Body: []interface{}{nil, 1.0}}, false},
+		{"zero arbitrary meta", func() *Dataset { ds := base(); ds.Meta.SetArbitrary("n", 0); return ds }(), base(), false},
+		{"zero body object value", &Dataset{Body: map[string]interface{}{"n": 0}}, &Dataset{Body: map[string]interface{}{}}, false},
+		{"zero format config", &Dataset{Structure: &Structure{Format: "csv", FormatConfig: map[string]interface{}{"headerRow": false}}}, &Dataset{Structure: &Structure{Format: "csv"}}, false},
+		{"zero schema value", &Dataset{Structure: &Structure{Schema: map[string]interface{}{"minItems": 0}}}, &Dataset{Structure: &Structure{Schema: map[string]interface{}{}}}, false},
+		{"empty format config", &Dataset{Structure: &Structure{Format: "csv", FormatConfig: map[string]interface{}{}}}, &Dataset{Structure: &Structure{Format: "csv"}}, true},
+		{"zero struct fields", &Dataset{Structure: &Structure{Format: "csv", Entries: 0, Strict: false}}, &Dataset{Structure: &Structure{Format: "csv"}}, true},
+		{"unencodable", &Dataset{Body: make(chan int)}, &Dataset{Body: make(chan int)}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.a.Equal(c.b); got != c.equal {
+			t.Errorf("%s: expected %t, got %t", c.description, c.equal, got)
+		}
+		if got := c.b.Equal(c.a); got != c.equal {
+			t.Errorf("%s (reversed): expected %t, got %t", c.description, c.equal, got)
+		}
+	}
+}
+
+func TestComponentEqual(t *testing.T) {
+	if !(&Commit{Path: "/a", Title: "a"}).Equal(&Commit{Path: "/b", Title: "a"}) {
+		t.Errorf("expected commits to be equal")
+	}
+	if (&Commit{Title: "a"}).Equal(&Commit{Title: "b"}) {
+		t.Errorf("expected commits to differ")
+	}
+	if !(*Meta)(nil).Equal(&Meta{Qri: "md:0"}) {
+		t.Errorf("expected nil & empty meta to be equal")
+	}
+	if (&Meta{Title: "a"}).Equal(nil) {
+		t.Errorf("expected meta to differ from nil")
+	}
+	if !(&Structure{Format: "json", Schema: map[string]interface{}{"a": 1, "b": 2}}).Equal(&Structure{Format: "json", Schema: map[string]interface{}{"b": 2, "a": 1}}) {
+		t.Errorf("expected structures to be equal")
+	}
+	if (&Structure{Format: "json"}).Equal(&Structure{Format: "csv"}) {
+		t.Errorf("expected structures to differ")
+	}
+	if !(&Transform{Path: "/a", Syntax: "starlark"}).Equal(&Transform{Syntax: "starlark"}) {
+		t.Errorf("expected transforms to be equal")
+	}
+	if (&Transform{Syntax: "starlark"}).Equal(&Transform{Syntax: "python"}) {
+		t.Errorf("expected transforms to differ")
+	}
+	if !(&Viz{Format: "html"}).Equal(&Viz{Path: "/a", Format: "html"}) {
+		t.Errorf("expected viz to be equal")
+	}
+	if (&Viz{Format: "html"}).Equal(&Viz{Format: "svg"}) {
+		t.Errorf("expected viz to differ")
+	}
+	if !(&Readme{Format: "md"}).Equal(&Readme{Path: "/a", Format: "md"}) {
+		t.Errorf("expected readmes to be equal")
+	}
+	if (&Readme{Format: "md"}).Equal(&Readme{Format: "html"}) {
+		t.Errorf("expected readmes to differ")
+	}
+}
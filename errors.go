@@ -0,0 +1,25 @@
+package dataset
+
+import "errors"
+
+// Errors that may occur anywhere a dataset is read, written or validated.
+// Functions return these errors directly or wrapped with additional detail,
+// check for them with errors.Is instead of matching error messages
+var (
+	// ErrNotFound occurs when a path doesn't resolve to any content. Stores &
+	// resolvers should return (or wrap) ErrNotFound for missing paths
+	ErrNotFound = errors.New("path not found")
+	// ErrNoBody occurs when reading the body of a dataset that doesn't have
+	// one
+	ErrNoBody = errors.New("dataset has no body")
+	// ErrFormatRequired occurs when a structure doesn't specify a data format
+	ErrFormatRequired = errors.New("format is required")
+	// ErrInvalidSchema occurs when a structure schema can't describe a body
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrSchemaMismatch occurs when data doesn't have the shape a structure
+	// schema describes
+	ErrSchemaMismatch = errors.New("schema mismatch")
+	// ErrAccessDenied occurs when the access level of a dataset doesn't allow
+	// sharing it where it's being sent, see Dataset.CheckAccess
+	ErrAccessDenied = errors.New("access denied")
+)
@@ -0,0 +1,31 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestErrorsIs(t *testing.T) {
+	ds := &Dataset{
+		Commit:    &Commit{Title: "initial commit", Timestamp: time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC)},
+		Structure: &Structure{},
+	}
+	if err := ds.Validate(); !errors.Is(err, ErrFormatRequired) {
+		t.Errorf("expected wrapped ErrFormatRequired, got: %v", err)
+	}
+
+	var se *json.SyntaxError
+	if err := (&Dataset{}).UnmarshalJSON([]byte(`{"meta":`)); !errors.As(err, &se) {
+		t.Errorf("expected dataset decoding to wrap json errors, got: %#v", err)
+	}
+
+	r := LinkResolver(func(ctx context.Context, path string) (*Dataset, error) { return nil, nil })
+	if _, err := r.ResolveLink(context.Background(), vals.NewLink("/ipfs/QmA")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/qri-io/qfs"
+)
+
+// FileSource is a named, re-readable source of file content. The files a
+// component holds are qfs.File streams that can only be read once. A
+// FileSource opens a fresh file each time Open is called, so content can be
+// read as many times as needed without buffering it on the component
+type FileSource interface {
+	// Name of the file, eg. "transform.star"
+	Name() string
+	// Size of the content in bytes, -1 if it isn't known until opened
+	Size() int64
+	// Open creates a file that reads content from the beginning. Callers
+	// must close the file
+	Open(ctx context.Context) (qfs.File, error)
+}
+
+// bytesSource is a FileSource for content held in memory
+type bytesSource struct {
+	name string
+	data []byte
+}
+
+// NewBytesSource creates a FileSource for in-memory content
+func NewBytesSource(name string, data []byte) FileSource {
+	return bytesSource{name: name, data: data}
+}
+
+// Name implements the FileSource interface
+func (s bytesSource) Name() string { return s.name }
+
+// Size implements the FileSource interface
+func (s bytesSource) Size() int64 { return int64(len(s.data)) }
+
+// Open implements the FileSource interface
+func (s bytesSource) Open(ctx context.Context) (qfs.File, error) {
+	return qfs.NewMemfileBytes(s.name, s.data), nil
+}
+
+// pathSource is a FileSource for content fetched from a resolver
+type pathSource struct {
+	resolver qfs.PathResolver
+	path     string
+}
+
+// NewPathSource creates a FileSource that fetches path from resolver each
+// time it's opened. The source is named for the last element of path
+func NewPathSource(resolver qfs.PathResolver, path string) FileSource {
+	return pathSource{resolver: resolver, path: path}
+}
+
+// Name implements the FileSource interface
+func (s pathSource) Name() string { return filepath.Base(s.path) }
+
+// Size implements the FileSource interface. resolved files aren't sized
+// until they're opened
+func (s pathSource) Size() int64 { return -1 }
+
+// Open implements the FileSource interface
+func (s pathSource) Open(ctx context.Context) (qfs.File, error) {
+	if s.resolver == nil {
+		return nil, ErrNoResolver
+	}
+	return s.resolver.Get(ctx, s.path)
+}
+
+// scriptSource picks a source for script content, preferring inline bytes
+// over a path. Returns nil if there's no content to read
+func scriptSource(name string, data []byte, path string, resolver qfs.PathResolver) FileSource {
+	if data != nil {
+		return NewBytesSource(name, data)
+	}
+	if path != "" {
+		return NewPathSource(resolver, path)
+	}
+	return nil
+}
+
+// ReadSource reads the full content of a FileSource
+func ReadSource(ctx context.Context, src FileSource) ([]byte, error) {
+	if src == nil {
+		return nil, fmt.Errorf("file source is required")
+	}
+	f, err := src.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// BodySource gives a re-readable source for the dataset body, nil if the
+// dataset has no body. Bodies represented as native go types can't be read
+// as files, and give ErrInlineBody
+func (ds *Dataset) BodySource(resolver qfs.PathResolver) (FileSource, error) {
+	if ds.Body != nil {
+		return nil, ErrInlineBody
+	}
+	if ds.BodyBytes != nil {
+		name := ds.BodyPath
+		if name == "" {
+			name = "body"
+		}
+		return NewBytesSource(name, ds.BodyBytes), nil
+	}
+	if ds.BodyPath != "" {
+		return NewPathSource(resolver, ds.BodyPath), nil
+	}
+	return nil, nil
+}
+
+// ScriptSource gives a re-readable source for the transform script, nil if
+// the transform has no script
+func (q *Transform) ScriptSource(resolver qfs.PathResolver) FileSource {
+	return scriptSource("transform.star", q.ScriptBytes, q.ScriptPath, resolver)
+}
+
+// ScriptSource gives a re-readable source for the step script, nil if the
+// step has no script
+func (s *TransformStep) ScriptSource(resolver qfs.PathResolver) FileSource {
+	return scriptSource(s.Name, s.ScriptBytes, s.ScriptPath, resolver)
+}
+
+// ScriptSource gives a re-readable source for the viz template, nil if the
+// viz has no template
+func (v *Viz) ScriptSource(resolver qfs.PathResolver) FileSource {
+	return scriptSource("template.html", v.ScriptBytes, v.ScriptPath, resolver)
+}
+
+// RenderedSource gives a re-readable source for the rendered viz, nil if the
+// viz hasn't been rendered
+func (v *Viz) RenderedSource(resolver qfs.PathResolver) FileSource {
+	return scriptSource("", nil, v.RenderedPath, resolver)
+}
+
+// ScriptSource gives a re-readable source for the readme script, nil if the
+// readme has no script
+func (r *Readme) ScriptSource(resolver qfs.PathResolver) FileSource {
+	return scriptSource("readme.md", r.ScriptBytes, r.ScriptPath, resolver)
+}
+
+// RenderedSource gives a re-readable source for the rendered readme, nil if
+// the readme hasn't been rendered
+func (r *Readme) RenderedSource(resolver qfs.PathResolver) FileSource {
+	return scriptSource("", nil, r.RenderedPath, resolver)
+}
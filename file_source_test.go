@@ -0,0 +1,110 @@
+package dataset
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFileSources(t *testing.T) {
+	resolver := mapResolver{"/ipfs/QmScript": "print('hi')"}
+
+	cases := []struct {
+		description string
+		src         FileSource
+		name        string
+		size        int64
+		content     string
+		err         string
+	}{
+		{"transform bytes", (&Transform{ScriptBytes: []byte("a = 1")}).ScriptSource(nil), "transform.star", 5, "a = 1", ""},
+		{"transform path", (&Transform{ScriptPath: "/ipfs/QmScript"}).ScriptSource(resolver), "QmScript", -1, "print('hi')", ""},
+		{"transform missing resolver", (&Transform{ScriptPath: "/ipfs/QmScript"}).ScriptSource(nil), "QmScript", -1, "", "no resolver available to fetch path"},
+		{"transform bad path", (&Transform{ScriptPath: "/ipfs/QmNope"}).ScriptSource(resolver), "QmNope", -1, "", "path not found"},
+		{"step bytes", (&TransformStep{Name: "setup", ScriptBytes: []byte("x")}).ScriptSource(nil), "setup", 1, "x", ""},
+		{"viz bytes", (&Viz{ScriptBytes: []byte("<html>")}).ScriptSource(nil), "template.html", 6, "<html>", ""},
+		{"viz rendered", (&Viz{RenderedPath: "/ipfs/QmScript"}).RenderedSource(resolver), "QmScript", -1, "print('hi')", ""},
+		{"readme bytes", (&Readme{ScriptBytes: []byte("# hi")}).ScriptSource(nil), "readme.md", 4, "# hi", ""},
+		{"readme rendered", (&Readme{RenderedPath: "/ipfs/QmScript"}).RenderedSource(resolver), "QmScript", -1, "print('hi')", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.src.Name() != c.name {
+				t.Errorf("name mismatch. expected: %q, got: %q", c.name, c.src.Name())
+			}
+			if c.src.Size() != c.size {
+				t.Errorf("size mismatch. expected: %d, got: %d", c.size, c.src.Size())
+			}
+			// sources must be re-readable
+			for i := 0; i < 2; i++ {
+				data, err := ReadSource(context.Background(), c.src)
+				if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+					t.Fatalf("read %d error mismatch. expected: %q, got: %v", i, c.err, err)
+				}
+				if string(data) != c.content {
+					t.Errorf("read %d content mismatch. expected: %q, got: %q", i, c.content, string(data))
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyFileSources(t *testing.T) {
+	if src := (&Transform{}).ScriptSource(nil); src != nil {
+		t.Errorf("expected empty transform to have no script source")
+	}
+	if src := (&TransformStep{}).ScriptSource(nil); src != nil {
+		t.Errorf("expected empty step to have no script source")
+	}
+	if src := (&Viz{}).RenderedSource(nil); src != nil {
+		t.Errorf("expected empty viz to have no rendered source")
+	}
+	if src := (&Readme{}).ScriptSource(nil); src != nil {
+		t.Errorf("expected empty readme to have no script source")
+	}
+	if _, err := ReadSource(context.Background(), nil); err == nil {
+		t.Errorf("expected reading a nil source to error")
+	}
+}
+
+func TestBodySource(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := (&Dataset{Body: []interface{}{1}}).BodySource(nil); err != ErrInlineBody {
+		t.Errorf("expected ErrInlineBody, got: %v", err)
+	}
+	if src, err := (&Dataset{}).BodySource(nil); err != nil || src != nil {
+		t.Errorf("expected empty dataset to have no body source. got: %v, %v", src, err)
+	}
+
+	src, err := (&Dataset{BodyBytes: []byte("[1,2]")}).BodySource(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Name() != "body" {
+		t.Errorf("name mismatch. expected: %q, got: %q", "body", src.Name())
+	}
+
+	ds := &Dataset{BodyPath: "/ipfs/QmBody"}
+	src, err = ds.BodySource(mapResolver{"/ipfs/QmBody": "[1,2]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		data, err := ReadSource(ctx, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "[1,2]" {
+			t.Errorf("read %d content mismatch. got: %q", i, string(data))
+		}
+	}
+
+	if err := ds.OpenBodyFile(ctx, nil); err != ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+	expect := "opening dataset.bodyPath '/ipfs/QmBody': path not found"
+	if err := ds.OpenBodyFile(ctx, mapResolver{}); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+}
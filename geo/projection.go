@@ -0,0 +1,176 @@
+// Package geo works with the coordinates of geospatial dataset bodies,
+// reprojecting coordinate columns between coordinate reference systems
+package geo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/qri-io/dataset"
+)
+
+// Projection maps geographic coordinates in degrees to the planar
+// coordinates of a coordinate reference system & back
+type Projection interface {
+	// Forward projects a longitude & latitude in degrees
+	Forward(lon, lat float64) (x, y float64)
+	// Inverse gives the longitude & latitude in degrees of projected x & y
+	Inverse(x, y float64) (lon, lat float64)
+}
+
+const (
+	// EPSG4326 is WGS 84 geographic coordinates in degrees of longitude &
+	// latitude
+	EPSG4326 = "EPSG:4326"
+	// EPSG3857 is the spherical web mercator projection used by web map tiles
+	EPSG3857 = "EPSG:3857"
+	// EPSG25832 is ETRS89 / UTM zone 32N, the usual CRS of German & Danish
+	// official geodata
+	EPSG25832 = "EPSG:25832"
+)
+
+// ellipsoid flattening of GRS 80 & WGS 84. The semi-major axis of both is
+// 6378137m
+const (
+	grs80 = 1 / 298.257222101
+	wgs84 = 1 / 298.257223563
+)
+
+// ProjectionFor gives the projection of an EPSG coordinate reference system.
+// Supported systems are EPSG:4326, EPSG:3857, ETRS89 / UTM zones 28N-38N
+// (EPSG:25828-25838) and WGS 84 / UTM northern zones (EPSG:32601-32660).
+// Datum shifts are not applied: ETRS89 & WGS 84 differ by less than a meter
+func ProjectionFor(crs string) (Projection, error) {
+	code, err := dataset.ParseCRS(crs)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code == 4326:
+		return geographic{}, nil
+	case code == 3857:
+		return webMercator{}, nil
+	case code >= 25828 && code <= 25838:
+		return newUTM(code-25800, grs80), nil
+	case code >= 32601 && code <= 32660:
+		return newUTM(code-32600, wgs84), nil
+	}
+	return nil, fmt.Errorf("unsupported CRS %q", crs)
+}
+
+// Transformer converts coordinates from one coordinate reference system to
+// another
+type Transformer struct {
+	from, to Projection
+}
+
+// NewTransformer creates a transformer of coordinates in CRS from to CRS to
+func NewTransformer(from, to string) (*Transformer, error) {
+	f, err := ProjectionFor(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ProjectionFor(to)
+	if err != nil {
+		return nil, err
+	}
+	return &Transformer{from: f, to: t}, nil
+}
+
+// Transform converts a single coordinate. Geographic coordinates are given
+// as x: longitude, y: latitude
+func (t *Transformer) Transform(x, y float64) (float64, float64) {
+	lon, lat := t.from.Inverse(x, y)
+	return t.to.Forward(lon, lat)
+}
+
+// geographic is the identity projection of EPSG:4326
+type geographic struct{}
+
+func (geographic) Forward(lon, lat float64) (float64, float64) { return lon, lat }
+func (geographic) Inverse(x, y float64) (float64, float64)     { return x, y }
+
+// webMercator projects onto a sphere with the WGS 84 semi-major axis
+type webMercator struct{}
+
+const earthRadius = 6378137.0
+
+func (webMercator) Forward(lon, lat float64) (float64, float64) {
+	return earthRadius * radians(lon), earthRadius * math.Log(math.Tan(math.Pi/4+radians(lat)/2))
+}
+
+func (webMercator) Inverse(x, y float64) (float64, float64) {
+	return degrees(x / earthRadius), degrees(2*math.Atan(math.Exp(y/earthRadius)) - math.Pi/2)
+}
+
+// utm is a northern zone of the universal transverse mercator projection,
+// computed with Krüger's series to third order in the third flattening,
+// accurate to well below a millimeter within a zone
+type utm struct {
+	lon0  float64
+	a     float64
+	e     float64
+	alpha [3]float64
+	beta  [3]float64
+}
+
+const (
+	utmScale   = 0.9996
+	utmEasting = 500000.0
+)
+
+func newUTM(zone int, f float64) *utm {
+	n := f / (2 - f)
+	n2, n3 := n*n, n*n*n
+	return &utm{
+		lon0:  radians(float64(zone*6 - 183)),
+		a:     utmScale * earthRadius / (1 + n) * (1 + n2/4 + n2*n2/64),
+		e:     math.Sqrt(f * (2 - f)),
+		alpha: [3]float64{n/2 - 2*n2/3 + 5*n3/16, 13*n2/48 - 3*n3/5, 61 * n3 / 240},
+		beta:  [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480},
+	}
+}
+
+func (p *utm) Forward(lon, lat float64) (float64, float64) {
+	phi, lam := radians(lat), radians(lon)-p.lon0
+	sin := math.Sin(phi)
+	t := math.Sinh(math.Atanh(sin) - p.e*math.Atanh(p.e*sin))
+	xi := math.Atan2(t, math.Cos(lam))
+	eta := math.Atanh(math.Sin(lam) / math.Sqrt(1+t*t))
+
+	x, y := eta, xi
+	for j, a := range p.alpha {
+		k := float64(2 * (j + 1))
+		x += a * math.Cos(k*xi) * math.Sinh(k*eta)
+		y += a * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	return utmEasting + p.a*x, p.a * y
+}
+
+func (p *utm) Inverse(x, y float64) (float64, float64) {
+	xi, eta := y/p.a, (x-utmEasting)/p.a
+	xi1, eta1 := xi, eta
+	for j, b := range p.beta {
+		k := float64(2 * (j + 1))
+		xi1 -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta1 -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xi1) / math.Cosh(eta1))
+	// solve for the latitude with conformal latitude chi by fixed point
+	// iteration, which converges to double precision in a few steps
+	phi := chi
+	for i := 0; i < 10; i++ {
+		sin := p.e * math.Sin(phi)
+		next := 2*math.Atan(math.Tan(math.Pi/4+chi/2)*math.Pow((1+sin)/(1-sin), p.e/2)) - math.Pi/2
+		if math.Abs(next-phi) < 1e-15 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+	lam := math.Atan2(math.Sinh(eta1), math.Cos(xi1))
+	return degrees(p.lon0 + lam), degrees(phi)
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProjectionFor(t *testing.T) {
+	cases := []struct {
+		crs string
+		err string
+	}{
+		{"EPSG:4326", ""},
+		{"EPSG:3857", ""},
+		{"EPSG:25832", ""},
+		{"epsg:32633", ""},
+		{"EPSG:31467", `unsupported CRS "EPSG:31467"`},
+		{"WGS84", `invalid CRS "WGS84", must be of the form EPSG:<code>`},
+	}
+	for _, c := range cases {
+		_, err := ProjectionFor(c.crs)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%q error mismatch. expected: %q, got: %v", c.crs, c.err, err)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	cases := []struct {
+		from, to   string
+		x, y       float64
+		expX, expY float64
+		tolerance  float64
+	}{
+		// Stuttgart
+		{EPSG4326, EPSG25832, 9.18, 48.78, 513223.54, 5403015.52, 0.01},
+		// central meridian of zone 32 on the equator
+		{EPSG4326, EPSG25832, 9, 0, 500000, 0, 0.001},
+		// Berlin, outside zone 32
+		{EPSG4326, EPSG25832, 13.4, 52.5, 798609.52, 5825756.24, 0.05},
+		{EPSG4326, EPSG3857, 180, 0, 20037508.34, 0, 0.01},
+		{EPSG25832, EPSG4326, 513223.54, 5403015.52, 9.18, 48.78, 1e-7},
+		{EPSG4326, EPSG4326, 9.18, 48.78, 9.18, 48.78, 0},
+	}
+	for _, c := range cases {
+		tr, err := NewTransformer(c.from, c.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := tr.Transform(c.x, c.y)
+		if math.Abs(x-c.expX) > c.tolerance || math.Abs(y-c.expY) > c.tolerance {
+			t.Errorf("%s -> %s (%v, %v) mismatch. expected: (%v, %v), got: (%v, %v)", c.from, c.to, c.x, c.y, c.expX, c.expY, x, y)
+		}
+	}
+}
+
+func TestTransformRoundTrip(t *testing.T) {
+	for _, crs := range []string{EPSG25832, "EPSG:32632", EPSG3857} {
+		to, err := NewTransformer(EPSG4326, crs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := NewTransformer(crs, EPSG4326)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range [][2]float64{{6, 47.3}, {9.18, 48.78}, {12, 55}, {5.9, 54.9}, {14.9, 50.1}} {
+			lon, lat := back.Transform(to.Transform(p[0], p[1]))
+			if math.Abs(lon-p[0]) > 1e-9 || math.Abs(lat-p[1]) > 1e-9 {
+				t.Errorf("%s round trip (%v, %v) mismatch. got: (%v, %v)", crs, p[0], p[1], lon, lat)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package geo
+
+import (
+	"fmt"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// ReprojectReader converts a pair of coordinate columns of each entry from
+// the CRS of a source structure to another CRS
+type ReprojectReader struct {
+	r      dsio.EntryReader
+	st     *dataset.Structure
+	t      *Transformer
+	x, y   string
+	xi, yi int
+	read   int
+}
+
+var _ dsio.EntryReader = (*ReprojectReader)(nil)
+
+// NewReprojectReader creates a reader converting the xCol & yCol columns of
+// r to CRS to. For geographic coordinates xCol holds longitude & yCol
+// latitude. The source CRS is read from the structure of r. Array rows
+// require a tabular schema to locate columns, object rows are read by key.
+// Entries with a null or empty coordinate are passed through unchanged
+func NewReprojectReader(r dsio.EntryReader, to, xCol, yCol string) (*ReprojectReader, error) {
+	st := r.Structure()
+	if st == nil || st.CRS == "" {
+		return nil, fmt.Errorf("reproject: source structure has no CRS")
+	}
+	t, err := NewTransformer(st.CRS, to)
+	if err != nil {
+		return nil, fmt.Errorf("reproject: %w", err)
+	}
+
+	rr := &ReprojectReader{r: r, t: t, x: xCol, y: yCol, xi: -1, yi: -1}
+	if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+		titles := cols.Titles()
+		for i, title := range titles {
+			switch title {
+			case xCol:
+				rr.xi = i
+			case yCol:
+				rr.yi = i
+			}
+		}
+		if rr.xi < 0 {
+			return nil, fmt.Errorf("reproject: column %q not found", xCol)
+		}
+		if rr.yi < 0 {
+			return nil, fmt.Errorf("reproject: column %q not found", yCol)
+		}
+	}
+
+	// values of the reprojected body differ, so values derived from the
+	// source body no longer apply. entries are read one to one
+	rr.st = st.Clone()
+	rr.st.CRS = to
+	rr.st.Path = ""
+	rr.st.Checksum = ""
+	rr.st.Length = 0
+	rr.st.ErrCount = 0
+	return rr, nil
+}
+
+// Structure gives the structure of reprojected entries, which differs from
+// the source in CRS & has no values derived from the source body
+func (r *ReprojectReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads & reprojects the next entry of the source
+func (r *ReprojectReader) ReadEntry() (dsio.Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.read
+	r.read++
+
+	switch v := ent.Value.(type) {
+	case []interface{}:
+		if r.xi < 0 {
+			return ent, fmt.Errorf("reproject: entry %d: array rows require a tabular schema", i)
+		}
+		if r.xi >= len(v) || r.yi >= len(v) {
+			return ent, fmt.Errorf("reproject: entry %d: row has %d columns", i, len(v))
+		}
+		x, y, ok, err := r.transform(i, v[r.xi], v[r.yi])
+		if err != nil || !ok {
+			return ent, err
+		}
+		row := make([]interface{}, len(v))
+		copy(row, v)
+		row[r.xi], row[r.yi] = x, y
+		ent.Value = row
+	case map[string]interface{}:
+		x, y, ok, err := r.transform(i, v[r.x], v[r.y])
+		if err != nil || !ok {
+			return ent, err
+		}
+		obj := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			obj[key] = val
+		}
+		obj[r.x], obj[r.y] = x, y
+		ent.Value = obj
+	default:
+		return ent, fmt.Errorf("reproject: entry %d: expected an array or object, got %T", i, ent.Value)
+	}
+	return ent, nil
+}
+
+// transform converts a coordinate pair, reporting false if either is null or
+// empty
+func (r *ReprojectReader) transform(i int, xv, yv interface{}) (x, y float64, ok bool, err error) {
+	if xv == nil || yv == nil || xv == "" || yv == "" {
+		return 0, 0, false, nil
+	}
+	if x, err = number(xv); err != nil {
+		return 0, 0, false, fmt.Errorf("reproject: entry %d: column %q: %w", i, r.x, err)
+	}
+	if y, err = number(yv); err != nil {
+		return 0, 0, false, fmt.Errorf("reproject: entry %d: column %q: %w", i, r.y, err)
+	}
+	x, y = r.t.Transform(x, y)
+	return x, y, true, nil
+}
+
+func number(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", v)
+}
+
+// Close closes the source reader
+func (r *ReprojectReader) Close() error {
+	return r.r.Close()
+}
@@ -0,0 +1,121 @@
+package geo
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func stopsReader(t *testing.T, crs, data string) dsio.EntryReader {
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		CRS:          crs,
+		Path:         "/mem/QmStops",
+		Checksum:     "QmStopsSum",
+		Length:       len(data),
+		Entries:      2,
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "lon", "type": []interface{}{"number", "null"}},
+					map[string]interface{}{"title": "lat", "type": []interface{}{"number", "null"}},
+				},
+			},
+		},
+	}
+	r, err := dsio.NewCSVReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestReprojectReader(t *testing.T) {
+	r, err := NewReprojectReader(stopsReader(t, EPSG4326, "name,lon,lat\nStuttgart Hbf,9.18,48.78\nunknown,,\n"), EPSG25832, "lon", "lat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Structure().CRS != EPSG25832 {
+		t.Errorf("structure CRS mismatch. expected: %q, got: %q", EPSG25832, r.Structure().CRS)
+	}
+	if st := r.Structure(); st.Path != "" || st.Checksum != "" || st.Length != 0 || st.Entries != 2 {
+		t.Errorf("expected values derived from the source body to be cleared, got: %#v", st)
+	}
+
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := ent.Value.([]interface{})
+	if row[0] != "Stuttgart Hbf" {
+		t.Errorf("name mismatch. got: %v", row[0])
+	}
+	if x, y := row[1].(float64), row[2].(float64); math.Abs(x-513223.54) > 0.01 || math.Abs(y-5403015.52) > 0.01 {
+		t.Errorf("coordinate mismatch. got: (%v, %v)", x, y)
+	}
+
+	ent, err = r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row := ent.Value.([]interface{}); row[1] != "" || row[2] != "" {
+		t.Errorf("expected empty coordinates to pass through, got: %v", row)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReprojectReaderObjects(t *testing.T) {
+	st := &dataset.Structure{Format: "json", CRS: EPSG25832, Schema: dataset.BaseSchemaObject}
+	src, err := dsio.NewJSONReader(st, bytes.NewBufferString(`{"a":{"x":513223.54,"y":5403015.52,"name":"Stuttgart Hbf"},"b":{"x":"far","y":0}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReprojectReader(src, EPSG4326, "x", "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ent, err := r.ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := ent.Value.(map[string]interface{})
+	if x, y := obj["x"].(float64), obj["y"].(float64); math.Abs(x-9.18) > 1e-7 || math.Abs(y-48.78) > 1e-7 {
+		t.Errorf("coordinate mismatch. got: (%v, %v)", x, y)
+	}
+	if obj["name"] != "Stuttgart Hbf" {
+		t.Errorf("name mismatch. got: %v", obj["name"])
+	}
+
+	expect := `reproject: entry 1: column "x": expected a number, got string`
+	if _, err := r.ReadEntry(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+}
+
+func TestNewReprojectReaderErrors(t *testing.T) {
+	cases := []struct {
+		crs, to, x, y string
+		err           string
+	}{
+		{"", EPSG25832, "lon", "lat", "reproject: source structure has no CRS"},
+		{EPSG4326, "EPSG:31467", "lon", "lat", `reproject: unsupported CRS "EPSG:31467"`},
+		{EPSG4326, EPSG25832, "x", "lat", `reproject: column "x" not found`},
+		{EPSG4326, EPSG25832, "lon", "y", `reproject: column "y" not found`},
+	}
+	for i, c := range cases {
+		_, err := NewReprojectReader(stopsReader(t, c.crs, "name,lon,lat\n"), c.to, c.x, c.y)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
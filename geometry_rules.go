@@ -0,0 +1,71 @@
+package dataset
+
+import "fmt"
+
+// GeometryRules configure validation of coordinates in a geospatial body.
+// Coordinates are always checked to be finite & in range, and polygon rings
+// to be closed
+type GeometryRules struct {
+	// BBox constrains all coordinates to a bounding box in GeoJSON order:
+	// [west, south, east, north] in WGS84 decimal degrees. west may be greater
+	// than east for boxes that cross the antimeridian
+	BBox []float64 `json:"bbox,omitempty"`
+	// Geometry names a column holding GeoJSON geometry objects
+	Geometry string `json:"geometry,omitempty"`
+	// Winding requires polygon exterior rings to wind "ccw" (counterclockwise,
+	// as RFC 7946 recommends) or "cw", with holes winding the opposite way.
+	// Empty accepts either
+	Winding string `json:"winding,omitempty"`
+	// X & Y name a pair of columns holding point coordinates: longitude &
+	// latitude for geographic bodies, easting & northing for projected ones
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+}
+
+// Polygon ring winding orders
+const (
+	WindingCCW = "ccw"
+	WindingCW  = "cw"
+)
+
+// IsEmpty checks to see if geometry rules have no values set
+func (g *GeometryRules) IsEmpty() bool {
+	return g.BBox == nil && g.Geometry == "" && g.Winding == "" && g.X == "" && g.Y == ""
+}
+
+// Clone returns a deep copy of geometry rules
+func (g *GeometryRules) Clone() *GeometryRules {
+	if g == nil {
+		return nil
+	}
+	c := &GeometryRules{
+		Geometry: g.Geometry,
+		Winding:  g.Winding,
+		X:        g.X,
+		Y:        g.Y,
+	}
+	if g.BBox != nil {
+		c.BBox = make([]float64, len(g.BBox))
+		copy(c.BBox, g.BBox)
+	}
+	return c
+}
+
+// Validate checks geometry rules are well formed
+func (g *GeometryRules) Validate() error {
+	if (g.X == "") != (g.Y == "") {
+		return fmt.Errorf("x & y columns must be set together")
+	}
+	if g.X == "" && g.Geometry == "" {
+		return fmt.Errorf("one of x & y or geometry columns is required")
+	}
+	switch g.Winding {
+	case "", WindingCCW, WindingCW:
+	default:
+		return fmt.Errorf("invalid winding %q, must be %q or %q", g.Winding, WindingCCW, WindingCW)
+	}
+	if g.BBox != nil {
+		return (&SpatialCoverage{BBox: g.BBox}).Validate()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeometryRulesValidate(t *testing.T) {
+	cases := []struct {
+		g   *GeometryRules
+		err string
+	}{
+		{&GeometryRules{X: "lon", Y: "lat"}, ""},
+		{&GeometryRules{Geometry: "geom", Winding: WindingCCW, BBox: []float64{5.8, 47.2, 15.1, 55.1}}, ""},
+		{&GeometryRules{BBox: []float64{170, -50, -170, -30}, X: "x", Y: "y"}, ""},
+		{&GeometryRules{}, "one of x & y or geometry columns is required"},
+		{&GeometryRules{X: "lon"}, "x & y columns must be set together"},
+		{&GeometryRules{Geometry: "geom", Winding: "left"}, `invalid winding "left", must be "ccw" or "cw"`},
+		{&GeometryRules{Geometry: "geom", BBox: []float64{5.8, 47.2}}, "bbox must have 4 values, got 2"},
+		{&GeometryRules{Geometry: "geom", BBox: []float64{5.8, 95, 15.1, 55.1}}, "bbox index 1: latitude 95 out of range"},
+	}
+	for i, c := range cases {
+		err := c.g.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestGeometryRulesClone(t *testing.T) {
+	var nilRules *GeometryRules
+	if nilRules.Clone() != nil {
+		t.Error("expected clone of nil rules to be nil")
+	}
+
+	g := &GeometryRules{BBox: []float64{5.8, 47.2, 15.1, 55.1}, Geometry: "geom", Winding: WindingCW, X: "x", Y: "y"}
+	c := g.Clone()
+	if !reflect.DeepEqual(g, c) {
+		t.Errorf("clone mismatch. expected: %v, got: %v", g, c)
+	}
+	c.BBox[0] = 0
+	if g.BBox[0] != 5.8 {
+		t.Error("expected clone bbox to be a copy")
+	}
+	if !(&GeometryRules{}).IsEmpty() || c.IsEmpty() {
+		t.Error("IsEmpty mismatch")
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/qri-io/dataset
 
-go 1.12
+go 1.13
 
 // override with corret timestamps for circleci: https://github.com/qri-io/qri/pull/865/files
 replace (
@@ -32,4 +32,5 @@ require (
 	github.com/yudai/gojsondiff v1.0.0
 	github.com/yudai/golcs v0.0.0-20170316035057-ecda9a501e82 // indirect
 	github.com/yudai/pp v2.0.1+incompatible // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
@@ -1,7 +1,6 @@
 package dataset
 
 import (
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 
@@ -9,31 +8,75 @@ import (
 	"github.com/multiformats/go-multihash"
 )
 
+const (
+	// HashFuncSHA2256 is the multihash name of SHA-256
+	HashFuncSHA2256 = "sha2-256"
+	// HashFuncBlake2b256 is the multihash name of BLAKE2b with a 256 bit
+	// digest
+	HashFuncBlake2b256 = "blake2b-256"
+	// DefaultHashFunc is the hash function used when none is configured
+	DefaultHashFunc = HashFuncSHA2256
+)
+
+// hashFuncCodes maps supported hash function names to multihash codes
+var hashFuncCodes = map[string]uint64{
+	HashFuncSHA2256:    multihash.SHA2_256,
+	HashFuncBlake2b256: multihash.BLAKE2B_MIN + 31,
+}
+
+// HashConfig configures hashing
+type HashConfig struct {
+	// Func is the multihash name of the hash function, one of HashFuncSHA2256
+	// or HashFuncBlake2b256. Defaults to DefaultHashFunc
+	Func string
+}
+
+// WithHashFunc sets the hash function by multihash name
+func WithHashFunc(name string) func(*HashConfig) {
+	return func(c *HashConfig) {
+		c.Func = name
+	}
+}
+
+// NewHashConfig applies opts to the default hash config, checking the
+// configured hash function is supported
+func NewHashConfig(opts ...func(*HashConfig)) (*HashConfig, error) {
+	cfg := &HashConfig{Func: DefaultHashFunc}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if _, ok := hashFuncCodes[cfg.Func]; !ok {
+		return nil, fmt.Errorf("unsupported hash function %q", cfg.Func)
+	}
+	return cfg, nil
+}
+
 // JSONHash calculates the hash of a json.Marshaler
 // It's important to note that this is *NOT* the same as an IPFS hash,
 // These hash functions should be used for other things like
 // checksumming, in-memory content-addressing, etc.
-func JSONHash(m json.Marshaler) (hash string, err error) {
+func JSONHash(m json.Marshaler, opts ...func(*HashConfig)) (hash string, err error) {
 	// marshal to cannoncical JSON representation
 	data, err := m.MarshalJSON()
 	if err != nil {
 		return
 	}
-	return HashBytes(data)
+	return HashBytes(data, opts...)
 }
 
-// HashBytes generates the base-58 encoded SHA-256 hash of a byte slice
+// HashBytes generates the base-58 encoded multihash of a byte slice, using
+// SHA-256 unless another hash function is configured. The multihash prefix
+// records which function produced the hash, see HashFunc
 // It's important to note that this is *NOT* the same as an IPFS hash,
 // These hash functions should be used for other things like
 // checksumming, in-memory content-addressing, etc.
-func HashBytes(data []byte) (hash string, err error) {
-	h := sha256.New()
-
-	if _, err = h.Write(data); err != nil {
+func HashBytes(data []byte, opts ...func(*HashConfig)) (hash string, err error) {
+	cfg, err := NewHashConfig(opts...)
+	if err != nil {
 		return
 	}
 
-	mhBuf, err := multihash.Encode(h.Sum(nil), multihash.SHA2_256)
+	mhBuf, err := multihash.Sum(data, hashFuncCodes[cfg.Func], -1)
 	if err != nil {
 		err = fmt.Errorf("error allocating multihash buffer: %s", err.Error())
 		return
@@ -42,3 +85,16 @@ func HashBytes(data []byte) (hash string, err error) {
 	hash = base58.Encode(mhBuf)
 	return
 }
+
+// HashFunc reads the multihash name of the function that produced a hash
+func HashFunc(hash string) (string, error) {
+	buf, err := base58.Decode(hash)
+	if err != nil {
+		return "", fmt.Errorf("invalid hash %q: %w", hash, err)
+	}
+	dec, err := multihash.Decode(buf)
+	if err != nil {
+		return "", fmt.Errorf("invalid hash %q: %w", hash, err)
+	}
+	return dec.Name, nil
+}
@@ -26,3 +26,42 @@ func TestHashBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestHashBytesFunc(t *testing.T) {
+	cases := []struct {
+		fn  string
+		out string
+		err string
+	}{
+		{"", "", "unsupported hash function \"\""},
+		{HashFuncSHA2256, "QmdfTbBqBPQ7VNxZEYEj14VmRuZBkqFbiwReogJgS1zR1n", ""},
+		{HashFuncBlake2b256, "2Drjgb5DseoVAvRLngcVmd4YfJAi3J1145kiNFV3CL32Hs6vzb", ""},
+		{"md5", "", "unsupported hash function \"md5\""},
+	}
+
+	for _, c := range cases {
+		got, err := HashBytes([]byte(""), WithHashFunc(c.fn))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %q error mismatch. expected: '%s', got: '%s'", c.fn, c.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got != c.out {
+			t.Errorf("case %q result mismatch. expected: %s got: %s", c.fn, c.out, got)
+		}
+		fn, err := HashFunc(got)
+		if err != nil {
+			t.Errorf("case %q unexpected error reading hash function: %s", c.fn, err)
+			continue
+		}
+		if fn != c.fn {
+			t.Errorf("case %q expected hash to record it's function, got: %s", c.fn, fn)
+		}
+	}
+
+	if _, err := HashFunc("not a hash"); err == nil {
+		t.Errorf("expected an invalid hash to error")
+	}
+}
@@ -0,0 +1,203 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// IdentifierSchemeDOI is the scheme for Digital Object Identifiers
+	IdentifierSchemeDOI = "doi"
+	// IdentifierSchemeURN is the scheme for Uniform Resource Names (RFC 8141)
+	IdentifierSchemeURN = "urn"
+	// IdentifierSchemeLocal is the scheme for identifiers that are only
+	// meaningful within a publisher's own systems
+	IdentifierSchemeLocal = "local"
+)
+
+var (
+	doiPattern = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+	urnPattern = regexp.MustCompile(`^(?i)urn:[a-z0-9][a-z0-9-]{0,31}:\S+$`)
+)
+
+// doiPrefixes are common ways of writing a DOI as a URI, stripped when
+// normalizing
+var doiPrefixes = []string{
+	"https://doi.org/",
+	"http://doi.org/",
+	"https://dx.doi.org/",
+	"http://dx.doi.org/",
+	"doi:",
+}
+
+// Identifier is a typed, persistent identifier for a dataset
+type Identifier struct {
+	// Scheme of the identifier, one of "doi", "urn" or "local"
+	Scheme string `json:"scheme"`
+	// Value is the identifier itself, without any resolver prefix. eg.
+	// "10.5281/zenodo.1234" for a DOI
+	Value string `json:"value"`
+	// Agency is the organization that issued a local identifier
+	Agency string `json:"agency,omitempty"`
+}
+
+// ParseIdentifier detects the scheme of an identifier string, returning a
+// normalized Identifier. Strings that aren't recognizable DOIs or URNs are
+// treated as local identifiers
+func ParseIdentifier(s string) *Identifier {
+	s = strings.TrimSpace(s)
+	id := &Identifier{Scheme: IdentifierSchemeLocal, Value: s}
+	lower := strings.ToLower(s)
+	for _, prefix := range doiPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			id.Scheme = IdentifierSchemeDOI
+			break
+		}
+	}
+	if doiPattern.MatchString(s) {
+		id.Scheme = IdentifierSchemeDOI
+	} else if urnPattern.MatchString(s) {
+		id.Scheme = IdentifierSchemeURN
+	}
+	id.Normalize()
+	return id
+}
+
+// Decode reads json.Umarshal-style data into an Identifier. Plain strings are
+// parsed with ParseIdentifier
+func (id *Identifier) Decode(val interface{}) (err error) {
+	if s, ok := val.(string); ok {
+		*id = *ParseIdentifier(s)
+		return nil
+	}
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	if id.Scheme, err = strVal(msi["scheme"]); err != nil {
+		return
+	}
+	if id.Value, err = strVal(msi["value"]); err != nil {
+		return
+	}
+	if id.Agency, err = strVal(msi["agency"]); err != nil {
+		return
+	}
+	return
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting either
+// an identifier object or a plain string
+func (id *Identifier) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*id = Identifier{}
+	return id.Decode(v)
+}
+
+// Clone returns a copy of an identifier
+func (id *Identifier) Clone() *Identifier {
+	if id == nil {
+		return nil
+	}
+	c := *id
+	return &c
+}
+
+// Normalize converts an identifier to it's canonical form: schemes are
+// lower-cased, resolver prefixes are removed from DOIs, and URN namespace
+// identifiers are lower-cased. DOIs are case-insensitive, but case is
+// preserved for display
+func (id *Identifier) Normalize() {
+	id.Scheme = strings.ToLower(strings.TrimSpace(id.Scheme))
+	id.Value = strings.TrimSpace(id.Value)
+
+	switch id.Scheme {
+	case IdentifierSchemeDOI:
+		lower := strings.ToLower(id.Value)
+		for _, prefix := range doiPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				id.Value = id.Value[len(prefix):]
+				break
+			}
+		}
+	case IdentifierSchemeURN:
+		// the "urn:" prefix and namespace identifier are case-insensitive
+		parts := strings.SplitN(id.Value, ":", 3)
+		if len(parts) == 3 {
+			id.Value = strings.ToLower(parts[0]+":"+parts[1]) + ":" + parts[2]
+		}
+	}
+}
+
+// Validate checks an identifier's value is well-formed for its scheme
+func (id *Identifier) Validate() error {
+	if id.Value == "" {
+		return fmt.Errorf("value is required")
+	}
+	switch id.Scheme {
+	case IdentifierSchemeDOI:
+		if !doiPattern.MatchString(id.Value) {
+			return fmt.Errorf("invalid doi '%s'", id.Value)
+		}
+	case IdentifierSchemeURN:
+		if !urnPattern.MatchString(id.Value) {
+			return fmt.Errorf("invalid urn '%s'", id.Value)
+		}
+	case IdentifierSchemeLocal:
+	default:
+		return fmt.Errorf("unknown scheme '%s'", id.Scheme)
+	}
+	return nil
+}
+
+// URL returns a resolvable URL for the identifier, if one exists. DOIs
+// resolve through doi.org, other schemes return the empty string
+func (id *Identifier) URL() string {
+	if id.Scheme == IdentifierSchemeDOI {
+		return "https://doi.org/" + id.Value
+	}
+	return ""
+}
+
+// String returns the identifier as a single string, using the URL form
+// if one exists
+func (id *Identifier) String() string {
+	if u := id.URL(); u != "" {
+		return u
+	}
+	return id.Value
+}
+
+// SchemaOrg returns the identifier as a schema.org PropertyValue, for use as
+// the "identifier" property of a schema.org Dataset
+func (id *Identifier) SchemaOrg() map[string]interface{} {
+	pv := map[string]interface{}{
+		"@type":      "PropertyValue",
+		"propertyID": strings.ToUpper(id.Scheme),
+		"value":      id.Value,
+	}
+	if u := id.URL(); u != "" {
+		pv["url"] = u
+	}
+	return pv
+}
+
+// DCAT returns the identifier as a DCAT-AP adms:Identifier, for use as an
+// adms:identifier of a dcat:Dataset
+func (id *Identifier) DCAT() map[string]interface{} {
+	adms := map[string]interface{}{
+		"@type":         "adms:Identifier",
+		"skos:notation": id.String(),
+	}
+	if id.Agency != "" {
+		adms["adms:schemaAgency"] = id.Agency
+	} else if id.Scheme != IdentifierSchemeLocal {
+		adms["adms:schemaAgency"] = strings.ToUpper(id.Scheme)
+	}
+	return adms
+}
@@ -0,0 +1,103 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect *Identifier
+	}{
+		{"10.5281/zenodo.1234", &Identifier{Scheme: "doi", Value: "10.5281/zenodo.1234"}},
+		{"doi:10.5281/zenodo.1234", &Identifier{Scheme: "doi", Value: "10.5281/zenodo.1234"}},
+		{"https://doi.org/10.5281/ZENODO.1234", &Identifier{Scheme: "doi", Value: "10.5281/ZENODO.1234"}},
+		{"http://dx.doi.org/10.5281/zenodo.1234", &Identifier{Scheme: "doi", Value: "10.5281/zenodo.1234"}},
+		{"URN:NBN:de:101-2019", &Identifier{Scheme: "urn", Value: "urn:nbn:de:101-2019"}},
+		{" gtfs-de-2019 ", &Identifier{Scheme: "local", Value: "gtfs-de-2019"}},
+	}
+
+	for i, c := range cases {
+		got := ParseIdentifier(c.in)
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d '%s' mismatch. expected: %#v, got: %#v", i, c.in, c.expect, got)
+		}
+	}
+}
+
+func TestIdentifierValidate(t *testing.T) {
+	cases := []struct {
+		id  *Identifier
+		err string
+	}{
+		{&Identifier{Scheme: "doi", Value: "10.5281/zenodo.1234"}, ""},
+		{&Identifier{Scheme: "urn", Value: "urn:nbn:de:101-2019"}, ""},
+		{&Identifier{Scheme: "local", Value: "anything goes"}, ""},
+		{&Identifier{Scheme: "doi"}, "value is required"},
+		{&Identifier{Scheme: "doi", Value: "https://doi.org/10.5281/zenodo.1234"}, "invalid doi 'https://doi.org/10.5281/zenodo.1234'"},
+		{&Identifier{Scheme: "urn", Value: "nbn:de:101"}, "invalid urn 'nbn:de:101'"},
+		{&Identifier{Scheme: "isbn", Value: "978-3-16-148410-0"}, "unknown scheme 'isbn'"},
+	}
+
+	for i, c := range cases {
+		err := c.id.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestIdentifierExports(t *testing.T) {
+	doi := &Identifier{Scheme: "doi", Value: "10.5281/zenodo.1234"}
+	local := &Identifier{Scheme: "local", Value: "gtfs-de", Agency: "MFDZ"}
+
+	if doi.String() != "https://doi.org/10.5281/zenodo.1234" {
+		t.Errorf("unexpected doi string: %s", doi.String())
+	}
+	if local.String() != "gtfs-de" {
+		t.Errorf("unexpected local string: %s", local.String())
+	}
+
+	expect := map[string]interface{}{
+		"@type":      "PropertyValue",
+		"propertyID": "DOI",
+		"value":      "10.5281/zenodo.1234",
+		"url":        "https://doi.org/10.5281/zenodo.1234",
+	}
+	if got := doi.SchemaOrg(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("schema.org mismatch. expected: %v, got: %v", expect, got)
+	}
+
+	expect = map[string]interface{}{
+		"@type":             "adms:Identifier",
+		"skos:notation":     "gtfs-de",
+		"adms:schemaAgency": "MFDZ",
+	}
+	if got := local.DCAT(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("dcat mismatch. expected: %v, got: %v", expect, got)
+	}
+}
+
+func TestMetaIdentifiersJSON(t *testing.T) {
+	md := &Meta{}
+	data := []byte(`{"qri":"md:0","identifiers":["doi:10.5281/zenodo.1234",{"scheme":"local","value":"gtfs-de"}]}`)
+	if err := json.Unmarshal(data, md); err != nil {
+		t.Fatal(err)
+	}
+	expect := []*Identifier{
+		{Scheme: "doi", Value: "10.5281/zenodo.1234"},
+		{Scheme: "local", Value: "gtfs-de"},
+	}
+	if !reflect.DeepEqual(expect, md.Identifiers) {
+		t.Errorf("identifiers mismatch. expected: %v, got: %v", expect, md.Identifiers)
+	}
+	if md.Meta()["identifiers"] != nil {
+		t.Errorf("identifiers should not be hoisted into arbitrary metadata")
+	}
+
+	if err := md.Set("identifiers", []interface{}{0}); err == nil || err.Error() != "parsing identifiers index 0: expected map[string]interface{}" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package dataset
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 // CurrentSpecVersion is the current verion of the dataset spec
@@ -23,6 +24,16 @@ const (
 	KindViz = Kind("vz:" + CurrentSpecVersion)
 	// KindReadme is the current kind for dataset readme
 	KindReadme = Kind("rm:" + CurrentSpecVersion)
+	// KindProvenance is the current kind for dataset provenance
+	KindProvenance = Kind("pv:" + CurrentSpecVersion)
+	// KindPreview is the current kind for dataset body previews
+	KindPreview = Kind("pr:" + CurrentSpecVersion)
+	// KindStats is the current kind for dataset body stats
+	KindStats = Kind("sa:" + CurrentSpecVersion)
+	// KindTemplate is the current kind for dataset templates
+	KindTemplate = Kind("tp:" + CurrentSpecVersion)
+	// KindCatalog is the current kind for dataset catalogs
+	KindCatalog = Kind("ct:" + CurrentSpecVersion)
 )
 
 // Kind is a short identifier for all types of qri dataset objects
@@ -38,24 +49,107 @@ func (k Kind) String() string {
 	return string(k)
 }
 
-// Valid checks to see if a kind string is valid
+// kindComponents maps kind type identifiers to the component they describe
+var kindComponents = map[string]string{
+	KindDataset.Type():    "dataset",
+	KindMeta.Type():       "meta",
+	KindStructure.Type():  "structure",
+	KindTransform.Type():  "transform",
+	KindCommit.Type():     "commit",
+	KindViz.Type():        "viz",
+	KindReadme.Type():     "readme",
+	KindProvenance.Type(): "provenance",
+	KindPreview.Type():    "preview",
+	KindStats.Type():      "stats",
+	KindTemplate.Type():   "template",
+	KindCatalog.Type():    "catalog",
+}
+
+// ParseKind reads a kind string, returning an error if the string isn't in
+// the form [type]:[version]
+func ParseKind(s string) (Kind, error) {
+	k := Kind(s)
+	if err := k.Valid(); err != nil {
+		return "", err
+	}
+	return k, nil
+}
+
+// Valid checks to see if a kind string is valid. A valid kind is a two-letter
+// lowercase type identifier, a colon, and a version number. Unrecognized
+// types & versions are valid, so documents written by newer versions of the
+// spec can still be decoded
 func (k Kind) Valid() error {
-	if len(k) < 4 {
-		return fmt.Errorf("invalid kind: '%s'. kind must be in the form [type]:[version]", k.String())
+	s := k.String()
+	if len(s) < 4 || s[2] != ':' {
+		return fmt.Errorf("invalid kind: '%s'. kind must be in the form [type]:[version]", s)
+	}
+	for _, r := range s[:2] {
+		if r < 'a' || r > 'z' {
+			return fmt.Errorf("invalid kind: '%s'. type must be two lowercase letters", s)
+		}
+	}
+	for _, r := range s[3:] {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("invalid kind: '%s'. version must be a number", s)
+		}
 	}
 	return nil
 }
 
 // Type returns the type identifier
 func (k Kind) Type() string {
+	if len(k) < 2 {
+		return ""
+	}
 	return k.String()[:2]
 }
 
 // Version returns the version portion of the kind identifier
 func (k Kind) Version() string {
+	if len(k) < 3 {
+		return ""
+	}
 	return k.String()[3:]
 }
 
+// VersionNumber returns the version of the kind as an integer, -1 if the kind
+// is invalid
+func (k Kind) VersionNumber() int {
+	if k.Valid() != nil {
+		return -1
+	}
+	v, err := strconv.Atoi(k.Version())
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+// Component returns the name of the component a kind describes, eg.
+// "structure" for "st:0". Returns an empty string for invalid kinds &
+// unrecognized types
+func (k Kind) Component() string {
+	if k.Valid() != nil {
+		return ""
+	}
+	return kindComponents[k.Type()]
+}
+
+// IsCurrent reports whether a kind is at the current spec version
+func (k Kind) IsCurrent() bool {
+	return k.Valid() == nil && k.Version() == CurrentSpecVersion
+}
+
+// checkKind validates the qri kind of a decoded component. Components may
+// omit their kind
+func checkKind(qri string) error {
+	if qri == "" {
+		return nil
+	}
+	return Kind(qri).Valid()
+}
+
 // UnmarshalJSON implements the JSON.Unmarshaler interface,
 // rejecting any strings that are not a valid kind
 func (k *Kind) UnmarshalJSON(data []byte) error {
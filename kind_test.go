@@ -17,6 +17,11 @@ func TestKindValid(t *testing.T) {
 		{"as:0", ""},
 		{"ps:0", ""},
 		{"ps:0", ""},
+		{"ds:12", ""},
+		{"ds-0", "invalid kind: 'ds-0'. kind must be in the form [type]:[version]"},
+		{"dst:0", "invalid kind: 'dst:0'. kind must be in the form [type]:[version]"},
+		{"DS:0", "invalid kind: 'DS:0'. type must be two lowercase letters"},
+		{"ds:v1", "invalid kind: 'ds:v1'. version must be a number"},
 	}
 
 	for i, c := range cases {
@@ -75,6 +80,7 @@ func TestKindUnmarshalJSON(t *testing.T) {
 	}{
 		{`"st:2"`, Kind("st:2"), ""},
 		{`""`, Kind(""), "invalid kind: ''. kind must be in the form [type]:[version]"},
+		{`"st:two"`, Kind("st:two"), "invalid kind: 'st:two'. version must be a number"},
 	}
 
 	for i, c := range cases {
@@ -90,3 +96,82 @@ func TestKindUnmarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestComponentKindUnmarshalJSON(t *testing.T) {
+	components := map[string]func() interface{}{
+		"catalog":    func() interface{} { return &Catalog{} },
+		"commit":     func() interface{} { return &Commit{} },
+		"dataset":    func() interface{} { return &Dataset{} },
+		"meta":       func() interface{} { return &Meta{} },
+		"preview":    func() interface{} { return &Preview{} },
+		"provenance": func() interface{} { return &Provenance{} },
+		"readme":     func() interface{} { return &Readme{} },
+		"stats":      func() interface{} { return &Stats{} },
+		"structure":  func() interface{} { return &Structure{} },
+		"template":   func() interface{} { return &Template{} },
+		"transform":  func() interface{} { return &Transform{} },
+		"viz":        func() interface{} { return &Viz{} },
+	}
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{`{}`, ""},
+		{`{"qri":"zz:1"}`, ""},
+		{`{"qri":"st"}`, "invalid kind: 'st'. kind must be in the form [type]:[version]"},
+		{`{"qri":"ST:0"}`, "invalid kind: 'ST:0'. type must be two lowercase letters"},
+		{`{"qri":"st:zero"}`, "invalid kind: 'st:zero'. version must be a number"},
+	}
+
+	for name, component := range components {
+		for i, c := range cases {
+			err := json.Unmarshal([]byte(c.input), component())
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("%s case %d error mismatch. expected: '%s', got: '%s'", name, i, c.err, err)
+			}
+		}
+	}
+
+	ds := &Dataset{}
+	if err := json.Unmarshal([]byte(`{"qri":"ds:0","structure":{"qri":"st"}}`), ds); err == nil {
+		t.Errorf("expected dataset with an invalid structure kind to error")
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	cases := []struct {
+		input     string
+		expect    Kind
+		component string
+		version   int
+		current   bool
+		err       string
+	}{
+		{"ds:0", KindDataset, "dataset", 0, true, ""},
+		{"st:2", Kind("st:2"), "structure", 2, false, ""},
+		{"rm:0", KindReadme, "readme", 0, true, ""},
+		{"zz:0", Kind("zz:0"), "", 0, true, ""},
+		{"ds", Kind(""), "", -1, false, "invalid kind: 'ds'. kind must be in the form [type]:[version]"},
+	}
+
+	for i, c := range cases {
+		got, err := ParseKind(c.input)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d response mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+		k := Kind(c.input)
+		if k.Component() != c.component {
+			t.Errorf("case %d component mismatch. expected: '%s', got: '%s'", i, c.component, k.Component())
+		}
+		if k.VersionNumber() != c.version {
+			t.Errorf("case %d version mismatch. expected: %d, got: %d", i, c.version, k.VersionNumber())
+		}
+		if k.IsCurrent() != c.current {
+			t.Errorf("case %d current mismatch. expected: %t, got: %t", i, c.current, k.IsCurrent())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Early versions of the dataset document described the query that produced a
+// dataset with three top level fields: queryString, querySyntax &
+// queryEngine. Queries are transforms, and these fields were superseded by
+// the Transform component. Decoding a document that still carries them
+// migrates their values into Transform:
+//
+// queryString becomes Transform.ScriptBytes, querySyntax becomes
+// Transform.Syntax, and queryEngine is recorded as Transform.Config["engine"]
+//
+// The legacy fields are never encoded. UnmarshalStrict rejects them, as they
+// aren't part of the current spec
+
+// LegacyQueryEngineConfigKey is the Transform config key the legacy
+// queryEngine field is migrated to
+const LegacyQueryEngineConfigKey = "engine"
+
+// legacyQuery holds the fields of a pre-transform query
+type legacyQuery struct {
+	QueryString string `json:"queryString"`
+	QuerySyntax string `json:"querySyntax"`
+	QueryEngine string `json:"queryEngine"`
+}
+
+func (lq legacyQuery) isEmpty() bool {
+	return lq.QueryString == "" && lq.QuerySyntax == "" && lq.QueryEngine == ""
+}
+
+// upgradeLegacyQuery migrates legacy query fields in a dataset document into
+// ds.Transform. It errors if ds already has a transform that conflicts with
+// the legacy query
+func upgradeLegacyQuery(ds *Dataset, data []byte) error {
+	if !bytes.Contains(data, []byte(`"query`)) {
+		return nil
+	}
+	lq := legacyQuery{}
+	if err := json.Unmarshal(data, &lq); err != nil {
+		return fmt.Errorf("legacy query: %s", err)
+	}
+	if lq.isEmpty() {
+		return nil
+	}
+
+	if ds.Transform == nil {
+		ds.Transform = &Transform{}
+	}
+	t := ds.Transform
+
+	if lq.QueryString != "" {
+		if t.ScriptPath != "" || (len(t.ScriptBytes) > 0 && string(t.ScriptBytes) != lq.QueryString) {
+			return fmt.Errorf("legacy query: queryString conflicts with transform script")
+		}
+		t.ScriptBytes = []byte(lq.QueryString)
+	}
+	if lq.QuerySyntax != "" {
+		if t.Syntax != "" && t.Syntax != lq.QuerySyntax {
+			return fmt.Errorf("legacy query: querySyntax %q conflicts with transform syntax %q", lq.QuerySyntax, t.Syntax)
+		}
+		t.Syntax = lq.QuerySyntax
+	}
+	if lq.QueryEngine != "" {
+		if t.Config == nil {
+			t.Config = map[string]interface{}{}
+		}
+		if engine, ok := t.Config[LegacyQueryEngineConfigKey]; ok && engine != lq.QueryEngine {
+			return fmt.Errorf("legacy query: queryEngine %q conflicts with transform config engine %v", lq.QueryEngine, engine)
+		}
+		t.Config[LegacyQueryEngineConfigKey] = lq.QueryEngine
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUpgradeLegacyQuery(t *testing.T) {
+	cases := []struct {
+		data   string
+		script string
+		syntax string
+		engine interface{}
+		err    string
+	}{
+		{`{"queryString":"select * from a","querySyntax":"sql","queryEngine":"postgres"}`, "select * from a", "sql", "postgres", ""},
+		{`{"querySyntax":"sql","transform":{"scriptBytes":"c2VsZWN0ICogZnJvbSBh"}}`, "select * from a", "sql", nil, ""},
+		{`{"queryString":"select * from a","transform":{"scriptBytes":"c2VsZWN0ICogZnJvbSBh","syntax":"sql"}}`, "select * from a", "sql", nil, ""},
+
+		{`{"queryString":"select 1","transform":{"scriptPath":"/ipfs/QmScript"}}`, "", "", nil, "legacy query: queryString conflicts with transform script"},
+		{`{"querySyntax":"sql","transform":{"syntax":"starlark"}}`, "", "", nil, `legacy query: querySyntax "sql" conflicts with transform syntax "starlark"`},
+		{`{"queryEngine":"postgres","transform":{"config":{"engine":"sqlite"}}}`, "", "", nil, `legacy query: queryEngine "postgres" conflicts with transform config engine sqlite`},
+	}
+
+	for i, c := range cases {
+		ds := &Dataset{}
+		err := json.Unmarshal([]byte(c.data), ds)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if ds.Transform == nil {
+			t.Errorf("case %d expected legacy query to create a transform", i)
+			continue
+		}
+		if string(ds.Transform.ScriptBytes) != c.script {
+			t.Errorf("case %d script mismatch. expected: %q, got: %q", i, c.script, ds.Transform.ScriptBytes)
+		}
+		if ds.Transform.Syntax != c.syntax {
+			t.Errorf("case %d syntax mismatch. expected: %q, got: %q", i, c.syntax, ds.Transform.Syntax)
+		}
+		if engine := ds.Transform.Config[LegacyQueryEngineConfigKey]; engine != c.engine {
+			t.Errorf("case %d engine mismatch. expected: %v, got: %v", i, c.engine, engine)
+		}
+	}
+
+	ds := &Dataset{}
+	if err := json.Unmarshal([]byte(`{"meta":{"title":"no query"}}`), ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Transform != nil {
+		t.Errorf("expected documents without legacy query fields to have no transform")
+	}
+
+	if err := UnmarshalStrict([]byte(`{"queryString":"select 1"}`), &Dataset{}); err == nil {
+		t.Errorf("expected strict decoding to reject legacy query fields")
+	}
+}
@@ -0,0 +1,127 @@
+package dataset
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SPDXLicense is an entry in the SPDX license list (https://spdx.org/licenses)
+type SPDXLicense struct {
+	// ID is the SPDX short identifier, eg. "CC-BY-4.0"
+	ID string
+	// Name is the full name of the license
+	Name string
+}
+
+// URL returns the SPDX reference page for a license
+func (l SPDXLicense) URL() string {
+	return "https://spdx.org/licenses/" + l.ID + ".html"
+}
+
+// spdxLicenses is a subset of the SPDX license list, covering licenses that
+// are commonly applied to data & software. It's only used for lookups, ids
+// that aren't listed are still valid license types
+var spdxLicenses = []SPDXLicense{
+	{"0BSD", "BSD Zero Clause License"},
+	{"AGPL-3.0-only", "GNU Affero General Public License v3.0 only"},
+	{"AGPL-3.0-or-later", "GNU Affero General Public License v3.0 or later"},
+	{"Apache-2.0", "Apache License 2.0"},
+	{"BSD-2-Clause", "BSD 2-Clause \"Simplified\" License"},
+	{"BSD-3-Clause", "BSD 3-Clause \"New\" or \"Revised\" License"},
+	{"CC-BY-3.0", "Creative Commons Attribution 3.0 Unported"},
+	{"CC-BY-3.0-DE", "Creative Commons Attribution 3.0 Germany"},
+	{"CC-BY-4.0", "Creative Commons Attribution 4.0 International"},
+	{"CC-BY-NC-4.0", "Creative Commons Attribution Non Commercial 4.0 International"},
+	{"CC-BY-NC-ND-4.0", "Creative Commons Attribution Non Commercial No Derivatives 4.0 International"},
+	{"CC-BY-NC-SA-4.0", "Creative Commons Attribution Non Commercial Share Alike 4.0 International"},
+	{"CC-BY-ND-4.0", "Creative Commons Attribution No Derivatives 4.0 International"},
+	{"CC-BY-SA-3.0", "Creative Commons Attribution Share Alike 3.0 Unported"},
+	{"CC-BY-SA-3.0-DE", "Creative Commons Attribution Share Alike 3.0 Germany"},
+	{"CC-BY-SA-4.0", "Creative Commons Attribution Share Alike 4.0 International"},
+	{"CC0-1.0", "Creative Commons Zero v1.0 Universal"},
+	{"CDLA-Permissive-1.0", "Community Data License Agreement Permissive 1.0"},
+	{"CDLA-Permissive-2.0", "Community Data License Agreement Permissive 2.0"},
+	{"CDLA-Sharing-1.0", "Community Data License Agreement Sharing 1.0"},
+	{"DL-DE-BY-2.0", "Data licence Germany – attribution – version 2.0"},
+	{"DL-DE-ZERO-2.0", "Data licence Germany – zero – version 2.0"},
+	{"EUPL-1.2", "European Union Public License 1.2"},
+	{"GPL-2.0-only", "GNU General Public License v2.0 only"},
+	{"GPL-2.0-or-later", "GNU General Public License v2.0 or later"},
+	{"GPL-3.0-only", "GNU General Public License v3.0 only"},
+	{"GPL-3.0-or-later", "GNU General Public License v3.0 or later"},
+	{"ISC", "ISC License"},
+	{"LGPL-2.1-only", "GNU Lesser General Public License v2.1 only"},
+	{"LGPL-2.1-or-later", "GNU Lesser General Public License v2.1 or later"},
+	{"LGPL-3.0-only", "GNU Lesser General Public License v3.0 only"},
+	{"LGPL-3.0-or-later", "GNU Lesser General Public License v3.0 or later"},
+	{"MIT", "MIT License"},
+	{"MPL-2.0", "Mozilla Public License 2.0"},
+	{"ODC-By-1.0", "Open Data Commons Attribution License v1.0"},
+	{"ODbL-1.0", "Open Data Commons Open Database License v1.0"},
+	{"OGL-UK-3.0", "Open Government Licence v3.0"},
+	{"PDDL-1.0", "Open Data Commons Public Domain Dedication & License 1.0"},
+	{"Unlicense", "The Unlicense"},
+}
+
+// deprecatedSPDXIDs maps deprecated SPDX identifiers to their replacements
+var deprecatedSPDXIDs = map[string]string{
+	"agpl-3.0": "AGPL-3.0-only",
+	"gpl-2.0":  "GPL-2.0-only",
+	"gpl-2.0+": "GPL-2.0-or-later",
+	"gpl-3.0":  "GPL-3.0-only",
+	"gpl-3.0+": "GPL-3.0-or-later",
+	"lgpl-2.1": "LGPL-2.1-only",
+	"lgpl-3.0": "LGPL-3.0-only",
+}
+
+// spdxID matches SPDX license identifiers, a "+" suffix included. Custom
+// identifiers use the "LicenseRef-" prefix
+var spdxID = regexp.MustCompile(`^[A-Za-z0-9.\-]+\+?$`)
+
+// LookupSPDXLicense finds a license by SPDX identifier. Matching is
+// case-insensitive, and deprecated identifiers resolve to their replacements
+func LookupSPDXLicense(id string) (SPDXLicense, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if replacement, ok := deprecatedSPDXIDs[id]; ok {
+		id = strings.ToLower(replacement)
+	}
+	for _, l := range spdxLicenses {
+		if strings.ToLower(l.ID) == id {
+			return l, true
+		}
+	}
+	return SPDXLicense{}, false
+}
+
+// Normalize replaces a recognized license type with its canonical SPDX
+// identifier, setting URL to the SPDX reference page if no url is set.
+// Unrecognized types are left as-is
+func (l *License) Normalize() {
+	spdx, ok := LookupSPDXLicense(l.Type)
+	if !ok {
+		return
+	}
+	l.Type = spdx.ID
+	if l.URL == "" {
+		l.URL = spdx.URL()
+	}
+}
+
+// Validate checks that a license is either identified by an SPDX license
+// identifier, or provides a url. SPDX identifiers missing from the embedded
+// subset of the license list are valid
+func (l *License) Validate() error {
+	if l.Type == "" && l.URL == "" {
+		return fmt.Errorf("type or url is required")
+	}
+	if err := validateURL("url", l.URL); err != nil {
+		return err
+	}
+	if l.Type != "" && l.URL == "" {
+		if !spdxID.MatchString(strings.TrimSpace(l.Type)) {
+			return fmt.Errorf("invalid license type '%s'. licenses without a url must have an SPDX identifier type", l.Type)
+		}
+	}
+	return nil
+}
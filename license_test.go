@@ -0,0 +1,74 @@
+package dataset
+
+import (
+	"testing"
+)
+
+func TestLookupSPDXLicense(t *testing.T) {
+	cases := []struct {
+		in, id string
+		ok     bool
+	}{
+		{"CC-BY-4.0", "CC-BY-4.0", true},
+		{"cc-by-4.0", "CC-BY-4.0", true},
+		{" odbl-1.0 ", "ODbL-1.0", true},
+		{"GPL-3.0", "GPL-3.0-only", true},
+		{"dl-de-by-2.0", "DL-DE-BY-2.0", true},
+		{"", "", false},
+		{"my-license", "", false},
+	}
+
+	for i, c := range cases {
+		got, ok := LookupSPDXLicense(c.in)
+		if ok != c.ok {
+			t.Errorf("case %d '%s': expected ok %t, got %t", i, c.in, c.ok, ok)
+			continue
+		}
+		if got.ID != c.id {
+			t.Errorf("case %d '%s': id mismatch. expected: '%s', got: '%s'", i, c.in, c.id, got.ID)
+		}
+	}
+}
+
+func TestLicenseNormalize(t *testing.T) {
+	cases := []struct {
+		in, out *License
+	}{
+		{&License{Type: "cc-by-4.0"}, &License{Type: "CC-BY-4.0", URL: "https://spdx.org/licenses/CC-BY-4.0.html"}},
+		{&License{Type: "cc-by-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"}, &License{Type: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"}},
+		{&License{Type: "custom", URL: "https://mfdz.de/license"}, &License{Type: "custom", URL: "https://mfdz.de/license"}},
+	}
+
+	for i, c := range cases {
+		c.in.Normalize()
+		if err := CompareLicenses(c.out, c.in); err != nil {
+			t.Errorf("case %d: %s", i, err)
+		}
+	}
+}
+
+func TestLicenseValidate(t *testing.T) {
+	cases := []struct {
+		l   *License
+		err string
+	}{
+		{&License{Type: "PDDL-1.0"}, ""},
+		{&License{Type: "pddl-1.0"}, ""},
+		{&License{URL: "https://mfdz.de/license"}, ""},
+		{&License{Type: "custom", URL: "https://mfdz.de/license"}, ""},
+		{&License{}, "type or url is required"},
+		{&License{Type: "Zlib"}, ""},
+		{&License{Type: "GPL-2.0+"}, ""},
+		{&License{Type: "LicenseRef-mfdz-1.0"}, ""},
+		{&License{Type: "my license"}, "invalid license type 'my license'. licenses without a url must have an SPDX identifier type"},
+		{&License{Type: "custom/1"}, "invalid license type 'custom/1'. licenses without a url must have an SPDX identifier type"},
+		{&License{Type: "custom", URL: "license.txt"}, "url: 'license.txt' must be an absolute url"},
+	}
+
+	for i, c := range cases {
+		err := c.l.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
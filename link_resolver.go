@@ -0,0 +1,45 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+// LinkResolver creates a vals.Resolver that dereferences links to datasets
+// with load. The path of a link names the dataset to load, and an optional
+// fragment is a JSON Pointer into the dataset document, so
+// "/ipfs/QmHash#/meta/title" resolves to the title of the dataset at
+// /ipfs/QmHash. links without a fragment resolve to the loaded *Dataset
+func LinkResolver(load DatasetLoader) vals.Resolver {
+	return vals.ResolverFunc(func(ctx context.Context, l vals.Link) (interface{}, error) {
+		if load == nil {
+			return nil, ErrNoResolver
+		}
+		if l.Path() == "" {
+			return nil, fmt.Errorf("link path is required")
+		}
+		ds, err := load(ctx, l.Path())
+		if err != nil {
+			return nil, err
+		}
+		if ds == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, l.Path())
+		}
+		if l.Fragment() == "" {
+			return ds, nil
+		}
+
+		data, err := json.Marshal(ds)
+		if err != nil {
+			return nil, err
+		}
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return vals.Get(doc, l.Fragment())
+	})
+}
@@ -0,0 +1,64 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestLinkResolver(t *testing.T) {
+	ctx := context.Background()
+	store := map[string]*Dataset{
+		"/ipfs/QmA": {Path: "/ipfs/QmA", Meta: &Meta{Title: "linked"}},
+	}
+	r := LinkResolver(func(ctx context.Context, path string) (*Dataset, error) {
+		if ds, ok := store[path]; ok {
+			return ds, nil
+		}
+		return nil, fmt.Errorf("path not found")
+	})
+
+	got, err := r.ResolveLink(ctx, vals.NewLink("/ipfs/QmA"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != store["/ipfs/QmA"] {
+		t.Errorf("expected link without fragment to resolve to the dataset")
+	}
+
+	cases := []struct {
+		ref    string
+		expect interface{}
+		err    string
+	}{
+		{"/ipfs/QmA#/meta/title", "linked", ""},
+		{"/ipfs/QmB#/meta/title", nil, "path not found"},
+		{"#/meta/title", nil, "link path is required"},
+		{"/ipfs/QmA#/meta/nope", nil, "/meta/nope: key not found"},
+	}
+	for _, c := range cases {
+		got, err := r.ResolveLink(ctx, vals.NewLink(c.ref))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("'%s' error mismatch. expected: '%s', got: '%v'", c.ref, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("'%s' result mismatch. expected: %v, got: %v", c.ref, c.expect, got)
+		}
+	}
+
+	body := []interface{}{map[string]interface{}{"$ref": "/ipfs/QmA"}}
+	title, err := vals.GetLinked(ctx, body, "/0/meta/title", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "linked" {
+		t.Errorf("traversal mismatch. got: %v", title)
+	}
+
+	if _, err := LinkResolver(nil).ResolveLink(ctx, vals.NewLink("/ipfs/QmA")); err != ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
@@ -17,17 +17,27 @@ import (
 type Meta struct {
 	// meta holds additional arbitrary metadata not covered by the spec when
 	// encoding & decoding json values here will be hoisted into the meta object
+	// keys in the form [namespace]:[name] are extensions, see SetExtension
 	meta map[string]interface{}
 
+	// AccessLevel is who may access this dataset, one of "public", "internal"
+	// or "restricted". An empty access level is public, see Meta.Access
+	AccessLevel string `json:"accessLevel,omitempty"`
 	// Url to access the dataset
 	AccessURL string `json:"accessURL,omitempty"`
 	// The frequency with which dataset changes. Must be an ISO 8601 repeating
-	// duration
+	// duration or a DCAT-AP / Dublin Core frequency vocabulary term, see
+	// ParseAccrualPeriodicity
 	AccrualPeriodicity string `json:"accrualPeriodicity,omitempty"`
 	// Citations is a slice of assets used to build this dataset
 	Citations []*Citation `json:"citations"`
+	// ContactPoint is who to contact with questions about this dataset
+	ContactPoint *ContactPoint `json:"contactPoint,omitempty"`
 	// Contribute
 	Contributors []*User `json:"contributors,omitempty"`
+	// Deprecated marks this dataset as no longer maintained, optionally
+	// pointing to a superseding dataset
+	Deprecated *Deprecation `json:"deprecated,omitempty"`
 	// Description follows the DCAT sense of the word, it should be around a
 	// paragraph of human-readable text
 	Description string `json:"description,omitempty"`
@@ -37,8 +47,12 @@ type Meta struct {
 	HomeURL string `json:"homeURL,omitempty"`
 	// Identifier is for *other* data catalog specifications. Identifier should
 	// not be used or relied on to be unique, because this package does not
-	// enforce any of these rules.
+	// enforce any of these rules. For typed, validated identifiers use
+	// Identifiers
 	Identifier string `json:"identifier,omitempty"`
+	// Identifiers are typed, persistent identifiers for this dataset like DOIs
+	// & URNs
+	Identifiers []*Identifier `json:"identifiers,omitempty"`
 	// String of Keywords
 	Keywords []string `json:"keywords,omitempty"`
 	// Languages this dataset is written in
@@ -49,6 +63,9 @@ type Meta struct {
 	// path is the location of meta, transient
 	// derived
 	Path string `json:"path,omitempty"`
+	// Publisher is the organization responsible for making this dataset
+	// available
+	Publisher *Publisher `json:"publisher,omitempty"`
 	// Kind is required, must be qri:md:[version]
 	// derived
 	Qri string `json:"qri,omitempty"`
@@ -57,8 +74,22 @@ type Meta struct {
 	ReadmeURL string `json:"readmeURL,omitempty"`
 	// Title of this dataset
 	Title string `json:"title,omitempty"`
+	// Sensitivity classifies how sensitive the data is, one of "low",
+	// "moderate" or "high"
+	Sensitivity string `json:"sensitivity,omitempty"`
+	// Spatial is the geographic area covered by this dataset
+	Spatial *SpatialCoverage `json:"spatial,omitempty"`
+	// Temporal is the period of time covered by this dataset
+	Temporal *TemporalCoverage `json:"temporal,omitempty"`
 	// "Category" for
 	Theme []string `json:"theme,omitempty"`
+	// Themes are categories from controlled vocabularies like the EU data
+	// theme vocabulary, unlike Theme which holds free-text categories
+	Themes []*Theme `json:"themes,omitempty"`
+	// Translations holds language-specific versions of Title, Description and
+	// Keywords, keyed by BCP-47 language tag. Title, Description and Keywords
+	// remain the default values for readers that don't support translation
+	Translations map[string]*MetaTranslation `json:"translations,omitempty"`
 	// Version is the version identifier for this dataset
 	Version string `json:"version,omitempty"`
 }
@@ -91,6 +122,16 @@ func (md *Meta) IsEmpty() bool {
 		md.ReadmeURL == "" &&
 		md.Title == "" &&
 		md.Theme == nil &&
+		md.Translations == nil &&
+		md.Spatial == nil &&
+		md.Temporal == nil &&
+		md.Publisher == nil &&
+		md.ContactPoint == nil &&
+		md.Identifiers == nil &&
+		md.Themes == nil &&
+		md.AccessLevel == "" &&
+		md.Sensitivity == "" &&
+		md.Deprecated == nil &&
 		md.Version == ""
 }
 
@@ -226,6 +267,63 @@ func (md *Meta) Set(key string, val interface{}) (err error) {
 		md.License = &License{}
 		err = md.License.Decode(val)
 
+	case "translations":
+		md.Translations, err = decodeTranslations(val)
+
+	case "spatial":
+		md.Spatial = &SpatialCoverage{}
+		err = md.Spatial.Decode(val)
+
+	case "temporal":
+		md.Temporal = &TemporalCoverage{}
+		err = md.Temporal.Decode(val)
+
+	case "publisher":
+		md.Publisher = &Publisher{}
+		err = md.Publisher.Decode(val)
+
+	case "contactpoint":
+		md.ContactPoint = &ContactPoint{}
+		err = md.ContactPoint.Decode(val)
+
+	case "identifiers":
+		if sl, ok := val.([]interface{}); ok {
+			md.Identifiers = make([]*Identifier, len(sl))
+			for i, v := range sl {
+				id := &Identifier{}
+				if err = id.Decode(v); err != nil {
+					err = fmt.Errorf("parsing identifiers index %d: %s", i, err.Error())
+					return
+				}
+				md.Identifiers[i] = id
+			}
+		} else {
+			err = fmt.Errorf("identifiers: expected interface slice")
+		}
+
+	case "themes":
+		if sl, ok := val.([]interface{}); ok {
+			md.Themes = make([]*Theme, len(sl))
+			for i, v := range sl {
+				th := &Theme{}
+				if err = th.Decode(v); err != nil {
+					err = fmt.Errorf("parsing themes index %d: %s", i, err.Error())
+					return
+				}
+				md.Themes[i] = th
+			}
+		} else {
+			err = fmt.Errorf("themes: expected interface slice")
+		}
+
+	case "accesslevel":
+		md.AccessLevel, err = strVal(val)
+	case "sensitivity":
+		md.Sensitivity, err = strVal(val)
+	case "deprecated":
+		md.Deprecated = &Deprecation{}
+		err = md.Deprecated.Decode(val)
+
 	// everything else
 	default:
 		if md.meta == nil {
@@ -306,12 +404,106 @@ func (md *Meta) Assign(metas ...*Meta) {
 		if m.Title != "" {
 			md.Title = m.Title
 		}
+		if m.Translations != nil {
+			md.Translations = m.Translations
+		}
+		if m.Spatial != nil {
+			md.Spatial = m.Spatial
+		}
+		if m.Temporal != nil {
+			md.Temporal = m.Temporal
+		}
+		if m.Publisher != nil {
+			md.Publisher = m.Publisher
+		}
+		if m.ContactPoint != nil {
+			md.ContactPoint = m.ContactPoint
+		}
+		if m.Identifiers != nil {
+			md.Identifiers = m.Identifiers
+		}
+		if m.Themes != nil {
+			md.Themes = m.Themes
+		}
+		if m.AccessLevel != "" {
+			md.AccessLevel = m.AccessLevel
+		}
+		if m.Sensitivity != "" {
+			md.Sensitivity = m.Sensitivity
+		}
+		if m.Deprecated != nil {
+			md.Deprecated = m.Deprecated
+		}
 		if m.Version != "" {
 			md.Version = m.Version
 		}
 	}
 }
 
+// Clone returns a deep copy of a metadata component, including arbitrary
+// metadata values
+func (md *Meta) Clone() *Meta {
+	if md == nil {
+		return nil
+	}
+	c := &Meta{
+		meta:               cloneMap(md.meta),
+		AccessURL:          md.AccessURL,
+		AccrualPeriodicity: md.AccrualPeriodicity,
+		Description:        md.Description,
+		DownloadURL:        md.DownloadURL,
+		HomeURL:            md.HomeURL,
+		Identifier:         md.Identifier,
+		Keywords:           cloneStrings(md.Keywords),
+		Language:           cloneStrings(md.Language),
+		License:            md.License.Clone(),
+		Path:               md.Path,
+		Qri:                md.Qri,
+		ReadmeURL:          md.ReadmeURL,
+		Title:              md.Title,
+		Theme:              cloneStrings(md.Theme),
+		Spatial:            md.Spatial.Clone(),
+		Temporal:           md.Temporal.Clone(),
+		Publisher:          md.Publisher.Clone(),
+		ContactPoint:       md.ContactPoint.Clone(),
+		AccessLevel:        md.AccessLevel,
+		Sensitivity:        md.Sensitivity,
+		Deprecated:         md.Deprecated.Clone(),
+		Version:            md.Version,
+	}
+	if md.Themes != nil {
+		c.Themes = make([]*Theme, len(md.Themes))
+		for i, th := range md.Themes {
+			c.Themes[i] = th.Clone()
+		}
+	}
+	if md.Identifiers != nil {
+		c.Identifiers = make([]*Identifier, len(md.Identifiers))
+		for i, id := range md.Identifiers {
+			c.Identifiers[i] = id.Clone()
+		}
+	}
+	if md.Translations != nil {
+		c.Translations = make(map[string]*MetaTranslation, len(md.Translations))
+		for tag, tr := range md.Translations {
+			c.Translations[tag] = tr.Clone()
+		}
+	}
+	if md.Citations != nil {
+		c.Citations = make([]*Citation, len(md.Citations))
+		for i, cite := range md.Citations {
+			c.Citations[i] = cite.Clone()
+		}
+	}
+	if md.Contributors != nil {
+		c.Contributors = make([]*User, len(md.Contributors))
+		for i, u := range md.Contributors {
+			c.Contributors[i] = u.Clone()
+		}
+	}
+	return c
+}
+
 // MarshalJSON uses a map to combine meta & standard fields.
 // Marshalling a map[string]interface{} automatically alpha-sorts the keys.
 func (md *Meta) MarshalJSON() ([]byte, error) {
@@ -327,7 +519,11 @@ func (md *Meta) MarshalJSON() ([]byte, error) {
 // MarshalJSONObject always marshals to a json Object, even if meta is empty or
 // a reference
 func (md *Meta) MarshalJSONObject() ([]byte, error) {
-	data := md.Meta()
+	// copy arbitrary metadata so spec fields aren't written into it
+	data := make(map[string]interface{}, len(md.meta))
+	for key, val := range md.meta {
+		data[key] = val
+	}
 
 	data["qri"] = KindMeta.String()
 
@@ -373,6 +569,36 @@ func (md *Meta) MarshalJSONObject() ([]byte, error) {
 	if md.AccrualPeriodicity != "" {
 		data["accrualPeriodicity"] = md.AccrualPeriodicity
 	}
+	if md.Translations != nil {
+		data["translations"] = md.Translations
+	}
+	if md.Spatial != nil {
+		data["spatial"] = md.Spatial
+	}
+	if md.Temporal != nil {
+		data["temporal"] = md.Temporal
+	}
+	if md.Publisher != nil {
+		data["publisher"] = md.Publisher
+	}
+	if md.ContactPoint != nil {
+		data["contactPoint"] = md.ContactPoint
+	}
+	if md.Identifiers != nil {
+		data["identifiers"] = md.Identifiers
+	}
+	if md.Themes != nil {
+		data["themes"] = md.Themes
+	}
+	if md.AccessLevel != "" {
+		data["accessLevel"] = md.AccessLevel
+	}
+	if md.Sensitivity != "" {
+		data["sensitivity"] = md.Sensitivity
+	}
+	if md.Deprecated != nil {
+		data["deprecated"] = md.Deprecated
+	}
 	if md.Version != "" {
 		data["version"] = md.Version
 	}
@@ -396,6 +622,9 @@ func (md *Meta) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &d); err != nil {
 		return fmt.Errorf("error unmarshling dataset metadata: %s", err.Error())
 	}
+	if err := checkKind(d.Qri); err != nil {
+		return err
+	}
 
 	meta := map[string]interface{}{}
 	if err := json.Unmarshal(data, &meta); err != nil {
@@ -403,26 +632,37 @@ func (md *Meta) UnmarshalJSON(data []byte) error {
 	}
 
 	for _, f := range []string{
+		"accessLevel",
 		"accessURL",
 		"accrualPeriodicity",
 		"citations",
+		"contactPoint",
 		"contributors",
 		"data",
+		"deprecated",
 		"description",
 		"downloadURL",
 		"homeURL",
 		"identifier",
+		"identifiers",
 		"image",
 		"keyword",
+		"keywords",
 		"path",
+		"publisher",
 		"qri",
 		"language",
 		"length",
 		"license",
 		"readmeURL",
+		"sensitivity",
+		"spatial",
+		"temporal",
 		"theme",
+		"themes",
 		"timestamp",
 		"title",
+		"translations",
 		"version",
 	} {
 		delete(meta, f)
@@ -458,6 +698,15 @@ func (u *User) Decode(val interface{}) (err error) {
 	return
 }
 
+// Clone returns a copy of a user
+func (u *User) Clone() *User {
+	if u == nil {
+		return nil
+	}
+	c := *u
+	return &c
+}
+
 // License represents a legal licensing agreement
 type License struct {
 	Type string `json:"type,omitempty"`
@@ -480,6 +729,15 @@ func (l *License) Decode(val interface{}) (err error) {
 	return
 }
 
+// Clone returns a copy of a license
+func (l *License) Clone() *License {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	return &c
+}
+
 // Citation is a place that this dataset drew it's information from
 type Citation struct {
 	Name  string `json:"name,omitempty"`
@@ -505,14 +763,101 @@ func (c *Citation) Decode(val interface{}) (err error) {
 	return
 }
 
-// Theme is pulled from the Project Open Data Schema version 1.1
-type Theme struct {
-	Description     string `json:"description,omitempty"`
-	DisplayName     string `json:"display_name,omitempty"`
-	ImageDisplayURL string `json:"image_display_url,omitempty"`
-	ID              string `json:"id,omitempty"`
-	Name            string `json:"name,omitempty"`
-	Title           string `json:"title,omitempty"`
+// Clone returns a copy of a citation
+func (c *Citation) Clone() *Citation {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	return &cp
+}
+
+// Publisher is the organization responsible for making a dataset available,
+// corresponding to the DCAT dct:publisher property & CKAN's organization
+type Publisher struct {
+	// Name of the publishing organization
+	Name string `json:"name,omitempty"`
+	// URL of the publisher's homepage
+	URL string `json:"url,omitempty"`
+	// Email is a general contact address for the publisher
+	Email string `json:"email,omitempty"`
+	// Identifier is a persistent identifier for the publisher, eg. a ROR, ISNI
+	// or company registry URI
+	Identifier string `json:"identifier,omitempty"`
+}
+
+// Decode reads json.Umarshal-style data into a Publisher
+func (p *Publisher) Decode(val interface{}) (err error) {
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	if p.Name, err = strVal(msi["name"]); err != nil {
+		return
+	}
+	if p.URL, err = strVal(msi["url"]); err != nil {
+		return
+	}
+	if p.Email, err = strVal(msi["email"]); err != nil {
+		return
+	}
+	if p.Identifier, err = strVal(msi["identifier"]); err != nil {
+		return
+	}
+	return
+}
+
+// Clone returns a copy of a publisher
+func (p *Publisher) Clone() *Publisher {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// ContactPoint is the person or group to contact with questions about a
+// dataset, corresponding to the DCAT dcat:contactPoint property & CKAN's
+// maintainer fields
+type ContactPoint struct {
+	// Name of the contact, a person or role like "Data Team"
+	Name string `json:"name,omitempty"`
+	// URL of a contact form or page
+	URL string `json:"url,omitempty"`
+	// Email address of the contact
+	Email string `json:"email,omitempty"`
+	// Identifier is a persistent identifier for the contact, eg. an ORCID
+	Identifier string `json:"identifier,omitempty"`
+}
+
+// Decode reads json.Umarshal-style data into a ContactPoint
+func (cp *ContactPoint) Decode(val interface{}) (err error) {
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	if cp.Name, err = strVal(msi["name"]); err != nil {
+		return
+	}
+	if cp.URL, err = strVal(msi["url"]); err != nil {
+		return
+	}
+	if cp.Email, err = strVal(msi["email"]); err != nil {
+		return
+	}
+	if cp.Identifier, err = strVal(msi["identifier"]); err != nil {
+		return
+	}
+	return
+}
+
+// Clone returns a copy of a contact point
+func (cp *ContactPoint) Clone() *ContactPoint {
+	if cp == nil {
+		return nil
+	}
+	c := *cp
+	return &c
 }
 
 // AccuralDuration takes an ISO 8601 periodicity measure & returns a
@@ -0,0 +1,428 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SpatialCoverage describes the geographic area a dataset covers, following
+// the DCAT dct:spatial property. At least one of BBox, Geometry or Place
+// should be set
+type SpatialCoverage struct {
+	// BBox is a bounding box in GeoJSON order: [west, south, east, north] in
+	// WGS84 decimal degrees. west may be greater than east for boxes that
+	// cross the antimeridian
+	BBox []float64 `json:"bbox,omitempty"`
+	// Geometry is a GeoJSON geometry object (RFC 7946) outlining the covered
+	// area
+	Geometry map[string]interface{} `json:"geometry,omitempty"`
+	// Place is the name of the covered area, eg. "Baden-Württemberg"
+	Place string `json:"place,omitempty"`
+	// PlaceURL identifies the covered area in a gazetteer like geonames.org
+	PlaceURL string `json:"placeURL,omitempty"`
+}
+
+// IsEmpty checks to see if spatial coverage has no values set
+func (s *SpatialCoverage) IsEmpty() bool {
+	return s.BBox == nil && s.Geometry == nil && s.Place == "" && s.PlaceURL == ""
+}
+
+// Clone returns a deep copy of spatial coverage
+func (s *SpatialCoverage) Clone() *SpatialCoverage {
+	if s == nil {
+		return nil
+	}
+	c := &SpatialCoverage{
+		Geometry: cloneMap(s.Geometry),
+		Place:    s.Place,
+		PlaceURL: s.PlaceURL,
+	}
+	if s.BBox != nil {
+		c.BBox = make([]float64, len(s.BBox))
+		copy(c.BBox, s.BBox)
+	}
+	return c
+}
+
+// Decode reads json.Umarshal-style data into spatial coverage
+func (s *SpatialCoverage) Decode(val interface{}) (err error) {
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	if s.Place, err = strVal(msi["place"]); err != nil {
+		return fmt.Errorf("place: %s", err)
+	}
+	if s.PlaceURL, err = strVal(msi["placeURL"]); err != nil {
+		return fmt.Errorf("placeURL: %s", err)
+	}
+	if g, ok := msi["geometry"]; ok && g != nil {
+		if s.Geometry, ok = g.(map[string]interface{}); !ok {
+			return fmt.Errorf("geometry: expected map[string]interface{}")
+		}
+	}
+	if b, ok := msi["bbox"]; ok && b != nil {
+		if s.BBox, err = floatSliceVal(b); err != nil {
+			return fmt.Errorf("bbox: %s", err)
+		}
+	}
+	return nil
+}
+
+// floatSliceVal confirms an interface is a slice of numbers
+func floatSliceVal(val interface{}) ([]float64, error) {
+	si, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("type must be a set of numbers")
+	}
+	fs := make([]float64, len(si))
+	for i, v := range si {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("index %d: type must be a number", i)
+		}
+		fs[i] = f
+	}
+	return fs, nil
+}
+
+// Validate checks spatial coverage for a well-formed bounding box & geometry.
+// West longitudes greater than east describe boxes that cross the
+// antimeridian, and are valid
+func (s *SpatialCoverage) Validate() error {
+	if s.BBox != nil {
+		if len(s.BBox) != 4 {
+			return fmt.Errorf("bbox must have 4 values, got %d", len(s.BBox))
+		}
+		for i, n := range s.BBox {
+			if err := validCoordinate(n, i%2 == 1); err != nil {
+				return fmt.Errorf("bbox index %d: %s", i, err)
+			}
+		}
+		if s.BBox[1] > s.BBox[3] {
+			return fmt.Errorf("bbox south latitude %g is greater than north latitude %g", s.BBox[1], s.BBox[3])
+		}
+	}
+	if s.Geometry != nil {
+		if err := validateGeometry(s.Geometry); err != nil {
+			return fmt.Errorf("geometry: %s", err)
+		}
+	}
+	return validateURL("placeURL", s.PlaceURL)
+}
+
+func validCoordinate(n float64, lat bool) error {
+	if lat && (n < -90 || n > 90) {
+		return fmt.Errorf("latitude %g out of range", n)
+	} else if !lat && (n < -180 || n > 180) {
+		return fmt.Errorf("longitude %g out of range", n)
+	}
+	return nil
+}
+
+// geometryTypes lists valid GeoJSON geometry types
+var geometryTypes = map[string]bool{
+	"Point":              true,
+	"MultiPoint":         true,
+	"LineString":         true,
+	"MultiLineString":    true,
+	"Polygon":            true,
+	"MultiPolygon":       true,
+	"GeometryCollection": true,
+}
+
+func validateGeometry(g map[string]interface{}) error {
+	t, _ := g["type"].(string)
+	if !geometryTypes[t] {
+		return fmt.Errorf("invalid type %q", g["type"])
+	}
+	if t == "GeometryCollection" {
+		geoms, ok := g["geometries"].([]interface{})
+		if !ok {
+			return fmt.Errorf("geometries are required")
+		}
+		for i, sub := range geoms {
+			m, ok := sub.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("geometries index %d: expected an object", i)
+			}
+			if err := validateGeometry(m); err != nil {
+				return fmt.Errorf("geometries index %d: %s", i, err)
+			}
+		}
+		return nil
+	}
+	if _, ok := g["coordinates"].([]interface{}); !ok {
+		return fmt.Errorf("coordinates are required")
+	}
+	return nil
+}
+
+// GeoJSON encodes spatial coverage as a GeoJSON Feature. Feature geometry is
+// Geometry if set, otherwise a polygon constructed from BBox. Bounding boxes
+// that cross the antimeridian, with a west longitude greater than east, are
+// split into a MultiPolygon of one polygon on each side. Place names are
+// written as feature properties
+func (s *SpatialCoverage) GeoJSON() ([]byte, error) {
+	feature := map[string]interface{}{
+		"type":     "Feature",
+		"geometry": nil,
+	}
+	if s.Geometry != nil {
+		feature["geometry"] = s.Geometry
+	} else if len(s.BBox) == 4 {
+		w, south, e, n := s.BBox[0], s.BBox[1], s.BBox[2], s.BBox[3]
+		ring := func(w, e float64) [][][]float64 {
+			return [][][]float64{{{w, south}, {e, south}, {e, n}, {w, n}, {w, south}}}
+		}
+		if w > e {
+			feature["geometry"] = map[string]interface{}{
+				"type":        "MultiPolygon",
+				"coordinates": [][][][]float64{ring(w, 180), ring(-180, e)},
+			}
+		} else {
+			feature["geometry"] = map[string]interface{}{
+				"type":        "Polygon",
+				"coordinates": ring(w, e),
+			}
+		}
+	}
+	if s.BBox != nil {
+		feature["bbox"] = s.BBox
+	}
+
+	props := map[string]interface{}{}
+	if s.Place != "" {
+		props["place"] = s.Place
+	}
+	if s.PlaceURL != "" {
+		props["placeURL"] = s.PlaceURL
+	}
+	feature["properties"] = props
+
+	return json.Marshal(feature)
+}
+
+// SpatialCoverageFromGeoJSON creates spatial coverage from a GeoJSON Feature
+// or geometry object. If the document doesn't include a bbox one is
+// calculated from geometry coordinates
+func SpatialCoverageFromGeoJSON(data []byte) (*SpatialCoverage, error) {
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	s := &SpatialCoverage{}
+	switch obj["type"] {
+	case "Feature":
+		if g, ok := obj["geometry"].(map[string]interface{}); ok {
+			s.Geometry = g
+		}
+		if props, ok := obj["properties"].(map[string]interface{}); ok {
+			s.Place, _ = props["place"].(string)
+			s.PlaceURL, _ = props["placeURL"].(string)
+		}
+	case "FeatureCollection":
+		return nil, fmt.Errorf("FeatureCollections are not supported, provide a single Feature or geometry")
+	default:
+		s.Geometry = obj
+	}
+
+	if b, ok := obj["bbox"]; ok {
+		bbox, err := floatSliceVal(b)
+		if err != nil {
+			return nil, fmt.Errorf("bbox: %s", err)
+		}
+		s.BBox = bbox
+	} else if s.Geometry != nil {
+		s.BBox = geometryBBox(s.Geometry)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// geometryBBox calculates the bounding box of a GeoJSON geometry, returning
+// nil if geometry has no coordinates
+func geometryBBox(g map[string]interface{}) []float64 {
+	var bbox []float64
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		arr, ok := v.([]interface{})
+		if !ok {
+			return
+		}
+		if len(arr) >= 2 {
+			lon, lonOk := arr[0].(float64)
+			lat, latOk := arr[1].(float64)
+			if lonOk && latOk {
+				if bbox == nil {
+					bbox = []float64{lon, lat, lon, lat}
+					return
+				}
+				if lon < bbox[0] {
+					bbox[0] = lon
+				}
+				if lat < bbox[1] {
+					bbox[1] = lat
+				}
+				if lon > bbox[2] {
+					bbox[2] = lon
+				}
+				if lat > bbox[3] {
+					bbox[3] = lat
+				}
+				return
+			}
+		}
+		for _, sub := range arr {
+			walk(sub)
+		}
+	}
+
+	walk(g["coordinates"])
+	if geoms, ok := g["geometries"].([]interface{}); ok {
+		for _, sub := range geoms {
+			if m, ok := sub.(map[string]interface{}); ok {
+				walk(m["coordinates"])
+			}
+		}
+	}
+	return bbox
+}
+
+// TemporalCoverage describes the period of time a dataset covers, following
+// the DCAT dct:temporal property. Either bound may be omitted for open-ended
+// periods. Bounds decoded from dates cover whole days in UTC: a start date
+// begins at midnight, and an end date runs to the end of the day, so a
+// period ending "2019-12-31" contains 2019-12-31T18:00:00Z. Date bounds are
+// encoded as dates again
+type TemporalCoverage struct {
+	// Start of the covered period
+	Start *time.Time `json:"start,omitempty"`
+	// End of the covered period
+	End *time.Time `json:"end,omitempty"`
+	// startDate & endDate record bounds decoded from dates
+	startDate, endDate bool
+}
+
+// IsEmpty checks to see if temporal coverage has no values set
+func (t *TemporalCoverage) IsEmpty() bool {
+	return t.Start == nil && t.End == nil
+}
+
+// Clone returns a copy of temporal coverage
+func (t *TemporalCoverage) Clone() *TemporalCoverage {
+	if t == nil {
+		return nil
+	}
+	c := &TemporalCoverage{}
+	if t.Start != nil {
+		start := *t.Start
+		c.Start = &start
+	}
+	if t.End != nil {
+		end := *t.End
+		c.End = &end
+	}
+	c.startDate, c.endDate = t.startDate, t.endDate
+	return c
+}
+
+// Decode reads json.Umarshal-style data into temporal coverage. Bounds may be
+// RFC 3339 timestamps or dates in the form YYYY-MM-DD
+func (t *TemporalCoverage) Decode(val interface{}) (err error) {
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	if t.Start, t.startDate, err = timeVal(msi["start"]); err != nil {
+		return fmt.Errorf("start: %s", err)
+	}
+	if t.End, t.endDate, err = timeVal(msi["end"]); err != nil {
+		return fmt.Errorf("end: %s", err)
+	}
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting the same
+// timestamp formats as Decode
+func (t *TemporalCoverage) UnmarshalJSON(data []byte) error {
+	v := map[string]interface{}{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = TemporalCoverage{}
+	return t.Decode(v)
+}
+
+// MarshalJSON implements the json.Marshaler interface, writing bounds decoded
+// from dates as dates & other bounds as RFC 3339 timestamps
+func (t *TemporalCoverage) MarshalJSON() ([]byte, error) {
+	v := map[string]string{}
+	if t.Start != nil {
+		v["start"] = formatBound(*t.Start, t.startDate)
+	}
+	if t.End != nil {
+		v["end"] = formatBound(*t.End, t.endDate)
+	}
+	return json.Marshal(v)
+}
+
+// dateLayout is the layout of date-only bounds
+const dateLayout = "2006-01-02"
+
+// formatBound writes a bound as a date or timestamp
+func formatBound(ts time.Time, date bool) string {
+	if date {
+		return ts.Format(dateLayout)
+	}
+	return ts.Format(time.RFC3339Nano)
+}
+
+// timeVal parses an optional timestamp string, reporting whether it's a date
+func timeVal(val interface{}) (*time.Time, bool, error) {
+	s, err := strVal(val)
+	if err != nil || s == "" {
+		return nil, false, err
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return &ts, false, nil
+	}
+	if ts, err := time.Parse(dateLayout, s); err == nil {
+		return &ts, true, nil
+	}
+	return nil, false, fmt.Errorf("invalid timestamp '%s'", s)
+}
+
+// Validate checks that temporal coverage doesn't end before it starts. An
+// end date is checked from the end of it's day
+func (t *TemporalCoverage) Validate() error {
+	if t.Start != nil && t.End != nil && !t.endsAfter(*t.Start) {
+		return fmt.Errorf("end %s is before start %s", formatBound(*t.End, t.endDate), formatBound(*t.Start, t.startDate))
+	}
+	return nil
+}
+
+// endsAfter checks if ts is no later than the end of the period, including
+// the whole day of an end date
+func (t *TemporalCoverage) endsAfter(ts time.Time) bool {
+	if t.endDate {
+		return ts.Before(t.End.AddDate(0, 0, 1))
+	}
+	return !ts.After(*t.End)
+}
+
+// Contains checks if a moment in time falls within the covered period
+func (t *TemporalCoverage) Contains(ts time.Time) bool {
+	if t.Start != nil && ts.Before(*t.Start) {
+		return false
+	}
+	if t.End != nil && !t.endsAfter(ts) {
+		return false
+	}
+	return true
+}
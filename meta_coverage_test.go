@@ -0,0 +1,200 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpatialCoverageValidate(t *testing.T) {
+	cases := []struct {
+		description string
+		s           *SpatialCoverage
+		err         string
+	}{
+		{"empty", &SpatialCoverage{}, ""},
+		{"named place", &SpatialCoverage{Place: "Stuttgart", PlaceURL: "https://www.geonames.org/2825297"}, ""},
+		{"bbox", &SpatialCoverage{BBox: []float64{7.5, 47.5, 10.5, 49.8}}, ""},
+		{"antimeridian bbox", &SpatialCoverage{BBox: []float64{170, -50, -170, -30}}, ""},
+		{"short bbox", &SpatialCoverage{BBox: []float64{1, 2}}, "bbox must have 4 values, got 2"},
+		{"bad longitude", &SpatialCoverage{BBox: []float64{-181, 0, 0, 0}}, "bbox index 0: longitude -181 out of range"},
+		{"bad latitude", &SpatialCoverage{BBox: []float64{0, 0, 0, 91}}, "bbox index 3: latitude 91 out of range"},
+		{"inverted latitude", &SpatialCoverage{BBox: []float64{0, 10, 1, 5}}, "bbox south latitude 10 is greater than north latitude 5"},
+		{"bad geometry type", &SpatialCoverage{Geometry: map[string]interface{}{"type": "Circle"}}, `geometry: invalid type "Circle"`},
+		{"no coordinates", &SpatialCoverage{Geometry: map[string]interface{}{"type": "Point"}}, "geometry: coordinates are required"},
+		{"collection", &SpatialCoverage{Geometry: map[string]interface{}{
+			"type":       "GeometryCollection",
+			"geometries": []interface{}{map[string]interface{}{"type": "Point"}},
+		}}, "geometry: geometries index 0: coordinates are required"},
+		{"relative place url", &SpatialCoverage{PlaceURL: "geonames/2825297"}, "placeURL: 'geonames/2825297' must be an absolute url"},
+	}
+
+	for _, c := range cases {
+		err := c.s.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%s: error mismatch. expected: '%s', got: '%v'", c.description, c.err, err)
+		}
+	}
+}
+
+func TestSpatialCoverageGeoJSON(t *testing.T) {
+	s := &SpatialCoverage{BBox: []float64{7.5, 47.5, 10.5, 49.8}, Place: "Baden-Württemberg"}
+	data, err := s.GeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"bbox":[7.5,47.5,10.5,49.8],"geometry":{"coordinates":[[[7.5,47.5],[10.5,47.5],[10.5,49.8],[7.5,49.8],[7.5,47.5]]],"type":"Polygon"},"properties":{"place":"Baden-Württemberg"},"type":"Feature"}`
+	if string(data) != expect {
+		t.Errorf("geojson mismatch.\nexpected: %s\ngot:      %s", expect, string(data))
+	}
+
+	got, err := SpatialCoverageFromGeoJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Place != s.Place {
+		t.Errorf("place mismatch. expected: '%s', got: '%s'", s.Place, got.Place)
+	}
+	if got.Geometry["type"] != "Polygon" {
+		t.Errorf("expected polygon geometry, got: %v", got.Geometry["type"])
+	}
+
+	// bounding boxes crossing the antimeridian are split
+	fiji := &SpatialCoverage{BBox: []float64{177, -19, -178, -16}}
+	if err := fiji.Validate(); err != nil {
+		t.Errorf("expected antimeridian bbox to be valid. got: %s", err)
+	}
+	if data, err = fiji.GeoJSON(); err != nil {
+		t.Fatal(err)
+	}
+	expect = `{"bbox":[177,-19,-178,-16],"geometry":{"coordinates":[[[[177,-19],[180,-19],[180,-16],[177,-16],[177,-19]]],[[[-180,-19],[-178,-19],[-178,-16],[-180,-16],[-180,-19]]]],"type":"MultiPolygon"},"properties":{},"type":"Feature"}`
+	if string(data) != expect {
+		t.Errorf("antimeridian geojson mismatch.\nexpected: %s\ngot:      %s", expect, string(data))
+	}
+
+	// bounding boxes are calculated from geometry when missing
+	got, err = SpatialCoverageFromGeoJSON([]byte(`{"type":"LineString","coordinates":[[9.1,48.7],[9.3,48.8],[9.2,48.6]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []float64{9.1, 48.6, 9.3, 48.8}; !reflect.DeepEqual(expect, got.BBox) {
+		t.Errorf("bbox mismatch. expected: %v, got: %v", expect, got.BBox)
+	}
+
+	if _, err := SpatialCoverageFromGeoJSON([]byte(`{"type":"FeatureCollection","features":[]}`)); err == nil {
+		t.Errorf("expected FeatureCollection to error")
+	}
+}
+
+func TestTemporalCoverage(t *testing.T) {
+	tc := &TemporalCoverage{}
+	if err := json.Unmarshal([]byte(`{"start":"2019-01-01","end":"2019-12-31T23:59:59Z"}`), tc); err != nil {
+		t.Fatal(err)
+	}
+	if !tc.Start.Equal(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("start mismatch: %s", tc.Start)
+	}
+	if !tc.Contains(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected coverage to contain mid-2019")
+	}
+	if tc.Contains(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected coverage not to contain 2020")
+	}
+	if err := tc.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"start":"2019-12-31T23:59:59Z","end":"2019-01-01T00:00:00Z"}`), tc); err != nil {
+		t.Fatal(err)
+	}
+	expect := "end 2019-01-01T00:00:00Z is before start 2019-12-31T23:59:59Z"
+	if err := tc.Validate(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expect, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"start":"last tuesday"}`), tc); err == nil {
+		t.Errorf("expected invalid timestamp to error")
+	}
+}
+
+func TestTemporalCoverageDates(t *testing.T) {
+	cases := []struct {
+		coverage string
+		ts       time.Time
+		contains bool
+		err      string
+	}{
+		{`{"end":"2019-12-31"}`, time.Date(2019, 12, 31, 18, 0, 0, 0, time.UTC), true, ""},
+		{`{"end":"2019-12-31"}`, time.Date(2019, 12, 31, 23, 30, 0, 0, time.FixedZone("", -2*60*60)), false, ""},
+		{`{"end":"2019-12-31"}`, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), false, ""},
+		{`{"end":"2019-12-31T00:00:00Z"}`, time.Date(2019, 12, 31, 18, 0, 0, 0, time.UTC), false, ""},
+		{`{"start":"2019-12-31"}`, time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC), true, ""},
+		{`{"start":"2019-12-31"}`, time.Date(2019, 12, 30, 23, 59, 59, 0, time.UTC), false, ""},
+		{`{"start":"2019-12-31T12:00:00Z","end":"2019-12-31"}`, time.Date(2019, 12, 31, 20, 0, 0, 0, time.UTC), true, ""},
+		{`{"start":"2019-12-31T12:00:00Z","end":"2019-12-30"}`, time.Time{}, false, "end 2019-12-30 is before start 2019-12-31T12:00:00Z"},
+		{`{"start":"2019-12-31","end":"2019-12-30T23:00:00Z"}`, time.Time{}, false, "end 2019-12-30T23:00:00Z is before start 2019-12-31"},
+	}
+	for i, c := range cases {
+		tc := &TemporalCoverage{}
+		if err := json.Unmarshal([]byte(c.coverage), tc); err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		err := tc.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+			continue
+		}
+		if c.err == "" && tc.Contains(c.ts) != c.contains {
+			t.Errorf("case %d: %s contains %s expected: %t", i, c.coverage, c.ts, c.contains)
+		}
+		data, err := json.Marshal(tc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rt := &TemporalCoverage{}
+		if err := json.Unmarshal(data, rt); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tc, rt) {
+			t.Errorf("case %d round trip mismatch. encoded: %s", i, data)
+		}
+	}
+
+	tc := &TemporalCoverage{}
+	json.Unmarshal([]byte(`{"start":"2019-01-01","end":"2019-12-31T23:59:59Z"}`), tc)
+	if data, _ := json.Marshal(tc.Clone()); string(data) != `{"end":"2019-12-31T23:59:59Z","start":"2019-01-01"}` {
+		t.Errorf("expected date bounds to encode as dates, got: %s", data)
+	}
+}
+
+func TestMetaCoverageJSON(t *testing.T) {
+	data := []byte(`{"qri":"md:0","spatial":{"bbox":[7.5,47.5,10.5,49.8],"place":"Baden-Württemberg"},"temporal":{"start":"2019-01-01T00:00:00Z"}}`)
+	md := &Meta{}
+	if err := json.Unmarshal(data, md); err != nil {
+		t.Fatal(err)
+	}
+	if md.Meta()["spatial"] != nil || md.Meta()["temporal"] != nil {
+		t.Errorf("coverage fields should not be hoisted into arbitrary metadata")
+	}
+
+	got, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md2 := &Meta{}
+	if err := json.Unmarshal(got, md2); err != nil {
+		t.Fatal(err)
+	}
+	if err := CompareMetas(md, md2); err != nil {
+		t.Errorf("round trip mismatch: %s", err)
+	}
+
+	md3 := &Meta{}
+	if err := md3.Set("spatial", map[string]interface{}{"bbox": []interface{}{"a"}}); err == nil {
+		t.Errorf("expected invalid bbox to error")
+	}
+	if err := md3.Set("temporal", map[string]interface{}{"end": "2019-02-01"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
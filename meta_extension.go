@@ -0,0 +1,148 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// namespacedKey matches metadata extension keys in the form
+// [namespace]:[name], eg. "mfdz:qualityReport"
+var namespacedKey = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*:[a-zA-Z][a-zA-Z0-9_.-]*$`)
+
+// IsExtensionKey checks if a metadata key is namespaced in the form
+// [namespace]:[name]. Namespaced keys will never collide with fields defined
+// by the dataset spec
+func IsExtensionKey(key string) bool {
+	return namespacedKey.MatchString(key)
+}
+
+// MetaExtension describes a namespaced block of additional metadata
+type MetaExtension struct {
+	// Key of the extension in the form [namespace]:[name]
+	Key string
+	// Validate checks an extension value, which will be a json.Unmarshal-style
+	// value. optional
+	Validate func(val interface{}) error
+}
+
+var (
+	extensionsLk   sync.RWMutex
+	metaExtensions = map[string]MetaExtension{}
+)
+
+// RegisterMetaExtension adds an extension to the set of known metadata
+// extensions. Values for registered extensions are checked by their
+// validation hook when set with SetExtension & when a dataset is validated.
+// Registering the same key twice is an error
+func RegisterMetaExtension(ext MetaExtension) error {
+	if !IsExtensionKey(ext.Key) {
+		return fmt.Errorf("invalid extension key '%s'. key must be in the form [namespace]:[name]", ext.Key)
+	}
+	extensionsLk.Lock()
+	defer extensionsLk.Unlock()
+	if _, ok := metaExtensions[ext.Key]; ok {
+		return fmt.Errorf("extension '%s' is already registered", ext.Key)
+	}
+	metaExtensions[ext.Key] = ext
+	return nil
+}
+
+// UnregisterMetaExtension removes a registered extension
+func UnregisterMetaExtension(key string) {
+	extensionsLk.Lock()
+	defer extensionsLk.Unlock()
+	delete(metaExtensions, key)
+}
+
+func registeredExtension(key string) (MetaExtension, bool) {
+	extensionsLk.RLock()
+	defer extensionsLk.RUnlock()
+	ext, ok := metaExtensions[key]
+	return ext, ok
+}
+
+// Extensions returns all namespaced extension values in a metadata struct,
+// excluding un-namespaced arbitrary metadata
+func (md *Meta) Extensions() map[string]interface{} {
+	exts := map[string]interface{}{}
+	for key, val := range md.meta {
+		if IsExtensionKey(key) {
+			exts[key] = val
+		}
+	}
+	return exts
+}
+
+// Extension gets the value of a namespaced extension
+func (md *Meta) Extension(key string) (interface{}, bool) {
+	if !IsExtensionKey(key) {
+		return nil, false
+	}
+	val, ok := md.meta[key]
+	return val, ok
+}
+
+// SetExtension writes a namespaced extension value. Values are stored in
+// their json.Unmarshal-style form, so an extension reads back the same way
+// before and after encoding. Registered extensions are validated before
+// being set
+func (md *Meta) SetExtension(key string, val interface{}) error {
+	if !IsExtensionKey(key) {
+		return fmt.Errorf("invalid extension key '%s'. key must be in the form [namespace]:[name]", key)
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("encoding extension '%s': %s", key, err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("encoding extension '%s': %s", key, err)
+	}
+	if err := validateExtension(key, v); err != nil {
+		return err
+	}
+
+	if md.meta == nil {
+		md.meta = map[string]interface{}{}
+	}
+	md.meta[key] = v
+	return nil
+}
+
+// DeleteExtension removes a namespaced extension value
+func (md *Meta) DeleteExtension(key string) {
+	if IsExtensionKey(key) {
+		delete(md.meta, key)
+	}
+}
+
+// ValidateExtensions runs the validation hook of each registered extension
+// present in metadata, returning the first error
+func (md *Meta) ValidateExtensions() error {
+	keys := make([]string, 0, len(md.meta))
+	for key := range md.meta {
+		if IsExtensionKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := validateExtension(key, md.meta[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateExtension(key string, val interface{}) error {
+	if ext, ok := registeredExtension(key); ok && ext.Validate != nil {
+		if err := ext.Validate(val); err != nil {
+			return fmt.Errorf("extension '%s': %s", key, err)
+		}
+	}
+	return nil
+}
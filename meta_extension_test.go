@@ -0,0 +1,140 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestIsExtensionKey(t *testing.T) {
+	cases := []struct {
+		key string
+		ok  bool
+	}{
+		{"mfdz:qualityReport", true},
+		{"ckan:extras.source", true},
+		{"qualityReport", false},
+		{"mfdz:", false},
+		{":qualityReport", false},
+		{"mfdz:quality:report", false},
+	}
+
+	for i, c := range cases {
+		if got := IsExtensionKey(c.key); got != c.ok {
+			t.Errorf("case %d '%s': expected %t, got %t", i, c.key, c.ok, got)
+		}
+	}
+}
+
+func TestRegisterMetaExtension(t *testing.T) {
+	ext := MetaExtension{
+		Key: "test:score",
+		Validate: func(val interface{}) error {
+			if _, ok := val.(float64); !ok {
+				return fmt.Errorf("score must be a number")
+			}
+			return nil
+		},
+	}
+	if err := RegisterMetaExtension(ext); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterMetaExtension(ext.Key)
+
+	if err := RegisterMetaExtension(ext); err == nil || err.Error() != "extension 'test:score' is already registered" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := RegisterMetaExtension(MetaExtension{Key: "score"}); err == nil || err.Error() != "invalid extension key 'score'. key must be in the form [namespace]:[name]" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	md := &Meta{}
+	if err := md.SetExtension("test:score", "high"); err == nil || err.Error() != "extension 'test:score': score must be a number" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := md.SetExtension("test:score", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	// validation hooks also run on decoded metadata
+	if err := json.Unmarshal([]byte(`{"test:score":"high"}`), md); err != nil {
+		t.Fatal(err)
+	}
+	if err := md.ValidateExtensions(); err == nil || err.Error() != "extension 'test:score': score must be a number" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMetaExtensionsRoundTrip(t *testing.T) {
+	type report struct {
+		Score  int      `json:"score"`
+		Issues []string `json:"issues"`
+	}
+
+	md := &Meta{Title: "stops"}
+	if err := md.SetExtension("mfdz:qualityReport", report{Score: 3, Issues: []string{"missing shapes"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := md.SetExtension("qualityReport", 3); err == nil {
+		t.Errorf("expected un-namespaced key to error")
+	}
+	md.SetArbitrary("legacy", true)
+
+	before, _ := md.Extension("mfdz:qualityReport")
+	data, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Meta{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	after, ok := got.Extension("mfdz:qualityReport")
+	if !ok {
+		t.Fatalf("expected extension to survive round trip")
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("extension mismatch. before: %v, after: %v", before, after)
+	}
+
+	expect := map[string]interface{}{"mfdz:qualityReport": after}
+	if exts := got.Extensions(); !reflect.DeepEqual(expect, exts) {
+		t.Errorf("extensions mismatch. expected: %v, got: %v", expect, exts)
+	}
+	if got.Meta()["legacy"] != true {
+		t.Errorf("expected un-namespaced arbitrary metadata to be preserved")
+	}
+
+	got.DeleteExtension("mfdz:qualityReport")
+	if _, ok := got.Extension("mfdz:qualityReport"); ok {
+		t.Errorf("expected extension to be deleted")
+	}
+}
+
+func TestMetaMarshalDoesNotHoistFields(t *testing.T) {
+	md := &Meta{Title: "a", Keywords: []string{"a"}}
+	if _, err := json.Marshal(md); err != nil {
+		t.Fatal(err)
+	}
+	if len(md.Meta()) != 0 {
+		t.Errorf("marshaling shouldn't write to arbitrary metadata, got: %v", md.Meta())
+	}
+
+	md.Title = ""
+	data, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"keywords":["a"],"qri":"md:0"}` {
+		t.Errorf("unexpected encoding: %s", string(data))
+	}
+
+	got := &Meta{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Meta()["keywords"]; ok {
+		t.Errorf("keywords shouldn't be hoisted into arbitrary metadata")
+	}
+}
@@ -0,0 +1,150 @@
+package dataset
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MetaTranslation holds language-specific versions of human-readable metadata
+// fields. Empty fields fall back to the default values set on Meta
+type MetaTranslation struct {
+	// Title of this dataset in the translated language
+	Title string `json:"title,omitempty"`
+	// Description of this dataset in the translated language
+	Description string `json:"description,omitempty"`
+	// Keywords for this dataset in the translated language
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// IsEmpty checks to see if a translation has no values set
+func (t *MetaTranslation) IsEmpty() bool {
+	return t.Title == "" && t.Description == "" && t.Keywords == nil
+}
+
+// Clone returns a copy of a translation
+func (t *MetaTranslation) Clone() *MetaTranslation {
+	if t == nil {
+		return nil
+	}
+	return &MetaTranslation{
+		Title:       t.Title,
+		Description: t.Description,
+		Keywords:    cloneStrings(t.Keywords),
+	}
+}
+
+// Decode reads json.Umarshal-style data into a MetaTranslation
+func (t *MetaTranslation) Decode(val interface{}) (err error) {
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	if t.Title, err = strVal(msi["title"]); err != nil {
+		return fmt.Errorf("title: %s", err)
+	}
+	if t.Description, err = strVal(msi["description"]); err != nil {
+		return fmt.Errorf("description: %s", err)
+	}
+	if t.Keywords, err = strSliceVal(msi["keywords"]); err != nil {
+		return fmt.Errorf("keywords: %s", err)
+	}
+	return nil
+}
+
+// decodeTranslations reads a json.Unmarshal-style map of language tags to
+// translations
+func decodeTranslations(val interface{}) (map[string]*MetaTranslation, error) {
+	if val == nil {
+		return nil, nil
+	}
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("translations: expected map[string]interface{}")
+	}
+	trs := make(map[string]*MetaTranslation, len(msi))
+	for tag, v := range msi {
+		t := &MetaTranslation{}
+		if err := t.Decode(v); err != nil {
+			return nil, fmt.Errorf("parsing translations '%s': %s", tag, err)
+		}
+		trs[tag] = t
+	}
+	return trs, nil
+}
+
+// languageTag is a loose match for BCP-47 language tags: a 2-3 letter primary
+// language subtag followed by any number of 1-8 character alphanumeric subtags
+var languageTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$`)
+
+// ValidLanguageTag checks that a string is a well-formed BCP-47 language tag
+func ValidLanguageTag(tag string) bool {
+	return languageTag.MatchString(tag)
+}
+
+func sortedTranslationTags(trs map[string]*MetaTranslation) []string {
+	tags := make([]string, 0, len(trs))
+	for tag := range trs {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// translations lists candidate translations for a language tag from most to
+// least specific, trying an exact match first (case-insensitive), then
+// successively shorter prefixes of the tag, so "de-CH" falls back to "de"
+func (md *Meta) translations(tag string) []*MetaTranslation {
+	if md.Translations == nil || tag == "" {
+		return nil
+	}
+	var found []*MetaTranslation
+	for tag != "" {
+		for t, tr := range md.Translations {
+			if tr != nil && strings.EqualFold(t, tag) {
+				found = append(found, tr)
+			}
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return found
+}
+
+// LocalizedTitle returns the title of a dataset in the language identified
+// by tag, falling back to the default Title if no translation exists
+func (md *Meta) LocalizedTitle(tag string) string {
+	for _, tr := range md.translations(tag) {
+		if tr.Title != "" {
+			return tr.Title
+		}
+	}
+	return md.Title
+}
+
+// LocalizedDescription returns the description of a dataset in the language
+// identified by tag, falling back to the default Description if no
+// translation exists
+func (md *Meta) LocalizedDescription(tag string) string {
+	for _, tr := range md.translations(tag) {
+		if tr.Description != "" {
+			return tr.Description
+		}
+	}
+	return md.Description
+}
+
+// LocalizedKeywords returns dataset keywords in the language identified by
+// tag, falling back to the default Keywords if no translation exists
+func (md *Meta) LocalizedKeywords(tag string) []string {
+	for _, tr := range md.translations(tag) {
+		if tr.Keywords != nil {
+			return tr.Keywords
+		}
+	}
+	return md.Keywords
+}
@@ -0,0 +1,118 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMetaTranslationsJSON(t *testing.T) {
+	data := []byte(`{"qri":"md:0","title":"Haltestellen","translations":{"en":{"title":"Stops","keywords":["transit"]}}}`)
+	md := &Meta{}
+	if err := json.Unmarshal(data, md); err != nil {
+		t.Fatal(err)
+	}
+	if md.Meta()["translations"] != nil {
+		t.Errorf("translations should not be hoisted into arbitrary metadata")
+	}
+	if md.Translations["en"] == nil || md.Translations["en"].Title != "Stops" {
+		t.Fatalf("expected english translation to decode, got: %v", md.Translations)
+	}
+
+	got, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md2 := &Meta{}
+	if err := json.Unmarshal(got, md2); err != nil {
+		t.Fatal(err)
+	}
+	if err := CompareMetas(md, md2); err != nil {
+		t.Errorf("round trip mismatch: %s", err)
+	}
+
+	// documents without translations are unchanged
+	plain := &Meta{}
+	if err := json.Unmarshal([]byte(`{"title":"Haltestellen"}`), plain); err != nil {
+		t.Fatal(err)
+	}
+	if plain.Translations != nil {
+		t.Errorf("expected nil translations")
+	}
+}
+
+func TestMetaSetTranslations(t *testing.T) {
+	md := &Meta{}
+	err := md.Set("translations", map[string]interface{}{
+		"en": map[string]interface{}{"title": "Stops", "keywords": []interface{}{"transit"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.LocalizedTitle("en") != "Stops" {
+		t.Errorf("expected title 'Stops', got '%s'", md.LocalizedTitle("en"))
+	}
+
+	err = md.Set("translations", map[string]interface{}{"en": map[string]interface{}{"title": 5}})
+	expect := "parsing translations 'en': title: type must be a string"
+	if err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: '%s', got: '%v'", expect, err)
+	}
+}
+
+func TestMetaLocalized(t *testing.T) {
+	md := &Meta{
+		Title:       "Haltestellen",
+		Description: "Alle Haltestellen",
+		Keywords:    []string{"verkehr"},
+		Translations: map[string]*MetaTranslation{
+			"en":    {Title: "Stops", Description: "All stops", Keywords: []string{"transit"}},
+			"en-GB": {Title: "Bus stops"},
+		},
+	}
+
+	cases := []struct {
+		tag, title, desc string
+		keywords         []string
+	}{
+		{"", "Haltestellen", "Alle Haltestellen", []string{"verkehr"}},
+		{"de", "Haltestellen", "Alle Haltestellen", []string{"verkehr"}},
+		{"en", "Stops", "All stops", []string{"transit"}},
+		{"EN", "Stops", "All stops", []string{"transit"}},
+		{"en-US", "Stops", "All stops", []string{"transit"}},
+		{"en-GB", "Bus stops", "All stops", []string{"transit"}},
+	}
+
+	for i, c := range cases {
+		if got := md.LocalizedTitle(c.tag); got != c.title {
+			t.Errorf("case %d title mismatch. expected: '%s', got: '%s'", i, c.title, got)
+		}
+		if got := md.LocalizedDescription(c.tag); got != c.desc {
+			t.Errorf("case %d description mismatch. expected: '%s', got: '%s'", i, c.desc, got)
+		}
+		if err := CompareStringSlices(c.keywords, md.LocalizedKeywords(c.tag)); err != nil {
+			t.Errorf("case %d keywords mismatch: %s", i, err)
+		}
+	}
+}
+
+func TestValidLanguageTag(t *testing.T) {
+	cases := []struct {
+		tag   string
+		valid bool
+	}{
+		{"en", true},
+		{"de-CH", true},
+		{"zh-Hant-TW", true},
+		{"", false},
+		{"e", false},
+		{"english", false},
+		{"en_US", false},
+		{"en-", false},
+	}
+
+	for i, c := range cases {
+		if got := ValidLanguageTag(c.tag); got != c.valid {
+			t.Errorf("case %d '%s': expected %t, got %t", i, c.tag, c.valid, got)
+		}
+	}
+}
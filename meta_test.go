@@ -142,6 +142,11 @@ func TestMetaSet(t *testing.T) {
 				"email": "email@steve.com",
 			}}, "", &Meta{Contributors: []*User{&User{ID: "steve", Email: "email@steve.com"}}}},
 
+		{"publisher", 0, "expected map[string]interface{}", nil},
+		{"publisher", map[string]interface{}{"name": "MFDZ", "url": "https://mfdz.de"}, "", &Meta{Publisher: &Publisher{Name: "MFDZ", URL: "https://mfdz.de"}}},
+		{"contactPoint", 0, "expected map[string]interface{}", nil},
+		{"contactPoint", map[string]interface{}{"email": "data@mfdz.de"}, "", &Meta{ContactPoint: &ContactPoint{Email: "data@mfdz.de"}}},
+
 		{"license", 0, "expected map[string]interface{}", nil},
 		{"license", map[string]interface{}{
 			"type": "foo",
@@ -298,6 +303,24 @@ func TestCitationDecode(t *testing.T) {
 	}
 }
 
+func TestPublisherDecode(t *testing.T) {
+	p := &Publisher{}
+	for _, key := range []string{"name", "url", "email", "identifier"} {
+		if err := p.Decode(map[string]interface{}{key: 0}); err == nil {
+			t.Errorf("expected error decoding invalid %s", key)
+		}
+	}
+}
+
+func TestContactPointDecode(t *testing.T) {
+	cp := &ContactPoint{}
+	for _, key := range []string{"name", "url", "email", "identifier"} {
+		if err := cp.Decode(map[string]interface{}{key: 0}); err == nil {
+			t.Errorf("expected error decoding invalid %s", key)
+		}
+	}
+}
+
 func TestLicenseDecode(t *testing.T) {
 	l := &License{}
 	if err := l.Decode(map[string]interface{}{"type": 0}); err == nil {
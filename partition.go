@@ -0,0 +1,159 @@
+package dataset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+// Partition granularities group the datetime or date values of a partition
+// column into periods
+const (
+	// PartitionYear partitions by year, keys like "2020"
+	PartitionYear = "year"
+	// PartitionMonth partitions by month, keys like "2020-03"
+	PartitionMonth = "month"
+	// PartitionDay partitions by day, keys like "2020-03-31"
+	PartitionDay = "day"
+	// PartitionHour partitions by hour, keys like "2020-03-31T13". hours are
+	// taken from datetimes in UTC
+	PartitionHour = "hour"
+)
+
+// partitionLayouts are the key layouts of each granularity. Keys of a
+// granularity sort in time order
+var partitionLayouts = map[string]string{
+	PartitionYear:  "2006",
+	PartitionMonth: "2006-01",
+	PartitionDay:   "2006-01-02",
+	PartitionHour:  "2006-01-02T15",
+}
+
+// Partition declares a body is stored as shards, one for each value of a
+// partition column, so readers can skip shards they don't need. Measurement
+// bodies are usually partitioned by a date or datetime column with a
+// granularity, eg. one shard per month
+type Partition struct {
+	// Column is the title of the column rows are partitioned by. Object rows
+	// are partitioned by the value of the Column key
+	Column string `json:"column"`
+	// Granularity groups date & datetime values of the column into periods,
+	// one of PartitionYear, PartitionMonth, PartitionDay or PartitionHour.
+	// Without a granularity every distinct value is a partition
+	Granularity string `json:"granularity,omitempty"`
+}
+
+// Clone returns a copy of a partition
+func (p *Partition) Clone() *Partition {
+	if p == nil {
+		return nil
+	}
+	return &Partition{Column: p.Column, Granularity: p.Granularity}
+}
+
+// Validate checks a partition is well formed
+func (p *Partition) Validate() error {
+	if p.Column == "" {
+		return fmt.Errorf("column is required")
+	}
+	if _, ok := partitionLayouts[p.Granularity]; p.Granularity != "" && !ok {
+		return fmt.Errorf("invalid granularity '%s', must be one of year, month, day or hour", p.Granularity)
+	}
+	return nil
+}
+
+// Key gives the partition key of a partition column value. Values of
+// partitions with a granularity must be dates, datetimes or strings of
+// either, and are keyed by period. Other values are keyed by their text, and
+// null values have the empty key
+func (p *Partition) Key(v interface{}) (string, error) {
+	if p.Granularity != "" {
+		return p.periodKey(v)
+	}
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case int:
+		return strconv.Itoa(x), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case fmt.Stringer:
+		return x.String(), nil
+	}
+	return "", fmt.Errorf("partition column %q: cannot key %T values", p.Column, v)
+}
+
+// periodKey gives the key of the period a temporal value is in
+func (p *Partition) periodKey(v interface{}) (string, error) {
+	layout, ok := partitionLayouts[p.Granularity]
+	if !ok {
+		return "", fmt.Errorf("invalid granularity '%s'", p.Granularity)
+	}
+	var t time.Time
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case time.Time:
+		t = x
+	case vals.DateTime:
+		t = x.Time()
+	case vals.Date:
+		t = x.Time()
+	case string:
+		if strings.TrimSpace(x) == "" {
+			return "", nil
+		}
+		val, err := vals.ParseTemporal(x)
+		if err != nil {
+			return "", fmt.Errorf("partition column %q: %w", p.Column, err)
+		}
+		switch tv := val.(type) {
+		case vals.DateTime:
+			t = tv.Time()
+		case vals.Date:
+			t = tv.Time()
+		default:
+			return "", fmt.Errorf("partition column %q: '%s' is a time of day, partitions by %s require dates", p.Column, x, p.Granularity)
+		}
+	default:
+		return "", fmt.Errorf("partition column %q: partitions by %s require dates, got %T", p.Column, p.Granularity, v)
+	}
+	return t.UTC().Format(layout), nil
+}
+
+// BodyShard is a stored part of a partitioned body holding the rows of one
+// partition key. Shards are written in the structure format
+type BodyShard struct {
+	// Key is the partition key of every row in the shard
+	Key string `json:"key"`
+	// Path is the location of the shard body
+	Path string `json:"path,omitempty"`
+	// Entries is the number of entries in the shard
+	Entries int `json:"entries"`
+	// Length is the size of the shard body in bytes
+	Length int `json:"length,omitempty"`
+}
+
+// cloneShards returns a deep copy of a shard list
+func cloneShards(shards []*BodyShard) []*BodyShard {
+	if shards == nil {
+		return nil
+	}
+	cp := make([]*BodyShard, len(shards))
+	for i, s := range shards {
+		if s != nil {
+			c := *s
+			cp[i] = &c
+		}
+	}
+	return cp
+}
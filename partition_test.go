@@ -0,0 +1,66 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestPartitionKey(t *testing.T) {
+	date, _ := vals.ParseDate("2020-03-31")
+	ts := time.Date(2020, 3, 31, 23, 30, 0, 0, time.FixedZone("", -2*60*60))
+
+	cases := []struct {
+		granularity string
+		value       interface{}
+		expect      string
+		err         string
+	}{
+		{"", "Feuersee", "Feuersee", ""},
+		{"", 12.5, "12.5", ""},
+		{"", int64(7), "7", ""},
+		{"", nil, "", ""},
+		{"", []interface{}{}, "", `partition column "date": cannot key []interface {} values`},
+		{PartitionYear, "2020-03-31", "2020", ""},
+		{PartitionMonth, "2020-03-31T13:45:00Z", "2020-03", ""},
+		{PartitionDay, date, "2020-03-31", ""},
+		{PartitionDay, ts, "2020-04-01", ""},
+		{PartitionHour, vals.NewDateTime(ts), "2020-04-01T01", ""},
+		{PartitionMonth, "", "", ""},
+		{PartitionMonth, "13:45:00", "", `partition column "date": '13:45:00' is a time of day, partitions by month require dates`},
+		{PartitionMonth, 2020.0, "", `partition column "date": partitions by month require dates, got float64`},
+		{"week", "2020-03-31", "", `invalid granularity 'week'`},
+	}
+
+	for i, c := range cases {
+		p := &Partition{Column: "date", Granularity: c.granularity}
+		got, err := p.Key(c.value)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d mismatch. expected: %q, got: %q", i, c.expect, got)
+		}
+	}
+}
+
+func TestPartitionValidate(t *testing.T) {
+	cases := []struct {
+		p   *Partition
+		err string
+	}{
+		{&Partition{Column: "date", Granularity: PartitionMonth}, ""},
+		{&Partition{Column: "station"}, ""},
+		{&Partition{Granularity: PartitionDay}, "column is required"},
+		{&Partition{Column: "date", Granularity: "week"}, "invalid granularity 'week', must be one of year, month, day or hour"},
+	}
+
+	for i, c := range cases {
+		err := c.p.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset/vals"
+)
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to a dataset document,
+// returning a new dataset. The input dataset is not modified. Patched datasets
+// are checked for component well-formedness. Open files on the input dataset
+// are carried over to the result if the path they were opened from is unchanged
+func ApplyMergePatch(ds *Dataset, patch []byte) (*Dataset, error) {
+	return applyDatasetPatch(ds, patch, MergePatchBytes)
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to a dataset document,
+// returning a new dataset. The input dataset is not modified. Patched datasets
+// are checked for component well-formedness. Open files on the input dataset
+// are carried over to the result if the path they were opened from is unchanged
+func ApplyJSONPatch(ds *Dataset, patch []byte) (*Dataset, error) {
+	return applyDatasetPatch(ds, patch, JSONPatchBytes)
+}
+
+func applyDatasetPatch(ds *Dataset, patch []byte, apply func(doc, patch []byte) ([]byte, error)) (*Dataset, error) {
+	if ds == nil {
+		ds = &Dataset{}
+	}
+	// always encode as an object, even if ds is a path reference
+	doc, err := json.Marshal(_dataset(*ds))
+	if err != nil {
+		return nil, fmt.Errorf("encoding dataset: %s", err)
+	}
+	patched, err := apply(doc, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Dataset{}
+	if err := json.Unmarshal(patched, res); err != nil {
+		return nil, fmt.Errorf("decoding patched dataset: %s", err)
+	}
+	if err := validatePatchedComponents(res); err != nil {
+		return nil, fmt.Errorf("invalid patch result: %s", err)
+	}
+	carryOverFiles(ds, res)
+	return res, nil
+}
+
+// validatePatchedComponents checks the well-formedness of every component
+// present in a dataset, without requiring any components be present
+func validatePatchedComponents(ds *Dataset) error {
+	if err := validateKind(ds.Qri, KindDataset); err != nil {
+		return err
+	}
+	if ds.Commit != nil {
+		if err := validateKind(ds.Commit.Qri, KindCommit); err != nil {
+			return fmt.Errorf("commit: %s", err)
+		}
+	}
+	if ds.Structure != nil {
+		if err := validateStructure(ds.Structure); err != nil {
+			return fmt.Errorf("structure: %s", err)
+		}
+	}
+	if ds.Meta != nil {
+		if err := validateMeta(ds.Meta); err != nil {
+			return fmt.Errorf("meta: %s", err)
+		}
+	}
+	if ds.Transform != nil {
+		if err := validateTransform(ds.Transform); err != nil {
+			return fmt.Errorf("transform: %s", err)
+		}
+	}
+	if ds.Viz != nil {
+		if err := validateViz(ds.Viz, ds.Structure); err != nil {
+			return fmt.Errorf("viz: %s", err)
+		}
+	}
+	if ds.Readme != nil {
+		if err := validateReadme(ds.Readme); err != nil {
+			return fmt.Errorf("readme: %s", err)
+		}
+	}
+	if ds.Provenance != nil {
+		if err := validateProvenance(ds.Provenance); err != nil {
+			return fmt.Errorf("provenance: %s", err)
+		}
+	}
+	if ds.Preview != nil {
+		if err := validatePreview(ds.Preview); err != nil {
+			return fmt.Errorf("preview: %s", err)
+		}
+	}
+	if ds.Stats != nil {
+		if err := validateStats(ds.Stats); err != nil {
+			return fmt.Errorf("stats: %s", err)
+		}
+	}
+	return nil
+}
+
+// carryOverFiles assigns unexported files from one dataset to another when
+// the path each file was resolved with is unchanged
+func carryOverFiles(from, to *Dataset) {
+	if from.bodyFile != nil && from.BodyPath == to.BodyPath {
+		to.bodyFile = from.bodyFile
+	}
+	if from.Transform != nil && to.Transform != nil && from.Transform.ScriptPath == to.Transform.ScriptPath {
+		to.Transform.scriptFile = from.Transform.scriptFile
+	}
+	if from.Transform != nil && to.Transform != nil {
+		for _, s := range from.Transform.Steps {
+			if s == nil {
+				continue
+			}
+			if t := to.Transform.Step(s.Name); t != nil && t.ScriptPath == s.ScriptPath {
+				t.scriptFile = s.scriptFile
+			}
+		}
+		for name, f := range from.Transform.Files {
+			if g, ok := to.Transform.Files[name]; ok && f != nil && g != nil && f.Path == g.Path {
+				g.file = f.file
+			}
+		}
+	}
+	if from.Viz != nil && to.Viz != nil {
+		if from.Viz.ScriptPath == to.Viz.ScriptPath {
+			to.Viz.scriptFile = from.Viz.scriptFile
+		}
+		if from.Viz.RenderedPath == to.Viz.RenderedPath {
+			to.Viz.renderedFile = from.Viz.renderedFile
+		}
+	}
+	if from.Readme != nil && to.Readme != nil {
+		if from.Readme.ScriptPath == to.Readme.ScriptPath {
+			to.Readme.scriptFile = from.Readme.scriptFile
+		}
+		if from.Readme.RenderedPath == to.Readme.RenderedPath {
+			to.Readme.renderedFile = from.Readme.renderedFile
+		}
+	}
+}
+
+// MergePatchBytes applies an RFC 7386 JSON Merge Patch to a JSON document.
+// It works with the JSON encoding of any dataset component
+func MergePatchBytes(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("decoding document: %s", err)
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("decoding merge patch: %s", err)
+	}
+	return json.Marshal(MergePatch(target, p))
+}
+
+// MergePatch applies an RFC 7386 merge patch to a json.Unmarshal-style value,
+// returning the patched value. map values in target may be modified in place
+func MergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, val := range p {
+		if val == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = MergePatch(t[key], val)
+	}
+	return t
+}
+
+// PatchOperation is a single RFC 6902 JSON Patch operation. Add, replace &
+// test operations require a value. A nil Value is only a JSON null when the
+// operation is decoded with one, otherwise the value is missing
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value"`
+
+	// valueSet records a value was decoded, null values included
+	valueSet bool
+}
+
+// hasValue reports whether an operation has a value
+func (op PatchOperation) hasValue() bool {
+	return op.Value != nil || op.valueSet
+}
+
+// needsValue reports whether op is an operation that requires a value
+func (op PatchOperation) needsValue() bool {
+	return op.Op == "add" || op.Op == "replace" || op.Op == "test"
+}
+
+// MarshalJSON implements the json.Marshaler interface, only writing values
+// of operations that require one
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	if !op.needsValue() {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+			From string `json:"from,omitempty"`
+		}{op.Op, op.Path, op.From})
+	}
+	type _patchOperation PatchOperation
+	return json.Marshal(_patchOperation(op))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, recording if the
+// operation has a value so a missing value can be told apart from null
+func (op *PatchOperation) UnmarshalJSON(data []byte) error {
+	_op := struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		From  string          `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}{}
+	if err := json.Unmarshal(data, &_op); err != nil {
+		return err
+	}
+	*op = PatchOperation{Op: _op.Op, Path: _op.Path, From: _op.From}
+	if _op.Value != nil {
+		op.valueSet = true
+		return json.Unmarshal(_op.Value, &op.Value)
+	}
+	return nil
+}
+
+// JSONPatchBytes applies an RFC 6902 JSON Patch to a JSON document. It works
+// with the JSON encoding of any dataset component
+func JSONPatchBytes(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("decoding document: %s", err)
+	}
+	ops := []PatchOperation{}
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("decoding json patch: %s", err)
+	}
+	res, err := JSONPatch(target, ops)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(res)
+}
+
+// JSONPatch applies a slice of RFC 6902 operations to a json.Unmarshal-style
+// value in order, returning the result. Application stops at the first
+// failing operation
+func JSONPatch(doc interface{}, ops []PatchOperation) (interface{}, error) {
+	var err error
+	for i, op := range ops {
+		if doc, err = applyOperation(doc, op); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %s", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	if op.needsValue() && !op.hasValue() {
+		return nil, fmt.Errorf("value is required")
+	}
+	switch op.Op {
+	case "add":
+		return pointerAdd(doc, op.Path, op.Value)
+	case "remove":
+		doc, _, err := pointerRemove(doc, op.Path)
+		return doc, err
+	case "replace":
+		doc, _, err := pointerRemove(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, op.Path, op.Value)
+	case "move":
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move a value into one of it's children")
+		}
+		doc, val, err := pointerRemove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, op.Path, val)
+	case "copy":
+		val, err := pointerGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, op.Path, cloneValue(val))
+	case "test":
+		val, err := pointerGet(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(val, op.Value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+func arrayIndex(tok string, length int, allowEnd bool) (int, error) {
+	if tok == "-" && allowEnd {
+		return length, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && tok[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func pointerGet(doc interface{}, ptr string) (interface{}, error) {
+	toks, err := vals.ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	for _, tok := range toks {
+		switch x := doc.(type) {
+		case map[string]interface{}:
+			val, ok := x[tok]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", tok)
+			}
+			doc = val
+		case []interface{}:
+			i, err := arrayIndex(tok, len(x), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = x[i]
+		default:
+			return nil, fmt.Errorf("cannot index into %T with %q", doc, tok)
+		}
+	}
+	return doc, nil
+}
+
+// pointerAdd sets val at ptr, returning the updated document
+func pointerAdd(doc interface{}, ptr string, val interface{}) (interface{}, error) {
+	toks, err := vals.ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return val, nil
+	}
+	return setIn(doc, toks, func(parent interface{}, tok string) (interface{}, error) {
+		switch x := parent.(type) {
+		case map[string]interface{}:
+			x[tok] = val
+			return x, nil
+		case []interface{}:
+			i, err := arrayIndex(tok, len(x), true)
+			if err != nil {
+				return nil, err
+			}
+			x = append(x, nil)
+			copy(x[i+1:], x[i:])
+			x[i] = val
+			return x, nil
+		default:
+			return nil, fmt.Errorf("cannot add to %T", parent)
+		}
+	})
+}
+
+// pointerRemove deletes the value at ptr, returning the updated document and
+// the removed value
+func pointerRemove(doc interface{}, ptr string) (interface{}, interface{}, error) {
+	toks, err := vals.ParsePointer(ptr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(toks) == 0 {
+		return nil, doc, nil
+	}
+	var removed interface{}
+	doc, err = setIn(doc, toks, func(parent interface{}, tok string) (interface{}, error) {
+		switch x := parent.(type) {
+		case map[string]interface{}:
+			val, ok := x[tok]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", tok)
+			}
+			removed = val
+			delete(x, tok)
+			return x, nil
+		case []interface{}:
+			i, err := arrayIndex(tok, len(x), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = x[i]
+			return append(x[:i:i], x[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove from %T", parent)
+		}
+	})
+	return doc, removed, err
+}
+
+// setIn walks to the parent of the last token & calls fn with it, rebuilding
+// the path back to the root with the value fn returns. rebuilding is required
+// because slices may be reallocated when modified
+func setIn(doc interface{}, toks []string, fn func(parent interface{}, tok string) (interface{}, error)) (interface{}, error) {
+	if len(toks) == 1 {
+		return fn(doc, toks[0])
+	}
+
+	tok := toks[0]
+	switch x := doc.(type) {
+	case map[string]interface{}:
+		child, ok := x[tok]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", tok)
+		}
+		updated, err := setIn(child, toks[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		x[tok] = updated
+		return x, nil
+	case []interface{}:
+		i, err := arrayIndex(tok, len(x), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := setIn(x[i], toks[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		x[i] = updated
+		return x, nil
+	default:
+		return nil, fmt.Errorf("cannot index into %T with %q", doc, tok)
+	}
+}
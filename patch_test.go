@@ -0,0 +1,201 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qri-io/qfs"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	body := qfs.NewMemfileBytes("body.json", []byte(`[]`))
+	ds := &Dataset{
+		BodyPath: "/ipfs/QmBody",
+		Meta:     &Meta{Title: "title", Description: "description", Keywords: []string{"a"}},
+		Structure: &Structure{
+			Format: "json",
+			Schema: BaseSchemaArray,
+		},
+	}
+	ds.SetBodyFile(body)
+
+	got, err := ApplyMergePatch(ds, []byte(`{"meta":{"title":"new title","description":null,"custom":"value"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Meta.Title != "new title" {
+		t.Errorf("expected title to be patched. got: %q", got.Meta.Title)
+	}
+	if got.Meta.Description != "" {
+		t.Errorf("expected description to be removed. got: %q", got.Meta.Description)
+	}
+	if got.Meta.Keywords[0] != "a" {
+		t.Errorf("expected keywords to be preserved")
+	}
+	if got.Meta.Meta()["custom"] != "value" {
+		t.Errorf("expected arbitrary field to be hoisted into meta")
+	}
+	if got.Structure.Format != "json" {
+		t.Errorf("expected structure to be preserved")
+	}
+	if got.BodyFile() != body {
+		t.Errorf("expected body file to carry over")
+	}
+	if ds.Meta.Title != "title" {
+		t.Errorf("input dataset was modified")
+	}
+
+	if _, err := ApplyMergePatch(ds, []byte(`{"structure":{"format":"tsv"}}`)); err == nil {
+		t.Errorf("expected invalid patch result to error")
+	} else if err.Error() != "invalid patch result: structure: invalid data format: `tsv`" {
+		t.Errorf("error mismatch. got: %s", err)
+	}
+	if _, err := ApplyMergePatch(ds, []byte(`{`)); err == nil {
+		t.Errorf("expected invalid patch json to error")
+	}
+
+	got, err = ApplyMergePatch(ds, []byte(`{"bodyPath":"/ipfs/QmNewBody"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BodyFile() != nil {
+		t.Errorf("expected changed body path to drop body file")
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// test cases from RFC 7386 appendix A
+	cases := []struct {
+		target, patch, expect string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for i, c := range cases {
+		got, err := MergePatchBytes([]byte(c.target), []byte(c.patch))
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d mismatch. want: %s got: %s", i, c.expect, string(got))
+		}
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	ds := &Dataset{
+		Meta:      &Meta{Title: "title", Keywords: []string{"a", "b"}},
+		Structure: &Structure{Format: "json", Schema: BaseSchemaArray},
+	}
+
+	got, err := ApplyJSONPatch(ds, []byte(`[
+		{"op":"test","path":"/meta/title","value":"title"},
+		{"op":"replace","path":"/meta/title","value":"new title"},
+		{"op":"add","path":"/meta/keywords/-","value":"c"},
+		{"op":"remove","path":"/meta/keywords/0"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Meta.Title != "new title" {
+		t.Errorf("expected title to be patched. got: %q", got.Meta.Title)
+	}
+	if len(got.Meta.Keywords) != 2 || got.Meta.Keywords[0] != "b" || got.Meta.Keywords[1] != "c" {
+		t.Errorf("keywords mismatch. got: %v", got.Meta.Keywords)
+	}
+
+	_, err = ApplyJSONPatch(ds, []byte(`[{"op":"test","path":"/meta/title","value":"nope"}]`))
+	if err == nil || err.Error() != "patch operation 0 (test /meta/title): test failed" {
+		t.Errorf("expected failed test operation to error. got: %v", err)
+	}
+}
+
+func TestPatchOperationJSON(t *testing.T) {
+	ops := []PatchOperation{
+		{Op: "add", Path: "/a", Value: nil, valueSet: true},
+		{Op: "test", Path: "/b", Value: false},
+		{Op: "copy", Path: "/c", From: "/a"},
+		{Op: "remove", Path: "/d"},
+	}
+	expect := `[{"op":"add","path":"/a","value":null},{"op":"test","path":"/b","value":false},{"op":"copy","path":"/c","from":"/a"},{"op":"remove","path":"/d"}]`
+	data, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect != string(data) {
+		t.Errorf("encoding mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+
+	got := []PatchOperation{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got[0].hasValue() || got[2].hasValue() {
+		t.Errorf("expected decoding to record value presence. got: %#v", got)
+	}
+	if _, err := JSONPatch(map[string]interface{}{}, []PatchOperation{{Op: "add", Path: "/a"}}); err == nil {
+		t.Errorf("expected add operation without a value to error")
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	// a selection of test cases from RFC 6902 appendix A
+	cases := []struct {
+		doc, patch, expect, err string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, ""},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, ""},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, ""},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, ""},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, ""},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, ""},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, ""},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`, ""},
+		{`{"/":1,"m~n":2}`, `[{"op":"copy","from":"/~1","path":"/m~0n"}]`, `{"/":1,"m~n":1}`, ""},
+		{`["a"]`, `[{"op":"add","path":"","value":{"b":"c"}}]`, `{"b":"c"}`, ""},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "", `patch operation 0 (add /baz/bat): key "baz" not found`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/3","value":"qux"}]`, "", `patch operation 0 (add /foo/3): array index 3 out of range`},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/01"}]`, "", `patch operation 0 (remove /foo/01): invalid array index "01"`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"qux"}]`, "", `patch operation 0 (replace /baz): key "baz" not found`},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, "", `patch operation 0 (move /foo/bar/baz): cannot move a value into one of it's children`},
+		{`{"foo":"bar"}`, `[{"op":"nope","path":"/foo"}]`, "", `patch operation 0 (nope /foo): unknown operation "nope"`},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"foo"}]`, "", `patch operation 0 (remove foo): invalid json pointer 'foo': must be empty or begin with '/'`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, "", `patch operation 0 (add /baz): value is required`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/foo"}]`, "", `patch operation 0 (replace /foo): value is required`},
+		{`{"foo":"bar"}`, `[{"op":"test","path":"/foo"}]`, "", `patch operation 0 (test /foo): value is required`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"baz":null,"foo":"bar"}`, ""},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`, ""},
+	}
+
+	for i, c := range cases {
+		got, err := JSONPatchBytes([]byte(c.doc), []byte(c.patch))
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("case %d error mismatch. want: %s got: %v", i, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d mismatch. want: %s got: %s", i, c.expect, string(got))
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Preview is a small representative sample of a dataset body, stored as a
+// separate component so a dataset can be previewed without reading the full
+// body. A preview holds the first Head entries of the body followed by a
+// random sample of the remaining entries, in body order
+type Preview struct {
+	// Body holds sampled entries in the shape of the dataset body: an array
+	// of entries for array bodies, an object of sampled keys for object
+	// bodies. Body can be read with the dataset structure
+	Body interface{} `json:"body,omitempty"`
+	// Head is the number of leading entries included in Body
+	Head int `json:"head,omitempty"`
+	// Indexes are the body positions of each entry in Body
+	Indexes []int `json:"indexes,omitempty"`
+	// Path is the location of the preview, transient
+	// derived
+	Path string `json:"path,omitempty"`
+	// Qri should always be KindPreview
+	// derived
+	Qri string `json:"qri,omitempty"`
+	// Seed seeds the random sample, making previews reproducible
+	Seed int64 `json:"seed,omitempty"`
+	// Total is the number of entries in the sampled body
+	Total int `json:"total,omitempty"`
+}
+
+// NewPreviewRef creates an empty struct with it's internal path set
+func NewPreviewRef(path string) *Preview {
+	return &Preview{Path: path}
+}
+
+// DropTransientValues removes values that cannot be recorded when the
+// dataset is rendered immutable, usually by storing it in a cafs
+func (p *Preview) DropTransientValues() {
+	p.Path = ""
+}
+
+// DropDerivedValues resets all set-on-save fields to their default values
+func (p *Preview) DropDerivedValues() {
+	p.Qri = ""
+	p.Path = ""
+}
+
+// IsEmpty checks to see if a preview has any fields other than the internal
+// path
+func (p *Preview) IsEmpty() bool {
+	return p.Body == nil &&
+		p.Head == 0 &&
+		p.Indexes == nil &&
+		p.Seed == 0 &&
+		p.Total == 0
+}
+
+// Assign collapses all properties of a group of previews on to one. this is
+// directly inspired by Javascript's Object.assign
+func (p *Preview) Assign(previews ...*Preview) {
+	for _, pr := range previews {
+		if pr == nil {
+			continue
+		}
+		if pr.Body != nil {
+			p.Body = pr.Body
+		}
+		if pr.Head != 0 {
+			p.Head = pr.Head
+		}
+		if pr.Indexes != nil {
+			p.Indexes = pr.Indexes
+		}
+		if pr.Path != "" {
+			p.Path = pr.Path
+		}
+		if pr.Qri != "" {
+			p.Qri = pr.Qri
+		}
+		if pr.Seed != 0 {
+			p.Seed = pr.Seed
+		}
+		if pr.Total != 0 {
+			p.Total = pr.Total
+		}
+	}
+}
+
+// Clone returns a deep copy of a preview. Body is copied with the same rules
+// as Dataset.Clone
+func (p *Preview) Clone() *Preview {
+	if p == nil {
+		return nil
+	}
+	c := &Preview{
+		Body:  cloneValue(p.Body),
+		Head:  p.Head,
+		Path:  p.Path,
+		Qri:   p.Qri,
+		Seed:  p.Seed,
+		Total: p.Total,
+	}
+	if p.Indexes != nil {
+		c.Indexes = make([]int, len(p.Indexes))
+		copy(c.Indexes, p.Indexes)
+	}
+	return c
+}
+
+// _preview is a private struct for marshaling into & out of.
+// fields must remain sorted in lexographical order
+type _preview Preview
+
+// MarshalJSON satisfies the json.Marshaler interface
+func (p *Preview) MarshalJSON() ([]byte, error) {
+	// if we're dealing with an empty object that has a path specified, marshal
+	// to a string instead
+	if p.Path != "" && p.IsEmpty() {
+		return json.Marshal(p.Path)
+	}
+	return p.MarshalJSONObject()
+}
+
+// MarshalJSONObject always marshals to a json Object, even if the preview is
+// empty or a reference
+func (p *Preview) MarshalJSONObject() ([]byte, error) {
+	c := _preview(*p)
+	if c.Qri == "" {
+		c.Qri = KindPreview.String()
+	}
+	return json.Marshal(c)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface
+func (p *Preview) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*p = Preview{Path: s}
+		return nil
+	}
+
+	_p := _preview{}
+	if err := json.Unmarshal(data, &_p); err != nil {
+		return fmt.Errorf("unmarshaling preview: %s", err)
+	}
+	if err := checkKind(_p.Qri); err != nil {
+		return err
+	}
+	*p = Preview(_p)
+	return nil
+}
+
+// UnmarshalPreview tries to extract a preview type from an empty interface.
+// Pairs nicely with datastore.Get() from github.com/ipfs/go-datastore
+func UnmarshalPreview(v interface{}) (*Preview, error) {
+	switch p := v.(type) {
+	case *Preview:
+		return p, nil
+	case Preview:
+		return &p, nil
+	case []byte:
+		pr := &Preview{}
+		err := json.Unmarshal(p, pr)
+		return pr, err
+	default:
+		err := fmt.Errorf("couldn't parse preview, value is invalid type")
+		return nil, err
+	}
+}
+
+func validatePreview(p *Preview) error {
+	if p.Path != "" && p.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(p.Qri, KindPreview); err != nil {
+		return err
+	}
+	if p.Head < 0 {
+		return fmt.Errorf("head cannot be negative")
+	}
+	if p.Indexes != nil && p.Head > len(p.Indexes) {
+		return fmt.Errorf("head %d is greater than the %d sampled entries", p.Head, len(p.Indexes))
+	}
+	if p.Total != 0 && len(p.Indexes) > p.Total {
+		return fmt.Errorf("%d sampled entries exceed the total of %d", len(p.Indexes), p.Total)
+	}
+	for i := 1; i < len(p.Indexes); i++ {
+		if p.Indexes[i] <= p.Indexes[i-1] {
+			return fmt.Errorf("indexes must be in ascending body order")
+		}
+	}
+	return nil
+}
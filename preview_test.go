@@ -0,0 +1,101 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func previewFixture() *Preview {
+	return &Preview{
+		Body:    []interface{}{[]interface{}{"a", 1.0}, []interface{}{"b", 2.0}, []interface{}{"q", 17.0}},
+		Head:    2,
+		Indexes: []int{0, 1, 16},
+		Seed:    42,
+		Total:   100,
+	}
+}
+
+func TestPreviewJSON(t *testing.T) {
+	p := previewFixture()
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"body":[["a",1],["b",2],["q",17]],"head":2,"indexes":[0,1,16],"qri":"pr:0","seed":42,"total":100}`
+	if string(data) != expect {
+		t.Errorf("json mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+	got := &Preview{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	p.Qri = KindPreview.String()
+	if !reflect.DeepEqual(p, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", p, got)
+	}
+
+	data, err = json.Marshal(NewPreviewRef("/ipfs/QmPreview"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"/ipfs/QmPreview"` {
+		t.Errorf("expected reference to marshal as a string, got: %s", data)
+	}
+	ref := &Preview{}
+	if err := json.Unmarshal(data, ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "/ipfs/QmPreview" || !ref.IsEmpty() {
+		t.Errorf("expected path reference, got: %#v", ref)
+	}
+
+	if _, err := UnmarshalPreview(false); err == nil {
+		t.Errorf("expected invalid type to error")
+	}
+}
+
+func TestPreviewAssign(t *testing.T) {
+	p := &Preview{Path: "/ipfs/QmPreview", Head: 5}
+	p.Assign(nil, &Preview{Head: 2, Total: 10})
+	if p.Path != "/ipfs/QmPreview" || p.Head != 2 || p.Total != 10 {
+		t.Errorf("assign mismatch: %#v", p)
+	}
+}
+
+func TestPreviewClone(t *testing.T) {
+	p := previewFixture()
+	c := p.Clone()
+	if !reflect.DeepEqual(p, c) {
+		t.Fatalf("clone mismatch. expected: %#v, got: %#v", p, c)
+	}
+	c.Indexes[0] = 99
+	c.Body.([]interface{})[0].([]interface{})[0] = "changed"
+	if p.Indexes[0] == 99 || p.Body.([]interface{})[0].([]interface{})[0] == "changed" {
+		t.Errorf("clone shares memory with original")
+	}
+	if (*Preview)(nil).Clone() != nil {
+		t.Errorf("expected nil clone to be nil")
+	}
+}
+
+func TestValidatePreview(t *testing.T) {
+	cases := []struct {
+		p   *Preview
+		err string
+	}{
+		{previewFixture(), ""},
+		{NewPreviewRef("/ipfs/QmPreview"), ""},
+		{&Preview{Qri: "pv:0", Total: 1}, "invalid kind: 'pv:0'. expected type 'pr'"},
+		{&Preview{Head: -1}, "head cannot be negative"},
+		{&Preview{Head: 2, Indexes: []int{0}}, "head 2 is greater than the 1 sampled entries"},
+		{&Preview{Indexes: []int{0, 1, 2}, Total: 2}, "3 sampled entries exceed the total of 2"},
+		{&Preview{Indexes: []int{0, 4, 2}, Total: 5}, "indexes must be in ascending body order"},
+	}
+	for i, c := range cases {
+		err := validatePreview(c.p)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ProvenanceNamespace is the namespace used for qri-specific attributes when
+// encoding provenance as PROV-JSON
+const ProvenanceNamespace = "https://qri.io/ns/provenance#"
+
+// Provenance records facts about the activity that produced a dataset
+// version: who ran it, what it read, which transform it executed, and when.
+// Where a Transform describes how a dataset *should* be built, Provenance
+// describes how a particular version *was* built. Provenance maps onto the
+// W3C PROV data model, with the dataset version as the generated entity
+type Provenance struct {
+	// Agent is the person, organization or software responsible for the
+	// activity
+	Agent *ProvenanceAgent `json:"agent,omitempty"`
+	// Ended is when the activity finished
+	Ended time.Time `json:"ended,omitempty"`
+	// Inputs are the resources the activity read
+	Inputs []*ProvenanceInput `json:"inputs,omitempty"`
+	// Path is the location of provenance, transient
+	// derived
+	Path string `json:"path,omitempty"`
+	// Qri should always be KindProvenance
+	// derived
+	Qri string `json:"qri,omitempty"`
+	// Run records the outcome of executing the transform, if any
+	Run *RunState `json:"run,omitempty"`
+	// Started is when the activity began
+	Started time.Time `json:"started,omitempty"`
+	// TransformPath references the transform component that was executed, if
+	// any
+	TransformPath string `json:"transformPath,omitempty"`
+}
+
+// ProvenanceAgent is an entity responsible for a provenance activity
+type ProvenanceAgent struct {
+	// ID is a stable identifier for the agent, eg. a profile ID or URL
+	ID string `json:"id,omitempty"`
+	// Name of the agent
+	Name string `json:"name,omitempty"`
+	// Type is one of "person", "organization" or "software"
+	Type string `json:"type,omitempty"`
+	// Version of a software agent
+	Version string `json:"version,omitempty"`
+}
+
+// ProvenanceInput is a resource that was read while producing a dataset
+// version
+type ProvenanceInput struct {
+	// Name is a short, human-readable name for the input
+	Name string `json:"name,omitempty"`
+	// Path or URL the input was read from
+	Path string `json:"path"`
+	// Hash is a content hash of the input as read, eg. "sha256:[hex]" or a
+	// multihash. Recording a hash makes it possible to tell if an input has
+	// changed since the version was produced
+	Hash string `json:"hash,omitempty"`
+}
+
+// NewProvenanceRef creates an empty struct with it's internal path set
+func NewProvenanceRef(path string) *Provenance {
+	return &Provenance{Path: path}
+}
+
+// DropTransientValues removes values that cannot be recorded when the
+// dataset is rendered immutable, usually by storing it in a cafs
+func (p *Provenance) DropTransientValues() {
+	p.Path = ""
+}
+
+// DropDerivedValues resets all set-on-save fields to their default values
+func (p *Provenance) DropDerivedValues() {
+	p.Qri = ""
+	p.Path = ""
+}
+
+// IsEmpty checks to see if provenance has any fields other than the internal
+// path
+func (p *Provenance) IsEmpty() bool {
+	return p.Agent == nil &&
+		p.Ended.IsZero() &&
+		p.Inputs == nil &&
+		p.Run == nil &&
+		p.Started.IsZero() &&
+		p.TransformPath == ""
+}
+
+// Duration returns the running time of the activity, preferring the recorded
+// run duration. zero if neither the run duration nor either time is known
+func (p *Provenance) Duration() time.Duration {
+	if p.Run != nil && p.Run.Duration != 0 {
+		return p.Run.Duration
+	}
+	if p.Started.IsZero() || p.Ended.IsZero() {
+		return 0
+	}
+	return p.Ended.Sub(p.Started)
+}
+
+// Assign collapses all properties of a group of provenance structs on to
+// one. this is directly inspired by Javascript's Object.assign
+func (p *Provenance) Assign(provs ...*Provenance) {
+	for _, pv := range provs {
+		if pv == nil {
+			continue
+		}
+
+		if pv.Agent != nil {
+			p.Agent = pv.Agent
+		}
+		if !pv.Ended.IsZero() {
+			p.Ended = pv.Ended
+		}
+		if pv.Inputs != nil {
+			p.Inputs = pv.Inputs
+		}
+		if pv.Path != "" {
+			p.Path = pv.Path
+		}
+		if pv.Qri != "" {
+			p.Qri = pv.Qri
+		}
+		if pv.Run != nil {
+			p.Run = pv.Run
+		}
+		if !pv.Started.IsZero() {
+			p.Started = pv.Started
+		}
+		if pv.TransformPath != "" {
+			p.TransformPath = pv.TransformPath
+		}
+	}
+}
+
+// Clone returns a deep copy of provenance
+func (p *Provenance) Clone() *Provenance {
+	if p == nil {
+		return nil
+	}
+	c := &Provenance{
+		Ended:         p.Ended,
+		Path:          p.Path,
+		Qri:           p.Qri,
+		Run:           p.Run.Clone(),
+		Started:       p.Started,
+		TransformPath: p.TransformPath,
+	}
+	if p.Agent != nil {
+		agent := *p.Agent
+		c.Agent = &agent
+	}
+	if p.Inputs != nil {
+		c.Inputs = make([]*ProvenanceInput, len(p.Inputs))
+		for i, in := range p.Inputs {
+			if in != nil {
+				cp := *in
+				c.Inputs[i] = &cp
+			}
+		}
+	}
+	return c
+}
+
+// MarshalJSON satisfies the json.Marshaler interface
+func (p *Provenance) MarshalJSON() ([]byte, error) {
+	// if we're dealing with an empty object that has a path specified, marshal
+	// to a string instead
+	if p.Path != "" && p.IsEmpty() {
+		return json.Marshal(p.Path)
+	}
+	return p.MarshalJSONObject()
+}
+
+// MarshalJSONObject always marshals to a json Object, even if provenance is
+// empty or a reference
+func (p *Provenance) MarshalJSONObject() ([]byte, error) {
+	kind := p.Qri
+	if kind == "" {
+		kind = KindProvenance.String()
+	}
+	data := map[string]interface{}{
+		"qri": kind,
+	}
+
+	if p.Agent != nil {
+		data["agent"] = p.Agent
+	}
+	if !p.Ended.IsZero() {
+		data["ended"] = p.Ended
+	}
+	if p.Inputs != nil {
+		data["inputs"] = p.Inputs
+	}
+	if p.Path != "" {
+		data["path"] = p.Path
+	}
+	if p.Run != nil {
+		data["run"] = p.Run
+	}
+	if !p.Started.IsZero() {
+		data["started"] = p.Started
+	}
+	if p.TransformPath != "" {
+		data["transformPath"] = p.TransformPath
+	}
+	return json.Marshal(data)
+}
+
+type _provenance Provenance
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface
+func (p *Provenance) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*p = Provenance{Path: s}
+		return nil
+	}
+
+	_p := _provenance{}
+	if err := json.Unmarshal(data, &_p); err != nil {
+		return fmt.Errorf("unmarshaling provenance: %s", err)
+	}
+	if err := checkKind(_p.Qri); err != nil {
+		return err
+	}
+	*p = Provenance(_p)
+	return nil
+}
+
+// UnmarshalProvenance tries to extract a provenance type from an empty
+// interface. Pairs nicely with datastore.Get() from github.com/ipfs/go-datastore
+func UnmarshalProvenance(v interface{}) (*Provenance, error) {
+	switch p := v.(type) {
+	case *Provenance:
+		return p, nil
+	case Provenance:
+		return &p, nil
+	case []byte:
+		prov := &Provenance{}
+		err := json.Unmarshal(p, prov)
+		return prov, err
+	default:
+		err := fmt.Errorf("couldn't parse provenance, value is invalid type")
+		return nil, err
+	}
+}
+
+// provAgentTypes maps agent types to PROV agent subtypes
+var provAgentTypes = map[string]string{
+	"person":       "prov:Person",
+	"organization": "prov:Organization",
+	"software":     "prov:SoftwareAgent",
+}
+
+// PROVJSON encodes provenance as a W3C PROV-JSON document
+// (https://www.w3.org/Submission/prov-json/). entityID identifies the
+// generated dataset version, and should be a qualified name, eg.
+// "qri:/ipfs/QmHash". inputs are encoded as entities the activity used, the
+// agent is associated with the activity
+func (p *Provenance) PROVJSON(entityID string) ([]byte, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("entity id is required")
+	}
+	const activityID = "_:activity"
+
+	activity := map[string]interface{}{}
+	if !p.Started.IsZero() {
+		activity["prov:startTime"] = p.Started.Format(time.RFC3339Nano)
+	}
+	if !p.Ended.IsZero() {
+		activity["prov:endTime"] = p.Ended.Format(time.RFC3339Nano)
+	}
+	if p.TransformPath != "" {
+		activity["qri:transform"] = p.TransformPath
+	}
+	if p.Run != nil {
+		if p.Run.EngineVersion != "" {
+			activity["qri:engineVersion"] = p.Run.EngineVersion
+		}
+		if p.Run.ExitStatus != nil {
+			activity["qri:exitStatus"] = *p.Run.ExitStatus
+		}
+	}
+
+	entities := map[string]interface{}{
+		entityID: map[string]interface{}{"prov:type": "qri:dataset"},
+	}
+	doc := map[string]interface{}{
+		"prefix": map[string]string{
+			"qri": ProvenanceNamespace,
+		},
+		"entity":   entities,
+		"activity": map[string]interface{}{activityID: activity},
+		"wasGeneratedBy": map[string]interface{}{
+			"_:generation": map[string]interface{}{
+				"prov:entity":   entityID,
+				"prov:activity": activityID,
+			},
+		},
+	}
+
+	if p.Inputs != nil {
+		used := map[string]interface{}{}
+		for i, in := range p.Inputs {
+			if in == nil {
+				continue
+			}
+			id := "_:input" + strconv.Itoa(i)
+			ent := map[string]interface{}{"qri:path": in.Path}
+			if in.Name != "" {
+				ent["prov:label"] = in.Name
+			}
+			if in.Hash != "" {
+				ent["qri:hash"] = in.Hash
+			}
+			entities[id] = ent
+			used["_:usage"+strconv.Itoa(i)] = map[string]interface{}{
+				"prov:activity": activityID,
+				"prov:entity":   id,
+			}
+		}
+		doc["used"] = used
+	}
+
+	if p.Agent != nil {
+		const agentID = "_:agent"
+		agent := map[string]interface{}{}
+		if t, ok := provAgentTypes[p.Agent.Type]; ok {
+			agent["prov:type"] = t
+		}
+		if p.Agent.Name != "" {
+			agent["prov:label"] = p.Agent.Name
+		}
+		if p.Agent.ID != "" {
+			agent["qri:id"] = p.Agent.ID
+		}
+		if p.Agent.Version != "" {
+			agent["qri:version"] = p.Agent.Version
+		}
+		doc["agent"] = map[string]interface{}{agentID: agent}
+		doc["wasAssociatedWith"] = map[string]interface{}{
+			"_:association": map[string]interface{}{
+				"prov:activity": activityID,
+				"prov:agent":    agentID,
+			},
+		}
+		doc["wasAttributedTo"] = map[string]interface{}{
+			"_:attribution": map[string]interface{}{
+				"prov:entity": entityID,
+				"prov:agent":  agentID,
+			},
+		}
+	}
+
+	return json.Marshal(doc)
+}
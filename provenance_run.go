@@ -0,0 +1,53 @@
+package dataset
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunState records the outcome of executing a transform, persisted with the
+// dataset version it produced as part of provenance. Where started & ended
+// times bound the whole activity, RunState describes the transform execution
+// itself, giving enough detail to debug a failed or slow run after the fact
+type RunState struct {
+	// Duration is how long transform execution took, encoded in nanoseconds
+	Duration time.Duration `json:"duration,omitempty"`
+	// EngineVersion is the version of the runtime that executed the transform
+	// eg. the starlark interpreter version
+	EngineVersion string `json:"engineVersion,omitempty"`
+	// Error is a message describing why the run failed, if it did
+	Error string `json:"error,omitempty"`
+	// ExitStatus is the exit status of the run. zero indicates success. nil
+	// means the exit status is unknown
+	ExitStatus *int `json:"exitStatus,omitempty"`
+	// StderrPath references captured standard error output
+	StderrPath string `json:"stderrPath,omitempty"`
+	// StdoutPath references captured standard output
+	StdoutPath string `json:"stdoutPath,omitempty"`
+}
+
+// Failed reports whether a run ended with an error or a non-zero exit status
+func (r *RunState) Failed() bool {
+	return r.Error != "" || (r.ExitStatus != nil && *r.ExitStatus != 0)
+}
+
+// Clone returns a deep copy of a run state
+func (r *RunState) Clone() *RunState {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	if r.ExitStatus != nil {
+		status := *r.ExitStatus
+		c.ExitStatus = &status
+	}
+	return &c
+}
+
+// validateRunState checks run state values are in range
+func validateRunState(r *RunState) error {
+	if r.Duration < 0 {
+		return fmt.Errorf("run: duration cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunStateFailed(t *testing.T) {
+	zero, one := 0, 1
+	cases := []struct {
+		run    *RunState
+		expect bool
+	}{
+		{&RunState{}, false},
+		{&RunState{ExitStatus: &zero}, false},
+		{&RunState{ExitStatus: &one}, true},
+		{&RunState{Error: "script timed out"}, true},
+	}
+	for i, c := range cases {
+		if got := c.run.Failed(); got != c.expect {
+			t.Errorf("case %d failed mismatch. expected: %t, got: %t", i, c.expect, got)
+		}
+	}
+}
+
+func TestProvenanceRunState(t *testing.T) {
+	status := 0
+	p := provenanceFixture()
+	p.Run = &RunState{
+		Duration:      40 * time.Second,
+		EngineVersion: "starlark-go 0.1.0",
+		ExitStatus:    &status,
+		StderrPath:    "/ipfs/QmStderr",
+		StdoutPath:    "/ipfs/QmStdout",
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Provenance{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	p.Qri = KindProvenance.String()
+	if !reflect.DeepEqual(p, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", p.Run, got.Run)
+	}
+
+	if d := p.Duration(); d != 40*time.Second {
+		t.Errorf("expected run duration to take precedence, got: %s", d)
+	}
+
+	c := p.Clone()
+	*c.Run.ExitStatus = 1
+	if *p.Run.ExitStatus != 0 {
+		t.Errorf("clone shares exit status with original")
+	}
+
+	doc, err := p.PROVJSON("qri:/ipfs/QmDataset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := struct {
+		Activity map[string]map[string]interface{} `json:"activity"`
+	}{}
+	if err := json.Unmarshal(doc, &prov); err != nil {
+		t.Fatal(err)
+	}
+	activity := prov.Activity["_:activity"]
+	if activity["qri:engineVersion"] != "starlark-go 0.1.0" || activity["qri:exitStatus"] != float64(0) {
+		t.Errorf("expected run state in activity, got: %v", activity)
+	}
+}
@@ -0,0 +1,140 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var provStarted = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func provenanceFixture() *Provenance {
+	return &Provenance{
+		Agent:         &ProvenanceAgent{ID: "QmProfile", Name: "qri", Type: "software", Version: "0.7.0"},
+		Inputs:        []*ProvenanceInput{{Name: "stops", Path: "https://example.com/gtfs.zip", Hash: "sha256:abc"}},
+		TransformPath: "/ipfs/QmTransform",
+		Started:       provStarted,
+		Ended:         provStarted.Add(time.Minute),
+	}
+}
+
+func TestProvenanceJSON(t *testing.T) {
+	p := provenanceFixture()
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Provenance{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	p.Qri = KindProvenance.String()
+	if !reflect.DeepEqual(p, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", p, got)
+	}
+
+	data, err = json.Marshal(NewProvenanceRef("/ipfs/QmProv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"/ipfs/QmProv"` {
+		t.Errorf("expected reference to marshal as a string, got: %s", data)
+	}
+	ref := &Provenance{}
+	if err := json.Unmarshal(data, ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "/ipfs/QmProv" || !ref.IsEmpty() {
+		t.Errorf("expected path reference, got: %#v", ref)
+	}
+
+	if _, err := UnmarshalProvenance(false); err == nil {
+		t.Errorf("expected invalid type to error")
+	}
+}
+
+func TestProvenanceAssign(t *testing.T) {
+	p := &Provenance{Path: "/ipfs/QmProv", TransformPath: "/ipfs/QmOld"}
+	p.Assign(nil, &Provenance{TransformPath: "/ipfs/QmNew", Started: provStarted})
+	if p.Path != "/ipfs/QmProv" || p.TransformPath != "/ipfs/QmNew" || !p.Started.Equal(provStarted) {
+		t.Errorf("assign mismatch: %#v", p)
+	}
+}
+
+func TestProvenanceClone(t *testing.T) {
+	p := provenanceFixture()
+	c := p.Clone()
+	if !reflect.DeepEqual(p, c) {
+		t.Fatalf("clone mismatch. expected: %#v, got: %#v", p, c)
+	}
+	c.Agent.Name = "changed"
+	c.Inputs[0].Hash = "changed"
+	if p.Agent.Name == "changed" || p.Inputs[0].Hash == "changed" {
+		t.Errorf("clone shares memory with original")
+	}
+	if (*Provenance)(nil).Clone() != nil {
+		t.Errorf("expected nil clone to be nil")
+	}
+}
+
+func TestProvenanceDuration(t *testing.T) {
+	if d := provenanceFixture().Duration(); d != time.Minute {
+		t.Errorf("duration mismatch. expected: %s, got: %s", time.Minute, d)
+	}
+	if d := (&Provenance{Started: provStarted}).Duration(); d != 0 {
+		t.Errorf("expected zero duration with no end time, got: %s", d)
+	}
+}
+
+func TestProvenancePROVJSON(t *testing.T) {
+	if _, err := provenanceFixture().PROVJSON(""); err == nil {
+		t.Errorf("expected empty entity id to error")
+	}
+
+	data, err := provenanceFixture().PROVJSON("qri:/ipfs/QmDataset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(`{
+		"prefix": { "qri": "https://qri.io/ns/provenance#" },
+		"entity": {
+			"qri:/ipfs/QmDataset": { "prov:type": "qri:dataset" },
+			"_:input0": { "prov:label": "stops", "qri:path": "https://example.com/gtfs.zip", "qri:hash": "sha256:abc" }
+		},
+		"activity": {
+			"_:activity": {
+				"prov:startTime": "2019-01-01T00:00:00Z",
+				"prov:endTime": "2019-01-01T00:01:00Z",
+				"qri:transform": "/ipfs/QmTransform"
+			}
+		},
+		"agent": {
+			"_:agent": { "prov:type": "prov:SoftwareAgent", "prov:label": "qri", "qri:id": "QmProfile", "qri:version": "0.7.0" }
+		},
+		"used": {
+			"_:usage0": { "prov:activity": "_:activity", "prov:entity": "_:input0" }
+		},
+		"wasGeneratedBy": {
+			"_:generation": { "prov:activity": "_:activity", "prov:entity": "qri:/ipfs/QmDataset" }
+		},
+		"wasAssociatedWith": {
+			"_:association": { "prov:activity": "_:activity", "prov:agent": "_:agent" }
+		},
+		"wasAttributedTo": {
+			"_:attribution": { "prov:entity": "qri:/ipfs/QmDataset", "prov:agent": "_:agent" }
+		}
+	}`), &expect); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("PROV-JSON mismatch.\nexpected: %v\ngot: %v", expect, got)
+	}
+}
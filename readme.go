@@ -53,7 +53,7 @@ func (r *Readme) DropDerivedValues() {
 }
 
 // InlineScriptFile opens the script file, reads its contents, and assigns it to scriptBytes.
-func (r* Readme) InlineScriptFile(ctx context.Context, resolver qfs.PathResolver) error {
+func (r *Readme) InlineScriptFile(ctx context.Context, resolver qfs.PathResolver) error {
 	if resolver == nil {
 		return nil
 	}
@@ -78,20 +78,9 @@ func (r* Readme) InlineScriptFile(ctx context.Context, resolver qfs.PathResolver
 // in-place file from ScriptBytes when defined, fetching from the
 // passed-in resolver otherwise
 func (r *Readme) OpenScriptFile(ctx context.Context, resolver qfs.PathResolver) (err error) {
-	if r.ScriptBytes != nil {
-		r.scriptFile = qfs.NewMemfileBytes("readme.md", r.ScriptBytes)
-		return nil
-	}
-
-	if r.ScriptPath == "" {
-		// nothing to resolve
-		return nil
+	if src := r.ScriptSource(resolver); src != nil {
+		r.scriptFile, err = src.Open(ctx)
 	}
-
-	if resolver == nil {
-		return ErrNoResolver
-	}
-	r.scriptFile, err = resolver.Get(ctx, r.ScriptPath)
 	return err
 }
 
@@ -102,15 +91,9 @@ func (r *Readme) SetScriptFile(file qfs.File) {
 
 // OpenRenderedFile generates a byte stream of the rendered data
 func (r *Readme) OpenRenderedFile(ctx context.Context, resolver qfs.PathResolver) (err error) {
-	if r.RenderedPath == "" {
-		// nothing to resolve
-		return nil
+	if src := r.RenderedSource(resolver); src != nil {
+		r.renderedFile, err = src.Open(ctx)
 	}
-
-	if resolver == nil {
-		return ErrNoResolver
-	}
-	r.renderedFile, err = resolver.Get(ctx, r.RenderedPath)
 	return err
 }
 
@@ -171,6 +154,22 @@ func (r *Readme) Assign(readmeConfigs ...*Readme) {
 	}
 }
 
+// Clone returns a deep copy of a readme. Script & rendered files are not
+// copied
+func (r *Readme) Clone() *Readme {
+	if r == nil {
+		return nil
+	}
+	return &Readme{
+		Format:       r.Format,
+		Path:         r.Path,
+		Qri:          r.Qri,
+		ScriptBytes:  cloneBytes(r.ScriptBytes),
+		ScriptPath:   r.ScriptPath,
+		RenderedPath: r.RenderedPath,
+	}
+}
+
 // MarshalJSON satisfies the json.Marshaler interface
 func (r *Readme) MarshalJSON() ([]byte, error) {
 	// if we're dealing with an empty object that has a path specified, marshal
@@ -199,6 +198,9 @@ func (r *Readme) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &_r); err != nil {
 		return err
 	}
+	if err := checkKind(_r.Qri); err != nil {
+		return err
+	}
 	if _r.Qri == "" {
 		_r.Qri = KindReadme.String()
 	}
@@ -0,0 +1,156 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/qri-io/qfs"
+)
+
+// ScriptEncoding determines how script bytes are written when scripts are
+// inlined into a JSON document
+type ScriptEncoding int
+
+const (
+	// ScriptEncodingBase64 writes scripts to the "scriptBytes" field as base64
+	ScriptEncodingBase64 ScriptEncoding = iota
+	// ScriptEncodingString writes scripts to the "scriptString" field as plain
+	// text. scripts that aren't valid UTF-8 fall back to base64
+	ScriptEncodingString
+)
+
+// inlineScript picks up the plain-text form of an inlined script, which any
+// component with a "scriptBytes" field accepts on decode
+type inlineScript struct {
+	ScriptString *string `json:"scriptString"`
+}
+
+// decodeInlineScript returns the bytes of a "scriptString" field in data,
+// nil if data has no such field
+func decodeInlineScript(data []byte) ([]byte, error) {
+	in := inlineScript{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	if in.ScriptString == nil {
+		return nil, nil
+	}
+	return []byte(*in.ScriptString), nil
+}
+
+// MarshalJSONInline encodes a transform with all scripts, step scripts &
+// supporting files embedded in the document instead of path references, so a
+// single exported file is fully self-contained. Scripts that aren't already
+// present as bytes are fetched from resolver. The receiver is not modified
+func (q *Transform) MarshalJSONInline(ctx context.Context, resolver qfs.PathResolver, enc ScriptEncoding) ([]byte, error) {
+	c := q.Clone()
+	var err error
+	if c.ScriptBytes, err = readScript(ctx, resolver, c.ScriptBytes, c.ScriptPath); err != nil {
+		return nil, fmt.Errorf("script: %s", err)
+	}
+	c.ScriptPath = ""
+	for _, s := range c.Steps {
+		if s == nil {
+			continue
+		}
+		if s.ScriptBytes, err = readScript(ctx, resolver, s.ScriptBytes, s.ScriptPath); err != nil {
+			return nil, fmt.Errorf("step '%s': script: %s", s.Name, err)
+		}
+		s.ScriptPath = ""
+	}
+	for _, name := range c.FileNames() {
+		f := c.Files[name]
+		if f == nil {
+			continue
+		}
+		if f.ScriptBytes, err = readScript(ctx, resolver, f.ScriptBytes, f.Path); err != nil {
+			return nil, fmt.Errorf("file '%s': %s", name, err)
+		}
+		f.Path = ""
+	}
+
+	data, err := c.MarshalJSONObject()
+	if err != nil || enc != ScriptEncodingString {
+		return data, err
+	}
+
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	stringifyScript(doc)
+	if steps, ok := doc["steps"].([]interface{}); ok {
+		for _, s := range steps {
+			if step, ok := s.(map[string]interface{}); ok {
+				stringifyScript(step)
+			}
+		}
+	}
+	if files, ok := doc["files"].(map[string]interface{}); ok {
+		for _, f := range files {
+			if file, ok := f.(map[string]interface{}); ok {
+				stringifyScript(file)
+			}
+		}
+	}
+	return marshalInline(doc)
+}
+
+// MarshalJSONInline encodes a viz with it's script embedded in the document
+// instead of a path reference, fetching the script from resolver if it isn't
+// already present as bytes. The receiver is not modified
+func (v *Viz) MarshalJSONInline(ctx context.Context, resolver qfs.PathResolver, enc ScriptEncoding) ([]byte, error) {
+	c := v.Clone()
+	var err error
+	if c.ScriptBytes, err = readScript(ctx, resolver, c.ScriptBytes, c.ScriptPath); err != nil {
+		return nil, fmt.Errorf("script: %s", err)
+	}
+	c.ScriptPath = ""
+	if c.Qri == "" {
+		c.Qri = KindViz.String()
+	}
+
+	data, err := c.MarshalJSONObject()
+	if err != nil || enc != ScriptEncodingString {
+		return data, err
+	}
+
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	stringifyScript(doc)
+	return marshalInline(doc)
+}
+
+// stringifyScript replaces a base64 "scriptBytes" field in an encoded
+// component with a plain-text "scriptString" field when the script is valid
+// UTF-8
+func stringifyScript(doc map[string]interface{}) {
+	enc, ok := doc["scriptBytes"].(string)
+	if !ok {
+		return
+	}
+	script, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil || !utf8.Valid(script) {
+		return
+	}
+	delete(doc, "scriptBytes")
+	doc["scriptString"] = string(script)
+}
+
+// marshalInline encodes an inlined document without escaping HTML characters,
+// keeping inlined scripts & templates readable
+func marshalInline(doc map[string]interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
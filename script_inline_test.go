@@ -0,0 +1,108 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestTransformMarshalJSONInline(t *testing.T) {
+	ctx := context.Background()
+	resolver := mapResolver{
+		"/ipfs/QmMain":  "load('lib.star', 'f')",
+		"/ipfs/QmLib":   "def f(): pass",
+		"/ipfs/QmClean": "SELECT * FROM a",
+	}
+	q := &Transform{
+		Qri:        KindTransform.String(),
+		Syntax:     "starlark",
+		ScriptPath: "/ipfs/QmMain",
+		Files:      map[string]*TransformFile{"lib.star": {Path: "/ipfs/QmLib"}},
+		Steps:      []*TransformStep{{Name: "clean", Syntax: SyntaxSQL, ScriptPath: "/ipfs/QmClean"}},
+	}
+
+	cases := []struct {
+		enc    ScriptEncoding
+		expect string
+	}{
+		{ScriptEncodingBase64, `{"files":{"lib.star":{"scriptBytes":"ZGVmIGYoKTogcGFzcw=="}},"qri":"tf:0","scriptBytes":"bG9hZCgnbGliLnN0YXInLCAnZicp","steps":[{"name":"clean","scriptBytes":"U0VMRUNUICogRlJPTSBh","syntax":"sql"}],"syntax":"starlark"}`},
+		{ScriptEncodingString, `{"files":{"lib.star":{"scriptString":"def f(): pass"}},"qri":"tf:0","scriptString":"load('lib.star', 'f')","steps":[{"name":"clean","scriptString":"SELECT * FROM a","syntax":"sql"}],"syntax":"starlark"}`},
+	}
+
+	for i, c := range cases {
+		data, err := q.MarshalJSONInline(ctx, resolver, c.enc)
+		if err != nil {
+			t.Fatalf("case %d unexpected error: %s", i, err)
+		}
+		if string(data) != c.expect {
+			t.Errorf("case %d result mismatch.\nwant: %s\ngot:  %s", i, c.expect, string(data))
+		}
+
+		got := &Transform{}
+		if err := json.Unmarshal(data, got); err != nil {
+			t.Fatalf("case %d decoding: %s", i, err)
+		}
+		if string(got.ScriptBytes) != resolver["/ipfs/QmMain"] {
+			t.Errorf("case %d script mismatch. got: %q", i, string(got.ScriptBytes))
+		}
+		if string(got.Files["lib.star"].ScriptBytes) != resolver["/ipfs/QmLib"] {
+			t.Errorf("case %d file mismatch. got: %q", i, string(got.Files["lib.star"].ScriptBytes))
+		}
+		if string(got.Step("clean").ScriptBytes) != resolver["/ipfs/QmClean"] {
+			t.Errorf("case %d step mismatch. got: %q", i, string(got.Step("clean").ScriptBytes))
+		}
+		if err := UnmarshalStrict(data, &Transform{}); err != nil {
+			t.Errorf("case %d strict decoding: %s", i, err)
+		}
+	}
+
+	if q.ScriptPath != "/ipfs/QmMain" || q.ScriptBytes != nil {
+		t.Errorf("expected receiver to be unmodified")
+	}
+
+	if _, err := q.MarshalJSONInline(ctx, nil, ScriptEncodingString); err == nil || err.Error() != "script: "+ErrNoResolver.Error() {
+		t.Errorf("expected missing resolver error, got: %v", err)
+	}
+}
+
+func TestVizMarshalJSONInline(t *testing.T) {
+	ctx := context.Background()
+	resolver := mapResolver{"/ipfs/QmTemplate": "<html>{{ .Meta.Title }}</html>"}
+	cases := []struct {
+		viz    *Viz
+		enc    ScriptEncoding
+		expect string
+		err    string
+	}{
+		{&Viz{Format: "html", ScriptPath: "/ipfs/QmTemplate"}, ScriptEncodingBase64, `{"format":"html","qri":"vz:0","scriptBytes":"PGh0bWw+e3sgLk1ldGEuVGl0bGUgfX08L2h0bWw+"}`, ""},
+		{&Viz{Format: "html", ScriptPath: "/ipfs/QmTemplate"}, ScriptEncodingString, `{"format":"html","qri":"vz:0","scriptString":"<html>{{ .Meta.Title }}</html>"}`, ""},
+		{&Viz{Format: "html", ScriptBytes: []byte{0xff, 0xfe}}, ScriptEncodingString, `{"format":"html","qri":"vz:0","scriptBytes":"//4="}`, ""},
+		{&Viz{Format: "html", ScriptPath: "/ipfs/QmMissing"}, ScriptEncodingString, "", "script: resolving '/ipfs/QmMissing': path not found"},
+	}
+
+	for i, c := range cases {
+		data, err := c.viz.MarshalJSONInline(ctx, resolver, c.enc)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if string(data) != c.expect {
+			t.Errorf("case %d result mismatch.\nwant: %s\ngot:  %s", i, c.expect, string(data))
+		}
+
+		got := &Viz{}
+		if err := json.Unmarshal(data, got); err != nil {
+			t.Fatalf("case %d decoding: %s", i, err)
+		}
+		expect := c.viz.ScriptBytes
+		if expect == nil {
+			expect = []byte(resolver[c.viz.ScriptPath])
+		}
+		if string(got.ScriptBytes) != string(expect) {
+			t.Errorf("case %d script mismatch. got: %q", i, string(got.ScriptBytes))
+		}
+	}
+}
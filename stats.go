@@ -0,0 +1,157 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Stats is a component that summarizes the values of a dataset body, stored
+// separately so a body can be described without reading it. Stats are
+// computed by the stats subpackage
+type Stats struct {
+	// Entries is the number of body entries the stats summarize. Stats that
+	// cover fewer entries than the body can be brought up to date by
+	// accumulating only the remaining entries
+	Entries int `json:"entries,omitempty"`
+	// Path is the location of the stats, transient
+	// derived
+	Path string `json:"path,omitempty"`
+	// Qri should always be KindStats
+	// derived
+	Qri string `json:"qri,omitempty"`
+	// Stats holds the summary of each body column
+	Stats interface{} `json:"stats,omitempty"`
+}
+
+// NewStatsRef creates an empty struct with it's internal path set
+func NewStatsRef(path string) *Stats {
+	return &Stats{Path: path}
+}
+
+// DropTransientValues removes values that cannot be recorded when the
+// dataset is rendered immutable, usually by storing it in a cafs
+func (sa *Stats) DropTransientValues() {
+	sa.Path = ""
+}
+
+// DropDerivedValues resets all set-on-save fields to their default values
+func (sa *Stats) DropDerivedValues() {
+	sa.Qri = ""
+	sa.Path = ""
+}
+
+// IsEmpty checks to see if stats has any fields other than the internal path
+func (sa *Stats) IsEmpty() bool {
+	return sa.Entries == 0 &&
+		sa.Stats == nil
+}
+
+// Assign collapses all properties of a group of stats on to one. this is
+// directly inspired by Javascript's Object.assign
+func (sa *Stats) Assign(sas ...*Stats) {
+	for _, s := range sas {
+		if s == nil {
+			continue
+		}
+		if s.Entries != 0 {
+			sa.Entries = s.Entries
+		}
+		if s.Path != "" {
+			sa.Path = s.Path
+		}
+		if s.Qri != "" {
+			sa.Qri = s.Qri
+		}
+		if s.Stats != nil {
+			sa.Stats = s.Stats
+		}
+	}
+}
+
+// Clone returns a deep copy of stats. Stats values are copied with the same
+// rules as Dataset.Clone
+func (sa *Stats) Clone() *Stats {
+	if sa == nil {
+		return nil
+	}
+	return &Stats{
+		Entries: sa.Entries,
+		Path:    sa.Path,
+		Qri:     sa.Qri,
+		Stats:   cloneValue(sa.Stats),
+	}
+}
+
+// _stats is a private struct for marshaling into & out of.
+// fields must remain sorted in lexographical order
+type _stats Stats
+
+// MarshalJSON satisfies the json.Marshaler interface
+func (sa *Stats) MarshalJSON() ([]byte, error) {
+	// if we're dealing with an empty object that has a path specified, marshal
+	// to a string instead
+	if sa.Path != "" && sa.IsEmpty() {
+		return json.Marshal(sa.Path)
+	}
+	return sa.MarshalJSONObject()
+}
+
+// MarshalJSONObject always marshals to a json Object, even if the stats are
+// empty or a reference
+func (sa *Stats) MarshalJSONObject() ([]byte, error) {
+	c := _stats(*sa)
+	if c.Qri == "" {
+		c.Qri = KindStats.String()
+	}
+	return json.Marshal(c)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface
+func (sa *Stats) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*sa = Stats{Path: s}
+		return nil
+	}
+
+	_s := _stats{}
+	if err := json.Unmarshal(data, &_s); err != nil {
+		return fmt.Errorf("unmarshaling stats: %s", err)
+	}
+	if err := checkKind(_s.Qri); err != nil {
+		return err
+	}
+	*sa = Stats(_s)
+	return nil
+}
+
+// UnmarshalStats tries to extract a stats type from an empty interface.
+// Pairs nicely with datastore.Get() from github.com/ipfs/go-datastore
+func UnmarshalStats(v interface{}) (*Stats, error) {
+	switch s := v.(type) {
+	case *Stats:
+		return s, nil
+	case Stats:
+		return &s, nil
+	case []byte:
+		sa := &Stats{}
+		err := json.Unmarshal(s, sa)
+		return sa, err
+	default:
+		err := fmt.Errorf("couldn't parse stats, value is invalid type")
+		return nil, err
+	}
+}
+
+func validateStats(sa *Stats) error {
+	if sa.Path != "" && sa.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(sa.Qri, KindStats); err != nil {
+		return err
+	}
+	if sa.Entries < 0 {
+		return fmt.Errorf("entries cannot be negative")
+	}
+	return nil
+}
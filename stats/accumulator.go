@@ -0,0 +1,206 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// Config configures stats accumulation
+type Config struct {
+	// Outliers enables outlier detection for numeric values with a method.
+	// Outlier detection is off by default
+	Outliers OutlierMethod
+	// OutlierThreshold overrides the default threshold of the outlier method
+	OutlierThreshold float64
+}
+
+// WithOutliers enables outlier detection. A zero threshold uses the method
+// default
+func WithOutliers(method OutlierMethod, threshold float64) func(*Config) {
+	return func(c *Config) {
+		c.Outliers = method
+		c.OutlierThreshold = threshold
+	}
+}
+
+func newConfig(opts []func(*Config)) (*Config, error) {
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.Outliers != "" {
+		if err := cfg.Outliers.Valid(); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.OutlierThreshold < 0 {
+		return cfg, fmt.Errorf("stats: outlier threshold cannot be negative")
+	}
+	return cfg, nil
+}
+
+// Accumulator summarizes the entries written to it. Array rows are
+// summarized by position, using column titles from the structure schema.
+// Object rows are summarized by key. Any other entry value is summarized as
+// a single column
+type Accumulator struct {
+	st      *dataset.Structure
+	cfg     *Config
+	err     error
+	titles  []string
+	cols    []*ColumnStats
+	index   map[string]int
+	entries int
+}
+
+var _ dsio.EntryWriter = (*Accumulator)(nil)
+
+// NewAccumulator creates an accumulator for a body with structure st. An
+// invalid configuration is returned as an error by WriteEntry
+func NewAccumulator(st *dataset.Structure, opts ...func(*Config)) *Accumulator {
+	cfg, err := newConfig(opts)
+	acc := &Accumulator{st: st, cfg: cfg, err: err, titles: schemaTitles(st), index: map[string]int{}}
+	for _, title := range acc.titles {
+		acc.column(title)
+	}
+	return acc
+}
+
+// Resume creates an accumulator that continues from previously computed
+// stats. Entries written to the accumulator are summarized as if they
+// followed the prev.Entries entries prev already summarizes. Columns are
+// matched by title, so columns added to the structure since prev was
+// computed start empty. A nil prev resumes from nothing.
+// Outlier detection continues with the method prev was computed with, and
+// enabling a different method is an error
+func Resume(st *dataset.Structure, prev *dataset.Stats, opts ...func(*Config)) (*Accumulator, error) {
+	cfg, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if prev == nil {
+		return NewAccumulator(st, opts...), nil
+	}
+	cols, err := Columns(prev)
+	if err != nil {
+		return nil, err
+	}
+	acc := &Accumulator{st: st, cfg: cfg, index: map[string]int{}}
+	for _, cs := range cols {
+		if cs == nil {
+			continue
+		}
+		if _, ok := acc.index[cs.Title]; ok {
+			return nil, fmt.Errorf("stats: duplicate column %q", cs.Title)
+		}
+		if ns := cs.Numeric; ns != nil && ns.Outliers != nil {
+			if cfg.Outliers != "" && cfg.Outliers != ns.Outliers.Method {
+				return nil, fmt.Errorf("stats: column %q outliers were computed with method %q", cs.Title, ns.Outliers.Method)
+			}
+			if err := ns.Outliers.Method.Valid(); err != nil {
+				return nil, fmt.Errorf("stats: column %q: %w", cs.Title, err)
+			}
+		}
+		acc.index[cs.Title] = len(acc.cols)
+		acc.cols = append(acc.cols, cs.copy())
+	}
+	acc.entries = prev.Entries
+	acc.titles = schemaTitles(st)
+	for _, title := range acc.titles {
+		acc.column(title)
+	}
+	return acc, nil
+}
+
+// Structure gives the structure of the summarized body
+func (acc *Accumulator) Structure() *dataset.Structure {
+	return acc.st
+}
+
+// WriteEntry summarizes one entry
+func (acc *Accumulator) WriteEntry(ent dsio.Entry) error {
+	if acc.err != nil {
+		return acc.err
+	}
+	acc.entries++
+	switch row := ent.Value.(type) {
+	case []interface{}:
+		for i, v := range row {
+			acc.column(acc.title(i)).add(v, acc.cfg)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(row))
+		for k := range row {
+			keys = append(keys, k)
+		}
+		// sort keys so columns are created in a deterministic order
+		sort.Strings(keys)
+		for _, k := range keys {
+			acc.column(k).add(row[k], acc.cfg)
+		}
+	default:
+		acc.column(acc.title(0)).add(ent.Value, acc.cfg)
+	}
+	return nil
+}
+
+// Close is a no-op, satisfying the dsio.EntryWriter interface. Stats may be
+// read at any time
+func (acc *Accumulator) Close() error {
+	return nil
+}
+
+// Entries gives the number of entries summarized, including entries
+// summarized by resumed stats
+func (acc *Accumulator) Entries() int {
+	return acc.entries
+}
+
+// Stats returns a copy of the accumulated stats as a dataset component
+func (acc *Accumulator) Stats() *dataset.Stats {
+	cols := make([]*ColumnStats, len(acc.cols))
+	for i, cs := range acc.cols {
+		cols[i] = cs.copy()
+	}
+	return &dataset.Stats{
+		Entries: acc.entries,
+		Qri:     dataset.KindStats.String(),
+		Stats:   cols,
+	}
+}
+
+// schemaTitles gives the column titles of a tabular structure, nil if st
+// doesn't describe tabular data
+func schemaTitles(st *dataset.Structure) []string {
+	if st == nil {
+		return nil
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil
+	}
+	return cols.Titles()
+}
+
+// title gives the title of the column at position i
+func (acc *Accumulator) title(i int) string {
+	if i < len(acc.titles) {
+		return acc.titles[i]
+	}
+	return fmt.Sprintf("col_%d", i)
+}
+
+// column gets the stats for a column title, creating them if necessary
+func (acc *Accumulator) column(title string) *ColumnStats {
+	if i, ok := acc.index[title]; ok {
+		return acc.cols[i]
+	}
+	cs := &ColumnStats{Title: title}
+	acc.index[title] = len(acc.cols)
+	acc.cols = append(acc.cols, cs)
+	return cs
+}
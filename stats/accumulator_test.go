@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestAccumulatorRows(t *testing.T) {
+	acc := NewAccumulator(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray})
+	for _, v := range []interface{}{
+		map[string]interface{}{"b": "x", "a": 1.0},
+		map[string]interface{}{"a": 2.0},
+		[]interface{}{"y"},
+		"z",
+	} {
+		if err := acc.WriteEntry(dsio.Entry{Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := acc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if acc.Entries() != 4 {
+		t.Errorf("expected 4 entries, got: %d", acc.Entries())
+	}
+	cols, err := Columns(acc.Stats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		title string
+		count int
+	}{
+		{"a", 2},
+		{"b", 1},
+		{"col_0", 2},
+	}
+	if len(cols) != len(expect) {
+		t.Fatalf("expected %d columns, got: %d", len(expect), len(cols))
+	}
+	for i, c := range expect {
+		if cols[i].Title != c.title || cols[i].Count != c.count {
+			t.Errorf("column %d mismatch. expected: %s %d, got: %s %d", i, c.title, c.count, cols[i].Title, cols[i].Count)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	prev := &dataset.Stats{
+		Entries: 2,
+		Stats: []*ColumnStats{
+			{Title: "name", Count: 2, String: &StringStats{Count: 2, Frequencies: map[string]int{"a": 2}, MaxLength: 1, MinLength: 1}},
+		},
+	}
+	acc, err := Resume(tableStructure, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.WriteEntry(dsio.Entry{Value: []interface{}{"bb", 4.0, true}}); err != nil {
+		t.Fatal(err)
+	}
+	sa := acc.Stats()
+	if sa.Entries != 3 {
+		t.Errorf("expected 3 entries, got: %d", sa.Entries)
+	}
+	cols := sa.Stats.([]*ColumnStats)
+	if len(cols) != 3 {
+		t.Fatalf("expected new schema columns to be added, got %d columns", len(cols))
+	}
+	if s := cols[0].String; cols[0].Count != 3 || s.MaxLength != 2 || s.Frequencies["a"] != 2 || s.Frequencies["bb"] != 1 {
+		t.Errorf("resumed column mismatch: %#v %#v", cols[0], s)
+	}
+	if cols[1].Title != "count" || cols[1].Count != 1 {
+		t.Errorf("expected added column to start empty, got: %#v", cols[1])
+	}
+	// resumed stats must not share memory with prev
+	if prev.Stats.([]*ColumnStats)[0].Count != 2 {
+		t.Errorf("resume modified previous stats")
+	}
+
+	dup := &dataset.Stats{Stats: []*ColumnStats{{Title: "a"}, {Title: "a"}}}
+	if _, err := Resume(nil, dup); err == nil || err.Error() != `stats: duplicate column "a"` {
+		t.Errorf("expected duplicate column error, got: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/dsio/replacecr"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// ComputeCSV summarizes a CSV body, a fast path for the common tabular case.
+// Cells are summarized straight from the parsed record instead of being
+// decoded into entries. Columns the schema types get the same stats as
+// Compute with a dsio.CSVReader. Unlike the generic path the schema doesn't
+// need to type every column: columns are identified by schema items or the
+// header row, and cells of untyped columns are summarized as numbers when
+// they parse as numbers, strings otherwise. The generic path summarizes
+// untyped cells as the strings the reader gives, so stats of untyped columns
+// differ between the two
+func ComputeCSV(st *dataset.Structure, r io.Reader, opts ...func(*Config)) (*dataset.Stats, error) {
+	return UpdateCSV(nil, st, r, opts...)
+}
+
+// UpdateCSV brings prev up to date with a CSV body that extends the body prev
+// was computed from, the CSV counterpart of Update
+func UpdateCSV(prev *dataset.Stats, st *dataset.Structure, r io.Reader, opts ...func(*Config)) (*dataset.Stats, error) {
+	if st == nil || st.DataFormat() != dataset.CSVDataFormat {
+		return nil, fmt.Errorf("stats: csv structure is required")
+	}
+	acc, err := Resume(st, prev, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if acc.err != nil {
+		return nil, acc.err
+	}
+	skip := 0
+	if prev != nil {
+		skip = prev.Entries
+	}
+
+	cr, err := newCSVColumnReader(st, r)
+	if err != nil {
+		return nil, err
+	}
+	if cr.header && len(acc.titles) == 0 {
+		rec, err := cr.r.Read()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("stats: reading header: %w", err)
+		}
+		acc.titles = append([]string(nil), rec...)
+		for _, title := range acc.titles {
+			acc.column(title)
+		}
+	} else if cr.header {
+		if _, err := cr.r.Read(); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("stats: reading header: %w", err)
+		}
+	}
+
+	var cols []*ColumnStats
+	for i := 0; ; i++ {
+		rec, err := cr.r.Read()
+		if err == io.EOF {
+			if i < skip {
+				return nil, fmt.Errorf("stats: body has %d entries, fewer than the %d previous stats summarize", i, skip)
+			}
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("stats: row %d: %w", i, err)
+		}
+		if i < skip {
+			continue
+		}
+
+		acc.entries++
+		for len(cols) < len(rec) {
+			cols = append(cols, acc.column(acc.title(len(cols))))
+		}
+		// like dsio.CSVReader, rows wider than the schema are read as strings
+		typed := cr.types == nil || len(rec) <= len(cr.types)
+		for j, cell := range rec {
+			if cr.cells.IsNull(cell) {
+				cols[j].addNull()
+			} else if typed {
+				cr.addCell(cols[j], j, cell, acc.cfg)
+			} else {
+				cols[j].addString(cell)
+			}
+		}
+	}
+	return acc.Stats(), nil
+}
+
+// csvColumnReader reads CSV records & knows how to summarize their cells
+type csvColumnReader struct {
+	r      *csv.Reader
+	header bool
+	types  []string
+	// cells decodes cells the way dsio.CSVReader does
+	cells *dsio.CSVCellDecoder
+}
+
+func newCSVColumnReader(st *dataset.Structure, r io.Reader) (*csvColumnReader, error) {
+	cr := &csvColumnReader{header: dsio.HasHeaderRow(st), cells: dsio.NewCSVCellDecoder(st)}
+	if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+		cr.types = make([]string, len(cols))
+		for i, c := range cols {
+			if c.Type != nil && len(*c.Type) > 0 {
+				cr.types[i] = []string(*c.Type)[0]
+			}
+		}
+	}
+
+	// skip the same preamble dsio.CSVReader does
+	br := bufio.NewReader(r)
+	dsio.SkipPreamble(st, br)
+	csvr := csv.NewReader(replacecr.Reader(br))
+	csvr.ReuseRecord = true
+	if st.FormatConfig != nil {
+		opts, err := dataset.NewCSVOptions(st.FormatConfig)
+		if err != nil {
+			return nil, fmt.Errorf("stats: %w", err)
+		}
+		csvr.LazyQuotes = opts.LazyQuotes
+		if opts.VariadicFields {
+			csvr.FieldsPerRecord = -1
+		}
+		if opts.Separator != rune(0) {
+			csvr.Comma = opts.Separator
+		}
+	}
+	if cr.types == nil {
+		// without a tabular schema rows may have any width
+		csvr.FieldsPerRecord = -1
+	}
+	cr.r = csvr
+	return cr, nil
+}
+
+// addCell summarizes cell i of a record, decoded as dsio.CSVReader decodes
+// it. Cells of untyped columns are summarized as numbers when they parse as
+// numbers
+func (cr *csvColumnReader) addCell(cs *ColumnStats, i int, cell string, cfg *Config) {
+	v := cr.cells.Decode(i, cell)
+	if s, ok := v.(string); ok && (i >= len(cr.types) || cr.types[i] == "") {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			cs.addFloat(f, cfg)
+			return
+		}
+	}
+	cs.add(v, cfg)
+}
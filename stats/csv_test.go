@@ -0,0 +1,266 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func csvStructure(decimals bool, cols ...map[string]interface{}) *dataset.Structure {
+	items := make([]interface{}, len(cols))
+	for i, c := range cols {
+		items[i] = c
+	}
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema:       dataset.BaseSchemaArray,
+	}
+	if decimals {
+		st.FormatConfig["decimalNumbers"] = true
+	}
+	if len(cols) > 0 {
+		st.Schema = map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": items},
+		}
+	}
+	return st
+}
+
+func col(title, typ string) map[string]interface{} {
+	return map[string]interface{}{"title": title, "type": typ}
+}
+
+const stopsCSV = `id,name,lat,wheelchair,opened,zone,meta
+1,Hauptbahnhof,48.78,true,2019-03-31,A,"{""a"":1}"
+2,Rotebühlplatz,48.77,no,2020-01-01,A,
+3.0,Schlossplatz, 48.779 ,,not a date,B,[1]
+x,Berliner Platz,n/a,yes,2021-06-01,,null
+99999999999999999999,Charlottenplatz,-1e3,false,2021-06-01,C,"{}"
+`
+
+func stopsStructure(decimals bool) *dataset.Structure {
+	return csvStructure(decimals,
+		col("id", "integer"),
+		col("name", "string"),
+		col("lat", "number"),
+		col("wheelchair", "boolean"),
+		map[string]interface{}{"title": "opened", "type": "string", "format": "date"},
+		col("zone", "string"),
+		col("meta", "object"),
+	)
+}
+
+func TestComputeCSVMatchesGeneric(t *testing.T) {
+	for _, decimals := range []bool{false, true} {
+		st := stopsStructure(decimals)
+		r, err := dsio.NewCSVReader(st, strings.NewReader(stopsCSV))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect, err := Compute(r, WithOutliers(OutliersZScore, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ComputeCSV(st, strings.NewReader(stopsCSV), WithOutliers(OutliersZScore, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(expect) {
+			ee, _ := json.Marshal(expect)
+			ge, _ := json.Marshal(got)
+			t.Errorf("decimals %t: fast path stats differ.\nexpected: %s\ngot:      %s", decimals, ee, ge)
+		}
+	}
+}
+
+func TestComputeCSVUntyped(t *testing.T) {
+	data := "name,count\na,1\nb,2.5\nc,many\n"
+	sa, err := ComputeCSV(csvStructure(false), strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols := sa.Stats.([]*ColumnStats)
+	if sa.Entries != 3 || len(cols) != 2 || cols[0].Title != "name" || cols[1].Title != "count" {
+		t.Fatalf("expected columns from the header row, got: %d entries, %#v", sa.Entries, cols)
+	}
+	if n := cols[1].Numeric; n == nil || n.Count != 2 || n.Max != 2.5 {
+		t.Errorf("expected number cells to be summarized as numbers, got: %#v", n)
+	}
+	if s := cols[1].String; s == nil || s.Count != 1 {
+		t.Errorf("expected other cells to be summarized as strings, got: %#v", s)
+	}
+}
+
+func TestComputeCSVNullValues(t *testing.T) {
+	data := "name,count\nNA,1\nb,-\nc,\n"
+	st := csvStructure(false, col("name", "string"), col("count", "integer"))
+	st.FormatConfig["nullValues"] = []interface{}{"", "NA", "-"}
+
+	r, err := dsio.NewCSVReader(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := Compute(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ComputeCSV(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(expect) {
+		t.Errorf("fast path stats differ from the generic path")
+	}
+	cols := got.Stats.([]*ColumnStats)
+	if cols[0].Nulls != 1 || cols[1].Nulls != 2 {
+		t.Errorf("expected null values to be counted as nulls, got: %d, %d", cols[0].Nulls, cols[1].Nulls)
+	}
+	if n := cols[1].Numeric; n == nil || n.Count != 1 {
+		t.Errorf("expected one number cell, got: %#v", n)
+	}
+}
+
+func TestComputeCSVDialect(t *testing.T) {
+	data := "price,count,active\n\"1.234,5\",1.000,ja\n\"0,99\",12,nein\nfree,many,vielleicht\n"
+	st := csvStructure(true, col("price", "number"), col("count", "integer"), col("active", "boolean"))
+	st.FormatConfig["trueValues"] = []interface{}{"ja"}
+	st.FormatConfig["falseValues"] = []interface{}{"nein"}
+	st.FormatConfig["decimalSeparator"] = ","
+	st.FormatConfig["groupSeparator"] = "."
+
+	for _, decimals := range []bool{false, true} {
+		st.FormatConfig["decimalNumbers"] = decimals
+		r, err := dsio.NewCSVReader(st, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect, err := Compute(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ComputeCSV(st, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(expect) {
+			ee, _ := json.Marshal(expect)
+			ge, _ := json.Marshal(got)
+			t.Errorf("decimals %t: fast path stats differ.\nexpected: %s\ngot:      %s", decimals, ee, ge)
+		}
+		cols := got.Stats.([]*ColumnStats)
+		if n := cols[0].Numeric; n == nil || n.Max != 1234.5 {
+			t.Errorf("decimals %t: expected localized numbers, got: %#v", decimals, n)
+		}
+		if n := cols[1].Numeric; n == nil || n.Max != 1000 || n.Min != 12 {
+			t.Errorf("decimals %t: expected localized integers, got: %#v", decimals, n)
+		}
+		if b := cols[2].Boolean; b == nil || b.True != 1 || b.False != 1 {
+			t.Errorf("decimals %t: expected localized booleans, got: %#v", decimals, b)
+		}
+	}
+}
+
+func TestComputeCSVPreamble(t *testing.T) {
+	data := "\xEF\xBB\xBF# exported from excel\nname,count\na,1\n"
+	st := csvStructure(false)
+	st.FormatConfig["commentPrefix"] = "#"
+	sa, err := ComputeCSV(st, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols := sa.Stats.([]*ColumnStats)
+	if sa.Entries != 1 || len(cols) != 2 || cols[0].Title != "name" {
+		t.Errorf("expected the byte order mark & comments to be skipped, got: %d entries, %#v", sa.Entries, cols)
+	}
+}
+
+func TestUpdateCSV(t *testing.T) {
+	st := stopsStructure(false)
+	lines := strings.SplitAfter(stopsCSV, "\n")
+	prior := strings.Join(lines[:3], "")
+
+	prev, err := ComputeCSV(st, strings.NewReader(prior))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UpdateCSV(prev, st, strings.NewReader(stopsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := ComputeCSV(st, strings.NewReader(stopsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(expect) {
+		t.Errorf("updated stats differ from recomputed stats")
+	}
+
+	if _, err := UpdateCSV(expect, st, strings.NewReader(prior)); err == nil || err.Error() != "stats: body has 2 entries, fewer than the 5 previous stats summarize" {
+		t.Errorf("expected shorter body error, got: %v", err)
+	}
+}
+
+func TestComputeCSVErrors(t *testing.T) {
+	cases := []struct {
+		st   *dataset.Structure
+		data string
+		err  string
+	}{
+		{nil, "", "stats: csv structure is required"},
+		{&dataset.Structure{Format: "json"}, "", "stats: csv structure is required"},
+		{csvStructure(false, col("a", "string")), "a\n\"b\n", `stats: row 0: parse error on line 2, column 4: extraneous or missing " in quoted-field`},
+		{csvStructure(false, col("a", "string")), "a\nb,c\n", "stats: row 0: record on line 2: wrong number of fields"},
+	}
+	for i, c := range cases {
+		_, err := ComputeCSV(c.st, strings.NewReader(c.data))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func benchmarkCSV(rows int) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("id,station,value,ok\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(buf, "%d,station %d,%f,%t\n", i, i%50, float64(i)*0.37, i%3 == 0)
+	}
+	return buf.Bytes()
+}
+
+func benchmarkCSVStructure() *dataset.Structure {
+	return csvStructure(false, col("id", "integer"), col("station", "string"), col("value", "number"), col("ok", "boolean"))
+}
+
+func BenchmarkComputeCSV(b *testing.B) {
+	data := benchmarkCSV(10000)
+	st := benchmarkCSVStructure()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ComputeCSV(st, bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkComputeCSVGeneric(b *testing.B) {
+	data := benchmarkCSV(10000)
+	st := benchmarkCSVStructure()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := dsio.NewCSVReader(st, bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Compute(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package stats
+
+import "math"
+
+// NumericStats summarizes number values. Mean & variance are kept with
+// Welford's online algorithm, which is stable across resumes: the running sum
+// of squared differences is recovered from Variance & Count
+type NumericStats struct {
+	// Count is the number of number values
+	Count int `json:"count"`
+	// Max is the largest value
+	Max float64 `json:"max"`
+	// Mean is the arithmetic mean of all values
+	Mean float64 `json:"mean"`
+	// Min is the smallest value
+	Min float64 `json:"min"`
+	// Outliers counts values far from the bulk of values, only present when
+	// outlier detection is enabled
+	Outliers *OutlierStats `json:"outliers,omitempty"`
+	// Variance is the population variance of all values
+	Variance float64 `json:"variance"`
+}
+
+// StdDev gives the population standard deviation of all values
+func (ns *NumericStats) StdDev() float64 {
+	return math.Sqrt(ns.Variance)
+}
+
+func (ns *NumericStats) add(f float64) {
+	if ns.Outliers != nil {
+		ns.Outliers.check(ns, f)
+	}
+	if ns.Count == 0 || f < ns.Min {
+		ns.Min = f
+	}
+	if ns.Count == 0 || f > ns.Max {
+		ns.Max = f
+	}
+	m2 := ns.Variance * float64(ns.Count)
+	ns.Count++
+	delta := f - ns.Mean
+	ns.Mean += delta / float64(ns.Count)
+	m2 += delta * (f - ns.Mean)
+	ns.Variance = m2 / float64(ns.Count)
+}
+
+func (ns *NumericStats) copy() *NumericStats {
+	c := *ns
+	if ns.Outliers != nil {
+		c.Outliers = ns.Outliers.copy()
+	}
+	return &c
+}
@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumericStats(t *testing.T) {
+	cases := []struct {
+		vals                     []float64
+		min, max, mean, variance float64
+	}{
+		{[]float64{5}, 5, 5, 5, 0},
+		{[]float64{2, 4, 4, 4, 5, 5, 7, 9}, 2, 9, 5, 4},
+		{[]float64{-1, 1}, -1, 1, 0, 1},
+	}
+	for i, c := range cases {
+		ns := &NumericStats{}
+		for _, v := range c.vals {
+			ns.add(v)
+		}
+		if ns.Count != len(c.vals) || ns.Min != c.min || ns.Max != c.max || ns.Mean != c.mean || math.Abs(ns.Variance-c.variance) > 1e-9 {
+			t.Errorf("case %d mismatch: %#v", i, ns)
+		}
+	}
+
+	ns := &NumericStats{}
+	for _, v := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		ns.add(v)
+	}
+	if ns.StdDev() != 2 {
+		t.Errorf("expected stddev 2, got: %f", ns.StdDev())
+	}
+}
@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// OutlierMethod names a way of flagging numeric outliers
+type OutlierMethod string
+
+const (
+	// OutliersZScore flags values more than Threshold standard deviations
+	// from the mean
+	OutliersZScore = OutlierMethod("zscore")
+	// OutliersIQR flags values more than Threshold interquartile ranges below
+	// the lower quartile or above the upper quartile
+	OutliersIQR = OutlierMethod("iqr")
+)
+
+// DefaultThreshold gives the conventional threshold of a method
+func (m OutlierMethod) DefaultThreshold() float64 {
+	if m == OutliersIQR {
+		return 1.5
+	}
+	return 3
+}
+
+// Valid checks an outlier method name
+func (m OutlierMethod) Valid() error {
+	switch m {
+	case OutliersZScore, OutliersIQR:
+		return nil
+	}
+	return fmt.Errorf("stats: unknown outlier method %q", string(m))
+}
+
+const (
+	// OutlierWarmup is the number of values a column must summarize before
+	// values are checked for outliers. Values are checked against the values
+	// that came before them, which say little until there are enough of them
+	OutlierWarmup = 30
+	// MaxOutlierExamples caps the number of outlier values kept as examples
+	MaxOutlierExamples = 5
+)
+
+// OutlierStats counts the outliers of a numeric column. Outliers are flagged
+// as values are accumulated, comparing each value to the distribution of the
+// values before it, which keeps detection single-pass & resumable
+type OutlierStats struct {
+	// Count is the number of flagged values
+	Count int `json:"count"`
+	// Examples are the first flagged values, up to MaxOutlierExamples
+	Examples []float64 `json:"examples,omitempty"`
+	// Method is the detection method
+	Method OutlierMethod `json:"method"`
+	// Sample holds values the quartiles of iqr detection are estimated from
+	Sample *Sample `json:"sample,omitempty"`
+	// Threshold is the number of standard deviations or interquartile ranges
+	// a value must be from the bulk of values to be flagged
+	Threshold float64 `json:"threshold"`
+}
+
+// newOutlierStats creates outlier stats for a method. A zero threshold uses
+// the method's default
+func newOutlierStats(method OutlierMethod, threshold float64) *OutlierStats {
+	if threshold == 0 {
+		threshold = method.DefaultThreshold()
+	}
+	o := &OutlierStats{Method: method, Threshold: threshold}
+	if method == OutliersIQR {
+		o.Sample = &Sample{}
+	}
+	return o
+}
+
+// check flags f if it's an outlier of ns, which must not yet include f
+func (o *OutlierStats) check(ns *NumericStats, f float64) {
+	if ns.Count >= OutlierWarmup && o.isOutlier(ns, f) {
+		o.Count++
+		if len(o.Examples) < MaxOutlierExamples {
+			o.Examples = append(o.Examples, f)
+		}
+	}
+	if o.Sample != nil {
+		o.Sample.add(f)
+	}
+}
+
+func (o *OutlierStats) isOutlier(ns *NumericStats, f float64) bool {
+	switch o.Method {
+	case OutliersZScore:
+		sd := ns.StdDev()
+		return sd > 0 && math.Abs(f-ns.Mean) > o.Threshold*sd
+	case OutliersIQR:
+		if o.Sample == nil || o.Sample.Count < OutlierWarmup {
+			return false
+		}
+		q1, q3 := o.Sample.Quantile(0.25), o.Sample.Quantile(0.75)
+		iqr := q3 - q1
+		return f < q1-o.Threshold*iqr || f > q3+o.Threshold*iqr
+	}
+	return false
+}
+
+func (o *OutlierStats) copy() *OutlierStats {
+	c := *o
+	if o.Examples != nil {
+		c.Examples = append([]float64(nil), o.Examples...)
+	}
+	c.Sample = o.Sample.copy()
+	return &c
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// sensorReadings gives n readings around 20 with broken readings at the
+// given positions
+func sensorReadings(n int, broken map[int]float64) []interface{} {
+	vals := make([]interface{}, n)
+	for i := range vals {
+		vals[i] = 20 + float64(i%5)*0.1
+		if v, ok := broken[i]; ok {
+			vals[i] = v
+		}
+	}
+	return vals
+}
+
+func accumulate(t *testing.T, acc *Accumulator, vals []interface{}) {
+	for _, v := range vals {
+		if err := acc.WriteEntry(dsio.Entry{Value: []interface{}{v}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	broken := map[int]float64{40: -999, 60: 850, 75: 21}
+	cases := []struct {
+		method   OutlierMethod
+		count    int
+		examples []float64
+	}{
+		{OutliersZScore, 2, []float64{-999, 850}},
+		{OutliersIQR, 3, []float64{-999, 850, 21}},
+	}
+	for _, c := range cases {
+		acc := NewAccumulator(nil, WithOutliers(c.method, 0))
+		accumulate(t, acc, sensorReadings(100, broken))
+		o := acc.Stats().Stats.([]*ColumnStats)[0].Numeric.Outliers
+		if o == nil {
+			t.Fatalf("%s: expected outlier stats", c.method)
+		}
+		if o.Method != c.method || o.Threshold != c.method.DefaultThreshold() {
+			t.Errorf("%s: config mismatch: %#v", c.method, o)
+		}
+		if o.Count != c.count {
+			t.Errorf("%s: expected %d outliers, got: %d", c.method, c.count, o.Count)
+		}
+		if len(o.Examples) != len(c.examples) {
+			t.Errorf("%s: expected examples %v, got: %v", c.method, c.examples, o.Examples)
+			continue
+		}
+		for i, v := range c.examples {
+			if o.Examples[i] != v {
+				t.Errorf("%s: expected examples %v, got: %v", c.method, c.examples, o.Examples)
+			}
+		}
+	}
+
+	acc := NewAccumulator(nil)
+	accumulate(t, acc, sensorReadings(100, broken))
+	if acc.Stats().Stats.([]*ColumnStats)[0].Numeric.Outliers != nil {
+		t.Errorf("expected outlier detection to be off by default")
+	}
+}
+
+func TestOutliersWarmup(t *testing.T) {
+	acc := NewAccumulator(nil, WithOutliers(OutliersZScore, 0))
+	accumulate(t, acc, sensorReadings(OutlierWarmup, map[int]float64{OutlierWarmup - 1: 9999}))
+	if o := acc.Stats().Stats.([]*ColumnStats)[0].Numeric.Outliers; o.Count != 0 {
+		t.Errorf("expected no outliers during warmup, got: %d", o.Count)
+	}
+}
+
+func TestOutliersResume(t *testing.T) {
+	vals := sensorReadings(200, map[int]float64{50: -999, 150: 900, 170: 1e6})
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+
+	whole := NewAccumulator(st, WithOutliers(OutliersIQR, 0))
+	accumulate(t, whole, vals)
+
+	first := NewAccumulator(st, WithOutliers(OutliersIQR, 0))
+	accumulate(t, first, vals[:120])
+	data, err := json.Marshal(first.Stats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := &dataset.Stats{}
+	if err := json.Unmarshal(data, prev); err != nil {
+		t.Fatal(err)
+	}
+	// outlier detection continues without being enabled again
+	resumed, err := Resume(st, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accumulate(t, resumed, vals[120:])
+	if !resumed.Stats().Equal(whole.Stats()) {
+		a, _ := json.Marshal(whole.Stats())
+		b, _ := json.Marshal(resumed.Stats())
+		t.Errorf("resumed stats differ.\nexpected: %s\ngot:      %s", a, b)
+	}
+
+	if _, err := Resume(st, prev, WithOutliers(OutliersZScore, 0)); err == nil || err.Error() != `stats: column "col_0" outliers were computed with method "iqr"` {
+		t.Errorf("expected method mismatch error, got: %v", err)
+	}
+}
+
+func TestOutliersConfig(t *testing.T) {
+	acc := NewAccumulator(nil, WithOutliers("mad", 0))
+	if err := acc.WriteEntry(dsio.Entry{Value: 1.0}); err == nil || err.Error() != `stats: unknown outlier method "mad"` {
+		t.Errorf("expected unknown method error, got: %v", err)
+	}
+	if _, err := Resume(nil, nil, WithOutliers(OutliersZScore, -1)); err == nil || err.Error() != "stats: outlier threshold cannot be negative" {
+		t.Errorf("expected negative threshold error, got: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// PatternEmail matches email addresses
+	PatternEmail = "email"
+	// PatternURL matches absolute http(s) & ftp urls
+	PatternURL = "url"
+	// PatternUUID matches hyphenated UUIDs of any version
+	PatternUUID = "uuid"
+	// PatternPostalCode matches five digit postal codes, with an optional
+	// four digit extension
+	PatternPostalCode = "postalCode"
+	// PatternISODate matches ISO 8601 dates & RFC 3339 timestamps
+	PatternISODate = "isoDate"
+)
+
+var (
+	emailRegexp      = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s.]+$`)
+	urlRegexp        = regexp.MustCompile(`^(?i:https?|ftp)://[^\s/?#]+[^\s]*$`)
+	uuidRegexp       = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	postalCodeRegexp = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+)
+
+// classify gives the name of the pattern s matches, the empty string if s
+// matches none
+func classify(s string) string {
+	switch {
+	case len(s) == 36 && uuidRegexp.MatchString(s):
+		return PatternUUID
+	case strings.IndexByte(s, '@') > 0 && emailRegexp.MatchString(s):
+		return PatternEmail
+	case strings.Contains(s, "://") && urlRegexp.MatchString(s):
+		return PatternURL
+	case isISODate(s):
+		return PatternISODate
+	case (len(s) == 5 || len(s) == 10) && s[0] >= '0' && s[0] <= '9' && postalCodeRegexp.MatchString(s):
+		return PatternPostalCode
+	}
+	return ""
+}
+
+func isISODate(s string) bool {
+	if len(s) < 10 || s[4] != '-' {
+		return false
+	}
+	if len(s) == 10 {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	}
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
+
+// maxShapeTokens caps the length of a shape. Values with more character runs
+// are considered irregular
+const maxShapeTokens = 32
+
+// character classes of a shape token
+const (
+	classUpper = "upper"
+	classLower = "lower"
+	classAlpha = "alpha"
+	classDigit = "digit"
+	classAlnum = "alnum"
+	classSpace = "space"
+	classLit   = "literal"
+)
+
+// ShapeToken is one run of similar characters in the shape of string values
+type ShapeToken struct {
+	// Class is the character class of the run. Runs of any other character
+	// have the class "literal" & record the character in Literal
+	Class string `json:"class"`
+	// Literal is the character of a literal run
+	Literal string `json:"literal,omitempty"`
+	// Max is the longest observed run
+	Max int `json:"max"`
+	// Min is the shortest observed run
+	Min int `json:"min"`
+}
+
+// shapeOf breaks s into runs of characters of the same class, appending runs
+// to buf. shapeOf returns nil if s has more than maxShapeTokens runs
+func shapeOf(s string, buf []ShapeToken) []ShapeToken {
+	shape := buf[:0]
+	for i, r := range s {
+		class, lit := runeClass(s, i, r)
+		if n := len(shape); n > 0 && shape[n-1].Class == class && shape[n-1].Literal == lit {
+			shape[n-1].Min++
+			shape[n-1].Max++
+			continue
+		}
+		if len(shape) == maxShapeTokens {
+			return nil
+		}
+		shape = append(shape, ShapeToken{Class: class, Literal: lit, Min: 1, Max: 1})
+	}
+	return shape
+}
+
+// runeClass gives the class of rune r at byte offset i of s. Literals are
+// sliced from s to avoid allocating
+func runeClass(s string, i int, r rune) (class, lit string) {
+	switch {
+	case unicode.IsUpper(r):
+		return classUpper, ""
+	case unicode.IsLetter(r):
+		return classLower, ""
+	case unicode.IsDigit(r):
+		return classDigit, ""
+	case unicode.IsSpace(r):
+		return classSpace, ""
+	}
+	return classLit, s[i : i+utf8.RuneLen(r)]
+}
+
+// mergeShapes generalizes shape a to also describe b, reporting if a
+// changed, and false ok if the shapes can't be merged. a is modified in place
+func mergeShapes(a []*ShapeToken, b []ShapeToken) (changed, ok bool) {
+	if len(a) != len(b) {
+		return false, false
+	}
+	for i, t := range a {
+		class, ok := mergeClasses(t.Class, b[i].Class)
+		if !ok || t.Literal != b[i].Literal {
+			return false, false
+		}
+		if class != t.Class || b[i].Min < t.Min || b[i].Max > t.Max {
+			changed = true
+		}
+		t.Class = class
+		if b[i].Min < t.Min {
+			t.Min = b[i].Min
+		}
+		if b[i].Max > t.Max {
+			t.Max = b[i].Max
+		}
+	}
+	return changed, true
+}
+
+func mergeClasses(a, b string) (string, bool) {
+	if a == b {
+		return a, true
+	}
+	if a == classLit || b == classLit || a == classSpace || b == classSpace {
+		return "", false
+	}
+	letters := func(c string) bool { return c == classUpper || c == classLower || c == classAlpha }
+	if letters(a) && letters(b) {
+		return classAlpha, true
+	}
+	return classAlnum, true
+}
+
+// shapeRegexp gives a regular expression matching every value of shape
+func shapeRegexp(shape []*ShapeToken) string {
+	buf := &strings.Builder{}
+	buf.WriteString("^")
+	for _, t := range shape {
+		switch t.Class {
+		case classUpper:
+			buf.WriteString(`\p{Lu}`)
+		case classLower:
+			buf.WriteString(`\p{Ll}`)
+		case classAlpha:
+			buf.WriteString(`\pL`)
+		case classDigit:
+			buf.WriteString(`\d`)
+		case classAlnum:
+			buf.WriteString(`[\pL\d]`)
+		case classSpace:
+			buf.WriteString(`\s`)
+		default:
+			buf.WriteString(regexp.QuoteMeta(t.Literal))
+		}
+		switch {
+		case t.Min == 1 && t.Max == 1:
+		case t.Min == t.Max:
+			fmt.Fprintf(buf, "{%d}", t.Min)
+		default:
+			fmt.Fprintf(buf, "{%d,%d}", t.Min, t.Max)
+		}
+	}
+	buf.WriteString("$")
+	return buf.String()
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// SampleSize is the number of values a Sample keeps
+const SampleSize = 256
+
+// Sample is a uniform sample of a stream of values, kept with reservoir
+// sampling. Whether a value is sampled is derived from its position in the
+// stream instead of a random source, so a sample resumed from persisted
+// stats makes the same choices as one that saw every value
+type Sample struct {
+	// Count is the number of values observed
+	Count int `json:"count"`
+	// Values are the sampled values, at most SampleSize of them
+	Values []float64 `json:"values,omitempty"`
+
+	// sorted caches the sorted sample, nil when stale
+	sorted []float64
+}
+
+// Quantile estimates quantile p of the observed values from the sample,
+// zero if no values have been observed. The estimate is exact while fewer
+// than SampleSize values have been observed
+func (s *Sample) Quantile(p float64) float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	if s.sorted == nil {
+		s.sorted = make([]float64, len(s.Values))
+		copy(s.sorted, s.Values)
+		sort.Float64s(s.sorted)
+	}
+	return s.sorted[int(math.Round(p*float64(len(s.sorted)-1)))]
+}
+
+func (s *Sample) add(x float64) {
+	s.Count++
+	if len(s.Values) < SampleSize {
+		s.Values = append(s.Values, x)
+		s.sorted = nil
+		return
+	}
+	if i := int(mix(uint64(s.Count)) % uint64(s.Count)); i < SampleSize {
+		s.Values[i] = x
+		s.sorted = nil
+	}
+}
+
+func (s *Sample) copy() *Sample {
+	if s == nil {
+		return nil
+	}
+	c := &Sample{Count: s.Count}
+	if s.Values != nil {
+		c.Values = append([]float64(nil), s.Values...)
+	}
+	return c
+}
+
+// mix is the splitmix64 finalizer, scrambling a position into a
+// well-distributed pseudo-random number
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	s := &Sample{}
+	if s.Quantile(0.5) != 0 {
+		t.Errorf("expected empty sample quantile to be zero")
+	}
+	for _, v := range []float64{3, 1, 2} {
+		s.add(v)
+	}
+	if s.Quantile(0.5) != 2 || s.Quantile(0) != 1 || s.Quantile(1) != 3 {
+		t.Errorf("expected exact quantiles of few values")
+	}
+
+	rnd := rand.New(rand.NewSource(7))
+	s = &Sample{}
+	for i := 0; i < 10000; i++ {
+		s.add(float64(rnd.Intn(1001)))
+	}
+	if s.Count != 10000 || len(s.Values) != SampleSize {
+		t.Errorf("expected %d sampled of 10000 values, got %d of %d", SampleSize, len(s.Values), s.Count)
+	}
+	for _, p := range []float64{0.25, 0.5, 0.75} {
+		if got := s.Quantile(p); math.Abs(got-p*1000) > 60 {
+			t.Errorf("quantile %f estimate %f is far from %f", p, got, p*1000)
+		}
+	}
+}
+
+func TestSampleResume(t *testing.T) {
+	whole := &Sample{}
+	for i := 0; i < 1000; i++ {
+		whole.add(float64(i))
+	}
+	s := &Sample{}
+	for i := 0; i < 400; i++ {
+		s.add(float64(i))
+	}
+	s = s.copy()
+	for i := 400; i < 1000; i++ {
+		s.add(float64(i))
+	}
+	for i, v := range whole.Values {
+		if s.Values[i] != v {
+			t.Fatalf("expected resumed sample to match, index %d: %f != %f", i, s.Values[i], v)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package stats computes summaries of dataset body values. Stats are
+// accumulated one entry at a time & record enough state to resume from a
+// persisted dataset.Stats component, so stats for an append-only body can be
+// brought up to date by reading only the appended entries
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/vals"
+)
+
+// ColumnStats summarizes the values of one body column. Values of each type
+// are summarized separately, a column that mixes types has a summary for
+// each type it holds
+type ColumnStats struct {
+	// Boolean summarizes boolean values
+	Boolean *BooleanStats `json:"boolean,omitempty"`
+	// Count is the number of values in the column, including nulls
+	Count int `json:"count"`
+	// Date summarizes date values
+	Date *DateStats `json:"date,omitempty"`
+	// DateTime summarizes datetime values
+	DateTime *DateTimeStats `json:"dateTime,omitempty"`
+	// Nulls is the number of null values
+	Nulls int `json:"nulls,omitempty"`
+	// Numeric summarizes number values
+	Numeric *NumericStats `json:"numeric,omitempty"`
+	// String summarizes string values
+	String *StringStats `json:"string,omitempty"`
+	// Title is the column title
+	Title string `json:"title"`
+}
+
+// add records one value
+func (cs *ColumnStats) add(v interface{}, cfg *Config) {
+	switch x := v.(type) {
+	case nil:
+		cs.addNull()
+	case bool:
+		cs.addBool(x)
+	case string:
+		cs.addString(x)
+	case vals.Date, vals.DateTime:
+		cs.addTemporal(x.(vals.Value))
+	case time.Time:
+		cs.addTemporal(vals.NewDateTime(x))
+	default:
+		if f, ok := toFloat(v); ok {
+			cs.addFloat(f, cfg)
+		} else {
+			cs.Count++
+		}
+	}
+}
+
+func (cs *ColumnStats) addNull() {
+	cs.Count++
+	cs.Nulls++
+}
+
+func (cs *ColumnStats) addBool(b bool) {
+	cs.Count++
+	if cs.Boolean == nil {
+		cs.Boolean = &BooleanStats{}
+	}
+	cs.Boolean.add(b)
+}
+
+func (cs *ColumnStats) addString(s string) {
+	cs.Count++
+	if cs.String == nil {
+		cs.String = &StringStats{}
+	}
+	cs.String.add(s)
+}
+
+// addTemporal records a temporal value. Times of day are only counted
+func (cs *ColumnStats) addTemporal(v vals.Value) {
+	cs.Count++
+	switch x := v.(type) {
+	case vals.Date:
+		if cs.Date == nil {
+			cs.Date = &DateStats{}
+		}
+		cs.Date.add(x)
+	case vals.DateTime:
+		if cs.DateTime == nil {
+			cs.DateTime = &DateTimeStats{}
+		}
+		cs.DateTime.add(x)
+	}
+}
+
+func (cs *ColumnStats) addFloat(f float64, cfg *Config) {
+	cs.Count++
+	if cs.Numeric == nil {
+		cs.Numeric = &NumericStats{}
+	}
+	if cs.Numeric.Outliers == nil && cfg.Outliers != "" {
+		cs.Numeric.Outliers = newOutlierStats(cfg.Outliers, cfg.OutlierThreshold)
+	}
+	cs.Numeric.add(f)
+}
+
+// copy returns a deep copy of column stats
+func (cs *ColumnStats) copy() *ColumnStats {
+	c := &ColumnStats{
+		Count: cs.Count,
+		Nulls: cs.Nulls,
+		Title: cs.Title,
+	}
+	if cs.Boolean != nil {
+		b := *cs.Boolean
+		c.Boolean = &b
+	}
+	if cs.Date != nil {
+		d := *cs.Date
+		c.Date = &d
+	}
+	if cs.DateTime != nil {
+		dt := *cs.DateTime
+		c.DateTime = &dt
+	}
+	if cs.Numeric != nil {
+		c.Numeric = cs.Numeric.copy()
+	}
+	if cs.String != nil {
+		c.String = cs.String.copy()
+	}
+	return c
+}
+
+// BooleanStats summarizes boolean values
+type BooleanStats struct {
+	// False is the number of false values
+	False int `json:"false"`
+	// True is the number of true values
+	True int `json:"true"`
+}
+
+func (bs *BooleanStats) add(b bool) {
+	if b {
+		bs.True++
+	} else {
+		bs.False++
+	}
+}
+
+// Compute summarizes every entry read from r
+func Compute(r dsio.EntryReader, opts ...func(*Config)) (*dataset.Stats, error) {
+	acc := NewAccumulator(r.Structure(), opts...)
+	if err := dsio.Copy(r, acc); err != nil {
+		return nil, err
+	}
+	return acc.Stats(), nil
+}
+
+// Update brings prev up to date with a body that extends the body prev was
+// computed from. The prev.Entries leading entries of r are skipped without
+// being summarized, so r must read the full body of the new version, & the
+// entries prev summarizes must be unchanged. Computing stats for a body that
+// rewrites prior entries requires Compute. A nil prev summarizes all of r
+func Update(prev *dataset.Stats, r dsio.EntryReader, opts ...func(*Config)) (*dataset.Stats, error) {
+	if prev == nil {
+		return Compute(r, opts...)
+	}
+	acc, err := Resume(r.Structure(), prev, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < prev.Entries; i++ {
+		if _, err := r.ReadEntry(); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("stats: body has %d entries, fewer than the %d previous stats summarize", i, prev.Entries)
+			}
+			return nil, fmt.Errorf("stats: %w", err)
+		}
+	}
+	if err := dsio.Copy(r, acc); err != nil {
+		return nil, err
+	}
+	return acc.Stats(), nil
+}
+
+// Columns reads the column stats of a stats component. Stats decoded from a
+// document hold generic values, which are converted with a JSON round trip
+func Columns(sa *dataset.Stats) ([]*ColumnStats, error) {
+	if sa == nil || sa.Stats == nil {
+		return nil, nil
+	}
+	if cols, ok := sa.Stats.([]*ColumnStats); ok {
+		return cols, nil
+	}
+	data, err := json.Marshal(sa.Stats)
+	if err != nil {
+		return nil, fmt.Errorf("stats: %w", err)
+	}
+	var cols []*ColumnStats
+	if err := json.Unmarshal(data, &cols); err != nil {
+		return nil, fmt.Errorf("stats: decoding column stats: %w", err)
+	}
+	return cols, nil
+}
+
+// toFloat converts go number types to a float64
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case int8:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case float32:
+		return float64(x), true
+	case float64:
+		return x, true
+	case vals.Decimal:
+		return x.Number(), true
+	}
+	return 0, false
+}
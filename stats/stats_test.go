@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+var tableStructure = &dataset.Structure{
+	Format: "json",
+	Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "name", "type": "string"},
+				map[string]interface{}{"title": "count", "type": "integer"},
+				map[string]interface{}{"title": "ok", "type": "boolean"},
+			},
+		},
+	},
+}
+
+func jsonReader(t *testing.T, st *dataset.Structure, data string) dsio.EntryReader {
+	r, err := dsio.NewJSONReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestCompute(t *testing.T) {
+	sa, err := Compute(jsonReader(t, tableStructure, `[["a",1,true],["b",3,false],["a",null,true]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa.Entries != 3 || sa.Qri != dataset.KindStats.String() {
+		t.Errorf("unexpected stats component: %#v", sa)
+	}
+	expect := []*ColumnStats{
+		{Title: "name", Count: 3, String: &StringStats{Count: 3, Frequencies: map[string]int{"a": 2, "b": 1}, MaxLength: 1, MinLength: 1,
+			Regex: `^\p{Ll}$`, Shape: []*ShapeToken{{Class: "lower", Max: 1, Min: 1}}}},
+		{Title: "count", Count: 3, Nulls: 1, Numeric: &NumericStats{Count: 2, Max: 3, Mean: 2, Min: 1, Variance: 1}},
+		{Title: "ok", Count: 3, Boolean: &BooleanStats{False: 1, True: 2}},
+	}
+	if !reflect.DeepEqual(expect, sa.Stats) {
+		data, _ := json.Marshal(sa.Stats)
+		t.Errorf("stats mismatch. got: %s", data)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	prior := `[["a",1,true],["b",3,false]]`
+	full := `[["a",1,true],["b",3,false],["c",8,true],["a",-2,null]]`
+
+	prev, err := Compute(jsonReader(t, tableStructure, prior))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// persist & reload the previous stats, as a stored version would be
+	data, err := json.Marshal(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &dataset.Stats{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Update(loaded, jsonReader(t, tableStructure, full))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := Compute(jsonReader(t, tableStructure, full))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(expect) {
+		ge, _ := json.Marshal(got)
+		ee, _ := json.Marshal(expect)
+		t.Errorf("updated stats differ from recomputed stats.\nexpected: %s\ngot:      %s", ee, ge)
+	}
+
+	if _, err := Update(loaded, jsonReader(t, tableStructure, `[["a",1,true]]`)); err == nil {
+		t.Errorf("expected a shorter body to error")
+	} else if err.Error() != "stats: body has 1 entries, fewer than the 2 previous stats summarize" {
+		t.Errorf("error mismatch: %s", err)
+	}
+
+	fresh, err := Update(nil, jsonReader(t, tableStructure, full))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fresh.Equal(expect) {
+		t.Errorf("expected nil previous stats to compute from scratch")
+	}
+}
+
+func TestColumns(t *testing.T) {
+	cols, err := Columns(nil)
+	if err != nil || cols != nil {
+		t.Errorf("expected nil stats to give no columns, got: %v, %v", cols, err)
+	}
+	if _, err := Columns(&dataset.Stats{Stats: "nope"}); err == nil {
+		t.Errorf("expected invalid column stats to error")
+	}
+	cols, err = Columns(&dataset.Stats{Stats: []interface{}{map[string]interface{}{"title": "a", "count": 2.0, "nulls": 2.0}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 1 || cols[0].Title != "a" || cols[0].Nulls != 2 {
+		t.Errorf("decoded column mismatch: %#v", cols)
+	}
+}
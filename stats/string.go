@@ -0,0 +1,129 @@
+package stats
+
+import "unicode/utf8"
+
+// MaxFrequencies caps the number of distinct values a string summary counts,
+// bounding the size of stats for high-cardinality columns
+const MaxFrequencies = 1000
+
+// StringStats summarizes string values
+type StringStats struct {
+	// Count is the number of string values
+	Count int `json:"count"`
+	// Frequencies counts occurrences of each distinct value, up to
+	// MaxFrequencies values
+	Frequencies map[string]int `json:"frequencies,omitempty"`
+	// FrequenciesTruncated is true when values were left out of Frequencies
+	FrequenciesTruncated bool `json:"frequenciesTruncated,omitempty"`
+	// MaxLength is the length of the longest value, in characters
+	MaxLength int `json:"maxLength"`
+	// MinLength is the length of the shortest value, in characters
+	MinLength int `json:"minLength"`
+	// Patterns counts values matching each common pattern, keyed by pattern
+	// name. Values matching no pattern aren't counted
+	Patterns map[string]int `json:"patterns,omitempty"`
+	// Regex is a generalized regular expression matching every non-empty
+	// value, derived from Shape. Empty when values share no common shape
+	Regex string `json:"regex,omitempty"`
+	// Shape describes the runs of characters shared by every non-empty value
+	Shape []*ShapeToken `json:"shape,omitempty"`
+	// ShapeIrregular is true when non-empty values share no common shape
+	ShapeIrregular bool `json:"shapeIrregular,omitempty"`
+
+	// scratch is reused to break values into shapes
+	scratch []ShapeToken
+}
+
+func (ss *StringStats) add(s string) {
+	l := utf8.RuneCountInString(s)
+	if ss.Count == 0 || l < ss.MinLength {
+		ss.MinLength = l
+	}
+	if ss.Count == 0 || l > ss.MaxLength {
+		ss.MaxLength = l
+	}
+	ss.Count++
+
+	if ss.Frequencies == nil {
+		ss.Frequencies = map[string]int{}
+	}
+	if _, ok := ss.Frequencies[s]; ok || len(ss.Frequencies) < MaxFrequencies {
+		ss.Frequencies[s]++
+	} else {
+		ss.FrequenciesTruncated = true
+	}
+
+	if p := classify(s); p != "" {
+		if ss.Patterns == nil {
+			ss.Patterns = map[string]int{}
+		}
+		ss.Patterns[p]++
+	}
+	if s != "" {
+		ss.addShape(s)
+	}
+}
+
+// addShape generalizes the shape of values to describe s
+func (ss *StringStats) addShape(s string) {
+	if ss.ShapeIrregular {
+		return
+	}
+	shape := shapeOf(s, ss.scratch)
+	if shape == nil {
+		ss.irregular()
+		return
+	}
+	ss.scratch = shape
+	if ss.Shape == nil {
+		ss.Shape = make([]*ShapeToken, len(shape))
+		for i := range shape {
+			t := shape[i]
+			// literals are sliced from the value, copy them so values can be
+			// collected
+			t.Literal = string(append([]byte(nil), t.Literal...))
+			ss.Shape[i] = &t
+		}
+		ss.Regex = shapeRegexp(ss.Shape)
+		return
+	}
+	changed, ok := mergeShapes(ss.Shape, shape)
+	if !ok {
+		ss.irregular()
+		return
+	}
+	if changed {
+		ss.Regex = shapeRegexp(ss.Shape)
+	}
+}
+
+func (ss *StringStats) irregular() {
+	ss.ShapeIrregular = true
+	ss.Shape = nil
+	ss.Regex = ""
+}
+
+func (ss *StringStats) copy() *StringStats {
+	c := *ss
+	c.scratch = nil
+	if ss.Frequencies != nil {
+		c.Frequencies = make(map[string]int, len(ss.Frequencies))
+		for k, v := range ss.Frequencies {
+			c.Frequencies[k] = v
+		}
+	}
+	if ss.Patterns != nil {
+		c.Patterns = make(map[string]int, len(ss.Patterns))
+		for k, v := range ss.Patterns {
+			c.Patterns[k] = v
+		}
+	}
+	if ss.Shape != nil {
+		c.Shape = make([]*ShapeToken, len(ss.Shape))
+		for i, t := range ss.Shape {
+			tc := *t
+			c.Shape[i] = &tc
+		}
+	}
+	return &c
+}
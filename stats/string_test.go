@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStringStats(t *testing.T) {
+	ss := &StringStats{}
+	for _, s := range []string{"héllo", "", "hi", "hi"} {
+		ss.add(s)
+	}
+	if ss.Count != 4 || ss.MinLength != 0 || ss.MaxLength != 5 {
+		t.Errorf("length mismatch: %#v", ss)
+	}
+	if ss.Frequencies["hi"] != 2 || ss.Frequencies[""] != 1 || ss.FrequenciesTruncated {
+		t.Errorf("frequencies mismatch: %#v", ss.Frequencies)
+	}
+
+	ss = &StringStats{}
+	for i := 0; i < MaxFrequencies+10; i++ {
+		ss.add(fmt.Sprintf("v%d", i))
+	}
+	ss.add("v0")
+	if len(ss.Frequencies) != MaxFrequencies || !ss.FrequenciesTruncated {
+		t.Errorf("expected frequencies to be truncated at %d values, got %d", MaxFrequencies, len(ss.Frequencies))
+	}
+	if ss.Frequencies["v0"] != 2 {
+		t.Errorf("expected counted values to keep counting after truncation")
+	}
+}
+
+func TestStringPatterns(t *testing.T) {
+	cases := []struct {
+		val, pattern string
+	}{
+		{"a@example.com", PatternEmail},
+		{"not an@email", ""},
+		{"https://mfdz.de/daten?x=1", PatternURL},
+		{"a@localhost", ""},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", PatternUUID},
+		{"70173", PatternPostalCode},
+		{"12345-6789", PatternPostalCode},
+		{"1234", ""},
+		{"2019-03-31", PatternISODate},
+		{"2019-03-31T12:00:00Z", PatternISODate},
+		{"2019-13-31", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := classify(c.val); got != c.pattern {
+			t.Errorf("%q: expected pattern %q, got: %q", c.val, c.pattern, got)
+		}
+	}
+
+	ss := &StringStats{}
+	for _, s := range []string{"a@example.com", "b@example.org", "70173", "unknown"} {
+		ss.add(s)
+	}
+	if len(ss.Patterns) != 2 || ss.Patterns[PatternEmail] != 2 || ss.Patterns[PatternPostalCode] != 1 {
+		t.Errorf("pattern distribution mismatch: %v", ss.Patterns)
+	}
+}
+
+func TestStringShape(t *testing.T) {
+	cases := []struct {
+		vals  []string
+		regex string
+	}{
+		{[]string{"AB-123", "CD-4567", ""}, `^\p{Lu}{2}-\d{3,4}$`},
+		{[]string{"Berlin", "Hamburg"}, `^\p{Lu}\p{Ll}{5,6}$`},
+		{[]string{"ab12", "AB12", "abc2"}, `^\pL{2,3}\d{1,2}$`},
+		{[]string{"ab-12", "12-ab"}, `^[\pL\d]{2}-[\pL\d]{2}$`},
+		{[]string{"ab12", "ab1c"}, ""},
+		{[]string{"a.b", "a+b"}, ""},
+		{[]string{"1 2", "12"}, ""},
+	}
+	for i, c := range cases {
+		ss := &StringStats{}
+		for _, v := range c.vals {
+			ss.add(v)
+		}
+		if ss.Regex != c.regex {
+			t.Errorf("case %d: expected regex %q, got: %q", i, c.regex, ss.Regex)
+		}
+		if ss.ShapeIrregular != (c.regex == "") {
+			t.Errorf("case %d: expected irregular to be %t", i, c.regex == "")
+		}
+		if c.regex == "" {
+			continue
+		}
+		re := regexp.MustCompile(c.regex)
+		for _, v := range c.vals {
+			if v != "" && !re.MatchString(v) {
+				t.Errorf("case %d: regex %s doesn't match %q", i, c.regex, v)
+			}
+		}
+	}
+
+	ss := &StringStats{}
+	ss.add(strings.Repeat("a1", maxShapeTokens))
+	if !ss.ShapeIrregular {
+		t.Errorf("expected values with too many runs to be irregular")
+	}
+}
@@ -0,0 +1,44 @@
+package stats
+
+import "github.com/qri-io/dataset/vals"
+
+// DateStats summarizes date values
+type DateStats struct {
+	// Count is the number of date values
+	Count int `json:"count"`
+	// Max is the latest date
+	Max vals.Date `json:"max"`
+	// Min is the earliest date
+	Min vals.Date `json:"min"`
+}
+
+func (ds *DateStats) add(d vals.Date) {
+	if ds.Count == 0 || d.Time().Before(ds.Min.Time()) {
+		ds.Min = d
+	}
+	if ds.Count == 0 || d.Time().After(ds.Max.Time()) {
+		ds.Max = d
+	}
+	ds.Count++
+}
+
+// DateTimeStats summarizes datetime values. Datetimes are compared as
+// instants, Min & Max keep the offset they were written with
+type DateTimeStats struct {
+	// Count is the number of datetime values
+	Count int `json:"count"`
+	// Max is the latest datetime
+	Max vals.DateTime `json:"max"`
+	// Min is the earliest datetime
+	Min vals.DateTime `json:"min"`
+}
+
+func (ds *DateTimeStats) add(dt vals.DateTime) {
+	if ds.Count == 0 || dt.Time().Before(ds.Min.Time()) {
+		ds.Min = dt
+	}
+	if ds.Count == 0 || dt.Time().After(ds.Max.Time()) {
+		ds.Max = dt
+	}
+	ds.Count++
+}
@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/vals"
+)
+
+func TestTemporalStats(t *testing.T) {
+	date := func(s string) vals.Date {
+		d, err := vals.ParseDate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	dateTime := func(s string) vals.DateTime {
+		dt, err := vals.ParseDateTime(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dt
+	}
+
+	acc := NewAccumulator(nil)
+	for _, v := range []interface{}{
+		date("2020-03-31"),
+		date("2019-01-01"),
+		dateTime("2020-01-01T01:00:00+02:00"),
+		dateTime("2020-01-01T00:00:00Z"),
+		time.Date(2019, 12, 31, 23, 0, 0, 0, time.UTC),
+		nil,
+	} {
+		if err := acc.WriteEntry(dsio.Entry{Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// resume from stats decoded from a document
+	data, err := json.Marshal(acc.Stats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := &dataset.Stats{}
+	if err := json.Unmarshal(data, prev); err != nil {
+		t.Fatal(err)
+	}
+	acc, err = Resume(nil, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.WriteEntry(dsio.Entry{Value: date("2021-06-01")}); err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := Columns(acc.Stats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := cols[0]
+	if cs.Count != 7 || cs.Nulls != 1 {
+		t.Errorf("count mismatch. expected 7 values & 1 null, got: %d, %d", cs.Count, cs.Nulls)
+	}
+	if ds := cs.Date; ds == nil || ds.Count != 3 || ds.Min.String() != "2019-01-01" || ds.Max.String() != "2021-06-01" {
+		t.Errorf("date stats mismatch. got: %#v", ds)
+	}
+	if ds := cs.DateTime; ds == nil || ds.Count != 3 || ds.Min.String() != "2020-01-01T01:00:00+02:00" || ds.Max.String() != "2020-01-01T00:00:00Z" {
+		t.Errorf("datetime stats mismatch. got: %#v", ds)
+	}
+}
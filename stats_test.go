@@ -0,0 +1,61 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStatsJSON(t *testing.T) {
+	sa := &Stats{
+		Entries: 3,
+		Stats:   []interface{}{map[string]interface{}{"count": 3.0, "title": "a"}},
+	}
+	data, err := json.Marshal(sa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"entries":3,"qri":"sa:0","stats":[{"count":3,"title":"a"}]}`
+	if string(data) != expect {
+		t.Errorf("json mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+	got := &Stats{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	sa.Qri = KindStats.String()
+	if !reflect.DeepEqual(sa, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", sa, got)
+	}
+
+	data, err = json.Marshal(NewStatsRef("/ipfs/QmStats"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"/ipfs/QmStats"` {
+		t.Errorf("expected reference to marshal as a string, got: %s", data)
+	}
+
+	if _, err := UnmarshalStats(false); err == nil {
+		t.Errorf("expected invalid type to error")
+	}
+}
+
+func TestStatsAssignClone(t *testing.T) {
+	sa := &Stats{Path: "/ipfs/QmStats", Entries: 5}
+	sa.Assign(nil, &Stats{Entries: 7, Stats: []interface{}{"x"}})
+	if sa.Path != "/ipfs/QmStats" || sa.Entries != 7 || sa.Stats == nil {
+		t.Errorf("assign mismatch: %#v", sa)
+	}
+	c := sa.Clone()
+	if !reflect.DeepEqual(sa, c) {
+		t.Fatalf("clone mismatch. expected: %#v, got: %#v", sa, c)
+	}
+	c.Stats.([]interface{})[0] = "changed"
+	if sa.Stats.([]interface{})[0] == "changed" {
+		t.Errorf("clone shares memory with original")
+	}
+	if (*Stats)(nil).Clone() != nil {
+		t.Errorf("expected nil clone to be nil")
+	}
+}
@@ -0,0 +1,134 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnmarshalStrict decodes JSON data into v, which should be a pointer to a
+// Dataset or dataset component. Unlike json.Unmarshal, UnmarshalStrict errors
+// if data contains any field that isn't part of the dataset spec, at any depth.
+// Field names must match exactly, including case. Arbitrary metadata fields
+// are also rejected, where normal decoding would hoist them into Meta.
+// Namespaced metadata extension keys like "mfdz:qualityReport" are allowed
+func UnmarshalStrict(data []byte, v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return fmt.Errorf("strict unmarshal requires a non-nil pointer, got %T", v)
+	}
+	if err := checkKnownFields(data, t.Elem(), ""); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// metaType is the type of Meta, the only component that holds extensions
+var metaType = reflect.TypeOf(Meta{})
+
+// checkKnownFields recursively confirms all object keys in data correspond to
+// the json field names of t. Values that aren't objects or arrays are skipped,
+// which accounts for components encoded as path strings
+func checkKnownFields(data []byte, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			// not an object, leave it to the type to decide if it's valid
+			return nil
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(obj) {
+			ft, ok := fields[key]
+			if !ok && t == metaType && IsExtensionKey(key) {
+				// extensions are hoisted into meta & checked by Meta.Validate
+				continue
+			}
+			if !ok {
+				return fmt.Errorf("unknown field %q", joinFieldPath(path, key))
+			}
+			if err := checkKnownFields(obj[key], ft, joinFieldPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			return nil
+		}
+		arr := []json.RawMessage{}
+		if err := json.Unmarshal(data, &arr); err != nil {
+			return nil
+		}
+		for i, raw := range arr {
+			if err := checkKnownFields(raw, t.Elem(), fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil
+		}
+		obj := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil
+		}
+		for _, key := range sortedKeys(obj) {
+			if err := checkKnownFields(obj[key], t.Elem(), joinFieldPath(path, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields maps the json names of exported struct fields to their types.
+// types that encode as strings (eg. time.Time) have no fields, and are never
+// decoded as objects by checkKnownFields
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		fields[name] = f.Type
+	}
+	if _, ok := fields["scriptBytes"]; ok {
+		// scripts may be inlined as plain text, see ScriptEncodingString
+		fields["scriptString"] = reflect.TypeOf("")
+	}
+	return fields
+}
+
+func sortedKeys(obj map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,48 @@
+package dataset
+
+import (
+	"testing"
+)
+
+func TestUnmarshalStrict(t *testing.T) {
+	cases := []struct {
+		description string
+		data        string
+		v           interface{}
+		err         string
+	}{
+		{"valid dataset", `{"qri":"ds:0","commit":{"title":"t","timestamp":"2019-01-01T00:00:00Z","author":{"id":"a"}},"meta":{"title":"t","citations":[{"name":"c"}],"license":{"type":"MIT"}},"structure":{"format":"json","schema":{"type":"array","anything":"goes"}},"transform":{"resources":{"a":"/ipfs/QmA","b":{"path":"/ipfs/QmB"}},"scriptBytes":"ZGVmIGZvbygpOiBwYXNz"}}`, &Dataset{}, ""},
+		{"path references", `{"meta":"/ipfs/QmMeta","structure":"/ipfs/QmSt"}`, &Dataset{}, ""},
+		{"dataset reference", `"/ipfs/QmDs"`, &Dataset{}, ""},
+		{"unknown dataset field", `{"qri":"ds:0","bdy":[]}`, &Dataset{}, `unknown field "bdy"`},
+		{"misspelled transform field", `{"transform":{"scriptpath":"/ipfs/QmScript"}}`, &Dataset{}, `unknown field "transform.scriptpath"`},
+		{"arbitrary meta field", `{"meta":{"title":"t","foo":"bar"}}`, &Dataset{}, `unknown field "meta.foo"`},
+		{"meta extension", `{"meta":{"title":"t","mfdz:qualityReport":{"score":0.9}}}`, &Dataset{}, ""},
+		{"extension outside meta", `{"format":"csv","mfdz:qualityReport":{}}`, &Structure{}, `unknown field "mfdz:qualityReport"`},
+		{"nested citation field", `{"citations":[{"name":"a"},{"nme":"b"}]}`, &Meta{}, `unknown field "citations.1.nme"`},
+		{"transform resource field", `{"resources":{"a":{"path":"/a","pth":"/b"}}}`, &Transform{}, `unknown field "resources.a.pth"`},
+		{"structure field", `{"format":"csv","formatconfig":{}}`, &Structure{}, `unknown field "formatconfig"`},
+		{"non-pointer", `{}`, Dataset{}, `strict unmarshal requires a non-nil pointer, got dataset.Dataset`},
+	}
+
+	for _, c := range cases {
+		err := UnmarshalStrict([]byte(c.data), c.v)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("case '%s' unexpected error: %s", c.description, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case '%s' error mismatch. want: %s, got: %v", c.description, c.err, err)
+		}
+	}
+
+	ds := &Dataset{}
+	if err := UnmarshalStrict([]byte(`{"meta":{"title":"strict"}}`), ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Meta.Title != "strict" {
+		t.Errorf("expected strict unmarshal to decode values")
+	}
+}
@@ -3,6 +3,7 @@ package dataset
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/qri-io/jsonschema"
 )
@@ -35,6 +36,9 @@ type Structure struct {
 	// Compression specifies any compression on the source data,
 	// if empty assume no compression
 	Compression string `json:"compression,omitempty"`
+	// CRS is the coordinate reference system of coordinates in a geospatial
+	// body, as an EPSG identifier like "EPSG:4326"
+	CRS string `json:"crs,omitempty"`
 	// Maximum nesting level of composite types in the dataset.
 	// eg: depth 1 == [], depth 2 == [[]]
 	// derived
@@ -55,11 +59,16 @@ type Structure struct {
 	// to interpret the speficied format.
 	// FormatConfig FormatConfig `json:"formatConfig,omitempty"`
 	FormatConfig map[string]interface{} `json:"formatConfig,omitempty"`
+	// Geometry configures validation of coordinates in a geospatial body
+	Geometry *GeometryRules `json:"geometry,omitempty"`
 
 	// Length is the length of the data object in bytes.
 	// must always match & be present
 	// derived
 	Length int `json:"length,omitempty"`
+	// Partition declares the body is stored as shards by the values of a
+	// column, see Dataset.BodyShards
+	Partition *Partition `json:"partition,omitempty"`
 	// location of this structure, transient
 	// derived
 	Path string `json:"path,omitempty"`
@@ -70,6 +79,9 @@ type Structure struct {
 	// are defined using the IETF json-schema specification. for more info
 	// on json-schema see: https://json-schema.org
 	Schema map[string]interface{} `json:"schema,omitempty"`
+	// Source is the format the body was given in, set when it's stored in a
+	// different format. Format & FormatConfig describe the stored body
+	Source *SourceFormat `json:"source,omitempty"`
 	// Strict requires schema validation to pass without error. Datasets with
 	// strict: true can have additional functionality and performance speedups
 	// that comes with being able to assume that all data is valid
@@ -130,40 +142,65 @@ func (s *Structure) RequiresTabularSchema() bool {
 	return s.Format == CSVDataFormat.String() || s.Format == XLSXDataFormat.String()
 }
 
-// Abstract returns this structure instance in it's "Abstract" form
-// stripping all nonessential values &
-// renaming all schema field names to standard variable names
+// Abstract returns this structure instance in it's "Abstract" form: a
+// fingerprint of the shape of the data that leaves out everything else.
+// Derived values & format config are stripped, schema annotations like
+// titles & descriptions are dropped, and schema column names are replaced
+// with AbstractColumnName names in column order. Object row properties are
+// named in sorted property order. Structures of datasets that hold the same
+// shape of data under different column names have equal abstract forms
 func (s *Structure) Abstract() *Structure {
 	a := &Structure{
-		Format:       s.Format,
-		FormatConfig: s.FormatConfig,
-		Encoding:     s.Encoding,
-		Strict:       s.Strict,
+		CRS:      s.CRS,
+		Format:   s.Format,
+		Encoding: s.Encoding,
+		Geometry: s.Geometry.Clone(),
+		Strict:   s.Strict,
 	}
 	if s.Schema != nil {
-		// TODO - Fix meeeeeeee
-		// a.Schema = &Schema{
-		// 	PrimaryKey: s.Schema.PrimaryKey,
-		// 	Fields:     make([]*Field, len(s.Schema.Fields)),
-		// }
-		// for i, f := range s.Schema.Fields {
-		// 	a.Schema.Fields[i] = &Field{
-		// 		Name:         AbstractColumnName(i),
-		// 		Type:         f.Type,
-		// 		MissingValue: f.MissingValue,
-		// 		Format:       f.Format,
-		// 		Constraints:  f.Constraints,
-		// 	}
-		// }
+		a.Schema = abstractSchema(s.Schema)
 	}
 	return a
 }
 
+// AbstractHash gives the hash of the abstract form of this structure, see
+// Abstract. Structures with the same abstract hash describe the same shape
+// of data
+func (s *Structure) AbstractHash(opts ...func(*HashConfig)) (string, error) {
+	return JSONHash(s.Abstract(), opts...)
+}
+
 // Hash gives the hash of this structure
 func (s *Structure) Hash() (string, error) {
 	return JSONHash(s)
 }
 
+// Clone returns a deep copy of a structure
+func (s *Structure) Clone() *Structure {
+	if s == nil {
+		return nil
+	}
+	return &Structure{
+		Checksum:     s.Checksum,
+		Compression:  s.Compression,
+		CRS:          s.CRS,
+		Depth:        s.Depth,
+		Encoding:     s.Encoding,
+		ErrCount:     s.ErrCount,
+		Entries:      s.Entries,
+		Format:       s.Format,
+		FormatConfig: cloneMap(s.FormatConfig),
+		Geometry:     s.Geometry.Clone(),
+		Length:       s.Length,
+		Partition:    s.Partition.Clone(),
+		Path:         s.Path,
+		Qri:          s.Qri,
+		Schema:       cloneMap(s.Schema),
+		Source:       s.Source.Clone(),
+		Strict:       s.Strict,
+	}
+}
+
 // separate type for marshalling into & out of
 // most importantly, struct names must be sorted lexographically
 type _structure Structure
@@ -192,15 +229,19 @@ func (s Structure) MarshalJSONObject() ([]byte, error) {
 	return json.Marshal(&_structure{
 		Checksum:     s.Checksum,
 		Compression:  s.Compression,
+		CRS:          s.CRS,
 		Depth:        s.Depth,
 		Encoding:     s.Encoding,
 		Entries:      s.Entries,
 		ErrCount:     s.ErrCount,
 		Format:       s.Format,
 		FormatConfig: opt,
+		Geometry:     s.Geometry,
 		Length:       s.Length,
+		Partition:    s.Partition,
 		Qri:          kind,
 		Schema:       s.Schema,
+		Source:       s.Source,
 		Strict:       s.Strict,
 	})
 }
@@ -218,6 +259,9 @@ func (s *Structure) UnmarshalJSON(data []byte) (err error) {
 	if err := json.Unmarshal(data, &_s); err != nil {
 		return fmt.Errorf("error unmarshaling dataset structure from json: %s", err.Error())
 	}
+	if err := checkKind(_s.Qri); err != nil {
+		return err
+	}
 
 	*s = Structure(_s)
 	return nil
@@ -227,14 +271,18 @@ func (s *Structure) UnmarshalJSON(data []byte) (err error) {
 func (s *Structure) IsEmpty() bool {
 	return s.Checksum == "" &&
 		s.Compression == "" &&
+		s.CRS == "" &&
 		s.Depth == 0 &&
 		s.Encoding == "" &&
 		s.Entries == 0 &&
 		s.ErrCount == 0 &&
 		s.Format == "" &&
 		s.FormatConfig == nil &&
+		s.Geometry == nil &&
 		s.Length == 0 &&
+		s.Partition == nil &&
 		s.Schema == nil &&
+		s.Source == nil &&
 		!s.Strict
 }
 
@@ -255,6 +303,9 @@ func (s *Structure) Assign(structures ...*Structure) {
 		if st.Compression != "" {
 			s.Compression = st.Compression
 		}
+		if st.CRS != "" {
+			s.CRS = st.CRS
+		}
 		if st.Depth != 0 {
 			s.Depth = st.Depth
 		}
@@ -273,12 +324,18 @@ func (s *Structure) Assign(structures ...*Structure) {
 		if st.FormatConfig != nil {
 			s.FormatConfig = st.FormatConfig
 		}
+		if st.Geometry != nil {
+			s.Geometry = st.Geometry
+		}
 		if st.Qri != "" {
 			s.Qri = st.Qri
 		}
 		if st.Length != 0 {
 			s.Length = st.Length
 		}
+		if st.Partition != nil {
+			s.Partition = st.Partition
+		}
 		// TODO - fix me
 		if st.Schema != nil {
 			// if s.Schema == nil {
@@ -287,6 +344,9 @@ func (s *Structure) Assign(structures ...*Structure) {
 			// s.Schema.Assign(st.Schema)
 			s.Schema = st.Schema
 		}
+		if st.Source != nil {
+			s.Source = st.Source
+		}
 		if st.Strict {
 			s.Strict = st.Strict
 		}
@@ -317,6 +377,126 @@ func AbstractColumnName(i int) string {
 	return base26(i)
 }
 
+// schemaAnnotations are schema keywords that describe values without
+// constraining them
+var schemaAnnotations = map[string]bool{
+	"$comment":    true,
+	"description": true,
+	"examples":    true,
+	"title":       true,
+}
+
+// abstractSchema copies a schema without annotations, naming row columns
+// with AbstractColumnName
+func abstractSchema(sch map[string]interface{}) map[string]interface{} {
+	a := stripSchemaAnnotations(sch)
+	rows, ok := a["items"].(map[string]interface{})
+	if !ok {
+		return a
+	}
+	if cols, ok := rows["items"].([]interface{}); ok {
+		for i, col := range cols {
+			if c, ok := col.(map[string]interface{}); ok {
+				c["title"] = AbstractColumnName(i)
+			}
+		}
+	}
+	if props, ok := rows["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		renamed := make(map[string]interface{}, len(props))
+		abstract := make(map[string]string, len(props))
+		for i, name := range names {
+			abstract[name] = AbstractColumnName(i)
+			renamed[abstract[name]] = props[name]
+		}
+		rows["properties"] = renamed
+		// required names without a property are kept as they are
+		abstractName := func(name string) string {
+			if a, ok := abstract[name]; ok {
+				return a
+			}
+			return name
+		}
+		// required names are sorted so the order they're listed in doesn't
+		// change the abstract form
+		switch req := rows["required"].(type) {
+		case []interface{}:
+			required := make([]string, 0, len(req))
+			for _, r := range req {
+				if name, ok := r.(string); ok {
+					required = append(required, abstractName(name))
+				}
+			}
+			sort.Strings(required)
+			rows["required"] = stringsToValues(required)
+		case []string:
+			required := make([]string, len(req))
+			for i, name := range req {
+				required[i] = abstractName(name)
+			}
+			sort.Strings(required)
+			rows["required"] = stringsToValues(required)
+		}
+	}
+	return a
+}
+
+// stripSchemaAnnotations deep-copies a schema, leaving out annotation
+// keywords. Keywords whose values are maps of names to schemas are copied
+// without treating names as keywords
+func stripSchemaAnnotations(sch map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(sch))
+	for key, val := range sch {
+		if schemaAnnotations[key] {
+			continue
+		}
+		switch key {
+		case "properties", "patternProperties", "definitions", "dependencies":
+			if named, ok := val.(map[string]interface{}); ok {
+				m := make(map[string]interface{}, len(named))
+				for name, v := range named {
+					m[name] = stripSchemaValue(v)
+				}
+				c[key] = m
+				continue
+			}
+		case "enum", "const", "default", "required":
+			c[key] = cloneValue(val)
+			continue
+		}
+		c[key] = stripSchemaValue(val)
+	}
+	return c
+}
+
+// stripSchemaValue strips annotations from a keyword value that holds a
+// schema or list of schemas
+func stripSchemaValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return stripSchemaAnnotations(x)
+	case []interface{}:
+		s := make([]interface{}, len(x))
+		for i, val := range x {
+			s[i] = stripSchemaValue(val)
+		}
+		return s
+	}
+	return cloneValue(v)
+}
+
+func stringsToValues(strs []string) []interface{} {
+	vals := make([]interface{}, len(strs))
+	for i, s := range strs {
+		vals[i] = s
+	}
+	return vals
+}
+
 // b26chars is a-z, lowercase
 const b26chars = "abcdefghijklmnopqrstuvwxyz"
 
@@ -0,0 +1,36 @@
+package dataset
+
+// SourceFormat records the format a body was given in when it's stored in a
+// different format, so it can be exported the way it was received. See
+// Structure.Source
+type SourceFormat struct {
+	// Encoding of the source body
+	Encoding string `json:"encoding,omitempty"`
+	// Format of the source body
+	Format string `json:"format"`
+	// FormatConfig of the source body
+	FormatConfig map[string]interface{} `json:"formatConfig,omitempty"`
+}
+
+// IsEmpty checks to see if a source format has no values set
+func (s *SourceFormat) IsEmpty() bool {
+	return s.Encoding == "" && s.Format == "" && s.FormatConfig == nil
+}
+
+// DataFormat gives the source format as a DataFormat
+func (s *SourceFormat) DataFormat() DataFormat {
+	df, _ := ParseDataFormatString(s.Format)
+	return df
+}
+
+// Clone returns a deep copy of a source format
+func (s *SourceFormat) Clone() *SourceFormat {
+	if s == nil {
+		return nil
+	}
+	return &SourceFormat{
+		Encoding:     s.Encoding,
+		Format:       s.Format,
+		FormatConfig: cloneMap(s.FormatConfig),
+	}
+}
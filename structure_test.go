@@ -104,12 +104,87 @@ func TestStructureAbstract(t *testing.T) {
 	}
 }
 
+func TestStructureAbstractSchema(t *testing.T) {
+	st := &Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type":        "array",
+			"description": "people",
+			"items": map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"name", "id"},
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{"type": "string", "description": "honorific"},
+					"name":  map[string]interface{}{"type": "string", "title": "Name"},
+					"id":    map[string]interface{}{"type": "integer", "enum": []interface{}{1, 2}},
+				},
+			},
+		},
+	}
+	expect := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"a", "b"},
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"type": "integer", "enum": []interface{}{1, 2}},
+				"b": map[string]interface{}{"type": "string"},
+				"c": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expect, st.Abstract().Schema); diff != "" {
+		t.Errorf("abstract schema mismatch (-want +got):\n%s", diff)
+	}
+	if st.Schema["description"] != "people" {
+		t.Errorf("expected Abstract not to modify the structure schema")
+	}
+}
+
+func TestStructureAbstractHash(t *testing.T) {
+	renamed := AirportCodesStructure.Clone()
+	renamed.FormatConfig = map[string]interface{}{"headerRow": false, "lazyQuotes": true}
+	renamed.Entries = 10
+	renamed.Schema["items"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["title"] = "identifier"
+
+	retyped := AirportCodesStructure.Clone()
+	retyped.Schema["items"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["type"] = "integer"
+
+	reformatted := AirportCodesStructure.Clone()
+	reformatted.Format = "json"
+
+	a, err := AirportCodesStructure.AbstractHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		description string
+		st          *Structure
+		same        bool
+	}{
+		{"renamed columns & new format config", renamed, true},
+		{"changed column type", retyped, false},
+		{"changed format", reformatted, false},
+	}
+	for _, c := range cases {
+		b, err := c.st.AbstractHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (a == b) != c.same {
+			t.Errorf("case '%s': expected equal hashes: %t. %s, %s", c.description, c.same, a, b)
+		}
+	}
+}
+
 func TestStructureIsEmpty(t *testing.T) {
 	cases := []struct {
 		st *Structure
 	}{
 		{&Structure{Checksum: "a"}},
 		{&Structure{Compression: compression.Tar.String()}},
+		{&Structure{CRS: "EPSG:4326"}},
+		{&Structure{Geometry: &GeometryRules{X: "lon", Y: "lat"}}},
 		{&Structure{Depth: 1}},
 		{&Structure{Encoding: "a"}},
 		{&Structure{Entries: 1}},
@@ -117,7 +192,9 @@ func TestStructureIsEmpty(t *testing.T) {
 		{&Structure{Format: "csv"}},
 		{&Structure{FormatConfig: map[string]interface{}{}}},
 		{&Structure{Length: 1}},
+		{&Structure{Partition: &Partition{Column: "date"}}},
 		{&Structure{Schema: map[string]interface{}{}}},
+		{&Structure{Source: &SourceFormat{Format: "csv"}}},
 		{&Structure{Strict: true}},
 	}
 
@@ -134,6 +211,7 @@ func TestStructureAssign(t *testing.T) {
 		Length:      2503,
 		Checksum:    "hey",
 		Compression: compression.Gzip.String(),
+		CRS:         "EPSG:25832",
 		Depth:       11,
 		ErrCount:    12,
 		Encoding:    "UTF-8",
@@ -150,6 +228,7 @@ func TestStructureAssign(t *testing.T) {
 		Length:      2503,
 		Checksum:    "hey",
 		Compression: compression.Gzip.String(),
+		CRS:         "EPSG:25832",
 		Depth:       11,
 		ErrCount:    12,
 		Encoding:    "UTF-8",
@@ -0,0 +1,209 @@
+package dataset
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/qri-io/dataset/tabular"
+)
+
+// UnionStructures computes the most general structure of a tabular dataset
+// across versions, so bodies written before & after schema changes can be
+// read as one table. structures should be given oldest first. The union has
+// every column of every version in order of first appearance. Column types
+// are the union of types across versions with "integer" widened to "number"
+// when both appear, and columns missing from any version are nullable.
+// Columns no version declares a type for stay untyped. Validation keywords
+// are kept only when all versions agree & they still apply to the union
+// types. Fields other than the schema come from the last structure. nil
+// structures are skipped
+func UnionStructures(structures ...*Structure) (*Structure, error) {
+	type unionCol struct {
+		title       string
+		types       []string
+		first       []string
+		description string
+		validation  map[string]interface{}
+		conflict    bool
+		versions    int
+	}
+	var (
+		cols    []*unionCol
+		byTitle = map[string]*unionCol{}
+		last    *Structure
+		n       int
+	)
+
+	for i, st := range structures {
+		if st == nil {
+			continue
+		}
+		n++
+		last = st
+		tcols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("structure %d: %w", i, err)
+		}
+		for _, tc := range tcols {
+			c, ok := byTitle[tc.Title]
+			if !ok {
+				c = &unionCol{title: tc.Title, validation: tc.Validation}
+				if tc.Type != nil {
+					c.first = widenTypes(*tc.Type)
+				}
+				byTitle[tc.Title] = c
+				cols = append(cols, c)
+			} else if !c.conflict && !reflect.DeepEqual(c.validation, tc.Validation) {
+				c.conflict = true
+				c.validation = nil
+			}
+			c.versions++
+			if tc.Type != nil {
+				for _, t := range *tc.Type {
+					c.types = appendType(c.types, t)
+				}
+			}
+			if tc.Description != "" {
+				c.description = tc.Description
+			}
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("at least one structure is required")
+	}
+
+	items := make([]interface{}, len(cols))
+	for i, c := range cols {
+		types := widenTypes(c.types)
+		if c.versions < n && len(types) > 0 {
+			types = appendType(types, "null")
+		}
+		col := map[string]interface{}{}
+		for key, val := range c.validation {
+			if keywordApplies(key, types, c.first) {
+				col[key] = val
+			}
+		}
+		col["title"] = c.title
+		if len(types) > 0 {
+			col["type"] = typeValue(types)
+		}
+		if c.description != "" {
+			col["description"] = c.description
+		}
+		items[i] = col
+	}
+
+	union := last.Clone()
+	union.Schema = map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		},
+	}
+	return union, nil
+}
+
+func appendType(types []string, t string) []string {
+	for _, s := range types {
+		if s == t {
+			return types
+		}
+	}
+	return append(types, t)
+}
+
+// widenTypes drops "integer" when "number" is present & moves "null" last
+func widenTypes(types []string) []string {
+	number, null := false, false
+	for _, t := range types {
+		number = number || t == "number"
+		null = null || t == "null"
+	}
+	widened := make([]string, 0, len(types))
+	for _, t := range types {
+		if t == "null" || t == "integer" && number {
+			continue
+		}
+		widened = append(widened, t)
+	}
+	if null {
+		widened = append(widened, "null")
+	}
+	return widened
+}
+
+// typedKeywords are validation keywords that only constrain values of some
+// types
+var typedKeywords = map[string][]string{
+	"format":           {"string"},
+	"pattern":          {"string"},
+	"minLength":        {"string"},
+	"maxLength":        {"string"},
+	"minimum":          {"integer", "number"},
+	"maximum":          {"integer", "number"},
+	"exclusiveMinimum": {"integer", "number"},
+	"exclusiveMaximum": {"integer", "number"},
+	"multipleOf":       {"integer", "number"},
+	"items":            {"array"},
+	"minItems":         {"array"},
+	"maxItems":         {"array"},
+	"uniqueItems":      {"array"},
+	"properties":       {"object"},
+	"required":         {"object"},
+	"minProperties":    {"object"},
+	"maxProperties":    {"object"},
+}
+
+// keywordApplies checks a validation keyword agreed on by every version still
+// matches the union types of a column. Typed keywords require every non-null
+// union type to be one they apply to. enum & const list values of the first
+// version types, and only apply while the union types are unchanged
+func keywordApplies(key string, types, first []string) bool {
+	switch key {
+	case "enum", "const":
+		if len(types) != len(first) {
+			return false
+		}
+		for i, t := range types {
+			if first[i] != t {
+				return false
+			}
+		}
+		return true
+	}
+	applies, ok := typedKeywords[key]
+	if !ok {
+		return true
+	}
+	for _, t := range types {
+		if t != "null" && !containsString(applies, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// typeValue gives a JSON schema type: a string for a single type, an array of
+// strings otherwise
+func typeValue(types []string) interface{} {
+	switch len(types) {
+	case 1:
+		return types[0]
+	}
+	v := make([]interface{}, len(types))
+	for i, t := range types {
+		v[i] = t
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func columnsStructure(format string, cols ...map[string]interface{}) *Structure {
+	items := make([]interface{}, len(cols))
+	for i, c := range cols {
+		items[i] = c
+	}
+	return &Structure{
+		Format: format,
+		Schema: map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": items},
+		},
+	}
+}
+
+func TestUnionStructures(t *testing.T) {
+	v1 := columnsStructure("csv",
+		map[string]interface{}{"title": "stop_id", "type": "string"},
+		map[string]interface{}{"title": "capacity", "type": "integer", "minimum": 0},
+		map[string]interface{}{"title": "zone", "type": "integer"},
+	)
+	v2 := columnsStructure("csv",
+		map[string]interface{}{"title": "stop_id", "type": "string", "description": "stop identifier"},
+		map[string]interface{}{"title": "capacity", "type": "number", "minimum": 0},
+		map[string]interface{}{"title": "wheelchair", "type": []interface{}{"boolean", "null"}},
+		map[string]interface{}{"title": "zone", "type": "string", "maxLength": 3},
+	)
+	v2.FormatConfig = map[string]interface{}{"headerRow": true}
+
+	union, err := UnionStructures(v1, nil, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(union.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"items":{"items":[{"description":"stop identifier","title":"stop_id","type":"string"},{"minimum":0,"title":"capacity","type":"number"},{"title":"zone","type":["integer","string"]},{"title":"wheelchair","type":["boolean","null"]}],"type":"array"},"type":"array"}`
+	if string(data) != expect {
+		t.Errorf("schema mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+	if union.FormatConfig["headerRow"] != true {
+		t.Errorf("expected fields other than schema from the last structure")
+	}
+	if v2.Schema["items"].(map[string]interface{})["items"].([]interface{})[2].(map[string]interface{})["title"] != "wheelchair" {
+		t.Errorf("expected input schemas to be unmodified")
+	}
+
+	removed := columnsStructure("csv", map[string]interface{}{"title": "stop_id", "type": "string"})
+	union, err = UnionStructures(v1, removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(union.Schema["items"].(map[string]interface{})["items"])
+	expect = `[{"title":"stop_id","type":"string"},{"minimum":0,"title":"capacity","type":["integer","null"]},{"title":"zone","type":["integer","null"]}]`
+	if string(data) != expect {
+		t.Errorf("removed column schema mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+}
+
+func TestUnionStructuresKeywords(t *testing.T) {
+	v1 := columnsStructure("csv",
+		map[string]interface{}{"title": "zone", "type": "string", "enum": []interface{}{"A", "B"}, "maxLength": 1},
+		map[string]interface{}{"title": "opened", "type": "string", "format": "date"},
+		map[string]interface{}{"title": "level", "type": "integer", "enum": []interface{}{1, 2}, "minimum": 0},
+		map[string]interface{}{"title": "notes", "type": []interface{}{}},
+	)
+	v2 := columnsStructure("csv",
+		map[string]interface{}{"title": "zone", "type": "string", "enum": []interface{}{"A", "B"}, "maxLength": 1},
+		map[string]interface{}{"title": "opened", "type": []interface{}{"string", "integer"}, "format": "date"},
+		map[string]interface{}{"title": "level", "type": "integer", "enum": []interface{}{1, 2}, "minimum": 0},
+	)
+	v3 := columnsStructure("csv",
+		map[string]interface{}{"title": "zone", "type": "string", "enum": []interface{}{"A", "B"}, "maxLength": 1},
+		map[string]interface{}{"title": "opened", "type": []interface{}{"string", "integer"}, "format": "date"},
+	)
+
+	union, err := UnionStructures(v1, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(union.Schema["items"].(map[string]interface{})["items"])
+	expect := `[{"enum":["A","B"],"maxLength":1,"title":"zone","type":"string"},{"title":"opened","type":["string","integer"]},{"enum":[1,2],"minimum":0,"title":"level","type":"integer"},{"title":"notes"}]`
+	if string(data) != expect {
+		t.Errorf("schema mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+
+	union, err = UnionStructures(v1, v3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(union.Schema["items"].(map[string]interface{})["items"])
+	expect = `[{"enum":["A","B"],"maxLength":1,"title":"zone","type":"string"},{"title":"opened","type":["string","integer"]},{"minimum":0,"title":"level","type":["integer","null"]},{"title":"notes"}]`
+	if string(data) != expect {
+		t.Errorf("removed column schema mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+}
+
+func TestUnionStructuresErrors(t *testing.T) {
+	cases := []struct {
+		structures []*Structure
+		err        string
+	}{
+		{nil, "at least one structure is required"},
+		{[]*Structure{nil}, "at least one structure is required"},
+		{[]*Structure{columnsStructure("csv"), {Format: "json", Schema: map[string]interface{}{"type": "string"}}}, "structure 1: invalid tabular schema: 'string' is not a valid type to describe the top level of a tablular schema"},
+	}
+	for i, c := range cases {
+		_, err := UnionStructures(c.structures...)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Template is a stored dataset document for a recurring feed, like a monthly
+// timetable export. Instantiating a template for a delivery date creates a
+// new dataset from the template components, with a name & text picked from
+// patterns with date placeholders:
+//
+//	{yyyy} four digit year       {yy} two digit year
+//	{mm}   two digit month       {dd} two digit day of the month
+//	{hh}   two digit hour        {q}  quarter, 1-4
+//	{ww}   two digit ISO week    {date} the date as yyyy-mm-dd
+//
+// Placeholders are expanded in NamePattern, CommitTitle and the meta title
+// & description. Templates are stored with dsfs.WriteTemplate
+type Template struct {
+	// CommitTitle is the commit title pattern of instantiated datasets
+	CommitTitle string `json:"commitTitle,omitempty"`
+	// Meta of instantiated datasets
+	Meta *Meta `json:"meta,omitempty"`
+	// NamePattern is the name pattern of instantiated datasets, eg.
+	// "vvs_gtfs_{yyyy}_{mm}". A pattern without placeholders names every
+	// delivery the same, making deliveries versions of one dataset
+	NamePattern string `json:"namePattern"`
+	// Path is the location of the template, transient
+	Path string `json:"path,omitempty"`
+	// Peername of the owner of instantiated datasets
+	Peername string `json:"peername,omitempty"`
+	// Qri should always be KindTemplate
+	Qri string `json:"qri,omitempty"`
+	// Readme of instantiated datasets
+	Readme *Readme `json:"readme,omitempty"`
+	// Structure of instantiated datasets
+	Structure *Structure `json:"structure,omitempty"`
+	// Transform of instantiated datasets
+	Transform *Transform `json:"transform,omitempty"`
+	// Viz of instantiated datasets
+	Viz *Viz `json:"viz,omitempty"`
+}
+
+// templatePlaceholder matches placeholders in template patterns
+var templatePlaceholder = regexp.MustCompile(`\{([a-z]*)\}`)
+
+// templateValues give the value of each placeholder for a date
+var templateValues = map[string]func(time.Time) string{
+	"yyyy": func(t time.Time) string { return fmt.Sprintf("%04d", t.Year()) },
+	"yy":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()%100) },
+	"mm":   func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) },
+	"dd":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) },
+	"hh":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) },
+	"q":    func(t time.Time) string { return strconv.Itoa((int(t.Month())-1)/3 + 1) },
+	"ww": func(t time.Time) string {
+		_, w := t.ISOWeek()
+		return fmt.Sprintf("%02d", w)
+	},
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+}
+
+// NewTemplateRef creates a template pointer with the internal path property
+// specified, and no other fields
+func NewTemplateRef(path string) *Template {
+	return &Template{Path: path}
+}
+
+// IsEmpty checks to see if a template has any fields other than the path
+func (t *Template) IsEmpty() bool {
+	return t.CommitTitle == "" &&
+		t.Meta == nil &&
+		t.NamePattern == "" &&
+		t.Peername == "" &&
+		t.Readme == nil &&
+		t.Structure == nil &&
+		t.Transform == nil &&
+		t.Viz == nil
+}
+
+// DropTransientValues removes values that cannot be recorded when the
+// template is rendered immutable, usually by storing it in a cafs. Templates
+// are stored as a single file, so component scripts are kept, but transform
+// secrets are redacted
+func (t *Template) DropTransientValues() {
+	t.Path = ""
+	if t.Transform != nil {
+		t.Transform.RedactSecrets()
+	}
+}
+
+// NewTemplate creates a template from a dataset document, dropping values
+// specific to a version: the body, commit, derived values & transient
+// values. The dataset isn't modified
+func NewTemplate(ds *Dataset, namePattern string) (*Template, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("dataset is required")
+	}
+	ds = ds.Clone()
+	ds.DropDerivedValues()
+	t := &Template{
+		Meta:        ds.Meta,
+		NamePattern: namePattern,
+		Peername:    ds.Peername,
+		Readme:      ds.Readme,
+		Structure:   ds.Structure,
+		Transform:   ds.Transform,
+		Viz:         ds.Viz,
+	}
+	if t.Structure != nil {
+		t.Structure.Entries = 0
+		t.Structure.ErrCount = 0
+		t.Structure.Length = 0
+	}
+	if t.Transform != nil {
+		t.Transform.RedactSecrets()
+	}
+	if ds.Commit != nil {
+		t.CommitTitle = ds.Commit.Title
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate checks a template is well formed: patterns use only known
+// placeholders, and the name pattern expands to a valid dataset name.
+// References are valid
+func (t *Template) Validate() error {
+	if t.Path != "" && t.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(t.Qri, KindTemplate); err != nil {
+		return err
+	}
+	if t.NamePattern == "" {
+		return fmt.Errorf("namePattern is required")
+	}
+	name, err := expandTemplate(t.NamePattern, time.Date(2006, 1, 2, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		return fmt.Errorf("namePattern: %s", err)
+	}
+	if !refNamePattern.MatchString(name) {
+		return fmt.Errorf("namePattern: invalid name '%s': names must start with a letter and contain only letters, numbers, '_' & '-'", name)
+	}
+	if _, err := expandTemplate(t.CommitTitle, time.Time{}); err != nil {
+		return fmt.Errorf("commitTitle: %s", err)
+	}
+	if t.Meta != nil {
+		if _, err := expandTemplate(t.Meta.Title, time.Time{}); err != nil {
+			return fmt.Errorf("meta.title: %s", err)
+		}
+		if _, err := expandTemplate(t.Meta.Description, time.Time{}); err != nil {
+			return fmt.Errorf("meta.description: %s", err)
+		}
+	}
+	return nil
+}
+
+// Instantiate creates the dataset for a delivery on date. Components are
+// copied, so changing the dataset doesn't change the template. Secrets that
+// were redacted in the template are left empty, to be set with
+// Transform.InjectSecrets
+func (t *Template) Instantiate(date time.Time) (*Dataset, error) {
+	if t.Path != "" && t.IsEmpty() {
+		return nil, fmt.Errorf("template %s must be loaded before it's instantiated", t.Path)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	// patterns are known to be valid, expansion can't fail
+	name, _ := expandTemplate(t.NamePattern, date)
+	ds := &Dataset{
+		Qri:       KindDataset.String(),
+		Name:      name,
+		Peername:  t.Peername,
+		Meta:      t.Meta.Clone(),
+		Readme:    t.Readme.Clone(),
+		Structure: t.Structure.Clone(),
+		Transform: t.Transform.Clone(),
+		Viz:       t.Viz.Clone(),
+	}
+	if ds.Meta != nil {
+		ds.Meta.Title, _ = expandTemplate(ds.Meta.Title, date)
+		ds.Meta.Description, _ = expandTemplate(ds.Meta.Description, date)
+	}
+	if t.CommitTitle != "" {
+		title, _ := expandTemplate(t.CommitTitle, date)
+		ds.Commit = &Commit{Title: title}
+	}
+	if ds.Transform != nil {
+		for key, val := range ds.Transform.Secrets {
+			if val == SecretRedacted {
+				ds.Transform.Secrets[key] = ""
+			}
+		}
+	}
+	return ds, nil
+}
+
+// expandTemplate replaces the placeholders of a pattern with values for a
+// date
+func expandTemplate(pattern string, date time.Time) (string, error) {
+	var err error
+	s := templatePlaceholder.ReplaceAllStringFunc(pattern, func(ph string) string {
+		value, ok := templateValues[ph[1:len(ph)-1]]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("unknown placeholder '%s'", ph)
+			}
+			return ph
+		}
+		return value(date)
+	})
+	return s, err
+}
+
+// Clone returns a deep copy of a template
+func (t *Template) Clone() *Template {
+	if t == nil {
+		return nil
+	}
+	return &Template{
+		CommitTitle: t.CommitTitle,
+		Meta:        t.Meta.Clone(),
+		NamePattern: t.NamePattern,
+		Path:        t.Path,
+		Peername:    t.Peername,
+		Qri:         t.Qri,
+		Readme:      t.Readme.Clone(),
+		Structure:   t.Structure.Clone(),
+		Transform:   t.Transform.Clone(),
+		Viz:         t.Viz.Clone(),
+	}
+}
+
+// _template is a private struct for marshaling into & out of
+type _template Template
+
+// MarshalJSON satisfies the json.Marshaler interface, setting the kind of
+// templates that don't have one. Empty templates with a path marshal to the
+// path string
+func (t Template) MarshalJSON() ([]byte, error) {
+	if t.Path != "" && t.IsEmpty() {
+		return json.Marshal(t.Path)
+	}
+	if t.Qri == "" {
+		t.Qri = KindTemplate.String()
+	}
+	return json.Marshal(_template(t))
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface, accepting either a
+// template object or a path string
+func (t *Template) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = Template{Path: s}
+		return nil
+	}
+	_t := _template{}
+	if err := json.Unmarshal(data, &_t); err != nil {
+		return fmt.Errorf("unmarshaling template: %w", err)
+	}
+	if err := checkKind(_t.Qri); err != nil {
+		return err
+	}
+	*t = Template(_t)
+	return nil
+}
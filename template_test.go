@@ -0,0 +1,155 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTemplateInstantiate(t *testing.T) {
+	tmpl := &Template{
+		CommitTitle: "delivery of {date}",
+		Meta:        &Meta{Title: "VVS timetable {yyyy}-{mm}", Description: "week {ww}, Q{q}", AccrualPeriodicity: "monthly"},
+		NamePattern: "vvs_gtfs_{yyyy}_{mm}",
+		Peername:    "mfdz",
+		Structure:   &Structure{Format: "csv", Schema: BaseSchemaArray},
+	}
+	ds, err := tmpl.Instantiate(time.Date(2026, 10, 14, 3, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Name != "vvs_gtfs_2026_10" || ds.Peername != "mfdz" || ds.Qri != KindDataset.String() {
+		t.Errorf("unexpected dataset reference: %s/%s %s", ds.Peername, ds.Name, ds.Qri)
+	}
+	if ds.Meta.Title != "VVS timetable 2026-10" || ds.Meta.Description != "week 42, Q4" || ds.Meta.AccrualPeriodicity != "monthly" {
+		t.Errorf("unexpected meta: %#v", ds.Meta)
+	}
+	if ds.Commit == nil || ds.Commit.Title != "delivery of 2026-10-14" {
+		t.Errorf("unexpected commit: %#v", ds.Commit)
+	}
+
+	// instances don't share components with the template
+	ds.Structure.Format = "json"
+	if tmpl.Structure.Format != "csv" || tmpl.Meta.Title != "VVS timetable {yyyy}-{mm}" {
+		t.Errorf("expected instantiating not to change the template")
+	}
+}
+
+func TestNewTemplate(t *testing.T) {
+	ds := &Dataset{
+		Qri:       KindDataset.String(),
+		Name:      "vvs_gtfs_2026_09",
+		Path:      "/ipfs/QmVersion",
+		Peername:  "mfdz",
+		Body:      []interface{}{},
+		Commit:    &Commit{Title: "september delivery", Timestamp: time.Now()},
+		Meta:      &Meta{Qri: KindMeta.String(), Title: "VVS timetable"},
+		Structure: &Structure{Format: "csv", Entries: 12, Length: 400, Checksum: "QmSum"},
+		Transform: &Transform{Syntax: "sql", Secrets: map[string]string{"token": "hunter2"}},
+	}
+	tmpl, err := NewTemplate(ds, "vvs_gtfs_{yyyy}_{mm}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Peername != "mfdz" || tmpl.CommitTitle != "september delivery" {
+		t.Errorf("unexpected template: %#v", tmpl)
+	}
+	if tmpl.Structure.Entries != 0 || tmpl.Structure.Length != 0 || tmpl.Structure.Checksum != "" || tmpl.Meta.Qri != "" {
+		t.Errorf("expected version specific values to be dropped, got: %#v %#v", tmpl.Structure, tmpl.Meta)
+	}
+	if tmpl.Transform.Secrets["token"] != SecretRedacted {
+		t.Errorf("expected secrets to be redacted")
+	}
+	if ds.Structure.Entries != 12 || ds.Transform.Secrets["token"] != "hunter2" {
+		t.Errorf("expected the dataset not to be modified")
+	}
+
+	data, err := json.Marshal(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Template{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Qri != KindTemplate.String() || got.NamePattern != tmpl.NamePattern || !reflect.DeepEqual(got.Structure.Format, "csv") {
+		t.Errorf("round trip mismatch: %s", data)
+	}
+	if KindTemplate.Component() != "template" {
+		t.Errorf("expected template kind component, got: %s", KindTemplate.Component())
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	cases := []struct {
+		tmpl *Template
+		err  string
+	}{
+		{&Template{NamePattern: "gtfs_{yyyy}{mm}{dd}_{hh}"}, ""},
+		{&Template{NamePattern: "gtfs"}, ""},
+		{&Template{}, "namePattern is required"},
+		{&Template{NamePattern: "gtfs_{month}"}, "namePattern: unknown placeholder '{month}'"},
+		{&Template{NamePattern: "{yyyy}_gtfs"}, "namePattern: invalid name '2006_gtfs': names must start with a letter and contain only letters, numbers, '_' & '-'"},
+		{&Template{NamePattern: "gtfs", CommitTitle: "{when}"}, "commitTitle: unknown placeholder '{when}'"},
+		{&Template{NamePattern: "gtfs", Meta: &Meta{Description: "as of {now}"}}, "meta.description: unknown placeholder '{now}'"},
+		{&Template{NamePattern: "gtfs", Qri: "ds:0"}, "invalid kind: 'ds:0'. expected type 'tp'"},
+	}
+	for i, c := range cases {
+		err := c.tmpl.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestTemplateRef(t *testing.T) {
+	ref := NewTemplateRef("/map/QmTemplate")
+	data, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"/map/QmTemplate"` {
+		t.Errorf("expected references to marshal to their path, got: %s", data)
+	}
+	got := &Template{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ref, got) {
+		t.Errorf("expected a reference, got: %#v", got)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("expected references to be valid, got: %s", err)
+	}
+	if _, err := got.Instantiate(time.Now()); err == nil || err.Error() != "template /map/QmTemplate must be loaded before it's instantiated" {
+		t.Errorf("expected instantiating a reference to fail, got: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"namePattern":1}`), got); err == nil {
+		t.Errorf("expected an invalid template to fail")
+	}
+}
+
+func TestTemplateInstantiateSecrets(t *testing.T) {
+	tmpl := &Template{
+		NamePattern: "gtfs",
+		Transform:   &Transform{Syntax: "sql", Secrets: map[string]string{"token": "hunter2"}},
+	}
+	tmpl.DropTransientValues()
+	ds, err := tmpl.Instantiate(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, ok := ds.Transform.Secrets["token"]; !ok || val != "" {
+		t.Errorf("expected redacted secrets to be declared & empty, got: %#v", ds.Transform.Secrets)
+	}
+	if tmpl.Transform.Secrets["token"] != SecretRedacted {
+		t.Errorf("expected the template to keep it's redacted secret")
+	}
+	if err := ds.Clone().Transform.InjectSecrets(nil); err == nil || err.Error() != "missing values for secrets: token" {
+		t.Errorf("expected empty secrets to be required, got: %v", err)
+	}
+	if err := ds.Transform.InjectSecrets(map[string]string{"token": "s3cret"}); err != nil {
+		t.Errorf("expected empty secrets to be injectable, got: %s", err)
+	}
+}
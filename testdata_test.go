@@ -62,31 +62,27 @@ var AirportCodesStructure = &Structure{
 
 var AirportCodesStructureAbstract = &Structure{
 	Format: "csv",
-	// FormatConfig: &CSVOptions{HeaderRow: true},
-	FormatConfig: map[string]interface{}{
-		"headerRow": true,
+	Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "a", "type": "string"},
+				map[string]interface{}{"title": "b", "type": "string"},
+				map[string]interface{}{"title": "c", "type": "string"},
+				map[string]interface{}{"title": "d", "type": "number"},
+				map[string]interface{}{"title": "e", "type": "number"},
+				map[string]interface{}{"title": "f", "type": "integer"},
+				map[string]interface{}{"title": "g", "type": "string"},
+				map[string]interface{}{"title": "h", "type": "string"},
+				map[string]interface{}{"title": "i", "type": "string"},
+				map[string]interface{}{"title": "j", "type": "string"},
+				map[string]interface{}{"title": "k", "type": "string"},
+				map[string]interface{}{"title": "l", "type": "string"},
+				map[string]interface{}{"title": "m", "type": "string"},
+			},
+		},
 	},
-	// Schema: jsonschema.Must(`{
-	// 	"type": "array",
-	// 	"items": {
-	// 		"type": "array",
-	// 		"items": [
-	// 			{ "title": "a", "type": "string"},
-	// 			{ "title": "b", "type": "string"},
-	// 			{ "title": "c", "type": "string"},
-	// 			{ "title": "d", "type": "number"},
-	// 			{ "title": "e", "type": "number"},
-	// 			{ "title": "f", "type": "integer"},
-	// 			{ "title": "g", "type": "string"},
-	// 			{ "title": "h", "type": "string"},
-	// 			{ "title": "i", "type": "string"},
-	// 			{ "title": "j", "type": "string"},
-	// 			{ "title": "k", "type": "string"},
-	// 			{ "title": "l", "type": "string"},
-	// 			{ "title": "m", "type": "string"}
-	// 		]
-	// 	}
-	// }`),
 }
 
 var ContinentCodes = &Dataset{
@@ -0,0 +1,206 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Theme is pulled from the Project Open Data Schema version 1.1, extended to
+// reference a concept in a controlled vocabulary. Themes from a controlled
+// vocabulary are identified by URI, which makes them suitable for faceting
+// across catalogs
+type Theme struct {
+	Description     string `json:"description,omitempty"`
+	DisplayName     string `json:"display_name,omitempty"`
+	ImageDisplayURL string `json:"image_display_url,omitempty"`
+	ID              string `json:"id,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Title           string `json:"title,omitempty"`
+
+	// URI identifies the theme concept, eg.
+	// "http://publications.europa.eu/resource/authority/data-theme/TRAN"
+	URI string `json:"uri,omitempty"`
+	// Label is the human-readable name of the theme
+	Label string `json:"label,omitempty"`
+	// Vocabulary is the URI of the controlled vocabulary URI belongs to
+	Vocabulary string `json:"vocabulary,omitempty"`
+}
+
+// Decode reads json.Umarshal-style data into a Theme. Plain strings are
+// treated as a theme URI or EU data theme code
+func (t *Theme) Decode(val interface{}) (err error) {
+	if s, ok := val.(string); ok {
+		*t = Theme{URI: s}
+		if th, ok := EUDataThemes.Lookup(s); ok {
+			*t = *th
+		}
+		return nil
+	}
+
+	msi, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}")
+	}
+	for _, f := range []struct {
+		key   string
+		field *string
+	}{
+		{"description", &t.Description},
+		{"display_name", &t.DisplayName},
+		{"image_display_url", &t.ImageDisplayURL},
+		{"id", &t.ID},
+		{"name", &t.Name},
+		{"title", &t.Title},
+		{"uri", &t.URI},
+		{"label", &t.Label},
+		{"vocabulary", &t.Vocabulary},
+	} {
+		if *f.field, err = strVal(msi[f.key]); err != nil {
+			return fmt.Errorf("%s: %s", f.key, err)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting either
+// a theme object or a plain string
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = Theme{}
+	return t.Decode(v)
+}
+
+// Clone returns a copy of a theme
+func (t *Theme) Clone() *Theme {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// Normalize fills in the URI, label & vocabulary of a theme that can be
+// found in one of the given vocabularies, matching on URI, then ID, then
+// Name. With no vocabularies given the EU data theme vocabulary is used.
+// Existing labels are never overwritten
+func (t *Theme) Normalize(vocabs ...*ThemeVocabulary) {
+	if len(vocabs) == 0 {
+		vocabs = []*ThemeVocabulary{EUDataThemes}
+	}
+	for _, key := range []string{t.URI, t.ID, t.Name} {
+		if key == "" {
+			continue
+		}
+		for _, v := range vocabs {
+			if th, ok := v.Lookup(key); ok {
+				t.URI = th.URI
+				t.Vocabulary = th.Vocabulary
+				if t.Label == "" {
+					t.Label = th.Label
+				}
+				return
+			}
+		}
+	}
+}
+
+// Validate checks a theme has a URI, and that themes claiming membership in
+// a known vocabulary exist in that vocabulary. The EU data theme vocabulary
+// is always known
+func (t *Theme) Validate(vocabs ...*ThemeVocabulary) error {
+	if t.URI == "" {
+		return fmt.Errorf("uri is required")
+	}
+	if err := validateURL("uri", t.URI); err != nil {
+		return err
+	}
+	if err := validateURL("vocabulary", t.Vocabulary); err != nil {
+		return err
+	}
+	if t.Vocabulary != "" && !strings.HasPrefix(t.URI, strings.TrimSuffix(t.Vocabulary, "/")+"/") {
+		return fmt.Errorf("uri '%s' is not part of vocabulary '%s'", t.URI, t.Vocabulary)
+	}
+
+	for _, v := range append([]*ThemeVocabulary{EUDataThemes}, vocabs...) {
+		if strings.HasPrefix(t.URI, v.URI+"/") && v.code(t.URI) == "" {
+			return fmt.Errorf("unknown theme '%s' in vocabulary '%s'", t.URI, v.URI)
+		}
+	}
+	return nil
+}
+
+// NormalizeThemes normalizes each of a Meta's controlled vocabulary themes.
+// see Theme.Normalize
+func (md *Meta) NormalizeThemes(vocabs ...*ThemeVocabulary) {
+	for _, t := range md.Themes {
+		if t != nil {
+			t.Normalize(vocabs...)
+		}
+	}
+}
+
+// ThemeVocabulary is a controlled vocabulary of themes, where each theme URI
+// is the vocabulary URI followed by a slash and a code
+type ThemeVocabulary struct {
+	// URI of the vocabulary, without a trailing slash
+	URI string
+	// Labels maps theme codes to human-readable labels
+	Labels map[string]string
+}
+
+// ThemeURI returns the URI for a code in the vocabulary
+func (v *ThemeVocabulary) ThemeURI(code string) string {
+	return v.URI + "/" + code
+}
+
+// code returns the canonical code for a theme code or URI, the empty string
+// if the vocabulary doesn't contain it. Codes are matched case-insensitively
+func (v *ThemeVocabulary) code(s string) string {
+	s = strings.TrimPrefix(s, v.URI+"/")
+	for code := range v.Labels {
+		if strings.EqualFold(code, s) {
+			return code
+		}
+	}
+	return ""
+}
+
+// Lookup finds a theme in the vocabulary by code or URI
+func (v *ThemeVocabulary) Lookup(s string) (*Theme, bool) {
+	code := v.code(strings.TrimSpace(s))
+	if code == "" {
+		return nil, false
+	}
+	return &Theme{
+		ID:         code,
+		URI:        v.ThemeURI(code),
+		Label:      v.Labels[code],
+		Vocabulary: v.URI,
+	}, true
+}
+
+// EUDataThemes is the EU Publications Office data theme vocabulary, used
+// for dcat:theme by DCAT-AP
+var EUDataThemes = &ThemeVocabulary{
+	URI: "http://publications.europa.eu/resource/authority/data-theme",
+	Labels: map[string]string{
+		"AGRI":      "Agriculture, fisheries, forestry and food",
+		"ECON":      "Economy and finance",
+		"EDUC":      "Education, culture and sport",
+		"ENER":      "Energy",
+		"ENVI":      "Environment",
+		"GOVE":      "Government and public sector",
+		"HEAL":      "Health",
+		"INTR":      "International issues",
+		"JUST":      "Justice, legal system and public safety",
+		"OP_DATPRO": "Provisional data",
+		"REGI":      "Regions and cities",
+		"SOCI":      "Population and society",
+		"TECH":      "Science and technology",
+		"TRAN":      "Transport",
+	},
+}
@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const euTheme = "http://publications.europa.eu/resource/authority/data-theme"
+
+func TestThemeVocabularyLookup(t *testing.T) {
+	cases := []struct {
+		in  string
+		uri string
+		ok  bool
+	}{
+		{"TRAN", euTheme + "/TRAN", true},
+		{"tran", euTheme + "/TRAN", true},
+		{euTheme + "/ENVI", euTheme + "/ENVI", true},
+		{euTheme + "/CARS", "", false},
+		{"Transport", "", false},
+	}
+
+	for i, c := range cases {
+		got, ok := EUDataThemes.Lookup(c.in)
+		if ok != c.ok {
+			t.Errorf("case %d '%s': expected ok %t, got %t", i, c.in, c.ok, ok)
+			continue
+		}
+		if ok && got.URI != c.uri {
+			t.Errorf("case %d '%s': uri mismatch. expected: '%s', got: '%s'", i, c.in, c.uri, got.URI)
+		}
+	}
+}
+
+func TestThemeNormalize(t *testing.T) {
+	custom := &ThemeVocabulary{
+		URI:    "https://mfdz.de/themes",
+		Labels: map[string]string{"gtfs": "GTFS Feeds"},
+	}
+
+	cases := []struct {
+		in     *Theme
+		vocabs []*ThemeVocabulary
+		expect *Theme
+	}{
+		{&Theme{ID: "tran"}, nil, &Theme{ID: "tran", URI: euTheme + "/TRAN", Label: "Transport", Vocabulary: euTheme}},
+		{&Theme{URI: euTheme + "/tran", Label: "Verkehr"}, nil, &Theme{URI: euTheme + "/TRAN", Label: "Verkehr", Vocabulary: euTheme}},
+		{&Theme{Name: "gtfs"}, []*ThemeVocabulary{custom}, &Theme{Name: "gtfs", URI: "https://mfdz.de/themes/gtfs", Label: "GTFS Feeds", Vocabulary: "https://mfdz.de/themes"}},
+		{&Theme{Name: "gtfs"}, nil, &Theme{Name: "gtfs"}},
+	}
+
+	for i, c := range cases {
+		c.in.Normalize(c.vocabs...)
+		if !reflect.DeepEqual(c.expect, c.in) {
+			t.Errorf("case %d mismatch. expected: %#v, got: %#v", i, c.expect, c.in)
+		}
+	}
+}
+
+func TestThemeValidate(t *testing.T) {
+	custom := &ThemeVocabulary{
+		URI:    "https://mfdz.de/themes",
+		Labels: map[string]string{"gtfs": "GTFS Feeds"},
+	}
+
+	cases := []struct {
+		theme  *Theme
+		vocabs []*ThemeVocabulary
+		err    string
+	}{
+		{&Theme{URI: euTheme + "/TRAN"}, nil, ""},
+		{&Theme{URI: "https://example.com/themes/anything"}, nil, ""},
+		{&Theme{URI: "https://mfdz.de/themes/gtfs", Vocabulary: "https://mfdz.de/themes"}, []*ThemeVocabulary{custom}, ""},
+		{&Theme{Label: "Transport"}, nil, "uri is required"},
+		{&Theme{URI: "TRAN"}, nil, "uri: 'TRAN' must be an absolute url"},
+		{&Theme{URI: euTheme + "/CARS"}, nil, "unknown theme '" + euTheme + "/CARS' in vocabulary '" + euTheme + "'"},
+		{&Theme{URI: "https://mfdz.de/themes/netex"}, []*ThemeVocabulary{custom}, "unknown theme 'https://mfdz.de/themes/netex' in vocabulary 'https://mfdz.de/themes'"},
+		{&Theme{URI: euTheme + "/TRAN", Vocabulary: "https://mfdz.de/themes"}, nil, "uri '" + euTheme + "/TRAN' is not part of vocabulary 'https://mfdz.de/themes'"},
+	}
+
+	for i, c := range cases {
+		err := c.theme.Validate(c.vocabs...)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestMetaThemesJSON(t *testing.T) {
+	md := &Meta{}
+	data := []byte(`{"qri":"md:0","theme":["Verkehr"],"themes":["TRAN",{"uri":"https://mfdz.de/themes/gtfs","label":"GTFS Feeds"}]}`)
+	if err := json.Unmarshal(data, md); err != nil {
+		t.Fatal(err)
+	}
+	expect := []*Theme{
+		{ID: "TRAN", URI: euTheme + "/TRAN", Label: "Transport", Vocabulary: euTheme},
+		{URI: "https://mfdz.de/themes/gtfs", Label: "GTFS Feeds"},
+	}
+	if !reflect.DeepEqual(expect, md.Themes) {
+		t.Errorf("themes mismatch. expected: %v, got: %v", expect, md.Themes)
+	}
+	if md.Meta()["themes"] != nil {
+		t.Errorf("themes should not be hoisted into arbitrary metadata")
+	}
+	if md.Theme[0] != "Verkehr" {
+		t.Errorf("free-text theme mismatch: %v", md.Theme)
+	}
+
+	if err := md.Set("themes", []interface{}{map[string]interface{}{"uri": 1}}); err == nil || err.Error() != "parsing themes index 0: uri: type must be a string" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
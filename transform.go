@@ -15,9 +15,31 @@ import (
 // more datasets, and the output is a single dataset
 // Ideally, transforms should contain all the machine-necessary bits to
 // deterministicly execute the algorithm referenced in "ScriptPath".
+// Transforms that span more than one file use ScriptPath as the entry point,
+// with supporting files listed in Files. Pipelines of multiple scripts are
+// described by Steps, which replace the top level script when defined
 type Transform struct {
+	// Anonymize declares column level anonymization applied to the transform
+	// output
+	Anonymize *AnonymizeSpec `json:"anonymize,omitempty"`
 	// Config outlines any configuration that would affect the resulting hash
 	Config map[string]interface{} `json:"config,omitempty"`
+	// EncryptedSecrets holds secret values encrypted with a caller-provided
+	// key, see EncryptSecrets
+	EncryptedSecrets []byte `json:"encryptedSecrets,omitempty"`
+	// Environment records versions of the runtime & libraries the transform
+	// executed with, eg. {"starlib": "v0.4.1"}
+	Environment map[string]string `json:"environment,omitempty"`
+	// Files are supporting script files for the entry point script, keyed by a
+	// slash-separated path relative to the transform module root, eg.
+	// "lib/gtfs.star"
+	Files map[string]*TransformFile `json:"files,omitempty"`
+	// NetworkAccess declares the hosts a transform may contact while executing.
+	// Transforms with network access can't be guaranteed to be reproducible
+	NetworkAccess []string `json:"networkAccess,omitempty"`
+	// Lineage records the input columns each output column is derived from,
+	// see ExtractSQLLineage
+	Lineage []*ColumnLineage `json:"lineage,omitempty"`
 	// location of the transform object, transient
 	Path string `json:"path,omitempty"`
 	// Kind should always equal KindTransform
@@ -33,9 +55,16 @@ type Transform struct {
 	ScriptBytes []byte `json:"scriptBytes,omitempty"`
 	// ScriptPath is the path to the script that produced this transformation.
 	ScriptPath string `json:"scriptPath,omitempty"`
-	// Secrets is a map of secret values used in the transformation, transient.
-	// TODO (b5): make this not-transient by censoring the values used, but not keys
+	// Secrets is a map of secret values used in the transformation. Secret
+	// values are transient: keys are recorded, values are always redacted when
+	// marshaling, see RedactSecrets & InjectSecrets
 	Secrets map[string]string `json:"secrets,omitempty"`
+	// Seed is the random number generator seed the transform executed with,
+	// nil if unset
+	Seed *int64 `json:"seed,omitempty"`
+	// Steps is an ordered pipeline of scripts, each with it's own syntax &
+	// config, executed in place of the top level script
+	Steps []*TransformStep `json:"steps,omitempty"`
 	// Syntax this transform was written in
 	Syntax string `json:"syntax,omitempty"`
 	// SyntaxVersion is an identifier for the application and version number that
@@ -47,8 +76,18 @@ type Transform struct {
 // dataset is rendered immutable, usually by storing it in a cafs
 func (q *Transform) DropTransientValues() {
 	q.Path = ""
-	q.Secrets = nil
+	q.RedactSecrets()
 	q.ScriptBytes = nil
+	for _, s := range q.Steps {
+		if s != nil {
+			s.ScriptBytes = nil
+		}
+	}
+	for _, f := range q.Files {
+		if f != nil {
+			f.ScriptBytes = nil
+		}
+	}
 }
 
 // DropDerivedValues resets all set-on-save fields to their default values
@@ -59,7 +98,7 @@ func (q *Transform) DropDerivedValues() {
 
 // InlineScriptFile opens the script file, reads its contents, and assigns it to
 // scriptBytes
-func (q* Transform) InlineScriptFile(ctx context.Context, resolver qfs.PathResolver) error {
+func (q *Transform) InlineScriptFile(ctx context.Context, resolver qfs.PathResolver) error {
 	if resolver == nil {
 		return nil
 	}
@@ -84,20 +123,9 @@ func (q* Transform) InlineScriptFile(ctx context.Context, resolver qfs.PathResol
 // in-place file from ScriptBytes when defined, fetching from the
 // passed-in resolver otherwise
 func (q *Transform) OpenScriptFile(ctx context.Context, resolver qfs.PathResolver) (err error) {
-	if q.ScriptBytes != nil {
-		q.scriptFile = qfs.NewMemfileBytes("transform.star", q.ScriptBytes)
-		return nil
+	if src := q.ScriptSource(resolver); src != nil {
+		q.scriptFile, err = src.Open(ctx)
 	}
-
-	if q.ScriptPath == "" {
-		// nothing to resolve
-		return nil
-	}
-
-	if resolver == nil {
-		return ErrNoResolver
-	}
-	q.scriptFile, err = resolver.Get(ctx, q.ScriptPath)
 	return err
 }
 
@@ -117,8 +145,34 @@ func (q *Transform) ScriptFile() qfs.File {
 // include details that specify resources other than datasets (urls?), and
 // details for interpreting the resource (eg. a selector to specify only a
 // subset of a resource is required)
+// Resources can also be HTTP(S) URLs, in which case fetch metadata recorded at
+// execution time makes the input reproducible & verifiable, see RecordFetch
+// Path may be a mutable reference like "peer/name", in which case Version pins
+// the exact version the transform reads, see Transform.RepinResources
 type TransformResource struct {
+	// ETag is the entity tag of a URL resource when it was fetched
+	ETag string `json:"etag,omitempty"`
+	// Hash is the content hash of a URL resource when it was fetched
+	Hash string `json:"hash,omitempty"`
+	// LastModified is the Last-Modified header of a URL resource when it was
+	// fetched, in HTTP date format
+	LastModified string `json:"lastModified,omitempty"`
+	// Name is the variable name a transform script uses for this resource
+	Name string `json:"name,omitempty"`
+	// Path to the resource, a dataset reference or URL
 	Path string `json:"path"`
+	// Version is the pinned, immutable path of the version of Path the
+	// transform reads, eg. "/ipfs/QmHash"
+	Version string `json:"version,omitempty"`
+}
+
+// Clone returns a copy of a transform resource
+func (r *TransformResource) Clone() *TransformResource {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
 }
 
 // private version for marshalling purposes only
@@ -150,7 +204,15 @@ func NewTransformRef(path string) *Transform {
 
 // IsEmpty checks to see if transform has any fields other than the internal path
 func (q *Transform) IsEmpty() bool {
-	return q.Config == nil &&
+	return q.Anonymize == nil &&
+		q.Config == nil &&
+		q.EncryptedSecrets == nil &&
+		q.Environment == nil &&
+		q.NetworkAccess == nil &&
+		q.Seed == nil &&
+		q.Steps == nil &&
+		q.Files == nil &&
+		q.Lineage == nil &&
 		q.Resources == nil &&
 		q.ScriptBytes == nil &&
 		q.ScriptPath == "" &&
@@ -167,6 +229,9 @@ func (q *Transform) Assign(qs ...*Transform) {
 			continue
 		}
 
+		if q2.Anonymize != nil {
+			q.Anonymize = q2.Anonymize
+		}
 		if q2.Config != nil {
 			if q.Config == nil {
 				q.Config = map[string]interface{}{}
@@ -175,6 +240,31 @@ func (q *Transform) Assign(qs ...*Transform) {
 				q.Config[key] = val
 			}
 		}
+		if q2.EncryptedSecrets != nil {
+			q.EncryptedSecrets = q2.EncryptedSecrets
+		}
+		if q2.Environment != nil {
+			if q.Environment == nil {
+				q.Environment = map[string]string{}
+			}
+			for key, val := range q2.Environment {
+				q.Environment[key] = val
+			}
+		}
+		if q2.Files != nil {
+			if q.Files == nil {
+				q.Files = map[string]*TransformFile{}
+			}
+			for key, val := range q2.Files {
+				q.Files[key] = val
+			}
+		}
+		if q2.Lineage != nil {
+			q.Lineage = q2.Lineage
+		}
+		if q2.NetworkAccess != nil {
+			q.NetworkAccess = q2.NetworkAccess
+		}
 		if q2.Path != "" {
 			q.Path = q2.Path
 		}
@@ -203,9 +293,19 @@ func (q *Transform) Assign(qs ...*Transform) {
 				q.Secrets = map[string]string{}
 			}
 			for key, val := range q2.Secrets {
+				// redacted values never overwrite known secrets
+				if _, ok := q.Secrets[key]; ok && val == SecretRedacted {
+					continue
+				}
 				q.Secrets[key] = val
 			}
 		}
+		if q2.Seed != nil {
+			q.Seed = q2.Seed
+		}
+		if q2.Steps != nil {
+			q.assignSteps(q2.Steps)
+		}
 		if q2.Syntax != "" {
 			q.Syntax = q2.Syntax
 		}
@@ -215,6 +315,61 @@ func (q *Transform) Assign(qs ...*Transform) {
 	}
 }
 
+// Clone returns a deep copy of a transform. The script file is not copied
+func (q *Transform) Clone() *Transform {
+	if q == nil {
+		return nil
+	}
+	c := &Transform{
+		Anonymize:        q.Anonymize.Clone(),
+		Config:           cloneMap(q.Config),
+		EncryptedSecrets: cloneBytes(q.EncryptedSecrets),
+		Lineage:          cloneLineage(q.Lineage),
+		NetworkAccess:    cloneStrings(q.NetworkAccess),
+		Path:             q.Path,
+		Qri:              q.Qri,
+		ScriptBytes:      cloneBytes(q.ScriptBytes),
+		ScriptPath:       q.ScriptPath,
+		Syntax:           q.Syntax,
+		SyntaxVersion:    q.SyntaxVersion,
+	}
+	if q.Resources != nil {
+		c.Resources = make(map[string]*TransformResource, len(q.Resources))
+		for key, r := range q.Resources {
+			c.Resources[key] = r.Clone()
+		}
+	}
+	if q.Environment != nil {
+		c.Environment = make(map[string]string, len(q.Environment))
+		for key, val := range q.Environment {
+			c.Environment[key] = val
+		}
+	}
+	if q.Steps != nil {
+		c.Steps = make([]*TransformStep, len(q.Steps))
+		for i, s := range q.Steps {
+			c.Steps[i] = s.Clone()
+		}
+	}
+	if q.Seed != nil {
+		seed := *q.Seed
+		c.Seed = &seed
+	}
+	if q.Files != nil {
+		c.Files = make(map[string]*TransformFile, len(q.Files))
+		for key, f := range q.Files {
+			c.Files[key] = f.Clone()
+		}
+	}
+	if q.Secrets != nil {
+		c.Secrets = make(map[string]string, len(q.Secrets))
+		for key, val := range q.Secrets {
+			c.Secrets[key] = val
+		}
+	}
+	return c
+}
+
 // _transform is a private struct for marshaling into & out of.
 // fields must remain sorted in lexographical order
 type _transform Transform
@@ -236,14 +391,23 @@ func (q Transform) MarshalJSONObject() ([]byte, error) {
 	}
 
 	return json.Marshal(&_transform{
-		Config:        q.Config,
-		Path:          q.Path,
-		Qri:           kind,
-		Resources:     q.Resources,
-		ScriptBytes:   q.ScriptBytes,
-		ScriptPath:    q.ScriptPath,
-		Syntax:        q.Syntax,
-		SyntaxVersion: q.SyntaxVersion,
+		Anonymize:        q.Anonymize,
+		Config:           q.Config,
+		EncryptedSecrets: q.EncryptedSecrets,
+		Environment:      q.Environment,
+		Files:            q.Files,
+		Lineage:          q.Lineage,
+		NetworkAccess:    q.NetworkAccess,
+		Path:             q.Path,
+		Qri:              kind,
+		Resources:        q.Resources,
+		ScriptBytes:      q.ScriptBytes,
+		ScriptPath:       q.ScriptPath,
+		Secrets:          redactedSecrets(q.Secrets),
+		Seed:             q.Seed,
+		Steps:            q.Steps,
+		Syntax:           q.Syntax,
+		SyntaxVersion:    q.SyntaxVersion,
 	})
 }
 
@@ -259,8 +423,18 @@ func (q *Transform) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &_q); err != nil {
 		return err
 	}
+	if err := checkKind(_q.Qri); err != nil {
+		return err
+	}
 
 	*q = Transform(_q)
+	script, err := decodeInlineScript(data)
+	if err != nil {
+		return err
+	}
+	if script != nil {
+		q.ScriptBytes = script
+	}
 	return nil
 }
 
@@ -0,0 +1,89 @@
+package dataset
+
+import "fmt"
+
+// Anonymization methods
+const (
+	// AnonymizeHash replaces values with a hex-encoded SHA-256 hash, keyed
+	// with a salt secret if one is given
+	AnonymizeHash = "hash"
+	// AnonymizeMask replaces all but the last Keep characters of values with
+	// "*"
+	AnonymizeMask = "mask"
+	// AnonymizeTruncate cuts numbers to Keep decimal places & strings to
+	// their first Keep characters
+	AnonymizeTruncate = "truncate"
+)
+
+// AnonymizeSpec declares the column level anonymization a transform applies
+// to its output, recording how personal data was removed from a dataset
+type AnonymizeSpec struct {
+	// Columns are anonymization rules applied in order
+	Columns []*AnonymizeColumn `json:"columns,omitempty"`
+	// K requires every combination of QuasiIdentifiers values in the output
+	// to occur at least K times. zero skips the check
+	K int `json:"k,omitempty"`
+	// QuasiIdentifiers are columns that together could identify a person
+	QuasiIdentifiers []string `json:"quasiIdentifiers,omitempty"`
+}
+
+// AnonymizeColumn is a single column anonymization rule
+type AnonymizeColumn struct {
+	// Column is the title or key of the column to anonymize
+	Column string `json:"column"`
+	// Keep parameterizes mask & truncate methods
+	Keep int `json:"keep,omitempty"`
+	// Method is one of "hash", "mask" or "truncate"
+	Method string `json:"method"`
+	// Salt names a transform secret that keys hashes. Unkeyed hashes of
+	// guessable values like phone numbers are easily reversed
+	Salt string `json:"salt,omitempty"`
+}
+
+// Clone returns a deep copy of an anonymize spec
+func (s *AnonymizeSpec) Clone() *AnonymizeSpec {
+	if s == nil {
+		return nil
+	}
+	c := &AnonymizeSpec{
+		K:                s.K,
+		QuasiIdentifiers: cloneStrings(s.QuasiIdentifiers),
+	}
+	if s.Columns != nil {
+		c.Columns = make([]*AnonymizeColumn, len(s.Columns))
+		for i, col := range s.Columns {
+			if col != nil {
+				cc := *col
+				c.Columns[i] = &cc
+			}
+		}
+	}
+	return c
+}
+
+// Validate checks an anonymize spec is well formed
+func (s *AnonymizeSpec) Validate() error {
+	for i, c := range s.Columns {
+		if c == nil || c.Column == "" {
+			return fmt.Errorf("column %d: column is required", i)
+		}
+		switch c.Method {
+		case AnonymizeHash, AnonymizeMask, AnonymizeTruncate:
+		default:
+			return fmt.Errorf("column '%s': unknown method '%s'", c.Column, c.Method)
+		}
+		if c.Keep < 0 {
+			return fmt.Errorf("column '%s': keep must not be negative", c.Column)
+		}
+		if c.Salt != "" && c.Method != AnonymizeHash {
+			return fmt.Errorf("column '%s': salt only applies to the hash method", c.Column)
+		}
+	}
+	if s.K < 0 {
+		return fmt.Errorf("k must not be negative")
+	}
+	if s.K > 0 && len(s.QuasiIdentifiers) == 0 {
+		return fmt.Errorf("k-anonymity requires quasiIdentifiers")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAnonymizeSpecValidate(t *testing.T) {
+	cases := []struct {
+		spec *AnonymizeSpec
+		err  string
+	}{
+		{&AnonymizeSpec{}, ""},
+		{&AnonymizeSpec{
+			Columns: []*AnonymizeColumn{
+				{Column: "email", Method: AnonymizeHash, Salt: "salt"},
+				{Column: "phone", Method: AnonymizeMask, Keep: 2},
+				{Column: "lat", Method: AnonymizeTruncate, Keep: 3},
+			},
+			K:                5,
+			QuasiIdentifiers: []string{"lat"},
+		}, ""},
+		{&AnonymizeSpec{Columns: []*AnonymizeColumn{nil}}, "column 0: column is required"},
+		{&AnonymizeSpec{Columns: []*AnonymizeColumn{{Method: AnonymizeHash}}}, "column 0: column is required"},
+		{&AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "a", Method: "shuffle"}}}, "column 'a': unknown method 'shuffle'"},
+		{&AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "a", Method: AnonymizeMask, Keep: -1}}}, "column 'a': keep must not be negative"},
+		{&AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "a", Method: AnonymizeMask, Salt: "salt"}}}, "column 'a': salt only applies to the hash method"},
+		{&AnonymizeSpec{K: -1}, "k must not be negative"},
+		{&AnonymizeSpec{K: 2}, "k-anonymity requires quasiIdentifiers"},
+	}
+	for i, c := range cases {
+		err := c.spec.Validate()
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestAnonymizeSpecClone(t *testing.T) {
+	var nilSpec *AnonymizeSpec
+	if nilSpec.Clone() != nil {
+		t.Error("expected clone of nil spec to be nil")
+	}
+
+	spec := &AnonymizeSpec{
+		Columns:          []*AnonymizeColumn{{Column: "email", Method: AnonymizeHash, Salt: "salt"}},
+		K:                3,
+		QuasiIdentifiers: []string{"zip"},
+	}
+	c := spec.Clone()
+	if !reflect.DeepEqual(spec, c) {
+		t.Errorf("clone mismatch. expected: %v, got: %v", spec, c)
+	}
+	c.Columns[0].Column = "changed"
+	c.QuasiIdentifiers[0] = "changed"
+	if spec.Columns[0].Column != "email" || spec.QuasiIdentifiers[0] != "zip" {
+		t.Error("expected clone to be a deep copy")
+	}
+}
+
+func TestTransformAnonymizeJSON(t *testing.T) {
+	tf := &Transform{Anonymize: &AnonymizeSpec{
+		Columns: []*AnonymizeColumn{{Column: "lat", Method: AnonymizeTruncate, Keep: 3}},
+	}}
+	data, err := json.Marshal(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"anonymize":{"columns":[{"column":"lat","keep":3,"method":"truncate"}]},"qri":"tf:0"}`
+	if string(data) != expect {
+		t.Errorf("json mismatch.\nexpected: %s\ngot:      %s", expect, data)
+	}
+
+	got := &Transform{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tf.Anonymize, got.Anonymize) {
+		t.Errorf("round trip mismatch. expected: %v, got: %v", tf.Anonymize, got.Anonymize)
+	}
+}
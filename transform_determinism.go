@@ -0,0 +1,123 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// cacheKeyInputs are the values that determine the output of a deterministic
+// transform. fields must remain sorted in lexographical order. An unset
+// anonymize spec is omitted, so keys of transforms without one are unchanged
+type cacheKeyInputs struct {
+	Anonymize     *AnonymizeSpec         `json:"anonymize,omitempty"`
+	Config        map[string]interface{} `json:"config"`
+	Environment   map[string]string      `json:"environment"`
+	Files         map[string]string      `json:"files"`
+	NetworkAccess []string               `json:"networkAccess"`
+	Resources     map[string]string      `json:"resources"`
+	Script        string                 `json:"script"`
+	Seed          *int64                 `json:"seed"`
+	Steps         []cacheKeyStep         `json:"steps"`
+	Syntax        string                 `json:"syntax"`
+	SyntaxVersion string                 `json:"syntaxVersion"`
+}
+
+// cacheKeyStep identifies a pipeline step in a cache key
+type cacheKeyStep struct {
+	Config map[string]interface{} `json:"config"`
+	Name   string                 `json:"name"`
+	Script string                 `json:"script"`
+	Syntax string                 `json:"syntax"`
+}
+
+// IsReproducible checks if a transform declares everything needed to
+// reproduce it's output: no network access, and a recorded syntax version.
+// Reproducible transforms can safely be cached by CacheKey
+func (q *Transform) IsReproducible() bool {
+	return len(q.NetworkAccess) == 0 && q.SyntaxVersion != ""
+}
+
+// CacheKey computes a reproducibility cache key for a transform from the
+// hash of it's scripts, config, resource hashes & determinism metadata. Two
+// transforms with the same cache key are expected to produce the same output.
+// Scripts are identified by the hash of ScriptBytes when present, by
+// ScriptPath otherwise. Resources are identified by their recorded hash when
+// present, their pinned version or path otherwise, which is expected to be
+// content-addressed;
+// resourceHashes, keyed by resource name, overrides the path of a resource
+// when a resource hash is known by some other means. The anonymize spec is
+// part of the key, so anonymized & plain outputs are never confused. Secrets
+// are never part of a cache key, anonymize salts are keyed by secret name
+func (q *Transform) CacheKey(resourceHashes map[string]string) (string, error) {
+	script, err := scriptIdentity(q.ScriptBytes, q.ScriptPath)
+	if err != nil {
+		return "", err
+	}
+	if script == "" && len(q.Steps) == 0 {
+		return "", fmt.Errorf("transform script is required")
+	}
+
+	in := cacheKeyInputs{
+		Anonymize:     q.Anonymize,
+		Config:        q.Config,
+		Environment:   q.Environment,
+		NetworkAccess: q.NetworkAccess,
+		Script:        script,
+		Seed:          q.Seed,
+		Syntax:        q.Syntax,
+		SyntaxVersion: q.SyntaxVersion,
+	}
+	if q.Files != nil {
+		in.Files = make(map[string]string, len(q.Files))
+		for name, f := range q.Files {
+			if f == nil {
+				continue
+			}
+			if in.Files[name], err = scriptIdentity(f.ScriptBytes, f.Path); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, s := range q.Steps {
+		if s == nil {
+			continue
+		}
+		step := cacheKeyStep{Config: s.Config, Name: s.Name, Syntax: s.Syntax}
+		if step.Script, err = scriptIdentity(s.ScriptBytes, s.ScriptPath); err != nil {
+			return "", err
+		}
+		in.Steps = append(in.Steps, step)
+	}
+	if q.Resources != nil || resourceHashes != nil {
+		in.Resources = map[string]string{}
+		for name, r := range q.Resources {
+			if r == nil {
+				continue
+			}
+			// URL resources aren't content-addressed, prefer a recorded hash
+			if r.Hash != "" {
+				in.Resources[name] = r.Hash
+			} else {
+				in.Resources[name] = r.ResolvedPath()
+			}
+		}
+		for name, hash := range resourceHashes {
+			in.Resources[name] = hash
+		}
+	}
+
+	// json.Marshal sorts map keys, giving a canonical encoding
+	data, err := json.Marshal(in)
+	if err != nil {
+		return "", fmt.Errorf("encoding cache key: %s", err)
+	}
+	return HashBytes(data)
+}
+
+// scriptIdentity gives the hash of script bytes if defined, path otherwise
+func scriptIdentity(data []byte, path string) (string, error) {
+	if data != nil {
+		return HashBytes(data)
+	}
+	return path, nil
+}
@@ -0,0 +1,115 @@
+package dataset
+
+import (
+	"testing"
+)
+
+func TestTransformCacheKey(t *testing.T) {
+	seed := int64(42)
+	base := func() *Transform {
+		return &Transform{
+			Config:        map[string]interface{}{"region": "bw"},
+			Environment:   map[string]string{"starlib": "v0.4.1"},
+			Resources:     map[string]*TransformResource{"stops": {Path: "/ipfs/QmStops"}},
+			ScriptBytes:   []byte("def transform(ds, ctx): pass"),
+			Secrets:       map[string]string{"token": "a"},
+			Seed:          &seed,
+			Syntax:        "starlark",
+			SyntaxVersion: "0.9.0",
+		}
+	}
+
+	key, err := base().CacheKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same := base()
+	same.Secrets["token"] = "b"
+	same.Path = "/ipfs/QmOther"
+	if got, _ := same.CacheKey(nil); got != key {
+		t.Errorf("expected secrets & path not to affect cache key")
+	}
+
+	otherSeed := int64(7)
+	cases := []struct {
+		description string
+		modify      func(q *Transform)
+		hashes      map[string]string
+	}{
+		{"config", func(q *Transform) { q.Config["region"] = "by" }, nil},
+		{"environment", func(q *Transform) { q.Environment["starlib"] = "v0.5.0" }, nil},
+		{"script", func(q *Transform) { q.ScriptBytes = []byte("def transform(ds, ctx): ds.set_body([])") }, nil},
+		{"seed", func(q *Transform) { q.Seed = &otherSeed }, nil},
+		{"no seed", func(q *Transform) { q.Seed = nil }, nil},
+		{"resource", func(q *Transform) { q.Resources["stops"].Path = "/ipfs/QmStops2" }, nil},
+		{"resource hash", func(q *Transform) {}, map[string]string{"stops": "QmStopsContent"}},
+		{"files", func(q *Transform) { q.Files = map[string]*TransformFile{"lib.star": {Path: "/ipfs/QmLib"}} }, nil},
+		{"network", func(q *Transform) { q.NetworkAccess = []string{"api.example.com"} }, nil},
+		{"anonymize", func(q *Transform) {
+			q.Anonymize = &AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "phone", Method: "hash"}}}
+		}, nil},
+	}
+
+	for _, c := range cases {
+		q := base()
+		c.modify(q)
+		got, err := q.CacheKey(c.hashes)
+		if err != nil {
+			t.Errorf("case '%s' unexpected error: %s", c.description, err)
+			continue
+		}
+		if got == key {
+			t.Errorf("case '%s': expected cache key to change", c.description)
+		}
+	}
+
+	if _, err := (&Transform{}).CacheKey(nil); err == nil {
+		t.Errorf("expected transform without a script to error")
+	}
+
+	anonymized := func() *Transform {
+		q := base()
+		q.Anonymize = &AnonymizeSpec{
+			Columns:          []*AnonymizeColumn{{Column: "phone", Method: "hash", Salt: "salt"}},
+			K:                5,
+			QuasiIdentifiers: []string{"zip"},
+		}
+		return q
+	}
+	anonKey, err := anonymized().CacheKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := map[string]func(s *AnonymizeSpec){
+		"column":           func(s *AnonymizeSpec) { s.Columns[0].Column = "email" },
+		"method":           func(s *AnonymizeSpec) { s.Columns[0].Method = "mask" },
+		"salt":             func(s *AnonymizeSpec) { s.Columns[0].Salt = "other_salt" },
+		"k":                func(s *AnonymizeSpec) { s.K = 10 },
+		"quasi-identifier": func(s *AnonymizeSpec) { s.QuasiIdentifiers = []string{"zip", "age"} },
+	}
+	for name, modify := range specs {
+		q := anonymized()
+		modify(q.Anonymize)
+		if got, _ := q.CacheKey(nil); got == anonKey {
+			t.Errorf("anonymize %s: expected cache key to change", name)
+		}
+	}
+	q := anonymized()
+	q.Secrets = map[string]string{"salt": "s3cret"}
+	if got, _ := q.CacheKey(nil); got != anonKey {
+		t.Errorf("expected salt secret values not to affect the cache key")
+	}
+}
+
+func TestTransformIsReproducible(t *testing.T) {
+	if (&Transform{}).IsReproducible() {
+		t.Errorf("expected transform without a syntax version not to be reproducible")
+	}
+	if !(&Transform{SyntaxVersion: "0.9.0"}).IsReproducible() {
+		t.Errorf("expected transform to be reproducible")
+	}
+	if (&Transform{SyntaxVersion: "0.9.0", NetworkAccess: []string{"example.com"}}).IsReproducible() {
+		t.Errorf("expected transform with network access not to be reproducible")
+	}
+}
@@ -0,0 +1,163 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/qfs"
+)
+
+// TransformFile is a supporting script file in a multi-file transform, like
+// a module imported by the entry point script
+type TransformFile struct {
+	// Path is the location the file is stored at
+	Path string `json:"path,omitempty"`
+	// ScriptBytes is for representing a file as a slice of bytes, transient
+	ScriptBytes []byte `json:"scriptBytes,omitempty"`
+
+	// file reader, doesn't serialize
+	file qfs.File
+}
+
+// Clone returns a copy of a transform file. The open file is not copied
+func (f *TransformFile) Clone() *TransformFile {
+	if f == nil {
+		return nil
+	}
+	return &TransformFile{
+		Path:        f.Path,
+		ScriptBytes: cloneBytes(f.ScriptBytes),
+	}
+}
+
+// private version for marshalling purposes only
+type transformFile TransformFile
+
+// UnmarshalJSON implements json.Unmarshaler, allowing both string and object
+// representations
+func (f *TransformFile) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = TransformFile{Path: s}
+		return nil
+	}
+
+	_f := &transformFile{}
+	if err := json.Unmarshal(data, _f); err != nil {
+		return err
+	}
+
+	*f = TransformFile(*_f)
+	script, err := decodeInlineScript(data)
+	if err != nil {
+		return err
+	}
+	if script != nil {
+		f.ScriptBytes = script
+	}
+	return nil
+}
+
+// FileNames returns the names of all supporting files in sorted order
+func (q *Transform) FileNames() []string {
+	names := make([]string, 0, len(q.Files))
+	for name := range q.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenFiles generates a byte stream for each supporting file, prioritizing
+// creating an in-place file from ScriptBytes when defined, fetching from the
+// passed-in resolver otherwise
+func (q *Transform) OpenFiles(ctx context.Context, resolver qfs.PathResolver) (err error) {
+	for _, name := range q.FileNames() {
+		f := q.Files[name]
+		if f == nil {
+			continue
+		}
+		if f.ScriptBytes != nil {
+			f.file = qfs.NewMemfileBytes(name, f.ScriptBytes)
+			continue
+		}
+		if f.Path == "" {
+			// nothing to resolve
+			continue
+		}
+		if resolver == nil {
+			return ErrNoResolver
+		}
+		if f.file, err = resolver.Get(ctx, f.Path); err != nil {
+			return fmt.Errorf("opening file '%s': %s", name, err)
+		}
+	}
+	return nil
+}
+
+// InlineFiles opens all supporting files, reads their contents, and assigns
+// them to ScriptBytes
+func (q *Transform) InlineFiles(ctx context.Context, resolver qfs.PathResolver) error {
+	if resolver == nil {
+		return nil
+	}
+	if err := q.OpenFiles(ctx, resolver); err != nil {
+		return err
+	}
+	for _, name := range q.FileNames() {
+		f := q.Files[name]
+		if f == nil || f.file == nil {
+			continue
+		}
+		data, err := ioutil.ReadAll(f.file)
+		if err != nil {
+			return fmt.Errorf("reading file '%s': %s", name, err)
+		}
+		f.ScriptBytes = data
+		f.Path = ""
+		f.file = nil
+	}
+	return nil
+}
+
+// SetFile assigns an open supporting file, adding a file entry if one
+// doesn't exist
+func (q *Transform) SetFile(name string, file qfs.File) {
+	if q.Files == nil {
+		q.Files = map[string]*TransformFile{}
+	}
+	f, ok := q.Files[name]
+	if !ok || f == nil {
+		f = &TransformFile{}
+		q.Files[name] = f
+	}
+	f.file = file
+}
+
+// File gives the internal file for a supporting file name, if any. Callers
+// that use the file in any way (eg. by calling Read) should consume the entire
+// file and call Close
+func (q *Transform) File(name string) qfs.File {
+	if f, ok := q.Files[name]; ok && f != nil {
+		return f.file
+	}
+	return nil
+}
+
+// validateTransformFileName checks a supporting file name is a clean path
+// relative to the transform module root
+func validateTransformFileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("file name is required")
+	}
+	if path.IsAbs(name) || strings.Contains(name, "\\") || path.Clean(name) != name ||
+		name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("file '%s': name must be a relative path within the transform", name)
+	}
+	return nil
+}
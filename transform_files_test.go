@@ -0,0 +1,107 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/qfs"
+)
+
+// mapResolver resolves paths to in-memory files
+type mapResolver map[string]string
+
+func (m mapResolver) Get(ctx context.Context, path string) (qfs.File, error) {
+	data, ok := m[path]
+	if !ok {
+		return nil, fmt.Errorf("path not found")
+	}
+	return qfs.NewMemfileBytes(path, []byte(data)), nil
+}
+
+func TestTransformFilesJSON(t *testing.T) {
+	q := &Transform{}
+	data := []byte(`{"qri":"tf:0","scriptPath":"/ipfs/QmMain","files":{"lib/gtfs.star":"/ipfs/QmLib","util.star":{"scriptBytes":"ZGVmIGYoKTogcGFzcw=="}}}`)
+	if err := json.Unmarshal(data, q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Files["lib/gtfs.star"].Path != "/ipfs/QmLib" {
+		t.Errorf("expected string file to decode as a path, got: %#v", q.Files["lib/gtfs.star"])
+	}
+	if string(q.Files["util.star"].ScriptBytes) != "def f(): pass" {
+		t.Errorf("script bytes mismatch: %q", q.Files["util.star"].ScriptBytes)
+	}
+
+	q.DropTransientValues()
+	if q.Files["util.star"].ScriptBytes != nil {
+		t.Errorf("expected file script bytes to be dropped")
+	}
+	if q.Files["lib/gtfs.star"].Path != "/ipfs/QmLib" {
+		t.Errorf("expected file path to be retained")
+	}
+}
+
+func TestTransformInlineFiles(t *testing.T) {
+	resolver := mapResolver{"/ipfs/QmLib": "load('util.star', 'f')"}
+	q := &Transform{Files: map[string]*TransformFile{
+		"lib/gtfs.star": {Path: "/ipfs/QmLib"},
+		"util.star":     {ScriptBytes: []byte("def f(): pass")},
+	}}
+
+	if err := q.OpenFiles(context.Background(), nil); err != ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+	if err := q.OpenFiles(context.Background(), resolver); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(q.File("lib/gtfs.star"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != resolver["/ipfs/QmLib"] {
+		t.Errorf("file contents mismatch: %q", data)
+	}
+
+	if err := q.InlineFiles(context.Background(), resolver); err != nil {
+		t.Fatal(err)
+	}
+	f := q.Files["lib/gtfs.star"]
+	if f.Path != "" || string(f.ScriptBytes) != resolver["/ipfs/QmLib"] {
+		t.Errorf("expected inlined file, got: %#v", f)
+	}
+
+	missing := &Transform{Files: map[string]*TransformFile{"a.star": {Path: "/ipfs/QmMissing"}}}
+	if err := missing.OpenFiles(context.Background(), resolver); err == nil || err.Error() != "opening file 'a.star': path not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	q.SetFile("new.star", qfs.NewMemfileBytes("new.star", []byte("x = 1")))
+	if q.File("new.star") == nil || q.File("nope.star") != nil {
+		t.Errorf("SetFile mismatch")
+	}
+}
+
+func TestValidateTransformFileName(t *testing.T) {
+	cases := []struct {
+		name string
+		err  string
+	}{
+		{"main.star", ""},
+		{"lib/gtfs.star", ""},
+		{"", "file name is required"},
+		{"/etc/passwd", "file '/etc/passwd': name must be a relative path within the transform"},
+		{"../secrets.star", "file '../secrets.star': name must be a relative path within the transform"},
+		{"lib/../../x.star", "file 'lib/../../x.star': name must be a relative path within the transform"},
+		{"./main.star", "file './main.star': name must be a relative path within the transform"},
+		{`lib\main.star`, `file 'lib\main.star': name must be a relative path within the transform`},
+	}
+
+	for i, c := range cases {
+		err := validateTransformFileName(c.name)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
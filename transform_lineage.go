@@ -0,0 +1,381 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset/tabular"
+)
+
+// ColumnLineage records the input columns an output column of a transform is
+// derived from
+type ColumnLineage struct {
+	// Column is the title of the output column
+	Column string `json:"column"`
+	// Expression is the expression computing the column, if known, eg.
+	// "count(*)"
+	Expression string `json:"expression,omitempty"`
+	// Sources are the input columns the output column is derived from. Output
+	// columns computed only from constants have no sources
+	Sources []*ColumnSource `json:"sources,omitempty"`
+}
+
+// ColumnSource is a column of an input dataset
+type ColumnSource struct {
+	// Column is the title of the input column
+	Column string `json:"column"`
+	// Dataset is the path or name of a transform resource
+	Dataset string `json:"dataset"`
+}
+
+// Clone returns a deep copy of column lineage
+func (l *ColumnLineage) Clone() *ColumnLineage {
+	if l == nil {
+		return nil
+	}
+	c := &ColumnLineage{Column: l.Column, Expression: l.Expression}
+	if l.Sources != nil {
+		c.Sources = make([]*ColumnSource, len(l.Sources))
+		for i, s := range l.Sources {
+			if s != nil {
+				cs := *s
+				c.Sources[i] = &cs
+			}
+		}
+	}
+	return c
+}
+
+func cloneLineage(ls []*ColumnLineage) []*ColumnLineage {
+	if ls == nil {
+		return nil
+	}
+	c := make([]*ColumnLineage, len(ls))
+	for i, l := range ls {
+		c[i] = l.Clone()
+	}
+	return c
+}
+
+// LineageFor gives the lineage of an output column, nil if none is recorded
+func (q *Transform) LineageFor(column string) *ColumnLineage {
+	for _, l := range q.Lineage {
+		if l != nil && l.Column == column {
+			return l
+		}
+	}
+	return nil
+}
+
+// DerivedFrom lists the output columns derived from a column of an input
+// dataset, in lineage order
+func (q *Transform) DerivedFrom(dataset, column string) []string {
+	var cols []string
+	for _, l := range q.Lineage {
+		if l == nil {
+			continue
+		}
+		for _, s := range l.Sources {
+			if s != nil && s.Dataset == dataset && s.Column == column {
+				cols = append(cols, l.Column)
+				break
+			}
+		}
+	}
+	return cols
+}
+
+// validateLineage checks lineage is well formed. When resources are defined
+// source datasets must be a resource path or name
+func validateLineage(q *Transform) error {
+	var datasets map[string]bool
+	if q.Resources != nil {
+		datasets = map[string]bool{}
+		for _, r := range q.Resources {
+			if r != nil {
+				datasets[r.Path] = true
+				if r.Name != "" {
+					datasets[r.Name] = true
+				}
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for i, l := range q.Lineage {
+		if l == nil || l.Column == "" {
+			return fmt.Errorf("lineage index %d: column is required", i)
+		}
+		if seen[l.Column] {
+			return fmt.Errorf("lineage index %d: duplicate column '%s'", i, l.Column)
+		}
+		seen[l.Column] = true
+		for j, s := range l.Sources {
+			if s == nil || s.Dataset == "" || s.Column == "" {
+				return fmt.Errorf("lineage column '%s': sources index %d: dataset & column are required", l.Column, j)
+			}
+			if datasets != nil && !datasets[s.Dataset] {
+				return fmt.Errorf("lineage column '%s': dataset '%s' is not a transform resource", l.Column, s.Dataset)
+			}
+		}
+	}
+	return nil
+}
+
+// ExtractSQLLineage parses an SQL transform, assigning Lineage from the
+// select list of the query. structures gives the structures of the datasets
+// the query reads, keyed by table reference, and is used to expand "*" &
+// resolve unqualified columns when a query reads more than one table.
+// Only plain SELECT queries over datasets are supported: queries reading
+// common table expressions or subqueries error. Any existing lineage is
+// replaced
+func (q *Transform) ExtractSQLLineage(structures map[string]*Structure) error {
+	query, err := q.SQLQuery()
+	if err != nil {
+		return err
+	}
+	if len(query.with) > 0 {
+		return fmt.Errorf("lineage: common table expressions are not supported")
+	}
+	toks, err := tokenizeSQL(string(q.ScriptBytes))
+	if err != nil {
+		return err
+	}
+	for i, t := range toks {
+		if (t.keyword("from") || t.keyword("join")) && i+1 < len(toks) && toks[i+1].punct("(") {
+			return fmt.Errorf("lineage: subqueries are not supported")
+		}
+	}
+	items, err := selectItems(toks)
+	if err != nil {
+		return fmt.Errorf("lineage: %s", err)
+	}
+
+	r := &lineageResolver{query: query, tables: map[string]*SQLTable{}, columns: map[string][]string{}}
+	for i := range query.Tables {
+		if err := r.addTable(&query.Tables[i], structures[query.Tables[i].Ref]); err != nil {
+			return fmt.Errorf("lineage: %s", err)
+		}
+	}
+
+	script := string(q.ScriptBytes)
+	var lineage []*ColumnLineage
+	for _, item := range items {
+		ls, err := r.item(script, item)
+		if err != nil {
+			return fmt.Errorf("lineage: %s", err)
+		}
+		lineage = append(lineage, ls...)
+	}
+	q.Lineage = lineage
+	return nil
+}
+
+// selectItems splits the select list of the top level SELECT statement into
+// the tokens of each item
+func selectItems(toks []sqlToken) ([][]sqlToken, error) {
+	start := -1
+	depth := 0
+	for i, t := range toks {
+		if t.punct("(") {
+			depth++
+		} else if t.punct(")") {
+			depth--
+		} else if depth == 0 && t.keyword("select") {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("query has no SELECT")
+	}
+	if start < len(toks) && (toks[start].keyword("distinct") || toks[start].keyword("all")) {
+		start++
+	}
+
+	var items [][]sqlToken
+	item := []sqlToken{}
+	depth = 0
+	for _, t := range toks[start:] {
+		if depth == 0 && (t.keyword("from") || t.punct(";") || t.keyword("union") || t.keyword("except") || t.keyword("intersect")) {
+			break
+		}
+		switch {
+		case t.punct("("):
+			depth++
+		case t.punct(")"):
+			depth--
+		case depth == 0 && t.punct(","):
+			items = append(items, item)
+			item = []sqlToken{}
+			continue
+		}
+		item = append(item, t)
+	}
+	items = append(items, item)
+	for i, it := range items {
+		if len(it) == 0 {
+			return nil, fmt.Errorf("select item %d is empty", i)
+		}
+	}
+	return items, nil
+}
+
+// lineageResolver maps column references to the tables they read from
+type lineageResolver struct {
+	query *SQLQuery
+	// tables keyed by lower-cased reference, alias & last reference segment
+	tables map[string]*SQLTable
+	// column titles of each table reference. nil for unknown structures
+	columns map[string][]string
+}
+
+func (r *lineageResolver) addTable(t *SQLTable, st *Structure) error {
+	names := []string{strings.ToLower(t.Ref)}
+	if t.Alias != "" {
+		names = append(names, strings.ToLower(t.Alias))
+	} else if idx := strings.LastIndex(t.Ref, "/"); idx >= 0 {
+		names = append(names, strings.ToLower(t.Ref[idx+1:]))
+	}
+	for _, n := range names {
+		r.tables[n] = t
+	}
+	if st != nil && st.Schema != nil {
+		cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+		if err != nil {
+			return fmt.Errorf("table '%s': %s", t.Ref, err)
+		}
+		r.columns[t.Ref] = cols.Titles()
+	}
+	return nil
+}
+
+// item gives the lineage of a single select item, which is more than one
+// column for "*" items
+func (r *lineageResolver) item(script string, toks []sqlToken) ([]*ColumnLineage, error) {
+	if len(toks) == 1 && toks[0].punct("*") {
+		var ls []*ColumnLineage
+		for i := range r.query.Tables {
+			stars, err := r.star(&r.query.Tables[i])
+			if err != nil {
+				return nil, err
+			}
+			ls = append(ls, stars...)
+		}
+		return ls, nil
+	}
+	if len(toks) == 3 && toks[0].name() && toks[1].punct(".") && toks[2].punct("*") {
+		t, ok := r.tables[strings.ToLower(toks[0].val)]
+		if !ok {
+			return nil, fmt.Errorf("unknown table '%s'", toks[0].val)
+		}
+		return r.star(t)
+	}
+
+	// aliases are "expr AS name", or "expr name" following a complete
+	// expression
+	alias := ""
+	expr := toks
+	if n := len(toks); n >= 3 && toks[n-2].keyword("as") && toks[n-1].name() {
+		alias, expr = toks[n-1].val, toks[:n-2]
+	} else if n >= 2 && toks[n-1].name() && endsExpression(toks[n-2]) {
+		alias, expr = toks[n-1].val, toks[:n-1]
+	}
+
+	l := &ColumnLineage{Column: alias, Expression: script[expr[0].pos:expr[len(expr)-1].end]}
+	seen := map[ColumnSource]bool{}
+	for i := 0; i < len(expr); i++ {
+		t := expr[i]
+		if !t.name() || i+1 < len(expr) && expr[i+1].punct("(") || i > 0 && expr[i-1].keyword("as") {
+			// skip function names & type names of CAST(x AS type)
+			continue
+		}
+		ref := SQLColumnRef{Name: t.val}
+		if i+2 < len(expr) && expr[i+1].punct(".") && expr[i+2].name() {
+			ref = SQLColumnRef{Qualifier: t.val, Name: expr[i+2].val}
+			i += 2
+		}
+		src, err := r.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[*src] {
+			seen[*src] = true
+			l.Sources = append(l.Sources, src)
+		}
+	}
+
+	if l.Column == "" {
+		l.Column = l.Expression
+		if len(l.Sources) == 1 && (len(expr) == 1 || len(expr) == 3 && expr[1].punct(".")) {
+			// plain column references keep their name
+			l.Column = expr[len(expr)-1].val
+		}
+	}
+	return []*ColumnLineage{l}, nil
+}
+
+// endsExpression is true for tokens that can end an expression, so a name
+// following them is an implicit alias
+func endsExpression(t sqlToken) bool {
+	return t.name() || t.punct(")") || t.typ == sqlNumber || t.typ == sqlString
+}
+
+// star gives lineage for every column of a table
+func (r *lineageResolver) star(t *SQLTable) ([]*ColumnLineage, error) {
+	cols, ok := r.columns[t.Ref]
+	if !ok {
+		return nil, fmt.Errorf("table '%s': a structure is required to expand *", t.Ref)
+	}
+	ls := make([]*ColumnLineage, len(cols))
+	for i, col := range cols {
+		ls[i] = &ColumnLineage{Column: col, Sources: []*ColumnSource{{Dataset: t.Ref, Column: col}}}
+	}
+	return ls, nil
+}
+
+// resolve finds the table & column title of a column reference
+func (r *lineageResolver) resolve(ref SQLColumnRef) (*ColumnSource, error) {
+	if ref.Qualifier != "" {
+		t, ok := r.tables[strings.ToLower(ref.Qualifier)]
+		if !ok {
+			return nil, fmt.Errorf("column '%s': unknown table '%s'", ref, ref.Qualifier)
+		}
+		return &ColumnSource{Dataset: t.Ref, Column: r.title(t, ref.Name)}, nil
+	}
+
+	if len(r.query.Tables) == 1 {
+		t := &r.query.Tables[0]
+		return &ColumnSource{Dataset: t.Ref, Column: r.title(t, ref.Name)}, nil
+	}
+	var found *SQLTable
+	for i := range r.query.Tables {
+		t := &r.query.Tables[i]
+		cols, ok := r.columns[t.Ref]
+		if !ok {
+			return nil, fmt.Errorf("column '%s': table '%s' has no structure to resolve unqualified columns", ref, t.Ref)
+		}
+		for _, col := range cols {
+			if strings.EqualFold(col, ref.Name) {
+				if found != nil && found.Ref != t.Ref {
+					return nil, fmt.Errorf("column '%s' is ambiguous", ref)
+				}
+				found = t
+			}
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unknown column '%s'", ref)
+	}
+	return &ColumnSource{Dataset: found.Ref, Column: r.title(found, ref.Name)}, nil
+}
+
+// title gives the column title of a table matching name case-insensitively,
+// or name if the table structure is unknown
+func (r *lineageResolver) title(t *SQLTable, name string) string {
+	for _, col := range r.columns[t.Ref] {
+		if strings.EqualFold(col, name) {
+			return col
+		}
+	}
+	return name
+}
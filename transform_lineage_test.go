@@ -0,0 +1,125 @@
+package dataset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func tabularStructure(titles ...string) *Structure {
+	cols := make([]interface{}, len(titles))
+	for i, t := range titles {
+		cols[i] = map[string]interface{}{"title": t, "type": "string"}
+	}
+	return &Structure{
+		Format: "csv",
+		Schema: map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": cols},
+		},
+	}
+}
+
+func TestExtractSQLLineage(t *testing.T) {
+	structures := map[string]*Structure{
+		"mfdz/stops":  tabularStructure("stop_id", "stop_name", "Stop_Lat", "stop_lon"),
+		"mfdz/routes": tabularStructure("route_id", "route_name", "agency_id"),
+		"mfdz/trips":  tabularStructure("trip_id", "route_id", "stop_id"),
+	}
+	cases := []struct {
+		description string
+		query       string
+		structures  map[string]*Structure
+		expect      string
+		err         string
+	}{
+		{"plain columns", "select stop_id, stop_name as name from mfdz/stops", nil,
+			`[{"column":"stop_id","expression":"stop_id","sources":[{"column":"stop_id","dataset":"mfdz/stops"}]},{"column":"name","expression":"stop_name","sources":[{"column":"stop_name","dataset":"mfdz/stops"}]}]`, ""},
+		{"expressions", "SELECT DISTINCT round(stop_lat, 3) lat, count(*), 'x' AS tag, CAST(stop_lon AS float) FROM mfdz/stops s", structures,
+			`[{"column":"lat","expression":"round(stop_lat, 3)","sources":[{"column":"Stop_Lat","dataset":"mfdz/stops"}]},{"column":"count(*)","expression":"count(*)"},{"column":"tag","expression":"'x'"},{"column":"CAST(stop_lon AS float)","expression":"CAST(stop_lon AS float)","sources":[{"column":"stop_lon","dataset":"mfdz/stops"}]}]`, ""},
+		{"joins", "select t.trip_id, r.route_name, stop_id, r.agency_id || '-' || t.route_id as key from mfdz/trips t join mfdz/routes r on t.route_id = r.route_id", structures,
+			`[{"column":"trip_id","expression":"t.trip_id","sources":[{"column":"trip_id","dataset":"mfdz/trips"}]},{"column":"route_name","expression":"r.route_name","sources":[{"column":"route_name","dataset":"mfdz/routes"}]},{"column":"stop_id","expression":"stop_id","sources":[{"column":"stop_id","dataset":"mfdz/trips"}]},{"column":"key","expression":"r.agency_id || '-' || t.route_id","sources":[{"column":"agency_id","dataset":"mfdz/routes"},{"column":"route_id","dataset":"mfdz/trips"}]}]`, ""},
+		{"star", "select * from mfdz/routes", structures,
+			`[{"column":"route_id","sources":[{"column":"route_id","dataset":"mfdz/routes"}]},{"column":"route_name","sources":[{"column":"route_name","dataset":"mfdz/routes"}]},{"column":"agency_id","sources":[{"column":"agency_id","dataset":"mfdz/routes"}]}]`, ""},
+		{"qualified star", "select routes.*, t.trip_id from mfdz/routes join mfdz/trips t using (route_id)", map[string]*Structure{"mfdz/routes": tabularStructure("route_id")},
+			`[{"column":"route_id","sources":[{"column":"route_id","dataset":"mfdz/routes"}]},{"column":"trip_id","expression":"t.trip_id","sources":[{"column":"trip_id","dataset":"mfdz/trips"}]}]`, ""},
+		{"star without structure", "select * from mfdz/routes", nil, "", "lineage: table 'mfdz/routes': a structure is required to expand *"},
+		{"ambiguous", "select route_id from mfdz/trips join mfdz/routes on trips.route_id = routes.route_id", structures, "", "lineage: column 'route_id' is ambiguous"},
+		{"unknown", "select color from mfdz/trips join mfdz/routes on trips.route_id = routes.route_id", structures, "", "lineage: unknown column 'color'"},
+		{"unresolvable", "select route_id from mfdz/trips join mfdz/routes on trips.route_id = routes.route_id", nil, "", "lineage: column 'route_id': table 'mfdz/trips' has no structure to resolve unqualified columns"},
+		{"unknown table", "select x.stop_id from mfdz/stops", nil, "", "lineage: column 'x.stop_id': unknown table 'x'"},
+		{"cte", "with s as (select * from mfdz/stops) select stop_id from s", nil, "", "lineage: common table expressions are not supported"},
+		{"subquery", "select stop_id from (select * from mfdz/stops)", nil, "", "lineage: subqueries are not supported"},
+	}
+
+	for _, c := range cases {
+		q := &Transform{Syntax: SyntaxSQL, ScriptBytes: []byte(c.query)}
+		err := q.ExtractSQLLineage(c.structures)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%s error mismatch. expected: %q, got: %v", c.description, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		data, err := json.Marshal(q.Lineage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != c.expect {
+			t.Errorf("%s lineage mismatch.\nexpected: %s\ngot:      %s", c.description, c.expect, data)
+		}
+	}
+}
+
+func TestLineageQueries(t *testing.T) {
+	q := &Transform{Lineage: []*ColumnLineage{
+		{Column: "name", Sources: []*ColumnSource{{Dataset: "mfdz/stops", Column: "stop_name"}}},
+		{Column: "label", Sources: []*ColumnSource{{Dataset: "mfdz/stops", Column: "stop_name"}, {Dataset: "mfdz/stops", Column: "stop_id"}}},
+		{Column: "n"},
+	}}
+
+	if l := q.LineageFor("label"); l == nil || len(l.Sources) != 2 {
+		t.Errorf("expected label lineage with 2 sources, got: %v", l)
+	}
+	if l := q.LineageFor("missing"); l != nil {
+		t.Errorf("expected nil lineage for unknown column, got: %v", l)
+	}
+	if got := q.DerivedFrom("mfdz/stops", "stop_name"); len(got) != 2 || got[0] != "name" || got[1] != "label" {
+		t.Errorf("derived columns mismatch. got: %v", got)
+	}
+	if got := q.DerivedFrom("mfdz/stops", "stop_lat"); got != nil {
+		t.Errorf("expected no derived columns, got: %v", got)
+	}
+
+	c := q.Clone()
+	c.Lineage[0].Sources[0].Column = "changed"
+	if q.Lineage[0].Sources[0].Column != "stop_name" {
+		t.Error("expected clone lineage to be a deep copy")
+	}
+}
+
+func TestValidateLineage(t *testing.T) {
+	cases := []struct {
+		q   *Transform
+		err string
+	}{
+		{&Transform{Lineage: []*ColumnLineage{{Column: "a", Sources: []*ColumnSource{{Dataset: "mfdz/stops", Column: "a"}}}}}, ""},
+		{&Transform{
+			Resources: map[string]*TransformResource{"a": {Path: "/ipfs/QmStops", Name: "stops"}},
+			Lineage:   []*ColumnLineage{{Column: "a", Sources: []*ColumnSource{{Dataset: "stops", Column: "a"}}}},
+		}, ""},
+		{&Transform{Lineage: []*ColumnLineage{{}}}, "lineage index 0: column is required"},
+		{&Transform{Lineage: []*ColumnLineage{{Column: "a"}, {Column: "a"}}}, "lineage index 1: duplicate column 'a'"},
+		{&Transform{Lineage: []*ColumnLineage{{Column: "a", Sources: []*ColumnSource{{Column: "a"}}}}}, "lineage column 'a': sources index 0: dataset & column are required"},
+		{&Transform{
+			Resources: map[string]*TransformResource{"a": {Path: "mfdz/routes"}},
+			Lineage:   []*ColumnLineage{{Column: "a", Sources: []*ColumnSource{{Dataset: "mfdz/stops", Column: "a"}}}},
+		}, "lineage column 'a': dataset 'mfdz/stops' is not a transform resource"},
+	}
+	for i, c := range cases {
+		err := validateLineage(c.q)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
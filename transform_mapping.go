@@ -0,0 +1,208 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SyntaxMapping is the transform syntax for declarative column mappings.
+// Mapping scripts are a Mapping encoded as JSON or YAML
+const SyntaxMapping = "mapping"
+
+// Mapping operations
+const (
+	// MapRename copies the From column
+	MapRename = "rename"
+	// MapCast converts the From column to Type
+	MapCast = "cast"
+	// MapSplit splits the From column on Separator, keeping the part at Index
+	MapSplit = "split"
+	// MapConcat joins the Sources columns with Separator
+	MapConcat = "concat"
+	// MapConstant sets every row to Value
+	MapConstant = "constant"
+	// MapExtract keeps the part of the From column matched by Pattern
+	MapExtract = "extract"
+)
+
+// mappingCastTypes are the types columns can be cast to
+var mappingCastTypes = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true}
+
+// Mapping is a declarative transform of tabular rows: each output column is
+// computed from the columns of a single input row. Mappings cover the column
+// shuffles most transforms are, without a scripting runtime
+type Mapping struct {
+	// Columns are the output columns, in order
+	Columns []*MappingColumn `json:"columns"`
+}
+
+// MappingColumn describes how an output column is computed
+type MappingColumn struct {
+	// Title of the output column
+	Title string `json:"title"`
+	// Op is one of "rename", "cast", "split", "concat", "constant" or
+	// "extract"
+	Op string `json:"op"`
+	// From is the title of the input column of rename, cast, split & extract
+	From string `json:"from,omitempty"`
+	// Sources are the titles of the input columns concat joins
+	Sources []string `json:"sources,omitempty"`
+	// Separator splits or joins values
+	Separator string `json:"separator,omitempty"`
+	// Index is the part of a split value to keep. negative values count from
+	// the end
+	Index int `json:"index,omitempty"`
+	// Pattern is the regular expression extract matches
+	Pattern string `json:"pattern,omitempty"`
+	// Group is the capture group extract keeps. zero keeps the first group
+	// of patterns with groups, and the whole match otherwise
+	Group int `json:"group,omitempty"`
+	// Value is the value of constant columns
+	Value interface{} `json:"value,omitempty"`
+	// Type converts the output of any op to one of "string", "integer",
+	// "number" or "boolean". required for cast
+	Type string `json:"type,omitempty"`
+}
+
+// ParseMapping decodes & validates a mapping script
+func ParseMapping(script []byte) (*Mapping, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(script, &v); err != nil {
+		return nil, err
+	}
+	v, err := jsonCompatible(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	m := &Mapping{}
+	if err := dec.Decode(m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Validate checks a mapping is well formed
+func (m *Mapping) Validate() error {
+	if len(m.Columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	titles := map[string]bool{}
+	for i, c := range m.Columns {
+		if c == nil || c.Title == "" {
+			return fmt.Errorf("column %d: title is required", i)
+		}
+		if titles[c.Title] {
+			return fmt.Errorf("column '%s': duplicate title", c.Title)
+		}
+		titles[c.Title] = true
+		if c.Type != "" && !mappingCastTypes[c.Type] {
+			return fmt.Errorf("column '%s': invalid type '%s'", c.Title, c.Type)
+		}
+
+		switch c.Op {
+		case MapRename, MapCast, MapSplit, MapExtract:
+			if c.From == "" {
+				return fmt.Errorf("column '%s': %s requires from", c.Title, c.Op)
+			}
+		case MapConcat:
+			if len(c.Sources) == 0 {
+				return fmt.Errorf("column '%s': concat requires sources", c.Title)
+			}
+		case MapConstant:
+		default:
+			return fmt.Errorf("column '%s': unknown op '%s'", c.Title, c.Op)
+		}
+		switch c.Op {
+		case MapCast:
+			if c.Type == "" {
+				return fmt.Errorf("column '%s': cast requires type", c.Title)
+			}
+		case MapSplit:
+			if c.Separator == "" {
+				return fmt.Errorf("column '%s': split requires separator", c.Title)
+			}
+		case MapExtract:
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				return fmt.Errorf("column '%s': pattern: %s", c.Title, err)
+			}
+			if c.Group < 0 || c.Group > re.NumSubexp() {
+				return fmt.Errorf("column '%s': pattern has no group %d", c.Title, c.Group)
+			}
+		}
+	}
+	return nil
+}
+
+// SourceColumns lists the input columns a mapping reads, in order of first
+// use
+func (m *Mapping) SourceColumns() []string {
+	var cols []string
+	seen := map[string]bool{}
+	for _, c := range m.Columns {
+		if c == nil {
+			continue
+		}
+		for _, src := range append([]string{c.From}, c.Sources...) {
+			if src != "" && !seen[src] {
+				seen[src] = true
+				cols = append(cols, src)
+			}
+		}
+	}
+	return cols
+}
+
+// Mapping parses the transform script as a mapping
+func (q *Transform) Mapping() (*Mapping, error) {
+	if !strings.EqualFold(q.Syntax, SyntaxMapping) {
+		return nil, fmt.Errorf("transform syntax '%s' is not %s", q.Syntax, SyntaxMapping)
+	}
+	if q.ScriptBytes == nil {
+		return nil, fmt.Errorf("mapping transforms require scriptBytes")
+	}
+	return ParseMapping(q.ScriptBytes)
+}
+
+// SetMapping stores a mapping as the transform script, setting the syntax to
+// SyntaxMapping
+func (q *Transform) SetMapping(m *Mapping) error {
+	if m == nil {
+		return fmt.Errorf("mapping is required")
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	q.Syntax = SyntaxMapping
+	q.ScriptBytes = data
+	return nil
+}
+
+// validateMappingTransform checks an inline mapping transform parses
+func validateMappingTransform(q *Transform) error {
+	if !strings.EqualFold(q.Syntax, SyntaxMapping) || q.ScriptBytes == nil {
+		return nil
+	}
+	if _, err := q.Mapping(); err != nil {
+		return fmt.Errorf("mapping: %s", err)
+	}
+	return nil
+}
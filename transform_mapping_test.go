@@ -0,0 +1,95 @@
+package dataset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	yml := `columns:
+  - title: stop
+    op: rename
+    from: stop_name
+  - title: code
+    op: extract
+    from: stop_id
+    pattern: 'de:(\d+)'
+    type: integer
+  - title: source
+    op: constant
+    value: mfdz
+`
+	m, err := ParseMapping([]byte(yml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &Mapping{Columns: []*MappingColumn{
+		{Title: "stop", Op: MapRename, From: "stop_name"},
+		{Title: "code", Op: MapExtract, From: "stop_id", Pattern: `de:(\d+)`, Type: "integer"},
+		{Title: "source", Op: MapConstant, Value: "mfdz"},
+	}}
+	if !reflect.DeepEqual(expect, m) {
+		t.Errorf("mapping mismatch. expected: %#v, got: %#v", expect, m)
+	}
+	if got := m.SourceColumns(); !reflect.DeepEqual(got, []string{"stop_name", "stop_id"}) {
+		t.Errorf("source columns mismatch, got: %v", got)
+	}
+
+	cases := []struct {
+		script string
+		err    string
+	}{
+		{`{"columns": []}`, "at least one column is required"},
+		{`{"columns": [{"op": "rename", "from": "a"}]}`, "column 0: title is required"},
+		{`{"columns": [{"title": "a", "op": "constant"}, {"title": "a", "op": "constant"}]}`, "column 'a': duplicate title"},
+		{`{"columns": [{"title": "a", "op": "explode"}]}`, "column 'a': unknown op 'explode'"},
+		{`{"columns": [{"title": "a", "op": "rename"}]}`, "column 'a': rename requires from"},
+		{`{"columns": [{"title": "a", "op": "cast", "from": "b"}]}`, "column 'a': cast requires type"},
+		{`{"columns": [{"title": "a", "op": "cast", "from": "b", "type": "date"}]}`, "column 'a': invalid type 'date'"},
+		{`{"columns": [{"title": "a", "op": "concat"}]}`, "column 'a': concat requires sources"},
+		{`{"columns": [{"title": "a", "op": "split", "from": "b"}]}`, "column 'a': split requires separator"},
+		{`{"columns": [{"title": "a", "op": "extract", "from": "b", "pattern": "("}]}`, "column 'a': pattern: error parsing regexp: missing closing ): `(`"},
+		{`{"columns": [{"title": "a", "op": "extract", "from": "b", "pattern": "x", "group": 1}]}`, "column 'a': pattern has no group 1"},
+		{`{"columns": [{"title": "a", "op": "constant", "colour": "red"}]}`, `json: unknown field "colour"`},
+	}
+	for i, c := range cases {
+		_, err := ParseMapping([]byte(c.script))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestTransformMapping(t *testing.T) {
+	q := &Transform{}
+	m := &Mapping{Columns: []*MappingColumn{{Title: "name", Op: MapConcat, Sources: []string{"first", "last"}, Separator: " "}}}
+	if err := q.SetMapping(m); err != nil {
+		t.Fatal(err)
+	}
+	if q.Syntax != SyntaxMapping {
+		t.Errorf("expected syntax to be set, got: %s", q.Syntax)
+	}
+	got, err := q.Mapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, got) {
+		t.Errorf("mapping mismatch. expected: %#v, got: %#v", m, got)
+	}
+
+	if err := q.Validate(context.Background(), nil); err != nil {
+		t.Errorf("expected mapping transform to be valid, got: %s", err)
+	}
+	q.ScriptBytes = []byte(`{"columns": [{"title": "a"}]}`)
+	if err := validateTransform(q); err == nil || err.Error() != "mapping: column 'a': unknown op ''" {
+		t.Errorf("expected an invalid mapping error, got: %v", err)
+	}
+	if err := q.Validate(context.Background(), nil); err == nil || err.Error() != "mapping: column 'a': unknown op ''" {
+		t.Errorf("expected the mapping to be parsed once by validate, got: %v", err)
+	}
+
+	if _, err := (&Transform{Syntax: "sql"}).Mapping(); err == nil || err.Error() != "transform syntax 'sql' is not mapping" {
+		t.Errorf("expected a syntax error, got: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// resourceName matches valid resource variable names
+var resourceName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ResolvedPath gives the path a resource should be read from: the pinned
+// version if one is set, Path otherwise
+func (r *TransformResource) ResolvedPath() string {
+	if r.Version != "" {
+		return r.Version
+	}
+	return r.Path
+}
+
+// IsPinned checks if a resource is pinned to a version
+func (r *TransformResource) IsPinned() bool {
+	return r.Version != ""
+}
+
+// Resource gets a resource by variable name, falling back to resource keys
+// for resources without a name. returns nil if no resource matches
+func (q *Transform) Resource(name string) *TransformResource {
+	for _, r := range q.Resources {
+		if r != nil && r.Name == name {
+			return r
+		}
+	}
+	if r, ok := q.Resources[name]; ok && r != nil && r.Name == "" {
+		return r
+	}
+	return nil
+}
+
+// RepinResources pins each dataset resource to the latest version of it's
+// reference, as loaded by load. URL resources are skipped. Returns the sorted
+// keys of resources that changed version
+func (q *Transform) RepinResources(ctx context.Context, load DatasetLoader) ([]string, error) {
+	if load == nil {
+		return nil, ErrNoResolver
+	}
+	var changed []string
+	for _, key := range sortedResourceNames(q.Resources) {
+		r := q.Resources[key]
+		if r == nil || r.IsURL() {
+			continue
+		}
+		ds, err := load(ctx, r.Path)
+		if err != nil {
+			return changed, fmt.Errorf("resource '%s': loading '%s': %s", key, r.Path, err)
+		}
+		if ds.Path == "" {
+			return changed, fmt.Errorf("resource '%s': '%s' did not resolve to a version path", key, r.Path)
+		}
+		if ds.Path != r.Version {
+			r.Version = ds.Path
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// validateResourceNames checks resource names are valid variable names &
+// unique within a transform
+func validateResourceNames(rs map[string]*TransformResource) error {
+	names := map[string]string{}
+	for _, key := range sortedResourceNames(rs) {
+		r := rs[key]
+		if r == nil || r.Name == "" {
+			continue
+		}
+		if !resourceName.MatchString(r.Name) {
+			return fmt.Errorf("resource '%s': invalid name '%s'", key, r.Name)
+		}
+		if other, ok := names[r.Name]; ok {
+			return fmt.Errorf("resource '%s': name '%s' is already used by resource '%s'", key, r.Name, other)
+		}
+		names[r.Name] = key
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTransformRepinResources(t *testing.T) {
+	latest := map[string]string{
+		"mfdz/stops":  "/ipfs/QmStops2",
+		"mfdz/routes": "/ipfs/QmRoutes1",
+	}
+	load := func(ctx context.Context, ref string) (*Dataset, error) {
+		if p, ok := latest[ref]; ok {
+			return &Dataset{Path: p}, nil
+		}
+		return nil, fmt.Errorf("not found")
+	}
+
+	q := &Transform{Resources: map[string]*TransformResource{
+		"a": {Name: "stops", Path: "mfdz/stops", Version: "/ipfs/QmStops1"},
+		"b": {Name: "routes", Path: "mfdz/routes", Version: "/ipfs/QmRoutes1"},
+		"c": {Path: "https://example.com/gtfs.zip"},
+	}}
+
+	if q.Resource("stops") != q.Resources["a"] || q.Resource("c") != q.Resources["c"] || q.Resource("a") != nil {
+		t.Errorf("resource lookup mismatch")
+	}
+
+	changed, err := q.RepinResources(context.Background(), load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{"a"}) {
+		t.Errorf("changed mismatch. expected: [a], got: %v", changed)
+	}
+	if r := q.Resources["a"]; r.Version != "/ipfs/QmStops2" || r.ResolvedPath() != "/ipfs/QmStops2" || r.Path != "mfdz/stops" {
+		t.Errorf("expected resource to be repinned, got: %#v", r)
+	}
+	if q.Resources["c"].IsPinned() {
+		t.Errorf("expected url resource to be skipped")
+	}
+
+	q.Resources["d"] = &TransformResource{Path: "mfdz/missing"}
+	if _, err := q.RepinResources(context.Background(), load); err == nil || err.Error() != "resource 'd': loading 'mfdz/missing': not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := q.RepinResources(context.Background(), nil); err != ErrNoResolver {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
+
+func TestValidateResourceNames(t *testing.T) {
+	cases := []struct {
+		rs  map[string]*TransformResource
+		err string
+	}{
+		{map[string]*TransformResource{"a": {Name: "stops", Path: "a"}, "b": {Path: "b"}}, ""},
+		{map[string]*TransformResource{"a": {Name: "bus-stops", Path: "a"}}, "resource 'a': invalid name 'bus-stops'"},
+		{map[string]*TransformResource{"a": {Name: "stops", Path: "a"}, "b": {Name: "stops", Path: "b"}}, "resource 'b': name 'stops' is already used by resource 'a'"},
+	}
+
+	for i, c := range cases {
+		err := validateResourceNames(c.rs)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package dataset
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IsURL checks if a resource references an HTTP(S) URL
+func (r *TransformResource) IsURL() bool {
+	return strings.HasPrefix(r.Path, "http://") || strings.HasPrefix(r.Path, "https://")
+}
+
+// RecordFetch captures fetch metadata for a URL resource from the response &
+// body of a successful request, so a later fetch can be checked against it
+func (r *TransformResource) RecordFetch(res *http.Response, body []byte) error {
+	if !r.IsURL() {
+		return fmt.Errorf("resource '%s' is not a url", r.Path)
+	}
+	if res == nil {
+		return fmt.Errorf("response is required")
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	hash, err := HashBytes(body)
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+	r.ETag = res.Header.Get("ETag")
+	r.LastModified = res.Header.Get("Last-Modified")
+	return nil
+}
+
+// Verify checks fetched data matches the recorded content hash of a resource.
+// resources without a recorded hash can't be verified, and error
+func (r *TransformResource) Verify(body []byte) error {
+	if r.Hash == "" {
+		return fmt.Errorf("resource '%s' has no recorded hash", r.Path)
+	}
+	hash, err := HashBytes(body)
+	if err != nil {
+		return err
+	}
+	if hash != r.Hash {
+		return fmt.Errorf("resource '%s' content changed. expected hash %s, got %s", r.Path, r.Hash, hash)
+	}
+	return nil
+}
+
+// ConditionalRequest creates a GET request for a URL resource that uses
+// recorded fetch metadata to only transfer the body if it has changed. A
+// response status of 304 Not Modified means the resource is unchanged
+func (r *TransformResource) ConditionalRequest() (*http.Request, error) {
+	if !r.IsURL() {
+		return nil, fmt.Errorf("resource '%s' is not a url", r.Path)
+	}
+	req, err := http.NewRequest("GET", r.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.ETag != "" {
+		req.Header.Set("If-None-Match", r.ETag)
+	}
+	if r.LastModified != "" {
+		req.Header.Set("If-Modified-Since", r.LastModified)
+	}
+	return req, nil
+}
+
+// validateResourceURL checks URL resources are valid with a host, and that
+// LastModified is an HTTP date
+func validateResourceURL(r *TransformResource) error {
+	if !r.IsURL() {
+		if r.ETag != "" || r.LastModified != "" {
+			return fmt.Errorf("etag & lastModified are only valid for url resources")
+		}
+		return nil
+	}
+	u, err := url.Parse(r.Path)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url '%s' has no host", r.Path)
+	}
+	if r.LastModified != "" {
+		if _, err := http.ParseTime(r.LastModified); err != nil {
+			return fmt.Errorf("invalid lastModified '%s'", r.LastModified)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package dataset
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransformResourceRecordFetch(t *testing.T) {
+	body := []byte("stop_id,stop_name\n1,Hauptbahnhof\n")
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write(body)
+	}))
+	defer s.Close()
+
+	r := &TransformResource{Path: s.URL + "/stops.csv"}
+	if !r.IsURL() {
+		t.Fatalf("expected resource to be a url")
+	}
+	if err := r.Verify(body); err == nil {
+		t.Errorf("expected verifying without a recorded hash to error")
+	}
+
+	req, err := r.ConditionalRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if err := r.RecordFetch(res, body); err != nil {
+		t.Fatal(err)
+	}
+	if r.ETag != `"v1"` || r.LastModified != lastModified || r.Hash == "" {
+		t.Errorf("fetch metadata mismatch: %#v", r)
+	}
+	if err := r.Verify(body); err != nil {
+		t.Error(err)
+	}
+	if err := r.Verify([]byte("changed")); err == nil {
+		t.Errorf("expected changed content to fail verification")
+	}
+
+	// recorded metadata makes the next request conditional
+	if req, err = r.ConditionalRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("expected conditional request to be not modified, got: %d", res.StatusCode)
+	}
+	if err := r.RecordFetch(res, nil); err == nil {
+		t.Errorf("expected recording a non-2xx response to error")
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &TransformResource{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if *got != *r {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", r, got)
+	}
+
+	ds := &TransformResource{Path: "/ipfs/QmStops"}
+	if ds.IsURL() {
+		t.Errorf("expected dataset path not to be a url")
+	}
+	if _, err := ds.ConditionalRequest(); err == nil {
+		t.Errorf("expected conditional request for a dataset path to error")
+	}
+}
+
+func TestValidateResourceURL(t *testing.T) {
+	cases := []struct {
+		r   *TransformResource
+		err string
+	}{
+		{&TransformResource{Path: "/ipfs/QmStops"}, ""},
+		{&TransformResource{Path: "https://example.com/gtfs.zip", LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}, ""},
+		{&TransformResource{Path: "https:///gtfs.zip"}, "url 'https:///gtfs.zip' has no host"},
+		{&TransformResource{Path: "https://example.com/gtfs.zip", LastModified: "yesterday"}, "invalid lastModified 'yesterday'"},
+		{&TransformResource{Path: "/ipfs/QmStops", ETag: `"v1"`}, "etag & lastModified are only valid for url resources"},
+	}
+
+	for i, c := range cases {
+		err := validateResourceURL(c.r)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
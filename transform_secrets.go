@@ -0,0 +1,137 @@
+package dataset
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SecretRedacted replaces secret values when a transform is marshaled
+const SecretRedacted = "[redacted]"
+
+// redactedSecrets returns a copy of secrets with all values redacted
+func redactedSecrets(secrets map[string]string) map[string]string {
+	if secrets == nil {
+		return nil
+	}
+	r := make(map[string]string, len(secrets))
+	for key := range secrets {
+		r[key] = SecretRedacted
+	}
+	return r
+}
+
+// SecretKeys returns the sorted names of all secrets a transform uses
+func (q *Transform) SecretKeys() []string {
+	keys := make([]string, 0, len(q.Secrets))
+	for key := range q.Secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RedactSecrets replaces all secret values with SecretRedacted, retaining
+// secret names
+func (q *Transform) RedactSecrets() {
+	for key := range q.Secrets {
+		q.Secrets[key] = SecretRedacted
+	}
+}
+
+// InjectSecrets assigns secret values for execution. Any secret the transform
+// declares that isn't provided and has no value is an error, redacted &
+// empty values count as no value. Secrets not already declared are added
+func (q *Transform) InjectSecrets(secrets map[string]string) error {
+	if q.Secrets == nil && len(secrets) > 0 {
+		q.Secrets = make(map[string]string, len(secrets))
+	}
+	for key, val := range secrets {
+		q.Secrets[key] = val
+	}
+
+	var missing []string
+	for _, key := range q.SecretKeys() {
+		if val := q.Secrets[key]; val == SecretRedacted || val == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing values for secrets: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// EncryptSecrets encrypts secret values with AES-GCM, storing the result in
+// EncryptedSecrets so they can be persisted alongside the transform. key must
+// be 16, 24 or 32 bytes long. Redacted values are not encrypted. Encryption
+// uses a random nonce, so EncryptedSecrets will differ each time secrets are
+// encrypted
+func (q *Transform) EncryptSecrets(key []byte) error {
+	values := map[string]string{}
+	for k, v := range q.Secrets {
+		if v != SecretRedacted {
+			values[k] = v
+		}
+	}
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := secretsCipher(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generating nonce: %s", err)
+	}
+	q.EncryptedSecrets = gcm.Seal(nonce, nonce, plaintext, nil)
+	return nil
+}
+
+// DecryptSecrets decrypts EncryptedSecrets with key, assigning decrypted
+// values to Secrets
+func (q *Transform) DecryptSecrets(key []byte) error {
+	if q.EncryptedSecrets == nil {
+		return nil
+	}
+	gcm, err := secretsCipher(key)
+	if err != nil {
+		return err
+	}
+	if len(q.EncryptedSecrets) < gcm.NonceSize() {
+		return fmt.Errorf("decrypting secrets: ciphertext is too short")
+	}
+	nonce, ciphertext := q.EncryptedSecrets[:gcm.NonceSize()], q.EncryptedSecrets[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return fmt.Errorf("decrypting secrets: %s", err)
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return fmt.Errorf("decrypting secrets: %s", err)
+	}
+	if q.Secrets == nil {
+		q.Secrets = make(map[string]string, len(values))
+	}
+	for k, v := range values {
+		q.Secrets[k] = v
+	}
+	return nil
+}
+
+func secretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %s", err)
+	}
+	return cipher.NewGCM(block)
+}
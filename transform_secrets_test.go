@@ -0,0 +1,83 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTransformSecretsRedaction(t *testing.T) {
+	q := &Transform{Syntax: "starlark", Secrets: map[string]string{"token": "hunter2"}}
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"qri":"tf:0","secrets":{"token":"[redacted]"},"syntax":"starlark"}`
+	if string(data) != expect {
+		t.Errorf("marshal mismatch. expected: %s, got: %s", expect, data)
+	}
+	if q.Secrets["token"] != "hunter2" {
+		t.Errorf("marshaling shouldn't modify secret values")
+	}
+
+	got := &Transform{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	// values must be injected after decoding
+	if err := got.InjectSecrets(nil); err == nil || err.Error() != "missing values for secrets: token" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := got.InjectSecrets(map[string]string{"token": "hunter2", "extra": "value"}); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(got.Secrets, map[string]string{"token": "hunter2", "extra": "value"}) {
+		t.Errorf("injected secrets mismatch: %v", got.Secrets)
+	}
+
+	// redacted values never overwrite known secrets in assign
+	q.Assign(&Transform{Secrets: map[string]string{"token": SecretRedacted, "new": SecretRedacted}})
+	if q.Secrets["token"] != "hunter2" || q.Secrets["new"] != SecretRedacted {
+		t.Errorf("assign mismatch: %v", q.Secrets)
+	}
+
+	q.DropTransientValues()
+	if !reflect.DeepEqual(q.SecretKeys(), []string{"new", "token"}) || q.Secrets["token"] != SecretRedacted {
+		t.Errorf("expected dropping transient values to redact secrets, got: %v", q.Secrets)
+	}
+}
+
+func TestTransformSecretsEncryption(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	q := &Transform{Secrets: map[string]string{"token": "hunter2", "unset": SecretRedacted}}
+	if err := q.EncryptSecrets([]byte("short")); err == nil {
+		t.Errorf("expected invalid key length to error")
+	}
+	if err := q.EncryptSecrets(key); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Transform{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if err := got.DecryptSecrets([]byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Errorf("expected decrypting with the wrong key to error")
+	}
+	if err := got.DecryptSecrets(key); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{"token": "hunter2", "unset": SecretRedacted}
+	if !reflect.DeepEqual(expect, got.Secrets) {
+		t.Errorf("decrypted secrets mismatch. expected: %v, got: %v", expect, got.Secrets)
+	}
+
+	got.EncryptedSecrets = []byte("abc")
+	if err := got.DecryptSecrets(key); err == nil {
+		t.Errorf("expected short ciphertext to error")
+	}
+}
@@ -0,0 +1,494 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/qri-io/dataset/tabular"
+)
+
+// SyntaxSQL is the transform syntax for SQL queries
+const SyntaxSQL = "sql"
+
+// SQLQuery is the result of parsing an SQL transform script. Parsing is
+// deliberately shallow: it finds the datasets a query reads from and the
+// columns it references without checking the full SQL grammar
+type SQLQuery struct {
+	// Tables lists the datasets a query reads from in order of appearance
+	Tables []SQLTable
+	// Columns lists column references in order of appearance
+	Columns []SQLColumnRef
+	// with holds names defined by common table expressions
+	with map[string]bool
+	// aliases holds names defined for select expressions
+	aliases map[string]bool
+}
+
+// SQLTable is a dataset referenced in a FROM or JOIN clause
+type SQLTable struct {
+	// Ref is the dataset reference, eg. "b5/world_bank_population"
+	Ref string
+	// Alias is the name the table is given in the query, if any
+	Alias string
+}
+
+// SQLColumnRef is a reference to a column within a query
+type SQLColumnRef struct {
+	// Qualifier is the table name or alias a reference is qualified with, if
+	// any
+	Qualifier string
+	// Name of the column
+	Name string
+}
+
+func (c SQLColumnRef) String() string {
+	if c.Qualifier != "" {
+		return c.Qualifier + "." + c.Name
+	}
+	return c.Name
+}
+
+// sqlKeywords are reserved words that are never column references
+var sqlKeywords = map[string]bool{}
+
+func init() {
+	for _, kw := range strings.Fields(`all and any as asc between both by case
+		cast cross desc distinct else end except exists false for from full group
+		having in inner intersect is join leading left like limit natural not null
+		offset on or order outer right select then trailing true union using when
+		where with`) {
+		sqlKeywords[kw] = true
+	}
+}
+
+type sqlTokenType int
+
+const (
+	sqlIdent sqlTokenType = iota
+	sqlQuotedIdent
+	sqlString
+	sqlNumber
+	sqlPunct
+)
+
+type sqlToken struct {
+	typ sqlTokenType
+	val string
+	// pos & end are byte offsets of the token in the query
+	pos, end int
+}
+
+func (t sqlToken) keyword(kw string) bool {
+	return t.typ == sqlIdent && strings.EqualFold(t.val, kw)
+}
+
+func (t sqlToken) punct(p string) bool {
+	return t.typ == sqlPunct && t.val == p
+}
+
+// name is true for tokens that can name a column or table
+func (t sqlToken) name() bool {
+	return t.typ == sqlQuotedIdent || t.typ == sqlIdent && !sqlKeywords[strings.ToLower(t.val)]
+}
+
+func tokenizeSQL(query string) ([]sqlToken, error) {
+	var toks []sqlToken
+	rs := []rune(query)
+	// byte offsets of each rune
+	offsets := make([]int, len(rs)+1)
+	o := 0
+	for i, r := range rs {
+		offsets[i] = o
+		o += len(string(r))
+	}
+	offsets[len(rs)] = o
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			continue
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			for i += 2; i+1 < len(rs) && !(rs[i] == '*' && rs[i+1] == '/'); i++ {
+			}
+			if i+1 >= len(rs) {
+				return nil, fmt.Errorf("unterminated comment at position %d", offsets[start])
+			}
+			i += 2
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			i++
+			var sb strings.Builder
+			for {
+				if i >= len(rs) {
+					return nil, fmt.Errorf("unterminated quote at position %d", offsets[start])
+				}
+				if rs[i] == r {
+					// doubled quotes are escapes
+					if i+1 < len(rs) && rs[i+1] == r {
+						sb.WriteRune(r)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(rs[i])
+				i++
+			}
+			typ := sqlQuotedIdent
+			if r == '\'' {
+				typ = sqlString
+			}
+			toks = append(toks, sqlToken{typ, sb.String(), offsets[start], offsets[i]})
+			continue
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(rs) && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_') {
+				i++
+			}
+			toks = append(toks, sqlToken{sqlIdent, string(rs[start:i]), offsets[start], offsets[i]})
+			continue
+		case unicode.IsDigit(r):
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.') {
+				i++
+			}
+			toks = append(toks, sqlToken{sqlNumber, string(rs[start:i]), offsets[start], offsets[i]})
+			continue
+		default:
+			i++
+			toks = append(toks, sqlToken{sqlPunct, string(r), offsets[start], offsets[i]})
+		}
+	}
+	return toks, nil
+}
+
+// ParseSQL parses an SQL query, extracting referenced tables & columns
+func ParseSQL(query string) (*SQLQuery, error) {
+	toks, err := tokenizeSQL(query)
+	if err != nil {
+		return nil, err
+	}
+	q := &SQLQuery{with: map[string]bool{}, aliases: map[string]bool{}}
+	// tableNames marks token indices that are part of a table reference or
+	// alias, or are otherwise not column references
+	tableNames := map[int]bool{}
+	// calls tracks open parentheses, true for those of function calls
+	var calls []bool
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch {
+		case t.punct("("):
+			call := i > 0 && toks[i-1].name() && !tableNames[i-1]
+			calls = append(calls, call)
+			if call && strings.EqualFold(toks[i-1].val, "extract") && i+1 < len(toks) {
+				// the first argument of extract is a date field, not a column
+				tableNames[i+1] = true
+			}
+		case t.punct(")"):
+			if len(calls) > 0 {
+				calls = calls[:len(calls)-1]
+			}
+		case t.keyword("from") && len(calls) > 0 && calls[len(calls)-1]:
+			// FROM separates function arguments, eg. substring(s FROM 2)
+		case t.keyword("with"):
+			// WITH name AS (...), name AS (...). the bodies of common table
+			// expressions are scanned as part of the enclosing query
+			for j := i + 1; j < len(toks) && toks[j].name(); {
+				q.with[strings.ToLower(toks[j].val)] = true
+				tableNames[j] = true
+				if j++; j < len(toks) && toks[j].keyword("as") {
+					j++
+				}
+				j = skipParens(toks, j)
+				if j >= len(toks) || !toks[j].punct(",") {
+					break
+				}
+				j++
+			}
+		case t.keyword("from") || t.keyword("join"):
+			j := i + 1
+			for {
+				if j >= len(toks) {
+					return nil, fmt.Errorf("expected table reference after %s", strings.ToUpper(t.val))
+				}
+				if toks[j].punct("(") {
+					// subqueries are scanned as part of the enclosing query
+					break
+				}
+				ref, next := readTableRef(toks, j)
+				if ref == "" {
+					return nil, fmt.Errorf("expected table reference after %s at position %d", strings.ToUpper(t.val), toks[j].pos)
+				}
+				for k := j; k < next; k++ {
+					tableNames[k] = true
+				}
+				j = next
+				tbl := SQLTable{Ref: ref}
+				if j < len(toks) && toks[j].keyword("as") {
+					j++
+				}
+				if j < len(toks) && toks[j].name() {
+					tbl.Alias = toks[j].val
+					tableNames[j] = true
+					j++
+				}
+				if q.with[strings.ToLower(ref)] {
+					if tbl.Alias != "" {
+						q.with[strings.ToLower(tbl.Alias)] = true
+					}
+				} else {
+					q.Tables = append(q.Tables, tbl)
+				}
+				if !(t.keyword("from") && j < len(toks) && toks[j].punct(",")) {
+					break
+				}
+				j++
+			}
+			i = j - 1
+		case t.keyword("as"):
+			if i+1 < len(toks) && toks[i+1].name() && !tableNames[i+1] {
+				q.aliases[strings.ToLower(toks[i+1].val)] = true
+				tableNames[i+1] = true
+			}
+		}
+	}
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if tableNames[i] || !t.name() {
+			continue
+		}
+		// function calls
+		if i+1 < len(toks) && toks[i+1].punct("(") {
+			continue
+		}
+		// qualified references
+		if i+2 < len(toks) && toks[i+1].punct(".") {
+			if toks[i+2].name() {
+				q.Columns = append(q.Columns, SQLColumnRef{Qualifier: t.val, Name: toks[i+2].val})
+			}
+			i += 2
+			continue
+		}
+		if q.aliases[strings.ToLower(t.val)] {
+			continue
+		}
+		q.Columns = append(q.Columns, SQLColumnRef{Name: t.val})
+	}
+
+	return q, nil
+}
+
+// skipParens returns the index of the token after a balanced parenthesized
+// group starting at i, or i if toks[i] doesn't open a group
+func skipParens(toks []sqlToken, i int) int {
+	if i >= len(toks) || !toks[i].punct("(") {
+		return i
+	}
+	depth := 0
+	for ; i < len(toks); i++ {
+		if toks[i].punct("(") {
+			depth++
+		} else if toks[i].punct(")") {
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// readTableRef reads a table reference starting at token i. references can
+// contain dataset reference characters like "b5/population@/ipfs/QmHash", so
+// any run of adjacent name & joining punctuation tokens is read as one
+// reference. returns the reference & the index of the next token
+func readTableRef(toks []sqlToken, i int) (string, int) {
+	if i >= len(toks) {
+		return "", i
+	}
+	if toks[i].typ == sqlQuotedIdent {
+		return toks[i].val, i + 1
+	}
+	var sb strings.Builder
+	j := i
+	for ; j < len(toks); j++ {
+		t := toks[j]
+		if j > i && t.pos != toks[j-1].end {
+			break
+		}
+		joiner := t.typ == sqlPunct && strings.Contains("/@.-:", t.val)
+		if !(joiner || t.typ == sqlNumber || t.typ == sqlIdent && (j > i || t.name())) {
+			break
+		}
+		sb.WriteString(t.val)
+	}
+	return sb.String(), j
+}
+
+// resourceKey generates the alphabetical key for the i-th resource: a, b, ...
+// z, aa, ab ...
+func resourceKey(i int) string {
+	key := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		key = string(rune('a'+(i-1)%26)) + key
+	}
+	return key
+}
+
+// SQLQuery parses the transform script as an SQL query
+func (q *Transform) SQLQuery() (*SQLQuery, error) {
+	if !strings.EqualFold(q.Syntax, SyntaxSQL) {
+		return nil, fmt.Errorf("transform syntax '%s' is not %s", q.Syntax, SyntaxSQL)
+	}
+	if q.ScriptBytes == nil {
+		return nil, fmt.Errorf("sql transforms require scriptBytes")
+	}
+	return ParseSQL(string(q.ScriptBytes))
+}
+
+// ExtractSQLResources parses an SQL transform & adds each dataset the query
+// reads from to Resources, with the next free alphabetical key in order of
+// appearance. Datasets that are already a resource are kept as-is, so
+// resource names & version pins aren't lost
+func (q *Transform) ExtractSQLResources() error {
+	query, err := q.SQLQuery()
+	if err != nil {
+		return err
+	}
+	if q.Resources == nil {
+		q.Resources = map[string]*TransformResource{}
+	}
+	seen := map[string]bool{}
+	for _, r := range q.Resources {
+		if r != nil {
+			seen[r.Path] = true
+		}
+	}
+	n := 0
+	for _, t := range query.Tables {
+		if seen[t.Ref] {
+			continue
+		}
+		seen[t.Ref] = true
+		for q.Resources[resourceKey(n)] != nil {
+			n++
+		}
+		q.Resources[resourceKey(n)] = &TransformResource{Path: t.Ref}
+	}
+	return nil
+}
+
+// ValidateSQLColumns checks column references in an SQL transform against
+// the structures of the datasets it reads, keyed by table reference.
+// Qualified references must name a column of their table, unqualified
+// references must name a column of at least one table. Tables without a
+// structure are not checked
+func (q *Transform) ValidateSQLColumns(structures map[string]*Structure) error {
+	query, err := q.SQLQuery()
+	if err != nil {
+		return err
+	}
+
+	// columns for each table, keyed by lower-cased reference & alias
+	tables := map[string]map[string]bool{}
+	// common table expressions have unknown columns
+	unchecked := map[string]bool{}
+	for name := range query.with {
+		unchecked[name] = true
+	}
+	all := map[string]bool{}
+	for _, t := range query.Tables {
+		names := []string{strings.ToLower(t.Ref)}
+		if t.Alias != "" {
+			names = append(names, strings.ToLower(t.Alias))
+		}
+		// the last segment of a reference is usable as a qualifier,
+		// eg. "population" for "b5/population"
+		if idx := strings.LastIndex(t.Ref, "/"); idx >= 0 && t.Alias == "" {
+			names = append(names, strings.ToLower(t.Ref[idx+1:]))
+		}
+
+		st, ok := structures[t.Ref]
+		if !ok || st == nil || st.Schema == nil {
+			for _, n := range names {
+				unchecked[n] = true
+			}
+			continue
+		}
+		cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+		if err != nil {
+			return fmt.Errorf("table '%s': %s", t.Ref, err)
+		}
+		set := map[string]bool{}
+		for _, title := range cols.Titles() {
+			set[strings.ToLower(title)] = true
+			all[strings.ToLower(title)] = true
+		}
+		for _, n := range names {
+			tables[n] = set
+		}
+	}
+
+	for _, c := range query.Columns {
+		name := strings.ToLower(c.Name)
+		if c.Qualifier != "" {
+			qual := strings.ToLower(c.Qualifier)
+			if unchecked[qual] {
+				continue
+			}
+			set, ok := tables[qual]
+			if !ok {
+				return fmt.Errorf("column '%s': unknown table '%s'", c, c.Qualifier)
+			}
+			if !set[name] {
+				return fmt.Errorf("column '%s': table '%s' has no column '%s'", c, c.Qualifier, c.Name)
+			}
+			continue
+		}
+		if len(unchecked) > 0 || all[name] {
+			continue
+		}
+		return fmt.Errorf("unknown column '%s'", c.Name)
+	}
+	return nil
+}
+
+// validateSQLTransform checks an inline SQL transform parses, and that each
+// dataset it reads from is listed in Resources by path or name when resources
+// are defined
+func validateSQLTransform(q *Transform) error {
+	if !strings.EqualFold(q.Syntax, SyntaxSQL) || q.ScriptBytes == nil {
+		return nil
+	}
+	query, err := q.SQLQuery()
+	if err != nil {
+		return fmt.Errorf("sql: %s", err)
+	}
+	if q.Resources == nil {
+		return nil
+	}
+	paths := map[string]bool{}
+	for _, r := range q.Resources {
+		if r != nil {
+			paths[r.Path] = true
+			if r.Name != "" {
+				paths[r.Name] = true
+			}
+		}
+	}
+	for _, t := range query.Tables {
+		if !paths[t.Ref] {
+			return fmt.Errorf("sql: table '%s' is not a transform resource", t.Ref)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSQL(t *testing.T) {
+	cases := []struct {
+		query   string
+		tables  []SQLTable
+		columns []SQLColumnRef
+	}{
+		{
+			"SELECT stop_id, stop_name FROM mfdz/stops WHERE stop_lat > 48.5",
+			[]SQLTable{{Ref: "mfdz/stops"}},
+			[]SQLColumnRef{{Name: "stop_id"}, {Name: "stop_name"}, {Name: "stop_lat"}},
+		},
+		{
+			`select s.stop_name, count(*) as departures -- per stop
+			from mfdz/stops as s
+			join mfdz/stop_times@/ipfs/QmHash st on st.stop_id = s.stop_id
+			group by s.stop_name order by departures desc`,
+			[]SQLTable{{Ref: "mfdz/stops", Alias: "s"}, {Ref: "mfdz/stop_times@/ipfs/QmHash", Alias: "st"}},
+			[]SQLColumnRef{{"s", "stop_name"}, {"st", "stop_id"}, {"s", "stop_id"}, {"s", "stop_name"}},
+		},
+		{
+			`WITH busy AS (SELECT route_id FROM "mfdz/routes" /* quoted */ WHERE route_type = 3)
+			SELECT b.route_id, 'it''s' FROM busy b, mfdz/trips t WHERE t.route_id = b.route_id`,
+			[]SQLTable{{Ref: "mfdz/routes"}, {Ref: "mfdz/trips", Alias: "t"}},
+			[]SQLColumnRef{{Name: "route_id"}, {Name: "route_type"}, {"b", "route_id"}, {"t", "route_id"}, {"b", "route_id"}},
+		},
+		{
+			`SELECT extract(year FROM t.arrival), substring(stop_name FROM 2 FOR 3), trim(leading '0' from stop_code)
+			FROM mfdz/stop_times t WHERE stop_id IN (SELECT stop_id FROM mfdz/stops)`,
+			[]SQLTable{{Ref: "mfdz/stop_times", Alias: "t"}, {Ref: "mfdz/stops"}},
+			[]SQLColumnRef{{"t", "arrival"}, {Name: "stop_name"}, {Name: "stop_code"}, {Name: "stop_id"}, {Name: "stop_id"}},
+		},
+		{
+			"SELECT coalesce((SELECT max(x) FROM mfdz/a), 0) FROM mfdz/b",
+			[]SQLTable{{Ref: "mfdz/a"}, {Ref: "mfdz/b"}},
+			[]SQLColumnRef{{Name: "x"}},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ParseSQL(c.query)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.tables, got.Tables) {
+			t.Errorf("case %d tables mismatch. expected: %v, got: %v", i, c.tables, got.Tables)
+		}
+		if !reflect.DeepEqual(c.columns, got.Columns) {
+			t.Errorf("case %d columns mismatch. expected: %v, got: %v", i, c.columns, got.Columns)
+		}
+	}
+
+	for i, bad := range []string{"SELECT 'unterminated", "SELECT * FROM", "SELECT * FROM WHERE", "SELECT /* oops"} {
+		if _, err := ParseSQL(bad); err == nil {
+			t.Errorf("bad case %d: expected error parsing %q", i, bad)
+		}
+	}
+}
+
+func TestTransformExtractSQLResources(t *testing.T) {
+	q := &Transform{
+		Syntax:      "sql",
+		ScriptBytes: []byte("select * from mfdz/stops s join mfdz/stop_times t on s.stop_id = t.stop_id join mfdz/stops p on p.parent_station = s.stop_id"),
+	}
+	if err := q.ExtractSQLResources(); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]*TransformResource{
+		"a": {Path: "mfdz/stops"},
+		"b": {Path: "mfdz/stop_times"},
+	}
+	if !reflect.DeepEqual(expect, q.Resources) {
+		t.Errorf("resources mismatch. expected: %v, got: %v", expect, q.Resources)
+	}
+
+	q = &Transform{
+		Syntax:      "sql",
+		ScriptBytes: []byte("select * from mfdz/stops s join mfdz/stop_times t on s.stop_id = t.stop_id join mfdz/trips r on r.trip_id = t.trip_id"),
+		Resources: map[string]*TransformResource{
+			"a":     {Path: "mfdz/routes"},
+			"stops": {Name: "stops", Path: "mfdz/stops", Version: "/ipfs/QmStops"},
+		},
+	}
+	if err := q.ExtractSQLResources(); err != nil {
+		t.Fatal(err)
+	}
+	expect = map[string]*TransformResource{
+		"a":     {Path: "mfdz/routes"},
+		"b":     {Path: "mfdz/stop_times"},
+		"c":     {Path: "mfdz/trips"},
+		"stops": {Name: "stops", Path: "mfdz/stops", Version: "/ipfs/QmStops"},
+	}
+	if !reflect.DeepEqual(expect, q.Resources) {
+		t.Errorf("merged resources mismatch. expected: %v, got: %v", expect, q.Resources)
+	}
+
+	if err := (&Transform{Syntax: "starlark", ScriptBytes: []byte("x")}).ExtractSQLResources(); err == nil {
+		t.Errorf("expected non-sql transform to error")
+	}
+
+	for i, expect := range map[int]string{0: "a", 25: "z", 26: "aa", 27: "ab", 52: "ba"} {
+		if got := resourceKey(i); got != expect {
+			t.Errorf("resourceKey(%d) mismatch. expected: %s, got: %s", i, expect, got)
+		}
+	}
+}
+
+func TestTransformValidateSQLColumns(t *testing.T) {
+	schema := func(cols ...string) *Structure {
+		items := make([]interface{}, len(cols))
+		for i, c := range cols {
+			items[i] = map[string]interface{}{"title": c, "type": "string"}
+		}
+		return &Structure{Schema: map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": items},
+		}}
+	}
+	structures := map[string]*Structure{
+		"mfdz/stops":      schema("stop_id", "stop_name"),
+		"mfdz/stop_times": schema("stop_id", "trip_id"),
+	}
+
+	cases := []struct {
+		query string
+		err   string
+	}{
+		{"select stop_id, stop_name from mfdz/stops", ""},
+		{"select s.stop_name, t.trip_id from mfdz/stops s join mfdz/stop_times t on s.stop_id = t.stop_id", ""},
+		{"select stops.stop_name from mfdz/stops", ""},
+		{"select x.anything from mfdz/unknown x", ""},
+		{"select stop_lat from mfdz/stops", "unknown column 'stop_lat'"},
+		{"select s.trip_id from mfdz/stops s join mfdz/stop_times t on s.stop_id = t.stop_id", "column 's.trip_id': table 's' has no column 'trip_id'"},
+		{"select q.stop_id from mfdz/stops s", "column 'q.stop_id': unknown table 'q'"},
+		{"with x as (select stop_id from mfdz/stops) select y.foo, bar from x y", ""},
+	}
+
+	for i, c := range cases {
+		q := &Transform{Syntax: "sql", ScriptBytes: []byte(c.query)}
+		err := q.ValidateSQLColumns(structures)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+	"gopkg.in/yaml.v2"
+)
+
+// SyntaxStarlark is the transform syntax for starlark scripts
+const SyntaxStarlark = "starlark"
+
+// StarlarkScript is the result of reading the header of a starlark transform
+// script: the leading comments, blank lines & load statements. Scripts
+// declare the config they accept with a YAML JSON schema under a "config"
+// key in their leading comments:
+//
+//	# config:
+//	#   type: object
+//	#   required: [region]
+//	#   properties:
+//	#     region: { type: string }
+//	load("http.star", "http")
+//	load("./lib/gtfs.star", parse = "parse_feed")
+//
+// The header is read without executing or fully parsing the script
+type StarlarkScript struct {
+	// ConfigSchema is the declared config schema, nil if none is declared
+	ConfigSchema *jsonschema.RootSchema
+	// Loads are the load statements of the header, in order
+	Loads []StarlarkLoad
+}
+
+// StarlarkLoad is a load statement
+type StarlarkLoad struct {
+	// Module is the loaded module, eg. "http.star"
+	Module string
+	// Symbols maps names bound by the statement to the names they load
+	Symbols map[string]string
+	// Line the statement starts on, starting at 1
+	Line int
+}
+
+// Local is true for loads of modules relative to the transform module root,
+// which must be transform files
+func (l StarlarkLoad) Local() bool {
+	return strings.HasPrefix(l.Module, "./") || strings.HasPrefix(l.Module, "../")
+}
+
+// FileName gives the transform file name of a local load
+func (l StarlarkLoad) FileName() string {
+	return path.Clean(l.Module)
+}
+
+// ParseStarlarkHeader reads the header of a starlark script
+func ParseStarlarkHeader(script []byte) (*StarlarkScript, error) {
+	s := &StarlarkScript{}
+	src := string(script)
+	var comments []string
+	line := 1
+	for i := 0; i < len(src); {
+		end := strings.IndexByte(src[i:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += i
+		}
+		text := strings.TrimSpace(src[i:end])
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "#"):
+			comment := strings.TrimPrefix(strings.TrimRight(src[i:end], " \t\r"), "#")
+			comments = append(comments, strings.TrimPrefix(comment, " "))
+		case strings.HasPrefix(src[i:], "load("):
+			ld, n, err := parseStarlarkLoad(src[i:], line)
+			if err != nil {
+				return nil, err
+			}
+			s.Loads = append(s.Loads, ld)
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+			continue
+		default:
+			// the header ends at the first statement that isn't a load
+			i = len(src)
+			continue
+		}
+		line++
+		i = end + 1
+	}
+
+	schema, err := starlarkConfigSchema(comments)
+	if err != nil {
+		return nil, fmt.Errorf("config schema: %s", err)
+	}
+	s.ConfigSchema = schema
+	return s, nil
+}
+
+// starlarkConfigSchema reads the config key of header comments as YAML
+func starlarkConfigSchema(comments []string) (*jsonschema.RootSchema, error) {
+	start := -1
+	for i, c := range comments {
+		if strings.HasPrefix(c, "config:") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, nil
+	}
+	lines := []string{comments[start]}
+	for _, c := range comments[start+1:] {
+		if c != "" && c[0] != ' ' && c[0] != '\t' {
+			break
+		}
+		lines = append(lines, c)
+	}
+
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &doc); err != nil {
+		return nil, err
+	}
+	v, err := jsonCompatible(doc["config"])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := v.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("expected an object, got %T", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	rs := &jsonschema.RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// parseStarlarkLoad reads a load statement at the start of src, returning
+// the number of bytes read
+func parseStarlarkLoad(src string, line int) (StarlarkLoad, int, error) {
+	ld := StarlarkLoad{Symbols: map[string]string{}, Line: line}
+	// args holds strings & identifiers, with "=" marking aliases
+	var args []string
+	var quoted []bool
+	i := len("load(")
+	for {
+		if i >= len(src) {
+			return ld, 0, fmt.Errorf("line %d: unterminated load statement", line)
+		}
+		c := src[i]
+		switch {
+		case c == ')':
+			i++
+			if err := ld.bind(args, quoted); err != nil {
+				return ld, 0, fmt.Errorf("line %d: %s", line, err)
+			}
+			return ld, i, nil
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			s, n, err := readStarlarkString(src[i:])
+			if err != nil {
+				return ld, 0, fmt.Errorf("line %d: %s", line, err)
+			}
+			args = append(args, s)
+			quoted = append(quoted, true)
+			i += n
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			args = append(args, src[i:j])
+			quoted = append(quoted, false)
+			i = j
+		case c == '=':
+			args = append(args, "=")
+			quoted = append(quoted, false)
+			i++
+		case c == ',' || c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		default:
+			return ld, 0, fmt.Errorf("line %d: unexpected %q in load statement", line, c)
+		}
+	}
+}
+
+// bind assigns the arguments of a load statement
+func (l *StarlarkLoad) bind(args []string, quoted []bool) error {
+	if len(args) == 0 || !quoted[0] {
+		return fmt.Errorf("load requires a module string")
+	}
+	l.Module = args[0]
+	for i := 1; i < len(args); i++ {
+		switch {
+		case quoted[i]:
+			l.Symbols[args[i]] = args[i]
+		case i+2 < len(args) && args[i+1] == "=" && quoted[i+2]:
+			l.Symbols[args[i]] = args[i+2]
+			i += 2
+		default:
+			return fmt.Errorf("invalid load argument %q", args[i])
+		}
+	}
+	if len(l.Symbols) == 0 {
+		return fmt.Errorf("load of %q binds no symbols", l.Module)
+	}
+	return nil
+}
+
+// readStarlarkString reads a quoted string literal at the start of src,
+// returning the number of bytes read
+func readStarlarkString(src string) (string, int, error) {
+	q := src[0]
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case q:
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(src) {
+				i++
+				sb.WriteByte(src[i])
+			}
+		case '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// StarlarkScript reads the header of a starlark transform script
+func (q *Transform) StarlarkScript() (*StarlarkScript, error) {
+	if !strings.EqualFold(q.Syntax, SyntaxStarlark) {
+		return nil, fmt.Errorf("transform syntax '%s' is not %s", q.Syntax, SyntaxStarlark)
+	}
+	if q.ScriptBytes == nil {
+		return nil, fmt.Errorf("starlark transforms require scriptBytes")
+	}
+	return ParseStarlarkHeader(q.ScriptBytes)
+}
+
+// StarlarkDependencies lists the modules an inline starlark transform loads,
+// in order of first appearance
+func (q *Transform) StarlarkDependencies() ([]string, error) {
+	s, err := q.StarlarkScript()
+	if err != nil {
+		return nil, err
+	}
+	var deps []string
+	seen := map[string]bool{}
+	for _, l := range s.Loads {
+		if !seen[l.Module] {
+			seen[l.Module] = true
+			deps = append(deps, l.Module)
+		}
+	}
+	return deps, nil
+}
+
+// validateStarlarkTransform checks the header of an inline starlark
+// transform reads, and that every local module it loads is a transform file
+func validateStarlarkTransform(q *Transform) error {
+	if !strings.EqualFold(q.Syntax, SyntaxStarlark) || q.ScriptBytes == nil {
+		return nil
+	}
+	s, err := q.StarlarkScript()
+	if err != nil {
+		return fmt.Errorf("starlark: %s", err)
+	}
+	for _, l := range s.Loads {
+		if !l.Local() {
+			continue
+		}
+		if _, ok := q.Files[l.FileName()]; !ok {
+			return fmt.Errorf("starlark: line %d: loaded module '%s' is not a transform file", l.Line, l.Module)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package dataset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const starlarkScript = `#!/usr/bin/env qri
+# bus stops of a region
+#
+# config:
+#   type: object
+#   required: [region]
+#   properties:
+#     region: { type: string, enum: [bw, by] }
+#     limit: { type: integer }
+# that's all
+load("http.star", "http")
+load(
+    "./lib/gtfs.star",  # feed parsing
+    parse = "parse_feed",
+    "stops",
+)
+
+def transform(ds, ctx):
+    load("late.star", "late")
+`
+
+func TestParseStarlarkHeader(t *testing.T) {
+	s, err := ParseStarlarkHeader([]byte(starlarkScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []StarlarkLoad{
+		{Module: "http.star", Symbols: map[string]string{"http": "http"}, Line: 11},
+		{Module: "./lib/gtfs.star", Symbols: map[string]string{"parse": "parse_feed", "stops": "stops"}, Line: 12},
+	}
+	if !reflect.DeepEqual(expect, s.Loads) {
+		t.Errorf("loads mismatch.\nexpected: %#v\ngot:      %#v", expect, s.Loads)
+	}
+	if s.ConfigSchema == nil {
+		t.Fatal("expected a config schema")
+	}
+	if errs, err := s.ConfigSchema.ValidateBytes([]byte(`{"region":"nrw"}`)); err != nil || len(errs) != 1 {
+		t.Errorf("expected declared schema to reject config, got: %v %v", errs, err)
+	}
+	if !s.Loads[1].Local() || s.Loads[1].FileName() != "lib/gtfs.star" || s.Loads[0].Local() {
+		t.Errorf("expected only ./lib/gtfs.star to be local")
+	}
+
+	s, err = ParseStarlarkHeader([]byte("x = 1\n# config: {type: object}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConfigSchema != nil || len(s.Loads) != 0 {
+		t.Errorf("expected comments after the header to be ignored")
+	}
+
+	cases := []struct {
+		script string
+		err    string
+	}{
+		{`load("http.star"`, "line 1: unterminated load statement"},
+		{`load("http.star)`, "line 1: unterminated string"},
+		{"\nload(http)", "line 2: load requires a module string"},
+		{`load("http.star")`, `line 1: load of "http.star" binds no symbols`},
+		{`load("http.star", h = http)`, `line 1: invalid load argument "h"`},
+		{`load("http.star", *)`, `line 1: unexpected '*' in load statement`},
+		{"# config: [1, 2]", "config schema: expected an object, got []interface {}"},
+		{"# config:\n#  type: [", "config schema: yaml: line 2: did not find expected node content"},
+	}
+	for i, c := range cases {
+		_, err := ParseStarlarkHeader([]byte(c.script))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
+
+func TestTransformStarlarkValidate(t *testing.T) {
+	valid := func() *Transform {
+		return &Transform{
+			Syntax:      SyntaxStarlark,
+			ScriptBytes: []byte(starlarkScript),
+			Config:      map[string]interface{}{"region": "bw", "limit": 10},
+			Files:       map[string]*TransformFile{"lib/gtfs.star": {ScriptBytes: []byte("def parse_feed(): pass")}},
+		}
+	}
+	ctx := context.Background()
+	if err := valid().Validate(ctx, nil); err != nil {
+		t.Errorf("expected valid transform to pass. got: %s", err)
+	}
+	deps, err := valid().StarlarkDependencies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deps, []string{"http.star", "./lib/gtfs.star"}) {
+		t.Errorf("dependencies mismatch, got: %v", deps)
+	}
+
+	cases := []struct {
+		description string
+		change      func(q *Transform)
+		err         string
+	}{
+		{"missing config", func(q *Transform) { q.Config = nil }, `config: /: {} "region" value is required`},
+		{"invalid config", func(q *Transform) { q.Config["region"] = "nrw" }, `config: /region: "nrw" should be one of ["bw", "by"]`},
+		{"missing file", func(q *Transform) { q.Files = nil }, "starlark: line 12: loaded module './lib/gtfs.star' is not a transform file"},
+		{"unreadable header", func(q *Transform) { q.ScriptBytes = []byte("load(") }, "starlark: line 1: unterminated load statement"},
+		{"step config", func(q *Transform) {
+			q.Steps = []*TransformStep{{Name: "fetch", ScriptBytes: []byte(starlarkScript), Config: map[string]interface{}{"limit": "ten"}}}
+		}, `step 'fetch': config: /: {"limit":"ten"} "region" value is required`},
+	}
+	for _, c := range cases {
+		q := valid()
+		c.change(q)
+		err := q.Validate(ctx, nil)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case '%s' error mismatch. expected: '%s', got: '%s'", c.description, c.err, err)
+		}
+	}
+}
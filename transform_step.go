@@ -0,0 +1,178 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/qfs"
+)
+
+// TransformStep is a single stage of a multi-step transform pipeline. Steps
+// execute in order, each receiving the output of the step before it, so a
+// pipeline can be split into clean, join & aggregate stages written in
+// different syntaxes
+type TransformStep struct {
+	// Config outlines any configuration specific to this step
+	Config map[string]interface{} `json:"config,omitempty"`
+	// Name uniquely identifies this step within a transform
+	Name string `json:"name"`
+
+	// script file reader, doesn't serialize
+	scriptFile qfs.File
+	// ScriptBytes is for representing a script as a slice of bytes, transient
+	ScriptBytes []byte `json:"scriptBytes,omitempty"`
+	// ScriptPath is the path to the script for this step
+	ScriptPath string `json:"scriptPath,omitempty"`
+	// Syntax this step is written in
+	Syntax string `json:"syntax,omitempty"`
+}
+
+// transformStep is a private struct for marshaling into & out of
+type transformStep TransformStep
+
+// UnmarshalJSON implements json.Unmarshaler, accepting inlined plain-text
+// scripts in addition to scriptBytes
+func (s *TransformStep) UnmarshalJSON(data []byte) error {
+	_s := transformStep{}
+	if err := json.Unmarshal(data, &_s); err != nil {
+		return err
+	}
+	*s = TransformStep(_s)
+	script, err := decodeInlineScript(data)
+	if err != nil {
+		return err
+	}
+	if script != nil {
+		s.ScriptBytes = script
+	}
+	return nil
+}
+
+// OpenScriptFile generates a byte stream of script data prioritizing creating an
+// in-place file from ScriptBytes when defined, fetching from the
+// passed-in resolver otherwise
+func (s *TransformStep) OpenScriptFile(ctx context.Context, resolver qfs.PathResolver) (err error) {
+	if src := s.ScriptSource(resolver); src != nil {
+		s.scriptFile, err = src.Open(ctx)
+	}
+	return err
+}
+
+// SetScriptFile assigns the scriptFile
+func (s *TransformStep) SetScriptFile(file qfs.File) {
+	s.scriptFile = file
+}
+
+// ScriptFile gives the internal file, if any. Callers that use the file in any
+// way (eg. by calling Read) should consume the entire file and call Close
+func (s *TransformStep) ScriptFile() qfs.File {
+	return s.scriptFile
+}
+
+// Assign collapses all properties of a group of steps onto one. this is
+// directly inspired by Javascript's Object.assign
+func (s *TransformStep) Assign(steps ...*TransformStep) {
+	for _, s2 := range steps {
+		if s2 == nil {
+			continue
+		}
+
+		if s2.Config != nil {
+			if s.Config == nil {
+				s.Config = map[string]interface{}{}
+			}
+			for key, val := range s2.Config {
+				s.Config[key] = val
+			}
+		}
+		if s2.Name != "" {
+			s.Name = s2.Name
+		}
+		if s2.scriptFile != nil {
+			s.scriptFile = s2.scriptFile
+		}
+		if s2.ScriptBytes != nil {
+			s.ScriptBytes = s2.ScriptBytes
+		}
+		if s2.ScriptPath != "" {
+			s.ScriptPath = s2.ScriptPath
+		}
+		if s2.Syntax != "" {
+			s.Syntax = s2.Syntax
+		}
+	}
+}
+
+// Clone returns a deep copy of a step. The script file is not copied
+func (s *TransformStep) Clone() *TransformStep {
+	if s == nil {
+		return nil
+	}
+	return &TransformStep{
+		Config:      cloneMap(s.Config),
+		Name:        s.Name,
+		ScriptBytes: cloneBytes(s.ScriptBytes),
+		ScriptPath:  s.ScriptPath,
+		Syntax:      s.Syntax,
+	}
+}
+
+// Step gets a pipeline step by name, nil if no step with that name exists
+func (q *Transform) Step(name string) *TransformStep {
+	for _, s := range q.Steps {
+		if s != nil && s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// assignSteps merges steps onto a transform by name. Steps that share a name
+// with an existing step are assigned onto it, unknown steps are appended
+func (q *Transform) assignSteps(steps []*TransformStep) {
+	for _, s := range steps {
+		if s == nil {
+			continue
+		}
+		if existing := q.Step(s.Name); existing != nil && s.Name != "" {
+			existing.Assign(s)
+			continue
+		}
+		q.Steps = append(q.Steps, s)
+	}
+}
+
+// OpenStepScriptFiles opens the script file of each pipeline step
+func (q *Transform) OpenStepScriptFiles(ctx context.Context, resolver qfs.PathResolver) error {
+	for i, s := range q.Steps {
+		if s == nil {
+			continue
+		}
+		if err := s.OpenScriptFile(ctx, resolver); err != nil {
+			return fmt.Errorf("step %d '%s': %s", i, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateSteps checks each step has a unique name & a script
+func validateSteps(steps []*TransformStep) error {
+	names := map[string]bool{}
+	for i, s := range steps {
+		if s == nil {
+			return fmt.Errorf("steps index %d is empty", i)
+		}
+		if s.Name == "" {
+			return fmt.Errorf("steps index %d: name is required", i)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("steps index %d: duplicate step name '%s'", i, s.Name)
+		}
+		if s.ScriptPath == "" && s.ScriptBytes == nil {
+			return fmt.Errorf("step '%s': scriptPath or scriptBytes is required", s.Name)
+		}
+		names[s.Name] = true
+	}
+	return nil
+}
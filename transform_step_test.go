@@ -0,0 +1,84 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestTransformStepsJSON(t *testing.T) {
+	q := &Transform{Steps: []*TransformStep{
+		{Name: "clean", Syntax: "starlark", ScriptPath: "/ipfs/QmClean"},
+		{Name: "aggregate", Syntax: "sql", ScriptBytes: []byte("select 1"), Config: map[string]interface{}{"limit": float64(10)}},
+	}}
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Transform{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	q.Qri = KindTransform.String()
+	if !reflect.DeepEqual(q, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", q, got)
+	}
+
+	got.DropTransientValues()
+	if got.Steps[1].ScriptBytes != nil {
+		t.Errorf("expected step script bytes to be dropped")
+	}
+}
+
+func TestTransformStepsAssign(t *testing.T) {
+	q := &Transform{Steps: []*TransformStep{
+		{Name: "clean", Syntax: "starlark", ScriptPath: "/ipfs/QmClean"},
+		{Name: "join", Syntax: "starlark", ScriptPath: "/ipfs/QmJoin"},
+	}}
+	q.Assign(&Transform{Steps: []*TransformStep{
+		{Name: "join", ScriptPath: "/ipfs/QmJoin2"},
+		{Name: "aggregate", Syntax: "sql", ScriptPath: "/ipfs/QmAgg"},
+	}})
+
+	expect := []*TransformStep{
+		{Name: "clean", Syntax: "starlark", ScriptPath: "/ipfs/QmClean"},
+		{Name: "join", Syntax: "starlark", ScriptPath: "/ipfs/QmJoin2"},
+		{Name: "aggregate", Syntax: "sql", ScriptPath: "/ipfs/QmAgg"},
+	}
+	if !reflect.DeepEqual(expect, q.Steps) {
+		t.Errorf("assign mismatch. expected: %v, got: %v", expect, q.Steps)
+	}
+
+	c := q.Clone()
+	c.Steps[0].Name = "changed"
+	if q.Steps[0].Name != "clean" {
+		t.Errorf("clone shares memory with original")
+	}
+}
+
+func TestTransformOpenStepScriptFiles(t *testing.T) {
+	resolver := mapResolver{"/ipfs/QmClean": "def transform(ds, ctx): pass"}
+	q := &Transform{Steps: []*TransformStep{
+		{Name: "clean", ScriptPath: "/ipfs/QmClean"},
+		{Name: "aggregate", ScriptBytes: []byte("select 1")},
+	}}
+	if err := q.OpenStepScriptFiles(context.Background(), resolver); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range q.Steps {
+		data, err := ioutil.ReadAll(s.ScriptFile())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) == 0 {
+			t.Errorf("step '%s': expected script data", s.Name)
+		}
+	}
+
+	q.Steps = append(q.Steps, &TransformStep{Name: "missing", ScriptPath: "/ipfs/QmMissing"})
+	if err := q.OpenStepScriptFiles(context.Background(), resolver); err == nil || err.Error() != "step 2 'missing': path not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
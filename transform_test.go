@@ -198,6 +198,8 @@ func TestTransformIsEmpty(t *testing.T) {
 		{&Transform{Config: map[string]interface{}{}}, false},
 		{&Transform{Resources: nil}, true},
 		{&Transform{Resources: map[string]*TransformResource{}}, false},
+		{&Transform{Anonymize: &AnonymizeSpec{}}, false},
+		{&Transform{Lineage: []*ColumnLineage{}}, false},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,277 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qfs"
+)
+
+// TransformSyntax describes a syntax transforms can be written in, giving
+// hooks to check transforms of that syntax without executing them
+type TransformSyntax struct {
+	// Name of the syntax, matched case-insensitively against Transform.Syntax
+	Name string
+	// ConfigSchema is a JSON schema transform config must conform to. optional
+	ConfigSchema *jsonschema.RootSchema
+	// ScriptConfigSchema reads a JSON schema config must conform to from a
+	// script, for syntaxes where scripts declare the config they accept.
+	// scripts that declare no schema return nil. checked in addition to
+	// ConfigSchema. optional
+	ScriptConfigSchema func(script []byte) (*jsonschema.RootSchema, error)
+	// Check examines a script, erroring if it won't load, eg. because it
+	// doesn't parse. Check must not execute the script. optional
+	Check func(script []byte) error
+}
+
+var (
+	syntaxesLk sync.RWMutex
+	syntaxes   = map[string]TransformSyntax{}
+)
+
+func init() {
+	RegisterTransformSyntax(TransformSyntax{
+		Name: SyntaxSQL,
+		Check: func(script []byte) error {
+			_, err := ParseSQL(string(script))
+			return err
+		},
+	})
+	RegisterTransformSyntax(TransformSyntax{
+		Name: SyntaxMapping,
+		Check: func(script []byte) error {
+			_, err := ParseMapping(script)
+			return err
+		},
+	})
+	RegisterTransformSyntax(TransformSyntax{
+		Name: SyntaxStarlark,
+		Check: func(script []byte) error {
+			_, err := ParseStarlarkHeader(script)
+			return err
+		},
+		ScriptConfigSchema: func(script []byte) (*jsonschema.RootSchema, error) {
+			s, err := ParseStarlarkHeader(script)
+			if err != nil {
+				return nil, err
+			}
+			return s.ConfigSchema, nil
+		},
+	})
+}
+
+// RegisterTransformSyntax adds a syntax to the set of known transform
+// syntaxes. Registering the same syntax twice is an error
+func RegisterTransformSyntax(s TransformSyntax) error {
+	if s.Name == "" {
+		return fmt.Errorf("syntax name is required")
+	}
+	key := strings.ToLower(s.Name)
+	syntaxesLk.Lock()
+	defer syntaxesLk.Unlock()
+	if _, ok := syntaxes[key]; ok {
+		return fmt.Errorf("syntax '%s' is already registered", s.Name)
+	}
+	syntaxes[key] = s
+	return nil
+}
+
+// UnregisterTransformSyntax removes a registered syntax
+func UnregisterTransformSyntax(name string) {
+	syntaxesLk.Lock()
+	defer syntaxesLk.Unlock()
+	delete(syntaxes, strings.ToLower(name))
+}
+
+func registeredSyntax(name string) (TransformSyntax, bool) {
+	syntaxesLk.RLock()
+	defer syntaxesLk.RUnlock()
+	s, ok := syntaxes[strings.ToLower(name)]
+	return s, ok
+}
+
+// Validate performs a dry run of a transform without executing it, returning
+// the first error encountered. Beyond checking the transform document is
+// well-formed, Validate checks the transform syntax is registered, scripts
+// exist & load according to the syntax Check hook, declared resources
+// resolve, and config conforms to the syntax config schema.
+// scripts & resources are fetched from resolver, which may only be nil if
+// the transform has no paths to resolve. URL resources are only checked to be
+// well-formed URLs. transforms that are only a path
+// reference are always considered valid
+func (q *Transform) Validate(ctx context.Context, resolver qfs.PathResolver) error {
+	if q.Path != "" && q.IsEmpty() {
+		return nil
+	}
+	if err := validateTransform(q); err != nil {
+		return err
+	}
+
+	if len(q.Steps) == 0 {
+		syn, err := transformSyntax(q.Syntax)
+		if err != nil {
+			return err
+		}
+		script, err := readScript(ctx, resolver, q.ScriptBytes, q.ScriptPath)
+		if err != nil {
+			return fmt.Errorf("script: %s", err)
+		}
+		if script == nil {
+			return fmt.Errorf("script is required")
+		}
+		if syn.Check != nil && !(q.ScriptBytes != nil && parsedByValidate(q.Syntax)) {
+			if err := syn.Check(script); err != nil {
+				return fmt.Errorf("script: %s", err)
+			}
+		}
+		if err := validateConfig(syn, script, q.Config); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range q.Steps {
+		name := s.Syntax
+		if name == "" {
+			name = q.Syntax
+		}
+		syn, err := transformSyntax(name)
+		if err != nil {
+			return fmt.Errorf("step '%s': %s", s.Name, err)
+		}
+		script, err := readScript(ctx, resolver, s.ScriptBytes, s.ScriptPath)
+		if err != nil {
+			return fmt.Errorf("step '%s': script: %s", s.Name, err)
+		}
+		if syn.Check != nil {
+			if err := syn.Check(script); err != nil {
+				return fmt.Errorf("step '%s': script: %s", s.Name, err)
+			}
+		}
+		if err := validateConfig(syn, script, s.Config); err != nil {
+			return fmt.Errorf("step '%s': %s", s.Name, err)
+		}
+	}
+
+	for _, name := range q.FileNames() {
+		f := q.Files[name]
+		if _, err := readScript(ctx, resolver, f.ScriptBytes, f.Path); err != nil {
+			return fmt.Errorf("file '%s': %s", name, err)
+		}
+	}
+
+	for _, name := range sortedResourceNames(q.Resources) {
+		r := q.Resources[name]
+		if r.IsURL() {
+			// url resources are fetched over the network when a transform runs,
+			// they're never in the store
+			if err := validateResourceURL(r); err != nil {
+				return fmt.Errorf("resource '%s': %s", name, err)
+			}
+			continue
+		}
+		if err := resolvePath(ctx, resolver, r.ResolvedPath()); err != nil {
+			return fmt.Errorf("resource '%s': %s", name, err)
+		}
+	}
+	return nil
+}
+
+// parsedByValidate checks if inline scripts of a syntax are already parsed
+// by validateTransform, and don't need their syntax Check run again
+func parsedByValidate(syntax string) bool {
+	return strings.EqualFold(syntax, SyntaxSQL) || strings.EqualFold(syntax, SyntaxMapping)
+}
+
+func transformSyntax(name string) (TransformSyntax, error) {
+	if name == "" {
+		return TransformSyntax{}, fmt.Errorf("syntax is required")
+	}
+	syn, ok := registeredSyntax(name)
+	if !ok {
+		return syn, fmt.Errorf("unknown syntax '%s'", name)
+	}
+	return syn, nil
+}
+
+// readScript reads script bytes if defined, fetching path from resolver
+// otherwise. returns nil if both are empty
+func readScript(ctx context.Context, resolver qfs.PathResolver, data []byte, path string) ([]byte, error) {
+	if data != nil || path == "" {
+		return data, nil
+	}
+	if resolver == nil {
+		return nil, ErrNoResolver
+	}
+	f, err := resolver.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving '%s': %s", path, err)
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// resolvePath checks a path resolves without reading it
+func resolvePath(ctx context.Context, resolver qfs.PathResolver, path string) error {
+	if resolver == nil {
+		return ErrNoResolver
+	}
+	f, err := resolver.Get(ctx, path)
+	if err != nil {
+		return fmt.Errorf("resolving '%s': %s", path, err)
+	}
+	return f.Close()
+}
+
+// validateConfig checks config against the config schemas of a syntax
+func validateConfig(syn TransformSyntax, script []byte, config map[string]interface{}) error {
+	schemas := []*jsonschema.RootSchema{syn.ConfigSchema}
+	if syn.ScriptConfigSchema != nil {
+		rs, err := syn.ScriptConfigSchema(script)
+		if err != nil {
+			return fmt.Errorf("config: %s", err)
+		}
+		schemas = append(schemas, rs)
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("config: %s", err)
+	}
+	for _, rs := range schemas {
+		if rs == nil {
+			continue
+		}
+		errs, err := rs.ValidateBytes(data)
+		if err != nil {
+			return fmt.Errorf("config: %s", err)
+		}
+		if len(errs) > 0 {
+			// schema keywords are checked in map order, sort errors so the
+			// reported error is stable
+			msgs := make([]string, len(errs))
+			for i, e := range errs {
+				msgs[i] = e.Error()
+			}
+			sort.Strings(msgs)
+			return fmt.Errorf("config: %s", msgs[0])
+		}
+	}
+	return nil
+}
+
+func sortedResourceNames(rs map[string]*TransformResource) []string {
+	names := make([]string, 0, len(rs))
+	for name := range rs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/jsonschema"
+)
+
+func TestTransformValidate(t *testing.T) {
+	schema := &jsonschema.RootSchema{}
+	if err := json.Unmarshal([]byte(`{"type":"object","properties":{"region":{"type":"string"}},"required":["region"]}`), schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTransformSyntax(TransformSyntax{
+		Name:         "test_syntax",
+		ConfigSchema: schema,
+		Check: func(script []byte) error {
+			if string(script) == "broken" {
+				return fmt.Errorf("syntax error")
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterTransformSyntax("test_syntax")
+	if err := RegisterTransformSyntax(TransformSyntax{Name: "TEST_SYNTAX"}); err == nil {
+		t.Errorf("expected registering a syntax twice to error")
+	}
+
+	resolver := mapResolver{
+		"/ipfs/QmScript": "ok",
+		"/ipfs/QmBroken": "broken",
+		"/ipfs/QmStops":  "{}",
+	}
+	valid := func() *Transform {
+		return &Transform{
+			Syntax:     "test_syntax",
+			ScriptPath: "/ipfs/QmScript",
+			Config:     map[string]interface{}{"region": "bw"},
+			Resources:  map[string]*TransformResource{"a": {Path: "/ipfs/QmStops"}},
+		}
+	}
+	ctx := context.Background()
+
+	if err := valid().Validate(ctx, resolver); err != nil {
+		t.Errorf("expected valid transform to pass. got: %s", err)
+	}
+	withURL := valid()
+	withURL.Resources["stations"] = &TransformResource{Path: "https://example.com/stations.csv"}
+	if err := withURL.Validate(ctx, resolver); err != nil {
+		t.Errorf("expected url resource not to be resolved. got: %s", err)
+	}
+	if err := NewTransformRef("/ipfs/QmTransform").Validate(ctx, nil); err != nil {
+		t.Errorf("expected transform reference to pass. got: %s", err)
+	}
+	sql := &Transform{Syntax: "sql", ScriptBytes: []byte("select * from mfdz/stops")}
+	if err := sql.Validate(ctx, nil); err != nil {
+		t.Errorf("expected sql transform to pass. got: %s", err)
+	}
+
+	cases := []struct {
+		description string
+		modify      func(q *Transform)
+		err         string
+	}{
+		{"no syntax", func(q *Transform) { q.Syntax = "" }, "syntax is required"},
+		{"unknown syntax", func(q *Transform) { q.Syntax = "cobol" }, "unknown syntax 'cobol'"},
+		{"no script", func(q *Transform) { q.ScriptPath = "" }, "script is required"},
+		{"missing script", func(q *Transform) { q.ScriptPath = "/ipfs/QmMissing" }, "script: resolving '/ipfs/QmMissing': path not found"},
+		{"broken script", func(q *Transform) { q.ScriptPath = "/ipfs/QmBroken" }, "script: syntax error"},
+		{"bad config", func(q *Transform) { q.Config = map[string]interface{}{"region": 1} }, `config: /region: 1 type should be string`},
+		{"missing config", func(q *Transform) { q.Config = nil }, `config: /: {} "region" value is required`},
+		{"missing resource", func(q *Transform) { q.Resources["b"] = &TransformResource{Path: "/ipfs/QmNope"} }, "resource 'b': resolving '/ipfs/QmNope': path not found"},
+		{"bad url resource", func(q *Transform) { q.Resources["b"] = &TransformResource{Path: "https://"} }, "resource 'b': url 'https://' has no host"},
+		{"missing file", func(q *Transform) { q.Files = map[string]*TransformFile{"lib.star": {Path: "/ipfs/QmNope"}} }, "file 'lib.star': resolving '/ipfs/QmNope': path not found"},
+		{"bad step", func(q *Transform) {
+			q.Steps = []*TransformStep{{Name: "clean", ScriptPath: "/ipfs/QmScript", Config: map[string]interface{}{"region": "bw"}}, {Name: "join", ScriptBytes: []byte("broken")}}
+		}, "step 'join': script: syntax error"},
+		{"bad sql step", func(q *Transform) {
+			q.Steps = []*TransformStep{{Name: "agg", Syntax: "sql", ScriptBytes: []byte("select * from")}}
+		}, "step 'agg': script: expected table reference after FROM"},
+	}
+
+	for _, c := range cases {
+		q := valid()
+		c.modify(q)
+		err := q.Validate(ctx, resolver)
+		if err == nil {
+			t.Errorf("case '%s': expected error, got nil", c.description)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("case '%s': error mismatch. expected: '%s', got: '%s'", c.description, c.err, err)
+		}
+	}
+
+	if err := valid().Validate(ctx, nil); err != ErrNoResolver && err.Error() != "script: "+ErrNoResolver.Error() {
+		t.Errorf("expected missing resolver error, got: %v", err)
+	}
+}
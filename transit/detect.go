@@ -0,0 +1,95 @@
+package transit
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/qri-io/dataset/detect"
+)
+
+// importing transit registers detection hooks for the shared mobility feeds
+// it ships structures for
+func init() {
+	detect.RegisterHook(DetectGBFS)
+	detect.RegisterHook(DetectMDS)
+}
+
+// container is an object or array enclosing the current JSON token
+type container struct {
+	path   string
+	object bool
+	// key is the current key of an object, expectKey is true when the next
+	// token of an object is a key
+	key       string
+	expectKey bool
+}
+
+// jsonKeyPaths gives the dot separated paths of all object keys in a JSON
+// document, eg. "data.stations[].station_id". Array elements share the path
+// of their array with a "[]" suffix. data may be truncated, paths are
+// collected up to the point decoding fails
+func jsonKeyPaths(data []byte) map[string]bool {
+	paths := map[string]bool{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []*container
+
+	// child gives the path of the next value of the top container
+	child := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		top := stack[len(stack)-1]
+		if !top.object {
+			return top.path + "[]"
+		}
+		if top.path == "" {
+			return top.key
+		}
+		return top.path + "." + top.key
+	}
+	// valueDone marks the end of a value in the top container
+	valueDone := func() {
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return paths
+		}
+
+		if len(stack) > 0 && stack[len(stack)-1].object && stack[len(stack)-1].expectKey {
+			if key, ok := tok.(string); ok {
+				top := stack[len(stack)-1]
+				top.key = key
+				top.expectKey = false
+				paths[child()] = true
+				continue
+			}
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &container{path: child(), object: true, expectKey: true})
+		case json.Delim('['):
+			stack = append(stack, &container{path: child()})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+}
+
+// hasPaths reports whether all of the given key paths are present
+func hasPaths(paths map[string]bool, want ...string) bool {
+	for _, p := range want {
+		if !paths[p] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package transit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/detect"
+)
+
+func TestJSONKeyPaths(t *testing.T) {
+	got := jsonKeyPaths([]byte(`{"a":1,"b":{"c":[{"d":true},{"e":[1,{"f":null}]}]},"g":"`))
+	for _, p := range []string{"a", "b", "b.c", "b.c[].d", "b.c[].e", "b.c[].e[].f", "g"} {
+		if !got[p] {
+			t.Errorf("expected path %q", p)
+		}
+	}
+	if len(got) != 7 {
+		t.Errorf("expected 7 paths, got: %v", got)
+	}
+}
+
+func TestDetectPresets(t *testing.T) {
+	cases := []struct {
+		path   string
+		expect *dataset.Structure
+	}{
+		{"testdata/gbfs_station_information.json", GBFSStationInformation()},
+		{"testdata/gbfs_station_status.json", GBFSStationStatus()},
+		{"testdata/mds_trips.json", MDSTrips()},
+	}
+
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			data, err := ioutil.ReadFile(c.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			st, _, err := detect.FromReader(dataset.JSONDataFormat, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := dataset.CompareStructures(c.expect, st); err != nil {
+				t.Errorf("detected structure mismatch: %s", err)
+			}
+
+			rs, err := st.JSONSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs, err := rs.ValidateBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) > 0 {
+				t.Errorf("expected sample to validate, got: %v", errs)
+			}
+		})
+	}
+}
+
+func TestDetectPresetsUnrecognized(t *testing.T) {
+	cases := []struct {
+		format dataset.DataFormat
+		data   string
+	}{
+		{dataset.JSONDataFormat, `{"last_updated":1,"ttl":0,"data":{"vehicles":[]}}`},
+		{dataset.JSONDataFormat, `{"version":"0.4.1","data":{"status_changes":[]}}`},
+		{dataset.CSVDataFormat, `last_updated,ttl,data`},
+	}
+	for i, c := range cases {
+		if st := DetectGBFS(c.format, []byte(c.data)); st != nil {
+			t.Errorf("case %d: unexpected GBFS detection", i)
+		}
+		if st := DetectMDS(c.format, []byte(c.data)); st != nil {
+			t.Errorf("case %d: unexpected MDS detection", i)
+		}
+	}
+}
+
+func TestPresetsRejectInvalid(t *testing.T) {
+	rs, err := GBFSStationStatus().JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := rs.ValidateBytes([]byte(`{"last_updated":1,"ttl":60,"data":{"stations":[{"station_id":"s1","num_bikes_available":-1}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) == 0 {
+		t.Error("expected validation errors for an invalid station")
+	}
+}
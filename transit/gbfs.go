@@ -0,0 +1,110 @@
+package transit
+
+import (
+	"github.com/qri-io/dataset"
+)
+
+// GBFSStationInformation gives a structure for GBFS station_information.json
+// feeds, a document of stations with static details like location & capacity
+func GBFSStationInformation() *dataset.Structure {
+	return gbfsStructure(object([]string{"station_id", "name", "lat", "lon"}, map[string]interface{}{
+		"station_id":         typ("string"),
+		"name":               typ("string"),
+		"short_name":         typ("string"),
+		"lat":                number(-90, 90),
+		"lon":                number(-180, 180),
+		"address":            typ("string"),
+		"cross_street":       typ("string"),
+		"region_id":          typ("string"),
+		"post_code":          typ("string"),
+		"rental_methods":     array(typ("string")),
+		"is_virtual_station": typ("boolean"),
+		"capacity":           minimum("integer", 0),
+	}))
+}
+
+// GBFSStationStatus gives a structure for GBFS station_status.json feeds, a
+// document of the current availability of vehicles & docks at stations.
+// Flags written as 0 or 1 by GBFS 1.x feeds are accepted alongside booleans
+func GBFSStationStatus() *dataset.Structure {
+	flag := typ([]interface{}{"boolean", "integer"})
+	return gbfsStructure(object([]string{"station_id", "num_bikes_available", "is_installed", "is_renting", "is_returning", "last_reported"}, map[string]interface{}{
+		"station_id":              typ("string"),
+		"num_bikes_available":     minimum("integer", 0),
+		"num_bikes_disabled":      minimum("integer", 0),
+		"num_docks_available":     minimum("integer", 0),
+		"num_docks_disabled":      minimum("integer", 0),
+		"is_installed":            flag,
+		"is_renting":              flag,
+		"is_returning":            flag,
+		"last_reported":           typ([]interface{}{"integer", "string"}),
+		"vehicle_types_available": array(typ("object")),
+	}))
+}
+
+// gbfsStructure wraps a station schema in the envelope shared by GBFS feeds
+func gbfsStructure(station map[string]interface{}) *dataset.Structure {
+	return jsonStructure(object([]string{"last_updated", "ttl", "data"}, map[string]interface{}{
+		"last_updated": typ([]interface{}{"integer", "string"}),
+		"ttl":          minimum("integer", 0),
+		"version":      typ("string"),
+		"data": object([]string{"stations"}, map[string]interface{}{
+			"stations": array(station),
+		}),
+	}))
+}
+
+// DetectGBFS is a detect.Hook recognizing GBFS station information & station
+// status feeds
+func DetectGBFS(format dataset.DataFormat, prefix []byte) *dataset.Structure {
+	if format != dataset.JSONDataFormat {
+		return nil
+	}
+	paths := jsonKeyPaths(prefix)
+	if !hasPaths(paths, "last_updated", "ttl", "data.stations") {
+		return nil
+	}
+	switch {
+	case paths["data.stations[].num_bikes_available"]:
+		return GBFSStationStatus()
+	case paths["data.stations[].lat"], paths["data.stations[].name"]:
+		return GBFSStationInformation()
+	}
+	return nil
+}
+
+func jsonStructure(schema map[string]interface{}) *dataset.Structure {
+	return &dataset.Structure{
+		Qri:    dataset.KindStructure.String(),
+		Format: dataset.JSONDataFormat.String(),
+		Schema: schema,
+	}
+}
+
+func object(required []string, props map[string]interface{}) map[string]interface{} {
+	req := make([]interface{}, len(required))
+	for i, r := range required {
+		req[i] = r
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"required":   req,
+		"properties": props,
+	}
+}
+
+func array(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func typ(t interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": t}
+}
+
+func number(min, max float64) map[string]interface{} {
+	return map[string]interface{}{"type": "number", "minimum": min, "maximum": max}
+}
+
+func minimum(t string, min float64) map[string]interface{} {
+	return map[string]interface{}{"type": t, "minimum": min}
+}
@@ -0,0 +1,553 @@
+// Package transit reads public transport & shared mobility feeds into
+// dataset entries, shipping a structure for each kind of feed so realtime
+// and schedule data can be archived & versioned as datasets. Importing
+// transit registers detect hooks that recognize GBFS & MDS JSON documents
+package transit
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/dsio/pbwire"
+)
+
+// FeedType selects the entities of a GTFS-Realtime feed to read
+type FeedType string
+
+const (
+	// TripUpdates reads one entry per stop time update of each trip update
+	TripUpdates FeedType = "trip_updates"
+	// VehiclePositions reads one entry per vehicle position
+	VehiclePositions FeedType = "vehicle_positions"
+	// Alerts reads one entry per informed entity of each alert
+	Alerts FeedType = "alerts"
+)
+
+// column is a single column of a shipped structure
+type column struct {
+	title string
+	typ   string
+}
+
+// gtfsrtColumns lists the columns of each feed type. All columns are
+// nullable, fields absent from a feed are read as null
+var gtfsrtColumns = map[FeedType][]column{
+	TripUpdates: {
+		{"entity_id", "string"},
+		{"is_deleted", "boolean"},
+		{"trip_id", "string"},
+		{"route_id", "string"},
+		{"direction_id", "integer"},
+		{"start_date", "string"},
+		{"start_time", "string"},
+		{"trip_schedule_relationship", "string"},
+		{"vehicle_id", "string"},
+		{"vehicle_label", "string"},
+		{"timestamp", "integer"},
+		{"delay", "integer"},
+		{"stop_sequence", "integer"},
+		{"stop_id", "string"},
+		{"arrival_delay", "integer"},
+		{"arrival_time", "integer"},
+		{"arrival_uncertainty", "integer"},
+		{"departure_delay", "integer"},
+		{"departure_time", "integer"},
+		{"departure_uncertainty", "integer"},
+		{"schedule_relationship", "string"},
+	},
+	VehiclePositions: {
+		{"entity_id", "string"},
+		{"is_deleted", "boolean"},
+		{"trip_id", "string"},
+		{"route_id", "string"},
+		{"direction_id", "integer"},
+		{"start_date", "string"},
+		{"start_time", "string"},
+		{"trip_schedule_relationship", "string"},
+		{"vehicle_id", "string"},
+		{"vehicle_label", "string"},
+		{"latitude", "number"},
+		{"longitude", "number"},
+		{"bearing", "number"},
+		{"odometer", "number"},
+		{"speed", "number"},
+		{"current_stop_sequence", "integer"},
+		{"stop_id", "string"},
+		{"current_status", "string"},
+		{"timestamp", "integer"},
+		{"congestion_level", "string"},
+		{"occupancy_status", "string"},
+	},
+	Alerts: {
+		{"entity_id", "string"},
+		{"is_deleted", "boolean"},
+		{"cause", "string"},
+		{"effect", "string"},
+		{"header_text", "string"},
+		{"description_text", "string"},
+		{"url", "string"},
+		{"active_start", "integer"},
+		{"active_end", "integer"},
+		{"agency_id", "string"},
+		{"route_id", "string"},
+		{"route_type", "integer"},
+		{"trip_id", "string"},
+		{"stop_id", "string"},
+	},
+}
+
+// GTFSRTStructure gives the structure of entries read from GTFS-Realtime
+// feeds of type ft. Entries are tabular, formatted as CSV with a header row
+func GTFSRTStructure(ft FeedType) (*dataset.Structure, error) {
+	cols, ok := gtfsrtColumns[ft]
+	if !ok {
+		return nil, fmt.Errorf("unknown GTFS-Realtime feed type %q", ft)
+	}
+	return tabularStructure(cols), nil
+}
+
+func tabularStructure(cols []column) *dataset.Structure {
+	items := make([]interface{}, len(cols))
+	for i, col := range cols {
+		items[i] = map[string]interface{}{
+			"title": col.title,
+			"type":  []interface{}{col.typ, "null"},
+		}
+	}
+	return &dataset.Structure{
+		Qri:          dataset.KindStructure.String(),
+		Format:       dataset.CSVDataFormat.String(),
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": items,
+			},
+		},
+	}
+}
+
+// FeedHeader is the header of a GTFS-Realtime feed message
+type FeedHeader struct {
+	// Version is the version of the GTFS-Realtime specification
+	Version string
+	// Incrementality is either FULL_DATASET or DIFFERENTIAL
+	Incrementality string
+	// Timestamp is the POSIX time the feed was created
+	Timestamp int64
+}
+
+// GTFSRTReader decodes a GTFS-Realtime FeedMessage into flat entries. Feed
+// entities are decoded one at a time as they're read
+type GTFSRTReader struct {
+	ft     FeedType
+	st     *dataset.Structure
+	r      io.Reader
+	fields *pbwire.Reader
+	header *FeedHeader
+	rows   [][]interface{}
+	i      int
+}
+
+var _ dsio.EntryReader = (*GTFSRTReader)(nil)
+
+// NewGTFSRTReader creates a reader for the entities of type ft in the feed
+// message read from r
+func NewGTFSRTReader(ft FeedType, r io.Reader) (*GTFSRTReader, error) {
+	st, err := GTFSRTStructure(ft)
+	if err != nil {
+		return nil, err
+	}
+	return &GTFSRTReader{ft: ft, st: st, r: r, fields: pbwire.NewReader(r)}, nil
+}
+
+// Structure gives the structure of entries
+func (r *GTFSRTReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// Header gives the feed header, which is nil until the header has been read.
+// Feeds write the header before entities, so the header is available after
+// the first call to ReadEntry
+func (r *GTFSRTReader) Header() *FeedHeader {
+	return r.header
+}
+
+// ReadEntry reads the next entry, returning io.EOF at the end of the feed
+func (r *GTFSRTReader) ReadEntry() (dsio.Entry, error) {
+	for len(r.rows) == 0 {
+		f, err := r.fields.Next()
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("reading GTFS-Realtime feed: %w", err)
+			}
+			return dsio.Entry{}, err
+		}
+
+		switch f.Num {
+		case 1:
+			m, err := decode(f.Bytes)
+			if err != nil {
+				return dsio.Entry{}, fmt.Errorf("reading feed header: %w", err)
+			}
+			r.header = &FeedHeader{}
+			r.header.Version, _ = m.str(1).(string)
+			if s, ok := m.enum(2, incrementality).(string); ok {
+				r.header.Incrementality = s
+			}
+			r.header.Timestamp, _ = m.integer(3).(int64)
+		case 2:
+			if r.rows, err = r.entityRows(f.Bytes); err != nil {
+				return dsio.Entry{}, err
+			}
+		}
+	}
+
+	ent := dsio.Entry{Index: r.i, Value: r.rows[0]}
+	r.rows = r.rows[1:]
+	r.i++
+	return ent, nil
+}
+
+// Close closes the underlying reader if it implements io.Closer
+func (r *GTFSRTReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// entityRows flattens a FeedEntity into rows, entities without a message of
+// the reader's feed type produce no rows
+func (r *GTFSRTReader) entityRows(data []byte) ([][]interface{}, error) {
+	e, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading feed entity: %w", err)
+	}
+	base := map[string]interface{}{
+		"entity_id":  e.str(1),
+		"is_deleted": e.boolean(2),
+	}
+
+	var rows []map[string]interface{}
+	switch r.ft {
+	case TripUpdates:
+		rows, err = tripUpdateRows(e.msg(3), base)
+	case VehiclePositions:
+		rows, err = vehiclePositionRows(e.msg(4), base)
+	case Alerts:
+		rows, err = alertRows(e.msg(5), base)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading feed entity %v: %w", base["entity_id"], err)
+	}
+
+	cols := gtfsrtColumns[r.ft]
+	out := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		out[i] = make([]interface{}, len(cols))
+		for j, col := range cols {
+			out[i][j] = row[col.title]
+		}
+	}
+	return out, nil
+}
+
+func tripUpdateRows(data []byte, base map[string]interface{}) ([]map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	tu, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	trip := copyRow(base)
+	if err := setTrip(trip, tu.msg(1)); err != nil {
+		return nil, err
+	}
+	if err := setVehicle(trip, tu.msg(3)); err != nil {
+		return nil, err
+	}
+	trip["timestamp"] = tu.integer(4)
+	trip["delay"] = tu.integer(5)
+
+	updates := tu.all(2)
+	if len(updates) == 0 {
+		return []map[string]interface{}{trip}, nil
+	}
+	rows := make([]map[string]interface{}, 0, len(updates))
+	for _, f := range updates {
+		stu, err := decode(f.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		row := copyRow(trip)
+		row["stop_sequence"] = stu.integer(1)
+		row["stop_id"] = stu.str(4)
+		row["schedule_relationship"] = stu.enum(5, stopScheduleRelationship)
+		for prefix, num := range map[string]int{"arrival": 2, "departure": 3} {
+			ev, err := decode(stu.msg(num))
+			if err != nil {
+				return nil, err
+			}
+			row[prefix+"_delay"] = ev.integer(1)
+			row[prefix+"_time"] = ev.integer(2)
+			row[prefix+"_uncertainty"] = ev.integer(3)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func vehiclePositionRows(data []byte, base map[string]interface{}) ([]map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	vp, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	row := copyRow(base)
+	if err := setTrip(row, vp.msg(1)); err != nil {
+		return nil, err
+	}
+	if err := setVehicle(row, vp.msg(8)); err != nil {
+		return nil, err
+	}
+	pos, err := decode(vp.msg(2))
+	if err != nil {
+		return nil, err
+	}
+	row["latitude"] = pos.float(1)
+	row["longitude"] = pos.float(2)
+	row["bearing"] = pos.float(3)
+	row["odometer"] = pos.double(4)
+	row["speed"] = pos.float(5)
+	row["current_stop_sequence"] = vp.integer(3)
+	row["stop_id"] = vp.str(7)
+	row["current_status"] = vp.enum(4, vehicleStopStatus)
+	row["timestamp"] = vp.integer(5)
+	row["congestion_level"] = vp.enum(6, congestionLevel)
+	row["occupancy_status"] = vp.enum(9, occupancyStatus)
+	return []map[string]interface{}{row}, nil
+}
+
+// alertRows flattens an alert. Active periods are collapsed to the earliest
+// start & latest end, null when any period is open ended
+func alertRows(data []byte, base map[string]interface{}) ([]map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	a, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	alert := copyRow(base)
+	alert["cause"] = a.enum(6, alertCause)
+	alert["effect"] = a.enum(7, alertEffect)
+	for key, num := range map[string]int{"url": 8, "header_text": 10, "description_text": 11} {
+		if alert[key], err = translatedString(a.msg(num)); err != nil {
+			return nil, err
+		}
+	}
+
+	var start, end interface{}
+	openStart, openEnd := false, false
+	for _, f := range a.all(1) {
+		tr, err := decode(f.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := tr.integer(1).(int64); !ok {
+			openStart = true
+		} else if start == nil || s < start.(int64) {
+			start = s
+		}
+		if e, ok := tr.integer(2).(int64); !ok {
+			openEnd = true
+		} else if end == nil || e > end.(int64) {
+			end = e
+		}
+	}
+	if !openStart {
+		alert["active_start"] = start
+	}
+	if !openEnd {
+		alert["active_end"] = end
+	}
+
+	selectors := a.all(5)
+	if len(selectors) == 0 {
+		return []map[string]interface{}{alert}, nil
+	}
+	rows := make([]map[string]interface{}, 0, len(selectors))
+	for _, f := range selectors {
+		sel, err := decode(f.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		row := copyRow(alert)
+		row["agency_id"] = sel.str(1)
+		row["route_id"] = sel.str(2)
+		row["route_type"] = sel.integer(3)
+		row["stop_id"] = sel.str(5)
+		trip, err := decode(sel.msg(4))
+		if err != nil {
+			return nil, err
+		}
+		row["trip_id"] = trip.str(1)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// setTrip sets the columns of a TripDescriptor
+func setTrip(row map[string]interface{}, data []byte) error {
+	t, err := decode(data)
+	if err != nil {
+		return err
+	}
+	row["trip_id"] = t.str(1)
+	row["start_time"] = t.str(2)
+	row["start_date"] = t.str(3)
+	row["trip_schedule_relationship"] = t.enum(4, tripScheduleRelationship)
+	row["route_id"] = t.str(5)
+	row["direction_id"] = t.integer(6)
+	return nil
+}
+
+// setVehicle sets the columns of a VehicleDescriptor
+func setVehicle(row map[string]interface{}, data []byte) error {
+	v, err := decode(data)
+	if err != nil {
+		return err
+	}
+	row["vehicle_id"] = v.str(1)
+	row["vehicle_label"] = v.str(2)
+	return nil
+}
+
+// translatedString gives the first translation of a TranslatedString
+func translatedString(data []byte) (interface{}, error) {
+	ts, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	translations := ts.all(1)
+	if len(translations) == 0 {
+		return nil, nil
+	}
+	t, err := decode(translations[0].Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return t.str(1), nil
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		cp[k] = v
+	}
+	return cp
+}
+
+// message is a decoded protobuf message, fields keyed by number. Accessors
+// give the last value of a field or nil when the field is absent
+type message map[int][]pbwire.Field
+
+// decode decodes a message, nil data decodes as an empty message
+func decode(data []byte) (message, error) {
+	m := message{}
+	err := pbwire.Decode(data, func(f pbwire.Field) error {
+		m[f.Num] = append(m[f.Num], f)
+		return nil
+	})
+	return m, err
+}
+
+func (m message) last(num int) (pbwire.Field, bool) {
+	fs := m[num]
+	if len(fs) == 0 {
+		return pbwire.Field{}, false
+	}
+	return fs[len(fs)-1], true
+}
+
+func (m message) all(num int) []pbwire.Field {
+	return m[num]
+}
+
+func (m message) msg(num int) []byte {
+	if f, ok := m.last(num); ok && f.Type == pbwire.Bytes {
+		return f.Bytes
+	}
+	return nil
+}
+
+func (m message) str(num int) interface{} {
+	if f, ok := m.last(num); ok && f.Type == pbwire.Bytes {
+		return f.String()
+	}
+	return nil
+}
+
+func (m message) integer(num int) interface{} {
+	if f, ok := m.last(num); ok && f.Type == pbwire.Varint {
+		return f.Int()
+	}
+	return nil
+}
+
+func (m message) boolean(num int) interface{} {
+	if f, ok := m.last(num); ok && f.Type == pbwire.Varint {
+		return f.Bool()
+	}
+	return nil
+}
+
+// float gives a float field as float64, keeping the shortest decimal
+// representation of the float32 value
+func (m message) float(num int) interface{} {
+	if f, ok := m.last(num); ok && f.Type == pbwire.Fixed32 {
+		v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f.Float()), 'g', -1, 32), 64)
+		return v
+	}
+	return nil
+}
+
+func (m message) double(num int) interface{} {
+	if f, ok := m.last(num); ok && f.Type == pbwire.Fixed64 {
+		return f.Double()
+	}
+	return nil
+}
+
+// enum gives the name of an enum value, unknown values are written as
+// numbers
+func (m message) enum(num int, names []string) interface{} {
+	f, ok := m.last(num)
+	if !ok || f.Type != pbwire.Varint {
+		return nil
+	}
+	if i := f.Int(); i >= 0 && i < int64(len(names)) && names[i] != "" {
+		return names[i]
+	}
+	return strconv.FormatInt(f.Int(), 10)
+}
+
+// enum names, indexed by value
+var (
+	incrementality           = []string{"FULL_DATASET", "DIFFERENTIAL"}
+	tripScheduleRelationship = []string{"SCHEDULED", "ADDED", "UNSCHEDULED", "CANCELED", "", "REPLACEMENT", "DUPLICATED", "DELETED"}
+	stopScheduleRelationship = []string{"SCHEDULED", "SKIPPED", "NO_DATA", "UNSCHEDULED"}
+	vehicleStopStatus        = []string{"INCOMING_AT", "STOPPED_AT", "IN_TRANSIT_TO"}
+	congestionLevel          = []string{"UNKNOWN_CONGESTION_LEVEL", "RUNNING_SMOOTHLY", "STOP_AND_GO", "CONGESTION", "SEVERE_CONGESTION"}
+	occupancyStatus          = []string{"EMPTY", "MANY_SEATS_AVAILABLE", "FEW_SEATS_AVAILABLE", "STANDING_ROOM_ONLY", "CRUSHED_STANDING_ROOM_ONLY", "FULL", "NOT_ACCEPTING_PASSENGERS", "NO_DATA_AVAILABLE", "NOT_BOARDABLE"}
+	alertCause               = []string{"", "UNKNOWN_CAUSE", "OTHER_CAUSE", "TECHNICAL_PROBLEM", "STRIKE", "DEMONSTRATION", "ACCIDENT", "HOLIDAY", "WEATHER", "MAINTENANCE", "CONSTRUCTION", "POLICE_ACTIVITY", "MEDICAL_EMERGENCY"}
+	alertEffect              = []string{"", "NO_SERVICE", "REDUCED_SERVICE", "SIGNIFICANT_DELAYS", "DETOUR", "ADDITIONAL_SERVICE", "MODIFIED_SERVICE", "OTHER_EFFECT", "UNKNOWN_EFFECT", "STOP_MOVED", "NO_EFFECT", "ACCESSIBILITY_ISSUE"}
+)
@@ -0,0 +1,194 @@
+package transit
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/dsio/pbwire"
+	"github.com/qri-io/dataset/tabular"
+)
+
+func str(b []byte, num int, s string) []byte {
+	return pbwire.AppendBytes(b, num, []byte(s))
+}
+
+func float(b []byte, num int, v float32) []byte {
+	return pbwire.AppendFixed32(b, num, math.Float32bits(v))
+}
+
+func testFeed() []byte {
+	header := str(nil, 1, "2.0")
+	header = pbwire.AppendVarint(header, 3, 1600000000)
+	feed := pbwire.AppendBytes(nil, 1, header)
+
+	trip := str(nil, 1, "t1")
+	trip = str(trip, 5, "r1")
+	trip = pbwire.AppendVarint(trip, 6, 1)
+
+	// trip update with two stop time updates
+	arrival := pbwire.AppendVarint(nil, 1, 60)
+	arrival = pbwire.AppendVarint(arrival, 2, 1600000100)
+	stu1 := pbwire.AppendVarint(nil, 1, 1)
+	stu1 = str(stu1, 4, "s1")
+	stu1 = pbwire.AppendBytes(stu1, 2, arrival)
+	stu2 := pbwire.AppendVarint(nil, 1, 2)
+	stu2 = str(stu2, 4, "s2")
+	stu2 = pbwire.AppendVarint(stu2, 5, 1)
+	tu := pbwire.AppendBytes(nil, 1, trip)
+	tu = pbwire.AppendBytes(tu, 2, stu1)
+	tu = pbwire.AppendBytes(tu, 2, stu2)
+	tu = pbwire.AppendBytes(tu, 3, str(nil, 1, "v1"))
+	entity := str(nil, 1, "e1")
+	entity = pbwire.AppendBytes(entity, 3, tu)
+	feed = pbwire.AppendBytes(feed, 2, entity)
+
+	// vehicle position
+	pos := float(nil, 1, 52.52)
+	pos = float(pos, 2, 13.405)
+	vp := pbwire.AppendBytes(nil, 1, trip)
+	vp = pbwire.AppendBytes(vp, 2, pos)
+	vp = pbwire.AppendVarint(vp, 4, 1)
+	vp = pbwire.AppendVarint(vp, 9, 2)
+	vp = pbwire.AppendBytes(vp, 8, str(str(nil, 1, "v1"), 2, "Bus 1"))
+	entity = str(nil, 1, "e2")
+	entity = pbwire.AppendBytes(entity, 4, vp)
+	feed = pbwire.AppendBytes(feed, 2, entity)
+
+	// alert with one period & two informed entities
+	period := pbwire.AppendVarint(nil, 1, 1600000000)
+	period = pbwire.AppendVarint(period, 2, 1600003600)
+	text := pbwire.AppendBytes(nil, 1, str(str(nil, 1, "Baustelle"), 2, "de"))
+	alert := pbwire.AppendBytes(nil, 1, period)
+	alert = pbwire.AppendBytes(alert, 5, str(nil, 2, "r1"))
+	alert = pbwire.AppendBytes(alert, 5, str(nil, 5, "s1"))
+	alert = pbwire.AppendVarint(alert, 6, 10)
+	alert = pbwire.AppendVarint(alert, 7, 4)
+	alert = pbwire.AppendBytes(alert, 10, text)
+	entity = str(nil, 1, "e3")
+	entity = pbwire.AppendBytes(entity, 5, alert)
+	feed = pbwire.AppendBytes(feed, 2, entity)
+
+	return feed
+}
+
+func readRows(t *testing.T, r dsio.EntryReader) []map[string]interface{} {
+	cols, _, err := tabular.ColumnsFromJSONSchema(r.Structure().Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]interface{}
+	for {
+		ent, err := r.ReadEntry()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if ent.Index != len(rows) {
+			t.Errorf("expected index %d, got %d", len(rows), ent.Index)
+		}
+		vals := ent.Value.([]interface{})
+		if len(vals) != len(cols) {
+			t.Fatalf("expected %d values, got %d", len(cols), len(vals))
+		}
+		row := map[string]interface{}{}
+		for i, v := range vals {
+			if v != nil {
+				row[cols[i].Title] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestGTFSRTReader(t *testing.T) {
+	cases := []struct {
+		ft     FeedType
+		expect []map[string]interface{}
+	}{
+		{TripUpdates, []map[string]interface{}{
+			{"entity_id": "e1", "trip_id": "t1", "route_id": "r1", "direction_id": int64(1), "vehicle_id": "v1",
+				"stop_sequence": int64(1), "stop_id": "s1", "arrival_delay": int64(60), "arrival_time": int64(1600000100)},
+			{"entity_id": "e1", "trip_id": "t1", "route_id": "r1", "direction_id": int64(1), "vehicle_id": "v1",
+				"stop_sequence": int64(2), "stop_id": "s2", "schedule_relationship": "SKIPPED"},
+		}},
+		{VehiclePositions, []map[string]interface{}{
+			{"entity_id": "e2", "trip_id": "t1", "route_id": "r1", "direction_id": int64(1), "vehicle_id": "v1", "vehicle_label": "Bus 1",
+				"latitude": 52.52, "longitude": 13.405, "current_status": "STOPPED_AT", "occupancy_status": "FEW_SEATS_AVAILABLE"},
+		}},
+		{Alerts, []map[string]interface{}{
+			{"entity_id": "e3", "cause": "CONSTRUCTION", "effect": "DETOUR", "header_text": "Baustelle",
+				"active_start": int64(1600000000), "active_end": int64(1600003600), "route_id": "r1"},
+			{"entity_id": "e3", "cause": "CONSTRUCTION", "effect": "DETOUR", "header_text": "Baustelle",
+				"active_start": int64(1600000000), "active_end": int64(1600003600), "stop_id": "s1"},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.ft), func(t *testing.T) {
+			r, err := NewGTFSRTReader(c.ft, bytes.NewReader(testFeed()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := readRows(t, r)
+			if !reflect.DeepEqual(c.expect, got) {
+				t.Errorf("entries mismatch.\nexpected: %v\ngot:      %v", c.expect, got)
+			}
+			if expect := (&FeedHeader{Version: "2.0", Timestamp: 1600000000}); !reflect.DeepEqual(expect, r.Header()) {
+				t.Errorf("header mismatch. expected: %v, got: %v", expect, r.Header())
+			}
+			if err := r.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGTFSRTReaderWriteCSV(t *testing.T) {
+	r, err := NewGTFSRTReader(TripUpdates, bytes.NewReader(testFeed()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	w, err := dsio.NewEntryWriter(r.Structure(), buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dsio.Copy(r, w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect := "entity_id,is_deleted,trip_id,route_id,direction_id,start_date,start_time,trip_schedule_relationship,vehicle_id,vehicle_label,timestamp,delay,stop_sequence,stop_id,arrival_delay,arrival_time,arrival_uncertainty,departure_delay,departure_time,departure_uncertainty,schedule_relationship\n" +
+		"e1,,t1,r1,1,,,,v1,,,,1,s1,60,1600000100,,,,,\n" +
+		"e1,,t1,r1,1,,,,v1,,,,2,s2,,,,,,,SKIPPED\n"
+	if buf.String() != expect {
+		t.Errorf("csv mismatch.\nexpected: %q\ngot:      %q", expect, buf.String())
+	}
+}
+
+func TestGTFSRTReaderErrors(t *testing.T) {
+	if _, err := NewGTFSRTReader("timetables", bytes.NewReader(nil)); err == nil || err.Error() != `unknown GTFS-Realtime feed type "timetables"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	feed := testFeed()
+	r, err := NewGTFSRTReader(TripUpdates, bytes.NewReader(feed[:len(feed)-3]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err = r.ReadEntry(); err != nil {
+			break
+		}
+	}
+	if expect := "reading GTFS-Realtime feed: pbwire: truncated message"; err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %q, got: %v", expect, err)
+	}
+}
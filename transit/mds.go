@@ -0,0 +1,61 @@
+package transit
+
+import (
+	"github.com/qri-io/dataset"
+)
+
+// mdsVehicleTypes are the vehicle types of the MDS provider API
+var mdsVehicleTypes = []interface{}{"bicycle", "cargo_bicycle", "car", "scooter", "moped", "other"}
+
+// mdsPropulsionTypes are the propulsion types of the MDS provider API
+var mdsPropulsionTypes = []interface{}{"human", "electric_assist", "electric", "combustion", "combustion_diesel", "hybrid", "hydrogen_fuel_cell", "plug_in_hybrid"}
+
+// MDSTrips gives a structure for MDS provider API trips payloads. Times are
+// milliseconds since the unix epoch, distances are meters and durations are
+// seconds
+func MDSTrips() *dataset.Structure {
+	trip := object([]string{
+		"provider_id", "provider_name", "device_id", "vehicle_id", "vehicle_type",
+		"propulsion_types", "trip_id", "trip_duration", "trip_distance", "route",
+		"accuracy", "start_time", "end_time",
+	}, map[string]interface{}{
+		"provider_id":              typ("string"),
+		"provider_name":            typ("string"),
+		"device_id":                typ("string"),
+		"vehicle_id":               typ("string"),
+		"vehicle_type":             map[string]interface{}{"type": "string", "enum": mdsVehicleTypes},
+		"propulsion_types":         array(map[string]interface{}{"type": "string", "enum": mdsPropulsionTypes}),
+		"trip_id":                  typ("string"),
+		"trip_duration":            minimum("integer", 0),
+		"trip_distance":            minimum("integer", 0),
+		"route":                    typ("object"),
+		"accuracy":                 minimum("integer", 0),
+		"start_time":               minimum("integer", 0),
+		"end_time":                 minimum("integer", 0),
+		"publication_time":         minimum("integer", 0),
+		"parking_verification_url": typ("string"),
+		"standard_cost":            typ("integer"),
+		"actual_cost":              typ("integer"),
+		"currency":                 typ("string"),
+	})
+
+	return jsonStructure(object([]string{"version", "data"}, map[string]interface{}{
+		"version": typ("string"),
+		"data": object([]string{"trips"}, map[string]interface{}{
+			"trips": array(trip),
+		}),
+		"links": typ("object"),
+	}))
+}
+
+// DetectMDS is a detect.Hook recognizing MDS provider API trips payloads
+func DetectMDS(format dataset.DataFormat, prefix []byte) *dataset.Structure {
+	if format != dataset.JSONDataFormat {
+		return nil
+	}
+	paths := jsonKeyPaths(prefix)
+	if hasPaths(paths, "version", "data.trips") && (paths["data.trips[].trip_id"] || paths["data.trips[].device_id"]) {
+		return MDSTrips()
+	}
+	return nil
+}
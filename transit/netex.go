@@ -0,0 +1,59 @@
+package transit
+
+// NeTEx profiles read common elements of NeTEx publications, the European
+// standard exchange format for public transport networks & timetables
+
+// NeTExStopPlaces reads StopPlace elements, one entry per stop place
+var NeTExStopPlaces = XMLProfile{
+	Element: "StopPlace",
+	Columns: []XMLColumn{
+		{Title: "id", Type: "string", Path: "@id"},
+		{Title: "version", Type: "string", Path: "@version"},
+		{Title: "name", Type: "string", Path: "Name"},
+		{Title: "short_name", Type: "string", Path: "ShortName"},
+		{Title: "public_code", Type: "string", Path: "PublicCode"},
+		{Title: "private_code", Type: "string", Path: "PrivateCode"},
+		{Title: "latitude", Type: "number", Path: "Centroid/Location/Latitude"},
+		{Title: "longitude", Type: "number", Path: "Centroid/Location/Longitude"},
+		{Title: "transport_mode", Type: "string", Path: "TransportMode"},
+		{Title: "stop_place_type", Type: "string", Path: "StopPlaceType"},
+		{Title: "parent_site_ref", Type: "string", Path: "ParentSiteRef/@ref"},
+		{Title: "topographic_place_ref", Type: "string", Path: "TopographicPlaceRef/@ref"},
+	},
+}
+
+// NeTExQuays reads Quay elements, the boarding positions of stop places
+var NeTExQuays = XMLProfile{
+	Element: "Quay",
+	Columns: []XMLColumn{
+		{Title: "id", Type: "string", Path: "@id"},
+		{Title: "version", Type: "string", Path: "@version"},
+		{Title: "stop_place_id", Type: "string", Path: "^StopPlace/@id"},
+		{Title: "name", Type: "string", Path: "Name"},
+		{Title: "public_code", Type: "string", Path: "PublicCode"},
+		{Title: "private_code", Type: "string", Path: "PrivateCode"},
+		{Title: "latitude", Type: "number", Path: "Centroid/Location/Latitude"},
+		{Title: "longitude", Type: "number", Path: "Centroid/Location/Longitude"},
+	},
+}
+
+// NeTExLines reads Line elements, one entry per line
+var NeTExLines = XMLProfile{
+	Element: "Line",
+	Columns: []XMLColumn{
+		{Title: "id", Type: "string", Path: "@id"},
+		{Title: "version", Type: "string", Path: "@version"},
+		{Title: "name", Type: "string", Path: "Name"},
+		{Title: "short_name", Type: "string", Path: "ShortName"},
+		{Title: "public_code", Type: "string", Path: "PublicCode"},
+		{Title: "private_code", Type: "string", Path: "PrivateCode"},
+		{Title: "transport_mode", Type: "string", Path: "TransportMode"},
+		{Title: "transport_submode", Type: "string", Path: "TransportSubmode/*"},
+		{Title: "operator_ref", Type: "string", Path: "OperatorRef/@ref"},
+		{Title: "authority_ref", Type: "string", Path: "AuthorityRef/@ref"},
+		{Title: "network_ref", Type: "string", Path: "RepresentedByGroupRef/@ref"},
+		{Title: "colour", Type: "string", Path: "Presentation/Colour"},
+		{Title: "text_colour", Type: "string", Path: "Presentation/TextColour"},
+		{Title: "url", Type: "string", Path: "Url"},
+	},
+}
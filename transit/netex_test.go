@@ -0,0 +1,107 @@
+package transit
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNeTExProfiles(t *testing.T) {
+	cases := []struct {
+		profile XMLProfile
+		expect  []map[string]interface{}
+	}{
+		{NeTExStopPlaces, []map[string]interface{}{
+			{"id": "de:08111:6118", "version": "3", "name": "Stuttgart Hauptbahnhof", "latitude": 48.784081, "longitude": 9.181635,
+				"topographic_place_ref": "de:08111", "transport_mode": "rail", "stop_place_type": "railStation"},
+			{"id": "de:08111:2026", "version": "1", "name": "Rathaus", "transport_mode": "metro"},
+		}},
+		{NeTExQuays, []map[string]interface{}{
+			{"id": "de:08111:6118:1:1", "version": "3", "stop_place_id": "de:08111:6118", "name": "Gleis 1", "public_code": "1",
+				"latitude": 48.7836, "longitude": 9.1811},
+			{"id": "de:08111:6118:1:2", "version": "3", "stop_place_id": "de:08111:6118", "name": "Gleis 2", "public_code": "2"},
+		}},
+		{NeTExLines, []map[string]interface{}{
+			{"id": "de:vvs:U1", "version": "2", "name": "Fellbach - Vaihingen", "short_name": "U1", "public_code": "U1",
+				"transport_mode": "metro", "transport_submode": "tram", "operator_ref": "de:ssb", "network_ref": "de:vvs:network",
+				"colour": "FFB700", "text_colour": "000000"},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.profile.Element, func(t *testing.T) {
+			f, err := os.Open("testdata/netex_sample.xml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewXMLProfileReader(c.profile, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			if diff := cmp.Diff(c.expect, readRows(t, r)); diff != "" {
+				t.Errorf("entries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestXMLProfileValidate(t *testing.T) {
+	col := func(path string) XMLProfile {
+		return XMLProfile{Element: "A", Columns: []XMLColumn{{Title: "a", Type: "string", Path: path}}}
+	}
+	cases := []struct {
+		description string
+		profile     XMLProfile
+		err         string
+	}{
+		{"netex stop places", NeTExStopPlaces, ""},
+		{"netex quays", NeTExQuays, ""},
+		{"netex lines", NeTExLines, ""},
+		{"no element", XMLProfile{}, "xml profile: element is required"},
+		{"no columns", XMLProfile{Element: "A"}, "xml profile: columns are required"},
+		{"bad type", XMLProfile{Element: "A", Columns: []XMLColumn{{Title: "a", Type: "date", Path: "B"}}}, `xml profile: column "a": unsupported type "date"`},
+		{"empty path", col(""), `xml profile: column "a": path is required`},
+		{"attribute mid path", col("@b/C"), `xml profile: column "a": invalid path "@b/C"`},
+		{"empty segment", col("B//C"), `xml profile: column "a": invalid path "B//C"`},
+		{"ancestor text", col("^B/C"), `xml profile: column "a": invalid path "^B/C", ancestor paths must be of the form ^Name/@attr`},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.profile.Validate()
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestXMLProfileReaderErrors(t *testing.T) {
+	p := XMLProfile{Element: "A", Columns: []XMLColumn{{Title: "n", Type: "integer", Path: "@n"}}}
+	cases := []struct {
+		description string
+		data        string
+		err         string
+	}{
+		{"invalid value", `<r><A n="1"/><A n="x"/></r>`, `reading A element 1: column "n": strconv.ParseInt: parsing "x": invalid syntax`},
+		{"truncated", `<r><A n="1"/><A n="2">`, "reading A element 1: XML syntax error on line 1: unexpected EOF"},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r, err := NewXMLProfileReader(p, strings.NewReader(c.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.ReadEntry(); err != nil {
+				t.Fatal(err)
+			}
+			_, err = r.ReadEntry()
+			if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+				t.Errorf("error mismatch. expected: %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package transit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// XMLProfile selects elements of an XML document to read as tabular
+// entries, extracting a column from each element by path. Elements are
+// matched by local name, ignoring namespaces
+type XMLProfile struct {
+	// Element is the name of elements read as entries
+	Element string
+	// Columns are the columns of each entry
+	Columns []XMLColumn
+}
+
+// XMLColumn extracts a single value from a profile element
+type XMLColumn struct {
+	// Title is the column title
+	Title string
+	// Type is the JSON schema type of the column: string, number, integer
+	// or boolean
+	Type string
+	// Path is a slash separated path of child element names relative to the
+	// profile element, giving the element's text. "*" matches any child,
+	// and a final "@name" segment gives an attribute instead of text, eg.
+	// "Centroid/Location/Latitude" or "OperatorRef/@ref". Paths starting with
+	// "^Name/" select an attribute of the nearest enclosing element named
+	// Name, eg. "^StopPlace/@id"
+	Path string
+}
+
+// Structure gives the structure of entries read with the profile
+func (p XMLProfile) Structure() *dataset.Structure {
+	cols := make([]column, len(p.Columns))
+	for i, c := range p.Columns {
+		cols[i] = column{title: c.Title, typ: c.Type}
+	}
+	return tabularStructure(cols)
+}
+
+// Validate checks a profile is well formed
+func (p XMLProfile) Validate() error {
+	if p.Element == "" {
+		return fmt.Errorf("xml profile: element is required")
+	}
+	if len(p.Columns) == 0 {
+		return fmt.Errorf("xml profile: columns are required")
+	}
+	for _, c := range p.Columns {
+		if c.Title == "" {
+			return fmt.Errorf("xml profile: column title is required")
+		}
+		switch c.Type {
+		case "string", "number", "integer", "boolean":
+		default:
+			return fmt.Errorf("xml profile: column %q: unsupported type %q", c.Title, c.Type)
+		}
+		if _, _, err := splitPath(c.Path); err != nil {
+			return fmt.Errorf("xml profile: column %q: %w", c.Title, err)
+		}
+	}
+	return nil
+}
+
+// splitPath separates a column path into an ancestor name, element path
+// segments & a trailing attribute name
+func splitPath(path string) (ancestor string, segments []string, err error) {
+	if path == "" {
+		return "", nil, fmt.Errorf("path is required")
+	}
+	if strings.HasPrefix(path, "^") {
+		parts := strings.Split(path[1:], "/")
+		if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], "@") || len(parts[1]) == 1 {
+			return "", nil, fmt.Errorf("invalid path %q, ancestor paths must be of the form ^Name/@attr", path)
+		}
+		return parts[0], parts[1:], nil
+	}
+	segments = strings.Split(path, "/")
+	for i, s := range segments {
+		if s == "" || (strings.HasPrefix(s, "@") && (i != len(segments)-1 || len(s) == 1)) {
+			return "", nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return "", segments, nil
+}
+
+// xmlNode is a captured element
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     strings.Builder
+	children []*xmlNode
+}
+
+func (n *xmlNode) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// find resolves element path segments, giving the first match
+func (n *xmlNode) find(segments []string) (string, bool) {
+	if len(segments) == 0 {
+		return strings.TrimSpace(n.text.String()), true
+	}
+	if seg := segments[0]; strings.HasPrefix(seg, "@") {
+		return n.attr(seg[1:])
+	}
+	for _, c := range n.children {
+		if segments[0] == "*" || c.name == segments[0] {
+			if v, ok := c.find(segments[1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// XMLProfileReader streams the elements of an XML document selected by a
+// profile as entries. Only a single element is held in memory at a time
+type XMLProfileReader struct {
+	p   XMLProfile
+	st  *dataset.Structure
+	r   io.Reader
+	dec *xml.Decoder
+	// stack of open elements outside of the current profile element
+	stack []*xmlNode
+	i     int
+}
+
+var _ dsio.EntryReader = (*XMLProfileReader)(nil)
+
+// NewXMLProfileReader creates a reader of the elements of r selected by p
+func NewXMLProfileReader(p XMLProfile, r io.Reader) (*XMLProfileReader, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &XMLProfileReader{p: p, st: p.Structure(), r: r, dec: xml.NewDecoder(r)}, nil
+}
+
+// Structure gives the structure of entries
+func (r *XMLProfileReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads the next profile element, returning io.EOF at the end of
+// the document
+func (r *XMLProfileReader) ReadEntry() (dsio.Entry, error) {
+	for {
+		tok, err := r.dec.Token()
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("reading xml: %w", err)
+			}
+			return dsio.Entry{}, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != r.p.Element {
+				// ancestors only need attributes
+				r.stack = append(r.stack, &xmlNode{name: t.Name.Local, attrs: t.Attr})
+				continue
+			}
+			n, err := r.capture(t)
+			if err != nil {
+				return dsio.Entry{}, err
+			}
+			row, err := r.row(n)
+			if err != nil {
+				return dsio.Entry{}, err
+			}
+			ent := dsio.Entry{Index: r.i, Value: row}
+			r.i++
+			return ent, nil
+		case xml.EndElement:
+			if len(r.stack) > 0 {
+				r.stack = r.stack[:len(r.stack)-1]
+			}
+		}
+	}
+}
+
+// capture reads the subtree of a profile element
+func (r *XMLProfileReader) capture(start xml.StartElement) (*xmlNode, error) {
+	root := &xmlNode{name: start.Name.Local, attrs: start.Attr}
+	open := []*xmlNode{root}
+	for len(open) > 0 {
+		tok, err := r.dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("reading %s element %d: %w", r.p.Element, r.i, err)
+		}
+		top := open[len(open)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			top.children = append(top.children, n)
+			open = append(open, n)
+		case xml.CharData:
+			top.text.Write(t)
+		case xml.EndElement:
+			open = open[:len(open)-1]
+		}
+	}
+	return root, nil
+}
+
+// row extracts the columns of a captured element
+func (r *XMLProfileReader) row(n *xmlNode) ([]interface{}, error) {
+	row := make([]interface{}, len(r.p.Columns))
+	for i, c := range r.p.Columns {
+		ancestor, segments, _ := splitPath(c.Path)
+		var (
+			s  string
+			ok bool
+		)
+		if ancestor != "" {
+			for j := len(r.stack) - 1; j >= 0; j-- {
+				if r.stack[j].name == ancestor {
+					s, ok = r.stack[j].attr(segments[0][1:])
+					break
+				}
+			}
+		} else {
+			s, ok = n.find(segments)
+		}
+		if !ok || s == "" {
+			continue
+		}
+
+		v, err := parseValue(c.Type, s)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s element %d: column %q: %w", r.p.Element, r.i, c.Title, err)
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+func parseValue(typ, s string) (interface{}, error) {
+	switch typ {
+	case "number":
+		return strconv.ParseFloat(s, 64)
+	case "integer":
+		return strconv.ParseInt(s, 10, 64)
+	case "boolean":
+		return strconv.ParseBool(s)
+	}
+	return s, nil
+}
+
+// Close closes the underlying reader if it implements io.Closer
+func (r *XMLProfileReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+package dataset
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/qri-io/dataset/compression"
+)
+
+// maxClockSkew is the amount of time a commit timestamp may sit in the future
+// before it's considered insane. Generous to account for misconfigured clocks
+const maxClockSkew = time.Hour * 24
+
+// Validate checks that a dataset document is well-formed according to the
+// dataset spec, returning the first error encountered, nil if valid.
+// Validate only examines the document itself: it doesn't resolve paths, read
+// the body, or check that body data conforms to the structure schema. For
+// "fit for use" checks see the validate subpackage
+// datasets that are only a path reference are always considered valid
+func (ds *Dataset) Validate() error {
+	if ds == nil {
+		return fmt.Errorf("dataset is required")
+	}
+	if ds.Path != "" && ds.IsEmpty() {
+		return nil
+	}
+
+	if err := validateKind(ds.Qri, KindDataset); err != nil {
+		return err
+	}
+
+	if ds.Commit == nil {
+		return fmt.Errorf("commit is required")
+	} else if err := validateCommit(ds.Commit); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	if ds.Structure == nil {
+		return fmt.Errorf("structure is required")
+	} else if err := validateStructure(ds.Structure); err != nil {
+		return fmt.Errorf("structure: %w", err)
+	}
+	if ds.Meta != nil {
+		if err := validateMeta(ds.Meta); err != nil {
+			return fmt.Errorf("meta: %w", err)
+		}
+	}
+	if ds.Transform != nil {
+		if err := validateTransform(ds.Transform); err != nil {
+			return fmt.Errorf("transform: %w", err)
+		}
+	}
+	if ds.Viz != nil {
+		if err := validateViz(ds.Viz, ds.Structure); err != nil {
+			return fmt.Errorf("viz: %w", err)
+		}
+	}
+	if ds.Readme != nil {
+		if err := validateReadme(ds.Readme); err != nil {
+			return fmt.Errorf("readme: %w", err)
+		}
+	}
+	if ds.Provenance != nil {
+		if err := validateProvenance(ds.Provenance); err != nil {
+			return fmt.Errorf("provenance: %w", err)
+		}
+	}
+	if ds.Preview != nil {
+		if err := validatePreview(ds.Preview); err != nil {
+			return fmt.Errorf("preview: %w", err)
+		}
+	}
+	if ds.Stats != nil {
+		if err := validateStats(ds.Stats); err != nil {
+			return fmt.Errorf("stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateKind checks a qri kind string. empty strings are valid, as kinds are
+// derived values that are set when a document is saved
+func validateKind(k string, expect Kind) error {
+	if k == "" {
+		return nil
+	}
+	kind := Kind(k)
+	if err := kind.Valid(); err != nil {
+		return err
+	}
+	if kind.Type() != expect.Type() {
+		return fmt.Errorf("invalid kind: '%s'. expected type '%s'", k, expect.Type())
+	}
+	return nil
+}
+
+// validateURL checks that a non-empty string is an absolute URL
+func validateURL(field, s string) error {
+	if s == "" {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("%s: %s", field, err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("%s: '%s' must be an absolute url", field, s)
+	}
+	return nil
+}
+
+// validateContact checks the url & email address of an organization or person
+func validateContact(field, u, email string) error {
+	if err := validateURL(field+".url", u); err != nil {
+		return err
+	}
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("%s.email: invalid email address '%s'", field, email)
+		}
+	}
+	return nil
+}
+
+func validateCommit(cm *Commit) error {
+	if cm.Path != "" && cm.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(cm.Qri, KindCommit); err != nil {
+		return err
+	}
+	if cm.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if cm.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	if cm.Timestamp.After(time.Now().Add(maxClockSkew)) {
+		return fmt.Errorf("timestamp %s is in the future", cm.Timestamp.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func validateStructure(st *Structure) error {
+	if st.Path != "" && st.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(st.Qri, KindStructure); err != nil {
+		return err
+	}
+	if st.Format == "" {
+		return ErrFormatRequired
+	}
+	if _, err := ParseDataFormatString(st.Format); err != nil {
+		return err
+	}
+	if err := validateCompression(st.Compression); err != nil {
+		return err
+	}
+	if st.Length < 0 {
+		return fmt.Errorf("length cannot be negative")
+	}
+	if st.Entries < 0 {
+		return fmt.Errorf("entries cannot be negative")
+	}
+	if st.ErrCount < 0 {
+		return fmt.Errorf("errCount cannot be negative")
+	}
+	return nil
+}
+
+// validateCompression checks against compression names instead of using
+// compression.ParseTypeString, which only recognizes the empty string
+func validateCompression(s string) error {
+	for _, name := range compression.Names {
+		if s == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid compression type %q", s)
+}
+
+func validateMeta(md *Meta) error {
+	if md.Path != "" && md.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(md.Qri, KindMeta); err != nil {
+		return err
+	}
+	for _, f := range [][2]string{
+		{"accessURL", md.AccessURL},
+		{"downloadURL", md.DownloadURL},
+		{"homeURL", md.HomeURL},
+		{"readmeURL", md.ReadmeURL},
+	} {
+		if err := validateURL(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+	if md.AccrualPeriodicity != "" {
+		if _, err := ParseAccrualPeriodicity(md.AccrualPeriodicity); err != nil {
+			return err
+		}
+	}
+	if err := validateAccess(md); err != nil {
+		return err
+	}
+	if md.License != nil {
+		if err := md.License.Validate(); err != nil {
+			return fmt.Errorf("license: %s", err)
+		}
+	}
+	for i, c := range md.Citations {
+		if c == nil {
+			return fmt.Errorf("citations index %d is empty", i)
+		}
+		if err := validateURL(fmt.Sprintf("citations index %d url", i), c.URL); err != nil {
+			return err
+		}
+	}
+	for i, id := range md.Identifiers {
+		if id == nil {
+			return fmt.Errorf("identifiers index %d is empty", i)
+		}
+		if err := id.Validate(); err != nil {
+			return fmt.Errorf("identifiers index %d: %s", i, err)
+		}
+	}
+	for i, t := range md.Themes {
+		if t == nil {
+			return fmt.Errorf("themes index %d is empty", i)
+		}
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("themes index %d: %s", i, err)
+		}
+	}
+	if md.Publisher != nil {
+		if md.Publisher.Name == "" {
+			return fmt.Errorf("publisher: name is required")
+		}
+		if err := validateContact("publisher", md.Publisher.URL, md.Publisher.Email); err != nil {
+			return err
+		}
+	}
+	if md.ContactPoint != nil {
+		if md.ContactPoint.Email == "" && md.ContactPoint.URL == "" {
+			return fmt.Errorf("contactPoint: email or url is required")
+		}
+		if err := validateContact("contactPoint", md.ContactPoint.URL, md.ContactPoint.Email); err != nil {
+			return err
+		}
+	}
+	if md.Spatial != nil {
+		if err := md.Spatial.Validate(); err != nil {
+			return fmt.Errorf("spatial: %s", err)
+		}
+	}
+	if md.Temporal != nil {
+		if err := md.Temporal.Validate(); err != nil {
+			return fmt.Errorf("temporal: %s", err)
+		}
+	}
+	for _, tag := range sortedTranslationTags(md.Translations) {
+		if !ValidLanguageTag(tag) {
+			return fmt.Errorf("translations: invalid language tag '%s'", tag)
+		}
+		if md.Translations[tag] == nil {
+			return fmt.Errorf("translations '%s' is empty", tag)
+		}
+	}
+	return md.ValidateExtensions()
+}
+
+func validateTransform(q *Transform) error {
+	if q.Path != "" && q.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(q.Qri, KindTransform); err != nil {
+		return err
+	}
+	for _, name := range sortedResourceNames(q.Resources) {
+		r := q.Resources[name]
+		if r == nil || r.Path == "" {
+			return fmt.Errorf("resource '%s': path is required", name)
+		}
+		if err := validateResourceURL(r); err != nil {
+			return fmt.Errorf("resource '%s': %s", name, err)
+		}
+	}
+	if err := validateResourceNames(q.Resources); err != nil {
+		return err
+	}
+	if err := validateSQLTransform(q); err != nil {
+		return err
+	}
+	if err := validateMappingTransform(q); err != nil {
+		return err
+	}
+	if err := validateStarlarkTransform(q); err != nil {
+		return err
+	}
+	if err := validateSteps(q.Steps); err != nil {
+		return err
+	}
+	if err := validateLineage(q); err != nil {
+		return err
+	}
+	if q.Anonymize != nil {
+		if err := q.Anonymize.Validate(); err != nil {
+			return fmt.Errorf("anonymize: %s", err)
+		}
+		for _, c := range q.Anonymize.Columns {
+			if _, ok := q.Secrets[c.Salt]; c.Salt != "" && !ok {
+				return fmt.Errorf("anonymize: column '%s': salt secret '%s' is not declared", c.Column, c.Salt)
+			}
+		}
+	}
+	for _, name := range q.FileNames() {
+		if err := validateTransformFileName(name); err != nil {
+			return err
+		}
+		if f := q.Files[name]; f == nil || f.Path == "" && f.ScriptBytes == nil {
+			return fmt.Errorf("file '%s': path or scriptBytes is required", name)
+		}
+	}
+	return nil
+}
+
+func validateViz(v *Viz, st *Structure) error {
+	if v.Path != "" && v.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(v.Qri, KindViz); err != nil {
+		return err
+	}
+	if err := validateVizOutputs(v.Outputs); err != nil {
+		return err
+	}
+	if v.Binding != nil {
+		return validateVizBinding(v.Binding, st)
+	}
+	return nil
+}
+
+func validateReadme(r *Readme) error {
+	if r.Path != "" && r.IsEmpty() {
+		return nil
+	}
+	return validateKind(r.Qri, KindReadme)
+}
+
+func validateProvenance(p *Provenance) error {
+	if p.Path != "" && p.IsEmpty() {
+		return nil
+	}
+	if err := validateKind(p.Qri, KindProvenance); err != nil {
+		return err
+	}
+	if !p.Started.IsZero() && !p.Ended.IsZero() && p.Ended.Before(p.Started) {
+		return fmt.Errorf("ended %s is before started %s", p.Ended.Format(time.RFC3339), p.Started.Format(time.RFC3339))
+	}
+	if p.Agent != nil {
+		if p.Agent.Type != "" {
+			if _, ok := provAgentTypes[p.Agent.Type]; !ok {
+				return fmt.Errorf("agent: invalid type '%s'", p.Agent.Type)
+			}
+		}
+	}
+	for i, in := range p.Inputs {
+		if in == nil || in.Path == "" {
+			return fmt.Errorf("inputs index %d: path is required", i)
+		}
+	}
+	if p.Run != nil {
+		return validateRunState(p.Run)
+	}
+	return nil
+}
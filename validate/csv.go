@@ -2,16 +2,19 @@ package validate
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 )
 
 // CheckCsvRowLengths ensures that csv input has
 // the same number of columns in every row and otherwise
-// returns an error
+// returns an error. Errors give both the index of the failing row & the line
+// it starts on, which differ when quoted cells span lines
 func CheckCsvRowLengths(r io.Reader) error {
 	csvReader := csv.NewReader(r)
-	csvReader.FieldsPerRecord = -1
+	// the first row sets the expected number of fields
+	csvReader.FieldsPerRecord = 0
 	csvReader.TrimLeadingSpace = true
 	//csvReader.LazyQuotes = true
 	firstRow, err := csvReader.Read()
@@ -24,11 +27,12 @@ func CheckCsvRowLengths(r io.Reader) error {
 		if err == io.EOF {
 			return nil
 		}
+		perr := &csv.ParseError{}
+		if errors.As(err, &perr) && perr.Err == csv.ErrFieldCount {
+			return fmt.Errorf("error: inconsistent column length on row %d (line %d) of length %d (rather than %d). ensure all csv columns same length", i, perr.StartLine, len(record), rowLen)
+		}
 		if err != nil {
 			return err
 		}
-		if len(record) != rowLen {
-			return fmt.Errorf("error: inconsistent column length on line %d of length %d (rather than %d). ensure all csv columns same length", i, len(record), rowLen)
-		}
 	}
 }
@@ -14,7 +14,8 @@ func TestCheckCsvRowLengths(t *testing.T) {
 		{rawText2, ""},
 		{rawText2b, ""},
 		{rawText3, ""}, //Note: since there are no commas this should pass
-		{rawText4, "error: inconsistent column length on line 4 of length 2 (rather than 1). ensure all csv columns same length"},
+		{rawText4, "error: inconsistent column length on row 4 (line 5) of length 2 (rather than 1). ensure all csv columns same length"},
+		{"a,b\n\"multi\nline\",1\n\"x\",\"y\nz\"\n1,2,3\n", "error: inconsistent column length on row 3 (line 6) of length 3 (rather than 2). ensure all csv columns same length"},
 	}
 
 	for i, c := range cases {
@@ -21,26 +21,26 @@ func EntryReader(r dsio.EntryReader) ([]jsonschema.ValError, error) {
 		Schema: st.Schema,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("error allocating data buffer: %s", err.Error())
+		return nil, fmt.Errorf("error allocating data buffer: %w", err)
 	}
 
 	err = dsio.EachEntry(r, func(i int, ent dsio.Entry, err error) error {
 		if err != nil {
-			return fmt.Errorf("error reading row %d: %s", i, err.Error())
+			return fmt.Errorf("error reading row %d: %w", i, err)
 		}
 		err = buf.WriteEntry(ent)
 		if err != nil {
-			return fmt.Errorf("error writing row %d: %s", i, err.Error())
+			return fmt.Errorf("error writing row %d: %w", i, err)
 		}
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("error reading values: %s", err.Error())
+		return nil, fmt.Errorf("error reading values: %w", err)
 	}
 
 	if e := buf.Close(); e != nil {
-		return nil, fmt.Errorf("error closing buffer: %s", e.Error())
+		return nil, fmt.Errorf("error closing buffer: %w", e)
 	}
 
 	data := buf.Bytes()
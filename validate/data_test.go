@@ -2,8 +2,10 @@ package validate
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/dataset/dstest"
 )
@@ -53,3 +55,43 @@ func TestEntryReader(t *testing.T) {
 		}
 	}
 }
+
+func TestEntryReaderNullValues(t *testing.T) {
+	data := "title,duration\nalien,117\nthe thing,NA\nsolaris,\n"
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "title", "type": "string"},
+					map[string]interface{}{"title": "duration", "type": []interface{}{"integer", "null"}},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		nullValues []interface{}
+		errors     int
+	}{
+		{nil, 2},
+		{[]interface{}{"", "NA"}, 0},
+	}
+	for i, c := range cases {
+		st.FormatConfig["nullValues"] = c.nullValues
+		r, err := dsio.NewEntryReader(st, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		errors, err := EntryReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errors) != c.errors {
+			t.Errorf("case %d: expected %d validation errors, got: %v", i, c.errors, errors)
+		}
+	}
+}
@@ -25,7 +25,7 @@ func Dataset(ds *dataset.Dataset) error {
 		log.Debug(err.Error())
 		return err
 	} else if err := Commit(ds.Commit); err != nil {
-		err := fmt.Errorf("commit: %s", err.Error())
+		err := fmt.Errorf("commit: %w", err)
 		log.Debug(err.Error())
 		return err
 	}
@@ -34,7 +34,7 @@ func Dataset(ds *dataset.Dataset) error {
 		log.Debug(err.Error())
 		return err
 	} else if err := Structure(ds.Structure); err != nil {
-		return fmt.Errorf("structure: %s", err.Error())
+		return fmt.Errorf("structure: %w", err)
 	}
 
 	return nil
@@ -66,15 +66,37 @@ func Structure(s *dataset.Structure) error {
 
 	df := s.DataFormat()
 	if df == dataset.UnknownDataFormat {
-		return fmt.Errorf("format is required")
+		return dataset.ErrFormatRequired
 	} else if df == dataset.CSVDataFormat {
 		if s.Schema == nil {
 			return fmt.Errorf("csv data format requires a schema")
 		}
 	}
 
+	if s.CRS != "" {
+		if _, err := dataset.ParseCRS(s.CRS); err != nil {
+			return fmt.Errorf("crs: %w", err)
+		}
+	}
+
+	if s.Geometry != nil {
+		if err := s.Geometry.Validate(); err != nil {
+			return fmt.Errorf("geometry: %w", err)
+		}
+	}
+
+	if s.Partition != nil {
+		if err := s.Partition.Validate(); err != nil {
+			return fmt.Errorf("partition: %w", err)
+		}
+	}
+
+	if s.Source != nil && s.Source.DataFormat() == dataset.UnknownDataFormat {
+		return fmt.Errorf("source: %w", dataset.ErrFormatRequired)
+	}
+
 	if err := Schema(s.Schema); err != nil {
-		return fmt.Errorf("schema: %s", err.Error())
+		return fmt.Errorf("schema: %w", err)
 	}
 
 	return nil
@@ -64,6 +64,12 @@ func TestStructure(t *testing.T) {
 		{&dataset.Structure{Format: "csv"}, "csv data format requires a schema"},
 		// {&dataset.Structure{Format: "csv"}, "schema: fields are required"},
 		{&dataset.Structure{Format: "json", Schema: map[string]interface{}{"type": "array"}}, ""},
+		{&dataset.Structure{Format: "json", CRS: "EPSG:25832", Schema: map[string]interface{}{"type": "array"}}, ""},
+		{&dataset.Structure{Format: "json", CRS: "WGS84", Schema: map[string]interface{}{"type": "array"}}, `crs: invalid CRS "WGS84", must be of the form EPSG:<code>`},
+		{&dataset.Structure{Format: "json", Geometry: &dataset.GeometryRules{X: "lon"}, Schema: map[string]interface{}{"type": "array"}}, "geometry: x & y columns must be set together"},
+		{&dataset.Structure{Format: "cbor", Source: &dataset.SourceFormat{Format: "csv"}, Schema: map[string]interface{}{"type": "array"}}, ""},
+		{&dataset.Structure{Format: "cbor", Source: &dataset.SourceFormat{}, Schema: map[string]interface{}{"type": "array"}}, "source: format is required"},
+		{&dataset.Structure{Format: "json", Partition: &dataset.Partition{Column: "date", Granularity: "week"}, Schema: map[string]interface{}{"type": "array"}}, "partition: invalid granularity 'week', must be one of year, month, day or hour"},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,229 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/jsonschema"
+)
+
+// DefaultMaxReported is the default number of invalid entries a Reader keeps
+// in it's report
+const DefaultMaxReported = 100
+
+// EntryError lists the schema violations of a single entry
+type EntryError struct {
+	// Index of the invalid entry
+	Index int
+	// Key of the invalid entry, for entries of object bodies
+	Key string
+	// Errors are the violations of the entry. Property paths start at the
+	// body, eg. "/3/1" for the second cell of the fourth row
+	Errors []jsonschema.ValError
+}
+
+// Error implements the error interface
+func (e *EntryError) Error() string {
+	msg := fmt.Sprintf("entry %d is invalid: %s", e.Index, e.Errors[0].Error())
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Errors)-1)
+	}
+	return msg
+}
+
+// Report summarizes the validity of the entries a Reader has read
+type Report struct {
+	// Entries is the number of entries read
+	Entries int
+	// Invalid is the number of invalid entries read
+	Invalid int
+	// Errors of the first invalid entries, up to the configured maximum
+	Errors []*EntryError
+}
+
+// ReaderConfig configures a validating reader
+type ReaderConfig struct {
+	// Continue reads past invalid entries, which are returned like valid
+	// ones. By default reading an invalid entry fails with it's *EntryError
+	Continue bool
+	// OnInvalid is called with the violations of each invalid entry
+	OnInvalid func(*EntryError)
+	// MaxReported limits the number of invalid entries kept in the report,
+	// defaults to DefaultMaxReported. negative values keep all of them
+	MaxReported int
+}
+
+// WithContinue configures a reader to read past invalid entries
+func WithContinue() func(*ReaderConfig) {
+	return func(c *ReaderConfig) {
+		c.Continue = true
+	}
+}
+
+// WithOnInvalid sets a func called with the violations of each invalid entry
+func WithOnInvalid(fn func(*EntryError)) func(*ReaderConfig) {
+	return func(c *ReaderConfig) {
+		c.OnInvalid = fn
+	}
+}
+
+// WithMaxReported sets the number of invalid entries kept in the report
+func WithMaxReported(n int) func(*ReaderConfig) {
+	return func(c *ReaderConfig) {
+		c.MaxReported = n
+	}
+}
+
+// Reader wraps an entry reader, validating each entry against the item
+// schema of the structure as it's read. Entries of array bodies are checked
+// against "items", entries of object bodies against "properties" &
+// "additionalProperties". Constraints on the body as a whole, like
+// "minItems", are left to EntryReader
+type Reader struct {
+	r      dsio.EntryReader
+	cfg    *ReaderConfig
+	object bool
+	// items is the schema of array entries, tuple the schemas of array
+	// entries by position followed by rest
+	items *jsonschema.Schema
+	tuple []*jsonschema.Schema
+	rest  *jsonschema.Schema
+	// props & additional are the schemas of object entries
+	props      jsonschema.Properties
+	additional *jsonschema.Schema
+	report     Report
+}
+
+var _ dsio.EntryReader = (*Reader)(nil)
+
+// NewReader creates a validating reader
+func NewReader(r dsio.EntryReader, opts ...func(*ReaderConfig)) (*Reader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("validate: reader is required")
+	}
+	cfg := &ReaderConfig{MaxReported: DefaultMaxReported}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	st := r.Structure()
+	if st == nil || st.Schema == nil {
+		return nil, fmt.Errorf("validate: %w: a schema object is required", dataset.ErrInvalidSchema)
+	}
+	tlt, err := dsio.GetTopLevelType(st)
+	if err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	rs, err := st.JSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+
+	vr := &Reader{r: r, cfg: cfg, object: tlt == "object"}
+	root := rs.Schema.Validators
+	if vr.object {
+		if props, ok := root["properties"].(*jsonschema.Properties); ok {
+			vr.props = *props
+		}
+		if ap, ok := root["additionalProperties"].(*jsonschema.AdditionalProperties); ok {
+			vr.additional = ap.Schema
+		}
+	} else if items, ok := root["items"].(*jsonschema.Items); ok {
+		if _, single := st.Schema["items"].(map[string]interface{}); single && len(items.Schemas) == 1 {
+			vr.items = items.Schemas[0]
+		} else {
+			vr.tuple = items.Schemas
+			if ai, ok := root["additionalItems"].(*jsonschema.AdditionalItems); ok {
+				vr.rest = ai.Schema
+			}
+		}
+	}
+	return vr, nil
+}
+
+// Structure gives the structure of read entries
+func (r *Reader) Structure() *dataset.Structure {
+	return r.r.Structure()
+}
+
+// Report gives the validity of the entries read so far
+func (r *Reader) Report() Report {
+	return r.report
+}
+
+// ReadEntry reads & validates the next entry
+func (r *Reader) ReadEntry() (dsio.Entry, error) {
+	ent, err := r.r.ReadEntry()
+	if err != nil {
+		return ent, err
+	}
+	i := r.report.Entries
+	r.report.Entries++
+
+	sch, path := r.itemSchema(i, ent)
+	if sch == nil {
+		return ent, nil
+	}
+	doc, err := jsonValue(ent.Value)
+	if err != nil {
+		return ent, fmt.Errorf("validate: entry %d: %w", i, err)
+	}
+	errs := []jsonschema.ValError{}
+	sch.Validate(path, doc, &errs)
+	if len(errs) == 0 {
+		return ent, nil
+	}
+
+	e := &EntryError{Index: i, Key: ent.Key, Errors: errs}
+	r.report.Invalid++
+	if r.cfg.MaxReported < 0 || len(r.report.Errors) < r.cfg.MaxReported {
+		r.report.Errors = append(r.report.Errors, e)
+	}
+	if r.cfg.OnInvalid != nil {
+		r.cfg.OnInvalid(e)
+	}
+	if !r.cfg.Continue {
+		return ent, e
+	}
+	return ent, nil
+}
+
+// itemSchema gives the schema an entry is checked against & the property
+// path of the entry, nil if the schema doesn't constrain the entry
+func (r *Reader) itemSchema(i int, ent dsio.Entry) (*jsonschema.Schema, string) {
+	if r.object {
+		path := "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(ent.Key)
+		if sch, ok := r.props[ent.Key]; ok {
+			return sch, path
+		}
+		return r.additional, path
+	}
+	path := fmt.Sprintf("/%d", i)
+	if r.items != nil {
+		return r.items, path
+	}
+	if i < len(r.tuple) {
+		return r.tuple[i], path
+	}
+	return r.rest, path
+}
+
+// jsonValue gives the JSON decoding of v, the kind of value schemas
+// validate. Readers give typed values like int64 or vals.Decimal
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// Close closes the underlying reader
+func (r *Reader) Close() error {
+	return r.r.Close()
+}
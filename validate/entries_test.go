@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+var entriesStructure = &dataset.Structure{
+	Format: "json",
+	Schema: map[string]interface{}{
+		"type":     "array",
+		"minItems": 10,
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "title", "type": "string"},
+				map[string]interface{}{"title": "duration", "type": "integer", "minimum": 0},
+			},
+		},
+	},
+}
+
+const entriesBody = `[
+	["alien", 117],
+	["the thing", "109"],
+	["solaris", -1],
+	[false, -1]
+]`
+
+func newEntriesReader(t *testing.T, st *dataset.Structure, data string, opts ...func(*ReaderConfig)) *Reader {
+	src, err := dsio.NewJSONReader(st, bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(src, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestReader(t *testing.T) {
+	r := newEntriesReader(t, entriesStructure, entriesBody)
+	if _, err := r.ReadEntry(); err != nil {
+		t.Fatalf("expected the first entry to be valid, got: %s", err)
+	}
+	_, err := r.ReadEntry()
+	e, ok := err.(*EntryError)
+	if !ok {
+		t.Fatalf("expected an *EntryError, got: %v", err)
+	}
+	if expect := `entry 1 is invalid: /1/1: "109" type should be integer`; e.Error() != expect {
+		t.Errorf("error mismatch. expected: %s, got: %s", expect, e.Error())
+	}
+
+	// continuing reads every entry, reporting the invalid ones
+	var called []int
+	r = newEntriesReader(t, entriesStructure, entriesBody, WithContinue(), WithMaxReported(2), WithOnInvalid(func(e *EntryError) {
+		called = append(called, e.Index)
+	}))
+	read := 0
+	for {
+		if _, err := r.ReadEntry(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		read++
+	}
+	if read != 4 {
+		t.Errorf("expected 4 entries to be read, got: %d", read)
+	}
+	if len(called) != 3 || called[0] != 1 || called[2] != 3 {
+		t.Errorf("expected invalid entries 1, 2 & 3 to be handled, got: %v", called)
+	}
+	rep := r.Report()
+	if rep.Entries != 4 || rep.Invalid != 3 || len(rep.Errors) != 2 {
+		t.Errorf("report mismatch. expected 4 entries, 3 invalid & 2 errors, got: %d, %d & %d", rep.Entries, rep.Invalid, len(rep.Errors))
+	}
+
+	// every violation of an entry is listed. constraints on the body as a
+	// whole, like minItems, aren't checked
+	r = newEntriesReader(t, entriesStructure, `[[false, -1]]`)
+	expect := "entry 0 is invalid: /0/0: false type should be string (and 1 more)"
+	if _, err := r.ReadEntry(); err == nil || err.Error() != expect {
+		t.Errorf("error mismatch. expected: %s, got: %v", expect, err)
+	}
+	if _, err := r.ReadEntry(); err != io.EOF {
+		t.Errorf("expected EOF, got: %v", err)
+	}
+}
+
+func TestReaderObjects(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"a/b": map[string]interface{}{"type": "string"},
+			},
+			"additionalProperties": map[string]interface{}{"type": "integer"},
+		},
+	}
+	r := newEntriesReader(t, st, `{"a/b": 1, "c": 2, "d": "x"}`, WithContinue(), WithMaxReported(-1))
+	for {
+		if _, err := r.ReadEntry(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep := r.Report()
+	if len(rep.Errors) != 2 {
+		t.Fatalf("expected 2 invalid entries, got: %d", len(rep.Errors))
+	}
+	if got := rep.Errors[0].Errors[0].PropertyPath; rep.Errors[0].Key != "a/b" || got != "/a~1b" {
+		t.Errorf("expected key a/b at path /a~1b, got: %s at %s", rep.Errors[0].Key, got)
+	}
+	if rep.Errors[1].Key != "d" {
+		t.Errorf("expected key d to be invalid, got: %s", rep.Errors[1].Key)
+	}
+}
+
+func TestReaderTuples(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type":            "array",
+			"items":           []interface{}{map[string]interface{}{"type": "string"}},
+			"additionalItems": map[string]interface{}{"type": "number"},
+		},
+	}
+	r := newEntriesReader(t, st, `["a", 1.5, "b"]`)
+	for i := 0; i < 2; i++ {
+		if _, err := r.ReadEntry(); err != nil {
+			t.Fatalf("entry %d: %s", i, err)
+		}
+	}
+	if _, err := r.ReadEntry(); err == nil {
+		t.Errorf("expected additional items to be validated")
+	}
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	if _, err := NewReader(nil); err == nil || err.Error() != "validate: reader is required" {
+		t.Errorf("expected a nil reader error, got: %v", err)
+	}
+}
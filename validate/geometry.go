@@ -0,0 +1,381 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/geo"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/dataset/vals"
+)
+
+// GeometryError is a single invalid coordinate or geometry in a body
+type GeometryError struct {
+	// Entry is the index of the invalid entry
+	Entry int
+	// Column is the title or key of the invalid value
+	Column string
+	// Message describes the problem
+	Message string
+}
+
+// Error implements the error interface
+func (e GeometryError) Error() string {
+	return fmt.Sprintf("entry %d: %s: %s", e.Entry, e.Column, e.Message)
+}
+
+// Geometry consumes a reader, checking coordinates according to the geometry
+// rules of the reader's structure & returning any invalid entries. Bodies in
+// a projected CRS are converted to WGS84 degrees for range & bounding box
+// checks. Null & empty values are skipped. Geometry returns no errors for
+// structures without geometry rules
+func Geometry(r dsio.EntryReader) ([]GeometryError, error) {
+	st := r.Structure()
+	if st == nil || st.Geometry == nil {
+		return nil, nil
+	}
+	rules := st.Geometry
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("geometry: %w", err)
+	}
+
+	gc := &geometryChecker{rules: rules}
+	if st.CRS != "" {
+		p, err := geo.ProjectionFor(st.CRS)
+		if err != nil {
+			return nil, fmt.Errorf("geometry: %w", err)
+		}
+		gc.proj = p
+	}
+
+	var titles []string
+	if cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema); err == nil {
+		titles = cols.Titles()
+	}
+
+	var errs []GeometryError
+	err := dsio.EachEntry(r, func(i int, ent dsio.Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		row, err := columnValues(ent.Value, titles)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		report := func(col, msg string) {
+			errs = append(errs, GeometryError{Entry: i, Column: col, Message: msg})
+		}
+		if rules.X != "" {
+			gc.point(row[rules.X], row[rules.Y], report)
+		}
+		if rules.Geometry != "" {
+			gc.geometry(row[rules.Geometry], report)
+		}
+		return nil
+	})
+	if err != nil {
+		return errs, fmt.Errorf("error reading values: %w", err)
+	}
+	return errs, nil
+}
+
+// columnValues maps the values of an array or object entry by column
+func columnValues(v interface{}, titles []string) (map[string]interface{}, error) {
+	switch row := v.(type) {
+	case map[string]interface{}:
+		return row, nil
+	case []interface{}:
+		if titles == nil {
+			return nil, fmt.Errorf("array rows require a tabular schema")
+		}
+		m := make(map[string]interface{}, len(row))
+		for i, val := range row {
+			if i < len(titles) {
+				m[titles[i]] = val
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("expected an array or object, got %T", v)
+}
+
+type geometryChecker struct {
+	rules *dataset.GeometryRules
+	proj  geo.Projection
+}
+
+// point checks an x & y column pair
+func (gc *geometryChecker) point(xv, yv interface{}, report func(col, msg string)) {
+	if isBlank(xv) || isBlank(yv) {
+		return
+	}
+	x, ok := number(xv)
+	if !ok {
+		report(gc.rules.X, fmt.Sprintf("expected a number, got %T", xv))
+		return
+	}
+	y, ok := number(yv)
+	if !ok {
+		report(gc.rules.Y, fmt.Sprintf("expected a number, got %T", yv))
+		return
+	}
+	if msg := gc.position(x, y); msg != "" {
+		report(gc.rules.X, msg)
+	}
+}
+
+// position checks a single coordinate pair, returning a description of any
+// problem
+func (gc *geometryChecker) position(x, y float64) string {
+	if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+		return fmt.Sprintf("coordinate (%g, %g) is not finite", x, y)
+	}
+	lon, lat := x, y
+	if gc.proj != nil {
+		lon, lat = gc.proj.Inverse(x, y)
+	}
+	if lon < -180 || lon > 180 {
+		return fmt.Sprintf("longitude %g out of range", lon)
+	}
+	if lat < -90 || lat > 90 {
+		return fmt.Sprintf("latitude %g out of range", lat)
+	}
+	if b := gc.rules.BBox; b != nil && !inBBox(b, lon, lat) {
+		return fmt.Sprintf("coordinate (%.7g, %.7g) is outside the bounding box", lon, lat)
+	}
+	return ""
+}
+
+func inBBox(b []float64, lon, lat float64) bool {
+	if lat < b[1] || lat > b[3] {
+		return false
+	}
+	if b[0] > b[2] {
+		// box crosses the antimeridian
+		return lon >= b[0] || lon <= b[2]
+	}
+	return lon >= b[0] && lon <= b[2]
+}
+
+// geometry checks a GeoJSON geometry value, which may be a JSON string
+func (gc *geometryChecker) geometry(v interface{}, report func(col, msg string)) {
+	if isBlank(v) {
+		return
+	}
+	col := gc.rules.Geometry
+	if s, ok := v.(string); ok {
+		var obj interface{}
+		if err := json.Unmarshal([]byte(s), &obj); err != nil {
+			report(col, fmt.Sprintf("invalid GeoJSON: %s", err))
+			return
+		}
+		v = obj
+	}
+	g, ok := v.(map[string]interface{})
+	if !ok {
+		report(col, fmt.Sprintf("expected a GeoJSON geometry object, got %T", v))
+		return
+	}
+	if msg := gc.checkGeometry(g); msg != "" {
+		report(col, msg)
+	}
+}
+
+// checkGeometry gives a description of the first problem in a geometry
+func (gc *geometryChecker) checkGeometry(g map[string]interface{}) string {
+	t, _ := g["type"].(string)
+	if t == "GeometryCollection" {
+		geoms, ok := g["geometries"].([]interface{})
+		if !ok {
+			return "geometries are required"
+		}
+		for i, sub := range geoms {
+			m, ok := sub.(map[string]interface{})
+			if !ok {
+				return fmt.Sprintf("geometries.%d: expected an object", i)
+			}
+			if msg := gc.checkGeometry(m); msg != "" {
+				return fmt.Sprintf("geometries.%d: %s", i, msg)
+			}
+		}
+		return ""
+	}
+
+	coords, ok := g["coordinates"]
+	if !ok {
+		return "coordinates are required"
+	}
+	var depth int
+	switch t {
+	case "Point":
+		depth = 0
+	case "MultiPoint", "LineString":
+		depth = 1
+	case "MultiLineString", "Polygon":
+		depth = 2
+	case "MultiPolygon":
+		depth = 3
+	default:
+		return fmt.Sprintf("invalid type %q", g["type"])
+	}
+	pos, msg := positions(coords, depth, "coordinates")
+	if msg != "" {
+		return msg
+	}
+
+	for _, p := range flatten(pos, depth) {
+		if msg := gc.position(p[0], p[1]); msg != "" {
+			return msg
+		}
+	}
+
+	switch t {
+	case "LineString":
+		if len(pos.([][]float64)) < 2 {
+			return "coordinates: a line string requires at least 2 positions"
+		}
+	case "MultiLineString":
+		for i, line := range pos.([][][]float64) {
+			if len(line) < 2 {
+				return fmt.Sprintf("coordinates.%d: a line string requires at least 2 positions", i)
+			}
+		}
+	case "Polygon":
+		return gc.polygon(pos.([][][]float64), "coordinates")
+	case "MultiPolygon":
+		for i, poly := range pos.([][][][]float64) {
+			if msg := gc.polygon(poly, fmt.Sprintf("coordinates.%d", i)); msg != "" {
+				return msg
+			}
+		}
+	}
+	return ""
+}
+
+// polygon checks the rings of a polygon are closed & wound as required
+func (gc *geometryChecker) polygon(rings [][][]float64, path string) string {
+	for i, ring := range rings {
+		if len(ring) < 4 {
+			return fmt.Sprintf("%s.%d: a linear ring requires at least 4 positions", path, i)
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Sprintf("%s.%d: linear ring is not closed", path, i)
+		}
+		if gc.rules.Winding == "" {
+			continue
+		}
+		ccw := gc.rules.Winding == dataset.WindingCCW
+		if i > 0 {
+			// holes wind opposite the exterior ring
+			ccw = !ccw
+		}
+		area := signedArea(ring)
+		if area == 0 {
+			return fmt.Sprintf("%s.%d: linear ring has no area", path, i)
+		}
+		if (area > 0) != ccw {
+			want := "counterclockwise"
+			if !ccw {
+				want = "clockwise"
+			}
+			return fmt.Sprintf("%s.%d: linear ring must wind %s", path, i, want)
+		}
+	}
+	return ""
+}
+
+// signedArea is positive for counterclockwise rings
+func signedArea(ring [][]float64) float64 {
+	var a float64
+	for i := 0; i < len(ring)-1; i++ {
+		a += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return a / 2
+}
+
+// positions decodes GeoJSON coordinates nested depth levels above single
+// positions, giving []float64, [][]float64, [][][]float64 or [][][][]float64
+func positions(v interface{}, depth int, path string) (interface{}, string) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Sprintf("%s: expected an array", path)
+	}
+	if depth == 0 {
+		if len(arr) < 2 {
+			return nil, fmt.Sprintf("%s: a position requires at least 2 values", path)
+		}
+		pos := make([]float64, len(arr))
+		for i, n := range arr {
+			f, ok := number(n)
+			if !ok {
+				return nil, fmt.Sprintf("%s.%d: expected a number, got %T", path, i, n)
+			}
+			pos[i] = f
+		}
+		return pos, ""
+	}
+
+	var out interface{}
+	switch depth {
+	case 1:
+		out = make([][]float64, 0, len(arr))
+	case 2:
+		out = make([][][]float64, 0, len(arr))
+	case 3:
+		out = make([][][][]float64, 0, len(arr))
+	}
+	for i, sub := range arr {
+		p, msg := positions(sub, depth-1, fmt.Sprintf("%s.%d", path, i))
+		if msg != "" {
+			return nil, msg
+		}
+		switch o := out.(type) {
+		case [][]float64:
+			out = append(o, p.([]float64))
+		case [][][]float64:
+			out = append(o, p.([][]float64))
+		case [][][][]float64:
+			out = append(o, p.([][][]float64))
+		}
+	}
+	return out, ""
+}
+
+// flatten lists every position of decoded coordinates
+func flatten(v interface{}, depth int) [][]float64 {
+	switch depth {
+	case 0:
+		return [][]float64{v.([]float64)}
+	case 1:
+		return v.([][]float64)
+	}
+	var out [][]float64
+	switch c := v.(type) {
+	case [][][]float64:
+		for _, sub := range c {
+			out = append(out, sub...)
+		}
+	case [][][][]float64:
+		for _, sub := range c {
+			out = append(out, flatten(sub, 2)...)
+		}
+	}
+	return out
+}
+
+// number reads numeric values, which unlike vals.ToFloat excludes strings
+func number(v interface{}) (float64, bool) {
+	if _, ok := v.(string); ok {
+		return 0, false
+	}
+	f, err := vals.ToFloat(v)
+	return f, err == nil
+}
+
+func isBlank(v interface{}) bool {
+	return v == nil || v == ""
+}
@@ -0,0 +1,128 @@
+package validate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestGeometry(t *testing.T) {
+	points := &dataset.GeometryRules{X: "lon", Y: "lat"}
+	shapes := &dataset.GeometryRules{Geometry: "geom", Winding: dataset.WindingCCW}
+	germany := []float64{5.8, 47.2, 15.1, 55.1}
+
+	cases := []struct {
+		description string
+		crs         string
+		rules       *dataset.GeometryRules
+		body        string
+		err         string
+		errors      []string
+	}{
+		{"no rules", "", nil, `[{"lon":500,"lat":0}]`, "", nil},
+		{"valid points", "", points, `[{"lon":9.18,"lat":48.78},{"lon":null,"lat":null},{"lon":-180,"lat":90}]`, "", nil},
+		{"out of range points", "", points, `[{"lon":190,"lat":0},{"lon":9,"lat":-91},{"lon":"9","lat":0}]`, "", []string{
+			"entry 0: lon: longitude 190 out of range",
+			"entry 1: lon: latitude -91 out of range",
+			"entry 2: lon: expected a number, got string",
+		}},
+		{"bounding box", "", &dataset.GeometryRules{X: "lon", Y: "lat", BBox: germany}, `[{"lon":9.18,"lat":48.78},{"lon":2.35,"lat":48.86}]`, "", []string{
+			"entry 1: lon: coordinate (2.35, 48.86) is outside the bounding box",
+		}},
+		{"antimeridian bounding box", "", &dataset.GeometryRules{X: "lon", Y: "lat", BBox: []float64{170, -50, -170, -30}}, `[{"lon":175,"lat":-40},{"lon":-175,"lat":-40},{"lon":0,"lat":-40}]`, "", []string{
+			"entry 2: lon: coordinate (0, -40) is outside the bounding box",
+		}},
+		{"projected bounding box", "EPSG:25832", &dataset.GeometryRules{X: "x", Y: "y", BBox: germany}, `[{"x":513223.54,"y":5403015.52},{"x":513223.54,"y":1000000}]`, "", []string{
+			"entry 1: x: coordinate (9.120323, 9.046543) is outside the bounding box",
+		}},
+		{"unsupported crs", "EPSG:31467", points, `[]`, `geometry: unsupported CRS "EPSG:31467"`, nil},
+		{"invalid rules", "", &dataset.GeometryRules{Winding: "cw"}, `[]`, "geometry: one of x & y or geometry columns is required", nil},
+		{"valid geometries", "", shapes, `[
+			{"geom":{"type":"Point","coordinates":[9.18,48.78]}},
+			{"geom":"{\"type\":\"LineString\",\"coordinates\":[[9,48],[10,49]]}"},
+			{"geom":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]],[[0.2,0.2],[0.2,0.8],[0.8,0.8],[0.2,0.2]]]}},
+			{"geom":{"type":"GeometryCollection","geometries":[{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}]}},
+			{"geom":null}
+		]`, "", nil},
+		{"invalid geometries", "", shapes, `[
+			{"geom":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}},
+			{"geom":{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]}},
+			{"geom":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]],[[0.1,0.1],[0.9,0.1],[0.9,0.9],[0.1,0.1]]]]}},
+			{"geom":{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}},
+			{"geom":{"type":"LineString","coordinates":[[0,0]]}},
+			{"geom":{"type":"Point","coordinates":[200,0]}},
+			{"geom":{"type":"Point","coordinates":["a",0]}},
+			{"geom":{"type":"Circle","coordinates":[0,0]}},
+			{"geom":{"type":"GeometryCollection","geometries":[{"type":"Point"}]}},
+			{"geom":"{nope"},
+			{"geom":[0,0]}
+		]`, "", []string{
+			"entry 0: geom: coordinates.0: linear ring is not closed",
+			"entry 1: geom: coordinates.0: linear ring must wind counterclockwise",
+			"entry 2: geom: coordinates.0.1: linear ring must wind clockwise",
+			"entry 3: geom: coordinates.0: a linear ring requires at least 4 positions",
+			"entry 4: geom: coordinates: a line string requires at least 2 positions",
+			"entry 5: geom: longitude 200 out of range",
+			"entry 6: geom: coordinates.0: expected a number, got string",
+			`entry 7: geom: invalid type "Circle"`,
+			"entry 8: geom: geometries.0: coordinates are required",
+			"entry 9: geom: invalid GeoJSON: invalid character 'n' looking for beginning of object key string",
+			"entry 10: geom: expected a GeoJSON geometry object, got []interface {}",
+		}},
+		{"unchecked winding", "", &dataset.GeometryRules{Geometry: "geom"}, `[{"geom":{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]}}]`, "", nil},
+	}
+
+	for _, c := range cases {
+		st := &dataset.Structure{Format: "json", CRS: c.crs, Geometry: c.rules, Schema: dataset.BaseSchemaArray}
+		r, err := dsio.NewJSONReader(st, bytes.NewBufferString(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errs, err := Geometry(r)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("%s error mismatch. expected: %q, got: %v", c.description, c.err, err)
+			continue
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("%s: error length mismatch. expected: %d, got: %d: %v", c.description, len(c.errors), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.errors[j] {
+				t.Errorf("%s: geometry error %d mismatch. expected: %q, got: %q", c.description, j, c.errors[j], e.Error())
+			}
+		}
+	}
+}
+
+func TestGeometryArrayRows(t *testing.T) {
+	st := &dataset.Structure{
+		Format:   "json",
+		Geometry: &dataset.GeometryRules{X: "lon", Y: "lat"},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "lon", "type": "number"},
+					map[string]interface{}{"title": "lat", "type": "number"},
+				},
+			},
+		},
+	}
+	r, err := dsio.NewJSONReader(st, bytes.NewBufferString(`[["a",9.18,48.78],["b",9.18,148.78]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := Geometry(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Error() != "entry 1: lon: latitude 148.78 out of range" {
+		t.Errorf("errors mismatch. got: %v", errs)
+	}
+}
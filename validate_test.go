@@ -0,0 +1,118 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatasetValidate(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := func() *Dataset {
+		return &Dataset{
+			Qri:       KindDataset.String(),
+			Commit:    &Commit{Title: "initial commit", Timestamp: ts},
+			Structure: &Structure{Format: "csv", Schema: BaseSchemaArray},
+		}
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Errorf("expected valid dataset to pass. got: %s", err)
+	}
+	if err := NewDatasetRef("/ipfs/QmFoo").Validate(); err != nil {
+		t.Errorf("expected dataset reference to pass. got: %s", err)
+	}
+	if err := (*Dataset)(nil).Validate(); err == nil {
+		t.Errorf("expected nil dataset to error")
+	}
+
+	cases := []struct {
+		description string
+		modify      func(ds *Dataset)
+		err         string
+	}{
+		{"bad kind", func(ds *Dataset) { ds.Qri = "st:0" }, "invalid kind: 'st:0'. expected type 'ds'"},
+		{"short kind", func(ds *Dataset) { ds.Qri = "ds" }, "invalid kind: 'ds'. kind must be in the form [type]:[version]"},
+		{"no commit", func(ds *Dataset) { ds.Commit = nil }, "commit is required"},
+		{"no commit title", func(ds *Dataset) { ds.Commit.Title = "" }, "commit: title is required"},
+		{"no timestamp", func(ds *Dataset) { ds.Commit.Timestamp = time.Time{} }, "commit: timestamp is required"},
+		{"future timestamp", func(ds *Dataset) { ds.Commit.Timestamp = time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC) }, "commit: timestamp 3000-01-01T00:00:00Z is in the future"},
+		{"no structure", func(ds *Dataset) { ds.Structure = nil }, "structure is required"},
+		{"no format", func(ds *Dataset) { ds.Structure.Format = "" }, "structure: format is required"},
+		{"bad format", func(ds *Dataset) { ds.Structure.Format = "tsv" }, "structure: invalid data format: `tsv`"},
+		{"bad compression", func(ds *Dataset) { ds.Structure.Compression = "zip" }, `structure: invalid compression type "zip"`},
+		{"negative length", func(ds *Dataset) { ds.Structure.Length = -1 }, "structure: length cannot be negative"},
+		{"relative url", func(ds *Dataset) { ds.Meta = &Meta{HomeURL: "example.com"} }, "meta: homeURL: 'example.com' must be an absolute url"},
+		{"bad url", func(ds *Dataset) { ds.Meta = &Meta{AccessURL: "http://[::1"} }, `meta: accessURL: parse "http://[::1": missing ']' in host`},
+		{"bad periodicity", func(ds *Dataset) { ds.Meta = &Meta{AccrualPeriodicity: "1W"} }, "meta: invalid accrual periodicity '1W': must be an ISO 8601 duration or frequency vocabulary term"},
+		{"bad access level", func(ds *Dataset) { ds.Meta = &Meta{AccessLevel: "secret"} }, "meta: invalid access level 'secret'. must be one of: public, internal, restricted"},
+		{"bad sensitivity", func(ds *Dataset) { ds.Meta = &Meta{Sensitivity: "top"} }, "meta: invalid sensitivity 'top'. must be one of: low, moderate, high"},
+		{"public sensitive data", func(ds *Dataset) { ds.Meta = &Meta{Sensitivity: "high"} }, "meta: sensitivity 'high' requires an access level other than 'public'"},
+		{"empty license", func(ds *Dataset) { ds.Meta = &Meta{License: &License{}} }, "meta: license: type or url is required"},
+		{"invalid license", func(ds *Dataset) { ds.Meta = &Meta{License: &License{Type: "foo bar"}} }, "meta: license: invalid license type 'foo bar'. licenses without a url must have an SPDX identifier type"},
+		{"nil citation", func(ds *Dataset) { ds.Meta = &Meta{Citations: []*Citation{nil}} }, "meta: citations index 0 is empty"},
+		{"bad doi", func(ds *Dataset) { ds.Meta = &Meta{Identifiers: []*Identifier{{Scheme: "doi", Value: "zenodo.1234"}}} }, "meta: identifiers index 0: invalid doi 'zenodo.1234'"},
+		{"unknown theme", func(ds *Dataset) {
+			ds.Meta = &Meta{Themes: []*Theme{{URI: "http://publications.europa.eu/resource/authority/data-theme/CARS"}}}
+		}, "meta: themes index 0: unknown theme 'http://publications.europa.eu/resource/authority/data-theme/CARS' in vocabulary 'http://publications.europa.eu/resource/authority/data-theme'"},
+		{"publisher name", func(ds *Dataset) { ds.Meta = &Meta{Publisher: &Publisher{URL: "https://mfdz.de"}} }, "meta: publisher: name is required"},
+		{"publisher url", func(ds *Dataset) { ds.Meta = &Meta{Publisher: &Publisher{Name: "MFDZ", URL: "mfdz.de"}} }, "meta: publisher.url: 'mfdz.de' must be an absolute url"},
+		{"empty contact", func(ds *Dataset) { ds.Meta = &Meta{ContactPoint: &ContactPoint{Name: "Data Team"}} }, "meta: contactPoint: email or url is required"},
+		{"contact email", func(ds *Dataset) { ds.Meta = &Meta{ContactPoint: &ContactPoint{Email: "data at mfdz.de"}} }, "meta: contactPoint.email: invalid email address 'data at mfdz.de'"},
+		{"bad language tag", func(ds *Dataset) {
+			ds.Meta = &Meta{Translations: map[string]*MetaTranslation{"english": {Title: "a"}}}
+		}, "meta: translations: invalid language tag 'english'"},
+		{"nil translation", func(ds *Dataset) { ds.Meta = &Meta{Translations: map[string]*MetaTranslation{"en": nil}} }, "meta: translations 'en' is empty"},
+		{"resource path", func(ds *Dataset) {
+			ds.Transform = &Transform{Resources: map[string]*TransformResource{"c": {}, "a": {}, "b": {}}}
+		}, "transform: resource 'a': path is required"},
+		{"transform file name", func(ds *Dataset) {
+			ds.Transform = &Transform{Files: map[string]*TransformFile{"../a.star": {Path: "/ipfs/QmA"}}}
+		}, "transform: file '../a.star': name must be a relative path within the transform"},
+		{"empty transform file", func(ds *Dataset) {
+			ds.Transform = &Transform{Files: map[string]*TransformFile{"a.star": {}}}
+		}, "transform: file 'a.star': path or scriptBytes is required"},
+		{"duplicate step", func(ds *Dataset) {
+			ds.Transform = &Transform{Steps: []*TransformStep{{Name: "clean", ScriptPath: "/a"}, {Name: "clean", ScriptPath: "/b"}}}
+		}, "transform: steps index 1: duplicate step name 'clean'"},
+		{"anonymize method", func(ds *Dataset) {
+			ds.Transform = &Transform{Anonymize: &AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "email", Method: "encrypt"}}}}
+		}, "transform: anonymize: column 'email': unknown method 'encrypt'"},
+		{"anonymize salt", func(ds *Dataset) {
+			ds.Transform = &Transform{Anonymize: &AnonymizeSpec{Columns: []*AnonymizeColumn{{Column: "email", Method: AnonymizeHash, Salt: "salt"}}}}
+		}, "transform: anonymize: column 'email': salt secret 'salt' is not declared"},
+		{"sql resource drift", func(ds *Dataset) {
+			ds.Transform = &Transform{Syntax: "sql", ScriptBytes: []byte("select * from mfdz/stops"), Resources: map[string]*TransformResource{"a": {Path: "mfdz/routes"}}}
+		}, "transform: sql: table 'mfdz/stops' is not a transform resource"},
+		{"viz kind", func(ds *Dataset) { ds.Viz = &Viz{Qri: "rm:0", Format: "html"} }, "viz: invalid kind: 'rm:0'. expected type 'vz'"},
+		{"viz output format", func(ds *Dataset) { ds.Viz = &Viz{Outputs: []*VizOutput{{Format: "gif"}}} }, "viz: outputs index 0: unsupported format 'gif'"},
+		{"viz png dimensions", func(ds *Dataset) { ds.Viz = &Viz{Outputs: []*VizOutput{{Format: "png", Width: 800}}} }, "viz: outputs index 0: png output requires width and height"},
+		{"viz binding column", func(ds *Dataset) {
+			ds.Structure.Schema = map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "array", "items": []interface{}{map[string]interface{}{"title": "stop_id", "type": "string"}}},
+			}
+			ds.Viz = &Viz{Binding: &VizBinding{Columns: []string{"stop_id", "stop_name"}}}
+		}, "viz: binding: unknown column 'stop_name'"},
+		{"readme kind", func(ds *Dataset) { ds.Readme = &Readme{Qri: "vz:0", Format: "md"} }, "readme: invalid kind: 'vz:0'. expected type 'rm'"},
+		{"provenance times", func(ds *Dataset) { ds.Provenance = &Provenance{Started: ts, Ended: ts.Add(-time.Second)} }, "provenance: ended 2018-12-31T23:59:59Z is before started 2019-01-01T00:00:00Z"},
+		{"provenance input", func(ds *Dataset) { ds.Provenance = &Provenance{Inputs: []*ProvenanceInput{{Name: "stops"}}} }, "provenance: inputs index 0: path is required"},
+		{"provenance agent", func(ds *Dataset) { ds.Provenance = &Provenance{Agent: &ProvenanceAgent{Type: "robot"}} }, "provenance: agent: invalid type 'robot'"},
+		{"preview head", func(ds *Dataset) { ds.Preview = &Preview{Head: 3, Indexes: []int{0, 1}} }, "preview: head 3 is greater than the 2 sampled entries"},
+		{"stats entries", func(ds *Dataset) { ds.Stats = &Stats{Entries: -1} }, "stats: entries cannot be negative"},
+		{"stats kind", func(ds *Dataset) { ds.Stats = &Stats{Qri: "pr:0", Entries: 1} }, "stats: invalid kind: 'pr:0'. expected type 'sa'"},
+		{"provenance run", func(ds *Dataset) { ds.Provenance = &Provenance{Run: &RunState{Duration: -time.Second}} }, "provenance: run: duration cannot be negative"},
+	}
+
+	for _, c := range cases {
+		ds := valid()
+		c.modify(ds)
+		err := ds.Validate()
+		if err == nil {
+			t.Errorf("case '%s': expected error, got nil", c.description)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("case '%s': error mismatch. expected: '%s', got: '%s'", c.description, c.err, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package vals
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // ConvertDecoded converts an interface that has been decoded into standard go types to a Value
@@ -22,6 +23,10 @@ func ConvertDecoded(d interface{}) (Value, error) {
 		return Integer(v), nil
 	case float64:
 		return Number(v), nil
+	case Decimal, Date, Time, DateTime, Link:
+		return v.(Value), nil
+	case time.Time:
+		return NewDateTime(v), nil
 	case int:
 		return Integer(v), nil
 	case int32:
@@ -68,6 +73,44 @@ func ConvertDecoded(d interface{}) (Value, error) {
 	}
 }
 
+// ToDecoded is the inverse of ConvertDecoded, turning a Value into standard
+// go types. Decimals, temporal values & links have no standard go equivalent
+// & are returned as-is
+func ToDecoded(v Value) interface{} {
+	switch t := v.(type) {
+	case nil, Null:
+		return nil
+	case String:
+		return string(t)
+	case Integer:
+		return int(t)
+	case Number:
+		return float64(t)
+	case Boolean:
+		return bool(t)
+	case ObjectValue:
+		return ToDecoded(t.Value)
+	case *Array:
+		return ToDecoded(*t)
+	case Array:
+		arr := make([]interface{}, len(t))
+		for i, val := range t {
+			arr[i] = ToDecoded(val)
+		}
+		return arr
+	case *Object:
+		return ToDecoded(*t)
+	case Object:
+		obj := make(map[string]interface{}, len(t))
+		for key, val := range t {
+			obj[key] = ToDecoded(val)
+		}
+		return obj
+	default:
+		return v
+	}
+}
+
 // UnmarshalJSON turns a slice of JSON bytes into a Value
 func UnmarshalJSON(data []byte) (v Value, err error) {
 	switch ParseType(data) {
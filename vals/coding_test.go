@@ -3,6 +3,7 @@ package vals
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -65,6 +66,27 @@ func TestConvertDecoded(t *testing.T) {
 	}
 }
 
+func TestToDecoded(t *testing.T) {
+	dec, _ := ParseDecimal("1.50")
+	in := map[string]interface{}{
+		"a": 1,
+		"b": 1.5,
+		"c": nil,
+		"d": true,
+		"e": "foo",
+		"f": []interface{}{"x", map[string]interface{}{"y": 2}},
+		"g": dec,
+	}
+	v, err := ConvertDecoded(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ToDecoded(v)
+	if !reflect.DeepEqual(in, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", in, got)
+	}
+}
+
 func TestUnmarshalJSON(t *testing.T) {
 	cases := []struct {
 		input  string
@@ -0,0 +1,459 @@
+package vals
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale configures the locale-dependent parts of coercing text to values.
+// The zero Locale reads numbers with a '.' decimal separator & no digit
+// grouping, slash separated dates day first, and zone-less times as UTC
+type Locale struct {
+	// DecimalSeparator separates the whole & fractional parts of a number.
+	// defaults to '.'
+	DecimalSeparator rune
+	// GroupSeparator separates groups of digits, eg. thousands. optional
+	GroupSeparator rune
+	// TrueValues & FalseValues are words read as booleans in addition to the
+	// defaults, matched case-insensitively
+	TrueValues  []string
+	FalseValues []string
+	// MonthFirst reads slash separated dates month first, eg. "03/31/2019"
+	MonthFirst bool
+	// Location zone-less times are read in. defaults to UTC
+	Location *time.Location
+}
+
+var (
+	// LocaleDE reads numbers like "1.234,5" & german boolean words
+	LocaleDE = Locale{
+		DecimalSeparator: ',',
+		GroupSeparator:   '.',
+		TrueValues:       []string{"ja", "wahr"},
+		FalseValues:      []string{"nein", "falsch"},
+	}
+	// LocaleUS reads numbers like "1,234.5" & dates like "03/31/2019"
+	LocaleUS = Locale{
+		GroupSeparator: ',',
+		MonthFirst:     true,
+	}
+)
+
+var (
+	// lenientTrueValues & lenientFalseValues are the boolean words lenient
+	// coercion reads in addition to the strconv.ParseBool forms
+	lenientTrueValues  = []string{"true", "t", "yes", "y", "1"}
+	lenientFalseValues = []string{"false", "f", "no", "n", "0"}
+)
+
+// CoerceConfig configures value coercion
+type CoerceConfig struct {
+	// Locale used when reading text
+	Locale Locale
+	// NullValues are strings read as null, eg. "NA" or "-". Matched exactly
+	// after trimming surrounding whitespace
+	NullValues []string
+	// Lenient broadens how text is read: integers may be written with a zero
+	// fractional part, eg. "1.0", and booleans as yes, y, no & n in any case.
+	// By default integer text must parse with strconv.ParseInt & boolean text
+	// with strconv.ParseBool, apart from locale words
+	Lenient bool
+}
+
+// WithLocale sets the locale coercion reads text with
+func WithLocale(l Locale) func(*CoerceConfig) {
+	return func(c *CoerceConfig) {
+		c.Locale = l
+	}
+}
+
+// WithLenient enables lenient coercion of text, see CoerceConfig.Lenient
+func WithLenient() func(*CoerceConfig) {
+	return func(c *CoerceConfig) {
+		c.Lenient = true
+	}
+}
+
+// WithNullValues sets the strings coercion reads as null
+func WithNullValues(tokens ...string) func(*CoerceConfig) {
+	return func(c *CoerceConfig) {
+		c.NullValues = tokens
+	}
+}
+
+// isNull checks if a string is one of the configured null values
+func (c *CoerceConfig) isNull(s string) bool {
+	if len(c.NullValues) == 0 {
+		return false
+	}
+	s = strings.TrimSpace(s)
+	for _, tok := range c.NullValues {
+		if s == strings.TrimSpace(tok) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNull checks if a value reads as null: nil, Null & strings that are one of
+// the configured null values
+func IsNull(v interface{}, opts ...func(*CoerceConfig)) bool {
+	switch t := v.(type) {
+	case nil, Null:
+		return true
+	case string:
+		return coerceConfig(opts).isNull(t)
+	case String:
+		return coerceConfig(opts).isNull(string(t))
+	}
+	return false
+}
+
+func coerceConfig(opts []func(*CoerceConfig)) *CoerceConfig {
+	cfg := &CoerceConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// CoercionError occurs when a value cannot be converted to the requested
+// type
+type CoercionError struct {
+	// Value that failed to coerce
+	Value interface{}
+	// Target names the type coercion was attempting to produce
+	Target string
+	// Reason describes why coercion failed
+	Reason string
+}
+
+// Error implements the error interface
+func (e *CoercionError) Error() string {
+	if s, ok := e.Value.(fmt.Stringer); ok {
+		return fmt.Sprintf("cannot coerce %s to %s: %s", s.String(), e.Target, e.Reason)
+	}
+	return fmt.Sprintf("cannot coerce %#v to %s: %s", e.Value, e.Target, e.Reason)
+}
+
+// ToInt coerces a value to an integer. Strings are read with the configured
+// locale, and must be integer text unless coercion is lenient. Floats &
+// decimals coerce only if they have no fractional part.
+// Booleans never coerce to integers
+func ToInt(v interface{}, opts ...func(*CoerceConfig)) (int64, error) {
+	fail := func(reason string) (int64, error) {
+		return 0, &CoercionError{Value: v, Target: "integer", Reason: reason}
+	}
+
+	switch t := v.(type) {
+	case int:
+		return int64(t), nil
+	case int8:
+		return int64(t), nil
+	case int16:
+		return int64(t), nil
+	case int32:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case uint8:
+		return int64(t), nil
+	case uint16:
+		return int64(t), nil
+	case uint32:
+		return int64(t), nil
+	case uint64:
+		if t > math.MaxInt64 {
+			return fail("value out of range")
+		}
+		return int64(t), nil
+	case Integer:
+		return int64(t), nil
+	case float32:
+		return floatToInt(float64(t), fail)
+	case float64:
+		return floatToInt(t, fail)
+	case Number:
+		return floatToInt(float64(t), fail)
+	case Decimal:
+		r := t.Rat()
+		if !r.IsInt() {
+			return fail("value has a fractional part")
+		}
+		if !r.Num().IsInt64() {
+			return fail("value out of range")
+		}
+		return r.Num().Int64(), nil
+	case string:
+		return stringToInt(t, coerceConfig(opts), fail)
+	case String:
+		return stringToInt(string(t), coerceConfig(opts), fail)
+	case nil, Null:
+		return fail("value is null")
+	default:
+		return fail(fmt.Sprintf("unsupported type %T", v))
+	}
+}
+
+func floatToInt(f float64, fail func(string) (int64, error)) (int64, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fail("value is not finite")
+	}
+	if f != math.Trunc(f) {
+		return fail("value has a fractional part")
+	}
+	if f > math.MaxInt64 || f < math.MinInt64 {
+		return fail("value out of range")
+	}
+	return int64(f), nil
+}
+
+func stringToInt(s string, cfg *CoerceConfig, fail func(string) (int64, error)) (int64, error) {
+	if cfg.isNull(s) {
+		return fail("value is null")
+	}
+	s = normalizeNumber(s, cfg.Locale)
+	if s == "" {
+		return fail("value is empty")
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	} else if err.(*strconv.NumError).Err == strconv.ErrRange {
+		return fail("value out of range")
+	}
+	if !cfg.Lenient {
+		return fail("invalid integer")
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fail("invalid number")
+	}
+	return floatToInt(f, fail)
+}
+
+// ToFloat coerces a value to a float. Strings are read with the configured
+// locale. Booleans never coerce to floats
+func ToFloat(v interface{}, opts ...func(*CoerceConfig)) (float64, error) {
+	fail := func(reason string) (float64, error) {
+		return 0, &CoercionError{Value: v, Target: "number", Reason: reason}
+	}
+
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case float32:
+		return float64(t), nil
+	case Number:
+		return float64(t), nil
+	case Decimal:
+		return t.Number(), nil
+	case string:
+		return stringToFloat(t, coerceConfig(opts), fail)
+	case String:
+		return stringToFloat(string(t), coerceConfig(opts), fail)
+	case nil, Null:
+		return fail("value is null")
+	case bool, Boolean:
+		return fail(fmt.Sprintf("unsupported type %T", v))
+	}
+	if i, err := ToInt(v); err == nil {
+		return float64(i), nil
+	}
+	return fail(fmt.Sprintf("unsupported type %T", v))
+}
+
+func stringToFloat(s string, cfg *CoerceConfig, fail func(string) (float64, error)) (float64, error) {
+	if cfg.isNull(s) {
+		return fail("value is null")
+	}
+	s = normalizeNumber(s, cfg.Locale)
+	if s == "" {
+		return fail("value is empty")
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		if err.(*strconv.NumError).Err == strconv.ErrRange {
+			return fail("value out of range")
+		}
+		return fail("invalid number")
+	}
+	return f, nil
+}
+
+// NormalizeNumber rewrites locale-formatted number text into the form
+// strconv & ParseDecimal read, eg. "1.234,5" read with LocaleDE becomes
+// "1234.5"
+func NormalizeNumber(s string, l Locale) string {
+	return normalizeNumber(s, l)
+}
+
+// normalizeNumber rewrites locale-formatted number text into the form strconv
+// reads, removing digit grouping & replacing the decimal separator with '.'
+func normalizeNumber(s string, l Locale) string {
+	s = strings.TrimSpace(s)
+	if l.GroupSeparator != 0 {
+		s = strings.Replace(s, string(l.GroupSeparator), "", -1)
+	}
+	if l.DecimalSeparator != 0 && l.DecimalSeparator != '.' {
+		s = strings.Replace(s, string(l.DecimalSeparator), ".", -1)
+	}
+	return s
+}
+
+// ToBool coerces a value to a boolean. Strings are read with
+// strconv.ParseBool, or matched case-insensitively against "true", "t",
+// "yes", "y", "1" & their negations when coercion is lenient, and against any
+// words the configured locale adds. Numbers coerce only if they're 0 or 1
+func ToBool(v interface{}, opts ...func(*CoerceConfig)) (bool, error) {
+	fail := func(reason string) (bool, error) {
+		return false, &CoercionError{Value: v, Target: "boolean", Reason: reason}
+	}
+
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case Boolean:
+		return bool(t), nil
+	case string:
+		return stringToBool(t, coerceConfig(opts), fail)
+	case String:
+		return stringToBool(string(t), coerceConfig(opts), fail)
+	case nil, Null:
+		return fail("value is null")
+	}
+
+	f, err := ToFloat(v)
+	if err != nil {
+		return fail(fmt.Sprintf("unsupported type %T", v))
+	}
+	switch f {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	}
+	return fail("only 0 and 1 are boolean numbers")
+}
+
+func stringToBool(s string, cfg *CoerceConfig, fail func(string) (bool, error)) (bool, error) {
+	if cfg.isNull(s) {
+		return fail("value is null")
+	}
+	s = strings.TrimSpace(s)
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b, nil
+	}
+	if cfg.Lenient && containsFold(lenientTrueValues, s) || containsFold(cfg.Locale.TrueValues, s) {
+		return true, nil
+	}
+	if cfg.Lenient && containsFold(lenientFalseValues, s) || containsFold(cfg.Locale.FalseValues, s) {
+		return false, nil
+	}
+	if s == "" {
+		return fail("value is empty")
+	}
+	return fail("unrecognized boolean value")
+}
+
+func containsFold(words []string, s string) bool {
+	for _, w := range words {
+		if strings.EqualFold(w, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToString coerces a value to a string. Numbers are written in their shortest
+// exact form, temporal values as ISO 8601 text. Objects & arrays never coerce
+// to strings
+func ToString(v interface{}, opts ...func(*CoerceConfig)) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32), nil
+	case Number:
+		return strconv.FormatFloat(float64(t), 'f', -1, 64), nil
+	case Integer:
+		return strconv.Itoa(int(t)), nil
+	case Boolean:
+		return strconv.FormatBool(bool(t)), nil
+	case String, Decimal, Date, Time, DateTime:
+		return t.(Value).String(), nil
+	case time.Time:
+		return t.Format(DateTimeLayout), nil
+	case nil, Null:
+		return "", &CoercionError{Value: v, Target: "string", Reason: "value is null"}
+	}
+	if i, err := ToInt(v); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+	return "", &CoercionError{Value: v, Target: "string", Reason: fmt.Sprintf("unsupported type %T", v)}
+}
+
+// ToTime coerces a value to a time. Strings are read as datetimes or dates in
+// the layouts ParseDateTime & ParseDate accept, with slash separated dates
+// read month first if the locale says so. Zone-less times are read in the
+// locale location
+func ToTime(v interface{}, opts ...func(*CoerceConfig)) (time.Time, error) {
+	fail := func(reason string) (time.Time, error) {
+		return time.Time{}, &CoercionError{Value: v, Target: "time", Reason: reason}
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case DateTime:
+		return t.Time(), nil
+	case Date:
+		return t.Time(), nil
+	case string:
+		return stringToTime(t, coerceConfig(opts), fail)
+	case String:
+		return stringToTime(string(t), coerceConfig(opts), fail)
+	case nil, Null:
+		return fail("value is null")
+	default:
+		return fail(fmt.Sprintf("unsupported type %T", v))
+	}
+}
+
+// monthFirstLayouts are tried before all others for locales that write
+// slash separated dates month first
+var monthFirstLayouts = []string{
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"1/2/2006",
+}
+
+func stringToTime(s string, cfg *CoerceConfig, fail func(string) (time.Time, error)) (time.Time, error) {
+	if cfg.isNull(s) {
+		return fail("value is null")
+	}
+	if strings.TrimSpace(s) == "" {
+		return fail("value is empty")
+	}
+	loc := cfg.Locale.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var layouts []string
+	if cfg.Locale.MonthFirst {
+		layouts = append(layouts, monthFirstLayouts...)
+	}
+	layouts = append(layouts, dateTimeLayouts...)
+	layouts = append(layouts, dateLayouts...)
+	if t, err := parseLayouts("time", s, layouts, loc); err == nil {
+		return t, nil
+	}
+	return fail("unrecognized date or time format")
+}
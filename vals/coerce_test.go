@@ -0,0 +1,230 @@
+package vals
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToInt(t *testing.T) {
+	dec, _ := ParseDecimal("12.00")
+	frac, _ := ParseDecimal("12.5")
+	cases := []struct {
+		in     interface{}
+		locale Locale
+		expect int64
+		err    string
+	}{
+		{int(1), Locale{}, 1, ""},
+		{uint8(2), Locale{}, 2, ""},
+		{float64(3), Locale{}, 3, ""},
+		{Integer(4), Locale{}, 4, ""},
+		{dec, Locale{}, 12, ""},
+		{" 42 ", Locale{}, 42, ""},
+		{"1,234", LocaleUS, 1234, ""},
+		{"1.234", LocaleDE, 1234, ""},
+		{String("5"), Locale{}, 5, ""},
+
+		{frac, Locale{}, 0, "cannot coerce 12.5 to integer: value has a fractional part"},
+		{1.5, Locale{}, 0, "cannot coerce 1.5 to integer: value has a fractional part"},
+		{uint64(1 << 63), Locale{}, 0, "cannot coerce 0x8000000000000000 to integer: value out of range"},
+		{"99999999999999999999", Locale{}, 0, `cannot coerce "99999999999999999999" to integer: value out of range`},
+		{"1,234", Locale{}, 0, `cannot coerce "1,234" to integer: invalid integer`},
+		{"-7.0", Locale{}, 0, `cannot coerce "-7.0" to integer: invalid integer`},
+		{"", Locale{}, 0, `cannot coerce "" to integer: value is empty`},
+		{true, Locale{}, 0, "cannot coerce true to integer: unsupported type bool"},
+		{nil, Locale{}, 0, "cannot coerce <nil> to integer: value is null"},
+	}
+	for i, c := range cases {
+		got, err := ToInt(c.in, WithLocale(c.locale))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d result mismatch. expected: %d, got: %d", i, c.expect, got)
+		}
+	}
+
+	if got, err := ToInt("-7.0", WithLenient()); err != nil || got != -7 {
+		t.Errorf("expected lenient coercion to read -7.0 as -7, got: %d, %v", got, err)
+	}
+	if _, err := ToInt("7.5", WithLenient()); err == nil || err.Error() != `cannot coerce "7.5" to integer: value has a fractional part` {
+		t.Errorf("expected lenient coercion to reject fractions, got: %v", err)
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	cases := []struct {
+		in     interface{}
+		locale Locale
+		expect float64
+		err    string
+	}{
+		{1.5, Locale{}, 1.5, ""},
+		{int64(2), Locale{}, 2, ""},
+		{Number(2.5), Locale{}, 2.5, ""},
+		{"3.25", Locale{}, 3.25, ""},
+		{"1e3", Locale{}, 1000, ""},
+		{"1.234,5", LocaleDE, 1234.5, ""},
+		{"1,234.5", LocaleUS, 1234.5, ""},
+
+		{"1,5", Locale{}, 0, `cannot coerce "1,5" to number: invalid number`},
+		{"1e999", Locale{}, 0, `cannot coerce "1e999" to number: value out of range`},
+		{false, Locale{}, 0, "cannot coerce false to number: unsupported type bool"},
+		{Null(true), Locale{}, 0, "cannot coerce <null> to number: value is null"},
+	}
+	for i, c := range cases {
+		got, err := ToFloat(c.in, WithLocale(c.locale))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d result mismatch. expected: %f, got: %f", i, c.expect, got)
+		}
+	}
+}
+
+func TestToBool(t *testing.T) {
+	cases := []struct {
+		in     interface{}
+		locale Locale
+		expect bool
+		err    string
+	}{
+		{true, Locale{}, true, ""},
+		{Boolean(false), Locale{}, false, ""},
+		{"TRUE", Locale{}, true, ""},
+		{"f", Locale{}, false, ""},
+		{"Ja", LocaleDE, true, ""},
+		{"nein", LocaleDE, false, ""},
+		{1, Locale{}, true, ""},
+		{0.0, Locale{}, false, ""},
+
+		{"ja", Locale{}, false, `cannot coerce "ja" to boolean: unrecognized boolean value`},
+		{"no", Locale{}, false, `cannot coerce "no" to boolean: unrecognized boolean value`},
+		{2, Locale{}, false, "cannot coerce 2 to boolean: only 0 and 1 are boolean numbers"},
+		{0.5, Locale{}, false, "cannot coerce 0.5 to boolean: only 0 and 1 are boolean numbers"},
+		{"", Locale{}, false, `cannot coerce "" to boolean: value is empty`},
+		{[]interface{}{}, Locale{}, false, "cannot coerce []interface {}{} to boolean: unsupported type []interface {}"},
+	}
+	for i, c := range cases {
+		got, err := ToBool(c.in, WithLocale(c.locale))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d result mismatch. expected: %t, got: %t", i, c.expect, got)
+		}
+	}
+
+	for _, s := range []string{"yes", "Y", "no", "N"} {
+		got, err := ToBool(s, WithLenient())
+		if err != nil {
+			t.Errorf("expected lenient coercion to read %q, got: %s", s, err)
+		} else if expect := s == "yes" || s == "Y"; got != expect {
+			t.Errorf("lenient %q mismatch. expected: %t, got: %t", s, expect, got)
+		}
+	}
+}
+
+func TestToString(t *testing.T) {
+	dec, _ := ParseDecimal("0.10")
+	cases := []struct {
+		in     interface{}
+		expect string
+		err    string
+	}{
+		{"a", "a", ""},
+		{true, "true", ""},
+		{1.5, "1.5", ""},
+		{1e21, "1000000000000000000000", ""},
+		{int32(12), "12", ""},
+		{Integer(3), "3", ""},
+		{dec, "0.10", ""},
+		{NewDate(2019, 3, 31), "2019-03-31", ""},
+		{time.Date(2019, 3, 31, 12, 0, 0, 0, time.UTC), "2019-03-31T12:00:00Z", ""},
+
+		{nil, "", "cannot coerce <nil> to string: value is null"},
+		{map[string]interface{}{}, "", "cannot coerce map[string]interface {}{} to string: unsupported type map[string]interface {}"},
+	}
+	for i, c := range cases {
+		got, err := ToString(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestToTime(t *testing.T) {
+	berlin := time.FixedZone("CET", 60*60)
+	cases := []struct {
+		in     interface{}
+		locale Locale
+		expect time.Time
+		err    string
+	}{
+		{"2019-03-31T13:45:00Z", Locale{}, time.Date(2019, 3, 31, 13, 45, 0, 0, time.UTC), ""},
+		{"31.03.2019", Locale{}, time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC), ""},
+		{"03/01/2019", Locale{}, time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC), ""},
+		{"03/01/2019", LocaleUS, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), ""},
+		{"31.03.2019 13:45", Locale{Location: berlin}, time.Date(2019, 3, 31, 13, 45, 0, 0, berlin), ""},
+		{NewDate(2019, 3, 31), Locale{}, time.Date(2019, 3, 31, 0, 0, 0, 0, time.UTC), ""},
+
+		{"next week", Locale{}, time.Time{}, `cannot coerce "next week" to time: unrecognized date or time format`},
+		{12, Locale{}, time.Time{}, "cannot coerce 12 to time: unsupported type int"},
+	}
+	for i, c := range cases {
+		got, err := ToTime(c.in, WithLocale(c.locale))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if !got.Equal(c.expect) {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestNullValues(t *testing.T) {
+	nulls := WithNullValues("", "NA", "-")
+	cases := []struct {
+		in     interface{}
+		expect bool
+	}{
+		{nil, true},
+		{Null(true), true},
+		{"", true},
+		{" NA ", true},
+		{String("-"), true},
+		{"na", false},
+		{"NULL", false},
+		{0, false},
+	}
+	for i, c := range cases {
+		if got := IsNull(c.in, nulls); got != c.expect {
+			t.Errorf("case %d: expected %t, got %t", i, c.expect, got)
+		}
+	}
+	if IsNull("") {
+		t.Errorf("expected strings not to be null without null values")
+	}
+
+	if _, err := ToInt("NA", nulls); err == nil || err.Error() != `cannot coerce "NA" to integer: value is null` {
+		t.Errorf("integer error mismatch, got: %v", err)
+	}
+	if _, err := ToFloat("-", nulls); err == nil || err.Error() != `cannot coerce "-" to number: value is null` {
+		t.Errorf("number error mismatch, got: %v", err)
+	}
+	if _, err := ToBool("NA", nulls); err == nil || err.Error() != `cannot coerce "NA" to boolean: value is null` {
+		t.Errorf("boolean error mismatch, got: %v", err)
+	}
+	if _, err := ToTime("", nulls); err == nil || err.Error() != `cannot coerce "" to time: value is null` {
+		t.Errorf("time error mismatch, got: %v", err)
+	}
+}
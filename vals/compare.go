@@ -3,7 +3,11 @@ package vals
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 // Equal checks if two Values are the same
@@ -15,6 +19,11 @@ func Equal(a, b Value) bool {
 	case TypeObject, TypeArray:
 		return reflect.DeepEqual(a, b)
 	case TypeNumber:
+		if ad, ok := a.(Decimal); ok {
+			if bd, ok := b.(Decimal); ok {
+				return ad.Cmp(bd) == 0
+			}
+		}
 		return a.Number() == b.Number()
 	case TypeInteger:
 		return a.Integer() == b.Integer()
@@ -23,6 +32,9 @@ func Equal(a, b Value) bool {
 	case TypeNull:
 		return a.IsNull() == b.IsNull()
 	case TypeString:
+		if cmp, err := CompareTemporal(a, b); err == nil {
+			return cmp == 0
+		}
 		return a.String() == b.String()
 	}
 	return false
@@ -88,3 +100,174 @@ func CompareNumberBytes(a, b []byte) (int, error) {
 	}
 	return -1, nil
 }
+
+// typeRank orders types for Compare: null < boolean < number < string <
+// array < object, with integers & numbers sharing a rank. types without a
+// defined place sort last
+func typeRank(t Type) int {
+	switch t {
+	case TypeNull:
+		return 0
+	case TypeBoolean:
+		return 1
+	case TypeInteger, TypeNumber:
+		return 2
+	case TypeString:
+		return 3
+	case TypeArray:
+		return 4
+	case TypeObject:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// Compare defines a total order over values, returning -1 if a sorts before
+// b, 0 if they're equivalent & +1 if a sorts after b. Values of different
+// types order null < boolean < number < string < array < object. Integers,
+// numbers & decimals compare by numeric value, with NaN before all other
+// numbers. Temporal values of the same kind compare chronologically, all
+// other strings compare bytewise. Arrays compare element by element, objects
+// compare by their sorted keys, then values. A nil Value is treated as null
+func Compare(a, b Value) int {
+	a, b = derefValue(a), derefValue(b)
+	ar, br := typeRank(a.Type()), typeRank(b.Type())
+	if ar != br {
+		return compareInts(ar, br)
+	}
+
+	switch ar {
+	case 0:
+		return 0
+	case 1:
+		ab, bb := a.Boolean(), b.Boolean()
+		if ab == bb {
+			return 0
+		} else if !ab {
+			return -1
+		}
+		return 1
+	case 2:
+		return compareNumeric(a, b)
+	case 3:
+		if cmp, err := CompareTemporal(a, b); err == nil {
+			return cmp
+		}
+		return strings.Compare(a.String(), b.String())
+	case 4:
+		al, bl := a.Len(), b.Len()
+		for i := 0; i < al && i < bl; i++ {
+			if cmp := Compare(a.Index(i), b.Index(i)); cmp != 0 {
+				return cmp
+			}
+		}
+		return compareInts(al, bl)
+	case 5:
+		ak, bk := a.Keys(), b.Keys()
+		sort.Strings(ak)
+		sort.Strings(bk)
+		for i := 0; i < len(ak) && i < len(bk); i++ {
+			if cmp := strings.Compare(ak[i], bk[i]); cmp != 0 {
+				return cmp
+			}
+			if cmp := Compare(a.MapIndex(ak[i]), b.MapIndex(bk[i])); cmp != 0 {
+				return cmp
+			}
+		}
+		return compareInts(len(ak), len(bk))
+	}
+	return 0
+}
+
+// Less reports whether a sorts before b according to Compare
+func Less(a, b Value) bool {
+	return Compare(a, b) < 0
+}
+
+// Sort orders the elements of an array in place according to Compare.
+// Equivalent elements keep their original order
+func Sort(a Array) {
+	sort.SliceStable(a, func(i, j int) bool { return Less(a[i], a[j]) })
+}
+
+// derefValue unwraps pointer & object-member values, treating nil as null
+func derefValue(v Value) Value {
+	switch t := v.(type) {
+	case nil:
+		return Null(true)
+	case *Array:
+		if t == nil {
+			return Null(true)
+		}
+		return *t
+	case *Object:
+		if t == nil {
+			return Null(true)
+		}
+		return *t
+	case ObjectValue:
+		return derefValue(t.Value)
+	}
+	return v
+}
+
+// compareNumeric compares integer, number & decimal values exactly
+func compareNumeric(a, b Value) int {
+	ar, aok := numericRat(a)
+	br, bok := numericRat(b)
+	if aok && bok {
+		return ar.Cmp(br)
+	}
+
+	// at least one value is NaN or infinite
+	af, bf := numericFloat(a), numericFloat(b)
+	an, bn := math.IsNaN(af), math.IsNaN(bf)
+	switch {
+	case an && bn:
+		return 0
+	case an:
+		return -1
+	case bn:
+		return 1
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+// numericRat gives the exact value of a finite numeric value
+func numericRat(v Value) (*big.Rat, bool) {
+	switch t := v.(type) {
+	case Decimal:
+		return t.Rat(), true
+	case Integer:
+		return new(big.Rat).SetInt64(int64(t)), true
+	}
+	if v.Type() == TypeInteger {
+		return new(big.Rat).SetInt64(int64(v.Integer())), true
+	}
+	f := v.Number()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return new(big.Rat).SetFloat64(f), true
+}
+
+func numericFloat(v Value) float64 {
+	if v.Type() == TypeInteger {
+		return float64(v.Integer())
+	}
+	return v.Number()
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
@@ -1,6 +1,8 @@
 package vals
 
 import (
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -112,3 +114,77 @@ func TestCompareNumberBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestCompare(t *testing.T) {
+	dec, _ := ParseDecimal("1.50")
+	big, _ := ParseDecimal("9223372036854775808")
+	nan := Number(math.NaN())
+
+	cases := []struct {
+		a, b   Value
+		expect int
+	}{
+		// cross-type ordering
+		{nil, Null(true), 0},
+		{Null(true), Boolean(false), -1},
+		{Boolean(true), Integer(0), -1},
+		{Number(100), String(""), -1},
+		{String("z"), Array{}, -1},
+		{Array{Integer(1)}, Object{}, -1},
+		{Object{}, Null(true), 1},
+
+		{Boolean(false), Boolean(true), -1},
+		{Boolean(true), Boolean(true), 0},
+
+		// numbers
+		{Integer(1), Number(1), 0},
+		{Integer(1), Number(1.5), -1},
+		{dec, Number(1.5), 0},
+		{dec, Integer(2), -1},
+		{Integer(math.MaxInt64), big, -1},
+		{nan, Number(math.Inf(-1)), -1},
+		{nan, nan, 0},
+		{Number(math.Inf(1)), Integer(math.MaxInt64), 1},
+
+		// strings & temporal values
+		{String("a"), String("b"), -1},
+		{String("b"), String("a"), 1},
+		{NewDate(2019, 3, 31), NewDate(2019, 4, 1), -1},
+		{mustDateTime("2019-03-31T12:00:00+02:00"), mustDateTime("2019-03-31T11:00:00Z"), -1},
+		{NewDate(2019, 3, 31), String("2019-03-31"), 0},
+
+		// arrays & objects
+		{Array{Integer(1)}, Array{Integer(1), Integer(0)}, -1},
+		{Array{Integer(2)}, &Array{Integer(1), Integer(0)}, 1},
+		{Object{"a": Integer(1)}, &Object{"a": Integer(1)}, 0},
+		{Object{"a": Integer(1)}, Object{"b": Integer(0)}, -1},
+		{Object{"a": Integer(2)}, Object{"a": Integer(1), "b": Integer(0)}, 1},
+		{NewObjectValue("x", Integer(1)), Integer(1), 0},
+	}
+
+	for i, c := range cases {
+		if got := Compare(c.a, c.b); got != c.expect {
+			t.Errorf("case %d: Compare(%v, %v) expected %d, got %d", i, c.a, c.b, c.expect, got)
+		}
+		if got := Compare(c.b, c.a); got != -c.expect {
+			t.Errorf("case %d: reversed Compare(%v, %v) expected %d, got %d", i, c.b, c.a, -c.expect, got)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	a := Array{Object{}, String("b"), Integer(2), Null(true), Number(1.5), Array{}, Boolean(true), String("a")}
+	Sort(a)
+	expect := Array{Null(true), Boolean(true), Number(1.5), Integer(2), String("a"), String("b"), Array{}, Object{}}
+	if !reflect.DeepEqual(expect, a) {
+		t.Errorf("sort mismatch. expected: %v, got: %v", expect, a)
+	}
+}
+
+func mustDateTime(s string) DateTime {
+	dt, err := ParseDateTime(s)
+	if err != nil {
+		panic(err)
+	}
+	return dt
+}
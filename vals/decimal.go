@@ -0,0 +1,130 @@
+package vals
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an arbitrary-precision number. Where Number stores a float64,
+// which silently rounds values like monetary amounts & high-precision
+// measurements, Decimal stores numbers exactly, retaining the number of
+// digits after the decimal point they were written with. Decimal values are
+// of type TypeNumber
+type Decimal struct {
+	rat   *big.Rat
+	scale int
+}
+
+// ParseDecimal parses decimal text, optionally with an exponent, eg.
+// "-12.50" or "1.5e-3", into a Decimal
+func ParseDecimal(s string) (Decimal, error) {
+	if s == "" || strings.ContainsAny(s, "/ ") {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", s)
+	}
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", s)
+	}
+	return Decimal{rat: rat, scale: decimalScale(s)}, nil
+}
+
+// decimalScale counts the digits after the decimal point s expresses,
+// accounting for any exponent
+func decimalScale(s string) int {
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		exp, _ = strconv.Atoi(s[i+1:])
+	}
+	scale := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = len(mantissa) - i - 1
+	}
+	if scale -= exp; scale < 0 {
+		scale = 0
+	}
+	return scale
+}
+
+// Rat gives a copy of the exact value of a decimal as a rational number
+func (d Decimal) Rat() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(d.rat)
+}
+
+// Cmp compares two decimals, returning -1 if d < b, 0 if they're equal & +1
+// if d > b. Decimals that differ only in trailing zeros are equal
+func (d Decimal) Cmp(b Decimal) int {
+	return d.Rat().Cmp(b.Rat())
+}
+
+// Type declares Decimal is of Number type
+func (d Decimal) Type() Type { return TypeNumber }
+
+// Len of Decimal will always panic
+func (d Decimal) Len() int {
+	panic(&ValueError{"Len", TypeNumber})
+}
+
+// Index of Decimal will always panic
+func (d Decimal) Index(i int) Value {
+	panic(&ValueError{"Index", TypeNumber})
+}
+
+// Keys of Decimal will always panic
+func (d Decimal) Keys() []string {
+	panic(&ValueError{"Keys", TypeNumber})
+}
+
+// MapIndex of Decimal will always panic
+func (d Decimal) MapIndex(key string) Value {
+	panic(&ValueError{"MapIndex", TypeNumber})
+}
+
+// Boolean of Decimal will always panic
+func (d Decimal) Boolean() bool {
+	panic(&ValueError{"Boolean", TypeNumber})
+}
+
+// String gives the exact decimal text of d, without an exponent
+func (d Decimal) String() string {
+	return d.Rat().FloatString(d.scale)
+}
+
+// Integer of Decimal will always panic
+func (d Decimal) Integer() int {
+	panic(&ValueError{"Integer", TypeNumber})
+}
+
+// Number gives the nearest float64 to d
+func (d Decimal) Number() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// IsNull of Decimal always returns false
+func (d Decimal) IsNull() bool { return false }
+
+// MarshalJSON encodes a decimal as a JSON number without losing precision
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string containing one, into a
+// decimal
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	}
+	dec, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = dec
+	return nil
+}
@@ -0,0 +1,83 @@
+package vals
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect string
+		err    string
+	}{
+		{"0", "0", ""},
+		{"12.50", "12.50", ""},
+		{"-0.000000000000000000001", "-0.000000000000000000001", ""},
+		{"92233720368547758070", "92233720368547758070", ""},
+		{"1.5e-3", "0.0015", ""},
+		{"1.25E2", "125", ""},
+		{"", "", "invalid decimal ''"},
+		{"1/3", "", "invalid decimal '1/3'"},
+		{"abc", "", "invalid decimal 'abc'"},
+	}
+	for i, c := range cases {
+		got, err := ParseDecimal(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got.String() != c.expect {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got.String())
+		}
+	}
+}
+
+func TestDecimalValue(t *testing.T) {
+	a, _ := ParseDecimal("0.1")
+	b, _ := ParseDecimal("0.10")
+	c, _ := ParseDecimal("0.1000000000000000000001")
+
+	if a.Type() != TypeNumber {
+		t.Errorf("expected decimal to be a number, got: %s", a.Type())
+	}
+	if a.Number() != 0.1 {
+		t.Errorf("expected float approximation 0.1, got: %f", a.Number())
+	}
+	if !Equal(a, b) {
+		t.Errorf("expected decimals differing in trailing zeros to be equal")
+	}
+	if Equal(a, c) {
+		t.Errorf("expected decimals differing beyond float64 precision to be unequal")
+	}
+	if !Equal(a, Number(0.1)) {
+		t.Errorf("expected decimal to equal matching Number")
+	}
+
+	v, err := ConvertDecoded(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "0.1000000000000000000001" {
+		t.Errorf("expected ConvertDecoded to keep decimal, got: %s", v.String())
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	d, _ := ParseDecimal("19.990000000000000001")
+	data, err := json.Marshal([]interface{}{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[19.990000000000000001]` {
+		t.Errorf("marshal mismatch. got: %s", data)
+	}
+
+	got := []Decimal{}
+	if err := json.Unmarshal([]byte(`[19.990000000000000001, "3.50"]`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Cmp(d) != 0 || got[1].String() != "3.50" {
+		t.Errorf("unmarshal mismatch. got: %s, %s", got[0], got[1])
+	}
+}
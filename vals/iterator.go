@@ -0,0 +1,106 @@
+package vals
+
+import "sort"
+
+// Iterator steps through a sequence of values one at a time, so algorithms
+// can run over sequences without holding the whole sequence in memory.
+// Iterators start before the first value: call Next to advance, then check
+// Err once Next returns false
+type Iterator interface {
+	// Next advances to the next value, returning false once the sequence is
+	// exhausted or an error occurs
+	Next() bool
+	// Value gives the current value
+	Value() Value
+	// Key gives the object key of the current value, empty if the sequence
+	// isn't drawn from an object
+	Key() string
+	// Err gives the error that stopped iteration, if any
+	Err() error
+	// Close releases any resources held by the iterator
+	Close() error
+}
+
+// sliceIterator iterates a slice of values held in memory
+type sliceIterator struct {
+	keys   []string
+	values []Value
+	i      int
+}
+
+// NewArrayIterator creates an iterator over the elements of an array
+func NewArrayIterator(a Array) Iterator {
+	return &sliceIterator{values: a, i: -1}
+}
+
+// NewObjectIterator creates an iterator over the values of an object in
+// sorted key order
+func NewObjectIterator(o Object) Iterator {
+	keys := o.Keys()
+	sort.Strings(keys)
+	values := make([]Value, len(keys))
+	for i, key := range keys {
+		values[i] = o[key]
+	}
+	return &sliceIterator{keys: keys, values: values, i: -1}
+}
+
+// Next implements the Iterator interface
+func (it *sliceIterator) Next() bool {
+	if it.i < len(it.values) {
+		it.i++
+	}
+	return it.i < len(it.values)
+}
+
+// Value implements the Iterator interface
+func (it *sliceIterator) Value() Value {
+	if it.i < 0 || it.i >= len(it.values) {
+		return nil
+	}
+	return it.values[it.i]
+}
+
+// Key implements the Iterator interface
+func (it *sliceIterator) Key() string {
+	if it.keys == nil || it.i < 0 || it.i >= len(it.keys) {
+		return ""
+	}
+	return it.keys[it.i]
+}
+
+// Err implements the Iterator interface
+func (it *sliceIterator) Err() error { return nil }
+
+// Close implements the Iterator interface
+func (it *sliceIterator) Close() error { return nil }
+
+// Collect reads all remaining values from an iterator into an array, or an
+// object if the iterator yields keys, closing the iterator
+func Collect(it Iterator) (Value, error) {
+	defer it.Close()
+	var (
+		arr Array
+		obj Object
+	)
+	for it.Next() {
+		if key := it.Key(); key != "" {
+			if obj == nil {
+				obj = Object{}
+			}
+			obj[key] = it.Value()
+			continue
+		}
+		arr = append(arr, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	if obj != nil {
+		return obj, nil
+	}
+	if arr == nil {
+		arr = Array{}
+	}
+	return arr, nil
+}
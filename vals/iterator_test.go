@@ -0,0 +1,63 @@
+package vals
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArrayIterator(t *testing.T) {
+	a := Array{Integer(1), String("a"), Null(true)}
+	it := NewArrayIterator(a)
+	if it.Value() != nil {
+		t.Errorf("expected nil value before Next")
+	}
+	got := Array{}
+	for it.Next() {
+		if it.Key() != "" {
+			t.Errorf("expected empty key, got: %s", it.Key())
+		}
+		got = append(got, it.Value())
+	}
+	if it.Next() {
+		t.Errorf("expected exhausted iterator to stay exhausted")
+	}
+	if !reflect.DeepEqual(a, got) {
+		t.Errorf("result mismatch. expected: %v, got: %v", a, got)
+	}
+}
+
+func TestObjectIterator(t *testing.T) {
+	o := Object{"b": Integer(2), "a": Integer(1), "c": Integer(3)}
+	it := NewObjectIterator(o)
+	keys := []string{}
+	for it.Next() {
+		keys = append(keys, it.Key())
+		if !Equal(o[it.Key()], it.Value()) {
+			t.Errorf("value mismatch for key %s", it.Key())
+		}
+	}
+	if !reflect.DeepEqual([]string{"a", "b", "c"}, keys) {
+		t.Errorf("expected sorted keys, got: %v", keys)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	cases := []struct {
+		it     Iterator
+		expect Value
+	}{
+		{NewArrayIterator(nil), Array{}},
+		{NewArrayIterator(Array{Integer(1)}), Array{Integer(1)}},
+		{NewObjectIterator(Object{"a": Integer(1)}), Object{"a": Integer(1)}},
+	}
+	for i, c := range cases {
+		got, err := Collect(c.it)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package vals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxLinkDepth limits how many links in a row resolution follows before
+// assuming a cycle
+const maxLinkDepth = 32
+
+// Link is a value that references another dataset, component or value.
+// Links follow JSON Reference conventions: a link encodes as an object with a
+// single "$ref" key, and a ref may end in a "#" fragment that is a JSON
+// Pointer into the referenced document, eg. "/ipfs/QmHash#/meta/title"
+type Link struct {
+	Ref string
+}
+
+// NewLink creates a link to ref
+func NewLink(ref string) Link {
+	return Link{Ref: ref}
+}
+
+// Path gives the portion of a link ref before any fragment
+func (l Link) Path() string {
+	if i := strings.IndexByte(l.Ref, '#'); i >= 0 {
+		return l.Ref[:i]
+	}
+	return l.Ref
+}
+
+// Fragment gives the JSON Pointer portion of a link ref after the "#", if
+// any
+func (l Link) Fragment() string {
+	if i := strings.IndexByte(l.Ref, '#'); i >= 0 {
+		return l.Ref[i+1:]
+	}
+	return ""
+}
+
+// Type declares Link is of Object type
+func (l Link) Type() Type { return TypeObject }
+
+// Len of Link will always panic
+func (l Link) Len() int { panic(&ValueError{"Len", TypeObject}) }
+
+// Index of Link will always panic
+func (l Link) Index(i int) Value { panic(&ValueError{"Index", TypeObject}) }
+
+// Keys of a Link is always the "$ref" key
+func (l Link) Keys() []string { return []string{"$ref"} }
+
+// MapIndex gives the ref for the "$ref" key, nil otherwise
+func (l Link) MapIndex(key string) Value {
+	if key == "$ref" {
+		return String(l.Ref)
+	}
+	return nil
+}
+
+// Boolean of Link will always panic
+func (l Link) Boolean() bool { panic(&ValueError{"Boolean", TypeObject}) }
+
+// String gives the link ref
+func (l Link) String() string { return l.Ref }
+
+// Integer of Link will always panic
+func (l Link) Integer() int { panic(&ValueError{"Integer", TypeObject}) }
+
+// Number of Link will always panic
+func (l Link) Number() float64 { panic(&ValueError{"Number", TypeObject}) }
+
+// IsNull of Link always returns false
+func (l Link) IsNull() bool { return false }
+
+// MarshalJSON encodes a link as a JSON Reference object
+func (l Link) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"$ref": l.Ref})
+}
+
+// UnmarshalJSON decodes a JSON Reference object
+func (l *Link) UnmarshalJSON(data []byte) error {
+	ref := map[string]string{}
+	if err := json.Unmarshal(data, &ref); err != nil || len(ref) != 1 || ref["$ref"] == "" {
+		return fmt.Errorf("link must be an object with a single '$ref' string, got %s", data)
+	}
+	l.Ref = ref["$ref"]
+	return nil
+}
+
+// AsLink checks if a value is a link, either as a Link or as a decoded JSON
+// Reference object with a single "$ref" string key
+func AsLink(v interface{}) (Link, bool) {
+	var ref interface{}
+	switch t := v.(type) {
+	case Link:
+		return t, true
+	case *Link:
+		if t != nil {
+			return *t, true
+		}
+		return Link{}, false
+	case map[string]interface{}:
+		if len(t) != 1 {
+			return Link{}, false
+		}
+		ref = t["$ref"]
+	case map[interface{}]interface{}:
+		if len(t) != 1 {
+			return Link{}, false
+		}
+		ref = t["$ref"]
+	case Object:
+		if len(t) != 1 {
+			return Link{}, false
+		}
+		ref = t["$ref"]
+	case *Object:
+		if t == nil {
+			return Link{}, false
+		}
+		return AsLink(*t)
+	default:
+		return Link{}, false
+	}
+
+	switch r := ref.(type) {
+	case string:
+		return Link{Ref: r}, r != ""
+	case String:
+		return Link{Ref: string(r)}, r != ""
+	}
+	return Link{}, false
+}
+
+// Resolver dereferences links to the values they reference. dataset stores
+// implement Resolver to make links inside bodies & documents traversable
+type Resolver interface {
+	// ResolveLink fetches the value a link references
+	ResolveLink(ctx context.Context, l Link) (interface{}, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface
+type ResolverFunc func(ctx context.Context, l Link) (interface{}, error)
+
+// ResolveLink calls f
+func (f ResolverFunc) ResolveLink(ctx context.Context, l Link) (interface{}, error) {
+	return f(ctx, l)
+}
+
+// Resolve dereferences v if it's a link, following links that resolve to
+// other links. values that aren't links are returned as-is
+func Resolve(ctx context.Context, v interface{}, r Resolver) (interface{}, error) {
+	for i := 0; i < maxLinkDepth; i++ {
+		l, ok := AsLink(v)
+		if !ok {
+			return v, nil
+		}
+		if r == nil {
+			return nil, fmt.Errorf("resolving link '%s': no resolver", l.Ref)
+		}
+		var err error
+		if v, err = r.ResolveLink(ctx, l); err != nil {
+			return nil, fmt.Errorf("resolving link '%s': %s", l.Ref, err)
+		}
+	}
+	return nil, fmt.Errorf("resolving link: exceeded %d links in a row, links may form a cycle", maxLinkDepth)
+}
+
+// GetLinked resolves a JSON Pointer like Get, dereferencing links with r as
+// they're encountered, so a pointer can traverse into linked documents. If
+// the pointer refers to a link, the link target is returned
+func GetLinked(ctx context.Context, value interface{}, pointer string, r Resolver) (interface{}, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	for i, tok := range tokens {
+		path := "/" + strings.Join(escapeTokens(tokens[:i+1]), "/")
+		if value, err = Resolve(ctx, value, r); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		if value, err = getToken(value, tok); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	return Resolve(ctx, value, r)
+}
@@ -0,0 +1,120 @@
+package vals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLinkJSON(t *testing.T) {
+	l := NewLink("/ipfs/QmHash#/meta/title")
+	data, err := json.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"$ref":"/ipfs/QmHash#/meta/title"}` {
+		t.Errorf("marshal mismatch. got: %s", data)
+	}
+
+	got := Link{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != l {
+		t.Errorf("round trip mismatch. expected: %v, got: %v", l, got)
+	}
+	if got.Path() != "/ipfs/QmHash" {
+		t.Errorf("path mismatch. got: %s", got.Path())
+	}
+	if got.Fragment() != "/meta/title" {
+		t.Errorf("fragment mismatch. got: %s", got.Fragment())
+	}
+
+	for _, bad := range []string{`"/ipfs/QmHash"`, `{}`, `{"$ref":"a","b":"c"}`} {
+		if err := json.Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("expected error unmarshaling %s", bad)
+		}
+	}
+}
+
+func TestAsLink(t *testing.T) {
+	cases := []struct {
+		in     interface{}
+		expect Link
+		ok     bool
+	}{
+		{NewLink("a"), NewLink("a"), true},
+		{&Link{Ref: "a"}, NewLink("a"), true},
+		{map[string]interface{}{"$ref": "a"}, NewLink("a"), true},
+		{map[interface{}]interface{}{"$ref": "a"}, NewLink("a"), true},
+		{Object{"$ref": String("a")}, NewLink("a"), true},
+		{&Object{"$ref": String("a")}, NewLink("a"), true},
+
+		{"a", Link{}, false},
+		{map[string]interface{}{"$ref": "a", "b": "c"}, Link{}, false},
+		{map[string]interface{}{"$ref": 1}, Link{}, false},
+		{map[string]interface{}{"$ref": ""}, Link{}, false},
+		{(*Link)(nil), Link{}, false},
+	}
+
+	for i, c := range cases {
+		got, ok := AsLink(c.in)
+		if ok != c.ok || got != c.expect {
+			t.Errorf("case %d mismatch. expected: %v %t, got: %v %t", i, c.expect, c.ok, got, ok)
+		}
+	}
+}
+
+func TestGetLinked(t *testing.T) {
+	ctx := context.Background()
+	docs := map[string]interface{}{
+		"/a": map[string]interface{}{
+			"title": "a",
+			"next":  map[string]interface{}{"$ref": "/b"},
+		},
+		"/b":     []interface{}{"x", NewLink("/alias")},
+		"/alias": NewLink("/c"),
+		"/c":     "c",
+		"/loop":  NewLink("/loop"),
+	}
+	r := ResolverFunc(func(ctx context.Context, l Link) (interface{}, error) {
+		if d, ok := docs[l.Ref]; ok {
+			return d, nil
+		}
+		return nil, fmt.Errorf("not found")
+	})
+
+	body := []interface{}{NewLink("/a"), NewLink("/missing"), NewLink("/loop")}
+
+	cases := []struct {
+		pointer string
+		expect  interface{}
+		err     string
+	}{
+		{"/0/title", "a", ""},
+		{"/0/next/0", "x", ""},
+		{"/0/next/1", "c", ""},
+		{"/0", docs["/a"], ""},
+
+		{"/1/title", nil, "/1/title: resolving link '/missing': not found"},
+		{"/2/title", nil, "/2/title: resolving link: exceeded 32 links in a row, links may form a cycle"},
+		{"/0/nope", nil, "/0/nope: key not found"},
+	}
+
+	for _, c := range cases {
+		got, err := GetLinked(ctx, body, c.pointer, r)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("'%s' error mismatch. expected: '%s', got: '%v'", c.pointer, c.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("'%s' result mismatch. expected: %#v, got: %#v", c.pointer, c.expect, got)
+		}
+	}
+
+	if _, err := GetLinked(ctx, body, "/0/title", nil); err == nil || err.Error() != "/0/title: resolving link '/a': no resolver" {
+		t.Errorf("expected no resolver error, got: %v", err)
+	}
+}
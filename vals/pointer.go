@@ -0,0 +1,148 @@
+package vals
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Get resolves an RFC 6901 JSON Pointer against value, returning the
+// referenced element. value may be a Value, data decoded into standard go
+// types (eg. by json.Unmarshal or a yaml decoder), or any value that encodes
+// to JSON, like a dataset document. The empty pointer refers to the whole
+// value
+func Get(value interface{}, pointer string) (interface{}, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return getTokens(value, tokens, "/")
+}
+
+// GetPath resolves a dotted path like "structure.schema.items.0" against
+// value. GetPath is a simpler variant of Get for use where JSON Pointer
+// syntax is inconvenient, at the cost of not supporting keys that contain a
+// "." character. The empty path refers to the whole value
+func GetPath(value interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return value, nil
+	}
+	return getTokens(value, strings.Split(path, "."), ".")
+}
+
+// ParsePointer splits an RFC 6901 JSON Pointer into unescaped reference
+// tokens
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer '%s': must be empty or begin with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		// order matters: "~01" must decode to "~1", not "/"
+		tokens[i] = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// escapeTokens reverses the unescaping ParsePointer performs
+func escapeTokens(tokens []string) []string {
+	escaped := make([]string, len(tokens))
+	for i, tok := range tokens {
+		escaped[i] = strings.Replace(strings.Replace(tok, "~", "~0", -1), "/", "~1", -1)
+	}
+	return escaped
+}
+
+// getTokens resolves tokens in order. errors are prefixed with the path to
+// the failing token, joined with sep
+func getTokens(value interface{}, tokens []string, sep string) (interface{}, error) {
+	var err error
+	for i, tok := range tokens {
+		if value, err = getToken(value, tok); err != nil {
+			path := strings.Join(tokens[:i+1], sep)
+			if sep == "/" {
+				path = "/" + strings.Join(escapeTokens(tokens[:i+1]), "/")
+			}
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	return value, nil
+}
+
+// getToken resolves a single reference token against value
+func getToken(value interface{}, tok string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if el, ok := v[tok]; ok {
+			return el, nil
+		}
+		return nil, fmt.Errorf("key not found")
+	case map[interface{}]interface{}:
+		if el, ok := v[tok]; ok {
+			return el, nil
+		}
+		return nil, fmt.Errorf("key not found")
+	case []interface{}:
+		i, err := arrayIndex(tok, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return v[i], nil
+	case *Object:
+		return getToken(*v, tok)
+	case Object:
+		if el, ok := v[tok]; ok {
+			return el, nil
+		}
+		return nil, fmt.Errorf("key not found")
+	case *Array:
+		return getToken(*v, tok)
+	case Array:
+		i, err := arrayIndex(tok, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return v[i], nil
+	case nil, Value, string, bool, float64, int, int64:
+		return nil, fmt.Errorf("cannot index into %T", value)
+	}
+
+	// fall back to the JSON encoding of value, which is how documents like
+	// datasets are addressed
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %T: %s", value, err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	switch decoded.(type) {
+	case map[string]interface{}, []interface{}:
+		return getToken(decoded, tok)
+	}
+	return nil, fmt.Errorf("cannot index into %T", value)
+}
+
+// arrayIndex parses an array index token, which must be a base-10 integer
+// without leading zeros
+func arrayIndex(tok string, length int) (int, error) {
+	if tok == "-" {
+		return 0, fmt.Errorf("index '-' refers to a nonexistent element")
+	}
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') || strings.TrimLeft(tok, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index '%s'", tok)
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index '%s'", tok)
+	}
+	if i >= length {
+		return 0, fmt.Errorf("index %d out of range", i)
+	}
+	return i, nil
+}
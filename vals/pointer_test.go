@@ -0,0 +1,123 @@
+package vals
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	// example document from RFC 6901 section 5
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		pointer string
+		expect  interface{}
+		err     string
+	}{
+		{"", doc, ""},
+		{"/foo", []interface{}{"bar", "baz"}, ""},
+		{"/foo/0", "bar", ""},
+		{"/", float64(0), ""},
+		{"/a~1b", float64(1), ""},
+		{"/c%d", float64(2), ""},
+		{"/e^f", float64(3), ""},
+		{"/g|h", float64(4), ""},
+		{"/i\\j", float64(5), ""},
+		{"/k\"l", float64(6), ""},
+		{"/ ", float64(7), ""},
+		{"/m~0n", float64(8), ""},
+
+		{"foo", nil, "invalid json pointer 'foo': must be empty or begin with '/'"},
+		{"/bar", nil, "/bar: key not found"},
+		{"/foo/2", nil, "/foo/2: index 2 out of range"},
+		{"/foo/01", nil, "/foo/01: invalid array index '01'"},
+		{"/foo/-", nil, "/foo/-: index '-' refers to a nonexistent element"},
+		{"/foo/0/a", nil, "/foo/0/a: cannot index into string"},
+		{"/a~1b/c~0", nil, "/a~1b/c~0: cannot index into float64"},
+	}
+
+	for i, c := range cases {
+		got, err := Get(doc, c.pointer)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d result mismatch. expected: %#v, got: %#v", i, c.expect, got)
+		}
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	got, err := Get(array2, "/1/city")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != String("toronto") {
+		t.Errorf("expected 'toronto', got: %#v", got)
+	}
+	if _, err := Get(object0, "/city/0"); err == nil || err.Error() != "/city/0: cannot index into vals.String" {
+		t.Errorf("expected indexing into string to error, got: %v", err)
+	}
+}
+
+func TestGetDocument(t *testing.T) {
+	type structure struct {
+		Format string                 `json:"format"`
+		Schema map[string]interface{} `json:"schema"`
+	}
+	doc := struct {
+		Structure *structure `json:"structure"`
+	}{&structure{Format: "csv", Schema: map[string]interface{}{"type": "array"}}}
+
+	got, err := Get(doc, "/structure/schema/type")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "array" {
+		t.Errorf("expected 'array', got: %#v", got)
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"structure": map[interface{}]interface{}{
+			"schema": map[string]interface{}{"items": []interface{}{"a", "b"}},
+		},
+	}
+	cases := []struct {
+		path   string
+		expect interface{}
+		err    string
+	}{
+		{"", doc, ""},
+		{"structure.schema.items.1", "b", ""},
+		{"structure.format", nil, "structure.format: key not found"},
+		{"structure.schema.items.x", nil, "structure.schema.items.x: invalid array index 'x'"},
+	}
+	for i, c := range cases {
+		got, err := GetPath(doc, c.path)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d result mismatch. expected: %#v, got: %#v", i, c.expect, got)
+		}
+	}
+}
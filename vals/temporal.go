@@ -0,0 +1,345 @@
+package vals
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DateLayout is the ISO 8601 layout dates are written in
+	DateLayout = "2006-01-02"
+	// TimeLayout is the ISO 8601 layout times of day are written in.
+	// fractional seconds are only written when present
+	TimeLayout = "15:04:05.999999999"
+	// DateTimeLayout is the ISO 8601 layout datetimes are written in
+	DateTimeLayout = time.RFC3339Nano
+)
+
+// dateLayouts are accepted when parsing dates, ISO 8601 first, followed by
+// common european day-first layouts
+var dateLayouts = []string{
+	DateLayout,
+	"20060102",
+	"2.1.2006",
+	"2/1/2006",
+	"2-1-2006",
+}
+
+// timeLayouts are accepted when parsing times of day
+var timeLayouts = []string{
+	"15:04:05.999999999",
+	"15:04",
+	"150405",
+	"15.04",
+}
+
+// dateTimeLayouts are accepted when parsing datetimes. layouts without a zone
+// are interpreted as UTC
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+}
+
+// parseLayouts parses s with the first matching layout. values without a
+// zone are interpreted in loc
+func parseLayouts(kind, s string, layouts []string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s '%s'", kind, s)
+}
+
+// Date is a calendar date without a time of day or zone. Dates are of type
+// string, and encode as ISO 8601 text, eg. "2019-03-31"
+type Date struct {
+	t time.Time
+}
+
+// NewDate creates a date from a year, month & day
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses ISO 8601 & common european date formats, eg. "2019-03-31",
+// "31.03.2019" or "31/03/2019". slash & dash separated dates are read day
+// first
+func ParseDate(s string) (Date, error) {
+	t, err := parseLayouts("date", s, dateLayouts, time.UTC)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t: t}, nil
+}
+
+// Time gives the date as a time at midnight UTC
+func (d Date) Time() time.Time { return d.t }
+
+// Type declares Date is of String type
+func (d Date) Type() Type { return TypeString }
+
+// Len of Date will always panic
+func (d Date) Len() int { panic(&ValueError{"Len", TypeString}) }
+
+// Index of Date will always panic
+func (d Date) Index(i int) Value { panic(&ValueError{"Index", TypeString}) }
+
+// Keys of Date will always panic
+func (d Date) Keys() []string { panic(&ValueError{"Keys", TypeString}) }
+
+// MapIndex of Date will always panic
+func (d Date) MapIndex(key string) Value { panic(&ValueError{"MapIndex", TypeString}) }
+
+// Boolean of Date will always panic
+func (d Date) Boolean() bool { panic(&ValueError{"Boolean", TypeString}) }
+
+// String gives the ISO 8601 form of the date
+func (d Date) String() string { return d.t.Format(DateLayout) }
+
+// Integer of Date will always panic
+func (d Date) Integer() int { panic(&ValueError{"Integer", TypeString}) }
+
+// Number of Date will always panic
+func (d Date) Number() float64 { panic(&ValueError{"Number", TypeString}) }
+
+// IsNull of Date always returns false
+func (d Date) IsNull() bool { return false }
+
+// MarshalJSON encodes a date as an ISO 8601 string
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON decodes a date string
+func (d *Date) UnmarshalJSON(data []byte) (err error) {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("date must be a string, got %s", data)
+	}
+	*d, err = ParseDate(s)
+	return err
+}
+
+// Time is a time of day without a date or zone. Times are of type string, and
+// encode as ISO 8601 text, eg. "13:45:00"
+type Time struct {
+	t time.Time
+}
+
+// NewTime creates a time of day
+func NewTime(hour, min, sec, nsec int) Time {
+	return Time{t: time.Date(0, 1, 1, hour, min, sec, nsec, time.UTC)}
+}
+
+// ParseTime parses ISO 8601 & common european time of day formats, eg.
+// "13:45:00", "13:45" or "13.45"
+func ParseTime(s string) (Time, error) {
+	t, err := parseLayouts("time", s, timeLayouts, time.UTC)
+	if err != nil {
+		return Time{}, err
+	}
+	return Time{t: t}, nil
+}
+
+// Time gives the time of day as a time on January 1st of year 0, UTC
+func (t Time) Time() time.Time { return t.t }
+
+// SinceMidnight gives the time of day as a duration since midnight
+func (t Time) SinceMidnight() time.Duration {
+	return t.t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// Type declares Time is of String type
+func (t Time) Type() Type { return TypeString }
+
+// Len of Time will always panic
+func (t Time) Len() int { panic(&ValueError{"Len", TypeString}) }
+
+// Index of Time will always panic
+func (t Time) Index(i int) Value { panic(&ValueError{"Index", TypeString}) }
+
+// Keys of Time will always panic
+func (t Time) Keys() []string { panic(&ValueError{"Keys", TypeString}) }
+
+// MapIndex of Time will always panic
+func (t Time) MapIndex(key string) Value { panic(&ValueError{"MapIndex", TypeString}) }
+
+// Boolean of Time will always panic
+func (t Time) Boolean() bool { panic(&ValueError{"Boolean", TypeString}) }
+
+// String gives the ISO 8601 form of the time
+func (t Time) String() string { return t.t.Format(TimeLayout) }
+
+// Integer of Time will always panic
+func (t Time) Integer() int { panic(&ValueError{"Integer", TypeString}) }
+
+// Number of Time will always panic
+func (t Time) Number() float64 { panic(&ValueError{"Number", TypeString}) }
+
+// IsNull of Time always returns false
+func (t Time) IsNull() bool { return false }
+
+// MarshalJSON encodes a time as an ISO 8601 string
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+// UnmarshalJSON decodes a time string
+func (t *Time) UnmarshalJSON(data []byte) (err error) {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("time must be a string, got %s", data)
+	}
+	*t, err = ParseTime(s)
+	return err
+}
+
+// DateTime is an instant in time. DateTimes are of type string, and encode as
+// RFC 3339 text, eg. "2019-03-31T13:45:00+02:00"
+type DateTime struct {
+	t time.Time
+}
+
+// NewDateTime wraps a time.Time as a DateTime
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{t: t}
+}
+
+// ParseDateTime parses ISO 8601 & common european datetime formats, eg.
+// "2019-03-31T13:45:00Z", "2019-03-31 13:45" or "31.03.2019 13:45".
+// datetimes without a zone are interpreted as UTC
+func ParseDateTime(s string) (DateTime, error) {
+	t, err := parseLayouts("datetime", s, dateTimeLayouts, time.UTC)
+	if err != nil {
+		return DateTime{}, err
+	}
+	return DateTime{t: t}, nil
+}
+
+// Time gives the underlying time.Time
+func (dt DateTime) Time() time.Time { return dt.t }
+
+// Type declares DateTime is of String type
+func (dt DateTime) Type() Type { return TypeString }
+
+// Len of DateTime will always panic
+func (dt DateTime) Len() int { panic(&ValueError{"Len", TypeString}) }
+
+// Index of DateTime will always panic
+func (dt DateTime) Index(i int) Value { panic(&ValueError{"Index", TypeString}) }
+
+// Keys of DateTime will always panic
+func (dt DateTime) Keys() []string { panic(&ValueError{"Keys", TypeString}) }
+
+// MapIndex of DateTime will always panic
+func (dt DateTime) MapIndex(key string) Value { panic(&ValueError{"MapIndex", TypeString}) }
+
+// Boolean of DateTime will always panic
+func (dt DateTime) Boolean() bool { panic(&ValueError{"Boolean", TypeString}) }
+
+// String gives the RFC 3339 form of the datetime
+func (dt DateTime) String() string { return dt.t.Format(DateTimeLayout) }
+
+// Integer of DateTime will always panic
+func (dt DateTime) Integer() int { panic(&ValueError{"Integer", TypeString}) }
+
+// Number of DateTime will always panic
+func (dt DateTime) Number() float64 { panic(&ValueError{"Number", TypeString}) }
+
+// IsNull of DateTime always returns false
+func (dt DateTime) IsNull() bool { return false }
+
+// MarshalJSON encodes a datetime as an RFC 3339 string
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(dt.String())), nil
+}
+
+// UnmarshalJSON decodes a datetime string
+func (dt *DateTime) UnmarshalJSON(data []byte) (err error) {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("datetime must be a string, got %s", data)
+	}
+	*dt, err = ParseDateTime(s)
+	return err
+}
+
+// ParseTemporal parses a string as a datetime, date or time, in that order,
+// for use when the kind of a temporal value isn't known ahead of time
+func ParseTemporal(s string) (Value, error) {
+	if dt, err := ParseDateTime(s); err == nil {
+		return dt, nil
+	}
+	if d, err := ParseDate(s); err == nil {
+		return d, nil
+	}
+	if t, err := ParseTime(s); err == nil {
+		return t, nil
+	}
+	return nil, fmt.Errorf("invalid date, time or datetime '%s'", s)
+}
+
+// ParseFormat parses a string as the temporal value described by a JSON
+// schema string format: "date", "time" or "date-time"
+func ParseFormat(format, s string) (Value, error) {
+	switch format {
+	case "date":
+		return ParseDate(s)
+	case "time":
+		return ParseTime(s)
+	case "date-time":
+		return ParseDateTime(s)
+	default:
+		return nil, fmt.Errorf("unsupported format '%s'", format)
+	}
+}
+
+// CompareTemporal compares two temporal values of the same kind, returning
+// -1 if a is before b, 0 if they're the same instant, and +1 if a is after b
+func CompareTemporal(a, b Value) (int, error) {
+	at, ak, err := temporalTime(a)
+	if err != nil {
+		return 0, err
+	}
+	bt, bk, err := temporalTime(b)
+	if err != nil {
+		return 0, err
+	}
+	if ak != bk {
+		return 0, fmt.Errorf("cannot compare %s to %s", ak, bk)
+	}
+	switch {
+	case at.Before(bt):
+		return -1, nil
+	case at.After(bt):
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// temporalTime gives the time & kind of a temporal value
+func temporalTime(v Value) (time.Time, string, error) {
+	switch t := v.(type) {
+	case Date:
+		return t.t, "date", nil
+	case Time:
+		return t.t, "time", nil
+	case DateTime:
+		return t.t, "datetime", nil
+	default:
+		return time.Time{}, "", fmt.Errorf("%T is not a temporal value", v)
+	}
+}
@@ -0,0 +1,172 @@
+package vals
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect string
+		err    string
+	}{
+		{"2019-03-31", "2019-03-31", ""},
+		{"20190331", "2019-03-31", ""},
+		{"31.03.2019", "2019-03-31", ""},
+		{"1.3.2019", "2019-03-01", ""},
+		{"01/03/2019", "2019-03-01", ""},
+		{"01-03-2019", "2019-03-01", ""},
+		{"2019-02-30", "", "invalid date '2019-02-30'"},
+		{"tomorrow", "", "invalid date 'tomorrow'"},
+	}
+	for i, c := range cases {
+		got, err := ParseDate(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got.String() != c.expect {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect string
+		err    string
+	}{
+		{"13:45:00", "13:45:00", ""},
+		{"13:45:00.250", "13:45:00.25", ""},
+		{"13:45", "13:45:00", ""},
+		{"13.45", "13:45:00", ""},
+		{"134500", "13:45:00", ""},
+		{"25:00", "", "invalid time '25:00'"},
+	}
+	for i, c := range cases {
+		got, err := ParseTime(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got.String() != c.expect {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+
+	if d := NewTime(1, 30, 0, 0).SinceMidnight(); d != 90*time.Minute {
+		t.Errorf("expected 1h30m since midnight, got: %s", d)
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect string
+		err    string
+	}{
+		{"2019-03-31T13:45:00+02:00", "2019-03-31T13:45:00+02:00", ""},
+		{"2019-03-31T13:45:00.5Z", "2019-03-31T13:45:00.5Z", ""},
+		{"2019-03-31T13:45:00", "2019-03-31T13:45:00Z", ""},
+		{"2019-03-31 13:45", "2019-03-31T13:45:00Z", ""},
+		{"31.03.2019 13:45", "2019-03-31T13:45:00Z", ""},
+		{"31/03/2019 13:45:10", "2019-03-31T13:45:10Z", ""},
+		{"2019-03-31", "", "invalid datetime '2019-03-31'"},
+	}
+	for i, c := range cases {
+		got, err := ParseDateTime(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && got.String() != c.expect {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestParseTemporal(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect Value
+	}{
+		{"2019-03-31T13:45:00Z", NewDateTime(time.Date(2019, 3, 31, 13, 45, 0, 0, time.UTC))},
+		{"31.03.2019", NewDate(2019, 3, 31)},
+		{"13:45", NewTime(13, 45, 0, 0)},
+	}
+	for i, c := range cases {
+		got, err := ParseTemporal(c.in)
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if !Equal(got, c.expect) {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+	if _, err := ParseTemporal("soon"); err == nil {
+		t.Errorf("expected invalid temporal value to error")
+	}
+	if _, err := ParseFormat("email", "a@b.c"); err == nil || err.Error() != "unsupported format 'email'" {
+		t.Errorf("expected unsupported format error, got: %v", err)
+	}
+}
+
+func TestCompareTemporal(t *testing.T) {
+	a, _ := ParseDateTime("2019-03-31T13:45:00+02:00")
+	b, _ := ParseDateTime("2019-03-31T11:45:00Z")
+	c, _ := ParseDateTime("2019-03-31T12:00:00Z")
+
+	if cmp, err := CompareTemporal(a, b); err != nil || cmp != 0 {
+		t.Errorf("expected the same instant in different zones to compare equal. got: %d, %v", cmp, err)
+	}
+	if !Equal(a, b) {
+		t.Errorf("expected the same instant in different zones to be equal")
+	}
+	if cmp, _ := CompareTemporal(b, c); cmp != -1 {
+		t.Errorf("expected b before c, got: %d", cmp)
+	}
+	if _, err := CompareTemporal(a, NewDate(2019, 3, 31)); err == nil || err.Error() != "cannot compare datetime to date" {
+		t.Errorf("expected kind mismatch error, got: %v", err)
+	}
+	if _, err := CompareTemporal(a, String("2019")); err == nil {
+		t.Errorf("expected comparing a string to error")
+	}
+
+	dates := []Date{NewDate(2020, 1, 1), NewDate(2019, 12, 31), NewDate(2019, 1, 2)}
+	sort.Slice(dates, func(i, j int) bool {
+		cmp, _ := CompareTemporal(dates[i], dates[j])
+		return cmp < 0
+	})
+	if dates[0].String() != "2019-01-02" || dates[2].String() != "2020-01-01" {
+		t.Errorf("sort mismatch: %v", dates)
+	}
+}
+
+func TestTemporalJSON(t *testing.T) {
+	doc := struct {
+		Date     Date     `json:"date"`
+		Time     Time     `json:"time"`
+		DateTime DateTime `json:"dateTime"`
+	}{}
+	data := []byte(`{"date":"2019-03-31","time":"13:45:00","dateTime":"2019-03-31T13:45:00+02:00"}`)
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("round trip mismatch.\nwant: %s\ngot:  %s", data, got)
+	}
+
+	d := Date{}
+	if err := json.Unmarshal([]byte(`20190331`), &d); err == nil || err.Error() != "date must be a string, got 20190331" {
+		t.Errorf("expected non-string date to error, got: %v", err)
+	}
+}
@@ -11,9 +11,17 @@ import (
 // Viz stores configuration data related to representing a dataset as a
 // visualization
 type Viz struct {
+	// Binding declares the data the viz consumes
+	Binding *VizBinding `json:"binding,omitempty"`
+	// Engine is the template engine that renders the viz script, eg.
+	// "html/template"
+	Engine string `json:"engine,omitempty"`
 	// Format designates the visualization configuration syntax. currently the
 	// only supported syntax is "html"
 	Format string `json:"format,omitempty"`
+	// Outputs lists the formats a viz renders to, each with it's own rendered
+	// file. A viz with no outputs renders to RenderedPath
+	Outputs []*VizOutput `json:"outputs,omitempty"`
 	// Path is the location of a viz, transient
 	// derived
 	Path string `json:"path,omitempty"`
@@ -55,20 +63,9 @@ func (v *Viz) DropDerivedValues() {
 // in-place file from ScriptBytes when defined, fetching from the
 // passed-in resolver otherwise
 func (v *Viz) OpenScriptFile(ctx context.Context, resolver qfs.PathResolver) (err error) {
-	if v.ScriptBytes != nil {
-		v.scriptFile = qfs.NewMemfileBytes("template.html", v.ScriptBytes)
-		return nil
-	}
-
-	if v.ScriptPath == "" {
-		// nothing to resolve
-		return nil
-	}
-
-	if resolver == nil {
-		return ErrNoResolver
+	if src := v.ScriptSource(resolver); src != nil {
+		v.scriptFile, err = src.Open(ctx)
 	}
-	v.scriptFile, err = resolver.Get(ctx, v.ScriptPath)
 	return err
 }
 
@@ -79,15 +76,9 @@ func (v *Viz) SetScriptFile(file qfs.File) {
 
 // OpenRenderedFile generates a byte stream of the rendered data
 func (v *Viz) OpenRenderedFile(ctx context.Context, resolver qfs.PathResolver) (err error) {
-	if v.RenderedPath == "" {
-		// nothing to resolve
-		return nil
-	}
-
-	if resolver == nil {
-		return ErrNoResolver
+	if src := v.RenderedSource(resolver); src != nil {
+		v.renderedFile, err = src.Open(ctx)
 	}
-	v.renderedFile, err = resolver.Get(ctx, v.RenderedPath)
 	return err
 }
 
@@ -110,7 +101,10 @@ func (v *Viz) RenderedFile() qfs.File {
 
 // IsEmpty checks to see if Viz has any fields other than the internal path
 func (v *Viz) IsEmpty() bool {
-	return v.Format == "" &&
+	return v.Binding == nil &&
+		v.Engine == "" &&
+		v.Format == "" &&
+		v.Outputs == nil &&
 		v.ScriptBytes == nil &&
 		v.ScriptPath == "" &&
 		v.RenderedPath == ""
@@ -124,9 +118,18 @@ func (v *Viz) Assign(visConfigs ...*Viz) {
 			continue
 		}
 
+		if vs.Binding != nil {
+			v.Binding = vs.Binding
+		}
+		if vs.Engine != "" {
+			v.Engine = vs.Engine
+		}
 		if vs.Format != "" {
 			v.Format = vs.Format
 		}
+		if vs.Outputs != nil {
+			v.assignOutputs(vs.Outputs)
+		}
 		if vs.Path != "" {
 			v.Path = vs.Path
 		}
@@ -148,6 +151,24 @@ func (v *Viz) Assign(visConfigs ...*Viz) {
 	}
 }
 
+// Clone returns a deep copy of a viz. Script & rendered files are not copied
+func (v *Viz) Clone() *Viz {
+	if v == nil {
+		return nil
+	}
+	return &Viz{
+		Binding:      v.Binding.Clone(),
+		Engine:       v.Engine,
+		Format:       v.Format,
+		Outputs:      cloneVizOutputs(v.Outputs),
+		Path:         v.Path,
+		Qri:          v.Qri,
+		ScriptBytes:  cloneBytes(v.ScriptBytes),
+		ScriptPath:   v.ScriptPath,
+		RenderedPath: v.RenderedPath,
+	}
+}
+
 // _viz is a private struct for marshaling into & out of.
 type _viz Viz
 
@@ -177,11 +198,21 @@ func (v *Viz) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &_v); err != nil {
 		return err
 	}
+	if err := checkKind(_v.Qri); err != nil {
+		return err
+	}
 	if _v.Qri == "" {
 		_v.Qri = KindViz.String()
 	}
 
 	*v = Viz(_v)
+	script, err := decodeInlineScript(data)
+	if err != nil {
+		return err
+	}
+	if script != nil {
+		v.ScriptBytes = script
+	}
 	return nil
 }
 
@@ -210,9 +241,18 @@ func (v *Viz) MarshalJSONObject() ([]byte, error) {
 		"qri": v.Qri,
 	}
 
+	if v.Binding != nil {
+		data["binding"] = v.Binding
+	}
+	if v.Engine != "" {
+		data["engine"] = v.Engine
+	}
 	if v.Format != "" {
 		data["format"] = v.Format
 	}
+	if v.Outputs != nil {
+		data["outputs"] = v.Outputs
+	}
 	if v.ScriptBytes != nil {
 		data["scriptBytes"] = v.ScriptBytes
 	}
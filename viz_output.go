@@ -0,0 +1,168 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset/tabular"
+)
+
+const (
+	// VizOutputHTML renders a viz as an HTML document
+	VizOutputHTML = "html"
+	// VizOutputSVG renders a viz as an SVG image
+	VizOutputSVG = "svg"
+	// VizOutputPNG renders a viz as a PNG image. PNG outputs must specify
+	// dimensions
+	VizOutputPNG = "png"
+)
+
+// vizOutputFormats is the set of supported output formats
+var vizOutputFormats = map[string]bool{
+	VizOutputHTML: true,
+	VizOutputSVG:  true,
+	VizOutputPNG:  true,
+}
+
+// VizOutput is a single rendered form of a viz
+type VizOutput struct {
+	// Format of the rendered output, one of "html", "svg" or "png"
+	Format string `json:"format"`
+	// Height of raster output in pixels
+	Height int `json:"height,omitempty"`
+	// RenderedPath is the path to the file rendered in this format
+	RenderedPath string `json:"renderedPath,omitempty"`
+	// Width of raster output in pixels
+	Width int `json:"width,omitempty"`
+}
+
+// VizBinding declares which parts of a dataset a viz consumes, making it
+// possible to validate a viz against a dataset without rendering it, and to
+// tell if a new version changed anything a viz depends on
+type VizBinding struct {
+	// Columns are the titles of body columns the viz reads
+	Columns []string `json:"columns,omitempty"`
+	// Stats are the names of column statistics the viz reads, eg. "count"
+	Stats []string `json:"stats,omitempty"`
+}
+
+// Clone returns a deep copy of a binding
+func (b *VizBinding) Clone() *VizBinding {
+	if b == nil {
+		return nil
+	}
+	c := &VizBinding{}
+	if b.Columns != nil {
+		c.Columns = append([]string{}, b.Columns...)
+	}
+	if b.Stats != nil {
+		c.Stats = append([]string{}, b.Stats...)
+	}
+	return c
+}
+
+// Output gets the output for a format, nil if the viz doesn't render to that
+// format
+func (v *Viz) Output(format string) *VizOutput {
+	for _, o := range v.Outputs {
+		if o != nil && strings.EqualFold(o.Format, format) {
+			return o
+		}
+	}
+	return nil
+}
+
+// assignOutputs merges outputs onto a viz by format. Outputs that share a
+// format with an existing output replace it, unknown outputs are appended
+func (v *Viz) assignOutputs(outputs []*VizOutput) {
+	for _, o := range outputs {
+		if o == nil {
+			continue
+		}
+		replaced := false
+		for i, existing := range v.Outputs {
+			if existing != nil && strings.EqualFold(existing.Format, o.Format) {
+				v.Outputs[i] = o
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			v.Outputs = append(v.Outputs, o)
+		}
+	}
+}
+
+func cloneVizOutputs(outputs []*VizOutput) []*VizOutput {
+	if outputs == nil {
+		return nil
+	}
+	c := make([]*VizOutput, len(outputs))
+	for i, o := range outputs {
+		if o != nil {
+			cp := *o
+			c[i] = &cp
+		}
+	}
+	return c
+}
+
+// validateVizOutputs checks each output has a unique, supported format, and
+// raster outputs have dimensions
+func validateVizOutputs(outputs []*VizOutput) error {
+	formats := map[string]bool{}
+	for i, o := range outputs {
+		if o == nil {
+			return fmt.Errorf("outputs index %d is empty", i)
+		}
+		format := strings.ToLower(o.Format)
+		if !vizOutputFormats[format] {
+			return fmt.Errorf("outputs index %d: unsupported format '%s'", i, o.Format)
+		}
+		if formats[format] {
+			return fmt.Errorf("outputs index %d: duplicate format '%s'", i, o.Format)
+		}
+		if o.Width < 0 || o.Height < 0 {
+			return fmt.Errorf("outputs index %d: dimensions cannot be negative", i)
+		}
+		if format == VizOutputPNG && (o.Width == 0 || o.Height == 0) {
+			return fmt.Errorf("outputs index %d: png output requires width and height", i)
+		}
+		formats[format] = true
+	}
+	return nil
+}
+
+// validateVizBinding checks all bound columns exist in a dataset structure.
+// bindings can only be checked against tabular structures with a schema
+func validateVizBinding(b *VizBinding, st *Structure) error {
+	for i, col := range b.Columns {
+		if col == "" {
+			return fmt.Errorf("binding: columns index %d is empty", i)
+		}
+	}
+	for i, stat := range b.Stats {
+		if stat == "" {
+			return fmt.Errorf("binding: stats index %d is empty", i)
+		}
+	}
+	if st == nil || st.Schema == nil || len(b.Columns) == 0 {
+		return nil
+	}
+
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		// non-tabular schemas have no columns to check against
+		return nil
+	}
+	titles := map[string]bool{}
+	for _, title := range cols.Titles() {
+		titles[title] = true
+	}
+	for _, col := range b.Columns {
+		if !titles[col] {
+			return fmt.Errorf("binding: unknown column '%s'", col)
+		}
+	}
+	return nil
+}
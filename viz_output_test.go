@@ -0,0 +1,88 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVizOutputsJSON(t *testing.T) {
+	v := &Viz{
+		Qri:     KindViz.String(),
+		Engine:  "html/template",
+		Format:  "html",
+		Binding: &VizBinding{Columns: []string{"stop_id", "count"}, Stats: []string{"count"}},
+		Outputs: []*VizOutput{
+			{Format: VizOutputHTML, RenderedPath: "/ipfs/QmHTML"},
+			{Format: VizOutputPNG, Width: 800, Height: 600},
+		},
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"binding":{"columns":["stop_id","count"],"stats":["count"]},"engine":"html/template","format":"html","outputs":[{"format":"html","renderedPath":"/ipfs/QmHTML"},{"format":"png","height":600,"width":800}],"qri":"vz:0"}`
+	if string(data) != expect {
+		t.Errorf("marshal mismatch.\nwant: %s\ngot:  %s", expect, data)
+	}
+
+	got := &Viz{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, got) {
+		t.Errorf("round trip mismatch. expected: %#v, got: %#v", v, got)
+	}
+	if got.IsEmpty() {
+		t.Errorf("expected viz with outputs to be non-empty")
+	}
+}
+
+func TestVizOutputsAssign(t *testing.T) {
+	v := &Viz{Outputs: []*VizOutput{{Format: "html", RenderedPath: "/ipfs/QmOld"}}}
+	v.Assign(&Viz{Outputs: []*VizOutput{{Format: "HTML", RenderedPath: "/ipfs/QmNew"}, {Format: "svg"}}})
+	if len(v.Outputs) != 2 {
+		t.Fatalf("expected 2 outputs, got: %d", len(v.Outputs))
+	}
+	if o := v.Output("html"); o == nil || o.RenderedPath != "/ipfs/QmNew" {
+		t.Errorf("expected html output to be replaced, got: %#v", o)
+	}
+	if v.Output("png") != nil {
+		t.Errorf("expected missing output to be nil")
+	}
+}
+
+func TestVizOutputsClone(t *testing.T) {
+	v := &Viz{
+		Binding: &VizBinding{Columns: []string{"a"}},
+		Outputs: []*VizOutput{{Format: "svg"}},
+	}
+	c := v.Clone()
+	if !reflect.DeepEqual(v, c) {
+		t.Fatalf("clone mismatch. expected: %#v, got: %#v", v, c)
+	}
+	c.Binding.Columns[0] = "changed"
+	c.Outputs[0].Format = "changed"
+	if v.Binding.Columns[0] == "changed" || v.Outputs[0].Format == "changed" {
+		t.Errorf("clone shares memory with original")
+	}
+}
+
+func TestValidateVizOutputs(t *testing.T) {
+	cases := []struct {
+		outputs []*VizOutput
+		err     string
+	}{
+		{nil, ""},
+		{[]*VizOutput{{Format: "html"}, {Format: "png", Width: 10, Height: 10}}, ""},
+		{[]*VizOutput{nil}, "outputs index 0 is empty"},
+		{[]*VizOutput{{Format: "svg"}, {Format: "SVG"}}, "outputs index 1: duplicate format 'SVG'"},
+		{[]*VizOutput{{Format: "svg", Width: -1}}, "outputs index 0: dimensions cannot be negative"},
+	}
+	for i, c := range cases {
+		err := validateVizOutputs(c.outputs)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%s'", i, c.err, err)
+		}
+	}
+}
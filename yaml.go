@@ -0,0 +1,224 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// YAML encoding is defined in terms of JSON encoding: values are marshaled to
+// JSON & converted into generic values for YAML to encode, and decoded YAML is
+// converted to JSON before being unmarshaled. This keeps field names,
+// reference-by-path strings & hoisted metadata identical across both formats
+
+// yamlValue converts a json.Marshaler into a generic value for YAML encoding
+func yamlValue(m json.Marshaler) (interface{}, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// yamlToJSON decodes a YAML value & re-encodes it as JSON
+func yamlToJSON(unmarshal func(interface{}) error) ([]byte, error) {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return nil, err
+	}
+	v, err := jsonCompatible(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// jsonCompatible converts the types YAML decodes to into values encoding/json
+// can marshal
+func jsonCompatible(v interface{}) (interface{}, error) {
+	var err error
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for key, val := range x {
+			str, ok := key.(string)
+			if !ok {
+				str = fmt.Sprintf("%v", key)
+			}
+			if m[str], err = jsonCompatible(val); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case map[string]interface{}:
+		for key, val := range x {
+			if x[key], err = jsonCompatible(val); err != nil {
+				return nil, err
+			}
+		}
+		return x, nil
+	case []interface{}:
+		for i, val := range x {
+			if x[i], err = jsonCompatible(val); err != nil {
+				return nil, err
+			}
+		}
+		return x, nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	default:
+		return v, nil
+	}
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (ds *Dataset) MarshalYAML() (interface{}, error) {
+	return yamlValue(ds)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (ds *Dataset) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return ds.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (cm *Commit) MarshalYAML() (interface{}, error) {
+	return yamlValue(cm)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (cm *Commit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return cm.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (md *Meta) MarshalYAML() (interface{}, error) {
+	return yamlValue(md)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (md *Meta) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return md.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (p *Provenance) MarshalYAML() (interface{}, error) {
+	return yamlValue(p)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (p *Provenance) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return p.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (p *Preview) MarshalYAML() (interface{}, error) {
+	return yamlValue(p)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (p *Preview) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return p.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (sa *Stats) MarshalYAML() (interface{}, error) {
+	return yamlValue(sa)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (sa *Stats) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return sa.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (r *Readme) MarshalYAML() (interface{}, error) {
+	return yamlValue(r)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (r *Readme) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return r.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (s Structure) MarshalYAML() (interface{}, error) {
+	return yamlValue(s)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (s *Structure) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return s.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (q Transform) MarshalYAML() (interface{}, error) {
+	return yamlValue(q)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (q *Transform) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return q.UnmarshalJSON(data)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, allowing both
+// string and object representations
+func (r *TransformResource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return r.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (v *Viz) MarshalYAML() (interface{}, error) {
+	return yamlValue(v)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (v *Viz) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := yamlToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalJSON(data)
+}
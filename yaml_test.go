@@ -0,0 +1,110 @@
+package dataset
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestDatasetYAMLRoundTrip(t *testing.T) {
+	for _, path := range []string{
+		"testdata/datasets/airport-codes.json",
+		"testdata/datasets/complete.json",
+		"testdata/datasets/continent-codes.json",
+		"testdata/datasets/hours.json",
+	} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds := &Dataset{}
+		if err := json.Unmarshal(data, ds); err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		expect, err := json.Marshal(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		yamlData, err := yaml.Marshal(ds)
+		if err != nil {
+			t.Fatalf("%s marshaling yaml: %s", path, err)
+		}
+		got := &Dataset{}
+		if err := yaml.Unmarshal(yamlData, got); err != nil {
+			t.Fatalf("%s unmarshaling yaml: %s", path, err)
+		}
+		gotJSON, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(expect) != string(gotJSON) {
+			t.Errorf("%s: yaml round trip mismatch.\nwant: %s\ngot:  %s", path, expect, gotJSON)
+		}
+	}
+}
+
+func TestComponentYAML(t *testing.T) {
+	data := []byte(`
+qri: ds:0
+meta:
+  title: yaml dataset
+  keywords:
+  - a
+  - b
+  custom: hoisted
+commit:
+  title: initial commit
+  timestamp: 2019-01-01T00:00:00Z
+structure: /ipfs/QmStructure
+transform:
+  syntax: starlark
+  resources:
+    a: /ipfs/QmA
+    b:
+      path: /ipfs/QmB
+viz:
+  format: html
+`)
+	ds := &Dataset{}
+	if err := yaml.Unmarshal(data, ds); err != nil {
+		t.Fatal(err)
+	}
+
+	if ds.Meta.Title != "yaml dataset" || len(ds.Meta.Keywords) != 2 {
+		t.Errorf("meta mismatch: %#v", ds.Meta)
+	}
+	if ds.Meta.Meta()["custom"] != "hoisted" {
+		t.Errorf("expected custom meta field to be hoisted")
+	}
+	if ds.Commit.Timestamp.Year() != 2019 {
+		t.Errorf("expected commit timestamp to decode. got: %s", ds.Commit.Timestamp)
+	}
+	if ds.Structure.Path != "/ipfs/QmStructure" {
+		t.Errorf("expected structure path reference. got: %q", ds.Structure.Path)
+	}
+	if ds.Transform.Resources["a"].Path != "/ipfs/QmA" || ds.Transform.Resources["b"].Path != "/ipfs/QmB" {
+		t.Errorf("transform resources mismatch")
+	}
+	if ds.Viz.Format != "html" {
+		t.Errorf("viz mismatch")
+	}
+
+	st := &Structure{}
+	if err := yaml.Unmarshal([]byte("format: csv\nformatConfig:\n  headerRow: true\n"), st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Format != "csv" || st.FormatConfig["headerRow"] != true {
+		t.Errorf("structure mismatch: %#v", st)
+	}
+	out, err := yaml.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "format: csv\nformatConfig:\n  headerRow: true\nqri: st:0\n"
+	if string(out) != expect {
+		t.Errorf("structure yaml mismatch.\nwant: %q\ngot:  %q", expect, string(out))
+	}
+}